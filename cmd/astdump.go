@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/lexer"
+	"github.com/burnlang/burn/pkg/parser"
+	"github.com/burnlang/burn/pkg/typechecker"
+)
+
+// dumpNode is an ordered, printer-independent view of an AST node. Fields
+// keep their declaration order so the output is stable across runs.
+type dumpNode struct {
+	fields []dumpField
+}
+
+type dumpField struct {
+	Name  string
+	Value interface{}
+}
+
+func (n *dumpNode) add(name string, value interface{}) {
+	n.fields = append(n.fields, dumpField{Name: name, Value: value})
+}
+
+func (n *dumpNode) get(name string) interface{} {
+	for _, f := range n.fields {
+		if f.Name == name {
+			return f.Value
+		}
+	}
+	return nil
+}
+
+func (n *dumpNode) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range n.fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.Name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+type astDumper struct {
+	source string
+	tc     *typechecker.TypeChecker
+}
+
+// runAST prints the parse tree of a Burn file as text or JSON
+func runAST(args []string, stdout, stderr io.Writer) int {
+	var filename string
+	asJSON, withTypes, noColor := false, false, false
+
+	for _, arg := range args {
+		switch arg {
+		case "--json":
+			asJSON = true
+		case "--types":
+			withTypes = true
+		case "--no-color":
+			noColor = true
+		default:
+			filename = arg
+		}
+	}
+
+	if filename == "" {
+		fmt.Fprintln(stderr, "Usage: burn ast <file.bn> [--json] [--types] [--no-color]")
+		return 1
+	}
+
+	source, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error reading file: %v\n", err)
+		return 1
+	}
+
+	printer := errorPrinter{color: colorEnabled(stderr, noColor)}
+
+	lex := lexer.New(string(source))
+	tokens, err := lex.Tokenize()
+	if err != nil {
+		printer.printError(stderr, formattedError("Lexical error", err, filename, string(source), lex.Position(), ast.Location{}))
+		return 1
+	}
+
+	p := parser.New(tokens)
+	program, err := p.Parse()
+	if err != nil {
+		printer.printError(stderr, formattedError("Parse error", err, filename, string(source), p.Position(), p.Location()))
+		return 1
+	}
+
+	exitCode := 0
+	dumper := &astDumper{source: string(source)}
+	if withTypes {
+		dumper.tc = typechecker.New()
+		if err := dumper.tc.Check(program.Declarations); err != nil {
+			printer.printError(stderr, formattedError("Type error", err, filename, string(source), dumper.tc.Position(), dumper.tc.Location()))
+			exitCode = 1
+		}
+	}
+
+	tree := dumper.dump(reflect.ValueOf(program))
+
+	if asJSON {
+		data, err := json.MarshalIndent(tree, "", "  ")
+		if err != nil {
+			fmt.Fprintf(stderr, "Error encoding AST: %v\n", err)
+			return 1
+		}
+		fmt.Fprintln(stdout, string(data))
+		return exitCode
+	}
+
+	printDumpTree(tree, 0, "", stdout)
+	return exitCode
+}
+
+func (d *astDumper) dump(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Interface {
+			return d.dump(v.Elem())
+		}
+		if v.Elem().Kind() == reflect.Struct {
+			return d.dumpStruct(v.Elem(), v.Interface())
+		}
+		return d.dump(v.Elem())
+	case reflect.Struct:
+		return d.dumpStruct(v, nil)
+	case reflect.Slice:
+		items := make([]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			items[i] = d.dump(v.Index(i))
+		}
+		return items
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(a, b int) bool {
+			return fmt.Sprint(keys[a].Interface()) < fmt.Sprint(keys[b].Interface())
+		})
+
+		node := &dumpNode{}
+		for _, key := range keys {
+			node.add(fmt.Sprint(key.Interface()), d.dump(v.MapIndex(key)))
+		}
+		return node
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	default:
+		if v.IsValid() && v.CanInterface() {
+			return v.Interface()
+		}
+		return nil
+	}
+}
+
+func (d *astDumper) dumpStruct(v reflect.Value, original interface{}) interface{} {
+	node := &dumpNode{}
+	node.add("kind", v.Type().Name())
+
+	if posField := v.FieldByName("Position"); posField.IsValid() && posField.Kind() == reflect.Int {
+		pos := int(posField.Int())
+		line, col := getLineAndCol(d.source, pos)
+		if loc, ok := v.FieldByName("Location").Interface().(ast.Location); ok && loc.IsValid() {
+			line, col = loc.Line, loc.Column
+		}
+		node.add("pos", pos)
+		node.add("line", line)
+		node.add("column", col)
+	}
+
+	if d.tc != nil {
+		if expr, ok := original.(ast.Expression); ok {
+			if exprType, exists := d.tc.TypeOf(expr); exists {
+				node.add("resolvedType", exprType)
+			}
+		}
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() || field.Name == "Position" || field.Name == "Location" {
+			continue
+		}
+		node.add(lowerFirst(field.Name), d.dump(v.Field(i)))
+	}
+
+	return node
+}
+
+func lowerFirst(name string) string {
+	if name == "" {
+		return name
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+func printDumpTree(value interface{}, indent int, label string, w io.Writer) {
+	indentStr := strings.Repeat("  ", indent)
+
+	switch v := value.(type) {
+	case *dumpNode:
+		kind, isNode := v.get("kind").(string)
+		if !isNode {
+			fmt.Fprintf(w, "%s%s\n", indentStr, strings.TrimSuffix(label, " "))
+			for _, f := range v.fields {
+				printDumpTree(f.Value, indent+1, f.Name+": ", w)
+			}
+			return
+		}
+
+		var attrs []string
+		var children []dumpField
+		for _, f := range v.fields {
+			switch f.Name {
+			case "kind", "pos", "line", "column":
+				continue
+			}
+			switch f.Value.(type) {
+			case *dumpNode, []interface{}:
+				children = append(children, f)
+			default:
+				if f.Value != nil && f.Value != "" {
+					attrs = append(attrs, fmt.Sprintf("%s=%v", f.Name, f.Value))
+				}
+			}
+		}
+
+		header := indentStr + label + kind
+		if len(attrs) > 0 {
+			header += " " + strings.Join(attrs, " ")
+		}
+		if line, ok := v.get("line").(int); ok {
+			header += fmt.Sprintf(" @%d:%d", line, v.get("column"))
+		}
+		fmt.Fprintln(w, header)
+
+		for _, child := range children {
+			printDumpTree(child.Value, indent+1, child.Name+": ", w)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "%s%s\n", indentStr, strings.TrimSuffix(label, " "))
+		for _, item := range v {
+			printDumpTree(item, indent+1, "", w)
+		}
+	default:
+		fmt.Fprintf(w, "%s%s%v\n", indentStr, label, v)
+	}
+}
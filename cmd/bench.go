@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/interpreter"
+)
+
+const (
+	benchFileSuffix    = "_bench.bn"
+	defaultBenchTime   = time.Second
+	maxBenchIterations = 1000000000
+)
+
+// runBench runs the bench_* functions of every *_bench.bn and *_test.bn file
+// under the given paths, scaling the iteration count until each benchmark
+// runs for at least the target duration
+func runBench(args []string, stdout, stderr io.Writer) int {
+	benchTime := defaultBenchTime
+	var paths []string
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--benchtime":
+			if i+1 >= len(args) {
+				fmt.Fprintln(stderr, "Error: --benchtime requires a duration, e.g. 500ms or 2s")
+				return 1
+			}
+			d, err := time.ParseDuration(args[i+1])
+			if err != nil || d <= 0 {
+				fmt.Fprintf(stderr, "Error: invalid --benchtime %q\n", args[i+1])
+				return 1
+			}
+			benchTime = d
+			i++
+		default:
+			paths = append(paths, args[i])
+		}
+	}
+
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	files, err := findSourceFiles(paths, benchFileSuffix, testFileSuffix)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	failed, found := false, false
+	for _, file := range files {
+		ran, ok := runBenchFile(file, benchTime, cfg.importPaths, stdout)
+		found = found || ran
+		failed = failed || !ok
+	}
+
+	if !found && !failed {
+		fmt.Fprintln(stderr, "No benchmarks found")
+		return 1
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// runBenchFile runs the benchmarks of one file. It reports whether the file
+// contained any benchmarks and whether all of them succeeded.
+func runBenchFile(file string, benchTime time.Duration, importPaths []string, stdout io.Writer) (bool, bool) {
+	source, err := os.ReadFile(file)
+	if err != nil {
+		fmt.Fprintf(stdout, "FAIL %s: %v\n", file, err)
+		return false, false
+	}
+
+	program, err := parseAndCheck(file, string(source), importPaths)
+	if err != nil {
+		fmt.Fprintf(stdout, "FAIL %s: %v\n", file, err)
+		return false, false
+	}
+
+	var benches []string
+	for _, decl := range program.Declarations {
+		if fn, ok := decl.(*ast.FunctionDeclaration); ok && fn.Receiver == "" && strings.HasPrefix(fn.Name, "bench_") {
+			benches = append(benches, fn.Name)
+		}
+	}
+	if len(benches) == 0 {
+		return false, true
+	}
+
+	interp := interpreter.New()
+	interp.SetFile(file)
+	for _, dir := range importPaths {
+		interp.AddImportPath(dir)
+	}
+	if err := interp.Load(program); err != nil {
+		fmt.Fprintf(stdout, "FAIL %s: %v\n", file, err)
+		return true, false
+	}
+
+	nameWidth := 0
+	for _, name := range benches {
+		if len(name) > nameWidth {
+			nameWidth = len(name)
+		}
+	}
+
+	fmt.Fprintf(stdout, "%s\n", file)
+	ok := true
+	for _, name := range benches {
+		n, elapsed, err := runBenchmark(interp, name, benchTime)
+		if err != nil {
+			fmt.Fprintf(stdout, "--- FAIL: %s\n    %v\n", name, err)
+			ok = false
+			continue
+		}
+		fmt.Fprintf(stdout, "%-*s %12d %14.0f ns/op\n", nameWidth, name, n,
+			float64(elapsed.Nanoseconds())/float64(n))
+	}
+	return true, ok
+}
+
+// runBenchmark calls a benchmark function with a growing iteration count
+// until a run takes at least benchTime, and returns the final count and time
+func runBenchmark(interp *interpreter.Interpreter, name string, benchTime time.Duration) (int, time.Duration, error) {
+	n := 1
+	for {
+		start := time.Now()
+		for j := 0; j < n; j++ {
+			if _, err := interp.Call(name); err != nil {
+				return 0, 0, err
+			}
+		}
+		elapsed := time.Since(start)
+
+		if elapsed >= benchTime || n >= maxBenchIterations {
+			return n, elapsed, nil
+		}
+		n = predictIterations(n, elapsed, benchTime)
+	}
+}
+
+// predictIterations estimates how many iterations reach the target time,
+// overshooting slightly and growing at most 100x per round
+func predictIterations(last int, elapsed, target time.Duration) int {
+	prevNs := elapsed.Nanoseconds()
+	if prevNs <= 0 {
+		prevNs = 1
+	}
+
+	n := int64(last) * target.Nanoseconds() / prevNs
+	n += n / 5
+	if n > int64(last)*100 {
+		n = int64(last) * 100
+	}
+	if n <= int64(last) {
+		n = int64(last) + 1
+	}
+	if n > maxBenchIterations {
+		n = maxBenchIterations
+	}
+	return int(n)
+}
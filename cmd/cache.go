@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/resolver"
+)
+
+// cacheHeader starts a .bnc file. The program that follows is only used
+// when Key is that of the source and options being run and every import
+// still resolves to a module with the same content.
+type cacheHeader struct {
+	Key     [sha256.Size]byte
+	Imports []cachedImport
+}
+
+// cachedImport is a module the cached program was typechecked against
+type cachedImport struct {
+	Path string
+	Sum  [sha256.Size]byte
+}
+
+// cachePath returns where the compiled form of a source file is cached
+func cachePath(filename string) string {
+	return strings.TrimSuffix(filename, ".bn") + ".bnc"
+}
+
+// cacheKey hashes what the parsed and typechecked program depends on,
+// besides its imports
+func cacheKey(source string, opts runOptions) [sha256.Size]byte {
+	h := sha256.New()
+	fmt.Fprintf(h, "burn %s\nstrict=%t\n", getVersion(), opts.strict)
+	for _, dir := range opts.importPaths {
+		fmt.Fprintf(h, "import path %q\n", dir)
+	}
+	for _, c := range opts.constants {
+		fmt.Fprintf(h, "constant %s:%s\n", c.name, c.typeName)
+	}
+	h.Write([]byte(source))
+
+	var key [sha256.Size]byte
+	copy(key[:], h.Sum(nil))
+	return key
+}
+
+// readCache returns the program cached for source, or nil when there is
+// none or it is out of date
+func readCache(source string, opts runOptions) *ast.Program {
+	file, err := os.Open(opts.cachePath)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	var header cacheHeader
+	if err := gob.NewDecoder(r).Decode(&header); err != nil || header.Key != cacheKey(source, opts) {
+		return nil
+	}
+	imports := resolver.Default(opts.importPaths...)
+	for _, imp := range header.Imports {
+		module, err := imports.Resolve(imp.Path, ".")
+		if err != nil || sha256.Sum256([]byte(module.Source)) != imp.Sum {
+			return nil
+		}
+	}
+
+	program, err := ast.Decode(r)
+	if err != nil {
+		return nil
+	}
+	return program
+}
+
+// writeCache caches a program that passed the typechecker. Failing to
+// write the cache, as in a read-only directory, is not an error.
+func writeCache(program *ast.Program, source string, opts runOptions) {
+	header := cacheHeader{Key: cacheKey(source, opts)}
+	imports := resolver.Default(opts.importPaths...)
+	for _, path := range importPaths(program) {
+		module, err := imports.Resolve(path, ".")
+		if err != nil {
+			return
+		}
+		header.Imports = append(header.Imports, cachedImport{Path: path, Sum: sha256.Sum256([]byte(module.Source))})
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(header); err != nil {
+		return
+	}
+	if err := ast.Encode(&buf, program); err != nil {
+		return
+	}
+
+	// Write to a temporary file first, so that a run reading the cache
+	// never sees half of it
+	tmp := opts.cachePath + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, opts.cachePath); err != nil {
+		os.Remove(tmp)
+	}
+}
+
+// importPaths lists the modules a program imports
+func importPaths(program *ast.Program) []string {
+	var paths []string
+	for _, decl := range program.Declarations {
+		switch d := decl.(type) {
+		case *ast.ImportDeclaration:
+			paths = append(paths, d.Path)
+		case *ast.MultiImportDeclaration:
+			for _, imp := range d.Imports {
+				paths = append(paths, imp.Path)
+			}
+		}
+	}
+	return paths
+}
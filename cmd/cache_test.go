@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/burnlang/burn/internal/testutil"
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/lexer"
+	"github.com/burnlang/burn/pkg/parser"
+)
+
+const cachedSource = "fun double(n: int): int { return n * 2 }\nprint(double(2))\n"
+
+func cacheOptions(t *testing.T) runOptions {
+	dir := t.TempDir()
+	return runOptions{cachePath: cachePath(filepath.Join(dir, "main.bn")), importPaths: []string{dir}}
+}
+
+func TestCacheRoundTrip(t *testing.T) {
+	opts := cacheOptions(t)
+	if readCache(cachedSource, opts) != nil {
+		t.Fatal("a program was read before any was cached")
+	}
+	writeCache(testutil.Program(t, cachedSource), cachedSource, opts)
+
+	program := readCache(cachedSource, opts)
+	if program == nil {
+		t.Fatal("the cached program was not read back")
+	}
+	if len(program.Declarations) != 2 {
+		t.Fatalf("got %d declarations, want 2", len(program.Declarations))
+	}
+	if fn, ok := program.Declarations[0].(*ast.FunctionDeclaration); !ok || fn.Name != "double" {
+		t.Errorf("first declaration is %#v, want function double", program.Declarations[0])
+	}
+}
+
+func TestCacheOutOfDate(t *testing.T) {
+	opts := cacheOptions(t)
+	writeCache(testutil.Program(t, cachedSource), cachedSource, opts)
+
+	if readCache(cachedSource+"print(1)\n", opts) != nil {
+		t.Error("the cache was used for changed source")
+	}
+	strict := opts
+	strict.strict = true
+	if readCache(cachedSource, strict) != nil {
+		t.Error("the cache was used for a run with different options")
+	}
+	if readCache(cachedSource, opts) == nil {
+		t.Error("the cache was not used for the source it was written for")
+	}
+}
+
+func TestCacheOutOfDateImport(t *testing.T) {
+	opts := cacheOptions(t)
+	lib := filepath.Join(opts.importPaths[0], "cachelib.bn")
+	if err := os.WriteFile(lib, []byte("fun one(): int { return 1 }\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	source := fmt.Sprintf("import %q\nprint(one())\n", filepath.ToSlash(lib))
+	tokens, err := lexer.New(source).Tokenize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	program, errs := parser.New(tokens).ParseAll()
+	if len(errs) > 0 {
+		t.Fatal(errs[0])
+	}
+	writeCache(program, source, opts)
+	if readCache(source, opts) == nil {
+		t.Fatal("the cached program was not read back")
+	}
+
+	if err := os.WriteFile(lib, []byte("fun one(): int { return 2 }\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if readCache(source, opts) != nil {
+		t.Error("the cache was used after an import changed")
+	}
+}
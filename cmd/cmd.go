@@ -3,7 +3,10 @@ package cmd
 import (
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
+
+	"github.com/burnlang/burn/pkg/interpreter"
 )
 
 func Execute(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
@@ -12,7 +15,28 @@ func Execute(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
 		return 1
 	}
 
-	nonOptions, options := parseArgs(args)
+	switch args[0] {
+	case "install":
+		return runInstall(args[1:], stdout, stderr)
+	case "add":
+		return runAdd(args[1:], stdout, stderr)
+	case "ast":
+		return runAST(args[1:], stdout, stderr)
+	case "tokens":
+		return runTokens(args[1:], stdout, stderr)
+	case "test":
+		return runTest(args[1:], stdout, stderr)
+	case "bench":
+		return runBench(args[1:], stdout, stderr)
+	case "run":
+		if len(args) == 1 {
+			printUsage(stdout)
+			return 1
+		}
+		return Execute(args[1:], stdin, stdout, stderr)
+	}
+
+	nonOptions, options, values, defines := parseArgs(args)
 
 	if options["help"] {
 		printUsage(stdout)
@@ -24,8 +48,38 @@ func Execute(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
 		return 0
 	}
 
+	if options["explain"] {
+		return explain(values["explain"], stdout, stderr)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	for _, warning := range cfg.warnings {
+		fmt.Fprintf(stderr, "Warning: %s\n", warning)
+	}
+	opts := runOptionsFrom(options, values, cfg)
+	if err := intFlag(values, "max-depth", 1, &opts.maxDepth); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	if err := intFlag(values, "max-errors", 0, &opts.maxErrors); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	for _, spec := range defines {
+		c, err := parseDefine(spec)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		opts.constants = append(opts.constants, c)
+	}
+
 	if options["repl"] {
-		return startREPL(stdin, stdout, stderr)
+		return startREPL(opts, nonOptions, stdin, stdout, stderr)
 	}
 
 	if options["eval"] {
@@ -33,7 +87,7 @@ func Execute(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
 			fmt.Fprintln(stderr, "Error: no code provided for evaluation")
 			return 1
 		}
-		return executeCode(nonOptions[0], options["debug"], stdout, stderr)
+		return executeCode(nonOptions[0], opts, stdout, stderr)
 	}
 
 	if options["exe"] {
@@ -41,7 +95,15 @@ func Execute(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
 			fmt.Fprintln(stderr, "Error: no source file provided for compilation")
 			return 1
 		}
-		return compileToExecutable(nonOptions[0], nonOptions[len(nonOptions)-1], stdout, stderr)
+		target := buildTarget{OS: values["os"], Arch: values["arch"]}
+		sources, outputName := nonOptions, values["output"]
+		// burn -exe main.bn out still names the output without -o
+		if outputName == "" && len(nonOptions) == 2 && !isMultiFile(nonOptions[1:]) &&
+			!strings.HasSuffix(nonOptions[1], ".bn") {
+			sources, outputName = nonOptions[:1], nonOptions[1]
+		}
+		report := reporter{out: stderr, level: verbosityFrom(options)}
+		return compileToExecutable(sources, outputName, target, report, stderr)
 	}
 
 	if len(nonOptions) == 0 {
@@ -49,25 +111,74 @@ func Execute(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
 		return 1
 	}
 
-	filename := nonOptions[0]
-	debug := options["debug"]
+	if isMultiFile(nonOptions) {
+		return executeFiles(nonOptions, opts, stdout, stderr)
+	}
+	return executeFile(nonOptions[0], opts, stdout, stderr)
+}
+
+// defaultMaxErrors is how many type errors are shown without --max-errors
+const defaultMaxErrors = 10
+
+// runOptionsFrom combines the command-line flags with the config file defaults
+func runOptionsFrom(options map[string]bool, values map[string]string, cfg config) runOptions {
+	return runOptions{
+		maxErrors:   defaultMaxErrors,
+		debug:       options["debug"],
+		profile:     options["profile"],
+		profileOut:  values["profile-out"],
+		trace:       options["trace"],
+		traceVars:   options["trace-vars"],
+		vm:          options["vm"],
+		noCache:     options["no-cache"],
+		strict:      options["strict"] || cfg.strict,
+		werror:      options["werror"],
+		noColor:     options["no-color"] || cfg.noColor,
+		importPaths: cfg.importPaths,
+		deny:        cfg.deny,
+		policy:      interpreter.Policy{Deny: cfg.denyBuiltins},
+	}
+}
 
-	return executeFile(filename, debug, stdout, stderr)
+// intFlag sets target to the value of the numeric flag name, if it was
+// given, which must be at least min
+func intFlag(values map[string]string, name string, min int, target *int) error {
+	value, ok := values[name]
+	if !ok {
+		return nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min {
+		return fmt.Errorf("invalid --%s %q: expected a number of at least %d", name, value, min)
+	}
+	*target = n
+	return nil
 }
 
 func getVersion() string {
 	return "0.1.0"
 }
 
-func parseArgs(args []string) ([]string, map[string]bool) {
+func parseArgs(args []string) ([]string, map[string]bool, map[string]string, []string) {
 	nonOptions := []string{}
+	defines := []string{}
+	values := map[string]string{}
 	options := map[string]bool{
-		"help":    false,
-		"version": false,
-		"repl":    false,
-		"eval":    false,
-		"debug":   false,
-		"exe":     false,
+		"help":     false,
+		"version":  false,
+		"repl":     false,
+		"eval":     false,
+		"debug":    false,
+		"exe":      false,
+		"profile":  false,
+		"trace":    false,
+		"strict":   false,
+		"no-color": false,
+		"quiet":    false,
+		"verbose":  false,
+		"explain":  false,
+		"vm":       false,
+		"no-cache": false,
 	}
 
 	for i := 0; i < len(args); i++ {
@@ -90,31 +201,127 @@ func parseArgs(args []string) ([]string, map[string]bool) {
 				options["debug"] = true
 			case "-exe", "--executable":
 				options["exe"] = true
+			case "--os", "--arch":
+				if i+1 < len(args) {
+					values[strings.TrimPrefix(arg, "--")] = args[i+1]
+					i++
+				}
+			case "--profile":
+				options["profile"] = true
+			case "--strict":
+				options["strict"] = true
+			case "--werror":
+				options["werror"] = true
+			case "--no-color":
+				options["no-color"] = true
+			case "-q", "--quiet":
+				options["quiet"] = true
+			case "--verbose":
+				options["verbose"] = true
+			case "--trace":
+				options["trace"] = true
+			case "--trace-vars":
+				options["trace-vars"] = true
+			case "--vm":
+				options["vm"] = true
+			case "--no-cache":
+				options["no-cache"] = true
+			case "--max-depth", "--max-errors":
+				if i+1 < len(args) {
+					values[strings.TrimPrefix(arg, "--")] = args[i+1]
+					i++
+				}
+			case "--explain":
+				options["explain"] = true
+				if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+					values["explain"] = args[i+1]
+					i++
+				}
+			case "--profile-out":
+				if i+1 < len(args) {
+					values["profile-out"] = args[i+1]
+					i++
+				}
+			case "-o", "--output":
+				if i+1 < len(args) {
+					values["output"] = args[i+1]
+					i++
+				}
+			case "-D", "--define":
+				if i+1 < len(args) {
+					defines = append(defines, args[i+1])
+					i++
+				}
+			default:
+				if strings.HasPrefix(arg, "-D") {
+					defines = append(defines, strings.TrimPrefix(arg, "-D"))
+				}
 			}
 		} else {
 			nonOptions = append(nonOptions, arg)
 		}
 	}
 
-	return nonOptions, options
+	return nonOptions, options, values, defines
 }
 
 func printUsage(w io.Writer) {
 	fmt.Fprintln(w, "Burn Programming Language")
 	fmt.Fprintln(w, "Usage:")
 	fmt.Fprintln(w, "  burn [options] [filename]")
+	fmt.Fprintln(w, "  burn [options] FILE... | DIR")
+	fmt.Fprintln(w, "  burn <command> [arguments]")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Commands:")
+	fmt.Fprintln(w, "  run PATH...    Run a file, several files or a package directory")
+	fmt.Fprintln(w, "  install [-q]   Fetch dependencies from burn.toml into burn_modules")
+	fmt.Fprintln(w, "  add NAME URL   Add a git (URL[@rev]) or tarball dependency and install it")
+	fmt.Fprintln(w, "  ast FILE       Print the parse tree (--json for JSON, --types to include resolved types)")
+	fmt.Fprintln(w, "  tokens FILE    Print the token stream with line/column (--json for JSON, --comments to keep comments)")
+	fmt.Fprintln(w, "  test [PATH...] Run test_* functions in *_test.bn files")
+	fmt.Fprintln(w, "                 (--coverage, --coverage-report, --lcov FILE)")
+	fmt.Fprintln(w, "  bench [PATH...] Run bench_* functions in *_bench.bn and *_test.bn files")
+	fmt.Fprintln(w, "                 (--benchtime DURATION, default 1s)")
 	fmt.Fprintln(w, "")
 	fmt.Fprintln(w, "Options:")
 	fmt.Fprintln(w, "  -h, --help     Show this help message")
 	fmt.Fprintln(w, "  -v, --version  Show version information")
-	fmt.Fprintln(w, "  -r, --repl     Start interactive REPL (Read-Eval-Print Loop)")
+	fmt.Fprintln(w, "  --explain [CODE]  Explain an error code such as BRN0301, or list them all")
+	fmt.Fprintln(w, "  -r, --repl [FILE...]  Start interactive REPL, loading the given files first")
 	fmt.Fprintln(w, "  -e, --eval     Evaluate Burn code from command line")
 	fmt.Fprintln(w, "  -d, --debug    Run in debug mode (show more information)")
 	fmt.Fprintln(w, "  -exe, --executable  Compile to a standalone executable")
+	fmt.Fprintln(w, "  -o, --output FILE  Output path for -exe (.exe is only added for Windows targets)")
+	fmt.Fprintln(w, "  --os OS        Target operating system for -exe (e.g. linux, windows, darwin)")
+	fmt.Fprintln(w, "  --arch ARCH    Target architecture for -exe (e.g. amd64, arm64)")
+	fmt.Fprintln(w, "  -D NAME[:TYPE]=VALUE  Define a constant (int, float, bool or string)")
+	fmt.Fprintln(w, "  -q, --quiet    Only print errors and warnings while building")
+	fmt.Fprintln(w, "  --verbose      Also list every file included in a build")
+	fmt.Fprintln(w, "  --no-color     Disable colored error output (also NO_COLOR=1)")
+	fmt.Fprintln(w, "  --strict       Enable extra checks: warn on any, reject implicit int/float")
+	fmt.Fprintln(w, "                 conversions, unused variables, shadowed globals and changed")
+	fmt.Fprintln(w, "                 fields of constants")
+	fmt.Fprintln(w, "  --werror       Treat warnings as errors and do not run the program")
+	fmt.Fprintln(w, "  --profile      Print per-function call counts and timings after the run")
+	fmt.Fprintln(w, "  --profile-out FILE  Write the profile in pprof format")
+	fmt.Fprintln(w, "  --max-errors N Show at most N type errors, or all with 0 (default 10)")
+	fmt.Fprintln(w, "  --max-depth N  Allow N nested function calls (default 10000)")
+	fmt.Fprintln(w, "  --trace        Log every executed statement to stderr")
+	fmt.Fprintln(w, "  --trace-vars   Like --trace, also showing the variables each statement reads")
+	fmt.Fprintln(w, "  --vm           Run on the bytecode VM, if the program only uses what it supports")
+	fmt.Fprintln(w, "  --no-cache     Do not read or write the .bnc cache of a checked program")
 	fmt.Fprintln(w, "")
 	fmt.Fprintln(w, "Examples:")
 	fmt.Fprintln(w, "  burn main.bn              Execute a Burn program")
 	fmt.Fprintln(w, "  burn -r                   Start REPL")
 	fmt.Fprintln(w, "  burn -e 'print(\"Hello\")' Evaluate a single expression")
 	fmt.Fprintln(w, "  burn -exe test/main.bn    Compile to executable")
+	fmt.Fprintln(w, "  burn -exe test/main.bn -o bin/main")
+	fmt.Fprintln(w, "  burn run ./src            Run all files of a package directory")
+	fmt.Fprintln(w, "  burn -exe ./src -o bin/app")
+	fmt.Fprintln(w, "  burn -exe --os windows --arch amd64 test/main.bn")
+	fmt.Fprintln(w, "  burn --profile main.bn    Profile a Burn program")
+	fmt.Fprintln(w, "  burn --explain BRN0301    Explain an error code")
+	fmt.Fprintln(w, "  burn -D ENV=prod -D RETRIES=5 deploy.bn")
+	fmt.Fprintln(w, "  burn add utils https://github.com/user/utils.git@v1.0.0")
 }
@@ -1,46 +1,117 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 
 	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/codegen"
 	"github.com/burnlang/burn/pkg/lexer"
 	"github.com/burnlang/burn/pkg/parser"
+	"github.com/burnlang/burn/pkg/resolver"
 	"github.com/burnlang/burn/pkg/stdlib"
 	"github.com/burnlang/burn/pkg/typechecker"
 )
 
-func compileToExecutable(sourceFile, outputName string, stdout, stderr io.Writer) int {
-	if !strings.HasSuffix(sourceFile, ".bn") {
-		fmt.Fprintf(stderr, "Warning: File %s does not have the .bn extension\n", sourceFile)
+// buildTarget selects the GOOS/GOARCH of the executable. Empty fields mean
+// the host platform.
+type buildTarget struct {
+	OS   string
+	Arch string
+}
+
+func (t buildTarget) isCross() bool {
+	return t.OS != "" || t.Arch != ""
+}
+
+func (t buildTarget) env() []string {
+	env := os.Environ()
+	if t.OS != "" {
+		env = append(env, "GOOS="+t.OS)
 	}
+	if t.Arch != "" {
+		env = append(env, "GOARCH="+t.Arch)
+	}
+	if t.isCross() {
+		env = append(env, "CGO_ENABLED=0")
+	}
+	return env
+}
 
-	if outputName == sourceFile || outputName == "" {
-		outputName = strings.TrimSuffix(filepath.Base(sourceFile), ".bn")
+// compileToExecutable builds an executable from a source file, or from
+// several files or a package directory compiled as one program
+func compileToExecutable(paths []string, outputName string, target buildTarget, report reporter, stderr io.Writer) int {
+	sourceFile := paths[0]
+	var sources *sourceSet
+	if isMultiFile(paths) {
+		set, err := loadSourceSet(paths)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error reading source files: %v\n", err)
+			return 1
+		}
+		sources = set
+		sourceFile = set.name
+	} else if !strings.HasSuffix(sourceFile, ".bn") {
+		report.warn("File %s does not have the .bn extension", sourceFile)
 	}
 
-	if !strings.HasSuffix(outputName, ".exe") {
-		outputName += ".exe"
+	if outputName == "" {
+		base := paths[0]
+		if abs, err := filepath.Abs(base); err == nil {
+			base = abs
+		}
+		outputName = strings.TrimSuffix(filepath.Base(base), ".bn")
+		if target.isCross() {
+			outputName += "-" + targetOrHost(target.OS, runtime.GOOS) + "-" + targetOrHost(target.Arch, runtime.GOARCH)
+		}
 	}
 
-	fmt.Fprintf(stdout, "Compiling %s to executable %s...\n", sourceFile, outputName)
+	if targetOrHost(target.OS, runtime.GOOS) == "windows" && !strings.HasSuffix(outputName, ".exe") {
+		outputName += ".exe"
+	}
 
-	source, err := os.ReadFile(sourceFile)
-	if err != nil {
-		fmt.Fprintf(stderr, "Error reading source file: %v\n", err)
+	if info, err := os.Stat(outputName); err == nil && info.IsDir() {
+		fmt.Fprintf(stderr, "Error: output %s is a directory, use -o to name the executable\n", outputName)
 		return 1
 	}
+	for _, path := range paths {
+		if samePath(outputName, path) {
+			fmt.Fprintf(stderr, "Error: output %s would overwrite the source file\n", outputName)
+			return 1
+		}
+	}
 
-	lex := lexer.New(string(source))
-	tokens, err := lex.Tokenize()
-	if err != nil {
-		fmt.Fprintf(stderr, "Lexical error: %v\n", err)
-		return 1
+	report.status("Compiling %s to executable %s...", sourceFile, outputName)
+
+	var source []byte
+	var tokens []lexer.Token
+	var err error
+	if sources != nil {
+		source = []byte(sources.source)
+		tokens, err = sources.tokenize()
+		if err != nil {
+			fmt.Fprintf(stderr, "%v\n", err)
+			return 1
+		}
+	} else {
+		source, err = os.ReadFile(sourceFile)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error reading source file: %v\n", err)
+			return 1
+		}
+
+		lex := lexer.New(string(source))
+		tokens, err = lex.Tokenize()
+		if err != nil {
+			fmt.Fprintf(stderr, "Lexical error: %v\n", err)
+			return 1
+		}
 	}
 
 	p := parser.New(tokens)
@@ -64,26 +135,79 @@ func compileToExecutable(sourceFile, outputName string, stdout, stderr io.Writer
 	defer os.RemoveAll(tempDir)
 
 	goFilePath := filepath.Join(tempDir, "main.go")
-	err = createExecutableWrapper(goFilePath, sourceFile, string(source))
+
+	if err := buildNative(program, goFilePath, outputName, target); err == nil {
+		report.status("Successfully compiled %s to %s (native)", sourceFile, outputName)
+		return 0
+	} else {
+		report.status("Falling back to embedded interpreter: %v", err)
+	}
+
+	mainPath := sourceFile
+	if sources != nil {
+		mainPath = sources.files[0].path
+	}
+	err = createExecutableWrapper(goFilePath, mainPath, string(source), report)
 	if err != nil {
 		fmt.Fprintf(stderr, "Error creating executable wrapper: %v\n", err)
 		return 1
 	}
 
 	cmd := exec.Command("go", "build", "-o", outputName, goFilePath)
-	cmd.Stdout = stdout
+	cmd.Env = target.env()
+	cmd.Stdout = stderr
 	cmd.Stderr = stderr
 	if err := cmd.Run(); err != nil {
 		fmt.Fprintf(stderr, "Error building executable: %v\n", err)
 		return 1
 	}
 
-	fmt.Fprintf(stdout, "Successfully compiled %s to %s\n", sourceFile, outputName)
+	report.status("Successfully compiled %s to %s", sourceFile, outputName)
 	return 0
 }
 
-func createExecutableWrapper(goFilePath, burnFilePath, burnSource string) error {
-	imports, err := collectImports(burnFilePath, burnSource)
+// buildNative translates the program to Go and builds it without the
+// interpreter. Any error means the caller should fall back to embedding.
+func buildNative(program *ast.Program, goFilePath, outputName string, target buildTarget) error {
+	goSource, err := codegen.GenerateGo(program)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(goFilePath, []byte(goSource), 0644); err != nil {
+		return err
+	}
+
+	var buildOutput bytes.Buffer
+	cmd := exec.Command("go", "build", "-o", outputName, goFilePath)
+	cmd.Env = target.env()
+	cmd.Stdout = &buildOutput
+	cmd.Stderr = &buildOutput
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("generated Go code did not build: %s", strings.TrimSpace(buildOutput.String()))
+	}
+
+	return nil
+}
+
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return absA == absB
+}
+
+func targetOrHost(value, host string) string {
+	if value != "" {
+		return value
+	}
+	return host
+}
+
+func createExecutableWrapper(goFilePath, burnFilePath, burnSource string, report reporter) error {
+	imports, err := collectImports(burnFilePath, burnSource, report)
 	if err != nil {
 		return err
 	}
@@ -117,7 +241,6 @@ import (
     "github.com/burnlang/burn/pkg/interpreter"
     "github.com/burnlang/burn/pkg/lexer"
     "github.com/burnlang/burn/pkg/parser"
-    "github.com/burnlang/burn/pkg/stdlib"
     "github.com/burnlang/burn/pkg/typechecker"
 )
 
@@ -170,13 +293,13 @@ func runBurnProgram() int {
         return 1
     }
 
-    _, err = interp.Interpret(program)
+    result, err := interp.Interpret(program)
     if err != nil {
         fmt.Fprintf(os.Stderr, "Runtime error: %%v\n", err)
-        return 1
+        return 2
     }
 
-    return 0
+    return interpreter.ExitCode(program, result)
 }
 
 func registerImport(interp *interpreter.Interpreter, path, source string) error {
@@ -189,7 +312,7 @@ func registerImport(interp *interpreter.Interpreter, path, source string) error
     // Register built-in standard libraries directly
     if basename == "date" || basename == "http" || basename == "time" || 
        path == "std/date" || path == "std/http" || path == "std/time" {
-        // These are already registered in RegisterBuiltinStandardLibraries
+        // These are already registered by interpreter.New
         return nil
     }
 
@@ -245,7 +368,7 @@ func registerImport(interp *interpreter.Interpreter, path, source string) error
 	return os.WriteFile(goFilePath, []byte(wrapperCode), 0644)
 }
 
-func collectImports(mainFile, mainSource string) (map[string]string, error) {
+func collectImports(mainFile, mainSource string, report reporter) (map[string]string, error) {
 	imports := make(map[string]string)
 
 	workingDir, err := os.Getwd()
@@ -258,7 +381,7 @@ func collectImports(mainFile, mainSource string) (map[string]string, error) {
 		imports[name] = content
 		imports["std/"+name] = content
 		imports["std/"+name+".bn"] = content
-		fmt.Printf("Including standard library %s (built-in)\n", name)
+		report.detail("Including standard library %s (built-in)", name)
 	}
 
 	// Check for standard libraries in the file system
@@ -271,7 +394,7 @@ func collectImports(mainFile, mainSource string) (map[string]string, error) {
 					imports[name] = content
 					imports["std/"+name] = content
 					imports["std/"+name+".bn"] = content
-					fmt.Printf("Auto-discovered standard library %s\n", name)
+					report.detail("Auto-discovered standard library %s", name)
 				}
 			}
 		}
@@ -314,41 +437,16 @@ func collectImports(mainFile, mainSource string) (map[string]string, error) {
 			return nil
 		}
 
-		// Try to find the file
-		var fileContent []byte
-		var readErr error
-
-		// Try direct path first
-		fileContent, readErr = os.ReadFile(imp.Path)
-		if readErr == nil {
-			imports[imp.Path] = string(fileContent)
-			fmt.Printf("Including imported file %s\n", imp.Path)
-			return collectNestedImports(imp.Path, string(fileContent), imports, workingDir, baseDir)
-		}
-
-		// Try multiple possible paths
-		possiblePaths := []string{
-			filepath.Join(baseDir, imp.Path),
-			imp.Path + ".bn",
-			filepath.Join(baseDir, imp.Path+".bn"),
-			filepath.Join(baseDir, "src", "lib", imp.Path),
-			filepath.Join(baseDir, "src", "lib", imp.Path+".bn"),
-			filepath.Join(baseDir, "src", "lib", "std", imp.Path),
-			filepath.Join(baseDir, "src", "lib", "std", imp.Path+".bn"),
-		}
-
-		for _, path := range possiblePaths {
-			fileContent, readErr = os.ReadFile(path)
-			if readErr == nil {
-				imports[imp.Path] = string(fileContent)
-				fmt.Printf("Including imported file %s\n", path)
-				return collectNestedImports(path, string(fileContent), imports, workingDir, baseDir)
-			}
+		dirs := []string{baseDir, filepath.Join(baseDir, "src", "lib"), filepath.Join(baseDir, "src", "lib", "std")}
+		if module, err := (resolver.FileSystem{Dirs: dirs}).Resolve(imp.Path, baseDir); err == nil {
+			imports[imp.Path] = module.Source
+			report.detail("Including imported file %s", module.Path)
+			return collectNestedImports(module.Path, module.Source, imports, workingDir, baseDir, report)
 		}
 
 		// If we get here and it's a std/ import, don't error - it might be handled elsewhere
 		if strings.HasPrefix(imp.Path, "std/") {
-			fmt.Printf("Warning: Could not find standard library file for %s, using built-in if available\n", imp.Path)
+			report.warn("Could not find standard library file for %s, using built-in if available", imp.Path)
 			return nil
 		}
 
@@ -373,7 +471,7 @@ func collectImports(mainFile, mainSource string) (map[string]string, error) {
 	return imports, nil
 }
 
-func collectNestedImports(filePath, source string, imports map[string]string, workingDir, originBaseDir string) error {
+func collectNestedImports(filePath, source string, imports map[string]string, workingDir, originBaseDir string, report reporter) error {
 	lex := lexer.New(source)
 	tokens, err := lex.Tokenize()
 	if err != nil {
@@ -399,7 +497,7 @@ func collectNestedImports(filePath, source string, imports map[string]string, wo
 			libName = strings.TrimSuffix(libName, ".bn")
 			if content, exists := stdlib.StdLibFiles[libName]; exists {
 				imports[imp.Path] = content
-				fmt.Printf("Including standard library %s (built-in)\n", libName)
+				report.detail("Including standard library %s (built-in)", libName)
 				return nil
 			}
 		}
@@ -411,33 +509,20 @@ func collectNestedImports(filePath, source string, imports map[string]string, wo
 
 		if stdLib, exists := stdlib.StdLibFiles[baseName]; exists {
 			imports[imp.Path] = stdLib
-			fmt.Printf("Including standard library %s (built-in)\n", baseName)
+			report.detail("Including standard library %s (built-in)", baseName)
 			return nil
 		}
 
-		possiblePaths := []string{
-			imp.Path,
-			filepath.Join(baseDir, imp.Path),
-			filepath.Join(workingDir, imp.Path),
-			imp.Path + ".bn",
-			filepath.Join(baseDir, imp.Path+".bn"),
-			filepath.Join(workingDir, imp.Path+".bn"),
-			filepath.Join(originBaseDir, "src", "lib", imp.Path),
-			filepath.Join(originBaseDir, "src", "lib", imp.Path+".bn"),
-		}
-
-		for _, path := range possiblePaths {
-			fileContent, readErr := os.ReadFile(path)
-			if readErr == nil {
-				imports[imp.Path] = string(fileContent)
-				fmt.Printf("Including nested import %s\n", path)
-				return collectNestedImports(path, string(fileContent), imports, workingDir, originBaseDir)
-			}
+		dirs := []string{baseDir, workingDir, filepath.Join(originBaseDir, "src", "lib")}
+		if module, err := (resolver.FileSystem{Dirs: dirs}).Resolve(imp.Path, baseDir); err == nil {
+			imports[imp.Path] = module.Source
+			report.detail("Including nested import %s", module.Path)
+			return collectNestedImports(module.Path, module.Source, imports, workingDir, originBaseDir, report)
 		}
 
 		// If we get here and it's a std/ import, don't error - it might be handled elsewhere
 		if strings.HasPrefix(imp.Path, "std/") {
-			fmt.Printf("Warning: Could not find standard library file for %s, using built-in if available\n", imp.Path)
+			report.warn("Could not find standard library file for %s, using built-in if available", imp.Path)
 			return nil
 		}
 
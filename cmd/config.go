@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/burnlang/burn/pkg/interpreter"
+	"github.com/burnlang/burn/pkg/suggest"
+	"github.com/burnlang/burn/pkg/toml"
+)
+
+const projectConfigFile = ".burn.toml"
+
+// configKeys are the keys a config file may set, with those of its tables
+// under "table.key"
+var configKeys = []string{"strict", "color", "import_paths", "capabilities", "capabilities.deny", "capabilities.deny_builtins"}
+
+// capabilityNames maps the names of capabilities in config files to them
+var capabilityNames = map[string]interpreter.Capability{
+	"network": interpreter.CapabilityNetwork,
+	"input":   interpreter.CapabilityInput,
+}
+
+// config holds the CLI defaults read from the user and project config files.
+// Command-line flags are applied on top of it.
+type config struct {
+	strict      bool
+	noColor     bool
+	importPaths []string
+
+	// deny and denyBuiltins are the capabilities and builtins programs
+	// may not use
+	deny         interpreter.Capability
+	denyBuiltins []string
+
+	// warnings are about keys of the config files that mean nothing
+	warnings []string
+}
+
+// loadConfig reads ~/.config/burn/config.toml and then the nearest
+// .burn.toml in the working directory or its parents. Project settings
+// override user settings; import paths from both are kept, project first,
+// and what either denies is denied.
+func loadConfig() (config, error) {
+	var cfg config
+
+	if path := userConfigPath(); path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return cfg, err
+		}
+	}
+
+	if path := findProjectConfig(); path != "" {
+		user := cfg.importPaths
+		cfg.importPaths = nil
+		if err := cfg.loadFile(path); err != nil {
+			return cfg, err
+		}
+		cfg.importPaths = append(cfg.importPaths, user...)
+	}
+
+	return cfg, nil
+}
+
+func userConfigPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "burn", "config.toml")
+}
+
+func findProjectConfig() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, projectConfigFile)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// loadFile applies the settings of one config file. A missing file is not
+// an error. Relative import paths are resolved against the file's directory.
+func (c *config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	root, err := toml.Parse(string(data))
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %v", path, err)
+	}
+	c.checkKeys(path, root, "")
+
+	if strict, ok := root.Bool("strict"); ok {
+		c.strict = strict
+	}
+	if color, ok := root.Bool("color"); ok {
+		c.noColor = !color
+	}
+	for _, dir := range root.Strings("import_paths") {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(path), dir)
+		}
+		c.importPaths = append(c.importPaths, dir)
+	}
+
+	capabilities := root.Table("capabilities")
+	for _, name := range capabilities.Strings("deny") {
+		capability, ok := capabilityNames[name]
+		if !ok {
+			return fmt.Errorf("%s: unknown capability %q in capabilities.deny%s", path, name, suggest.Hint(name, maps.Keys(capabilityNames)))
+		}
+		c.deny |= capability
+	}
+	c.denyBuiltins = append(c.denyBuiltins, capabilities.Strings("deny_builtins")...)
+
+	return nil
+}
+
+// checkKeys records a warning for each key of table, whose keys are named
+// with prefix, that is not a config key, such as a misspelled one
+func (c *config) checkKeys(path string, table toml.Table, prefix string) {
+	for _, key := range table.Keys() {
+		name := prefix + key
+		if !slices.Contains(configKeys, name) {
+			c.warnings = append(c.warnings, fmt.Sprintf("%s: unknown key %s%s", path, name, suggest.Hint(name, slices.Values(configKeys))))
+			continue
+		}
+		if nested := table.Table(key); nested != nil {
+			c.checkKeys(path, nested, name+".")
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// constant is a value defined on the command line with -D NAME[:TYPE]=VALUE
+type constant struct {
+	name     string
+	typeName string
+	value    interface{}
+}
+
+// parseDefine parses a -D argument. Without an explicit type the value is
+// an int, float or bool when it reads as one, and a string otherwise.
+func parseDefine(spec string) (constant, error) {
+	name, raw, found := strings.Cut(spec, "=")
+	if !found {
+		return constant{}, fmt.Errorf("invalid -D %q, expected NAME=VALUE", spec)
+	}
+	name, typeName, _ := strings.Cut(name, ":")
+
+	if !isIdentifier(name) {
+		return constant{}, fmt.Errorf("invalid -D %q: %q is not a valid name", spec, name)
+	}
+
+	if typeName == "" {
+		typeName = inferDefineType(raw)
+	}
+
+	c := constant{name: name, typeName: typeName}
+	switch typeName {
+	case "int":
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return constant{}, fmt.Errorf("invalid -D %s: %q is not an int", name, raw)
+		}
+		c.value = float64(n)
+	case "float":
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return constant{}, fmt.Errorf("invalid -D %s: %q is not a float", name, raw)
+		}
+		c.value = f
+	case "bool":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return constant{}, fmt.Errorf("invalid -D %s: %q is not a bool", name, raw)
+		}
+		c.value = b
+	case "string":
+		c.value = raw
+	default:
+		return constant{}, fmt.Errorf("invalid -D %s: type must be int, float, bool or string", name)
+	}
+
+	return c, nil
+}
+
+func inferDefineType(raw string) string {
+	if _, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return "int"
+	}
+	if _, err := strconv.ParseFloat(raw, 64); err == nil && !strings.ContainsAny(raw, "nN") {
+		return "float"
+	}
+	if raw == "true" || raw == "false" {
+		return "bool"
+	}
+	return "string"
+}
+
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/diagnostic"
+	"github.com/burnlang/burn/pkg/errcode"
+	"github.com/burnlang/burn/pkg/suggest"
+	"github.com/burnlang/burn/pkg/typechecker"
+)
+
+// sourceError is an error located in a Burn source file. Its Error text is
+// the one-line "X error at line N, column M: msg" form; printError renders
+// it as a diagnostic with the offending source line underlined.
+type sourceError struct {
+	kind   string
+	err    error
+	file   string
+	source string
+	pos    int
+	loc    ast.Location
+}
+
+// lineAndCol returns the line and column of the error, scanning the source
+// only when the stage that failed did not know them
+func (e *sourceError) lineAndCol() (int, int) {
+	if e.loc.IsValid() {
+		return e.loc.Line, e.loc.Column
+	}
+	return getLineAndCol(e.source, e.pos)
+}
+
+func (e *sourceError) Error() string {
+	if e.pos < 0 || strings.Contains(e.err.Error(), "at line") {
+		return fmt.Sprintf("%s: %v", e.kind, e.err)
+	}
+	line, col := e.lineAndCol()
+	return fmt.Sprintf("%s at line %d, column %d: %v", e.kind, line, col, e.err)
+}
+
+func (e *sourceError) Unwrap() error {
+	return e.err
+}
+
+// code returns the code of the error, which burn --explain describes
+func (e *sourceError) code() (errcode.Code, bool) {
+	return errcode.Of(e.err)
+}
+
+// errorPrinter writes CLI errors and warnings as diagnostics
+type errorPrinter struct {
+	color bool
+}
+
+func (p errorPrinter) renderer() diagnostic.Renderer {
+	return diagnostic.Renderer{Color: p.color}
+}
+
+// printError writes err to w. Errors located in source code are followed by
+// the file name and the source line with the offending span underlined.
+// Each of several errors joined into err is written in turn, and errors
+// with a code are followed by how to learn more about the first code.
+func (p errorPrinter) printError(w io.Writer, err error) {
+	var first string
+	p.printErrors(w, err, &first)
+	if first != "" {
+		fmt.Fprintf(w, "For more information about this error, run burn --explain %s\n", first)
+	}
+}
+
+// printErrors writes each error joined into err, recording the first code
+// among them in first
+func (p errorPrinter) printErrors(w io.Writer, err error, first *string) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			p.printErrors(w, err, first)
+		}
+		return
+	}
+
+	var srcErr *sourceError
+	if !errors.As(err, &srcErr) {
+		p.renderer().Render(w, diagnostic.Diagnostic{Message: err.Error()})
+		return
+	}
+
+	d := diagnostic.Diagnostic{File: srcErr.file, Source: srcErr.source}
+	d.Message, d.Help = withoutHint(srcErr.Error())
+	if srcErr.pos >= 0 {
+		d.Line, d.Column = srcErr.lineAndCol()
+	}
+	if code, ok := srcErr.code(); ok {
+		d.Code = code.ID
+		if *first == "" {
+			*first = code.ID
+		}
+	}
+	p.renderer().Render(w, d)
+}
+
+// printWarning writes a typechecker warning found in source. Under
+// --werror it says that the warning stops the program.
+func (p errorPrinter) printWarning(w io.Writer, warning typechecker.Warning, file, source string, pos int, werror bool) {
+	d := diagnostic.Diagnostic{Severity: diagnostic.Warning, File: file, Source: source}
+	d.Message, d.Help = withoutHint(warning.Message)
+	d.Line, d.Column = warningLineAndCol(warning, source, pos)
+	d.Code = warning.Code
+	if werror {
+		d.Notes = append(d.Notes, "treated as an error because of --werror")
+	}
+	p.renderer().Render(w, d)
+}
+
+// withoutHint moves a "did you mean" hint out of an error message into a
+// suggestion of its own
+func withoutHint(msg string) (string, string) {
+	if msg, name, ok := suggest.CutHint(msg); ok {
+		return msg, "did you mean " + name + "?"
+	}
+	return msg, ""
+}
+
+// colorEnabled reports whether output to w should use ANSI colors: only for
+// terminals, and never with --no-color, NO_COLOR set or TERM=dumb
+func colorEnabled(w io.Writer, noColor bool) bool {
+	return diagnostic.ColorEnabled(w, noColor)
+}
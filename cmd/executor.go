@@ -6,14 +6,56 @@ import (
 	"os"
 	"strings"
 
+	"github.com/burnlang/burn/pkg/ast"
 	"github.com/burnlang/burn/pkg/interpreter"
 	"github.com/burnlang/burn/pkg/lexer"
 	"github.com/burnlang/burn/pkg/parser"
 	"github.com/burnlang/burn/pkg/typechecker"
+	"github.com/burnlang/burn/pkg/vm"
 )
 
+const (
+	// exitError reports that the program could not be read, parsed or typechecked
+	exitError = 1
+	// exitRuntimeError reports that the program failed while running
+	exitRuntimeError = 2
+)
+
+// runOptions controls how a program is executed
+type runOptions struct {
+	debug      bool
+	profile    bool
+	profileOut string
+	trace      bool
+	traceVars  bool
+	vm         bool
+	noCache    bool
+	maxDepth   int
+	maxErrors  int
+	strict     bool
+	werror     bool
+	noColor    bool
+	filename   string
+
+	// cachePath is where the parsed and typechecked program is cached, or
+	// "" not to cache it
+	cachePath string
+
+	importPaths []string
+	constants   []constant
+
+	// deny and policy restrict the capabilities and builtins the program
+	// may use
+	deny   interpreter.Capability
+	policy interpreter.Policy
+
+	// sources is set when the program is made of several files
+	sources  *sourceSet
+	profiler *interpreter.Profiler
+}
+
 // executeFile executes a Burn source file
-func executeFile(filename string, debug bool, stdout, stderr io.Writer) int {
+func executeFile(filename string, opts runOptions, stdout, stderr io.Writer) int {
 	if !strings.HasSuffix(filename, ".bn") {
 		fmt.Fprintf(stderr, "Warning: File %s does not have the .bn extension\n", filename)
 	}
@@ -21,33 +63,198 @@ func executeFile(filename string, debug bool, stdout, stderr io.Writer) int {
 	source, err := os.ReadFile(filename)
 	if err != nil {
 		fmt.Fprintf(stderr, "Error reading file: %v\n", err)
-		return 1
+		return exitError
 	}
 
-	return executeCode(string(source), debug, stdout, stderr)
+	opts.filename = filename
+	if !opts.noCache && !opts.debug {
+		opts.cachePath = cachePath(filename)
+	}
+	return executeCode(string(source), opts, stdout, stderr)
+}
+
+// executeFiles executes a program made of several files or package directories
+func executeFiles(paths []string, opts runOptions, stdout, stderr io.Writer) int {
+	set, err := loadSourceSet(paths)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error reading files: %v\n", err)
+		return exitError
+	}
+
+	opts.filename = set.name
+	opts.sources = set
+	return executeCode(set.source, opts, stdout, stderr)
 }
 
 // executeCode executes Burn code from a string
-func executeCode(source string, debug bool, stdout, stderr io.Writer) int {
-	result, err := execute(source, debug, stdout)
+func executeCode(source string, opts runOptions, stdout, stderr io.Writer) int {
+	if opts.profile || opts.profileOut != "" {
+		opts.profiler = interpreter.NewProfiler()
+	}
+
+	result, code, err := execute(source, opts, stdout, stderr)
+
+	if opts.profiler != nil {
+		if code := writeProfile(opts.profiler, opts, stderr); code != 0 {
+			return code
+		}
+	}
+
 	if err != nil {
-		fmt.Fprintf(stderr, "Error: %v\n", err)
-		return 1
+		if opts.sources != nil {
+			err = opts.sources.relocate(err)
+		}
+		printer := errorPrinter{color: colorEnabled(stderr, opts.noColor)}
+		printer.printError(stderr, err)
+		return code
 	}
 
-	if result != nil && debug {
+	if result != nil && opts.debug {
 		fmt.Fprintln(stdout, "Program result:", result)
 	}
 
+	return code
+}
+
+// writeProfile prints the profile table and writes the pprof file, if requested
+func writeProfile(profiler *interpreter.Profiler, opts runOptions, stderr io.Writer) int {
+	if opts.profile {
+		fmt.Fprintln(stderr, "--- Profile ---")
+		profiler.WriteReport(stderr)
+	}
+
+	if opts.profileOut == "" {
+		return 0
+	}
+
+	file, err := os.Create(opts.profileOut)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error writing profile: %v\n", err)
+		return 1
+	}
+	defer file.Close()
+
+	if err := profiler.WritePprof(file); err != nil {
+		fmt.Fprintf(stderr, "Error writing profile: %v\n", err)
+		return 1
+	}
 	return 0
 }
 
-// execute performs the actual execution of Burn code
-func execute(source string, debug bool, stdout io.Writer) (interface{}, error) {
-	lex := lexer.New(source)
-	tokens, err := lex.Tokenize()
+// execute performs the actual execution of Burn code. Besides the result it
+// returns the exit code: the value returned by `fun main(): int`, or
+// exitError/exitRuntimeError when err is set.
+func execute(source string, opts runOptions, stdout, stderr io.Writer) (interface{}, int, error) {
+	debug := opts.debug
+
+	var program *ast.Program
+	if opts.cachePath != "" {
+		program = readCache(source, opts)
+	}
+	if program == nil {
+		var err error
+		if program, err = check(source, opts, stdout, stderr); err != nil {
+			return nil, exitError, err
+		}
+	}
+
+	interp := interpreter.New()
+	interp.SetFile(opts.filename)
+	for _, dir := range opts.importPaths {
+		interp.AddImportPath(dir)
+	}
+	for _, c := range opts.constants {
+		interp.DefineConstant(c.name, c.value)
+	}
+	interp.Deny(opts.deny)
+	interp.SetPolicy(opts.policy)
+	if opts.maxDepth > 0 {
+		interp.SetLimits(interpreter.Limits{MaxDepth: opts.maxDepth})
+	}
+	if opts.profiler != nil {
+		interp.AddCallHook(opts.profiler)
+	}
+	if opts.trace || opts.traceVars {
+		tracer := interpreter.NewTracer(stderr, interp)
+		tracer.ShowValues = opts.traceVars
+		tracer.AddSource(opts.filename, source)
+		interp.AddStatementHook(tracer)
+	}
+
+	if opts.vm {
+		compiled, err := compileVM(program, opts)
+		if err == nil {
+			if debug {
+				fmt.Fprintln(stdout, "--- Bytecode ---")
+				compiled.Disassemble(stdout)
+				fmt.Fprintln(stdout)
+			}
+			machine := vm.New(interp)
+			machine.SetMaxDepth(opts.maxDepth)
+			result, err := machine.Run(compiled)
+			if err != nil {
+				return nil, exitRuntimeError, formattedError("Runtime error", err, opts.filename, source, machine.Position(), machine.Location())
+			}
+			return result, interpreter.ExitCode(program, result), nil
+		}
+		fmt.Fprintf(stderr, "Note: %v; running on the interpreter\n", err)
+	}
+
+	result, err := interp.Interpret(program)
 	if err != nil {
-		return nil, formattedError("Lexical error", err, source, lex.Position())
+		return nil, exitRuntimeError, runtimeError(err, interp, opts.filename, source)
+	}
+
+	return result, interpreter.ExitCode(program, result), nil
+}
+
+// compileVM compiles a program for --vm, unless it needs the interpreter
+func compileVM(program *ast.Program, opts runOptions) (*vm.Program, error) {
+	switch {
+	case opts.profiler != nil:
+		return nil, fmt.Errorf("the VM does not support profiling yet")
+	case opts.trace || opts.traceVars:
+		return nil, fmt.Errorf("the VM does not support tracing yet")
+	case opts.sources != nil:
+		return nil, fmt.Errorf("the VM does not support programs of several files yet")
+	}
+	return vm.Compile(program)
+}
+
+// runtimeError locates a runtime error, which may lie in an imported file
+// rather than in the program being run
+func runtimeError(err error, interp *interpreter.Interpreter, filename, source string) error {
+	errFile := interp.ErrorFile()
+	if errFile == filename {
+		return formattedError("Runtime error", err, filename, source, interp.Position(), interp.Location())
+	}
+
+	if errFile != "" {
+		if errSource, readErr := os.ReadFile(errFile); readErr == nil {
+			return formattedError("Runtime error", err, errFile, string(errSource), interp.Position(), interp.Location())
+		}
+	}
+	return &sourceError{kind: "Runtime error", err: err, file: errFile, pos: -1}
+}
+
+// check lexes, parses and typechecks source, printing any warnings, and
+// caches the program if opts asks for it
+func check(source string, opts runOptions, stdout, stderr io.Writer) (*ast.Program, error) {
+	debug := opts.debug
+
+	var tokens []lexer.Token
+	var err error
+	if opts.sources != nil {
+		tokens, err = opts.sources.tokenize()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		lex := lexer.New(source)
+		tokens, err = lex.Tokenize()
+		if err != nil {
+			return nil, formattedError("Lexical error", err, opts.filename, source, lex.Position(), ast.Location{})
+		}
 	}
 
 	if debug {
@@ -61,21 +268,43 @@ func execute(source string, debug bool, stdout io.Writer) (interface{}, error) {
 		fmt.Fprintln(stdout)
 	}
 
-	p := parser.New(tokens)
-	program, err := p.Parse()
-	if err != nil {
-		return nil, formattedError("Parse error", err, source, p.Position())
+	program, syntaxErrs := parser.New(tokens).ParseAll()
+	if len(syntaxErrs) > 0 {
+		return nil, syntaxErrors(syntaxErrs, opts.maxErrors, opts.filename, source)
 	}
 
 	if debug {
 		fmt.Fprintln(stdout, "--- AST ---")
-		printAST(program, 0, stdout)
+		printAST(program, source, stdout)
 		fmt.Fprintln(stdout)
 	}
 
 	tc := typechecker.New()
-	if err := tc.Check(program.Declarations); err != nil {
-		return nil, formattedError("Type error", err, source, tc.Position())
+	tc.SetStrict(opts.strict)
+	for _, dir := range opts.importPaths {
+		tc.AddImportPath(dir)
+	}
+	for _, c := range opts.constants {
+		tc.DefineConstant(c.name, c.typeName)
+	}
+	if err := tc.ProcessImports(program.Declarations, "."); err != nil {
+		return nil, formattedError("Import error", err, opts.filename, source, tc.Position(), tc.Location())
+	}
+	errs := tc.CheckAll(program.Declarations)
+	printer := errorPrinter{color: colorEnabled(stderr, opts.noColor)}
+	for _, warning := range tc.Warnings() {
+		if opts.sources != nil {
+			file, pos := opts.sources.locate(warning.Position)
+			printer.printWarning(stderr, warning, file.path, file.source, pos, opts.werror)
+			continue
+		}
+		printer.printWarning(stderr, warning, opts.filename, source, warning.Position, opts.werror)
+	}
+	if len(errs) > 0 {
+		return nil, typeErrors(errs, opts.maxErrors, opts.filename, source)
+	}
+	if n := len(tc.Warnings()); n > 0 && opts.werror {
+		return nil, fmt.Errorf("%d warnings treated as errors (--werror)", n)
 	}
 
 	if debug {
@@ -83,11 +312,10 @@ func execute(source string, debug bool, stdout io.Writer) (interface{}, error) {
 		fmt.Fprintln(stdout)
 	}
 
-	interpreter := interpreter.New()
-	result, err := interpreter.Interpret(program)
-	if err != nil {
-		return nil, formattedError("Runtime error", err, source, interpreter.Position())
+	// A program with warnings is checked again on every run, so that they
+	// keep being shown
+	if opts.cachePath != "" && len(tc.Warnings()) == 0 {
+		writeCache(program, source, opts)
 	}
-
-	return result, nil
+	return program, nil
 }
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/burnlang/burn/pkg/errcode"
+)
+
+// explain writes the explanation of an error code, or lists every code
+// with its title when id is empty
+func explain(id string, stdout, stderr io.Writer) int {
+	if id == "" {
+		for _, code := range errcode.All() {
+			fmt.Fprintf(stdout, "%s  %-13s  %s\n", code.ID, code.Kind, code.Title)
+		}
+		return 0
+	}
+
+	code, ok := errcode.Lookup(id)
+	if !ok {
+		fmt.Fprintf(stderr, "Error: unknown error code %s (run burn --explain to list them)\n", id)
+		return 1
+	}
+	fmt.Fprintf(stdout, "%s: %s (%s)\n\n%s\n", code.ID, code.Title, code.Kind, code.Explanation)
+	return 0
+}
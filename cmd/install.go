@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/burnlang/burn/pkg/pkgmgr"
+)
+
+// runInstall fetches all dependencies declared in burn.toml into burn_modules
+func runInstall(args []string, stdout, stderr io.Writer) int {
+	args, report := installFlags(args, stderr)
+	if len(args) > 0 {
+		fmt.Fprintln(stderr, "Error: install does not take arguments, use 'burn add' to declare a dependency")
+		return 1
+	}
+
+	return install(report, stderr)
+}
+
+// runAdd declares a new dependency in burn.toml and installs it
+func runAdd(args []string, stdout, stderr io.Writer) int {
+	args, report := installFlags(args, stderr)
+	if len(args) != 2 {
+		fmt.Fprintln(stderr, "Usage: burn add [-q] <name> <git-url[@rev]|tarball-url>")
+		return 1
+	}
+
+	dep := pkgmgr.ParseDependency(args[0], args[1])
+	if err := pkgmgr.AddDependency(".", dep); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	report.status("Added %s to %s", dep.Name, pkgmgr.ManifestFile)
+	return install(report, stderr)
+}
+
+func install(report reporter, stderr io.Writer) int {
+	if err := pkgmgr.Install(".", report.writer(verbosityNormal)); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// installFlags removes -q/--quiet from the arguments of install and add
+func installFlags(args []string, stderr io.Writer) ([]string, reporter) {
+	report := reporter{out: stderr, level: verbosityNormal}
+	var rest []string
+	for _, arg := range args {
+		if arg == "-q" || arg == "--quiet" {
+			report.level = verbosityQuiet
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return rest, report
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+)
+
+// verbosity controls how much informational output the CLI prints
+type verbosity int
+
+const (
+	verbosityQuiet verbosity = iota
+	verbosityNormal
+	verbosityVerbose
+)
+
+// verbosityFrom returns the level selected by -q/--quiet and --verbose
+func verbosityFrom(options map[string]bool) verbosity {
+	switch {
+	case options["quiet"]:
+		return verbosityQuiet
+	case options["verbose"]:
+		return verbosityVerbose
+	default:
+		return verbosityNormal
+	}
+}
+
+// reporter prints informational messages, such as build progress, to stderr
+// so that stdout only carries the output of the program itself
+type reporter struct {
+	out   io.Writer
+	level verbosity
+}
+
+// status prints a progress message unless running quietly
+func (r reporter) status(format string, args ...interface{}) {
+	if r.level >= verbosityNormal {
+		fmt.Fprintf(r.out, format+"\n", args...)
+	}
+}
+
+// detail prints a message only shown with --verbose
+func (r reporter) detail(format string, args ...interface{}) {
+	if r.level >= verbosityVerbose {
+		fmt.Fprintf(r.out, format+"\n", args...)
+	}
+}
+
+// writer returns a writer for messages shown at level, which discards them
+// when running at a lower verbosity
+func (r reporter) writer(level verbosity) io.Writer {
+	if r.level < level {
+		return io.Discard
+	}
+	return r.out
+}
+
+// warn prints a warning at every verbosity level
+func (r reporter) warn(format string, args ...interface{}) {
+	fmt.Fprintf(r.out, "Warning: "+format+"\n", args...)
+}
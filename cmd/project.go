@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/lexer"
+)
+
+// sourceFile is one file of a program assembled from several files
+type sourceFile struct {
+	path   string
+	source string
+	offset int
+}
+
+// sourceSet is a program made of several files, such as a package
+// directory. The files share their types and functions without importing
+// each other: they are lexed one by one and their tokens are joined into a
+// single program, with positions offset as if the files were concatenated.
+type sourceSet struct {
+	name   string
+	files  []sourceFile
+	source string
+}
+
+// loadSourceSet reads the named files and the .bn files directly inside the
+// named directories, leaving out tests and benchmarks
+func loadSourceSet(paths []string) (*sourceSet, error) {
+	set := &sourceSet{name: strings.Join(paths, " ")}
+	var joined strings.Builder
+
+	for _, path := range paths {
+		files, err := packageFiles(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			set.files = append(set.files, sourceFile{path: file, source: string(data), offset: joined.Len()})
+			joined.Write(data)
+			joined.WriteByte('\n')
+		}
+	}
+
+	if len(set.files) == 0 {
+		return nil, fmt.Errorf("no .bn files found in %s", set.name)
+	}
+	set.source = joined.String()
+	return set, nil
+}
+
+// packageFiles returns path itself for a file, or the sorted .bn files of a
+// directory other than *_test.bn and *_bench.bn
+func packageFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".bn") ||
+			strings.HasSuffix(name, testFileSuffix) || strings.HasSuffix(name, benchFileSuffix) {
+			continue
+		}
+		files = append(files, filepath.Join(path, name))
+	}
+	return files, nil
+}
+
+// isMultiFile reports whether the paths given on the command line name more
+// than one file, either directly or through a directory
+func isMultiFile(paths []string) bool {
+	if len(paths) != 1 {
+		return len(paths) > 1
+	}
+	info, err := os.Stat(paths[0])
+	return err == nil && info.IsDir()
+}
+
+// tokenize lexes every file and returns the joined token stream
+func (s *sourceSet) tokenize() ([]lexer.Token, error) {
+	var tokens []lexer.Token
+
+	for _, file := range s.files {
+		lex := lexer.New(file.source)
+		fileTokens, err := lex.Tokenize()
+		if err != nil {
+			return nil, formattedError("Lexical error", err, file.path, file.source, lex.Position(), ast.Location{})
+		}
+		for _, token := range fileTokens {
+			if token.Type == lexer.TokenEOF {
+				continue
+			}
+			token.Position += file.offset
+			tokens = append(tokens, token)
+		}
+	}
+
+	return append(tokens, lexer.Token{Type: lexer.TokenEOF, Position: len(s.source)}), nil
+}
+
+// locate maps a position in the joined program to a file and a position in it
+func (s *sourceSet) locate(pos int) (*sourceFile, int) {
+	for i := len(s.files) - 1; i > 0; i-- {
+		if pos >= s.files[i].offset {
+			return &s.files[i], pos - s.files[i].offset
+		}
+	}
+	return &s.files[0], pos
+}
+
+// relocate makes an error located in the joined program point into the file
+// it came from. The line and column of the error, when known, already refer
+// to that file, since every file is lexed on its own.
+func (s *sourceSet) relocate(err error) error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs := joined.Unwrap()
+		relocated := make([]error, len(errs))
+		for j, err := range errs {
+			relocated[j] = s.relocate(err)
+		}
+		return errors.Join(relocated...)
+	}
+
+	var srcErr *sourceError
+	if !errors.As(err, &srcErr) || srcErr.file != s.name || srcErr.pos < 0 {
+		return err
+	}
+
+	file, pos := s.locate(srcErr.pos)
+	return &sourceError{kind: srcErr.kind, err: srcErr.err, file: file.path, source: file.source, pos: pos, loc: srcErr.loc}
+}
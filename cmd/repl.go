@@ -1,16 +1,97 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"os"
 	"strings"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/burn"
 )
 
-func startREPL(stdin io.Reader, stdout, stderr io.Writer) int {
+// replSession holds the state of an interactive session, an engine whose
+// runs see the functions, types and variables of the earlier ones. A
+// declaration made again, such as by loading a file a second time,
+// replaces the earlier one.
+type replSession struct {
+	engine *burn.Engine
+}
+
+func newREPLSession(opts runOptions) *replSession {
+	engine := burn.NewEngine(burn.Options{
+		ImportPaths: opts.importPaths,
+		Limits:      burn.Limits{MaxDepth: opts.maxDepth},
+		Deny:        opts.deny,
+		Policy:      opts.policy,
+	})
+	for _, c := range opts.constants {
+		engine.DefineConstant(c.name, c.typeName, c.value)
+	}
+	return &replSession{engine: engine}
+}
+
+// eval runs a line of input in the session and returns its value as print
+// shows it
+func (s *replSession) eval(line string) (interface{}, error) {
+	result, _, err := s.engine.EvalChunk(line)
+	if err == nil {
+		result, err = s.engine.Display(result)
+	}
+	if err != nil {
+		return nil, engineError(err, "", line)
+	}
+	return result, nil
+}
+
+// load evaluates the declarations and top-level statements of a file into
+// the session. Its main function is defined but not run.
+func (s *replSession) load(filename string) error {
+	if err := s.engine.LoadFile(filename); err != nil {
+		source, _ := os.ReadFile(filename)
+		return engineError(err, filename, string(source))
+	}
+	return nil
+}
+
+// engineError converts an error of the engine running source, which file
+// names, to a sourceError, so that it is printed with the source line it
+// points at. Errors in other files, such as imports, are shown with theirs.
+func engineError(err error, file, source string) error {
+	var burnErr *burn.Error
+	if !errors.As(err, &burnErr) {
+		return err
+	}
+	if burnErr.File != file {
+		file, source = burnErr.File, ""
+		if content, readErr := os.ReadFile(file); readErr == nil {
+			source = string(content)
+		}
+	}
+	srcErr := &sourceError{kind: string(burnErr.Kind), err: burnErr.Err, file: file, source: source, pos: -1}
+	if burnErr.Line > 0 && source != "" {
+		srcErr.pos = 0
+		srcErr.loc = ast.Location{Line: burnErr.Line, Column: burnErr.Column}
+	}
+	return srcErr
+}
+
+func startREPL(opts runOptions, files []string, stdin io.Reader, stdout, stderr io.Writer) int {
 	fmt.Fprintf(stdout, "Burn Programming Language v%s\n", getVersion())
 	fmt.Fprintln(stdout, "Type 'exit' to quit, 'help' for more information")
 
 	buf := make([]byte, 1024)
+	printer := errorPrinter{color: colorEnabled(stderr, opts.noColor)}
+	session := newREPLSession(opts)
+
+	for _, file := range files {
+		if err := session.load(file); err != nil {
+			printer.printError(stderr, err)
+			return 1
+		}
+		fmt.Fprintf(stdout, "Loaded %s\n", file)
+	}
 
 	for {
 		fmt.Fprint(stdout, "> ")
@@ -37,9 +118,19 @@ func startREPL(stdin io.Reader, stdout, stderr io.Writer) int {
 			continue
 		}
 
-		result, err := execute(line, false, stdout)
+		if strings.HasPrefix(line, ":load ") {
+			file := strings.TrimSpace(strings.TrimPrefix(line, ":load "))
+			if err := session.load(file); err != nil {
+				printer.printError(stderr, err)
+			} else {
+				fmt.Fprintf(stdout, "Loaded %s\n", file)
+			}
+			continue
+		}
+
+		result, err := session.eval(line)
 		if err != nil {
-			fmt.Fprintf(stderr, "Error: %v\n", err)
+			printer.printError(stderr, err)
 		} else if result != nil {
 			fmt.Fprintf(stdout, "=> %v\n", result)
 		}
@@ -52,6 +143,7 @@ func printReplHelp(w io.Writer) {
 	fmt.Fprintln(w, "Burn REPL commands:")
 	fmt.Fprintln(w, "  exit, quit  - Exit the REPL")
 	fmt.Fprintln(w, "  help        - Show this help message")
+	fmt.Fprintln(w, "  :load FILE  - Load the declarations of a file into the session")
 	fmt.Fprintln(w, "")
 	fmt.Fprintln(w, "Examples:")
 	fmt.Fprintln(w, "  > print(\"Hello, world!\")")
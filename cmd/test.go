@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/coverage"
+	"github.com/burnlang/burn/pkg/interpreter"
+	"github.com/burnlang/burn/pkg/lexer"
+	"github.com/burnlang/burn/pkg/parser"
+	"github.com/burnlang/burn/pkg/pkgmgr"
+	"github.com/burnlang/burn/pkg/typechecker"
+)
+
+const testFileSuffix = "_test.bn"
+
+type testOptions struct {
+	coverage bool
+	annotate bool
+	lcovFile string
+
+	importPaths []string
+}
+
+// runTest runs the test_* functions of every *_test.bn file under the given
+// paths and optionally reports statement and branch coverage
+func runTest(args []string, stdout, stderr io.Writer) int {
+	var opts testOptions
+	var paths []string
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--coverage":
+			opts.coverage = true
+		case "--coverage-report":
+			opts.coverage = true
+			opts.annotate = true
+		case "--lcov":
+			if i+1 >= len(args) {
+				fmt.Fprintln(stderr, "Error: --lcov requires a file name")
+				return 1
+			}
+			opts.coverage = true
+			opts.lcovFile = args[i+1]
+			i++
+		default:
+			paths = append(paths, args[i])
+		}
+	}
+
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	opts.importPaths = cfg.importPaths
+
+	files, err := findSourceFiles(paths, testFileSuffix)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	if len(files) == 0 {
+		fmt.Fprintln(stderr, "No test files found")
+		return 1
+	}
+
+	var collector *coverage.Collector
+	if opts.coverage {
+		collector = coverage.New()
+		collector.Include = func(path string) bool {
+			return !strings.HasSuffix(path, testFileSuffix)
+		}
+	}
+
+	failed := false
+	for _, file := range files {
+		if !runTestFile(file, opts, collector, stdout) {
+			failed = true
+		}
+	}
+
+	if collector != nil {
+		fmt.Fprintln(stdout)
+		collector.WriteSummary(stdout)
+		if opts.annotate {
+			fmt.Fprintln(stdout)
+			collector.WriteAnnotated(stdout)
+		}
+		if opts.lcovFile != "" {
+			if err := writeLCOVFile(collector, opts.lcovFile); err != nil {
+				fmt.Fprintf(stderr, "Error writing coverage: %v\n", err)
+				return 1
+			}
+		}
+	}
+
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// findSourceFiles returns the explicitly named files plus every file under
+// the named directories ending in one of suffixes, skipping vendored packages
+func findSourceFiles(paths []string, suffixes ...string) ([]string, error) {
+	var files []string
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				name := d.Name()
+				if p != path && (name == pkgmgr.VendorDir || strings.HasPrefix(name, ".")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			for _, suffix := range suffixes {
+				if strings.HasSuffix(p, suffix) {
+					files = append(files, p)
+					break
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+// runTestFile runs the tests of one file and reports whether all passed
+func runTestFile(file string, opts testOptions, collector *coverage.Collector, stdout io.Writer) bool {
+	source, err := os.ReadFile(file)
+	if err != nil {
+		fmt.Fprintf(stdout, "FAIL %s: %v\n", file, err)
+		return false
+	}
+
+	program, err := parseAndCheck(file, string(source), opts.importPaths)
+	if err != nil {
+		fmt.Fprintf(stdout, "FAIL %s: %v\n", file, err)
+		return false
+	}
+
+	var tests []string
+	for _, decl := range program.Declarations {
+		if fn, ok := decl.(*ast.FunctionDeclaration); ok && fn.Receiver == "" && strings.HasPrefix(fn.Name, "test_") {
+			tests = append(tests, fn.Name)
+		}
+	}
+
+	interp := interpreter.New()
+	interp.SetFile(file)
+	for _, dir := range opts.importPaths {
+		interp.AddImportPath(dir)
+	}
+	if collector != nil {
+		interp.AddStatementHook(collector)
+	}
+	if err := interp.Load(program); err != nil {
+		fmt.Fprintf(stdout, "FAIL %s: %v\n", file, err)
+		return false
+	}
+	if collector != nil {
+		for _, loaded := range interp.LoadedFiles() {
+			if collector.Include(loaded) {
+				_ = collector.AddFile(loaded)
+			}
+		}
+	}
+
+	passed := 0
+	start := time.Now()
+	for _, name := range tests {
+		testStart := time.Now()
+		if _, err := interp.Call(name); err != nil {
+			fmt.Fprintf(stdout, "--- FAIL: %s (%s)\n    %v\n",
+				name, formatElapsed(time.Since(testStart)), err)
+			continue
+		}
+		passed++
+		fmt.Fprintf(stdout, "--- PASS: %s (%s)\n", name, formatElapsed(time.Since(testStart)))
+	}
+
+	if passed < len(tests) {
+		fmt.Fprintf(stdout, "FAIL %s: %d of %d tests failed (%s)\n",
+			file, len(tests)-passed, len(tests), formatElapsed(time.Since(start)))
+		return false
+	}
+	fmt.Fprintf(stdout, "ok   %s: %d tests (%s)\n", file, len(tests), formatElapsed(time.Since(start)))
+	return true
+}
+
+// parseAndCheck lexes, parses and typechecks a Burn program
+func parseAndCheck(file, source string, importPaths []string) (*ast.Program, error) {
+	lex := lexer.New(source)
+	tokens, err := lex.Tokenize()
+	if err != nil {
+		return nil, formattedError("Lexical error", err, file, source, lex.Position(), ast.Location{})
+	}
+
+	p := parser.New(tokens)
+	program, err := p.Parse()
+	if err != nil {
+		return nil, formattedError("Parse error", err, file, source, p.Position(), p.Location())
+	}
+
+	tc := typechecker.New()
+	for _, dir := range importPaths {
+		tc.AddImportPath(dir)
+	}
+	if err := tc.ProcessImports(program.Declarations, "."); err != nil {
+		return nil, formattedError("Import error", err, file, source, tc.Position(), tc.Location())
+	}
+	if err := tc.Check(program.Declarations); err != nil {
+		return nil, formattedError("Type error", err, file, source, tc.Position(), tc.Location())
+	}
+
+	return program, nil
+}
+
+func formatElapsed(d time.Duration) string {
+	return fmt.Sprintf("%.3fs", d.Seconds())
+}
+
+func writeLCOVFile(collector *coverage.Collector, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return collector.WriteLCOV(file)
+}
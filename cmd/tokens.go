@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/lexer"
+)
+
+type tokenDump struct {
+	Type   string `json:"type"`
+	Value  string `json:"value"`
+	Pos    int    `json:"pos"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// runTokens prints the token stream of a Burn file as text or JSON
+func runTokens(args []string, stdout, stderr io.Writer) int {
+	var filename string
+	asJSON, noColor, comments := false, false, false
+
+	for _, arg := range args {
+		switch arg {
+		case "--json":
+			asJSON = true
+		case "--no-color":
+			noColor = true
+		case "--comments":
+			comments = true
+		default:
+			filename = arg
+		}
+	}
+
+	if filename == "" {
+		fmt.Fprintln(stderr, "Usage: burn tokens <file.bn> [--json] [--comments] [--no-color]")
+		return 1
+	}
+
+	source, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error reading file: %v\n", err)
+		return 1
+	}
+
+	lex := lexer.New(string(source))
+	lex.SetKeepComments(comments)
+	tokens, err := lex.Tokenize()
+	if err != nil {
+		printer := errorPrinter{color: colorEnabled(stderr, noColor)}
+		printer.printError(stderr, formattedError("Lexical error", err, filename, string(source), lex.Position(), ast.Location{}))
+		return 1
+	}
+
+	dumps := make([]tokenDump, len(tokens))
+	for i, token := range tokens {
+		dumps[i] = tokenDump{
+			Type:   tokenTypeToString(token.Type),
+			Value:  token.Value,
+			Pos:    token.Position,
+			Line:   token.Line,
+			Column: token.Col,
+		}
+	}
+
+	if asJSON {
+		data, err := json.MarshalIndent(dumps, "", "  ")
+		if err != nil {
+			fmt.Fprintf(stderr, "Error encoding tokens: %v\n", err)
+			return 1
+		}
+		fmt.Fprintln(stdout, string(data))
+		return 0
+	}
+
+	for _, token := range dumps {
+		fmt.Fprintf(stdout, "%-8s %-14s %s\n",
+			fmt.Sprintf("%d:%d", token.Line, token.Column), token.Type, strconv.Quote(token.Value))
+	}
+	return 0
+}
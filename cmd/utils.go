@@ -1,21 +1,20 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io"
-	"strings"
+	"reflect"
 
 	"github.com/burnlang/burn/pkg/ast"
 	"github.com/burnlang/burn/pkg/lexer"
+	"github.com/burnlang/burn/pkg/parser"
+	"github.com/burnlang/burn/pkg/typechecker"
 )
 
-func formattedError(errType string, err error, source string, pos int) error {
-	errMsg := err.Error()
-
-	if strings.Contains(errMsg, "at line") {
-		return fmt.Errorf("%s: %v", errType, err)
-	}
-
+// formattedError attaches the location of an error in source to it. loc is
+// the line and column of pos when the stage that failed knows them.
+func formattedError(errType string, err error, file, source string, pos int, loc ast.Location) error {
 	if pos < 0 {
 		pos = 0
 	}
@@ -26,8 +25,46 @@ func formattedError(errType string, err error, source string, pos int) error {
 		}
 	}
 
-	line, col := getLineAndCol(source, pos)
-	return fmt.Errorf("%s at line %d, column %d: %v", errType, line, col, err)
+	return &sourceError{kind: errType, err: err, file: file, source: source, pos: pos, loc: loc}
+}
+
+// syntaxErrors combines the errors found by the parser into one, showing
+// at most max of them when max is not zero
+func syntaxErrors(errs []*parser.Error, max int, file, source string) error {
+	located := make([]error, len(errs))
+	for j, err := range errs {
+		located[j] = formattedError("Parse error", err.Err, file, source, err.Position, err.Location)
+	}
+	return joinErrors(located, max, "syntax")
+}
+
+// typeErrors combines the errors found by the typechecker into one, showing
+// at most max of them when max is not zero
+func typeErrors(errs []*typechecker.Error, max int, file, source string) error {
+	located := make([]error, len(errs))
+	for j, err := range errs {
+		located[j] = formattedError("Type error", err.Err, file, source, err.Position, err.Location)
+	}
+	return joinErrors(located, max, "type")
+}
+
+// joinErrors joins the first max of errs, or all when max is zero, saying
+// how many more errors of the kind there are
+func joinErrors(errs []error, max int, kind string) error {
+	if max > 0 && len(errs) > max {
+		more := fmt.Errorf("%d more %s errors not shown (see --max-errors)", len(errs)-max, kind)
+		errs = append(errs[:max:max], more)
+	}
+	return errors.Join(errs...)
+}
+
+// warningLineAndCol returns the line and column of a typechecker warning,
+// falling back to pos in source for warnings about nodes without a location
+func warningLineAndCol(w typechecker.Warning, source string, pos int) (int, int) {
+	if w.Location.IsValid() {
+		return w.Location.Line, w.Location.Column
+	}
+	return getLineAndCol(source, pos)
 }
 
 func getLineAndCol(source string, pos int) (int, int) {
@@ -47,12 +84,56 @@ func getLineAndCol(source string, pos int) (int, int) {
 
 func tokenTypeToString(tokenType lexer.TokenType) string {
 	switch tokenType {
+	case lexer.TokenEOF:
+		return "EOF"
 	case lexer.TokenIdentifier:
 		return "IDENTIFIER"
 	case lexer.TokenNumber:
 		return "NUMBER"
 	case lexer.TokenString:
 		return "STRING"
+	case lexer.TokenPlus:
+		return "PLUS"
+	case lexer.TokenMinus:
+		return "MINUS"
+	case lexer.TokenMultiply:
+		return "MULTIPLY"
+	case lexer.TokenDivide:
+		return "DIVIDE"
+	case lexer.TokenAssign:
+		return "ASSIGN"
+	case lexer.TokenEqual:
+		return "EQUAL"
+	case lexer.TokenNotEqual:
+		return "NOT_EQUAL"
+	case lexer.TokenLess:
+		return "LESS"
+	case lexer.TokenGreater:
+		return "GREATER"
+	case lexer.TokenLessEqual:
+		return "LESS_EQUAL"
+	case lexer.TokenGreaterEqual:
+		return "GREATER_EQUAL"
+	case lexer.TokenLeftParen:
+		return "LEFT_PAREN"
+	case lexer.TokenRightParen:
+		return "RIGHT_PAREN"
+	case lexer.TokenLeftBrace:
+		return "LEFT_BRACE"
+	case lexer.TokenRightBrace:
+		return "RIGHT_BRACE"
+	case lexer.TokenComma:
+		return "COMMA"
+	case lexer.TokenSemicolon:
+		return "SEMICOLON"
+	case lexer.TokenColon:
+		return "COLON"
+	case lexer.TokenNot:
+		return "NOT"
+	case lexer.TokenAnd:
+		return "AND"
+	case lexer.TokenOr:
+		return "OR"
 	case lexer.TokenFun:
 		return "FUN"
 	case lexer.TokenVar:
@@ -61,73 +142,78 @@ func tokenTypeToString(tokenType lexer.TokenType) string {
 		return "CONST"
 	case lexer.TokenTypeKeyword:
 		return "DEF"
+	case lexer.TokenIf:
+		return "IF"
+	case lexer.TokenElse:
+		return "ELSE"
+	case lexer.TokenReturn:
+		return "RETURN"
+	case lexer.TokenWhile:
+		return "WHILE"
+	case lexer.TokenFor:
+		return "FOR"
+	case lexer.TokenTrue:
+		return "TRUE"
+	case lexer.TokenFalse:
+		return "FALSE"
+	case lexer.TokenTypeInt:
+		return "TYPE_INT"
+	case lexer.TokenTypeFloat:
+		return "TYPE_FLOAT"
+	case lexer.TokenTypeString:
+		return "TYPE_STRING"
+	case lexer.TokenTypeBool:
+		return "TYPE_BOOL"
+	case lexer.TokenDot:
+		return "DOT"
+	case lexer.TokenLeftBracket:
+		return "LEFT_BRACKET"
+	case lexer.TokenRightBracket:
+		return "RIGHT_BRACKET"
+	case lexer.TokenImport:
+		return "IMPORT"
+	case lexer.TokenModulo:
+		return "MODULO"
+	case lexer.TokenClass:
+		return "CLASS"
+	case lexer.TokenTypeVoid:
+		return "TYPE_VOID"
+	case lexer.TokenComment:
+		return "COMMENT"
+	case lexer.TokenBreak:
+		return "BREAK"
+	case lexer.TokenContinue:
+		return "CONTINUE"
+	case lexer.TokenMatch:
+		return "MATCH"
+	case lexer.TokenArrow:
+		return "ARROW"
+	case lexer.TokenDotDot:
+		return "DOT_DOT"
+	case lexer.TokenIn:
+		return "IN"
+	case lexer.TokenNil:
+		return "NIL"
+	case lexer.TokenQuestion:
+		return "QUESTION"
+	case lexer.TokenInterface:
+		return "INTERFACE"
+	case lexer.TokenPrivate:
+		return "PRIVATE"
+	case lexer.TokenEllipsis:
+		return "ELLIPSIS"
+	case lexer.TokenQuestionDot:
+		return "QUESTION_DOT"
+	case lexer.TokenQuestionQuestion:
+		return "QUESTION_QUESTION"
 	default:
 		return fmt.Sprintf("TOKEN(%d)", int(tokenType))
 	}
 }
 
-func printAST(node ast.Node, indent int, w io.Writer) {
-	indentStr := strings.Repeat("  ", indent)
-
-	switch n := node.(type) {
-	case *ast.Program:
-		fmt.Fprintln(w, indentStr+"Program")
-		for _, decl := range n.Declarations {
-			printAST(decl, indent+1, w)
-		}
-	case *ast.FunctionDeclaration:
-		fmt.Fprintf(w, "%sFunctionDeclaration: %s\n", indentStr, n.Name)
-		fmt.Fprintf(w, "%s  Parameters: ", indentStr)
-		for i, param := range n.Parameters {
-			if i > 0 {
-				fmt.Fprint(w, ", ")
-			}
-			fmt.Fprintf(w, "%s: %s", param.Name, param.Type)
-		}
-		fmt.Fprintln(w)
-		if n.ReturnType != "" {
-			fmt.Fprintf(w, "%s  ReturnType: %s\n", indentStr, n.ReturnType)
-		}
-		fmt.Fprintf(w, "%s  Body:\n", indentStr)
-		for _, stmt := range n.Body {
-			printAST(stmt, indent+2, w)
-		}
-	case *ast.ClassDeclaration:
-		fmt.Fprintf(w, "%sClassDeclaration: %s\n", indentStr, n.Name)
-		fmt.Fprintf(w, "%s  Methods:\n", indentStr)
-		for _, method := range n.Methods {
-			printAST(method, indent+2, w)
-		}
-	case *ast.VariableDeclaration:
-		fmt.Fprintf(w, "%sVariableDeclaration: %s", indentStr, n.Name)
-		if n.Type != "" {
-			fmt.Fprintf(w, " : %s", n.Type)
-		}
-		fmt.Fprintln(w)
-		if n.Value != nil {
-			printAST(n.Value, indent+1, w)
-		}
-	case *ast.ExpressionStatement:
-		fmt.Fprintf(w, "%sExpressionStatement:\n", indentStr)
-		printAST(n.Expression, indent+1, w)
-	case *ast.CallExpression:
-		fmt.Fprintf(w, "%sCallExpression: ", indentStr)
-		printAST(n.Callee, indent, w)
-		fmt.Fprintf(w, "%s  Arguments:\n", indentStr)
-		for _, arg := range n.Arguments {
-			printAST(arg, indent+2, w)
-		}
-	case *ast.ClassMethodCallExpression:
-		fmt.Fprintf(w, "%sClassMethodCallExpression: %s.%s\n", indentStr, n.ClassName, n.MethodName)
-		fmt.Fprintf(w, "%s  Arguments:\n", indentStr)
-		for _, arg := range n.Arguments {
-			printAST(arg, indent+2, w)
-		}
-	case *ast.LiteralExpression:
-		fmt.Fprintf(w, "%sLiteral: %v (%T)\n", indentStr, n.Value, n.Value)
-	case *ast.VariableExpression:
-		fmt.Fprintf(w, "%sVariable: %s\n", indentStr, n.Name)
-	default:
-		fmt.Fprintf(w, "%sNode: %T\n", indentStr, node)
-	}
+// printAST writes an indented text rendering of node, including positions
+// resolved against source
+func printAST(node ast.Node, source string, w io.Writer) {
+	dumper := &astDumper{source: source}
+	printDumpTree(dumper.dump(reflect.ValueOf(node)), 0, "", w)
 }
@@ -0,0 +1,79 @@
+// Package testutil holds the helpers shared by the tests of the packages
+// that run Burn programs
+package testutil
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/interpreter"
+	"github.com/burnlang/burn/pkg/lexer"
+	"github.com/burnlang/burn/pkg/parser"
+	"github.com/burnlang/burn/pkg/typechecker"
+	"github.com/burnlang/burn/pkg/vm"
+)
+
+// Program lexes, parses and typechecks source, failing t on the first error
+func Program(t testing.TB, source string) *ast.Program {
+	t.Helper()
+	tokens, err := lexer.New(source).Tokenize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	program, errs := parser.New(tokens).ParseAll()
+	if len(errs) > 0 {
+		t.Fatal(errs[0])
+	}
+	if errs := typechecker.New().CheckAll(program.Declarations); len(errs) > 0 {
+		t.Fatal(errs[0])
+	}
+	return program
+}
+
+// Run typechecks and interprets source and returns what it printed
+func Run(t testing.TB, source string) string {
+	t.Helper()
+	out, err := RunErr(t, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// RunErr is like Run, and returns the error source failed with at run time
+func RunErr(t testing.TB, source string) (string, error) {
+	t.Helper()
+	program := Program(t, source)
+	var out bytes.Buffer
+	interp := interpreter.New()
+	interp.SetOutput(&out)
+	_, err := interp.Interpret(program)
+	return out.String(), err
+}
+
+// RunVM typechecks source, compiles it to bytecode and runs it on the VM,
+// and returns what it printed
+func RunVM(t testing.TB, source string) string {
+	t.Helper()
+	out, err := RunVMErr(t, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// RunVMErr is like RunVM, and returns the error source failed with at run
+// time. Source the VM cannot compile fails t.
+func RunVMErr(t testing.TB, source string) (string, error) {
+	t.Helper()
+	compiled, err := vm.Compile(Program(t, source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	interp := interpreter.New()
+	interp.SetOutput(&out)
+	_, err = vm.New(interp).Run(compiled)
+	return out.String(), err
+}
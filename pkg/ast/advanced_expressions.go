@@ -5,6 +5,9 @@ type CompoundAssignmentExpression struct {
 	Operator string
 	Value    Expression
 	Position int
+	Location
+
+	Binding *Binding
 }
 
 func (c *CompoundAssignmentExpression) expressionNode() {}
@@ -21,6 +24,7 @@ type LiteralExpression struct {
 	Type     string
 	Raw      string
 	Position int
+	Location
 }
 
 func (l *LiteralExpression) expressionNode() {}
@@ -35,6 +39,7 @@ func (l *LiteralExpression) String() string {
 type GroupingExpression struct {
 	Expression Expression
 	Position   int
+	Location
 }
 
 func (g *GroupingExpression) expressionNode() {}
@@ -51,6 +56,7 @@ type LambdaExpression struct {
 	ReturnType string
 	Body       []Declaration
 	Position   int
+	Location
 }
 
 func (l *LambdaExpression) expressionNode() {}
@@ -64,6 +70,7 @@ func (l *LambdaExpression) String() string {
 
 type ThisExpression struct {
 	Position int
+	Location
 }
 
 func (t *ThisExpression) expressionNode() {}
@@ -77,6 +84,7 @@ func (t *ThisExpression) String() string {
 
 type NilExpression struct {
 	Position int
+	Location
 }
 
 func (n *NilExpression) expressionNode() {}
@@ -92,6 +100,7 @@ type CastExpression struct {
 	Expression Expression
 	TargetType string
 	Position   int
+	Location
 }
 
 func (c *CastExpression) expressionNode() {}
@@ -108,6 +117,7 @@ type RangeExpression struct {
 	End      Expression
 	Step     Expression
 	Position int
+	Location
 }
 
 func (r *RangeExpression) expressionNode() {}
@@ -119,9 +129,28 @@ func (r *RangeExpression) String() string {
 	return "RangeExpression"
 }
 
+// ConditionalExpression is cond ? Then : Else
+type ConditionalExpression struct {
+	Condition Expression
+	Then      Expression
+	Else      Expression
+	Position  int
+	Location
+}
+
+func (c *ConditionalExpression) expressionNode() {}
+func (c *ConditionalExpression) Pos() int {
+	return c.Position
+}
+
+func (c *ConditionalExpression) String() string {
+	return "ConditionalExpression"
+}
+
 type ErrorNode struct {
 	Message  string
 	Position int
+	Location
 }
 
 func (e *ErrorNode) expressionNode()  {}
@@ -0,0 +1,44 @@
+package ast
+
+import "strings"
+
+// Comment is a // comment, kept when the lexer is asked to keep comments.
+// Text includes the leading slashes.
+type Comment struct {
+	Text     string
+	Position int
+	Location
+}
+
+func (c *Comment) Pos() int {
+	return c.Position
+}
+
+// CommentGroup is a run of comments on consecutive lines with no code
+// between them. The group ending on the line above a function, variable,
+// type or class declaration is the Doc of that declaration.
+type CommentGroup struct {
+	List []*Comment
+}
+
+func (g *CommentGroup) Pos() int {
+	return g.List[0].Position
+}
+
+func (g *CommentGroup) Loc() Location {
+	return g.List[0].Location
+}
+
+// Text returns the text of the comments without their slashes and the
+// space after them, one line per comment
+func (g *CommentGroup) Text() string {
+	if g == nil {
+		return ""
+	}
+	lines := make([]string, len(g.List))
+	for j, c := range g.List {
+		text := strings.TrimPrefix(c.Text, "//")
+		lines[j] = strings.TrimPrefix(text, " ")
+	}
+	return strings.Join(lines, "\n")
+}
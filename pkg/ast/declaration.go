@@ -1,9 +1,17 @@
 package ast
 
 type TypeDefinition struct {
-	Name     string
+	Name string
+
+	// TypeParameters are the names standing for the types a generic type
+	// such as type Box<T> { value: T } is used with, as in Box<int>
+	TypeParameters []string
+
 	Fields   []TypeField
 	Position int
+	Location
+
+	Doc *CommentGroup
 }
 
 func (t *TypeDefinition) declarationNode() {}
@@ -19,6 +27,7 @@ type TypeField struct {
 	Name     string
 	Type     string
 	Position int
+	Location
 }
 
 func (t *TypeField) Pos() int {
@@ -26,11 +35,32 @@ func (t *TypeField) Pos() int {
 }
 
 type FunctionDeclaration struct {
-	Name       string
+	Name string
+
+	// Receiver is the type a method such as fun (p: Point) norm(): float
+	// is declared on, or empty for a function. The receiver is the first
+	// of the Parameters.
+	Receiver string
+
+	// Private functions can only be called from their own file, and
+	// private methods from the methods of their own class or type
+	Private bool
+
+	// TypeParameters are the names standing for the types a generic
+	// function such as fun first<T>(arr: [T]): T is called with
+	TypeParameters []string
+
 	Parameters []Parameter
 	ReturnType string
 	Body       []Declaration
 	Position   int
+	Location
+
+	// Binding is where a function declared inside another is kept, like
+	// a local variable
+	Binding *Binding
+
+	Doc *CommentGroup
 }
 
 func (f *FunctionDeclaration) declarationNode() {}
@@ -46,6 +76,7 @@ type Parameter struct {
 	Name     string
 	Type     string
 	Position int
+	Location
 }
 
 func (p *Parameter) Pos() int {
@@ -58,6 +89,16 @@ type VariableDeclaration struct {
 	Value    Expression
 	IsConst  bool
 	Position int
+	Location
+
+	// Private fields of a class can only be used by its methods
+	Private bool
+
+	// Binding is where a local variable is kept, always in the scope it
+	// is declared in
+	Binding *Binding
+
+	Doc *CommentGroup
 }
 
 func (v *VariableDeclaration) declarationNode() {}
@@ -76,6 +117,7 @@ func (v *VariableDeclaration) String() string {
 type ImportDeclaration struct {
 	Path     string
 	Position int
+	Location
 }
 
 func (i *ImportDeclaration) declarationNode() {}
@@ -86,6 +128,7 @@ func (i *ImportDeclaration) Pos() int {
 type MultiImportDeclaration struct {
 	Imports  []*ImportDeclaration
 	Position int
+	Location
 }
 
 func (m *MultiImportDeclaration) declarationNode() {}
@@ -94,10 +137,25 @@ func (m *MultiImportDeclaration) Pos() int {
 }
 
 type ClassDeclaration struct {
-	Name          string
+	Name string
+
+	// Fields are the instance fields declared with var, each with a type
+	// and an optional default value
+	Fields []*VariableDeclaration
+
+	// StaticFields are the fields of the class itself, declared with
+	// static var or static const and each with a value
+	StaticFields []*VariableDeclaration
+
+	// Interfaces are the names after implements
+	Interfaces []string
+
 	Methods       []*FunctionDeclaration
 	StaticMethods []*FunctionDeclaration
 	Position      int
+	Location
+
+	Doc *CommentGroup
 }
 
 func (c *ClassDeclaration) declarationNode() {}
@@ -108,3 +166,24 @@ func (c *ClassDeclaration) Pos() int {
 func (c *ClassDeclaration) String() string {
 	return "ClassDeclaration: " + c.Name
 }
+
+// InterfaceDeclaration declares the methods a class implementing it must
+// have. Methods have no body, and leave out the instance a class method
+// takes as its first parameter.
+type InterfaceDeclaration struct {
+	Name     string
+	Methods  []*FunctionDeclaration
+	Position int
+	Location
+
+	Doc *CommentGroup
+}
+
+func (i *InterfaceDeclaration) declarationNode() {}
+func (i *InterfaceDeclaration) Pos() int {
+	return i.Position
+}
+
+func (i *InterfaceDeclaration) String() string {
+	return "InterfaceDeclaration: " + i.Name
+}
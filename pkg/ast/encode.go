@@ -0,0 +1,399 @@
+package ast
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"slices"
+)
+
+// nodeTypes are the nodes that may sit in a field of interface type. An
+// encoded interface value is its index in this list plus one, then the
+// node; appending to the list keeps earlier encodings readable, but
+// Decode rejects them anyway once any node changes shape.
+var nodeTypes = []reflect.Type{
+	reflect.TypeOf(""),
+	reflect.TypeOf(float64(0)),
+	reflect.TypeOf(false),
+
+	reflect.TypeOf(&TypeDefinition{}),
+	reflect.TypeOf(&FunctionDeclaration{}),
+	reflect.TypeOf(&VariableDeclaration{}),
+	reflect.TypeOf(&ImportDeclaration{}),
+	reflect.TypeOf(&MultiImportDeclaration{}),
+	reflect.TypeOf(&ClassDeclaration{}),
+	reflect.TypeOf(&InterfaceDeclaration{}),
+
+	reflect.TypeOf(&BlockStatement{}),
+	reflect.TypeOf(&ReturnStatement{}),
+	reflect.TypeOf(&IfStatement{}),
+	reflect.TypeOf(&WhileStatement{}),
+	reflect.TypeOf(&ForStatement{}),
+	reflect.TypeOf(&ForInStatement{}),
+	reflect.TypeOf(&ExpressionStatement{}),
+	reflect.TypeOf(&BreakStatement{}),
+	reflect.TypeOf(&ContinueStatement{}),
+	reflect.TypeOf(&MatchStatement{}),
+
+	reflect.TypeOf(&BinaryExpression{}),
+	reflect.TypeOf(&UnaryExpression{}),
+	reflect.TypeOf(&CallExpression{}),
+	reflect.TypeOf(&GetExpression{}),
+	reflect.TypeOf(&SetExpression{}),
+	reflect.TypeOf(&IndexExpression{}),
+	reflect.TypeOf(&SliceExpression{}),
+	reflect.TypeOf(&IndexAssignmentExpression{}),
+	reflect.TypeOf(&ArrayLiteralExpression{}),
+	reflect.TypeOf(&MapLiteralExpression{}),
+	reflect.TypeOf(&StructLiteralExpression{}),
+	reflect.TypeOf(&ClassMethodCallExpression{}),
+	reflect.TypeOf(&VariableExpression{}),
+	reflect.TypeOf(&AssignmentExpression{}),
+	reflect.TypeOf(&CompoundAssignmentExpression{}),
+	reflect.TypeOf(&LiteralExpression{}),
+	reflect.TypeOf(&GroupingExpression{}),
+	reflect.TypeOf(&LambdaExpression{}),
+	reflect.TypeOf(&ThisExpression{}),
+	reflect.TypeOf(&NilExpression{}),
+	reflect.TypeOf(&CastExpression{}),
+	reflect.TypeOf(&RangeExpression{}),
+	reflect.TypeOf(&ConditionalExpression{}),
+	reflect.TypeOf(&ErrorNode{}),
+}
+
+var (
+	nodeIndex = map[reflect.Type]uint64{}
+
+	// fields holds the indexes of the exported fields of every struct a
+	// node is made of. It is filled in by init and only read afterwards.
+	fields = map[reflect.Type][]int{}
+
+	// schema is a hash of the shape of every node, written before an
+	// encoded program so that Decode notices programs encoded by a build
+	// whose nodes differ
+	schema [sha256.Size]byte
+
+	// interfaces convert a node to a value of the interface type of the
+	// field it goes in. Setting a field to a value of its own type spares
+	// reflect from checking that the node implements the interface.
+	interfaces = map[reflect.Type]func(x any) (reflect.Value, bool){
+		reflect.TypeFor[Declaration](): as[Declaration],
+		reflect.TypeFor[Statement]():   as[Statement],
+		reflect.TypeFor[Expression]():  as[Expression],
+		reflect.TypeFor[Node]():        as[Node],
+		reflect.TypeFor[any]():         as[any],
+	}
+)
+
+func as[T any](x any) (reflect.Value, bool) {
+	t, ok := x.(T)
+	return reflect.ValueOf(&t).Elem(), ok
+}
+
+func init() {
+	h := sha256.New()
+	for k, t := range nodeTypes {
+		nodeIndex[t] = uint64(k) + 1
+		describe(h, t, map[reflect.Type]bool{})
+	}
+	describe(h, reflect.TypeFor[Program](), map[reflect.Type]bool{})
+	copy(schema[:], h.Sum(nil))
+}
+
+// describe writes the shape of t, as far as encoding goes, to w, and
+// records the fields of the structs it finds
+func describe(w io.Writer, t reflect.Type, seen map[reflect.Type]bool) {
+	fmt.Fprintf(w, "%s:%s;", t, t.Kind())
+	if seen[t] {
+		return
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice:
+		describe(w, t.Elem(), seen)
+	case reflect.Map:
+		describe(w, t.Key(), seen)
+		describe(w, t.Elem(), seen)
+	case reflect.Struct:
+		if _, done := fields[t]; !done {
+			exported := []int{}
+			for k := range t.NumField() {
+				if t.Field(k).IsExported() {
+					exported = append(exported, k)
+				}
+			}
+			fields[t] = exported
+		}
+		for _, k := range fields[t] {
+			fmt.Fprintf(w, "%s=", t.Field(k).Name)
+			describe(w, t.Field(k).Type, seen)
+		}
+	}
+}
+
+// ErrSchema is returned by Decode for a program encoded by a build of Burn
+// whose syntax tree differs
+var ErrSchema = errors.New("program was encoded for a different syntax tree")
+
+// Encode writes p to w in a compact binary form that Decode reads back,
+// such as for caching a program that was already parsed and typechecked.
+// Bindings are kept; comments are not.
+func Encode(w io.Writer, p *Program) error {
+	e := &encoder{buf: append([]byte(nil), schema[:]...), strings: map[string]uint64{}}
+	e.value(reflect.ValueOf(Program{Declarations: p.Declarations, Position: p.Position, Location: p.Location}))
+	if e.err != nil {
+		return e.err
+	}
+	_, err := w.Write(e.buf)
+	return err
+}
+
+// Decode reads a program written by Encode
+func Decode(r io.Reader) (*Program, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < len(schema) || [sha256.Size]byte(data[:len(schema)]) != schema {
+		return nil, ErrSchema
+	}
+
+	d := &decoder{data: data[len(schema):]}
+	var p Program
+	d.value(reflect.ValueOf(&p).Elem())
+	if d.err != nil {
+		return nil, d.err
+	}
+	return &p, nil
+}
+
+type encoder struct {
+	buf []byte
+	err error
+
+	// strings numbers the strings written so far, each of which is only
+	// written out the first time
+	strings map[string]uint64
+}
+
+func (e *encoder) uint(n uint64) {
+	e.buf = binary.AppendUvarint(e.buf, n)
+}
+
+func (e *encoder) string(s string) {
+	if k, seen := e.strings[s]; seen {
+		e.uint(k)
+		return
+	}
+	e.strings[s] = uint64(len(e.strings)) + 1
+	e.uint(0)
+	e.uint(uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+// value writes v. Nil pointers, slices, maps and interfaces are written
+// as 0 and others with 1 added to their length or type.
+func (e *encoder) value(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf = append(e.buf, 1)
+		} else {
+			e.buf = append(e.buf, 0)
+		}
+	case reflect.Int:
+		e.buf = binary.AppendVarint(e.buf, v.Int())
+	case reflect.Float64:
+		e.uint(math.Float64bits(v.Float()))
+	case reflect.String:
+		e.string(v.String())
+	case reflect.Pointer:
+		if v.IsNil() {
+			e.uint(0)
+			return
+		}
+		e.uint(1)
+		e.value(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			e.uint(0)
+			return
+		}
+		k, known := nodeIndex[v.Elem().Type()]
+		if !known {
+			e.err = fmt.Errorf("cannot encode %s", v.Elem().Type())
+			return
+		}
+		e.uint(k)
+		e.value(v.Elem())
+	case reflect.Slice:
+		if v.IsNil() {
+			e.uint(0)
+			return
+		}
+		e.uint(uint64(v.Len()) + 1)
+		for k := range v.Len() {
+			e.value(v.Index(k))
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			e.uint(0)
+			return
+		}
+		e.uint(uint64(v.Len()) + 1)
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return cmp.Compare(a.String(), b.String())
+		})
+		for _, key := range keys {
+			e.value(key)
+			e.value(v.MapIndex(key))
+		}
+	case reflect.Struct:
+		for _, k := range fields[v.Type()] {
+			e.value(v.Field(k))
+		}
+	default:
+		e.err = fmt.Errorf("cannot encode %s", v.Type())
+	}
+}
+
+type decoder struct {
+	data    []byte
+	err     error
+	strings []string
+}
+
+func (d *decoder) uint() uint64 {
+	n, size := binary.Uvarint(d.data)
+	if size <= 0 {
+		d.fail()
+		return 0
+	}
+	d.data = d.data[size:]
+	return n
+}
+
+func (d *decoder) int() int64 {
+	n, size := binary.Varint(d.data)
+	if size <= 0 {
+		d.fail()
+		return 0
+	}
+	d.data = d.data[size:]
+	return n
+}
+
+func (d *decoder) string() string {
+	k := d.uint()
+	if k > 0 {
+		if k > uint64(len(d.strings)) {
+			d.fail()
+			return ""
+		}
+		return d.strings[k-1]
+	}
+	n := d.uint()
+	if n > uint64(len(d.data)) {
+		d.fail()
+		return ""
+	}
+	s := string(d.data[:n])
+	d.data = d.data[n:]
+	d.strings = append(d.strings, s)
+	return s
+}
+
+func (d *decoder) fail() {
+	if d.err == nil {
+		d.err = errors.New("malformed encoded program")
+	}
+	d.data = nil
+}
+
+// value reads into v what encoder.value wrote for a value of its type
+func (d *decoder) value(v reflect.Value) {
+	if d.err != nil {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(d.uint() != 0)
+	case reflect.Int:
+		v.SetInt(d.int())
+	case reflect.Float64:
+		v.SetFloat(math.Float64frombits(d.uint()))
+	case reflect.String:
+		v.SetString(d.string())
+	case reflect.Pointer:
+		if d.uint() == 0 {
+			return
+		}
+		p := reflect.New(v.Type().Elem())
+		d.value(p.Elem())
+		v.Set(p)
+	case reflect.Interface:
+		k := d.uint()
+		if k == 0 {
+			return
+		}
+		convert := interfaces[v.Type()]
+		if k > uint64(len(nodeTypes)) || convert == nil {
+			d.fail()
+			return
+		}
+		x := reflect.New(nodeTypes[k-1]).Elem()
+		d.value(x)
+		if x, ok := convert(x.Interface()); ok {
+			v.Set(x)
+		} else {
+			d.fail()
+		}
+	case reflect.Slice:
+		n := d.uint()
+		if n == 0 || d.err != nil {
+			return
+		}
+		if n-1 > uint64(len(d.data)) {
+			d.fail()
+			return
+		}
+		s := reflect.MakeSlice(v.Type(), int(n-1), int(n-1))
+		for k := range int(n - 1) {
+			d.value(s.Index(k))
+		}
+		v.Set(s)
+	case reflect.Map:
+		n := d.uint()
+		if n == 0 || d.err != nil {
+			return
+		}
+		if n-1 > uint64(len(d.data)) {
+			d.fail()
+			return
+		}
+		m := reflect.MakeMapWithSize(v.Type(), int(n-1))
+		for range n - 1 {
+			key := reflect.New(v.Type().Key()).Elem()
+			d.value(key)
+			elem := reflect.New(v.Type().Elem()).Elem()
+			d.value(elem)
+			if d.err != nil {
+				return
+			}
+			m.SetMapIndex(key, elem)
+		}
+		v.Set(m)
+	case reflect.Struct:
+		for _, k := range fields[v.Type()] {
+			d.value(v.Field(k))
+		}
+	default:
+		d.fail()
+	}
+}
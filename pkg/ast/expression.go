@@ -4,7 +4,11 @@ type BinaryExpression struct {
 	Left     Expression
 	Operator string
 	Right    Expression
-	Position int
+	// IntDivision is set by the typechecker on a / of two ints, whose
+	// result is truncated toward zero
+	IntDivision bool
+	Position    int
+	Location
 }
 
 func (b *BinaryExpression) expressionNode() {}
@@ -20,6 +24,7 @@ type UnaryExpression struct {
 	Operator string
 	Right    Expression
 	Position int
+	Location
 }
 
 func (u *UnaryExpression) expressionNode() {}
@@ -34,7 +39,11 @@ func (u *UnaryExpression) String() string {
 type CallExpression struct {
 	Callee    Expression
 	Arguments []Expression
-	Position  int
+	// NumberType is set by the typechecker on a call of typeof whose
+	// argument is an int or a float, which are both float64 at run time
+	NumberType string
+	Position   int
+	Location
 }
 
 func (c *CallExpression) expressionNode() {}
@@ -47,9 +56,15 @@ func (c *CallExpression) String() string {
 }
 
 type GetExpression struct {
-	Object   Expression
-	Name     string
+	Object Expression
+	Name   string
+
+	// Optional is set for object?.name, which is nil when the object is
+	// nil, as is a method call through it
+	Optional bool
+
 	Position int
+	Location
 }
 
 func (g *GetExpression) expressionNode() {}
@@ -66,6 +81,7 @@ type SetExpression struct {
 	Name     string
 	Value    Expression
 	Position int
+	Location
 }
 
 func (s *SetExpression) expressionNode() {}
@@ -81,6 +97,7 @@ type IndexExpression struct {
 	Array    Expression
 	Index    Expression
 	Position int
+	Location
 }
 
 func (i *IndexExpression) expressionNode() {}
@@ -97,6 +114,7 @@ type SliceExpression struct {
 	Start    Expression
 	End      Expression
 	Position int
+	Location
 }
 
 func (s *SliceExpression) expressionNode() {}
@@ -108,9 +126,32 @@ func (s *SliceExpression) String() string {
 	return "SliceExpression"
 }
 
+// IndexAssignmentExpression sets an element of an array or the value of a
+// key of a map: array[index] = value
+type IndexAssignmentExpression struct {
+	Array    Expression
+	Index    Expression
+	Value    Expression
+	Position int
+	Location
+}
+
+func (i *IndexAssignmentExpression) expressionNode() {}
+func (i *IndexAssignmentExpression) Pos() int {
+	return i.Position
+}
+
+func (i *IndexAssignmentExpression) String() string {
+	return "IndexAssignmentExpression"
+}
+
 type ArrayLiteralExpression struct {
 	Elements []Expression
-	Position int
+	// ElementType is the element type of the array the literal is the value
+	// of, set by the type checker when the literal is declared with one
+	ElementType string
+	Position    int
+	Location
 }
 
 func (a *ArrayLiteralExpression) expressionNode() {}
@@ -122,10 +163,39 @@ func (a *ArrayLiteralExpression) String() string {
 	return "ArrayLiteralExpression"
 }
 
+// MapLiteralExpression is a map written out as {key: value, ...}, where the
+// first key is not a name, or {:} for an empty map
+type MapLiteralExpression struct {
+	Entries  []MapEntry
+	Position int
+	Location
+}
+
+// MapEntry is a key and its value in a map literal
+type MapEntry struct {
+	Key   Expression
+	Value Expression
+}
+
+func (m *MapLiteralExpression) expressionNode() {}
+func (m *MapLiteralExpression) Pos() int {
+	return m.Position
+}
+
+func (m *MapLiteralExpression) String() string {
+	return "MapLiteralExpression"
+}
+
+// StructLiteralExpression is a struct literal such as Point{x: 1, y: 2}.
+// Written without its type name, as {x: 1, y: 2}, it takes the return type
+// of the function around it, or the type of the variable or field it is
+// the value of.
 type StructLiteralExpression struct {
 	Type     string
+	Named    bool
 	Fields   map[string]Expression
 	Position int
+	Location
 }
 
 func (s *StructLiteralExpression) expressionNode() {}
@@ -143,6 +213,7 @@ type ClassMethodCallExpression struct {
 	Arguments  []Expression
 	IsStatic   bool
 	Position   int
+	Location
 }
 
 func (c *ClassMethodCallExpression) expressionNode() {}
@@ -158,9 +229,22 @@ func (c *ClassMethodCallExpression) String() string {
 	return "ClassMethodCallExpression: " + c.ClassName + "." + c.MethodName + " (" + methodType + ")"
 }
 
+// Binding is where the variable a name refers to is kept while the program
+// runs: in Slot of the scope Depth scopes out from the one the name is used
+// in. The interpreter resolves the names of local variables to bindings
+// before it runs a program; other names, such as those of globals, have
+// none and are looked up by name.
+type Binding struct {
+	Depth int
+	Slot  int
+}
+
 type VariableExpression struct {
 	Name     string
 	Position int
+	Location
+
+	Binding *Binding
 }
 
 func (v *VariableExpression) expressionNode() {}
@@ -176,6 +260,9 @@ type AssignmentExpression struct {
 	Name     string
 	Value    Expression
 	Position int
+	Location
+
+	Binding *Binding
 }
 
 func (a *AssignmentExpression) expressionNode() {}
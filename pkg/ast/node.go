@@ -1,7 +1,10 @@
 package ast
 
+// Node is implemented by every node of the syntax tree. Pos is the byte
+// offset of the node in the source and Loc its line and column.
 type Node interface {
 	Pos() int
+	Loc() Location
 }
 
 type Expression interface {
@@ -22,12 +25,51 @@ type Statement interface {
 type Program struct {
 	Declarations []Declaration
 	Position     int
+	Location
+
+	// Comments lists every comment group of the source in order, if the
+	// lexer kept comments
+	Comments []*CommentGroup
+
+	// resolved is set once the names of local variables are resolved to
+	// bindings, after which the program is only read
+	resolved bool
 }
 
 func (p *Program) Pos() int {
 	return p.Position
 }
 
+// Resolved reports whether MarkResolved was called
+func (p *Program) Resolved() bool {
+	return p.resolved
+}
+
+// MarkResolved records that the names of the local variables of p are
+// resolved to bindings
+func (p *Program) MarkResolved() {
+	p.resolved = true
+}
+
 func (p *Program) String() string {
 	return "Program"
 }
+
+// Location is the line and column, both 1-based, at which a node starts in
+// its source file. Columns count bytes. A zero Location is unknown, as for
+// nodes built by hand rather than by the parser.
+type Location struct {
+	Line   int
+	Column int
+}
+
+// Loc returns the location itself, so that nodes embedding a Location
+// implement Node
+func (l Location) Loc() Location {
+	return l
+}
+
+// IsValid reports whether the location is known
+func (l Location) IsValid() bool {
+	return l.Line > 0
+}
@@ -3,6 +3,7 @@ package ast
 type BlockStatement struct {
 	Statements []Declaration
 	Position   int
+	Location
 }
 
 func (b *BlockStatement) declarationNode() {}
@@ -18,6 +19,7 @@ func (b *BlockStatement) String() string {
 type ReturnStatement struct {
 	Value    Expression
 	Position int
+	Location
 }
 
 func (r *ReturnStatement) declarationNode() {}
@@ -35,6 +37,7 @@ type IfStatement struct {
 	ThenBranch []Declaration
 	ElseBranch []Declaration
 	Position   int
+	Location
 }
 
 func (i *IfStatement) declarationNode() {}
@@ -50,7 +53,11 @@ func (i *IfStatement) String() string {
 type WhileStatement struct {
 	Condition Expression
 	Body      []Declaration
-	Position  int
+	// Label names the loop for break and continue statements in loops
+	// inside it, as outer does in outer: while ... { break outer }
+	Label    string
+	Position int
+	Location
 }
 
 func (w *WhileStatement) declarationNode() {}
@@ -68,7 +75,9 @@ type ForStatement struct {
 	Condition   Expression
 	Increment   Expression
 	Body        []Declaration
+	Label       string
 	Position    int
+	Location
 }
 
 func (f *ForStatement) declarationNode() {}
@@ -81,9 +90,35 @@ func (f *ForStatement) String() string {
 	return "ForStatement"
 }
 
+// ForInStatement runs Body once for each element of an array, number of a
+// range or key of a map, bound to Variable
+type ForInStatement struct {
+	Variable string
+	Iterable Expression
+	Body     []Declaration
+	Label    string
+	Position int
+	Location
+
+	// Binding is where the variable is kept, the first slot of the scope
+	// of each iteration
+	Binding *Binding
+}
+
+func (f *ForInStatement) declarationNode() {}
+func (f *ForInStatement) stmtNode()        {}
+func (f *ForInStatement) Pos() int {
+	return f.Position
+}
+
+func (f *ForInStatement) String() string {
+	return "ForInStatement: " + f.Variable
+}
+
 type ExpressionStatement struct {
 	Expression Expression
 	Position   int
+	Location
 }
 
 func (e *ExpressionStatement) declarationNode() {}
@@ -95,3 +130,78 @@ func (e *ExpressionStatement) Pos() int {
 func (e *ExpressionStatement) String() string {
 	return "ExpressionStatement"
 }
+
+// BreakStatement leaves the loop it is in, or the enclosing loop named by
+// Label
+type BreakStatement struct {
+	Label    string
+	Position int
+	Location
+}
+
+func (b *BreakStatement) declarationNode() {}
+func (b *BreakStatement) stmtNode()        {}
+func (b *BreakStatement) Pos() int {
+	return b.Position
+}
+
+func (b *BreakStatement) String() string {
+	return "BreakStatement"
+}
+
+// ContinueStatement goes on to the next iteration of the loop it is in, or
+// of the enclosing loop named by Label
+type ContinueStatement struct {
+	Label    string
+	Position int
+	Location
+}
+
+func (c *ContinueStatement) declarationNode() {}
+func (c *ContinueStatement) stmtNode()        {}
+func (c *ContinueStatement) Pos() int {
+	return c.Position
+}
+
+func (c *ContinueStatement) String() string {
+	return "ContinueStatement"
+}
+
+// MatchStatement runs the body of the first of its cases that matches
+// Value
+type MatchStatement struct {
+	Value    Expression
+	Cases    []*MatchCase
+	Position int
+	Location
+}
+
+func (m *MatchStatement) declarationNode() {}
+func (m *MatchStatement) stmtNode()        {}
+func (m *MatchStatement) Pos() int {
+	return m.Position
+}
+
+func (m *MatchStatement) String() string {
+	return "MatchStatement"
+}
+
+// MatchCase is a case of a match statement. It matches a value equal to
+// one of its Patterns or of one of its Types. A case with neither is the
+// default case, written _, and matches any value.
+type MatchCase struct {
+	Patterns []Expression
+	Types    []string
+	Body     []Declaration
+	Position int
+	Location
+}
+
+// IsDefault reports whether c is the default case
+func (c *MatchCase) IsDefault() bool {
+	return len(c.Patterns) == 0 && len(c.Types) == 0
+}
+
+func (c *MatchCase) Pos() int {
+	return c.Position
+}
@@ -10,7 +10,11 @@ type Visitor interface {
 	VisitIfStatement(ifStmt *IfStatement) interface{}
 	VisitWhileStatement(whileStmt *WhileStatement) interface{}
 	VisitForStatement(forStmt *ForStatement) interface{}
+	VisitForInStatement(forInStmt *ForInStatement) interface{}
 	VisitExpressionStatement(exprStmt *ExpressionStatement) interface{}
+	VisitBreakStatement(breakStmt *BreakStatement) interface{}
+	VisitContinueStatement(continueStmt *ContinueStatement) interface{}
+	VisitMatchStatement(matchStmt *MatchStatement) interface{}
 	VisitBinaryExpression(binaryExpr *BinaryExpression) interface{}
 	VisitUnaryExpression(unaryExpr *UnaryExpression) interface{}
 	VisitCallExpression(callExpr *CallExpression) interface{}
@@ -18,7 +22,9 @@ type Visitor interface {
 	VisitSetExpression(setExpr *SetExpression) interface{}
 	VisitIndexExpression(indexExpr *IndexExpression) interface{}
 	VisitSliceExpression(sliceExpr *SliceExpression) interface{}
+	VisitIndexAssignmentExpression(indexAssign *IndexAssignmentExpression) interface{}
 	VisitArrayLiteralExpression(arrayLiteral *ArrayLiteralExpression) interface{}
+	VisitMapLiteralExpression(mapLiteral *MapLiteralExpression) interface{}
 	VisitStructLiteralExpression(structLiteral *StructLiteralExpression) interface{}
 	VisitClassMethodCallExpression(callExpr *ClassMethodCallExpression) interface{}
 	VisitVariableExpression(varExpr *VariableExpression) interface{}
@@ -31,6 +37,7 @@ type Visitor interface {
 	VisitNilExpression(nilExpr *NilExpression) interface{}
 	VisitCastExpression(castExpr *CastExpression) interface{}
 	VisitRangeExpression(rangeExpr *RangeExpression) interface{}
+	VisitConditionalExpression(conditionalExpr *ConditionalExpression) interface{}
 	VisitErrorNode(errorNode *ErrorNode) interface{}
 }
 
@@ -70,10 +77,26 @@ func (f *ForStatement) Accept(visitor Visitor) interface{} {
 	return visitor.VisitForStatement(f)
 }
 
+func (f *ForInStatement) Accept(visitor Visitor) interface{} {
+	return visitor.VisitForInStatement(f)
+}
+
 func (e *ExpressionStatement) Accept(visitor Visitor) interface{} {
 	return visitor.VisitExpressionStatement(e)
 }
 
+func (b *BreakStatement) Accept(visitor Visitor) interface{} {
+	return visitor.VisitBreakStatement(b)
+}
+
+func (c *ContinueStatement) Accept(visitor Visitor) interface{} {
+	return visitor.VisitContinueStatement(c)
+}
+
+func (m *MatchStatement) Accept(visitor Visitor) interface{} {
+	return visitor.VisitMatchStatement(m)
+}
+
 func (b *BinaryExpression) Accept(visitor Visitor) interface{} {
 	return visitor.VisitBinaryExpression(b)
 }
@@ -102,10 +125,18 @@ func (s *SliceExpression) Accept(visitor Visitor) interface{} {
 	return visitor.VisitSliceExpression(s)
 }
 
+func (i *IndexAssignmentExpression) Accept(visitor Visitor) interface{} {
+	return visitor.VisitIndexAssignmentExpression(i)
+}
+
 func (a *ArrayLiteralExpression) Accept(visitor Visitor) interface{} {
 	return visitor.VisitArrayLiteralExpression(a)
 }
 
+func (m *MapLiteralExpression) Accept(visitor Visitor) interface{} {
+	return visitor.VisitMapLiteralExpression(m)
+}
+
 func (s *StructLiteralExpression) Accept(visitor Visitor) interface{} {
 	return visitor.VisitStructLiteralExpression(s)
 }
@@ -154,6 +185,10 @@ func (r *RangeExpression) Accept(visitor Visitor) interface{} {
 	return visitor.VisitRangeExpression(r)
 }
 
+func (c *ConditionalExpression) Accept(visitor Visitor) interface{} {
+	return visitor.VisitConditionalExpression(c)
+}
+
 func (e *ErrorNode) Accept(visitor Visitor) interface{} {
 	return visitor.VisitErrorNode(e)
 }
@@ -0,0 +1,114 @@
+package burn
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/burnlang/burn/pkg/interpreter"
+	"github.com/burnlang/burn/pkg/suggest"
+)
+
+// Call calls the Burn function name with arguments converted by ToValue and
+// returns its result. The arguments are checked against the parameter types
+// the function declares, and the call is subject to Options.Limits.
+func (e *Engine) Call(name string, args ...interface{}) (Value, error) {
+	values := make([]Value, len(args))
+	for j, arg := range args {
+		v, err := ToValue(arg)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d of %s: %v", j+1, name, err)
+		}
+		values[j] = v
+	}
+
+	functions := e.interp.GetFunctions()
+	fn, exists := functions[name]
+	if !exists {
+		return nil, fmt.Errorf("undefined function: %s%s", name, suggest.Hint(name, maps.Keys(functions)))
+	}
+	if len(values) != len(fn.Parameters) {
+		return nil, fmt.Errorf("function %s expects %d arguments but got %d",
+			name, len(fn.Parameters), len(values))
+	}
+	for j, param := range fn.Parameters {
+		if !hasType(values[j], param.Type) {
+			return nil, fmt.Errorf("argument %d of function %s expects %s but got %T",
+				j+1, name, param.Type, args[j])
+		}
+	}
+
+	defer e.begin()()
+	result, err := e.interp.Call(name, values...)
+	if err != nil {
+		return nil, e.runtimeError(err, "", "")
+	}
+	return result, nil
+}
+
+// CallAs calls a Burn function like Engine.Call and converts its result to
+// T with FromValue:
+//
+//	total, err := burn.CallAs[int](engine, "total", items)
+func CallAs[T any](e *Engine, name string, args ...interface{}) (T, error) {
+	var out T
+	result, err := e.Call(name, args...)
+	if err != nil {
+		return out, err
+	}
+	if err := FromValue(result, &out); err != nil {
+		return out, fmt.Errorf("result of %s: %v", name, err)
+	}
+	return out, nil
+}
+
+// hasType reports whether a Burn value can be passed as typeName
+func hasType(v Value, typeName string) bool {
+	if typeName == "any" {
+		return true
+	}
+	if baseType, optional := strings.CutSuffix(typeName, "?"); optional {
+		return v == nil || hasType(v, baseType)
+	}
+	switch val := v.(type) {
+	case nil:
+		switch typeName {
+		case "int", "float", "string", "bool", "bytes", "array":
+			return false
+		}
+		return !strings.HasPrefix(typeName, "[") && !strings.HasPrefix(typeName, "Result<") &&
+			!strings.HasPrefix(typeName, "Option<")
+	case float64:
+		return typeName == "float" || (typeName == "int" && val == float64(int64(val)))
+	case string:
+		return typeName == "string"
+	case bool:
+		return typeName == "bool"
+	case []byte:
+		return typeName == "bytes"
+	case *Array:
+		if typeName == "array" {
+			return true
+		}
+		elemType, ok := strings.CutPrefix(typeName, "[")
+		if !ok {
+			return false
+		}
+		elemType = strings.TrimSuffix(elemType, "]")
+		for _, elem := range val.Elements {
+			if !hasType(elem, elemType) {
+				return false
+			}
+		}
+		return true
+	case *Struct:
+		return typeName == val.TypeName || val.TypeName == ""
+	case *Map:
+		return strings.HasPrefix(typeName, "map<")
+	case *interpreter.Result:
+		return strings.HasPrefix(typeName, "Result<")
+	case *interpreter.Option:
+		return strings.HasPrefix(typeName, "Option<")
+	}
+	return false
+}
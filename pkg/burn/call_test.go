@@ -0,0 +1,119 @@
+package burn
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCallOptionalParameter(t *testing.T) {
+	e := NewEngine(Options{})
+	if _, err := e.Run(`fun id(s: string?): string { return s ?? "none" }`); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		arg  interface{}
+		want string
+	}{
+		{"x", "x"},
+		{nil, "none"},
+	} {
+		got, err := CallAs[string](e, "id", tt.arg)
+		if err != nil {
+			t.Errorf("id(%v): %v", tt.arg, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("id(%v) = %q, want %q", tt.arg, got, tt.want)
+		}
+	}
+	if _, err := e.Call("id", 1); err == nil {
+		t.Error("id(1) was accepted for a string? parameter")
+	}
+}
+
+func TestCallChecksArguments(t *testing.T) {
+	e := NewEngine(Options{})
+	if _, err := e.Run("fun add(a: int, b: int): int { return a + b }"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := CallAs[int](e, "add", 2, 3); err != nil || got != 5 {
+		t.Errorf("add(2, 3) = %d, %v", got, err)
+	}
+	for _, args := range [][]interface{}{
+		{2},
+		{2, "3"},
+		{2, 3.5},
+		{2, nil},
+	} {
+		if _, err := e.Call("add", args...); err == nil {
+			t.Errorf("add%v was accepted", args)
+		}
+	}
+	if _, err := e.Call("ad", 1, 2); err == nil {
+		t.Error("calling an undefined function was accepted")
+	}
+}
+
+func TestCallWithStructsAndArrays(t *testing.T) {
+	e := NewEngine(Options{})
+	_, err := e.Run(`
+type Item { name: string, price: int }
+fun total(items: [Item]): int {
+    var sum = 0
+    for (item in items) {
+        sum = sum + item.price
+    }
+    return sum
+}
+fun names(items: [Item]): [string] {
+    return map(items, fun(item: Item): string { return item.name })
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type Item struct {
+		Name  string `burn:"name"`
+		Price int    `burn:"price"`
+	}
+	items := []Item{{"a", 2}, {"b", 3}}
+	if got, err := CallAs[int](e, "total", items); err != nil || got != 5 {
+		t.Errorf("total = %d, %v; want 5", got, err)
+	}
+	got, err := CallAs[[]string](e, "names", items)
+	if err != nil || !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("names = %v, %v; want [a b]", got, err)
+	}
+	if _, err := e.Call("total", []int{1, 2}); err == nil {
+		t.Error("total of an array of ints was accepted for [Item]")
+	}
+}
+
+func TestCallRuntimeErrorAndLimits(t *testing.T) {
+	e := NewEngine(Options{Limits: Limits{MaxSteps: 1000}})
+	_, err := e.Run(`
+fun at(xs: [int], i: int): int { return xs[i] }
+fun spin(): int {
+    var n = 0
+    while (true) {
+        n = n + 1
+    }
+    return n
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = e.Call("at", []int{1}, 3)
+	var burnErr *Error
+	if !errors.As(err, &burnErr) || burnErr.Kind != RuntimeError || burnErr.Code != "BRN0502" {
+		t.Errorf("at([1], 3): got %v, want runtime error BRN0502", err)
+	}
+	if _, err := e.Call("spin"); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("spin: got %v, want the step limit exceeded", err)
+	}
+	if got, err := CallAs[int](e, "at", []int{4, 5}, 1); err != nil || got != 5 {
+		t.Errorf("at([4, 5], 1) after a failed call = %d, %v; want 5", got, err)
+	}
+}
@@ -0,0 +1,113 @@
+package burn
+
+import (
+	"os"
+
+	"github.com/burnlang/burn/pkg/ast"
+)
+
+// EvalChunk runs a fragment of a session, such as a notebook cell or a REPL
+// input, and returns the value and type of its last statement. The type is
+// empty when the last statement has no value. Unlike Run, EvalChunk never
+// calls main.
+func (e *Engine) EvalChunk(source string) (Value, string, error) {
+	return e.evalChunk(source, "")
+}
+
+// LoadFile runs the declarations and top-level statements of a Burn source
+// file like EvalChunk, defining its main function without calling it. What
+// the file declares replaces earlier declarations of the same names, so a
+// file can be loaded again after it changes.
+func (e *Engine) LoadFile(path string) error {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	_, _, err = e.evalChunk(string(source), path)
+	return err
+}
+
+// Display returns a value as print shows it: a struct whose class has a
+// toString method becomes the string it returns, also inside arrays
+func (e *Engine) Display(v Value) (Value, error) {
+	return e.interp.Display(v)
+}
+
+func (e *Engine) evalChunk(source, file string) (Value, string, error) {
+	program, tc, err := e.check(source, file)
+	if err != nil {
+		return nil, "", err
+	}
+
+	e.interp.SetFile(file)
+	defer e.begin()()
+	result, err := e.interp.Eval(program)
+	if err != nil {
+		return nil, "", e.runtimeError(err, source, file)
+	}
+
+	var typeName string
+	if n := len(program.Declarations); n > 0 {
+		if stmt, ok := program.Declarations[n-1].(*ast.ExpressionStatement); ok {
+			typeName, _ = tc.TypeOf(stmt.Expression)
+		}
+	}
+	return result, typeName, nil
+}
+
+// declarationKey identifies what a declaration defines, or is empty for
+// statements
+func declarationKey(decl ast.Declaration) string {
+	switch d := decl.(type) {
+	case *ast.FunctionDeclaration:
+		if d.Receiver != "" {
+			return "fun " + d.Receiver + "." + d.Name
+		}
+		return "fun " + d.Name
+	case *ast.TypeDefinition:
+		return "type " + d.Name
+	case *ast.ClassDeclaration:
+		return "class " + d.Name
+	case *ast.InterfaceDeclaration:
+		return "interface " + d.Name
+	case *ast.VariableDeclaration:
+		return "var " + d.Name
+	}
+	return ""
+}
+
+// redeclared returns the accepted declarations that decls does not declare
+// again
+func redeclared(accepted, decls []ast.Declaration) []ast.Declaration {
+	replaced := make(map[string]bool)
+	for _, decl := range decls {
+		if key := declarationKey(decl); key != "" {
+			replaced[key] = true
+		}
+	}
+
+	var kept []ast.Declaration
+	for _, decl := range accepted {
+		if !replaced[declarationKey(decl)] {
+			kept = append(kept, decl)
+		}
+	}
+	return kept
+}
+
+// definitions returns the declarations later runs are checked against,
+// leaving out statements that have already run
+func definitions(decls []ast.Declaration) []ast.Declaration {
+	var defs []ast.Declaration
+	for _, decl := range decls {
+		switch decl.(type) {
+		case *ast.ImportDeclaration, *ast.MultiImportDeclaration:
+			defs = append(defs, decl)
+		default:
+			if declarationKey(decl) != "" {
+				defs = append(defs, decl)
+			}
+		}
+	}
+	return defs
+}
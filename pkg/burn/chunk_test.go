@@ -0,0 +1,103 @@
+package burn
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadFileAgainReplacesDefinitions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "r.bn")
+	e := NewEngine(Options{})
+	for _, source := range []string{
+		"fun sq(n: int): int { return n * n }\nfun main() { print(0) }",
+		"fun sq(n: int): int { return n + n }\nfun main() { print(0) }",
+	} {
+		if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := e.LoadFile(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, typeName, err := e.EvalChunk("sq(5)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got int
+	if err := FromValue(result, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != 10 || typeName != "int" {
+		t.Errorf("sq(5) = %d of type %q, want 10 of type int", got, typeName)
+	}
+}
+
+func TestDefineConstant(t *testing.T) {
+	e := NewEngine(Options{})
+	e.DefineConstant("limit", "int", float64(5))
+	if _, err := e.Run("fun below(n: int): bool { return n < limit }"); err != nil {
+		t.Fatal(err)
+	}
+	result, _, err := e.EvalChunk("below(3)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != true {
+		t.Errorf("below(3) = %v, want true", result)
+	}
+}
+
+func TestEvalChunkSession(t *testing.T) {
+	e := NewEngine(Options{Stdout: io.Discard})
+	for _, tt := range []struct {
+		source   string
+		want     Value
+		wantType string
+	}{
+		{"var total = 0", nil, ""},
+		{"fun twice(n: int): int { return n * 2 }", nil, ""},
+		{"total = total + twice(2)\ntotal = total + 5\ntotal", 9.0, "int"},
+		{`"n = " + toString(total)`, "n = 9", "string"},
+		{"type P { x: int }\nP{x: total}.x * 2.5", 22.5, "float"},
+		{"print(total)", nil, ""},
+	} {
+		result, typeName, err := e.EvalChunk(tt.source)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.source, err)
+		}
+		if result != tt.want || typeName != tt.wantType {
+			t.Errorf("%s = %v of type %q, want %v of type %q", tt.source, result, typeName, tt.want, tt.wantType)
+		}
+	}
+}
+
+func TestEvalChunkRedefinition(t *testing.T) {
+	e := NewEngine(Options{})
+	for _, source := range []string{
+		"fun scale(n: int): int { return n * 2 }",
+		"fun twice(n: int): int { return scale(scale(n)) }",
+		"fun scale(n: int): int { return n * 3 }",
+	} {
+		if _, _, err := e.EvalChunk(source); err != nil {
+			t.Fatalf("%s: %v", source, err)
+		}
+	}
+	if result, _, err := e.EvalChunk("twice(1)"); err != nil || result != 9.0 {
+		t.Errorf("twice(1) after redefining scale = %v, %v; want 9", result, err)
+	}
+
+	// A redefinition that breaks the code kept from earlier chunks is
+	// rejected, and the old definition stays
+	_, _, err := e.EvalChunk(`fun scale(s: string): string { return s }`)
+	var burnErr *Error
+	if !errors.As(err, &burnErr) || burnErr.Kind != TypeError {
+		t.Errorf("got %v, want a type error for twice calling scale with an int", err)
+	}
+	if result, _, err := e.EvalChunk("twice(1)"); err != nil || result != 9.0 {
+		t.Errorf("twice(1) after a rejected chunk = %v, %v; want 9", result, err)
+	}
+}
@@ -0,0 +1,166 @@
+package burn
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
+	"github.com/burnlang/burn/pkg/interpreter"
+	"github.com/burnlang/burn/pkg/lexer"
+	"github.com/burnlang/burn/pkg/parser"
+	"github.com/burnlang/burn/pkg/typechecker"
+)
+
+// Severity tells whether a Diagnostic stops a program from running
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// Diagnostic is an error or warning found by Compile. Line and Column are
+// 1-based and zero when the location is unknown. Code is the stable code
+// of the problem, as for Error.
+type Diagnostic struct {
+	Severity Severity
+	Kind     ErrorKind
+	Code     string
+	File     string
+	Line     int
+	Column   int
+	Message  string
+}
+
+// Compilation is the result of Compile
+type Compilation struct {
+	// Program is the parsed program, with an ast.ErrorNode in place of each
+	// declaration or statement that failed to parse. It is nil after a
+	// lexical error.
+	Program *ast.Program
+
+	// Diagnostics lists the problems found, in the order of the stages
+	// that found them and by position within a stage
+	Diagnostics []Diagnostic
+
+	source string
+	file   string
+	types  *typechecker.TypeChecker
+}
+
+// TypeOf returns the type the typechecker resolved for an expression of
+// Program
+func (c *Compilation) TypeOf(expr ast.Expression) (string, bool) {
+	if c.types == nil {
+		return "", false
+	}
+	return c.types.TypeOf(expr)
+}
+
+// Err returns the first error of the compilation as an *Error, or nil if
+// there were only warnings
+func (c *Compilation) Err() error {
+	for _, d := range c.Diagnostics {
+		if d.Severity == SeverityError {
+			return &Error{Kind: d.Kind, Code: d.Code, File: d.File, Line: d.Line, Column: d.Column, Err: errors.New(d.Message)}
+		}
+	}
+	return nil
+}
+
+// Compile lexes, parses, resolves the imports of and typechecks source
+// without running it, for tools such as editors, formatters and linters.
+// It reports every error it can rather than only the first: parsing
+// resumes at the next declaration after a syntax error, and typechecking
+// at the next top-level declaration after a type error. A lexical error
+// ends the compilation, and a program with syntax errors is not
+// typechecked. The Strict, ImportPaths and Resolver options apply.
+//
+// A compilation without errors can be run by Engine.RunCompiled, by any
+// number of engines at the same time.
+func Compile(source, file string, opts Options) *Compilation {
+	return compile(source, file, opts, nil)
+}
+
+// Compile compiles source like the Compile function, with the options of
+// the engine and the functions registered with it. Declarations of earlier
+// runs are not visible to the compiled program.
+func (e *Engine) Compile(source, file string) *Compilation {
+	return compile(source, file, e.opts, e.functions)
+}
+
+func compile(source, file string, opts Options, functions []hostFunction) *Compilation {
+	c := &Compilation{source: source, file: file}
+	report := func(severity Severity, kind ErrorKind, pos int, loc ast.Location, err error) {
+		line, col := loc.Line, loc.Column
+		if !loc.IsValid() && pos >= 0 {
+			line, col = lineAndColumn(source, pos)
+		}
+		code, _ := errcode.Of(err)
+		c.Diagnostics = append(c.Diagnostics, Diagnostic{
+			Severity: severity, Kind: kind, Code: code.ID, File: file, Line: line, Column: col, Message: err.Error(),
+		})
+	}
+
+	lex := lexer.New(source)
+	tokens, err := lex.Tokenize()
+	if err != nil {
+		report(SeverityError, LexicalError, lex.Position(), ast.Location{}, err)
+		return c
+	}
+
+	program, syntaxErrs := parser.New(tokens).ParseAll()
+	c.Program = program
+	for _, err := range syntaxErrs {
+		report(SeverityError, ParseError, err.Position, err.Location, err.Err)
+	}
+	if len(syntaxErrs) > 0 {
+		return c
+	}
+
+	tc := typechecker.New()
+	tc.SetStrict(opts.Strict)
+	for _, dir := range opts.ImportPaths {
+		tc.AddImportPath(dir)
+	}
+	if opts.Resolver != nil {
+		tc.SetResolver(opts.Resolver)
+	}
+	for _, fn := range functions {
+		if err := tc.DefineFunction(fn.name, fn.parameters, fn.returnType); err != nil {
+			report(SeverityError, TypeError, -1, ast.Location{}, err)
+		}
+	}
+	c.types = tc
+
+	baseDir := "."
+	if file != "" {
+		baseDir = filepath.Dir(file)
+	}
+	for _, decl := range program.Declarations {
+		switch decl.(type) {
+		case *ast.ImportDeclaration, *ast.MultiImportDeclaration:
+			if err := tc.ProcessImports([]ast.Declaration{decl}, baseDir); err != nil {
+				report(SeverityError, ImportError, decl.Pos(), decl.Loc(), err)
+			}
+		}
+	}
+
+	for _, err := range tc.CheckAll(program.Declarations) {
+		report(SeverityError, TypeError, err.Position, err.Location, err.Err)
+	}
+	for _, w := range tc.Warnings() {
+		report(SeverityWarning, TypeError, w.Position, w.Location, errcode.New(w.Code, w.Message))
+	}
+	// Resolved now, as a compilation may be run by many interpreters at once
+	interpreter.Resolve(program)
+	return c
+}
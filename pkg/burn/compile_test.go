@@ -0,0 +1,90 @@
+package burn
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/burnlang/burn/pkg/ast"
+)
+
+func TestCompileReportsEveryError(t *testing.T) {
+	c := Compile(`fun a(): int { return "x" }
+fun b(): string { return 1 }
+var c: bool = 2
+`, "errors.bn", Options{})
+	if len(c.Diagnostics) != 3 {
+		t.Fatalf("got %d diagnostics, want 3: %+v", len(c.Diagnostics), c.Diagnostics)
+	}
+	for j, d := range c.Diagnostics {
+		if d.Severity != SeverityError || d.Kind != TypeError || d.Code == "" || d.File != "errors.bn" || d.Line != j+1 {
+			t.Errorf("diagnostic %d is %+v, want a type error with a code at line %d", j, d, j+1)
+		}
+	}
+	var burnErr *Error
+	if err := c.Err(); !errors.As(err, &burnErr) || burnErr.Line != 1 {
+		t.Errorf("Err() = %v, want the first error", err)
+	}
+}
+
+func TestCompileRecoversFromSyntaxErrors(t *testing.T) {
+	c := Compile("fun a( { }\nfun ok(): int { return 1 }\nvar = 3\n", "", Options{})
+	var parseErrors int
+	for _, d := range c.Diagnostics {
+		if d.Kind == ParseError {
+			parseErrors++
+		}
+		if d.Kind == TypeError {
+			t.Errorf("a program with syntax errors was typechecked: %+v", d)
+		}
+	}
+	if parseErrors != 2 {
+		t.Errorf("got %d parse errors, want 2: %+v", parseErrors, c.Diagnostics)
+	}
+	var nodes, functions int
+	for _, decl := range c.Program.Declarations {
+		switch decl.(type) {
+		case *ast.ErrorNode:
+			nodes++
+		case *ast.FunctionDeclaration:
+			functions++
+		}
+	}
+	if nodes != 2 || functions != 1 {
+		t.Errorf("program has %d error nodes and %d functions, want 2 and 1", nodes, functions)
+	}
+
+	if c := Compile(`var s = "unterminated`, "", Options{}); c.Program != nil || len(c.Diagnostics) != 1 || c.Diagnostics[0].Kind != LexicalError {
+		t.Errorf("a lexical error gave %+v", c.Diagnostics)
+	}
+}
+
+func TestCompileTypesAndWarnings(t *testing.T) {
+	c := Compile("fun f(n: int) { print(1) }\nvar x = 1.5 * 2\n", "", Options{})
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Diagnostics) != 1 || c.Diagnostics[0].Severity != SeverityWarning {
+		t.Errorf("got %+v, want a warning for the unused parameter", c.Diagnostics)
+	}
+	x := c.Program.Declarations[1].(*ast.VariableDeclaration)
+	if typeName, ok := c.TypeOf(x.Value); !ok || typeName != "float" {
+		t.Errorf("TypeOf(1.5 * 2) = %q, %v; want float", typeName, ok)
+	}
+}
+
+func TestEngineCompileSeesRegisteredFunctions(t *testing.T) {
+	e := NewEngine(Options{})
+	if err := e.RegisterFunction("host", func(n int) int { return n + 1 }); err != nil {
+		t.Fatal(err)
+	}
+	if err := Compile("host(1)", "", Options{}).Err(); err == nil {
+		t.Error("Compile without the engine accepted a call of host")
+	}
+	c := e.Compile("host(1)", "")
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if result, err := e.RunCompiled(c); err != nil || result != 2.0 {
+		t.Errorf("host(1) = %v, %v; want 2", result, err)
+	}
+}
@@ -0,0 +1,308 @@
+// Package burn embeds the Burn language in Go programs. An Engine lexes,
+// parses, typechecks and runs Burn source, and keeps the functions and
+// variables it defines from one run to the next.
+package burn
+
+import (
+	"io"
+	"os"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
+	"github.com/burnlang/burn/pkg/interpreter"
+	"github.com/burnlang/burn/pkg/lexer"
+	"github.com/burnlang/burn/pkg/parser"
+	"github.com/burnlang/burn/pkg/resolver"
+	"github.com/burnlang/burn/pkg/typechecker"
+)
+
+// Value is a Burn runtime value: float64, string, bool, nil, bytes, an
+// array, a struct or a class instance
+type Value = interpreter.Value
+
+// Limits bounds the steps, time, memory and call depth of each run
+type Limits = interpreter.Limits
+
+// DefaultMaxDepth is the call depth allowed when Limits.MaxDepth is zero
+const DefaultMaxDepth = interpreter.DefaultMaxDepth
+
+// Capability is a kind of side effect that Options.Deny can forbid
+type Capability = interpreter.Capability
+
+// Policy lists the builtins scripts may or may not call
+type Policy = interpreter.Policy
+
+// PolicyError is the error of a call of a builtin forbidden by
+// Options.Policy
+type PolicyError = interpreter.PolicyError
+
+// RecursionError is the error of a call deeper than Limits.MaxDepth, with
+// the chain of calls in progress
+type RecursionError = interpreter.RecursionError
+
+const (
+	CapabilityNetwork = interpreter.CapabilityNetwork
+	CapabilityInput   = interpreter.CapabilityInput
+)
+
+var (
+	// ErrLimitExceeded is wrapped by the error of a run that goes over its
+	// Limits
+	ErrLimitExceeded = interpreter.ErrLimitExceeded
+
+	// ErrCapabilityDenied is wrapped by the error of a run that uses a
+	// denied capability
+	ErrCapabilityDenied = interpreter.ErrCapabilityDenied
+)
+
+// Options configures an Engine
+type Options struct {
+	// Strict enables the extra checks of burn --strict
+	Strict bool
+
+	// ImportPaths are directories searched for imports that are not found
+	// relative to the working directory
+	ImportPaths []string
+
+	// Resolver, when set, replaces the lookup of imports on disk and in the
+	// standard library, and ImportPaths is ignored
+	Resolver resolver.Resolver
+
+	// Stdout receives the output of print; it defaults to os.Stdout
+	Stdout io.Writer
+
+	// Stdin is read by input; it defaults to os.Stdin
+	Stdin io.Reader
+
+	// Limits applies afresh to every Run, RunFile and Call
+	Limits Limits
+
+	// Deny lists the capabilities scripts may not use, for example
+	// CapabilityNetwork|CapabilityInput
+	Deny Capability
+
+	// Policy restricts the builtins scripts may call by name, for example
+	// Policy{Allow: []string{"print", "HTTP.get"}}. Registering a function
+	// the policy forbids fails.
+	Policy Policy
+
+	// BeforeCall, when set, is called before every call of a Burn function
+	// or builtin. Returning an error stops the call with that error.
+	BeforeCall func(call *CallInfo) error
+
+	// AfterCall, when set, is called after every call of a Burn function
+	// or builtin with its duration and error
+	AfterCall func(call *CallInfo)
+}
+
+// CallInfo describes a call seen by Options.BeforeCall and Options.AfterCall
+type CallInfo = interpreter.CallInfo
+
+// callHooks adapts Options.BeforeCall and Options.AfterCall to the
+// interpreter
+type callHooks struct {
+	before func(call *CallInfo) error
+	after  func(call *CallInfo)
+}
+
+func (h callHooks) BeforeCall(call *CallInfo) error {
+	if h.before == nil {
+		return nil
+	}
+	return h.before(call)
+}
+
+func (h callHooks) AfterCall(call *CallInfo) {
+	if h.after != nil {
+		h.after(call)
+	}
+}
+
+// Engine runs Burn programs. Declarations from earlier runs stay visible to
+// later ones, so a host can load a library once and then run code using it.
+// A run may declare a function, type, class or variable again; the new
+// declaration replaces the old one, and the code kept from earlier runs
+// must still typecheck against it. An Engine is not safe for concurrent use.
+type Engine struct {
+	opts      Options
+	interp    *interpreter.Interpreter
+	accepted  []ast.Declaration
+	sources   []chunkSource
+	functions []hostFunction
+	constants []hostConstant
+	warnings  []typechecker.Warning
+	metrics   Metrics
+}
+
+func NewEngine(opts Options) *Engine {
+	e := &Engine{opts: opts}
+	e.interp, _ = e.newInterpreter()
+	return e
+}
+
+// newInterpreter returns an interpreter set up with the options and host
+// functions of the engine
+func (e *Engine) newInterpreter() (*interpreter.Interpreter, error) {
+	interp := interpreter.New()
+	for _, dir := range e.opts.ImportPaths {
+		interp.AddImportPath(dir)
+	}
+	if e.opts.Stdout != nil {
+		interp.SetOutput(e.opts.Stdout)
+	}
+	if e.opts.Stdin != nil {
+		interp.SetInput(e.opts.Stdin)
+	}
+	interp.Deny(e.opts.Deny)
+	interp.SetPolicy(e.opts.Policy)
+	if e.opts.Resolver != nil {
+		interp.SetResolver(e.opts.Resolver)
+	}
+	if e.opts.BeforeCall != nil || e.opts.AfterCall != nil {
+		interp.AddInvocationHook(callHooks{before: e.opts.BeforeCall, after: e.opts.AfterCall})
+	}
+	for _, fn := range e.functions {
+		if err := interp.DefineFunction(fn.name, fn.impl); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range e.constants {
+		interp.DefineConstant(c.name, c.value)
+	}
+	return interp, nil
+}
+
+// Run runs Burn source. If it declares main, main is called and its result
+// returned; otherwise the top-level statements run and the value of the
+// last one is returned.
+func (e *Engine) Run(source string) (Value, error) {
+	return e.run(source, "")
+}
+
+// RunFile reads and runs a Burn source file like Run
+func (e *Engine) RunFile(path string) (Value, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return e.run(string(source), path)
+}
+
+// Warnings returns the strict mode warnings of the last run
+func (e *Engine) Warnings() []typechecker.Warning {
+	return e.warnings
+}
+
+// RunCompiled runs a program compiled by Compile like Run, without lexing,
+// parsing or typechecking it again. The compilation is only read, so many
+// engines may run it concurrently.
+func (e *Engine) RunCompiled(c *Compilation) (Value, error) {
+	if err := c.Err(); err != nil {
+		return nil, err
+	}
+	e.accepted = append(redeclared(e.accepted, c.Program.Declarations), definitions(c.Program.Declarations)...)
+	e.sources = append(e.sources, chunkSource{File: c.file, Source: c.source})
+	return e.execute(c.Program, c.source, c.file)
+}
+
+func (e *Engine) run(source, file string) (Value, error) {
+	program, _, err := e.check(source, file)
+	if err != nil {
+		return nil, err
+	}
+	return e.execute(program, source, file)
+}
+
+// execute runs a checked program
+func (e *Engine) execute(program *ast.Program, source, file string) (Value, error) {
+	e.interp.SetFile(file)
+	defer e.begin()()
+	var result Value
+	var err error
+	if declaresMain(program) {
+		if err = e.interp.Load(program); err == nil {
+			result, err = e.interp.Call("main")
+		}
+	} else {
+		result, err = e.interp.Eval(program)
+	}
+	if err != nil {
+		return nil, e.runtimeError(err, source, file)
+	}
+	return result, nil
+}
+
+// check parses source and typechecks it together with the declarations of
+// earlier runs, which it replaces where source redeclares them
+func (e *Engine) check(source, file string) (*ast.Program, *typechecker.TypeChecker, error) {
+	lex := lexer.New(source)
+	tokens, err := lex.Tokenize()
+	if err != nil {
+		return nil, nil, newError(LexicalError, err, file, source, lex.Position(), ast.Location{})
+	}
+
+	p := parser.New(tokens)
+	program, err := p.Parse()
+	if err != nil {
+		return nil, nil, newError(ParseError, err, file, source, p.Position(), p.Location())
+	}
+
+	tc := typechecker.New()
+	tc.SetStrict(e.opts.Strict)
+	for _, dir := range e.opts.ImportPaths {
+		tc.AddImportPath(dir)
+	}
+	if e.opts.Resolver != nil {
+		tc.SetResolver(e.opts.Resolver)
+	}
+	for _, fn := range e.functions {
+		if err := tc.DefineFunction(fn.name, fn.parameters, fn.returnType); err != nil {
+			return nil, nil, &Error{Kind: TypeError, File: file, Err: err}
+		}
+	}
+	for _, c := range e.constants {
+		tc.DefineConstant(c.name, c.typeName)
+	}
+
+	kept := redeclared(e.accepted, program.Declarations)
+	decls := append(kept, program.Declarations...)
+	if err := tc.ProcessImports(decls, "."); err != nil {
+		return nil, nil, newError(ImportError, err, file, source, tc.Position(), tc.Location())
+	}
+	err = tc.Check(decls)
+	e.warnings = tc.Warnings()
+	if err != nil {
+		return nil, nil, newError(TypeError, err, file, source, tc.Position(), tc.Location())
+	}
+
+	defs := definitions(program.Declarations)
+	e.accepted = append(kept, defs...)
+	if len(defs) > 0 {
+		e.sources = append(e.sources, chunkSource{File: file, Source: source})
+	}
+	return program, tc, nil
+}
+
+// runtimeError locates a runtime error, which may lie in an imported file
+func (e *Engine) runtimeError(err error, source, file string) error {
+	errFile := e.interp.ErrorFile()
+	if errFile == file && source != "" {
+		return newError(RuntimeError, err, file, source, e.interp.Position(), e.interp.Location())
+	}
+	if errFile != "" {
+		if errSource, readErr := os.ReadFile(errFile); readErr == nil {
+			return newError(RuntimeError, err, errFile, string(errSource), e.interp.Position(), e.interp.Location())
+		}
+	}
+	code, _ := errcode.Of(err)
+	return &Error{Kind: RuntimeError, Code: code.ID, File: errFile, Err: err}
+}
+
+func declaresMain(program *ast.Program) bool {
+	for _, decl := range program.Declarations {
+		if fn, ok := decl.(*ast.FunctionDeclaration); ok && fn.Name == "main" && fn.Receiver == "" {
+			return true
+		}
+	}
+	return false
+}
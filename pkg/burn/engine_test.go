@@ -0,0 +1,90 @@
+package burn
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/burnlang/burn/pkg/resolver"
+)
+
+func TestOptionsStdio(t *testing.T) {
+	var out bytes.Buffer
+	e := NewEngine(Options{Stdout: &out, Stdin: strings.NewReader("Ann\n")})
+	if _, err := e.Run(`print("hi " + input("name? "))`); err != nil {
+		t.Fatal(err)
+	}
+	if want := "name? hi Ann\n"; out.String() != want {
+		t.Errorf("printed %q, want %q", out.String(), want)
+	}
+}
+
+func TestOptionsLimits(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		limits Limits
+		source string
+	}{
+		{"steps", Limits{MaxSteps: 100}, "var n = 0\nwhile (true) { n = n + 1 }"},
+		{"timeout", Limits{Timeout: 20 * time.Millisecond}, "var n = 0\nwhile (true) { n = n + 1 }"},
+		{"memory", Limits{MaxMemory: 1 << 16}, `var s = "x"` + "\nwhile (true) { s = s + s }"},
+		{"depth", Limits{MaxDepth: 20}, "fun down(n: int): int { return down(n + 1) }\ndown(0)"},
+	} {
+		e := NewEngine(Options{Limits: tt.limits})
+		_, err := e.Run(tt.source)
+		if !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("%s: got %v, want the limit exceeded", tt.name, err)
+		}
+		// The limits apply afresh to the next run
+		if result, err := e.Run("1 + 1"); err != nil || result != 2.0 {
+			t.Errorf("%s: the run after the limit was exceeded gave %v, %v", tt.name, result, err)
+		}
+	}
+}
+
+func TestOptionsDeny(t *testing.T) {
+	e := NewEngine(Options{Deny: CapabilityInput, Stdin: strings.NewReader("x\n"), Stdout: io.Discard})
+	_, err := e.Run(`print(input("? "))`)
+	if !errors.Is(err, ErrCapabilityDenied) {
+		t.Errorf("got %v, want input denied", err)
+	}
+	if _, err := e.Run(`print("still runs")`); err != nil {
+		t.Errorf("a run without input failed: %v", err)
+	}
+}
+
+func TestOptionsImportPaths(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "shapes.bn"), []byte("fun area(w: int, h: int): int { return w * h }\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	source := "import \"shapes\"\narea(2, 3)"
+	if _, err := NewEngine(Options{}).Run(source); err == nil {
+		t.Error("an import outside the import paths was found")
+	}
+	result, err := NewEngine(Options{ImportPaths: []string{dir}}).Run(source)
+	if err != nil || result != 6.0 {
+		t.Errorf("area(2, 3) = %v, %v; want 6", result, err)
+	}
+}
+
+func TestOptionsResolver(t *testing.T) {
+	modules := fstest.MapFS{
+		"geo/area.bn":  {Data: []byte("import \"units.bn\"\nfun area(w: int, h: int): int { return w * h * scale() }\n")},
+		"geo/units.bn": {Data: []byte("fun scale(): int { return 10 }\n")},
+	}
+	e := NewEngine(Options{Resolver: resolver.Chain{resolver.FS{FS: modules}, resolver.Stdlib{}}})
+	result, err := e.Run("import \"geo/area.bn\"\narea(2, 3)")
+	if err != nil || result != 60.0 {
+		t.Errorf("area(2, 3) = %v, %v; want 60", result, err)
+	}
+	if _, err := e.Run("import \"test/utils.bn\""); err == nil {
+		t.Error("an import on disk was found through a resolver serving an FS")
+	}
+}
@@ -0,0 +1,72 @@
+package burn
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
+)
+
+// ErrorKind tells which stage of running a program failed
+type ErrorKind string
+
+const (
+	LexicalError ErrorKind = "Lexical error"
+	ParseError   ErrorKind = "Parse error"
+	ImportError  ErrorKind = "Import error"
+	TypeError    ErrorKind = "Type error"
+	RuntimeError ErrorKind = "Runtime error"
+)
+
+// Error is an error in a Burn program. Line and Column are 1-based and zero
+// when the location is unknown. Code is the stable code of the error, such
+// as BRN0301, or "" for errors without one; burn --explain describes it.
+type Error struct {
+	Kind   ErrorKind
+	Code   string
+	File   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *Error) Error() string {
+	var sb strings.Builder
+	if e.File != "" {
+		sb.WriteString(e.File + ": ")
+	}
+	sb.WriteString(string(e.Kind))
+	if e.Line > 0 && !strings.Contains(e.Err.Error(), "at line") {
+		fmt.Fprintf(&sb, " at line %d, column %d", e.Line, e.Column)
+	}
+	sb.WriteString(": " + e.Err.Error())
+	return sb.String()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// newError locates err at loc, or at the byte offset pos of source when the
+// stage that failed did not know the line and column
+func newError(kind ErrorKind, err error, file, source string, pos int, loc ast.Location) *Error {
+	line, col := loc.Line, loc.Column
+	if !loc.IsValid() {
+		line, col = lineAndColumn(source, pos)
+	}
+	code, _ := errcode.Of(err)
+	return &Error{Kind: kind, Code: code.ID, File: file, Line: line, Column: col, Err: err}
+}
+
+// lineAndColumn converts a byte offset in source to a line and column
+func lineAndColumn(source string, pos int) (int, int) {
+	if pos > len(source) {
+		pos = len(source)
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	line := 1 + strings.Count(source[:pos], "\n")
+	return line, pos - strings.LastIndex(source[:pos], "\n")
+}
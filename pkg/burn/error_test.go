@@ -0,0 +1,55 @@
+package burn
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDiagnosticsHaveCodes(t *testing.T) {
+	for _, source := range []string{
+		"fun f(): any { return fun(): int { return 1 } }",
+		"fun f(): int {\n    if (true) { print(1) }\n    return 1\n    print(2)\n}",
+		"fun f(n: int) { print(1) }",
+	} {
+		c := Compile(source, "", Options{})
+		if len(c.Diagnostics) == 0 {
+			t.Errorf("%q: no diagnostics", source)
+		}
+		for _, d := range c.Diagnostics {
+			if d.Code == "" {
+				t.Errorf("%q: %s %q has no code", source, d.Severity, d.Message)
+			}
+		}
+	}
+}
+
+func TestRuntimeErrorsHaveCodes(t *testing.T) {
+	for source, want := range map[string]string{
+		`print(len(HTTP.parseJSON("1")))`: "BRN0510",
+		`print(toInt("ten"))`:             "BRN0512",
+		`assert(false, "checked")`:        "BRN0514",
+		`print([1, 2][5])`:                "BRN0502",
+	} {
+		_, err := NewEngine(Options{}).Run(source)
+		var burnErr *Error
+		if !errors.As(err, &burnErr) {
+			t.Errorf("%q: got %v, want a runtime error", source, err)
+			continue
+		}
+		if burnErr.Code != want {
+			t.Errorf("%q: got code %q for %v, want %s", source, burnErr.Code, err, want)
+		}
+	}
+}
+
+func TestCallErrorsHaveCodes(t *testing.T) {
+	e := NewEngine(Options{})
+	if _, err := e.Run("fun first(xs: [int]): int { return xs[0] }"); err != nil {
+		t.Fatal(err)
+	}
+	_, err := e.Call("first", []int{})
+	var burnErr *Error
+	if !errors.As(err, &burnErr) || burnErr.Code != "BRN0502" {
+		t.Errorf("got %v, want a runtime error with code BRN0502", err)
+	}
+}
@@ -0,0 +1,108 @@
+package burn
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// hostFunction is the signature of a Go function registered with an Engine
+type hostFunction struct {
+	name       string
+	parameters []string
+	returnType string
+	impl       func(args []Value) (Value, error)
+}
+
+// hostConstant is a constant defined with DefineConstant
+type hostConstant struct {
+	name     string
+	typeName string
+	value    Value
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// DefineConstant defines a constant of the Burn type typeName, such as
+// "int" or "[string]", that every later run sees, in functions too, like
+// burn -D does for a program
+func (e *Engine) DefineConstant(name, typeName string, value Value) {
+	e.interp.DefineConstant(name, value)
+	e.constants = append(e.constants, hostConstant{name: name, typeName: typeName, value: value})
+}
+
+// RegisterFunction makes a Go function callable from Burn under name.
+// Parameters and results may be any type ToValue and FromValue convert, or
+// Value for any Burn value. The function may return nothing, one
+// value, an error, or a value and an error; a non-nil error becomes a
+// runtime error in the calling script.
+func (e *Engine) RegisterFunction(name string, fn interface{}) error {
+	fv := reflect.ValueOf(fn)
+	if fv.Kind() != reflect.Func {
+		return fmt.Errorf("cannot register %s: %T is not a function", name, fn)
+	}
+	ft := fv.Type()
+	if ft.IsVariadic() {
+		return fmt.Errorf("cannot register %s: variadic functions are not supported", name)
+	}
+
+	sig := hostFunction{name: name, parameters: make([]string, ft.NumIn())}
+	for j := range sig.parameters {
+		typeName, err := burnType(ft.In(j))
+		if err != nil {
+			return fmt.Errorf("cannot register %s: parameter %d: %v", name, j+1, err)
+		}
+		sig.parameters[j] = typeName
+	}
+
+	returnsError := ft.NumOut() > 0 && ft.Out(ft.NumOut()-1) == errorType
+	results := ft.NumOut()
+	if returnsError {
+		results--
+	}
+	switch results {
+	case 0:
+	case 1:
+		typeName, err := burnType(ft.Out(0))
+		if err != nil {
+			return fmt.Errorf("cannot register %s: result: %v", name, err)
+		}
+		sig.returnType = typeName
+	default:
+		return fmt.Errorf("cannot register %s: functions may return at most one value and an error", name)
+	}
+
+	sig.impl = func(args []Value) (Value, error) {
+		if len(args) != ft.NumIn() {
+			return nil, fmt.Errorf("%s expects %d arguments but got %d", name, ft.NumIn(), len(args))
+		}
+		in := make([]reflect.Value, len(args))
+		for j, arg := range args {
+			v, err := toGo(arg, ft.In(j))
+			if err != nil {
+				return nil, fmt.Errorf("argument %d of %s: %v", j+1, name, err)
+			}
+			in[j] = v
+		}
+
+		out := fv.Call(in)
+		if returnsError {
+			if err, _ := out[len(out)-1].Interface().(error); err != nil {
+				return nil, err
+			}
+		}
+		if results == 0 {
+			return nil, nil
+		}
+		result, err := fromGo(out[0])
+		if err != nil {
+			return nil, fmt.Errorf("result of %s: %v", name, err)
+		}
+		return result, nil
+	}
+	if err := e.interp.DefineFunction(name, sig.impl); err != nil {
+		return fmt.Errorf("cannot register %s: %w", name, err)
+	}
+
+	e.functions = append(e.functions, sig)
+	return nil
+}
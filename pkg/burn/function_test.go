@@ -0,0 +1,86 @@
+package burn
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRegisterFunction(t *testing.T) {
+	e := NewEngine(Options{})
+	for name, fn := range map[string]interface{}{
+		"add":   func(a, b int) int { return a + b },
+		"total": func(xs []float64) float64 { return xs[0] + xs[1] + xs[2] },
+		"greet": func(name string) string { return "hi " + name },
+	} {
+		if err := e.RegisterFunction(name, fn); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for source, want := range map[string]interface{}{
+		"add(2, 3)":                              5,
+		"total([1.0, 2.0, 3.5])":                 6.5,
+		`greet("ann")`:                           "hi ann",
+		"add(add(1, 1), 40)":                     42,
+		"fun f(): int { return add(1, 2) }\nf()": 3,
+	} {
+		result, err := e.Run(source)
+		if err != nil {
+			t.Errorf("%s: %v", source, err)
+			continue
+		}
+		if fmt.Sprint(result) != fmt.Sprint(want) {
+			t.Errorf("%s = %v, want %v", source, result, want)
+		}
+	}
+}
+
+func TestRegisteredFunctionIsTypechecked(t *testing.T) {
+	e := NewEngine(Options{})
+	if err := e.RegisterFunction("add", func(a, b int) int { return a + b }); err != nil {
+		t.Fatal(err)
+	}
+	for _, source := range []string{`add("2", 3)`, "add(1)", `var s: string = add(1, 2)`} {
+		_, err := e.Run(source)
+		var burnErr *Error
+		if !errors.As(err, &burnErr) || burnErr.Kind != TypeError {
+			t.Errorf("%s: got %v, want a type error", source, err)
+		}
+	}
+}
+
+func TestRegisteredFunctionError(t *testing.T) {
+	e := NewEngine(Options{})
+	err := e.RegisterFunction("lookup", func(key string) (string, error) {
+		if key == "" {
+			return "", errors.New("empty key")
+		}
+		return strings.ToUpper(key), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result, err := e.Run(`lookup("k")`); err != nil || result != "K" {
+		t.Errorf(`lookup("k") = %v, %v; want "K"`, result, err)
+	}
+	_, err = e.Run(`lookup("")`)
+	var burnErr *Error
+	if !errors.As(err, &burnErr) || burnErr.Kind != RuntimeError || !strings.Contains(err.Error(), "empty key") {
+		t.Errorf(`lookup(""): got %v, want the runtime error "empty key"`, err)
+	}
+}
+
+func TestRegisterUnsupportedFunction(t *testing.T) {
+	e := NewEngine(Options{})
+	for name, fn := range map[string]interface{}{
+		"notFunction": 5,
+		"variadic":    func(xs ...int) int { return len(xs) },
+		"twoResults":  func() (int, int) { return 1, 2 },
+		"channel":     func(ch chan int) {},
+	} {
+		if err := e.RegisterFunction(name, fn); err == nil {
+			t.Errorf("registering %s was accepted", name)
+		}
+	}
+}
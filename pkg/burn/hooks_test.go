@@ -0,0 +1,88 @@
+package burn
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCallHooks(t *testing.T) {
+	var before, after []string
+	var failed []string
+	e := NewEngine(Options{
+		Stdout: io.Discard,
+		BeforeCall: func(call *CallInfo) error {
+			before = append(before, fmt.Sprintf("%s%v", call.Name, call.Args))
+			return nil
+		},
+		AfterCall: func(call *CallInfo) {
+			kind := "burn"
+			if call.Builtin {
+				kind = "builtin"
+			}
+			after = append(after, call.Name+" "+kind)
+			if call.Err != nil {
+				failed = append(failed, call.Name)
+			}
+			if call.Duration < 0 {
+				t.Errorf("%s took %v", call.Name, call.Duration)
+			}
+		},
+	})
+	_, err := e.Run(`
+fun double(n: int): int { return n * 2 }
+var xs = [double(2)]
+push(xs, 1)
+print(xs)
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"double[2]", "push[[4] 1]", "print[[4 1]]"}; !slices.Equal(before, want) {
+		t.Errorf("before calls %q, want %q", before, want)
+	}
+	if want := []string{"double burn", "push builtin", "print builtin"}; !slices.Equal(after, want) {
+		t.Errorf("after calls %q, want %q", after, want)
+	}
+
+	before, after = nil, nil
+	if _, err := e.Run("toInt(\"x\")"); err == nil {
+		t.Fatal("toInt(\"x\") succeeded")
+	}
+	if !slices.Equal(failed, []string{"toInt"}) {
+		t.Errorf("AfterCall saw errors in %q, want toInt", failed)
+	}
+}
+
+func TestBeforeCallStopsCall(t *testing.T) {
+	quota := errors.New("quota exceeded")
+	calls := 0
+	e := NewEngine(Options{
+		BeforeCall: func(call *CallInfo) error {
+			if call.Name != "step" {
+				return nil
+			}
+			calls++
+			if calls > 3 {
+				return quota
+			}
+			return nil
+		},
+	})
+	_, err := e.Run(`
+fun step(n: int): int { return n + 1 }
+var n = 0
+while (true) {
+    n = step(n)
+}
+`)
+	if !errors.Is(err, quota) || !strings.Contains(err.Error(), "quota exceeded") {
+		t.Errorf("got %v, want the error of BeforeCall", err)
+	}
+	if calls != 4 {
+		t.Errorf("BeforeCall saw %d calls of step, want 4", calls)
+	}
+}
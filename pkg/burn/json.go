@@ -0,0 +1,115 @@
+package burn
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/burnlang/burn/pkg/interpreter"
+)
+
+// MarshalJSON encodes a Burn value as JSON. Structs and class instances
+// become objects with their fields in alphabetical order, as do maps with
+// string keys, arrays become arrays, bytes become base64 strings and nil
+// becomes null. Whole numbers are written without a fractional part.
+func MarshalJSON(v Value) ([]byte, error) {
+	plain, err := toJSON(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(plain)
+}
+
+// UnmarshalJSON decodes JSON into a Burn value. Objects become structs;
+// typeName names the type of the top-level object, or of the objects of a
+// top-level array, and nested objects have no type name. Numbers become
+// float64 and null becomes nil.
+func UnmarshalJSON(data []byte, typeName string) (Value, error) {
+	var plain interface{}
+	if err := json.Unmarshal(data, &plain); err != nil {
+		return nil, err
+	}
+
+	v := fromJSON(plain)
+	switch val := v.(type) {
+	case *Struct:
+		val.TypeName = typeName
+	case *Array:
+		for _, elem := range val.Elements {
+			if s, ok := elem.(*Struct); ok {
+				s.TypeName = typeName
+			}
+		}
+	}
+	return v, nil
+}
+
+// toJSON converts a Burn value to the values encoding/json writes
+func toJSON(v Value) (interface{}, error) {
+	switch val := v.(type) {
+	case nil, string, bool:
+		return val, nil
+	case float64:
+		if math.IsNaN(val) || math.IsInf(val, 0) {
+			return nil, fmt.Errorf("cannot encode %v as JSON", val)
+		}
+		return val, nil
+	case []byte:
+		return base64.StdEncoding.EncodeToString(val), nil
+	case *Array:
+		arr := make([]interface{}, len(val.Elements))
+		for j, elem := range val.Elements {
+			encoded, err := toJSON(elem)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %v", j, err)
+			}
+			arr[j] = encoded
+		}
+		return arr, nil
+	case *Struct:
+		obj := make(map[string]interface{}, len(val.Fields))
+		for name, field := range val.Fields {
+			encoded, err := toJSON(field)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %v", name, err)
+			}
+			obj[name] = encoded
+		}
+		return obj, nil
+	case *Map:
+		obj := make(map[string]interface{}, len(val.Keys))
+		for _, key := range val.Keys {
+			name, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("cannot encode a map with %T keys as JSON", key)
+			}
+			encoded, err := toJSON(val.Entries[key])
+			if err != nil {
+				return nil, fmt.Errorf("key %s: %v", name, err)
+			}
+			obj[name] = encoded
+		}
+		return obj, nil
+	}
+	return nil, fmt.Errorf("cannot encode %T as JSON", v)
+}
+
+// fromJSON converts a value decoded by encoding/json to a Burn value
+func fromJSON(v interface{}) Value {
+	switch val := v.(type) {
+	case []interface{}:
+		arr := make([]Value, len(val))
+		for j, elem := range val {
+			arr[j] = fromJSON(elem)
+		}
+		return interpreter.NewArray(arr)
+	case map[string]interface{}:
+		s := &Struct{Fields: make(map[string]interface{}, len(val))}
+		for name, field := range val {
+			s.Fields[name] = fromJSON(field)
+		}
+		return s
+	}
+	return v
+}
@@ -0,0 +1,77 @@
+package burn
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	e := NewEngine(Options{})
+	v, err := e.Run(`
+type Order { id: int, items: [string], total: float, note: string?, raw: bytes, extra: map<string, int> }
+Order{id: 7, items: ["a", "b"], total: 2.5, note: nil, raw: bytesOf([104, 105]), extra: {"z": 1, "b": 2}}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := MarshalJSON(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"extra":{"b":2,"z":1},"id":7,"items":["a","b"],"note":null,"raw":"aGk=","total":2.5}`
+	if string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+
+	if data, err := MarshalJSON(math.Inf(1)); err == nil {
+		t.Errorf("infinity was encoded as %s", data)
+	}
+	for _, source := range []string{"var m = {1: 2}\nm", "var f = fun(): int { return 1 }\nf"} {
+		v, err := e.Run(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data, err := MarshalJSON(v); err == nil {
+			t.Errorf("%s was encoded as %s", source, data)
+		}
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	v, err := UnmarshalJSON([]byte(`[{"id": 1, "tags": ["x"], "owner": {"name": "ann"}}, {"id": 2, "tags": [], "owner": null}]`), "Order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	orders, ok := v.(*Array)
+	if !ok || len(orders.Elements) != 2 {
+		t.Fatalf("got %#v, want an array of 2 orders", v)
+	}
+	first := orders.Elements[0].(*Struct)
+	if first.TypeName != "Order" || first.Fields["id"] != 1.0 {
+		t.Errorf("first order is %v", first)
+	}
+	if owner := first.Fields["owner"].(*Struct); owner.TypeName != "" || owner.Fields["name"] != "ann" {
+		t.Errorf("nested object is %v, want one without a type name", owner)
+	}
+	if tags := first.Fields["tags"].(*Array); len(tags.Elements) != 1 || tags.Elements[0] != "x" {
+		t.Errorf("tags are %v", tags)
+	}
+
+	// A decoded value can be passed to a Burn function expecting the type
+	e := NewEngine(Options{})
+	_, err = e.Run(`
+type Owner { name: string }
+type Order { id: int, tags: [string], owner: Owner? }
+fun count(orders: [Order]): int { return len(orders) }
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := CallAs[int](e, "count", v); err != nil || n != 2 {
+		t.Errorf("count = %d, %v; want 2", n, err)
+	}
+
+	if _, err := UnmarshalJSON([]byte("{"), "Order"); err == nil {
+		t.Error("invalid JSON was accepted")
+	}
+}
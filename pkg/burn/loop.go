@@ -0,0 +1,172 @@
+package burn
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrLoopStopped is returned for work submitted to a Loop after Stop
+var ErrLoopStopped = errors.New("loop stopped")
+
+// Loop runs work on an Engine one piece at a time on a goroutine of its
+// own. Asynchronous Go code such as HTTP handlers, timers and file
+// watchers can hand it calls of Burn callbacks from any goroutine, and the
+// engine only ever sees one at a time. Submitting work never blocks, so
+// work may be submitted from inside the loop, for example by a registered
+// function, with Post or After; waiting for it there with Call, Do or Stop
+// would deadlock. A piece of work that panics fails with the panic as its
+// error, and the loop goes on.
+type Loop struct {
+	engine *Engine
+	wake   chan struct{}
+	done   chan struct{}
+
+	mu      sync.Mutex
+	queue   []job
+	stopped bool
+}
+
+// job is a piece of work queued on a Loop. fail reports the panic of run
+// to whoever waits for the work.
+type job struct {
+	run  func()
+	fail func(err error)
+}
+
+// Result is the outcome of a call posted to a Loop
+type Result struct {
+	Value Value
+	Err   error
+}
+
+// NewLoop starts a loop running work on e. Once the loop is started, e
+// must only be used through it.
+func NewLoop(e *Engine) *Loop {
+	l := &Loop{
+		engine: e,
+		wake:   make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	go l.run()
+	return l
+}
+
+func (l *Loop) run() {
+	defer close(l.done)
+	for {
+		l.mu.Lock()
+		jobs, stopped := l.queue, l.stopped
+		l.queue = nil
+		l.mu.Unlock()
+
+		if len(jobs) == 0 {
+			if stopped {
+				return
+			}
+			<-l.wake
+			continue
+		}
+		for _, j := range jobs {
+			l.runJob(j)
+		}
+	}
+}
+
+// runJob runs j, turning a panic into its error so that it does not end
+// the loop and the program with it
+func (l *Loop) runJob(j job) {
+	defer func() {
+		if r := recover(); r != nil {
+			j.fail(fmt.Errorf("panic in loop: %v", r))
+		}
+	}()
+	j.run()
+}
+
+// submit queues job, failing once the loop is stopped. The queue has no
+// bound, so submit never waits for the loop.
+func (l *Loop) submit(j job) error {
+	l.mu.Lock()
+	if l.stopped {
+		l.mu.Unlock()
+		return ErrLoopStopped
+	}
+	l.queue = append(l.queue, j)
+	l.mu.Unlock()
+	l.signal()
+	return nil
+}
+
+// signal wakes the loop if it is waiting for work
+func (l *Loop) signal() {
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Post queues a call of the Burn function name, as made by Engine.Call,
+// and returns at once. The result is delivered on the returned channel,
+// which has room for it, so callers not interested in it can drop the
+// channel.
+func (l *Loop) Post(name string, args ...interface{}) <-chan Result {
+	results := make(chan Result, 1)
+	err := l.submit(job{
+		run: func() {
+			v, err := l.engine.Call(name, args...)
+			results <- Result{Value: v, Err: err}
+		},
+		fail: func(err error) { results <- Result{Err: err} },
+	})
+	if err != nil {
+		results <- Result{Err: err}
+	}
+	return results
+}
+
+// Call queues a call of the Burn function name and waits for its result
+func (l *Loop) Call(name string, args ...interface{}) (Value, error) {
+	r := <-l.Post(name, args...)
+	return r.Value, r.Err
+}
+
+// After posts a call of the Burn function name once d has passed. Stopping
+// the returned timer cancels the call if it has not been posted yet.
+func (l *Loop) After(d time.Duration, name string, args ...interface{}) *time.Timer {
+	return time.AfterFunc(d, func() {
+		l.Post(name, args...)
+	})
+}
+
+// Do runs fn on the loop and waits for it to return. fn has the engine to
+// itself, so it can make several calls, run code or take a snapshot
+// without other work coming in between. If fn panics, Do returns the panic
+// as an error.
+func (l *Loop) Do(fn func(e *Engine)) error {
+	finished := make(chan error, 1)
+	err := l.submit(job{
+		run: func() {
+			fn(l.engine)
+			finished <- nil
+		},
+		fail: func(err error) { finished <- err },
+	})
+	if err != nil {
+		return err
+	}
+	return <-finished
+}
+
+// Stop lets the work already queued finish, then ends the loop. Work
+// submitted afterwards fails with ErrLoopStopped. Stop may be called more
+// than once and from several goroutines, but not from work running on the
+// loop, which it would wait for forever.
+func (l *Loop) Stop() {
+	l.mu.Lock()
+	l.stopped = true
+	l.mu.Unlock()
+	l.signal()
+	<-l.done
+}
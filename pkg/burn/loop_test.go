@@ -0,0 +1,214 @@
+package burn
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoopPostFromInsideLoop(t *testing.T) {
+	e := NewEngine(Options{})
+	if _, err := e.Run("fun inc(n: int): int { return n + 1 }"); err != nil {
+		t.Fatal(err)
+	}
+	l := NewLoop(e)
+
+	const jobs = 1000
+	posted := make(chan []<-chan Result, 1)
+	go func() {
+		l.Do(func(*Engine) {
+			var results []<-chan Result
+			for n := range jobs {
+				results = append(results, l.Post("inc", n))
+			}
+			posted <- results
+		})
+	}()
+
+	var results []<-chan Result
+	select {
+	case results = <-posted:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("posting %d jobs from inside the loop blocked", jobs)
+	}
+	for n, ch := range results {
+		r := <-ch
+		if r.Err != nil {
+			t.Fatalf("inc(%d): %v", n, r.Err)
+		}
+		var got int
+		if err := FromValue(r.Value, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got != n+1 {
+			t.Errorf("inc(%d) = %d", n, got)
+		}
+	}
+	l.Stop()
+}
+
+func TestLoopStopFinishesQueuedWork(t *testing.T) {
+	e := NewEngine(Options{})
+	if _, err := e.Run("fun inc(n: int): int { return n + 1 }"); err != nil {
+		t.Fatal(err)
+	}
+	l := NewLoop(e)
+	results := make([]<-chan Result, 100)
+	for n := range results {
+		results[n] = l.Post("inc", n)
+	}
+	l.Stop()
+
+	for n, ch := range results {
+		if r := <-ch; r.Err != nil {
+			t.Errorf("inc(%d): %v", n, r.Err)
+		}
+	}
+	if _, err := l.Call("inc", 1); err != ErrLoopStopped {
+		t.Errorf("Call after Stop: got %v, want ErrLoopStopped", err)
+	}
+}
+
+func TestLoopSurvivesPanickingWork(t *testing.T) {
+	e := NewEngine(Options{})
+	err := e.RegisterFunction("explode", func(n int) int { panic("boom") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Run("fun inc(n: int): int { return n + 1 }\nfun blast(n: int): int { return explode(n) }"); err != nil {
+		t.Fatal(err)
+	}
+	l := NewLoop(e)
+	defer l.Stop()
+
+	if err := l.Do(func(*Engine) { panic("boom") }); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Do of a panicking function: got %v, want the panic", err)
+	}
+	if _, err := l.Call("blast", 1); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("call of a panicking function: got %v, want the panic", err)
+	}
+	if v, err := l.Call("inc", 1); err != nil || v != float64(2) {
+		t.Errorf("inc(1) after a panic = %v, %v", v, err)
+	}
+}
+
+func TestLoopStopTwice(t *testing.T) {
+	l := NewLoop(NewEngine(Options{}))
+	stopped := make(chan struct{})
+	go func() {
+		l.Stop()
+		l.Stop()
+		close(stopped)
+	}()
+	l.Stop()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stopping a stopped loop blocked")
+	}
+	if err := l.Do(func(*Engine) {}); err != ErrLoopStopped {
+		t.Errorf("Do after Stop: got %v, want ErrLoopStopped", err)
+	}
+}
+
+func TestLoopSerializesCalls(t *testing.T) {
+	e := NewEngine(Options{})
+	_, err := e.Run(`
+class Hits {
+    static var n: int = 0
+}
+fun hit(): int {
+    Hits.n = Hits.n + 1
+    return Hits.n
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewLoop(e)
+	defer l.Stop()
+
+	const callers, calls = 10, 50
+	var wg sync.WaitGroup
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range calls {
+				if _, err := l.Call("hit"); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	var got int
+	err = l.Do(func(e *Engine) {
+		result, _, err := e.EvalChunk("Hits.n")
+		if err == nil {
+			err = FromValue(result, &got)
+		}
+		if err != nil {
+			t.Error(err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != callers*calls {
+		t.Errorf("hits = %d, want %d", got, callers*calls)
+	}
+}
+
+func TestLoopAfterAndStopped(t *testing.T) {
+	var out syncBuffer
+	e := NewEngine(Options{Stdout: &out})
+	if _, err := e.Run(`fun tick(s: string) { print(s) }`); err != nil {
+		t.Fatal(err)
+	}
+	l := NewLoop(e)
+	l.After(10*time.Millisecond, "tick", "late")
+	cancelled := l.After(time.Hour, "tick", "never")
+	if _, err := l.Call("tick", "now"); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(out.String(), "late") && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancelled.Stop()
+	l.Stop()
+	if got := out.String(); got != "now\nlate\n" {
+		t.Errorf("printed %q, want %q", got, "now\nlate\n")
+	}
+
+	if _, err := l.Call("tick", "x"); !errors.Is(err, ErrLoopStopped) {
+		t.Errorf("Call after Stop: got %v, want ErrLoopStopped", err)
+	}
+	if err := l.Do(func(*Engine) {}); !errors.Is(err, ErrLoopStopped) {
+		t.Errorf("Do after Stop: got %v, want ErrLoopStopped", err)
+	}
+}
+
+// syncBuffer is a bytes.Buffer that the loop can print to while a test
+// reads it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,49 @@
+package burn
+
+import "time"
+
+// Metrics describes the work done by the last Run, RunFile, RunCompiled,
+// EvalChunk or Call of an Engine
+type Metrics struct {
+	WallTime time.Duration
+
+	// Nodes is the number of statements and expressions evaluated
+	Nodes int
+
+	// AllocatedBytes is the number of bytes of strings and arrays the run
+	// created, as counted for Limits.MaxMemory. Memory that is freed is
+	// not subtracted, so this is the total allocated, not the peak in use.
+	AllocatedBytes int64
+
+	// BuiltinCalls counts the calls of each builtin, including functions
+	// registered with RegisterFunction, by name
+	BuiltinCalls map[string]int
+
+	// HTTPBytes is the size of the bodies of the HTTP requests made and
+	// the responses received
+	HTTPBytes int64
+}
+
+// Metrics returns the metrics of the last run
+func (e *Engine) Metrics() Metrics {
+	return e.metrics
+}
+
+// begin starts a run under the limits of the engine. The function it
+// returns records the metrics of the run and is meant to be deferred:
+//
+//	defer e.begin()()
+func (e *Engine) begin() func() {
+	e.interp.SetLimits(e.opts.Limits)
+	start := time.Now()
+	return func() {
+		m := e.interp.Metrics()
+		e.metrics = Metrics{
+			WallTime:       time.Since(start),
+			Nodes:          m.Nodes,
+			AllocatedBytes: m.Memory,
+			BuiltinCalls:   m.BuiltinCalls,
+			HTTPBytes:      m.HTTPBytes,
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package burn
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	e := NewEngine(Options{Stdout: io.Discard})
+	if err := e.RegisterFunction("host", func() int { return 1 }); err != nil {
+		t.Fatal(err)
+	}
+	_, err := e.Run(`
+fun viaHost(): int { return host() }
+var xs = [host()]
+push(xs, 2)
+push(xs, 3)
+var s = "ab"
+for (x in xs) {
+    s = s + s
+    print(x)
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := e.Metrics()
+	for name, want := range map[string]int{"host": 1, "push": 2, "print": 3} {
+		if got := m.BuiltinCalls[name]; got != want {
+			t.Errorf("%s was called %d times, want %d", name, got, want)
+		}
+	}
+	if m.Nodes < 20 || m.AllocatedBytes == 0 || m.WallTime <= 0 {
+		t.Errorf("got %+v, want the nodes, memory and time of the run", m)
+	}
+
+	// Metrics are those of the last run only
+	if _, err := e.Call("viaHost"); err != nil {
+		t.Fatal(err)
+	}
+	m = e.Metrics()
+	if m.BuiltinCalls["host"] != 1 || m.BuiltinCalls["print"] != 0 {
+		t.Errorf("after a call of viaHost, builtin calls are %v", m.BuiltinCalls)
+	}
+}
+
+func TestMetricsHTTPBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		fmt.Fprint(w, "0123456789")
+	}))
+	defer server.Close()
+
+	e := NewEngine(Options{})
+	_, err := e.Run(fmt.Sprintf(`
+import "http"
+var response = HTTP.post(%q, "hello")
+response.body
+`, server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := e.Metrics().HTTPBytes; got != 15 {
+		t.Errorf("HTTPBytes = %d, want 5 sent and 10 received", got)
+	}
+}
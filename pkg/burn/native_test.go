@@ -0,0 +1,66 @@
+package burn
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/burnlang/burn/pkg/stdlib"
+)
+
+func init() {
+	stdlib.RegisterNativeLibrary("testkv", `
+class KV {
+    fun get(key: string): string {}
+}
+fun words(s: string): [string] {}
+fun count(xs: [int]): int {}
+`, map[string]stdlib.Builtin{
+		"KV.get": func(args []interface{}) (interface{}, error) {
+			if args[0] == "" {
+				return nil, errors.New("empty key")
+			}
+			return "value of " + args[0].(string), nil
+		},
+		"words": func(args []interface{}) (interface{}, error) {
+			var out []interface{}
+			for _, w := range strings.Fields(args[0].(string)) {
+				out = append(out, w)
+			}
+			return out, nil
+		},
+		"count": func(args []interface{}) (interface{}, error) {
+			xs, ok := args[0].([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("count got %T", args[0])
+			}
+			return float64(len(xs)), nil
+		},
+	})
+}
+
+func TestNativeLibrary(t *testing.T) {
+	e := NewEngine(Options{})
+	if _, err := e.Run(`import "testkv"`); err != nil {
+		t.Fatal(err)
+	}
+	for source, want := range map[string]interface{}{
+		`KV.get("a")`:         "value of a",
+		`len(words("a b c"))`: 3.0,
+		`words("x y")[1]`:     "y",
+		`count([1, 2, 3, 4])`: 4.0,
+		`count(map([1, 2], fun(n: int): int { return n }))`: 2.0,
+	} {
+		result, err := e.Run(source)
+		if err != nil || result != want {
+			t.Errorf("%s = %v, %v; want %v", source, result, err, want)
+		}
+	}
+	if _, err := e.Run(`KV.get("")`); err == nil || !strings.Contains(err.Error(), "empty key") {
+		t.Errorf(`KV.get(""): got %v, want the error of the builtin`, err)
+	}
+	if _, err := e.Run(`count("abc")`); err == nil {
+		t.Error("a string was accepted for the [int] parameter of a native function")
+	}
+}
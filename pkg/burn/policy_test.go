@@ -0,0 +1,51 @@
+package burn
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestPolicy(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		policy  Policy
+		source  string
+		builtin string // the builtin denied, or "" when the run succeeds
+		rule    string
+	}{
+		{"allowed", Policy{Allow: []string{"print", "HTTP.get"}}, `print("ok")`, "", ""},
+		{"not allowed", Policy{Allow: []string{"print", "HTTP.get"}}, `print(toInt("1"))`, "toInt", ""},
+		{"other method", Policy{Allow: []string{"print", "HTTP.get"}}, `HTTP.post("http://localhost", "x")`, "HTTP.post", ""},
+		{"denied", Policy{Deny: []string{"toInt"}}, `print(toInt("1"))`, "toInt", "toInt"},
+		{"denied class", Policy{Deny: []string{"HTTP"}}, `HTTP.post("http://localhost", "x")`, "HTTP.post", "HTTP"},
+		{"deny wins", Policy{Allow: []string{"print", "toInt"}, Deny: []string{"toInt"}}, `print(toInt("1"))`, "toInt", "toInt"},
+	} {
+		_, err := NewEngine(Options{Policy: tt.policy, Stdout: io.Discard}).Run(tt.source)
+		if tt.builtin == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+			continue
+		}
+		var policyErr *PolicyError
+		if !errors.As(err, &policyErr) || !errors.Is(err, ErrCapabilityDenied) {
+			t.Errorf("%s: got %v, want a policy error", tt.name, err)
+			continue
+		}
+		if policyErr.Builtin != tt.builtin || policyErr.Rule != tt.rule {
+			t.Errorf("%s: denied %s by rule %q, want %s by rule %q", tt.name, policyErr.Builtin, policyErr.Rule, tt.builtin, tt.rule)
+		}
+	}
+}
+
+func TestPolicyRegisterFunction(t *testing.T) {
+	e := NewEngine(Options{Policy: Policy{Allow: []string{"print", "double"}}})
+	if err := e.RegisterFunction("double", func(n int) int { return 2 * n }); err != nil {
+		t.Fatal(err)
+	}
+	err := e.RegisterFunction("triple", func(n int) int { return 3 * n })
+	if !errors.Is(err, ErrCapabilityDenied) {
+		t.Errorf("registering a function missing from Allow: got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package burn
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+func TestRunCompiledConcurrently(t *testing.T) {
+	c := Compile(`
+import "strings"
+
+type Tally { words: [string], count: int }
+
+fun tally(text: string): Tally {
+    var t = Tally{words: [], count: 0}
+    for (w in Strings.split(text, " ")) {
+        push(t.words, w)
+        t.count = t.count + 1
+    }
+    return t
+}
+
+var seen = [0]
+var t = tally("a b c")
+push(seen, t.count)
+print(t.words)
+len(seen) * 10 + t.count
+`, "", Options{})
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	const engines = 8
+	var wg sync.WaitGroup
+	outs := make([]bytes.Buffer, engines)
+	results := make([]Value, engines)
+	errs := make([]error, engines)
+	for k := range engines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e := NewEngine(Options{Stdout: &outs[k]})
+			// Each engine runs the program twice, on its own state
+			for range 2 {
+				results[k], errs[k] = e.RunCompiled(c)
+			}
+		}()
+	}
+	wg.Wait()
+	for k := range engines {
+		if errs[k] != nil {
+			t.Errorf("engine %d: %v", k, errs[k])
+			continue
+		}
+		if results[k] != 23.0 || outs[k].String() != "[a b c]\n[a b c]\n" {
+			t.Errorf("engine %d gave %v and printed %q", k, results[k], outs[k].String())
+		}
+	}
+}
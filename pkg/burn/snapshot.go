@@ -0,0 +1,253 @@
+package burn
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/interpreter"
+)
+
+// chunkSource is the source of a run that defined something
+type chunkSource struct {
+	File   string `json:"file,omitempty"`
+	Source string `json:"source"`
+}
+
+// snapshot is the serialized state of an Engine. Redeclared names the
+// globals whose values cannot be saved, whose declarations are run again.
+type snapshot struct {
+	Sources    []chunkSource             `json:"sources"`
+	Globals    map[string]*snapshotValue `json:"globals"`
+	Redeclared []string                  `json:"redeclared,omitempty"`
+}
+
+// snapshotValue is a Burn value tagged with its kind, so that numbers,
+// arrays, structs and maps read back exactly as they were. A map keeps its
+// keys in order in Keys and their values in Elements.
+type snapshotValue struct {
+	Kind     string                    `json:"kind"`
+	Number   float64                   `json:"number,omitempty"`
+	String   string                    `json:"string,omitempty"`
+	Bool     bool                      `json:"bool,omitempty"`
+	Bytes    []byte                    `json:"bytes,omitempty"`
+	Elements []*snapshotValue          `json:"elements,omitempty"`
+	Type     string                    `json:"type,omitempty"`
+	Fields   map[string]*snapshotValue `json:"fields,omitempty"`
+	Keys     []*snapshotValue          `json:"keys,omitempty"`
+}
+
+// Snapshot serializes the functions, types, classes and global variables
+// defined so far. Functions, types and classes are saved as the source that
+// declared them and global variables by value. Globals holding things that
+// cannot be saved, such as lambdas, are declared again by Restore, and so
+// hold the value of their initializer rather than one assigned later.
+// Values shared between globals are saved as separate copies.
+func (e *Engine) Snapshot() ([]byte, error) {
+	variables := e.interp.GetVariables()
+	globals := make(map[string]*snapshotValue)
+	var redeclared []string
+	for _, decl := range e.accepted {
+		v, ok := decl.(*ast.VariableDeclaration)
+		if !ok {
+			continue
+		}
+		value, exists := variables[v.Name]
+		if !exists {
+			continue
+		}
+		if encoded, ok := encodeValue(value); ok {
+			globals[v.Name] = encoded
+		} else {
+			redeclared = append(redeclared, v.Name)
+		}
+	}
+	return json.Marshal(snapshot{Sources: e.sources, Globals: globals, Redeclared: redeclared})
+}
+
+// Restore replaces the state of the engine with one saved by Snapshot,
+// possibly by another engine. Functions registered with RegisterFunction
+// are kept, and must include those the saved code calls. Imports are
+// loaded again, and top-level statements and variable initializers are
+// not run again, except the declarations of globals whose values could not
+// be saved. On error the engine is left unchanged.
+func (e *Engine) Restore(data []byte) error {
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("invalid snapshot: %v", err)
+	}
+
+	interp, err := e.newInterpreter()
+	if err != nil {
+		return err
+	}
+	prevInterp, prevAccepted, prevSources := e.interp, e.accepted, e.sources
+	e.interp, e.accepted, e.sources = interp, nil, nil
+
+	if err := e.restore(snap); err != nil {
+		e.interp, e.accepted, e.sources = prevInterp, prevAccepted, prevSources
+		return err
+	}
+	return nil
+}
+
+func (e *Engine) restore(snap snapshot) error {
+	// declaredIn holds the chunk declaring each global
+	declaredIn := make(map[string]chunkSource)
+	for _, chunk := range snap.Sources {
+		program, _, err := e.check(chunk.Source, chunk.File)
+		if err != nil {
+			return err
+		}
+		for _, decl := range program.Declarations {
+			if v, ok := decl.(*ast.VariableDeclaration); ok {
+				declaredIn[v.Name] = chunk
+			}
+		}
+		e.interp.SetFile(chunk.File)
+		if err := e.interp.Load(program); err != nil {
+			return e.runtimeError(err, chunk.Source, chunk.File)
+		}
+	}
+
+	names := make([]string, 0, len(snap.Globals))
+	for name := range snap.Globals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value, err := decodeValue(snap.Globals[name])
+		if err != nil {
+			return fmt.Errorf("invalid snapshot: global %s: %v", name, err)
+		}
+		e.interp.AddVariable(name, value)
+	}
+
+	// Redeclared globals come last, in the order they were declared, so
+	// that their initializers see the other globals
+	redeclared := make(map[string]bool, len(snap.Redeclared))
+	for _, name := range snap.Redeclared {
+		redeclared[name] = true
+	}
+	for _, decl := range e.accepted {
+		if v, ok := decl.(*ast.VariableDeclaration); ok && redeclared[v.Name] {
+			chunk := declaredIn[v.Name]
+			e.interp.SetFile(chunk.File)
+			if _, err := e.interp.Eval(&ast.Program{Declarations: []ast.Declaration{v}}); err != nil {
+				return e.runtimeError(err, chunk.Source, chunk.File)
+			}
+		}
+	}
+	return nil
+}
+
+// encodeValue converts a Burn value for a snapshot, reporting false for
+// values that cannot be saved
+func encodeValue(v Value) (*snapshotValue, bool) {
+	switch val := v.(type) {
+	case nil:
+		return &snapshotValue{Kind: "nil"}, true
+	case float64:
+		return &snapshotValue{Kind: "number", Number: val}, true
+	case string:
+		return &snapshotValue{Kind: "string", String: val}, true
+	case bool:
+		return &snapshotValue{Kind: "bool", Bool: val}, true
+	case []byte:
+		return &snapshotValue{Kind: "bytes", Bytes: val}, true
+	case *Array:
+		elems := make([]*snapshotValue, len(val.Elements))
+		for j, elem := range val.Elements {
+			encoded, ok := encodeValue(elem)
+			if !ok {
+				return nil, false
+			}
+			elems[j] = encoded
+		}
+		return &snapshotValue{Kind: "array", Elements: elems}, true
+	case *Struct:
+		fields := make(map[string]*snapshotValue, len(val.Fields))
+		for name, field := range val.Fields {
+			encoded, ok := encodeValue(field)
+			if !ok {
+				return nil, false
+			}
+			fields[name] = encoded
+		}
+		return &snapshotValue{Kind: "struct", Type: val.TypeName, Fields: fields}, true
+	case *Map:
+		keys := make([]*snapshotValue, len(val.Keys))
+		elems := make([]*snapshotValue, len(val.Keys))
+		for j, key := range val.Keys {
+			encodedKey, _ := encodeValue(key)
+			encoded, ok := encodeValue(val.Entries[key])
+			if !ok {
+				return nil, false
+			}
+			keys[j], elems[j] = encodedKey, encoded
+		}
+		return &snapshotValue{Kind: "map", Keys: keys, Elements: elems}, true
+	}
+	return nil, false
+}
+
+// decodeValue converts a snapshot value back to a Burn value
+func decodeValue(sv *snapshotValue) (Value, error) {
+	if sv == nil {
+		return nil, nil
+	}
+	switch sv.Kind {
+	case "nil":
+		return nil, nil
+	case "number":
+		return sv.Number, nil
+	case "string":
+		return sv.String, nil
+	case "bool":
+		return sv.Bool, nil
+	case "bytes":
+		return append([]byte{}, sv.Bytes...), nil
+	case "array":
+		arr := make([]Value, len(sv.Elements))
+		for j, elem := range sv.Elements {
+			v, err := decodeValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			arr[j] = v
+		}
+		return interpreter.NewArray(arr), nil
+	case "struct":
+		s := &Struct{TypeName: sv.Type, Fields: make(map[string]interface{}, len(sv.Fields))}
+		for name, field := range sv.Fields {
+			v, err := decodeValue(field)
+			if err != nil {
+				return nil, err
+			}
+			s.Fields[name] = v
+		}
+		return s, nil
+	case "map":
+		if len(sv.Keys) != len(sv.Elements) {
+			return nil, fmt.Errorf("map has %d keys but %d values", len(sv.Keys), len(sv.Elements))
+		}
+		m := interpreter.NewMap()
+		for j, key := range sv.Keys {
+			if key == nil || (key.Kind != "number" && key.Kind != "string" && key.Kind != "bool") {
+				return nil, fmt.Errorf("invalid map key")
+			}
+			k, err := decodeValue(key)
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeValue(sv.Elements[j])
+			if err != nil {
+				return nil, err
+			}
+			m.Set(k, v)
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("unknown kind of value %q", sv.Kind)
+}
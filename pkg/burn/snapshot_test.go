@@ -0,0 +1,119 @@
+package burn
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestSnapshotRestoresLambdaGlobals(t *testing.T) {
+	e := NewEngine(Options{})
+	for _, source := range []string{
+		"var factor = 2",
+		"var double = fun(n: int): int { return n * factor }",
+		"var twice = [1, 2]",
+	} {
+		if _, err := e.Run(source); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := e.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := NewEngine(Options{})
+	if err := restored.Restore(data); err != nil {
+		t.Fatal(err)
+	}
+	result, err := restored.Run("double(4) + len(twice)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got int
+	if err := FromValue(result, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != 10 {
+		t.Errorf("double(4) + len(twice) = %d, want 10", got)
+	}
+}
+
+func TestSnapshotKeepsCurrentValues(t *testing.T) {
+	e := NewEngine(Options{Stdout: io.Discard})
+	_, err := e.Run(`
+type Point { x: int, y: int }
+class Counter {
+    var count: int
+    fun inc(c: Counter) { this.count = this.count + 1 }
+}
+var p = Point{x: 1, y: 2}
+var names = ["a"]
+var ages = {"a": 1}
+var raw = bytesOf([1, 2])
+var counter = Counter()
+print("initialized")
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Run(`
+p.x = 10
+push(names, "b")
+ages["b"] = 2
+counter.inc()
+counter.inc()
+`); err != nil {
+		t.Fatal(err)
+	}
+	data, err := e.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	restored := NewEngine(Options{Stdout: &out})
+	if err := restored.Restore(data); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() > 0 {
+		t.Errorf("restoring ran top-level statements again: %q", out.String())
+	}
+	result, err := restored.Run(`
+counter.inc()
+toString(p.x) + " " + toString(len(names)) + " " + toString(ages["b"]) + " " + toString(len(raw)) + " " + toString(counter.count)
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "10 2 2 2 3"; result != want {
+		t.Errorf("restored state gives %q, want %q", result, want)
+	}
+}
+
+func TestRestoreFailureLeavesEngine(t *testing.T) {
+	e := NewEngine(Options{})
+	if err := e.RegisterFunction("host", func() int { return 7 }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Run("fun seven(): int { return host() }\nvar kept = 1"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := e.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other := NewEngine(Options{})
+	if _, err := other.Run("var mine = 5"); err != nil {
+		t.Fatal(err)
+	}
+	for _, bad := range [][]byte{[]byte("not json"), data} {
+		if err := other.Restore(bad); err == nil {
+			t.Errorf("restoring %.20q into an engine without host was accepted", bad)
+		}
+	}
+	if result, err := other.Run("mine"); err != nil || result != 5.0 {
+		t.Errorf("mine = %v, %v after failed restores; want 5", result, err)
+	}
+}
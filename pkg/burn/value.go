@@ -0,0 +1,318 @@
+package burn
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/burnlang/burn/pkg/interpreter"
+)
+
+// Struct is a Burn struct value
+type Struct = interpreter.Struct
+
+// Map is a Burn map value
+type Map = interpreter.Map
+
+// Array is a Burn array value
+type Array = interpreter.Array
+
+// ToValue converts a Go value to a Burn value. Numbers become float64,
+// byte slices become bytes, other slices and arrays become Burn arrays,
+// and structs become Burn structs
+// named after the Go type. Maps with string keys become structs without a
+// type name. Struct fields are renamed with a `burn:"name"` tag and skipped
+// with `burn:"-"`; unexported fields are always skipped. A *Struct, *Array
+// or *Map is already a Burn value and is kept, not copied, so a registered
+// function taking an *Array changes the array of the script.
+func ToValue(v interface{}) (Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return fromGo(reflect.ValueOf(v))
+}
+
+// FromValue stores a Burn value in the Go value dst points to, converting
+// it the opposite way to ToValue
+func FromValue(v Value, dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("FromValue needs a non-nil pointer, got %T", dst)
+	}
+	converted, err := toGo(v, rv.Elem().Type())
+	if err != nil {
+		return err
+	}
+	rv.Elem().Set(converted)
+	return nil
+}
+
+// burnType returns the Burn type name matching a Go type
+func burnType(t reflect.Type) (string, error) {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "int", nil
+	case reflect.Float32, reflect.Float64:
+		return "float", nil
+	case reflect.String:
+		return "string", nil
+	case reflect.Bool:
+		return "bool", nil
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return "bytes", nil
+		}
+		if _, err := burnType(t.Elem()); err != nil {
+			return "", err
+		}
+		return "array", nil
+	case reflect.Struct:
+		return t.Name(), nil
+	case reflect.Ptr:
+		switch t {
+		case reflect.TypeOf((*Array)(nil)):
+			return "array", nil
+		case reflect.TypeOf((*Map)(nil)):
+			return "any", nil
+		}
+		if t.Elem().Kind() == reflect.Struct {
+			return t.Elem().Name(), nil
+		}
+	case reflect.Map:
+		if t.Key().Kind() == reflect.String {
+			return "any", nil
+		}
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "any", nil
+		}
+	}
+	return "", fmt.Errorf("unsupported type %s", t)
+}
+
+// fieldName returns the Burn name of a struct field, or "" to skip it
+func fieldName(f reflect.StructField) string {
+	if f.PkgPath != "" {
+		return ""
+	}
+	tag := f.Tag.Get("burn")
+	if tag == "-" {
+		return ""
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name
+	}
+	return f.Name
+}
+
+// fromGo converts a Go value to a Burn value
+func fromGo(v reflect.Value) (Value, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return append([]byte(nil), v.Bytes()...), nil
+		}
+		arr := make([]Value, v.Len())
+		for j := range arr {
+			elem, err := fromGo(v.Index(j))
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %v", j, err)
+			}
+			arr[j] = elem
+		}
+		return interpreter.NewArray(arr), nil
+	case reflect.Struct:
+		s := &Struct{TypeName: v.Type().Name(), Fields: make(map[string]interface{})}
+		for j := 0; j < v.NumField(); j++ {
+			name := fieldName(v.Type().Field(j))
+			if name == "" {
+				continue
+			}
+			field, err := fromGo(v.Field(j))
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %v", name, err)
+			}
+			s.Fields[name] = field
+		}
+		return s, nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported type %s", v.Type())
+		}
+		s := &Struct{Fields: make(map[string]interface{}, v.Len())}
+		iter := v.MapRange()
+		for iter.Next() {
+			elem, err := fromGo(iter.Value())
+			if err != nil {
+				return nil, fmt.Errorf("key %s: %v", iter.Key().String(), err)
+			}
+			s.Fields[iter.Key().String()] = elem
+		}
+		return s, nil
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		switch burnValue := v.Interface().(type) {
+		case *Struct, *Array, *Map:
+			return burnValue, nil
+		}
+		return fromGo(v.Elem())
+	}
+	return nil, fmt.Errorf("unsupported type %s", v.Type())
+}
+
+// toGo converts a Burn value to the Go type t
+func toGo(v Value, t reflect.Type) (reflect.Value, error) {
+	if t.Kind() == reflect.Interface {
+		if v == nil {
+			return reflect.Zero(t), nil
+		}
+		if !reflect.TypeOf(v).Implements(t) {
+			return reflect.Value{}, fmt.Errorf("cannot convert %T to %s", v, t)
+		}
+		return reflect.ValueOf(v).Convert(t), nil
+	}
+	if t.Kind() == reflect.Ptr {
+		if v == nil {
+			return reflect.Zero(t), nil
+		}
+		switch v.(type) {
+		case *Struct, *Array, *Map:
+			if reflect.TypeOf(v) == t {
+				return reflect.ValueOf(v), nil
+			}
+		}
+		elem, err := toGo(v, t.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	}
+
+	switch val := v.(type) {
+	case float64:
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return reflect.ValueOf(int64(val)).Convert(t), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if val < 0 {
+				return reflect.Value{}, fmt.Errorf("cannot convert %v to %s", val, t)
+			}
+			return reflect.ValueOf(uint64(val)).Convert(t), nil
+		case reflect.Float32, reflect.Float64:
+			return reflect.ValueOf(val).Convert(t), nil
+		}
+	case string:
+		if t.Kind() == reflect.String {
+			return reflect.ValueOf(val).Convert(t), nil
+		}
+	case bool:
+		if t.Kind() == reflect.Bool {
+			return reflect.ValueOf(val).Convert(t), nil
+		}
+	case []byte:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return reflect.ValueOf(append([]byte(nil), val...)).Convert(t), nil
+		}
+	case *Array:
+		switch t.Kind() {
+		case reflect.Slice:
+			slice := reflect.MakeSlice(t, len(val.Elements), len(val.Elements))
+			if err := fillElements(slice, val.Elements); err != nil {
+				return reflect.Value{}, err
+			}
+			return slice, nil
+		case reflect.Array:
+			if len(val.Elements) != t.Len() {
+				return reflect.Value{}, fmt.Errorf("cannot convert array of length %d to %s", len(val.Elements), t)
+			}
+			arr := reflect.New(t).Elem()
+			if err := fillElements(arr, val.Elements); err != nil {
+				return reflect.Value{}, err
+			}
+			return arr, nil
+		}
+	case *Map:
+		if t.Kind() == reflect.Map {
+			m := reflect.MakeMapWithSize(t, len(val.Keys))
+			for _, key := range val.Keys {
+				k, err := toGo(key, t.Key())
+				if err != nil {
+					return reflect.Value{}, fmt.Errorf("key %v: %v", key, err)
+				}
+				elem, err := toGo(val.Entries[key], t.Elem())
+				if err != nil {
+					return reflect.Value{}, fmt.Errorf("key %v: %v", key, err)
+				}
+				m.SetMapIndex(k, elem)
+			}
+			return m, nil
+		}
+	case *Struct:
+		switch t.Kind() {
+		case reflect.Struct:
+			return structToGo(val, t)
+		case reflect.Map:
+			if t.Key().Kind() != reflect.String {
+				break
+			}
+			m := reflect.MakeMapWithSize(t, len(val.Fields))
+			for name, field := range val.Fields {
+				elem, err := toGo(field, t.Elem())
+				if err != nil {
+					return reflect.Value{}, fmt.Errorf("field %s: %v", name, err)
+				}
+				m.SetMapIndex(reflect.ValueOf(name).Convert(t.Key()), elem)
+			}
+			return m, nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("cannot convert %T to %s", v, t)
+}
+
+func fillElements(dst reflect.Value, elems []Value) error {
+	for j, elem := range elems {
+		ev, err := toGo(elem, dst.Type().Elem())
+		if err != nil {
+			return fmt.Errorf("element %d: %v", j, err)
+		}
+		dst.Index(j).Set(ev)
+	}
+	return nil
+}
+
+// structToGo fills a Go struct from a Burn struct. Fields missing from the
+// Burn value keep their zero value.
+func structToGo(s *Struct, t reflect.Type) (reflect.Value, error) {
+	out := reflect.New(t).Elem()
+	for j := 0; j < t.NumField(); j++ {
+		name := fieldName(t.Field(j))
+		if name == "" {
+			continue
+		}
+		field, exists := s.Fields[name]
+		if !exists {
+			continue
+		}
+		fv, err := toGo(field, t.Field(j).Type)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("field %s: %v", name, err)
+		}
+		out.Field(j).Set(fv)
+	}
+	return out, nil
+}
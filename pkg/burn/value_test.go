@@ -0,0 +1,137 @@
+package burn
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegisteredFunctionSharesArray(t *testing.T) {
+	e := NewEngine(Options{})
+	err := e.RegisterFunction("fill", func(a *Array, n int) {
+		for k := range n {
+			a.Elements = append(a.Elements, float64(k))
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := e.Run("var xs = [9]\nfill(xs, 3)\nlen(xs)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != 4.0 {
+		t.Errorf("len(xs) = %v after fill, want 4", result)
+	}
+
+	v, err := ToValue(&Array{Elements: []Value{1.0}})
+	if a, ok := v.(*Array); err != nil || !ok || len(a.Elements) != 1 {
+		t.Errorf("ToValue of an *Array = %#v, %v; want the array", v, err)
+	}
+	var back *Array
+	if err := FromValue(v, &back); err != nil || back != v {
+		t.Errorf("FromValue into an *Array = %#v, %v; want the same array", back, err)
+	}
+}
+
+type address struct {
+	City string `burn:"city"`
+}
+
+type person struct {
+	Name    string   `burn:"name"`
+	Age     int      `burn:"age"`
+	Tags    []string `burn:"tags"`
+	Home    *address `burn:"home"`
+	Scores  [2]float64
+	Secret  string `burn:"-"`
+	private int
+}
+
+func TestStructRoundTrip(t *testing.T) {
+	in := person{Name: "Ann", Age: 41, Tags: []string{"a", "b"}, Home: &address{City: "Oslo"}, Scores: [2]float64{1.5, 2}, Secret: "x", private: 3}
+	v, err := ToValue(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, ok := v.(*Struct)
+	if !ok || s.TypeName != "person" {
+		t.Fatalf("ToValue = %#v, want a person struct", v)
+	}
+	if _, exists := s.Fields["Secret"]; exists {
+		t.Error(`a field tagged burn:"-" was converted`)
+	}
+	if _, exists := s.Fields["private"]; exists {
+		t.Error("an unexported field was converted")
+	}
+	if s.Fields["age"] != 41.0 {
+		t.Errorf("age = %#v, want 41.0", s.Fields["age"])
+	}
+	if tags, ok := s.Fields["tags"].(*Array); !ok || len(tags.Elements) != 2 {
+		t.Errorf("tags = %#v, want an array of 2", s.Fields["tags"])
+	}
+
+	var out person
+	if err := FromValue(v, &out); err != nil {
+		t.Fatal(err)
+	}
+	in.Secret, in.private = "", 0
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("FromValue(ToValue(%+v)) = %+v", in, out)
+	}
+}
+
+func TestRunResultToGo(t *testing.T) {
+	e := NewEngine(Options{})
+	v, err := e.Run(`
+type address { city: string }
+type person { name: string, age: int, tags: [string], home: address? }
+person{name: "Bo", age: 7, tags: ["x"], home: address{city: "Rome"}}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got person
+	if err := FromValue(v, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := person{Name: "Bo", Age: 7, Tags: []string{"x"}, Home: &address{City: "Rome"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestMapConversion(t *testing.T) {
+	v, err := ToValue(map[string]int{"a": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, ok := v.(*Struct)
+	if !ok || s.TypeName != "" || s.Fields["a"] != 1.0 {
+		t.Fatalf("ToValue of a map = %#v, want a struct without a type name", v)
+	}
+	var back map[string]int
+	if err := FromValue(v, &back); err != nil || back["a"] != 1 {
+		t.Errorf("FromValue = %v, %v", back, err)
+	}
+}
+
+func TestFromValueErrors(t *testing.T) {
+	var n int
+	if err := FromValue(1.0, n); err == nil {
+		t.Error("FromValue into a non-pointer was accepted")
+	}
+	if err := FromValue("one", &n); err == nil {
+		t.Error("FromValue of a string into an int was accepted")
+	}
+	var pair [2]int
+	if err := FromValue(&Array{Elements: []Value{1.0}}, &pair); err == nil {
+		t.Error("FromValue of an array of 1 into [2]int was accepted")
+	}
+	var u uint
+	if err := FromValue(-1.0, &u); err == nil {
+		t.Error("FromValue of -1 into a uint was accepted")
+	}
+	if _, err := ToValue(make(chan int)); err == nil {
+		t.Error("ToValue of a channel was accepted")
+	}
+}
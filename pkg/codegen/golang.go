@@ -0,0 +1,630 @@
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/lexer"
+)
+
+// UnsupportedError reports a construct the Go backend cannot translate.
+// Callers fall back to embedding the interpreter when they see it.
+type UnsupportedError struct {
+	Construct string
+}
+
+func (e *UnsupportedError) Error() string {
+	return "unsupported construct for Go code generation: " + e.Construct
+}
+
+func unsupported(format string, args ...interface{}) error {
+	return &UnsupportedError{Construct: fmt.Sprintf(format, args...)}
+}
+
+type goGenerator struct {
+	out       strings.Builder
+	indent    int
+	types     map[string]*ast.TypeDefinition
+	functions map[string]*ast.FunctionDeclaration
+	// usedLabels are the loop labels a break or continue has named
+	usedLabels map[string]bool
+}
+
+// GenerateGo translates a typechecked program into a standalone Go main
+// package. Numbers are represented as float64 to match the interpreter.
+func GenerateGo(program *ast.Program) (string, error) {
+	g := &goGenerator{
+		types:      make(map[string]*ast.TypeDefinition),
+		functions:  make(map[string]*ast.FunctionDeclaration),
+		usedLabels: make(map[string]bool),
+	}
+
+	var topLevel []ast.Declaration
+	for _, decl := range program.Declarations {
+		switch d := decl.(type) {
+		case *ast.TypeDefinition:
+			if len(d.TypeParameters) > 0 {
+				return "", unsupported("generic type %s", d.Name)
+			}
+			g.types[d.Name] = d
+		case *ast.FunctionDeclaration:
+			if d.Receiver != "" {
+				return "", unsupported("method %s.%s", d.Receiver, d.Name)
+			}
+			if len(d.TypeParameters) > 0 {
+				return "", unsupported("generic function %s", d.Name)
+			}
+			g.functions[d.Name] = d
+		case *ast.ImportDeclaration, *ast.MultiImportDeclaration:
+			return "", unsupported("import")
+		case *ast.ClassDeclaration:
+			return "", unsupported("class %s", d.Name)
+		case *ast.InterfaceDeclaration:
+			return "", unsupported("interface %s", d.Name)
+		default:
+			topLevel = append(topLevel, decl)
+		}
+	}
+
+	g.line("// Code generated by burn -exe. DO NOT EDIT.")
+	g.line("")
+	g.line("package main")
+	g.line("")
+	g.out.WriteString(goRuntime)
+
+	for _, decl := range program.Declarations {
+		if typeDef, ok := decl.(*ast.TypeDefinition); ok {
+			if err := g.typeDefinition(typeDef); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	for _, decl := range program.Declarations {
+		if fn, ok := decl.(*ast.FunctionDeclaration); ok {
+			if err := g.function(fn); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	g.line("func main() {")
+	g.indent++
+	g.line("defer burnRecover()")
+	if mainFn, hasMain := g.functions["main"]; hasMain {
+		// The interpreter only runs main when it exists, ignoring other
+		// top-level statements, so the generated program does the same.
+		if mainFn.ReturnType == "int" {
+			g.line("os.Exit(int(b_main()))")
+		} else {
+			g.line("b_main()")
+		}
+	} else {
+		for _, decl := range topLevel {
+			if err := g.statement(decl); err != nil {
+				return "", err
+			}
+		}
+	}
+	g.indent--
+	g.line("}")
+
+	return g.out.String(), nil
+}
+
+func (g *goGenerator) line(format string, args ...interface{}) {
+	if format != "" {
+		g.out.WriteString(strings.Repeat("\t", g.indent))
+		fmt.Fprintf(&g.out, format, args...)
+	}
+	g.out.WriteString("\n")
+}
+
+func (g *goGenerator) goType(typeName string) (string, error) {
+	switch typeName {
+	case "int", "float", "number":
+		return "float64", nil
+	case "string", "bool":
+		return typeName, nil
+	case "", "void":
+		return "", nil
+	}
+
+	if _, exists := g.types[typeName]; exists {
+		return "*T_" + typeName, nil
+	}
+
+	return "", unsupported("type %s", typeName)
+}
+
+func (g *goGenerator) typeDefinition(typeDef *ast.TypeDefinition) error {
+	g.line("type T_%s struct {", typeDef.Name)
+	g.indent++
+	for _, field := range typeDef.Fields {
+		fieldType, err := g.goType(field.Type)
+		if err != nil {
+			return err
+		}
+		g.line("F_%s %s", field.Name, fieldType)
+	}
+	g.indent--
+	g.line("}")
+	g.line("")
+	return nil
+}
+
+func (g *goGenerator) function(fn *ast.FunctionDeclaration) error {
+	params := make([]string, len(fn.Parameters))
+	for i, param := range fn.Parameters {
+		paramType, err := g.goType(param.Type)
+		if err != nil {
+			return err
+		}
+		if paramType == "" {
+			return unsupported("parameter %s without type", param.Name)
+		}
+		params[i] = "b_" + param.Name + " " + paramType
+	}
+
+	returnType, err := g.goType(fn.ReturnType)
+	if err != nil {
+		return err
+	}
+	if returnType != "" {
+		returnType = " " + returnType
+	}
+
+	g.line("func b_%s(%s)%s {", fn.Name, strings.Join(params, ", "), returnType)
+	g.indent++
+	for _, stmt := range fn.Body {
+		if err := g.statement(stmt); err != nil {
+			return err
+		}
+	}
+	if returnType != "" {
+		g.line("panic(burnError(%q))", "function "+fn.Name+" did not return a value")
+	}
+	g.indent--
+	g.line("}")
+	g.line("")
+	return nil
+}
+
+func (g *goGenerator) block(stmts []ast.Declaration) error {
+	g.indent++
+	defer func() { g.indent-- }()
+
+	for _, stmt := range stmts {
+		if err := g.statement(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *goGenerator) statement(decl ast.Declaration) error {
+	switch d := decl.(type) {
+	case *ast.VariableDeclaration:
+		varType, err := g.goType(d.Type)
+		if err != nil {
+			return err
+		}
+		if varType == "" {
+			return unsupported("variable %s without type", d.Name)
+		}
+		if d.Value == nil {
+			g.line("var b_%s %s", d.Name, varType)
+		} else {
+			value, err := g.expression(d.Value)
+			if err != nil {
+				return err
+			}
+			g.line("var b_%s %s = %s", d.Name, varType, value)
+		}
+		g.line("_ = b_%s", d.Name)
+		return nil
+	case *ast.ExpressionStatement:
+		stmt, err := g.simpleStatement(d.Expression)
+		if err != nil {
+			return err
+		}
+		g.line("%s", stmt)
+		return nil
+	case *ast.ReturnStatement:
+		if d.Value == nil {
+			g.line("return")
+			return nil
+		}
+		value, err := g.expression(d.Value)
+		if err != nil {
+			return err
+		}
+		g.line("return %s", value)
+		return nil
+	case *ast.IfStatement:
+		cond, err := g.expression(d.Condition)
+		if err != nil {
+			return err
+		}
+		g.line("if %s {", cond)
+		if err := g.block(d.ThenBranch); err != nil {
+			return err
+		}
+		if len(d.ElseBranch) > 0 {
+			g.line("} else {")
+			if err := g.block(d.ElseBranch); err != nil {
+				return err
+			}
+		}
+		g.line("}")
+		return nil
+	case *ast.WhileStatement:
+		cond, err := g.expression(d.Condition)
+		if err != nil {
+			return err
+		}
+		return g.labeled(d.Label, func() error {
+			g.line("for %s {", cond)
+			if err := g.block(d.Body); err != nil {
+				return err
+			}
+			g.line("}")
+			return nil
+		})
+	case *ast.ForStatement:
+		return g.labeled(d.Label, func() error {
+			return g.forStatement(d)
+		})
+	case *ast.BreakStatement:
+		g.line("%s", g.loopControl("break", d.Label))
+		return nil
+	case *ast.ContinueStatement:
+		g.line("%s", g.loopControl("continue", d.Label))
+		return nil
+	case *ast.BlockStatement:
+		g.line("{")
+		if err := g.block(d.Statements); err != nil {
+			return err
+		}
+		g.line("}")
+		return nil
+	default:
+		return unsupported("%T", decl)
+	}
+}
+
+// labeled generates a loop with a label, or none. Go rejects labels no
+// break or continue uses, so the label is put before the loop once its
+// body has been generated and only if it is used.
+func (g *goGenerator) labeled(label string, loop func() error) error {
+	start := g.out.Len()
+	if err := loop(); err != nil {
+		return err
+	}
+	if label == "" || !g.usedLabels[label] {
+		return nil
+	}
+	delete(g.usedLabels, label)
+
+	generated := g.out.String()
+	g.out.Reset()
+	g.out.WriteString(generated[:start])
+	g.line("L_%s:", label)
+	g.out.WriteString(generated[start:])
+	return nil
+}
+
+// loopControl renders a break or continue, given by keyword, of the loop
+// with the label, or of the innermost loop without one
+func (g *goGenerator) loopControl(keyword, label string) string {
+	if label == "" {
+		return keyword
+	}
+	g.usedLabels[label] = true
+	return keyword + " L_" + label
+}
+
+func (g *goGenerator) forStatement(stmt *ast.ForStatement) error {
+	init := ""
+	switch d := stmt.Initializer.(type) {
+	case nil:
+	case *ast.VariableDeclaration:
+		if d.Value == nil {
+			return unsupported("for initializer without value")
+		}
+		value, err := g.expression(d.Value)
+		if err != nil {
+			return err
+		}
+		init = "b_" + d.Name + " := " + value
+	case *ast.ExpressionStatement:
+		s, err := g.simpleStatement(d.Expression)
+		if err != nil {
+			return err
+		}
+		init = s
+	default:
+		return unsupported("for initializer %T", d)
+	}
+
+	cond := ""
+	if stmt.Condition != nil {
+		c, err := g.expression(stmt.Condition)
+		if err != nil {
+			return err
+		}
+		cond = c
+	}
+
+	post := ""
+	if stmt.Increment != nil {
+		p, err := g.simpleStatement(stmt.Increment)
+		if err != nil {
+			return err
+		}
+		post = p
+	}
+
+	g.line("for %s; %s; %s {", init, cond, post)
+	if err := g.block(stmt.Body); err != nil {
+		return err
+	}
+	g.line("}")
+	return nil
+}
+
+// simpleStatement renders an expression in statement position, where Burn
+// assignments become Go assignment statements.
+func (g *goGenerator) simpleStatement(expr ast.Expression) (string, error) {
+	switch e := expr.(type) {
+	case *ast.AssignmentExpression:
+		value, err := g.expression(e.Value)
+		if err != nil {
+			return "", err
+		}
+		return "b_" + e.Name + " = " + value, nil
+	case *ast.SetExpression:
+		object, err := g.expression(e.Object)
+		if err != nil {
+			return "", err
+		}
+		value, err := g.expression(e.Value)
+		if err != nil {
+			return "", err
+		}
+		return object + ".F_" + e.Name + " = " + value, nil
+	case *ast.CallExpression:
+		return g.expression(e)
+	default:
+		value, err := g.expression(expr)
+		if err != nil {
+			return "", err
+		}
+		return "_ = " + value, nil
+	}
+}
+
+func (g *goGenerator) expression(expr ast.Expression) (string, error) {
+	switch e := expr.(type) {
+	case *ast.LiteralExpression:
+		switch e.Type {
+		case "number":
+			if text := fmt.Sprint(e.Value); lexer.IsBigInt(text) || lexer.IsDecimal(text) {
+				return "", unsupported("literal %s", text)
+			}
+			value, err := lexer.ParseNumber(fmt.Sprint(e.Value))
+			if err != nil {
+				return "", err
+			}
+			return "float64(" + strconv.FormatFloat(value, 'g', -1, 64) + ")", nil
+		case "string":
+			return strconv.Quote(fmt.Sprint(e.Value)), nil
+		case "bool":
+			return fmt.Sprint(e.Value), nil
+		}
+		return "", unsupported("literal of type %s", e.Type)
+	case *ast.VariableExpression:
+		return "b_" + e.Name, nil
+	case *ast.GroupingExpression:
+		inner, err := g.expression(e.Expression)
+		if err != nil {
+			return "", err
+		}
+		return "(" + inner + ")", nil
+	case *ast.UnaryExpression:
+		right, err := g.expression(e.Right)
+		if err != nil {
+			return "", err
+		}
+		return "(" + e.Operator + right + ")", nil
+	case *ast.BinaryExpression:
+		left, err := g.expression(e.Left)
+		if err != nil {
+			return "", err
+		}
+		right, err := g.expression(e.Right)
+		if err != nil {
+			return "", err
+		}
+		switch e.Operator {
+		case "/":
+			if e.IntDivision {
+				return "math.Trunc(burnDiv(" + left + ", " + right + "))", nil
+			}
+			return "burnDiv(" + left + ", " + right + ")", nil
+		case "%":
+			return "burnMod(" + left + ", " + right + ")", nil
+		case "+", "-", "*", "<", ">", "<=", ">=", "==", "!=", "&&", "||":
+			return "(" + left + " " + e.Operator + " " + right + ")", nil
+		}
+		return "", unsupported("operator %s", e.Operator)
+	case *ast.CallExpression:
+		return g.call(e)
+	case *ast.GetExpression:
+		object, err := g.expression(e.Object)
+		if err != nil {
+			return "", err
+		}
+		return object + ".F_" + e.Name, nil
+	case *ast.StructLiteralExpression:
+		if _, exists := g.types[e.Type]; !exists {
+			return "", unsupported("struct literal of unknown type %q", e.Type)
+		}
+		names := make([]string, 0, len(e.Fields))
+		for name := range e.Fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fields := make([]string, len(names))
+		for i, name := range names {
+			value, err := g.expression(e.Fields[name])
+			if err != nil {
+				return "", err
+			}
+			fields[i] = "F_" + name + ": " + value
+		}
+		return "&T_" + e.Type + "{" + strings.Join(fields, ", ") + "}", nil
+	default:
+		return "", unsupported("%T", expr)
+	}
+}
+
+var goBuiltins = map[string]string{
+	"print":    "burnPrint",
+	"toString": "burnToString",
+	"toInt":    "burnToInt",
+	"toFloat":  "burnToFloat",
+	"len":      "burnLen",
+	"input":    "burnInput",
+}
+
+func (g *goGenerator) call(expr *ast.CallExpression) (string, error) {
+	callee, ok := expr.Callee.(*ast.VariableExpression)
+	if !ok {
+		return "", unsupported("method call")
+	}
+
+	args := make([]string, len(expr.Arguments))
+	for i, arg := range expr.Arguments {
+		value, err := g.expression(arg)
+		if err != nil {
+			return "", err
+		}
+		args[i] = value
+	}
+
+	if _, exists := g.functions[callee.Name]; exists {
+		return "b_" + callee.Name + "(" + strings.Join(args, ", ") + ")", nil
+	}
+	if builtin, exists := goBuiltins[callee.Name]; exists {
+		return builtin + "(" + strings.Join(args, ", ") + ")", nil
+	}
+
+	return "", unsupported("call to %s", callee.Name)
+}
+
+const goRuntime = `import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type burnError string
+
+func burnRecover() {
+	if r := recover(); r != nil {
+		if err, ok := r.(burnError); ok {
+			fmt.Fprintf(os.Stderr, "Runtime error: %s\n", string(err))
+			os.Exit(2)
+		}
+		panic(r)
+	}
+}
+
+func burnPrint(args ...interface{}) {
+	for _, arg := range args {
+		fmt.Println(arg)
+	}
+}
+
+func burnToString(v interface{}) string {
+	switch val := v.(type) {
+	case float64:
+		if val == math.Trunc(val) {
+			return fmt.Sprintf("%.0f", val)
+		}
+		return fmt.Sprintf("%g", val)
+	case string:
+		return val
+	case bool:
+		return fmt.Sprintf("%t", val)
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+func burnToInt(v interface{}) float64 {
+	switch val := v.(type) {
+	case float64:
+		return float64(int(val))
+	case string:
+		i, err := strconv.Atoi(val)
+		if err != nil {
+			panic(burnError(fmt.Sprintf("cannot convert string to int: %v", err)))
+		}
+		return float64(i)
+	}
+	panic(burnError(fmt.Sprintf("cannot convert %T to int", v)))
+}
+
+func burnToFloat(v interface{}) float64 {
+	switch val := v.(type) {
+	case float64:
+		return val
+	case string:
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			panic(burnError(fmt.Sprintf("cannot convert string to float: %v", err)))
+		}
+		return f
+	}
+	panic(burnError(fmt.Sprintf("cannot convert %T to float", v)))
+}
+
+func burnLen(v interface{}) float64 {
+	if s, ok := v.(string); ok {
+		return float64(len([]rune(s)))
+	}
+	panic(burnError(fmt.Sprintf("len expects string or array, got %T", v)))
+}
+
+func burnInput(prompt string) string {
+	fmt.Print(prompt)
+	text, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.TrimSpace(text)
+}
+
+func burnDiv(a, b float64) float64 {
+	if b == 0 {
+		panic(burnError("division by zero"))
+	}
+	return a / b
+}
+
+func burnMod(a, b float64) float64 {
+	if b == 0 {
+		panic(burnError("modulo by zero"))
+	}
+	return math.Mod(a, b)
+}
+
+`
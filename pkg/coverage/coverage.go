@@ -0,0 +1,339 @@
+package coverage
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/lexer"
+	"github.com/burnlang/burn/pkg/parser"
+)
+
+// Collector records which statements and if/else branches of a set of
+// source files were executed. It implements interpreter.StatementHook.
+type Collector struct {
+	// Include decides whether a file seen during execution but never added
+	// with AddFile should be tracked. Nil means only added files are tracked.
+	Include func(path string) bool
+
+	files map[string]*File
+	order []string
+	keys  map[string]string
+}
+
+// File holds the coverage counters of one source file
+type File struct {
+	Path       string
+	source     string
+	statements map[int]*counter
+	branches   map[int]*branch
+}
+
+type counter struct {
+	line int
+	hits int
+}
+
+type branch struct {
+	line  int
+	then  int
+	other int
+}
+
+// Summary is the aggregated coverage of a file
+type Summary struct {
+	Path              string
+	Statements        int
+	CoveredStatements int
+	Branches          int
+	CoveredBranches   int
+}
+
+func New() *Collector {
+	return &Collector{files: make(map[string]*File), keys: make(map[string]string)}
+}
+
+// AddFile registers the statements of a source file so that unexecuted
+// code is reported too. Adding the same file twice is a no-op.
+func (c *Collector) AddFile(path string) error {
+	key := c.key(path)
+	if _, exists := c.files[key]; exists {
+		return nil
+	}
+
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	tokens, err := lexer.New(string(source)).Tokenize()
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	program, err := parser.New(tokens).Parse()
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	file := &File{
+		Path:       path,
+		source:     string(source),
+		statements: make(map[int]*counter),
+		branches:   make(map[int]*branch),
+	}
+	file.collect(program.Declarations)
+
+	c.files[key] = file
+	c.order = append(c.order, key)
+	return nil
+}
+
+func (f *File) collect(decls []ast.Declaration) {
+	for _, decl := range decls {
+		switch d := decl.(type) {
+		case *ast.FunctionDeclaration:
+			f.collect(d.Body)
+		case *ast.ClassDeclaration:
+			for _, method := range d.Methods {
+				f.collect(method.Body)
+			}
+			for _, method := range d.StaticMethods {
+				f.collect(method.Body)
+			}
+		case *ast.VariableDeclaration, *ast.ExpressionStatement, *ast.ReturnStatement,
+			*ast.BreakStatement, *ast.ContinueStatement:
+			f.addStatement(d)
+		case *ast.IfStatement:
+			f.addStatement(d)
+			f.branches[d.Position] = &branch{line: d.Line}
+			f.collect(d.ThenBranch)
+			f.collect(d.ElseBranch)
+		case *ast.WhileStatement:
+			f.addStatement(d)
+			f.collect(d.Body)
+		case *ast.ForStatement:
+			f.addStatement(d)
+			f.collect(d.Body)
+		case *ast.ForInStatement:
+			f.addStatement(d)
+			f.collect(d.Body)
+		case *ast.MatchStatement:
+			f.addStatement(d)
+			for _, matchCase := range d.Cases {
+				f.collect(matchCase.Body)
+			}
+		case *ast.BlockStatement:
+			f.collect(d.Statements)
+		}
+	}
+}
+
+func (f *File) addStatement(stmt ast.Declaration) {
+	f.statements[stmt.Pos()] = &counter{line: stmt.Loc().Line}
+}
+
+// lookup returns the tracked file for a path reported by the interpreter
+func (c *Collector) lookup(path string) *File {
+	if path == "" {
+		return nil
+	}
+	key := c.key(path)
+	if f, ok := c.files[key]; ok {
+		return f
+	}
+	if c.Include != nil && c.Include(path) {
+		if err := c.AddFile(path); err == nil {
+			return c.files[key]
+		}
+	}
+	return nil
+}
+
+func (c *Collector) key(path string) string {
+	if key, ok := c.keys[path]; ok {
+		return key
+	}
+	key := filepath.Clean(path)
+	if abs, err := filepath.Abs(path); err == nil {
+		key = abs
+	}
+	c.keys[path] = key
+	return key
+}
+
+func (c *Collector) ExecuteStatement(file string, stmt ast.Declaration) {
+	if f := c.lookup(file); f != nil {
+		if counter, ok := f.statements[stmt.Pos()]; ok {
+			counter.hits++
+		}
+	}
+}
+
+func (c *Collector) TakeBranch(file string, stmt *ast.IfStatement, taken bool) {
+	if f := c.lookup(file); f != nil {
+		if b, ok := f.branches[stmt.Position]; ok {
+			if taken {
+				b.then++
+			} else {
+				b.other++
+			}
+		}
+	}
+}
+
+// Files returns the tracked files in the order they were added
+func (c *Collector) Files() []*File {
+	files := make([]*File, 0, len(c.order))
+	for _, key := range c.order {
+		files = append(files, c.files[key])
+	}
+	return files
+}
+
+func (f *File) Summary() Summary {
+	s := Summary{Path: f.Path, Statements: len(f.statements), Branches: 2 * len(f.branches)}
+	for _, counter := range f.statements {
+		if counter.hits > 0 {
+			s.CoveredStatements++
+		}
+	}
+	for _, b := range f.branches {
+		if b.then > 0 {
+			s.CoveredBranches++
+		}
+		if b.other > 0 {
+			s.CoveredBranches++
+		}
+	}
+	return s
+}
+
+// lineHits returns the execution count of every line holding a statement
+func (f *File) lineHits() map[int]int {
+	hits := make(map[int]int)
+	for _, counter := range f.statements {
+		if current, ok := hits[counter.line]; !ok || counter.hits > current {
+			hits[counter.line] = counter.hits
+		}
+	}
+	return hits
+}
+
+func (f *File) sortedBranches() []*branch {
+	branches := make([]*branch, 0, len(f.branches))
+	for _, b := range f.branches {
+		branches = append(branches, b)
+	}
+	sort.Slice(branches, func(a, b int) bool { return branches[a].line < branches[b].line })
+	return branches
+}
+
+// WriteSummary prints statement and branch coverage per file and in total
+func (c *Collector) WriteSummary(w io.Writer) {
+	var total Summary
+	total.Path = "total"
+
+	fmt.Fprintf(w, "%-40s %12s %12s\n", "file", "statements", "branches")
+	for _, f := range c.Files() {
+		s := f.Summary()
+		writeSummaryLine(w, s)
+		total.Statements += s.Statements
+		total.CoveredStatements += s.CoveredStatements
+		total.Branches += s.Branches
+		total.CoveredBranches += s.CoveredBranches
+	}
+	writeSummaryLine(w, total)
+}
+
+func writeSummaryLine(w io.Writer, s Summary) {
+	fmt.Fprintf(w, "%-40s %12s %12s\n", s.Path,
+		percent(s.CoveredStatements, s.Statements), percent(s.CoveredBranches, s.Branches))
+}
+
+func percent(covered, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(covered)/float64(total))
+}
+
+// WriteAnnotated prints each file with its per-line execution counts.
+// Lines that hold statements which never ran are marked with "#####".
+func (c *Collector) WriteAnnotated(w io.Writer) {
+	for _, f := range c.Files() {
+		fmt.Fprintf(w, "--- %s ---\n", f.Path)
+		hits := f.lineHits()
+		for n, text := range strings.Split(strings.TrimSuffix(f.source, "\n"), "\n") {
+			count, ok := hits[n+1]
+			switch {
+			case !ok:
+				fmt.Fprintf(w, "%8s | %4d | %s\n", "", n+1, text)
+			case count == 0:
+				fmt.Fprintf(w, "%8s | %4d | %s\n", "#####", n+1, text)
+			default:
+				fmt.Fprintf(w, "%8d | %4d | %s\n", count, n+1, text)
+			}
+		}
+	}
+}
+
+// WriteLCOV writes the coverage data in LCOV tracefile format
+func (c *Collector) WriteLCOV(w io.Writer) error {
+	for _, f := range c.Files() {
+		path, err := filepath.Abs(f.Path)
+		if err != nil {
+			path = f.Path
+		}
+
+		fmt.Fprintln(w, "TN:")
+		fmt.Fprintf(w, "SF:%s\n", path)
+
+		hits := f.lineHits()
+		lines := make([]int, 0, len(hits))
+		for line := range hits {
+			lines = append(lines, line)
+		}
+		sort.Ints(lines)
+
+		branchesHit := 0
+		for id, b := range f.sortedBranches() {
+			fmt.Fprintf(w, "BRDA:%d,%d,0,%s\n", b.line, id, b.count(b.then))
+			fmt.Fprintf(w, "BRDA:%d,%d,1,%s\n", b.line, id, b.count(b.other))
+			if b.then > 0 {
+				branchesHit++
+			}
+			if b.other > 0 {
+				branchesHit++
+			}
+		}
+		fmt.Fprintf(w, "BRF:%d\n", 2*len(f.branches))
+		fmt.Fprintf(w, "BRH:%d\n", branchesHit)
+
+		linesHit := 0
+		for _, line := range lines {
+			fmt.Fprintf(w, "DA:%d,%d\n", line, hits[line])
+			if hits[line] > 0 {
+				linesHit++
+			}
+		}
+		fmt.Fprintf(w, "LF:%d\n", len(lines))
+		fmt.Fprintf(w, "LH:%d\n", linesHit)
+		if _, err := fmt.Fprintln(w, "end_of_record"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// count formats a branch counter for LCOV, where "-" means the condition
+// itself was never evaluated
+func (b *branch) count(n int) string {
+	if b.then+b.other == 0 {
+		return "-"
+	}
+	return fmt.Sprint(n)
+}
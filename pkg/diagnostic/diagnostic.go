@@ -0,0 +1,135 @@
+// Package diagnostic renders the errors and warnings of Burn programs for
+// terminals: a headline, the source line with the offending span
+// underlined, and notes and suggestions, in color when the terminal
+// supports it
+package diagnostic
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Severity tells whether a Diagnostic stops a program from running
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+)
+
+func (s Severity) String() string {
+	if s == Warning {
+		return "Warning"
+	}
+	return "Error"
+}
+
+// Diagnostic is an error or warning to show. Line and Column are 1-based
+// and zero when the location is unknown, in which case no source line is
+// shown.
+type Diagnostic struct {
+	Severity Severity
+
+	// Code is the error code, such as BRN0301, or ""
+	Code string
+
+	// Message is the headline, such as "Type error at line 3, column 11:
+	// undefined variable: total"
+	Message string
+
+	File   string
+	Source string
+	Line   int
+	Column int
+
+	// Notes add context, and Help suggests a fix, such as "did you mean
+	// total?"
+	Notes []string
+	Help  string
+}
+
+const (
+	colorReset  = "\033[0m"
+	colorBold   = "\033[1m"
+	colorRed    = "\033[1;31m"
+	colorYellow = "\033[1;33m"
+	colorBlue   = "\033[1;34m"
+	colorCyan   = "\033[1;36m"
+)
+
+// Renderer writes diagnostics, using ANSI colors when Color is set
+type Renderer struct {
+	Color bool
+}
+
+func (r Renderer) paint(code, text string) string {
+	if !r.Color || text == "" {
+		return text
+	}
+	return code + text + colorReset
+}
+
+// Render writes d to w: the severity and code, the message, then the file
+// name and the source line with the offending span underlined and in bold,
+// and last the notes and the help
+func (r Renderer) Render(w io.Writer, d Diagnostic) {
+	color := colorRed
+	if d.Severity == Warning {
+		color = colorYellow
+	}
+	label := d.Severity.String()
+	if d.Code != "" {
+		label += "[" + d.Code + "]"
+	}
+	fmt.Fprintf(w, "%s %s\n", r.paint(color, label+":"), r.paint(colorBold, d.Message))
+
+	gutter := " "
+	if d.Line > 0 {
+		text := sourceLine(d.Source, d.Line)
+		gutter = strings.Repeat(" ", len(fmt.Sprint(d.Line)))
+		name := d.File
+		if name == "" {
+			name = "<eval>"
+		}
+		start := d.Column - 1
+		end := min(start+spanLength(text, d.Column), len(text))
+		if start < 0 || start > len(text) {
+			start, end = len(text), len(text)
+		}
+
+		fmt.Fprintf(w, "%s%s %s:%d:%d\n", gutter, r.paint(colorBlue, "-->"), name, d.Line, d.Column)
+		fmt.Fprintf(w, "%s %s\n", gutter, r.paint(colorBlue, "|"))
+		fmt.Fprintf(w, "%s %s %s%s%s\n", r.paint(colorBlue, fmt.Sprint(d.Line)), r.paint(colorBlue, "|"),
+			text[:start], r.paint(colorBold, text[start:end]), text[end:])
+		fmt.Fprintf(w, "%s %s %s%s\n", gutter, r.paint(colorBlue, "|"),
+			caretPadding(text, d.Column), r.paint(color, underline(spanLength(text, d.Column))))
+	} else if d.File != "" {
+		fmt.Fprintf(w, "  %s %s\n", r.paint(colorBlue, "-->"), d.File)
+	}
+
+	for _, note := range d.Notes {
+		fmt.Fprintf(w, "%s %s %s\n", gutter, r.paint(colorBlue, "="), r.paint(colorBold, "note:")+" "+note)
+	}
+	if d.Help != "" {
+		fmt.Fprintf(w, "%s %s %s\n", gutter, r.paint(colorBlue, "="), r.paint(colorCyan, "help:")+" "+d.Help)
+	}
+}
+
+// ColorEnabled reports whether output to w should use ANSI colors: only for
+// terminals, and never with noColor, NO_COLOR set or TERM=dumb
+func ColorEnabled(w io.Writer, noColor bool) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,81 @@
+package diagnostic
+
+import "strings"
+
+// underline returns a caret under the first character of a span of length
+// n followed by tildes under the rest, as in ^~~~
+func underline(n int) string {
+	return "^" + strings.Repeat("~", n-1)
+}
+
+// sourceLine returns the text of a 1-based line of source
+func sourceLine(source string, line int) string {
+	lines := strings.Split(source, "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return strings.TrimRight(lines[line-1], "\r")
+}
+
+// caretPadding returns the whitespace that aligns a caret under column col of
+// text, keeping tabs so that the caret lines up however tabs are rendered
+func caretPadding(text string, col int) string {
+	var pad strings.Builder
+	for i := 0; i < col-1; i++ {
+		if i < len(text) && text[i] == '\t' {
+			pad.WriteByte('\t')
+		} else {
+			pad.WriteByte(' ')
+		}
+	}
+	return pad.String()
+}
+
+// spanLength returns how many characters to underline at column col: the
+// whole identifier, number, string or comparison or logical operator
+// starting there, or a single character
+func spanLength(text string, col int) int {
+	start := col - 1
+	if start < 0 || start >= len(text) {
+		return 1
+	}
+
+	end := start
+	switch c := text[start]; {
+	case c == '"':
+		end++
+		for end < len(text) && text[end] != '"' {
+			if text[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		end++
+		if end > len(text) {
+			end = len(text)
+		}
+	case c >= '0' && c <= '9':
+		for end < len(text) && (isWordChar(text[end]) || text[end] == '.') {
+			end++
+		}
+	case isWordChar(c):
+		for end < len(text) && isWordChar(text[end]) {
+			end++
+		}
+	case strings.IndexByte(operatorChars, c) >= 0:
+		for end < len(text) && strings.IndexByte(operatorChars, text[end]) >= 0 {
+			end++
+		}
+	default:
+		end++
+	}
+	return end - start
+}
+
+// operatorChars make up the operators underlined as a whole, such as ==,
+// <= and ??
+const operatorChars = "=!<>&|?"
+
+func isWordChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
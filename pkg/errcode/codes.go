@@ -0,0 +1,824 @@
+package errcode
+
+const (
+	lexical = "Lexical error"
+	parse   = "Parse error"
+	imports = "Import error"
+	types   = "Type error"
+	runtime = "Runtime error"
+)
+
+// codes lists the codes of every stage. Each error gets its code where it
+// is made, with Errorf or New. Codes are never reused: a code that is no
+// longer needed is left out, and a new kind of error gets the next free
+// number of its stage.
+var codes = []Code{
+	{
+		ID: "BRN0101", Kind: lexical, Title: "Unexpected character",
+		Explanation: `The source contains a character that does not start any token of Burn,
+such as a single & or | or a character outside of strings and comments.
+
+    var ok = a & b      // error: '&' on its own is not an operator
+
+Use the operator that was meant, && or ||, or put the text in a string.
+
+    var ok = a && b`,
+	},
+	{
+		ID: "BRN0102", Kind: lexical, Title: "Unterminated string",
+		Explanation: `A string literal is not closed by a " before the end of the file.
+
+    print("hello)
+
+Close the string. A " inside a string is written \".
+
+    print("hello")
+    print("she said \"hi\"")`,
+	},
+	{
+		ID: "BRN0103", Kind: lexical, Title: "Invalid number",
+		Explanation: `A number literal is malformed: a digit is not valid for its base, a
+prefix such as 0x has no digits after it, or an underscore does not sit
+between two digits.
+
+    var mask = 0b102    // 2 is not a binary digit
+    var big = 1__000    // '_' must separate successive digits
+    var hex = 0x        // no digits
+
+Write the digits of the base, with at most one '_' between two of them.
+
+    var mask = 0b101
+    var big = 1_000
+    var hex = 0xff`,
+	},
+
+	{
+		ID: "BRN0202", Kind: parse, Title: "Invalid assignment target",
+		Explanation: `The left side of = is not something a value can be stored in. Only
+variables, fields and elements of arrays and maps can be assigned to.
+
+    getCount() = 3
+    user?.name = "Ann"  // cannot assign through ?.
+
+Assign to a variable, field or element, checking optional values against
+nil first.
+
+    count = 3
+    if (user != nil) {
+        user.name = "Ann"
+    }`,
+	},
+	{
+		ID: "BRN0203", Kind: parse, Title: "Declaration in the wrong place",
+		Explanation: `Some declarations are only allowed at the top level of a file: methods
+declared on a type, functions with type parameters and private functions.
+
+    fun main() {
+        fun (p: Point) norm(): float { ... }    // not at the top level
+    }
+
+Move the declaration out of the function.
+
+    fun (p: Point) norm(): float { ... }
+
+    fun main() { ... }`,
+	},
+	{
+		ID: "BRN0204", Kind: parse, Title: "Missing initializer",
+		Explanation: `Constants and static fields of classes must be given a value where they
+are declared.
+
+    const limit: int
+    class Counter {
+        static var count: int
+    }
+
+Give them a value.
+
+    const limit: int = 10
+    class Counter {
+        static var count: int = 0
+    }`,
+	},
+	{
+		ID: "BRN0205", Kind: parse, Title: "Variadic parameter is not the last",
+		Explanation: `A variadic parameter, which takes any number of arguments, must be the
+last parameter of a function or function type.
+
+    fun log(parts: ...string, level: int) { ... }
+
+Move it to the end.
+
+    fun log(level: int, parts: ...string) { ... }`,
+	},
+	{
+		ID: "BRN0206", Kind: parse, Title: "Invalid match pattern",
+		Explanation: `A case of a match has a pattern that is not a literal, a type or _, or
+combines _ with other patterns.
+
+    match value {
+        x + 1 => print("one more"),
+        1, _ => print("other"),
+    }
+
+Use literals or types as patterns, and _ on its own for every other value.
+
+    match value {
+        1, 2 => print("small"),
+        _ => print("other"),
+    }`,
+	},
+	{
+		ID: "BRN0207", Kind: parse, Title: "Invalid declaration",
+		Explanation: `A declaration is well formed but not allowed: a generic function names
+the same type parameter twice, a type is written as void?, or a function
+of an interface has a body.
+
+    fun pair<T, T>(a: T, b: T): [T] { ... }
+    interface Shape {
+        fun area(): float { return 0.0 }
+    }
+
+Give type parameters distinct names and leave the bodies of interface
+functions to the classes that implement them.
+
+    fun pair<T>(a: T, b: T): [T] { ... }
+    interface Shape {
+        fun area(): float
+    }`,
+	},
+	{
+		ID: "BRN0201", Kind: parse, Title: "Unexpected token",
+		Explanation: `The parser expected something else at this point of the program, such
+as a closing bracket, a name or an expression. The error says what was
+expected, and the underlined token is what was found instead. Often a
+bracket or comma is missing just before it.
+
+    var items = [1, 2
+    print(items)
+
+Add what is missing.
+
+    var items = [1, 2]
+    print(items)`,
+	},
+
+	{
+		ID: "BRN0401", Kind: imports, Title: "Import not found",
+		Explanation: `An imported file does not exist in any of the places imports are looked
+up: the directory of the importing file, the current directory, the
+import paths of the configuration and burn_modules/.
+
+    import "utils.bn"
+
+Check the path, add the directory to import_paths in .burn.toml, or
+install the dependency with burn install.`,
+	},
+	{
+		ID: "BRN0402", Kind: imports, Title: "Error in an imported file",
+		Explanation: `An imported file has a lexical or parse error of its own. The error names
+the file and the problem in it.
+
+Run the imported file on its own, as in burn utils.bn, to see the error
+with its source line, and fix it there.`,
+	},
+
+	{
+		ID: "BRN0301", Kind: types, Title: "Undefined variable",
+		Explanation: `A variable is used that is not declared in scope. Variables are only
+visible after their declaration, inside the block that declares them.
+
+    fun main() {
+        if (true) {
+            var total = 1
+        }
+        print(total)
+    }
+
+Declare the variable in a scope that includes its uses, or fix the
+spelling; a close name in scope is suggested.
+
+    fun main() {
+        var total = 0
+        if (true) {
+            total = 1
+        }
+        print(total)
+    }`,
+	},
+	{
+		ID: "BRN0302", Kind: types, Title: "Undefined function or class",
+		Explanation: `A function or class is called that is neither declared in the program or
+its imports nor a builtin.
+
+    import "math.bn"
+    print(squareRoot(2.0))
+
+Fix the spelling, or import the file that declares it.`,
+	},
+	{
+		ID: "BRN0325", Kind: types, Title: "Match pattern cannot match",
+		Explanation: `A pattern of a match can never match the value: its type differs from
+the type of the value, or it names an unknown type. The default case _
+must also be the last.
+
+    var n: int = 3
+    match n {
+        "three" => print(3),
+        _ => print("other"),
+    }
+
+Use patterns of the type of the value.
+
+    match n {
+        3 => print("three"),
+        _ => print("other"),
+    }`,
+	},
+	{
+		ID: "BRN0303", Kind: types, Title: "Unknown type",
+		Explanation: `A type is named that is not a builtin type and not declared with type,
+class or interface.
+
+    fun origin(): Pont {
+        return {x: 0, y: 0}
+    }
+
+Fix the spelling, or declare or import the type.
+
+    type Point {
+        x: int,
+        y: int
+    }
+    fun origin(): Point {
+        return {x: 0, y: 0}
+    }`,
+	},
+	{
+		ID: "BRN0304", Kind: types, Title: "Unknown field",
+		Explanation: `A field is used that the type does not declare, or a field is used on a
+value that has no fields, such as a number.
+
+    type Point {
+        x: int,
+        y: int
+    }
+    var p: Point = {x: 1, y: 2}
+    print(p.z)
+
+Use a field of the type.`,
+	},
+	{
+		ID: "BRN0305", Kind: types, Title: "Undefined method",
+		Explanation: `A method is called that the type or class does not have, or a static
+method is called on an instance instead of the class.
+
+    class Counter {
+        var count: int
+
+        fun create(): Counter { return Counter() }
+    }
+    var c = Counter.create()
+    c.create()
+
+Call the methods the type declares, and static methods on the class.
+
+    var c = Counter.create()`,
+	},
+	{
+		ID: "BRN0317", Kind: types, Title: "Interface not implemented",
+		Explanation: `A class says it implements an interface but lacks one of its methods,
+has it with other parameter or return types, or makes it private.
+
+    interface Shape {
+        fun area(): float
+    }
+    class Square implements Shape {
+        var side: float
+    }
+
+Give the class every method of the interface, public and taking an
+instance of the class before the same parameters.
+
+    class Square implements Shape {
+        var side: float
+
+        fun area(square: Square): float { return this.side * this.side }
+    }`,
+	},
+	{
+		ID: "BRN0318", Kind: types, Title: "Private member",
+		Explanation: `A private field, method or function is used outside of the class or file
+that declares it.
+
+    class Account {
+        private var balance: float
+    }
+    var a = Account()
+    print(a.balance)
+
+Use a public method of the class, or make the member public.`,
+	},
+	{
+		ID: "BRN0313", Kind: types, Title: "Assignment to a constant",
+		Explanation: `A constant is assigned to after its declaration. In strict mode, the
+fields of a struct held by a constant cannot change either.
+
+    const limit = 10
+    limit = 20
+
+Declare it with var if it must change.
+
+    var limit = 10
+    limit = 20`,
+	},
+	{
+		ID: "BRN0306", Kind: types, Title: "Mismatched types",
+		Explanation: `A value is stored where a value of another type is expected: in a
+variable, field, array or map of a declared type, or next to elements of
+another type in an array or map literal.
+
+    var count: int = "three"
+    var mixed = [1, "two"]
+
+Store a value of the declared type, converting it if needed.
+
+    var count: int = toInt("3")
+    var mixed: [any] = [1, "two"]`,
+	},
+	{
+		ID: "BRN0307", Kind: types, Title: "Wrong argument type",
+		Explanation: `An argument of a call has another type than the parameter it is given
+for.
+
+    fun double(n: int): int { return n * 2 }
+    double("4")
+
+Pass a value of the parameter type.
+
+    double(toInt("4"))`,
+	},
+	{
+		ID: "BRN0308", Kind: types, Title: "Wrong number of arguments",
+		Explanation: `A function, method or constructor is called with more or fewer arguments
+than it has parameters.
+
+    fun add(a: int, b: int): int { return a + b }
+    add(1)
+
+Pass one argument for each parameter.
+
+    add(1, 2)`,
+	},
+	{
+		ID: "BRN0315", Kind: types, Title: "Value may be nil",
+		Explanation: `A value of an optional type, such as string?, is used as if it could not
+be nil, or ?? or a nil check is used on a value that can never be nil.
+
+    fun find(name: string): User? { ... }
+    print(find("ann").name)
+
+Check the value against nil first, or give a fallback with ??.
+
+    var user = find("ann")
+    if (user != nil) {
+        print(user.name)
+    }`,
+	},
+	{
+		ID: "BRN0309", Kind: types, Title: "Invalid operands",
+		Explanation: `An operator is applied to values of types it does not work on, such as
+a number and a string, or && on numbers. In strict mode int and float
+cannot be mixed either.
+
+    var label = "count: " + 3
+
+Convert one of the values first.
+
+    var label = "count: " + toString(3)`,
+	},
+	{
+		ID: "BRN0310", Kind: types, Title: "Condition is not a bool",
+		Explanation: `The condition of an if, while, for or ?: is not a bool. Numbers, strings
+and nil are not true or false in Burn.
+
+    var items = [1, 2]
+    if (len(items)) {
+        print("not empty")
+    }
+
+Compare the value to get a bool.
+
+    if (len(items) > 0) {
+        print("not empty")
+    }`,
+	},
+	{
+		ID: "BRN0311", Kind: types, Title: "Missing return",
+		Explanation: `A function with a return type can end without returning a value, for
+example when only one branch of an if returns.
+
+    fun sign(n: int): int {
+        if (n < 0) {
+            return -1
+        }
+    }
+
+Return a value on every path.
+
+    fun sign(n: int): int {
+        if (n < 0) {
+            return -1
+        }
+        return 1
+    }`,
+	},
+	{
+		ID: "BRN0312", Kind: types, Title: "Invalid return",
+		Explanation: `A return gives a value of another type than the function returns, or a
+value at all in a void function or init method.
+
+    fun greet(): void {
+        return "hi"
+    }
+
+Return a value of the declared return type, or declare the type the
+function returns.
+
+    fun greet(): string {
+        return "hi"
+    }`,
+	},
+	{
+		ID: "BRN0314", Kind: types, Title: "Already defined",
+		Explanation: `A name is declared twice in the same scope: a variable, constant,
+function, type, class, field or method.
+
+    var count = 1
+    var count = 2
+
+Pick another name, or assign to the existing variable.
+
+    var count = 1
+    count = 2`,
+	},
+	{
+		ID: "BRN0316", Kind: types, Title: "Type cannot be inferred",
+		Explanation: `The type of a declaration cannot be worked out from its value, as for
+nil, an empty map or None, or there is no value at all.
+
+    var user = nil
+    var counts = {}
+
+Write the type.
+
+    var user: User? = nil
+    var counts: map<string, int> = {}`,
+	},
+	{
+		ID: "BRN0319", Kind: types, Title: "Break or continue outside of a loop",
+		Explanation: `break and continue can only be used inside a loop, and a label they
+name must be that of a loop around them.
+
+    fun stop() {
+        break
+    }
+
+Return from the function instead, or move the statement into the loop.`,
+	},
+	{
+		ID: "BRN0320", Kind: types, Title: "Unused variable or parameter",
+		Explanation: `In strict mode, a local variable must be read after it is declared.
+Outside of strict mode this is a warning, as a parameter that is never
+read always is.
+
+    fun main() {
+        var unused = compute()
+    }
+
+Remove the variable, use the value, or start its name with _ to keep it.
+
+    fun main() {
+        var _unused = compute()
+    }`,
+	},
+	{
+		ID: "BRN0321", Kind: types, Title: "Shadowed name",
+		Explanation: `In strict mode, a local variable or parameter must not have the name of
+a global variable, function or type, or of a variable of an enclosing
+scope, since the outer one can then no longer be used.
+
+    var count = 0
+    fun add(count: int) { ... }
+
+Give the local variable another name.`,
+	},
+	{
+		ID: "BRN0322", Kind: types, Title: "Invalid cast",
+		Explanation: `A value is cast with as to a type it can never have, such as a bool to
+int.
+
+    var n = true as int
+
+Convert the value explicitly.
+
+    var n = flag ? 1 : 0`,
+	},
+	{
+		ID: "BRN0323", Kind: types, Title: "Invalid index",
+		Explanation: `A value is indexed or sliced that cannot be, or with an index of the
+wrong type: arrays, strings and bytes take integers, and maps their key
+type.
+
+    var items = [1, 2, 3]
+    print(items["first"])
+
+Index with a value of the right type.
+
+    print(items[0])`,
+	},
+	{
+		ID: "BRN0324", Kind: types, Title: "Value cannot be called or iterated",
+		Explanation: `A value that is not a function is called, or a for-in loop goes over a
+value that is not an array, map, string or range.
+
+    var n = 3
+    for (x in n) { ... }
+
+Loop over a range instead.
+
+    for (x in 0..n) { ... }`,
+	},
+
+	{
+		ID: "BRN0326", Kind: types, Title: "Unreachable code",
+		Explanation: `A statement comes after a return, break or continue in the same block,
+or after an if whose branches all leave the block, so it never runs. This
+is a warning.
+
+    fun sign(n: int): int {
+        return n < 0 ? -1 : 1
+        print("done")
+    }
+
+Remove the statement, or move it before the return.`,
+	},
+	{
+		ID: "BRN0327", Kind: types, Title: "Constant condition",
+		Explanation: `The condition of an if is a constant, such as true or 1 < 2, so one of
+its branches never runs. This is a warning.
+
+    if (true) {
+        print("always")
+    }
+
+Remove the if and keep the branch that runs.`,
+	},
+	{
+		ID: "BRN0328", Kind: types, Title: "Explicit any",
+		Explanation: `In strict mode, a variable, parameter, field or result is declared with
+the type any, which turns off type checking of its uses. This is a
+warning.
+
+    fun show(value: any) {
+        print(value)
+    }
+
+Declare the type the value has, or an interface it implements.
+
+    fun show(value: string) {
+        print(value)
+    }`,
+	},
+	{
+		ID: "BRN0329", Kind: types, Title: "Branches of different types",
+		Explanation: `The two branches of a ? : expression, or the two operands of ??, have
+different types, so the expression has no single type.
+
+    var label = ok ? "yes" : 0
+    var name = nickname ?? 42
+
+Give both sides the same type.
+
+    var label = ok ? "yes" : "no"
+    var name = nickname ?? "anonymous"`,
+	},
+	{
+		ID: "BRN0330", Kind: types, Title: "Invalid class declaration",
+		Explanation: `A class or method declaration is not well-formed: a field of a class
+without init has no default value, init does not take the new instance
+as its first parameter, a static field has the name of a field, or a
+method is declared on a name that is not a type.
+
+    class Counter {
+        count: int
+        static count = 0
+    }
+
+Give fields a default value or an init method, and static fields names of
+their own.
+
+    class Counter {
+        count: int = 0
+        static total = 0
+    }`,
+	},
+	{
+		ID: "BRN0331", Kind: types, Title: "this outside of a method",
+		Explanation: `this is used outside of a method, or in a static method, which has no
+instance.
+
+    fun describe(): string {
+        return this.name
+    }
+
+Use this only in methods that take the instance as their first parameter,
+or pass the value as a parameter.
+
+    fun describe(user: User): string {
+        return user.name
+    }`,
+	},
+	{
+		ID: "BRN0332", Kind: types, Title: "Internal typechecker error",
+		Explanation: `The typechecker met a part of the syntax tree it does not know. This is a
+bug in Burn rather than in the program; please report it with the code
+that causes it.`,
+	},
+	{
+		ID: "BRN0501", Kind: runtime, Title: "Division by zero",
+		Explanation: `A number is divided by zero, or its remainder by zero is taken.
+
+    var average = total / count
+
+Check the divisor first.
+
+    if (count > 0) {
+        var average = total / count
+    }`,
+	},
+	{
+		ID: "BRN0502", Kind: runtime, Title: "Index out of range",
+		Explanation: `An element is read that does not exist: an index past the end of an
+array, string or bytes, a slice beyond its length, or a key a map does
+not have.
+
+    var items = [1, 2, 3]
+    print(items[3])
+
+Check the length or key first.
+
+    if (len(items) > 3) {
+        print(items[3])
+    }`,
+	},
+	{
+		ID: "BRN0503", Kind: runtime, Title: "Use of nil",
+		Explanation: `A field of a struct is read or set through a value that is nil.
+
+    var p: Point = nil
+    print(p.x)
+
+Check the value against nil first.`,
+	},
+	{
+		ID: "BRN0504", Kind: runtime, Title: "Unwrap failed",
+		Explanation: `unwrap is called on an Err or None, or unwrapErr on an Ok.
+
+    var n = unwrap(tryToInt("three"))
+
+Match on the result, or give a default with unwrapOr.
+
+    var n = unwrapOr(tryToInt("three"), 0)`,
+	},
+	{
+		ID: "BRN0505", Kind: runtime, Title: "Maximum recursion depth exceeded",
+		Explanation: `Functions call each other more deeply than the limit, 10000 nested calls
+unless --max-depth says otherwise. This is usually a recursive function
+whose base case is never reached. The error shows the chain of calls.
+
+    fun down(n: int): int {
+        return down(n - 1)
+    }
+
+Make sure the recursion ends, or raise the limit with --max-depth.
+
+    fun down(n: int): int {
+        if (n <= 0) {
+            return 0
+        }
+        return down(n - 1)
+    }`,
+	},
+	{
+		ID: "BRN0506", Kind: runtime, Title: "Limit exceeded",
+		Explanation: `A program embedded with limits ran too many steps, used too much memory
+or ran too long. The error says which limit was reached.
+
+Make the program do less work, or raise the limit where it is set.`,
+	},
+	{
+		ID: "BRN0507", Kind: runtime, Title: "Capability denied",
+		Explanation: `A builtin, such as HTTP.get, is called that the policy of the program
+that embeds Burn does not allow.
+
+Ask for the builtin to be allowed, or do without it.`,
+	},
+	{
+		ID: "BRN0508", Kind: runtime, Title: "Invalid cast",
+		Explanation: `A value, often one of type any such as from parseJSON, is cast with as to
+a type it does not have, or a string cast to a number is not a number.
+
+    var n = "ten" as int
+
+Check the value first, or use tryToInt and tryToFloat, which give a
+Result.`,
+	},
+	{
+		ID: "BRN0509", Kind: runtime, Title: "Undefined at runtime",
+		Explanation: `A variable, function, method or field is used that does not exist. The
+typechecker catches most of these; the rest come from values of type any,
+whose fields and methods are only known when the program runs.
+
+    var data = parseJSON("{}")
+    print(data.name)
+
+Cast the value to a type with the fields first.`,
+	},
+	{
+		ID: "BRN0510", Kind: runtime, Title: "Invalid argument",
+		Explanation: `A builtin, such as len, HTTP.get or roundDecimal, is called with the wrong
+number of arguments or with an argument it cannot use. The typechecker
+catches most of these; the rest come from values of type any.
+
+    var data = parseJSON("[1, 2]")
+    print(len(data.items))      // len expects string, array or map
+
+Cast the value to the type the builtin expects first.
+
+    var items = data as [int]
+    print(len(items))`,
+	},
+	{
+		ID: "BRN0511", Kind: runtime, Title: "Value of the wrong type",
+		Explanation: `An operator, index, field access, call or loop is used on a value of a
+type it does not work with, such as + on a string and nil. The
+typechecker catches most of these; the rest come from values of type any.
+
+    var data = parseJSON("{}")
+    print("name: " + data.name)
+
+Check or cast the value first.
+
+    var name = data.name as string`,
+	},
+	{
+		ID: "BRN0512", Kind: runtime, Title: "Conversion failed",
+		Explanation: `toInt, toFloat, toBigInt or toDecimal is given a value it cannot convert,
+such as a string that is not a number.
+
+    var n = toInt("ten")
+
+Use tryToInt or tryToFloat, which give a Result, or check the text first.
+
+    var n = unwrapOr(tryToInt("ten"), 0)`,
+	},
+	{
+		ID: "BRN0513", Kind: runtime, Title: "Host operation failed",
+		Explanation: `An operation of the host system failed: an HTTP request could not be
+made or its response read, JSON text could not be parsed, or standard
+input could not be read. The error says what went wrong.
+
+    var res = HTTP.get("http://localhost:1")
+
+Check that the server, file or input is available, and that JSON text is
+well-formed.`,
+	},
+	{
+		ID: "BRN0514", Kind: runtime, Title: "Assertion failed",
+		Explanation: `The condition given to assert is false. The error shows the message given
+to assert, if any.
+
+    assert(len(items) > 0, "items must not be empty")
+
+Find out why the condition does not hold; the assertion itself is right
+unless it expects the wrong thing.`,
+	},
+	{
+		ID: "BRN0515", Kind: runtime, Title: "Internal interpreter error",
+		Explanation: `The interpreter or the VM met a part of the program it does not know.
+This is a bug in Burn rather than in the program; please report it with
+the code that causes it.`,
+	},
+	{
+		ID: "BRN0516", Kind: runtime, Title: "Assignment to a constant",
+		Explanation: `A constant is assigned to while the program runs. The typechecker
+reports this as BRN0313 for code it checks; this error comes from code it
+did not check, such as a REPL line or a host call.
+
+    const limit = 10
+    limit = 20
+
+Declare the value with var if it needs to change.`,
+	},
+}
@@ -0,0 +1,88 @@
+// Package errcode gives the errors of each stage of running a Burn program
+// stable codes, such as BRN0301 for an undefined variable, and longer
+// explanations of them for burn --explain
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Code is the code of a kind of error
+type Code struct {
+	// ID is the code itself, such as BRN0301
+	ID string
+
+	// Kind is the stage the error happens in, such as "Type error"
+	Kind string
+
+	// Title says in a few words what is wrong
+	Title string
+
+	// Explanation describes the error with examples of code that causes
+	// it and of how to fix it
+	Explanation string
+}
+
+// Error is an error with a code, given to it where the error is made
+type Error struct {
+	// ID is the code of the error, such as BRN0301
+	ID  string
+	Err error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode returns the code of the error. Error types of other packages
+// give their errors a code with a method of the same name.
+func (e *Error) ErrorCode() string {
+	return e.ID
+}
+
+// New returns an error with the code id and the message text
+func New(id, text string) error {
+	return &Error{ID: id, Err: errors.New(text)}
+}
+
+// Errorf returns an error with the code id and a message formatted like
+// fmt.Errorf does, which may wrap other errors with %w
+func Errorf(id, format string, args ...any) error {
+	return &Error{ID: id, Err: fmt.Errorf(format, args...)}
+}
+
+// Lookup returns the code with the given ID, in upper or lower case
+func Lookup(id string) (Code, bool) {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	for _, code := range codes {
+		if code.ID == id {
+			return code, true
+		}
+	}
+	return Code{}, false
+}
+
+// Of returns the code of err: that of the first error with a code among err
+// and the errors it wraps, such as the undefined variable an error "in
+// function main: ..." wraps
+func Of(err error) (Code, bool) {
+	var coded interface{ ErrorCode() string }
+	if !errors.As(err, &coded) {
+		return Code{}, false
+	}
+	return Lookup(coded.ErrorCode())
+}
+
+// All returns every code, ordered by ID
+func All() []Code {
+	return slices.SortedFunc(slices.Values(codes), func(a, b Code) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+}
@@ -0,0 +1,123 @@
+package errcode_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/burnlang/burn/pkg/errcode"
+)
+
+// stages are the packages whose errors and warnings are diagnostics of
+// Burn programs
+var stages = []string{"lexer", "parser", "typechecker", "interpreter", "vm"}
+
+// notDiagnostics are files whose errors are returned to Go callers rather
+// than reported against a program
+var notDiagnostics = map[string]bool{
+	"parser/incremental.go": true,
+}
+
+// TestEveryDiagnosticHasCode fails for an error made in a stage without a
+// code, or with a code that does not exist. Errors that wrap another with
+// %w take the code of the error they wrap.
+func TestEveryDiagnosticHasCode(t *testing.T) {
+	fset := token.NewFileSet()
+	for _, stage := range stages {
+		files, err := filepath.Glob(filepath.Join("..", stage, "*.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range files {
+			name := filepath.Join(stage, filepath.Base(path))
+			if strings.HasSuffix(path, "_test.go") || notDiagnostics[filepath.ToSlash(name)] {
+				continue
+			}
+			file, err := parser.ParseFile(fset, path, nil, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ast.Inspect(file, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				checkCall(t, fset, call)
+				return true
+			})
+		}
+	}
+}
+
+func checkCall(t *testing.T, fset *token.FileSet, call *ast.CallExpr) {
+	t.Helper()
+	pos := fset.Position(call.Pos())
+	switch callee(call) {
+	case "fmt.Errorf":
+		if format, ok := literal(call.Args[0]); !ok || !strings.Contains(format, "%w") {
+			t.Errorf("%s: error made with fmt.Errorf has no code; use errcode.Errorf", pos)
+		}
+	case "errors.New":
+		t.Errorf("%s: error made with errors.New has no code; use errcode.New", pos)
+	case "errcode.Errorf", "errcode.New":
+		checkCode(t, pos, call.Args[0])
+	case "t.warn":
+		checkCode(t, pos, call.Args[1])
+	}
+}
+
+func checkCode(t *testing.T, pos token.Position, arg ast.Expr) {
+	t.Helper()
+	id, ok := literal(arg)
+	if !ok {
+		t.Errorf("%s: code is not a string literal", pos)
+		return
+	}
+	if _, ok := errcode.Lookup(id); !ok {
+		t.Errorf("%s: unknown code %s", pos, id)
+	}
+}
+
+// callee returns the name of a function called as pkg.Func or x.Method
+func callee(call *ast.CallExpr) string {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	x, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+	return x.Name + "." + sel.Sel.Name
+}
+
+func literal(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+func TestOf(t *testing.T) {
+	err := errcode.Errorf("BRN0301", "undefined variable: x")
+	wrapped := errcode.Errorf("BRN0402", "error in import a.bn: %w", err)
+	for _, tt := range []struct {
+		err  error
+		want string
+	}{
+		{err, "BRN0301"},
+		{wrapped, "BRN0402"},
+		{errcode.Errorf("BRN0999", "no such code"), ""},
+	} {
+		code, ok := errcode.Of(tt.err)
+		if code.ID != tt.want || ok != (tt.want != "") {
+			t.Errorf("Of(%q) = %s, %v; want %q", tt.err, code.ID, ok, tt.want)
+		}
+	}
+}
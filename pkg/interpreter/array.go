@@ -0,0 +1,472 @@
+package interpreter
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
+)
+
+// Array is a Burn array. An array is shared by every variable, field,
+// element and argument holding it, so what push, pop, insert, removeAt and
+// assignments to its elements do to it shows through all of them.
+type Array struct {
+	Elements []Value
+}
+
+// NewArray returns an array of elements
+func NewArray(elements []Value) *Array {
+	return &Array{Elements: elements}
+}
+
+func (a *Array) String() string {
+	return fmt.Sprint(a.Elements)
+}
+
+// arrayMutators are the builtins that change the length of an array
+var arrayMutators = map[string]bool{"push": true, "pop": true, "insert": true, "removeAt": true}
+
+// CallsBack reports whether the builtin called name is one of the
+// arrayCallbacks
+func CallsBack(name string) bool {
+	return arrayCallbacks[name]
+}
+
+// place is somewhere a value is stored, read and written without
+// evaluating the expression that names it twice
+type place struct {
+	get func() (Value, error)
+	set func(Value) error
+}
+
+// placeOf evaluates the parts of a variable, field or element expression
+// and returns the place it names
+func (i *Interpreter) placeOf(expr ast.Expression) (*place, error) {
+	switch e := expr.(type) {
+	case *ast.VariableExpression:
+		return &place{
+			get: func() (Value, error) {
+				value, exists := i.variable(e.Name, e.Binding)
+				if !exists {
+					return nil, errcode.Errorf("BRN0509", "undefined variable: %s%s", e.Name, i.nameHint(e.Name))
+				}
+				return value, nil
+			},
+			set: func(value Value) error {
+				return i.assign(e.Name, e.Binding, value)
+			},
+		}, nil
+	case *ast.GetExpression:
+		if class, ok := i.staticField(e); ok {
+			return &place{
+				get: func() (Value, error) {
+					return class.StaticValues[e.Name], nil
+				},
+				set: func(value Value) error {
+					return class.setStatic(e.Name, value)
+				},
+			}, nil
+		}
+		object, err := i.evaluateExpression(e.Object)
+		if err != nil {
+			return nil, err
+		}
+		structObj, ok := object.(*Struct)
+		if !ok {
+			return nil, errcode.Errorf("BRN0511", "cannot access field on non-struct value")
+		}
+		return &place{
+			get: func() (Value, error) {
+				value, exists := structObj.Fields[e.Name]
+				if !exists {
+					return nil, errcode.Errorf("BRN0509", "undefined field '%s' on struct of type '%s'", e.Name, structObj.TypeName)
+				}
+				return value, nil
+			},
+			set: func(value Value) error {
+				structObj.Fields[e.Name] = value
+				return nil
+			},
+		}, nil
+	case *ast.IndexExpression:
+		target, err := i.evaluateExpression(e.Array)
+		if err != nil {
+			return nil, err
+		}
+		index, err := i.evaluateExpression(e.Index)
+		if err != nil {
+			return nil, err
+		}
+		switch container := target.(type) {
+		case *Map:
+			if err := checkKey(index); err != nil {
+				return nil, err
+			}
+			return &place{
+				get: func() (Value, error) {
+					value, exists := container.Get(index)
+					if !exists {
+						return nil, errcode.Errorf("BRN0502", "map has no key %v", index)
+					}
+					return value, nil
+				},
+				set: func(value Value) error {
+					container.Set(index, value)
+					return nil
+				},
+			}, nil
+		case *Array:
+			indexNumber, ok := index.(float64)
+			if !ok {
+				return nil, errcode.Errorf("BRN0511", "array index must be a number")
+			}
+			idx := int(indexNumber)
+			if idx < 0 || idx >= len(container.Elements) {
+				return nil, errcode.Errorf("BRN0502", "array index out of bounds: %d", idx)
+			}
+			return &place{
+				get: func() (Value, error) {
+					return container.Elements[idx], nil
+				},
+				set: func(value Value) error {
+					container.Elements[idx] = value
+					return nil
+				},
+			}, nil
+		}
+		return nil, errcode.Errorf("BRN0511", "cannot index into %T", target)
+	}
+	return nil, errcode.Errorf("BRN0511", "expected a variable, field or element, got %T", expr)
+}
+
+// addArrayBuiltins adds the builtins on arrays other than the
+// arrayCallbacks
+func (i *Interpreter) addArrayBuiltins() {
+	for name := range arrayMutators {
+		i.builtins.values[name] = &BuiltinFunction{
+			Name: name,
+			Fn: func(args []Value) (Value, error) {
+				return i.mutateArray(name, args)
+			},
+		}
+	}
+	i.builtins.values["contains"] = &BuiltinFunction{
+		Name: "contains",
+		Fn: func(args []Value) (Value, error) {
+			if len(args) != 2 {
+				return nil, errcode.Errorf("BRN0510", "contains expects exactly 2 arguments")
+			}
+			arr, ok := args[0].(*Array)
+			if !ok {
+				return nil, errcode.Errorf("BRN0510", "contains expects an array, got %s", typeOf(args[0]))
+			}
+			for _, elem := range arr.Elements {
+				if equal, err := deepEqual(elem, args[1]); err != nil || equal {
+					return equal, err
+				}
+			}
+			return false, nil
+		},
+	}
+}
+
+// mutateArray applies one of the arrayMutators to the array args[0], with
+// the arguments after it, changing the array in place
+func (i *Interpreter) mutateArray(name string, args []Value) (Value, error) {
+	want := map[string]int{"push": 2, "pop": 1, "insert": 3, "removeAt": 2}[name]
+	if len(args) != want {
+		return nil, errcode.Errorf("BRN0510", "%s expects exactly %d arguments", name, want)
+	}
+	arr, ok := args[0].(*Array)
+	if !ok {
+		return nil, errcode.Errorf("BRN0510", "%s expects an array, got %s", name, typeOf(args[0]))
+	}
+	elems := arr.Elements
+
+	index := func(limit int) (int, error) {
+		number, ok := args[1].(float64)
+		if !ok {
+			return 0, errcode.Errorf("BRN0510", "%s expects a number as index, got %s", name, typeOf(args[1]))
+		}
+		idx := int(number)
+		if idx < 0 || idx > limit {
+			return 0, errcode.Errorf("BRN0502", "%s index %d out of bounds for array of length %d", name, idx, len(elems))
+		}
+		return idx, nil
+	}
+
+	var result Value
+	switch name {
+	case "push":
+		if err := i.allocate(16); err != nil {
+			return nil, err
+		}
+		arr.Elements = append(elems, args[1])
+	case "pop":
+		if len(elems) == 0 {
+			return nil, errcode.Errorf("BRN0502", "pop from an empty array")
+		}
+		last := len(elems) - 1
+		result = elems[last]
+		elems[last] = nil
+		arr.Elements = elems[:last]
+	case "insert":
+		idx, err := index(len(elems))
+		if err != nil {
+			return nil, err
+		}
+		if err := i.allocate(16); err != nil {
+			return nil, err
+		}
+		arr.Elements = slices.Insert(elems, idx, args[2])
+	case "removeAt":
+		idx, err := index(len(elems) - 1)
+		if err != nil {
+			return nil, err
+		}
+		result = elems[idx]
+		arr.Elements = slices.Delete(elems, idx, idx+1)
+	}
+	return result, nil
+}
+
+// arrayCallbacks are the builtins that go through the elements of an
+// array, calling a function for them except for sort
+var arrayCallbacks = map[string]bool{
+	"map": true, "filter": true, "reduce": true,
+	"find": true, "some": true, "every": true,
+	"sort": true, "sortBy": true,
+}
+
+// callArrayCallback calls one of the arrayCallbacks
+func (i *Interpreter) callArrayCallback(name string, args []Value) (Value, error) {
+	count := 2
+	switch name {
+	case "reduce":
+		count = 3
+	case "sort":
+		count = 1
+	}
+	if len(args) != count {
+		return nil, errcode.Errorf("BRN0510", "%s expects exactly %d arguments", name, count)
+	}
+	if err := i.host.policy.check(name); err != nil {
+		return nil, err
+	}
+	i.countBuiltin(name)
+
+	array, ok := args[0].(*Array)
+	if !ok {
+		return nil, errcode.Errorf("BRN0510", "%s expects an array, got %s", name, typeOf(args[0]))
+	}
+	arr := array.Elements
+	if name == "sort" {
+		return i.sortArray(arr, nil)
+	}
+	fn := args[1]
+	test := func(elem Value) (bool, error) {
+		result, err := i.callValue(fn, []Value{elem})
+		if err != nil {
+			return false, err
+		}
+		b, ok := result.(bool)
+		if !ok {
+			return false, errcode.Errorf("BRN0511", "function given to %s must return a bool, got %T", name, result)
+		}
+		return b, nil
+	}
+
+	switch name {
+	case "sortBy":
+		return i.sortArray(arr, fn)
+	case "map":
+		if err := i.allocate(16 * len(arr)); err != nil {
+			return nil, err
+		}
+		result := make([]Value, len(arr))
+		for j, elem := range arr {
+			value, err := i.callValue(fn, []Value{elem})
+			if err != nil {
+				return nil, err
+			}
+			result[j] = value
+		}
+		return NewArray(result), nil
+	case "filter":
+		result := []Value{}
+		for _, elem := range arr {
+			keep, err := test(elem)
+			if err != nil {
+				return nil, err
+			}
+			if keep {
+				if err := i.allocate(16); err != nil {
+					return nil, err
+				}
+				result = append(result, elem)
+			}
+		}
+		return NewArray(result), nil
+	case "reduce":
+		acc := args[2]
+		for _, elem := range arr {
+			value, err := i.callValue(fn, []Value{acc, elem})
+			if err != nil {
+				return nil, err
+			}
+			acc = value
+		}
+		return acc, nil
+	case "find":
+		for _, elem := range arr {
+			found, err := test(elem)
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				return &Option{Some: true, Value: elem}, nil
+			}
+		}
+		return &Option{}, nil
+	}
+
+	// some stops at the first element passing the test and every at the
+	// first failing it
+	for _, elem := range arr {
+		passed, err := test(elem)
+		if err != nil {
+			return nil, err
+		}
+		if passed == (name == "some") {
+			return passed, nil
+		}
+	}
+	return name == "every", nil
+}
+
+// sortArray returns a sorted copy of arr. Without a comparison function the
+// elements must be all numbers or all strings; with one, a negative result
+// puts its first argument first. Equal elements keep their order.
+func (i *Interpreter) sortArray(arr []Value, compare Value) (Value, error) {
+	if err := i.allocate(16 * len(arr)); err != nil {
+		return nil, err
+	}
+	sorted := make([]Value, len(arr))
+	copy(sorted, arr)
+
+	if compare == nil {
+		numbers := len(sorted) > 0
+		if numbers {
+			_, numbers = sorted[0].(float64)
+		}
+		for _, elem := range sorted {
+			_, isNumber := elem.(float64)
+			_, isString := elem.(string)
+			if !(numbers && isNumber) && !(!numbers && isString) {
+				return nil, errcode.Errorf("BRN0510", "sort expects an array of numbers or of strings, got %T", elem)
+			}
+		}
+		sort.SliceStable(sorted, func(a, b int) bool {
+			if x, ok := sorted[a].(float64); ok {
+				return x < sorted[b].(float64)
+			}
+			return sorted[a].(string) < sorted[b].(string)
+		})
+		return NewArray(sorted), nil
+	}
+
+	// The first error stops the calls of compare, and sorting ends without
+	// moving anything further
+	var sortErr error
+	sort.SliceStable(sorted, func(a, b int) bool {
+		if sortErr != nil {
+			return false
+		}
+		result, err := i.callValue(compare, []Value{sorted[a], sorted[b]})
+		if err != nil {
+			sortErr = err
+			return false
+		}
+		order, ok := result.(float64)
+		if !ok {
+			sortErr = errcode.Errorf("BRN0511", "function given to sortBy must return a number, got %T", result)
+			return false
+		}
+		return order < 0
+	})
+	if sortErr != nil {
+		return nil, sortErr
+	}
+	return NewArray(sorted), nil
+}
+
+// evaluateSlice evaluates a[start:end], a new array or string of the
+// elements or characters from start up to but not including end
+func (i *Interpreter) evaluateSlice(expr *ast.SliceExpression) (Value, error) {
+	target, err := i.evaluateExpression(expr.Array)
+	if err != nil {
+		return nil, err
+	}
+
+	// Strings are sliced by character, not by byte
+	var length int
+	var chars []rune
+	switch t := target.(type) {
+	case *Array:
+		length = len(t.Elements)
+	case string:
+		chars = []rune(t)
+		length = len(chars)
+	case []byte:
+		length = len(t)
+	default:
+		return nil, errcode.Errorf("BRN0511", "cannot slice %T", target)
+	}
+
+	bound := func(e ast.Expression, missing int) (int, error) {
+		if e == nil {
+			return missing, nil
+		}
+		value, err := i.evaluateExpression(e)
+		if err != nil {
+			return 0, err
+		}
+		number, ok := value.(float64)
+		if !ok {
+			return 0, errcode.Errorf("BRN0511", "slice bounds must be numbers, got %T", value)
+		}
+		return int(number), nil
+	}
+	start, err := bound(expr.Start, 0)
+	if err != nil {
+		return nil, err
+	}
+	end, err := bound(expr.End, length)
+	if err != nil {
+		return nil, err
+	}
+	if start < 0 || end > length || start > end {
+		return nil, errcode.Errorf("BRN0502", "slice bounds out of range: [%d:%d] of length %d", start, end, length)
+	}
+
+	if b, ok := target.([]byte); ok {
+		if err := i.allocate(end - start); err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b[start:end]...), nil
+	}
+	arr, isArray := target.(*Array)
+	if !isArray {
+		result := string(chars[start:end])
+		if err := i.allocate(len(result)); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+	if err := i.allocate(16 * (end - start)); err != nil {
+		return nil, err
+	}
+	return NewArray(slices.Clone(arr.Elements[start:end])), nil
+}
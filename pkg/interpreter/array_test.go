@@ -0,0 +1,75 @@
+package interpreter_test
+
+import (
+	"testing"
+
+	"github.com/burnlang/burn/internal/testutil"
+)
+
+func TestPushToSharedArray(t *testing.T) {
+	got := testutil.Run(t, `
+var e = [1, 2]
+push(e, 3)
+var f = e
+push(e, 4)
+push(f, 5)
+print(e)
+print(f)
+`)
+	if want := "[1 2 3 4 5]\n[1 2 3 4 5]\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestInsertAndRemoveOnSharedArray(t *testing.T) {
+	got := testutil.Run(t, `
+var e = [1, 2]
+push(e, 3)
+var f = e
+insert(e, 0, 0)
+removeAt(f, 0)
+push(f, 6)
+print(e)
+print(f)
+`)
+	if want := "[1 2 3 6]\n[1 2 3 6]\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestFunctionChangesArgumentArray(t *testing.T) {
+	got := testutil.Run(t, `
+type Bag { items: [int] }
+
+fun add(xs: [int]) {
+    push(xs, 1)
+    xs[0] = 9
+}
+
+var a = [0]
+add(a)
+var b = Bag{items: [0]}
+add(b.items)
+var grid = [[0], [0]]
+add(grid[1])
+print(a)
+print(b.items)
+print(grid)
+`)
+	if want := "[9 1]\n[9 1]\n[[0] [9 1]]\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSliceCopiesArray(t *testing.T) {
+	got := testutil.Run(t, `
+var a = [1, 2]
+var b = a[0:len(a)]
+push(b, 3)
+print(a)
+print(b)
+`)
+	if want := "[1 2]\n[1 2 3]\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,126 @@
+package interpreter
+
+import (
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/burnlang/burn/pkg/errcode"
+)
+
+// Burn bigints are *big.Int values. None is changed once made: every
+// operation on bigints makes a new one, so they can be shared freely.
+
+// bigIntOf converts an operand of an operation on bigints, a bigint or a
+// whole number, to a bigint
+func bigIntOf(value Value) (*big.Int, bool) {
+	switch v := value.(type) {
+	case *big.Int:
+		return v, true
+	case float64:
+		if v != math.Trunc(v) || math.IsInf(v, 0) {
+			return nil, false
+		}
+		n, _ := big.NewFloat(v).Int(nil)
+		return n, true
+	}
+	return nil, false
+}
+
+// bigIntOperation evaluates a binary operation on two bigints or a bigint
+// and a whole number. It reports false when the operands are not these,
+// leaving the operation to the other values.
+func (i *Interpreter) bigIntOperation(operator string, left, right Value) (Value, bool, error) {
+	_, lBig := left.(*big.Int)
+	_, rBig := right.(*big.Int)
+	if !lBig && !rBig {
+		return nil, false, nil
+	}
+	l, lOk := bigIntOf(left)
+	r, rOk := bigIntOf(right)
+	if !lOk || !rOk {
+		return nil, false, nil
+	}
+
+	result := new(big.Int)
+	switch operator {
+	case "==":
+		return l.Cmp(r) == 0, true, nil
+	case "!=":
+		return l.Cmp(r) != 0, true, nil
+	case "<":
+		return l.Cmp(r) < 0, true, nil
+	case ">":
+		return l.Cmp(r) > 0, true, nil
+	case "<=":
+		return l.Cmp(r) <= 0, true, nil
+	case ">=":
+		return l.Cmp(r) >= 0, true, nil
+	case "+":
+		result.Add(l, r)
+	case "-":
+		result.Sub(l, r)
+	case "*":
+		result.Mul(l, r)
+	case "/":
+		if r.Sign() == 0 {
+			return nil, true, errcode.Errorf("BRN0501", "division by zero")
+		}
+		result.Quo(l, r)
+	case "%":
+		if r.Sign() == 0 {
+			return nil, true, errcode.Errorf("BRN0501", "modulo by zero")
+		}
+		result.Rem(l, r)
+	default:
+		return nil, false, nil
+	}
+	if err := i.allocate(len(result.Bits()) * 8); err != nil {
+		return nil, true, err
+	}
+	return result, true, nil
+}
+
+// toBigInt converts a number, truncating it toward zero, or the text of an
+// integer to a bigint, for toBigInt and as bigint
+func toBigInt(value Value) (*big.Int, error) {
+	switch v := value.(type) {
+	case *big.Int:
+		return v, nil
+	case *Decimal:
+		whole, _ := v.Round(0, "down")
+		return whole.Digits, nil
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return nil, errcode.Errorf("BRN0512", "cannot convert %g to bigint", v)
+		}
+		n, _ := big.NewFloat(v).Int(nil)
+		return n, nil
+	case string:
+		n, ok := new(big.Int).SetString(strings.TrimSpace(v), 10)
+		if !ok {
+			return nil, errcode.Errorf("BRN0512", "cannot convert %q to bigint", v)
+		}
+		return n, nil
+	}
+	return nil, errcode.Errorf("BRN0512", "cannot convert %s to bigint", typeOf(value))
+}
+
+// bigIntFloat returns the float nearest a bigint, for toFloat, toInt and
+// casts to numbers
+func bigIntFloat(n *big.Int) float64 {
+	f, _ := new(big.Float).SetInt(n).Float64()
+	return f
+}
+
+func (i *Interpreter) addBigIntBuiltins() {
+	i.builtins.values["toBigInt"] = &BuiltinFunction{
+		Name: "toBigInt",
+		Fn: func(args []Value) (Value, error) {
+			if len(args) != 1 {
+				return nil, errcode.Errorf("BRN0510", "toBigInt expects exactly one argument")
+			}
+			return toBigInt(args[0])
+		},
+	}
+}
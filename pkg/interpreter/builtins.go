@@ -1,15 +1,19 @@
 package interpreter
 
 import (
-	"bufio"
 	"fmt"
-	"os"
+	"math"
+	"math/big"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
+
+	"github.com/burnlang/burn/pkg/errcode"
 )
 
-type Value interface{}
+// Value is a Burn runtime value
+type Value = interface{}
 
 type BuiltinFunction struct {
 	Name string
@@ -20,132 +24,281 @@ func (b *BuiltinFunction) Call(args []Value) (Value, error) {
 	return b.Fn(args)
 }
 
+// DefineFunction adds a built-in function supplied by the host. Like the
+// other built-ins it can be called from every function. It fails for names
+// the Policy forbids.
+func (i *Interpreter) DefineFunction(name string, fn func(args []Value) (Value, error)) error {
+	if _, exists := i.globals.Get(name); exists {
+		return errcode.Errorf("BRN0314", "%s is already defined", name)
+	}
+	if err := i.host.policy.check(name); err != nil {
+		return err
+	}
+	i.builtins.Define(name, &BuiltinFunction{Name: name, Fn: fn})
+	return nil
+}
+
 func (i *Interpreter) addBuiltins() {
-	i.environment["print"] = &BuiltinFunction{
+	i.builtins.values["print"] = &BuiltinFunction{
 		Name: "print",
 		Fn: func(args []Value) (Value, error) {
 			for _, arg := range args {
-				fmt.Println(arg)
+				shown, err := i.Display(arg)
+				if err != nil {
+					return nil, err
+				}
+				fmt.Fprintln(i.host.stdout, shown)
 			}
 			return nil, nil
 		},
 	}
 
-	i.environment["input"] = &BuiltinFunction{
+	i.builtins.values["input"] = &BuiltinFunction{
 		Name: "input",
 		Fn: func(args []Value) (Value, error) {
+			if err := i.require(CapabilityInput, "input"); err != nil {
+				return nil, err
+			}
 			if len(args) > 0 {
-				fmt.Print(args[0])
+				fmt.Fprint(i.host.stdout, args[0])
 			}
-			reader := bufio.NewReader(os.Stdin)
-			text, err := reader.ReadString('\n')
+			text, err := i.host.stdin.ReadString('\n')
 			if err != nil {
-				return "", err
+				return "", errcode.Errorf("BRN0513", "%w", err)
 			}
 			return strings.TrimSpace(text), nil
 		},
 	}
 
-	i.environment["toString"] = &BuiltinFunction{
+	i.builtins.values["toString"] = &BuiltinFunction{
 		Name: "toString",
 		Fn: func(args []Value) (Value, error) {
 			if len(args) != 1 {
-				return nil, fmt.Errorf("toString expects exactly one argument")
-			}
-
-			switch val := args[0].(type) {
-			case float64:
-				if val == float64(int(val)) {
-					return fmt.Sprintf("%.0f", val), nil
-				}
-				return fmt.Sprintf("%g", val), nil
-			case int:
-				return fmt.Sprintf("%d", val), nil
-			case string:
-				return val, nil
-			case bool:
-				return fmt.Sprintf("%t", val), nil
-			case nil:
-				return "null", nil
-			default:
-				return fmt.Sprintf("%v", val), nil
+				return nil, errcode.Errorf("BRN0510", "toString expects exactly one argument")
 			}
+			return i.toString(args[0])
 		},
 	}
 
-	i.environment["toInt"] = &BuiltinFunction{
+	i.builtins.values["toInt"] = &BuiltinFunction{
 		Name: "toInt",
 		Fn: func(args []Value) (Value, error) {
 			if len(args) != 1 {
-				return nil, fmt.Errorf("toInt expects exactly one argument")
+				return nil, errcode.Errorf("BRN0510", "toInt expects exactly one argument")
 			}
 
 			switch val := args[0].(type) {
 			case float64:
 				return float64(int(val)), nil
+			case *big.Int:
+				return math.Trunc(bigIntFloat(val)), nil
+			case *Decimal:
+				return math.Trunc(decimalFloat(val)), nil
 			case string:
 				intVal, err := strconv.Atoi(val)
 				if err != nil {
-					return nil, fmt.Errorf("cannot convert string to int: %v", err)
+					return nil, errcode.Errorf("BRN0512", "cannot convert string to int: %v", err)
 				}
 				return float64(intVal), nil
 			default:
-				return nil, fmt.Errorf("cannot convert %T to int", val)
+				return nil, errcode.Errorf("BRN0512", "cannot convert %T to int", val)
 			}
 		},
 	}
 
-	i.environment["toFloat"] = &BuiltinFunction{
+	i.builtins.values["toFloat"] = &BuiltinFunction{
 		Name: "toFloat",
 		Fn: func(args []Value) (Value, error) {
 			if len(args) != 1 {
-				return nil, fmt.Errorf("toFloat expects exactly one argument")
+				return nil, errcode.Errorf("BRN0510", "toFloat expects exactly one argument")
 			}
 
 			switch val := args[0].(type) {
 			case float64:
 				return val, nil
+			case *big.Int:
+				return bigIntFloat(val), nil
+			case *Decimal:
+				return decimalFloat(val), nil
 			case string:
 				floatVal, err := strconv.ParseFloat(val, 64)
 				if err != nil {
-					return nil, fmt.Errorf("cannot convert string to float: %v", err)
+					return nil, errcode.Errorf("BRN0512", "cannot convert string to float: %v", err)
 				}
 				return floatVal, nil
 			default:
-				return nil, fmt.Errorf("cannot convert %T to float", val)
+				return nil, errcode.Errorf("BRN0512", "cannot convert %T to float", val)
 			}
 		},
 	}
 
-	i.environment["len"] = &BuiltinFunction{
+	i.builtins.values["len"] = &BuiltinFunction{
 		Name: "len",
 		Fn: func(args []Value) (Value, error) {
 			if len(args) != 1 {
-				return nil, fmt.Errorf("len expects exactly one argument")
+				return nil, errcode.Errorf("BRN0510", "len expects exactly one argument")
 			}
 
 			switch val := args[0].(type) {
 			case string:
+				return float64(utf8.RuneCountInString(val)), nil
+			case *Array:
+				return float64(len(val.Elements)), nil
+			case []byte:
 				return float64(len(val)), nil
-			case []Value:
-				return float64(len(val)), nil
+			case *Map:
+				return float64(len(val.Keys)), nil
 			default:
-				return nil, fmt.Errorf("len expects string or array, got %T", val)
+				return nil, errcode.Errorf("BRN0510", "len expects string, array or map, got %T", val)
+			}
+		},
+	}
+
+	i.builtins.values["lenBytes"] = &BuiltinFunction{
+		Name: "lenBytes",
+		Fn: func(args []Value) (Value, error) {
+			if len(args) != 1 {
+				return nil, errcode.Errorf("BRN0510", "lenBytes expects exactly one argument")
+			}
+			s, ok := args[0].(string)
+			if !ok {
+				return nil, errcode.Errorf("BRN0510", "lenBytes expects a string, got %T", args[0])
 			}
+			return float64(len(s)), nil
 		},
 	}
 
-	i.environment["now"] = &BuiltinFunction{
+	i.builtins.values["typeof"] = &BuiltinFunction{
+		Name: "typeof",
+		Fn: func(args []Value) (Value, error) {
+			if len(args) != 1 {
+				return nil, errcode.Errorf("BRN0510", "typeof expects exactly one argument")
+			}
+			return typeOf(args[0]), nil
+		},
+	}
+
+	i.builtins.values["clone"] = &BuiltinFunction{
+		Name: "clone",
+		Fn: func(args []Value) (Value, error) {
+			if len(args) != 1 {
+				return nil, errcode.Errorf("BRN0510", "clone expects exactly one argument")
+			}
+			return i.deepCopy(args[0], make(map[Value]Value))
+		},
+	}
+
+	i.builtins.values["assert"] = &BuiltinFunction{
+		Name: "assert",
+		Fn: func(args []Value) (Value, error) {
+			if len(args) < 1 || len(args) > 2 {
+				return nil, errcode.Errorf("BRN0510", "assert expects a condition and an optional message")
+			}
+			if cond, ok := args[0].(bool); ok && cond {
+				return nil, nil
+			}
+			if len(args) == 2 {
+				return nil, errcode.Errorf("BRN0514", "assertion failed: %v", args[1])
+			}
+			return nil, errcode.Errorf("BRN0514", "assertion failed")
+		},
+	}
+
+	i.builtins.values["now"] = &BuiltinFunction{
 		Name: "now",
 		Fn: func(args []Value) (Value, error) {
 			if len(args) != 0 {
-				return nil, fmt.Errorf("now expects no arguments")
+				return nil, errcode.Errorf("BRN0510", "now expects no arguments")
 			}
 			currentTime := float64(time.Now().UnixNano()) / 1e9
 			return currentTime, nil
 		},
 	}
+	i.addArrayBuiltins()
+	i.addBigIntBuiltins()
+	i.addDecimalBuiltins()
+	i.addMapBuiltins()
+	i.addResultBuiltins()
+	i.addBytesBuiltins()
 	i.registerDateLibrary()
 	i.registerHTTPLibrary()
 	i.registerTimeLibrary()
 }
+
+// toString converts a value to a string for toString and as string
+func (i *Interpreter) toString(value Value) (string, error) {
+	switch val := value.(type) {
+	case float64:
+		if val == float64(int(val)) {
+			return fmt.Sprintf("%.0f", val), nil
+		}
+		return fmt.Sprintf("%g", val), nil
+	case int:
+		return fmt.Sprintf("%d", val), nil
+	case *big.Int:
+		return val.String(), nil
+	case *Decimal:
+		return val.String(), nil
+	case string:
+		return val, nil
+	case bool:
+		return fmt.Sprintf("%t", val), nil
+	case nil:
+		return "null", nil
+	case *Struct:
+		str, ok, err := i.structString(val)
+		if err != nil || ok {
+			return str, err
+		}
+		return fmt.Sprintf("%v", val), nil
+	default:
+		return fmt.Sprintf("%v", val), nil
+	}
+}
+
+// typeOf returns the name of the Burn type of a value for typeof. Ints and
+// floats are both float64 at run time: the typechecker tells typeof which
+// one an argument of either type is, and for any other a whole number is
+// taken for an int.
+func typeOf(value Value) string {
+	switch v := value.(type) {
+	case nil:
+		return "nil"
+	case float64:
+		if v == float64(int(v)) {
+			return "int"
+		}
+		return "float"
+	case int:
+		return "int"
+	case *big.Int:
+		return "bigint"
+	case *Decimal:
+		return "decimal"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case []byte:
+		return "bytes"
+	case *Array:
+		return "array"
+	case *Map, map[string]interface{}:
+		return "map"
+	case *Struct:
+		return v.TypeName
+	case *Result:
+		return "Result"
+	case *Option:
+		return "Option"
+	case *Function, *BuiltinFunction:
+		return "function"
+	case *Range:
+		return "range"
+	case *Class:
+		return "class"
+	case time.Time:
+		return "Time"
+	}
+	return "any"
+}
@@ -0,0 +1,22 @@
+package interpreter_test
+
+import (
+	"testing"
+
+	"github.com/burnlang/burn/internal/testutil"
+)
+
+func TestTypeof(t *testing.T) {
+	got := testutil.Run(t, `
+fun show(a: any) { print(typeof(a)) }
+print(typeof(5.0))
+print(typeof(1000000 * 1000000 * 1000000 * 1000000))
+print(typeof(1..3))
+print(typeof([1]))
+show(2.5)
+show(1..3)
+`)
+	if want := "float\nint\nrange\narray\nfloat\nrange\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
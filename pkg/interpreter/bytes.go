@@ -0,0 +1,154 @@
+package interpreter
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/burnlang/burn/pkg/errcode"
+)
+
+// Burn bytes values are Go byte slices. Indexing one gives a number from 0
+// to 255.
+
+func (i *Interpreter) addBytesBuiltins() {
+	argCount := func(name string, args []Value) error {
+		if len(args) != 1 {
+			return errcode.Errorf("BRN0510", "%s expects exactly one argument", name)
+		}
+		return nil
+	}
+	bytesArg := func(name string, args []Value) ([]byte, error) {
+		if err := argCount(name, args); err != nil {
+			return nil, err
+		}
+		b, ok := args[0].([]byte)
+		if !ok {
+			return nil, errcode.Errorf("BRN0510", "%s expects bytes, got %T", name, args[0])
+		}
+		return b, nil
+	}
+	stringArg := func(name string, args []Value) (string, error) {
+		if err := argCount(name, args); err != nil {
+			return "", err
+		}
+		s, ok := args[0].(string)
+		if !ok {
+			return "", errcode.Errorf("BRN0510", "%s expects a string, got %T", name, args[0])
+		}
+		return s, nil
+	}
+
+	i.builtins.values["toBytes"] = &BuiltinFunction{
+		Name: "toBytes",
+		Fn: func(args []Value) (Value, error) {
+			s, err := stringArg("toBytes", args)
+			if err != nil {
+				return nil, err
+			}
+			if err := i.allocate(len(s)); err != nil {
+				return nil, err
+			}
+			return []byte(s), nil
+		},
+	}
+
+	i.builtins.values["bytesOf"] = &BuiltinFunction{
+		Name: "bytesOf",
+		Fn: func(args []Value) (Value, error) {
+			if err := argCount("bytesOf", args); err != nil {
+				return nil, err
+			}
+			array, ok := args[0].(*Array)
+			if !ok {
+				return nil, errcode.Errorf("BRN0510", "bytesOf expects an array, got %s", typeOf(args[0]))
+			}
+			values := array.Elements
+			if err := i.allocate(len(values)); err != nil {
+				return nil, err
+			}
+			b := make([]byte, len(values))
+			for j, value := range values {
+				n, ok := value.(float64)
+				if !ok || n < 0 || n > 255 || n != float64(int(n)) {
+					return nil, errcode.Errorf("BRN0510", "bytesOf expects whole numbers from 0 to 255, got %v", value)
+				}
+				b[j] = byte(n)
+			}
+			return b, nil
+		},
+	}
+
+	i.builtins.values["decodeUTF8"] = &BuiltinFunction{
+		Name: "decodeUTF8",
+		Fn: func(args []Value) (Value, error) {
+			b, err := bytesArg("decodeUTF8", args)
+			if err != nil {
+				return nil, err
+			}
+			if !utf8.Valid(b) {
+				return &Result{Err: "bytes are not valid UTF-8"}, nil
+			}
+			return &Result{Ok: true, Value: string(b)}, nil
+		},
+	}
+
+	i.builtins.values["hexEncode"] = &BuiltinFunction{
+		Name: "hexEncode",
+		Fn: func(args []Value) (Value, error) {
+			b, err := bytesArg("hexEncode", args)
+			if err != nil {
+				return nil, err
+			}
+			if err := i.allocate(2 * len(b)); err != nil {
+				return nil, err
+			}
+			return hex.EncodeToString(b), nil
+		},
+	}
+
+	i.builtins.values["hexDecode"] = &BuiltinFunction{
+		Name: "hexDecode",
+		Fn: func(args []Value) (Value, error) {
+			s, err := stringArg("hexDecode", args)
+			if err != nil {
+				return nil, err
+			}
+			b, err := hex.DecodeString(s)
+			if err != nil {
+				return &Result{Err: fmt.Sprintf("invalid hex: %v", err)}, nil
+			}
+			return &Result{Ok: true, Value: b}, nil
+		},
+	}
+
+	i.builtins.values["base64Encode"] = &BuiltinFunction{
+		Name: "base64Encode",
+		Fn: func(args []Value) (Value, error) {
+			b, err := bytesArg("base64Encode", args)
+			if err != nil {
+				return nil, err
+			}
+			if err := i.allocate(base64.StdEncoding.EncodedLen(len(b))); err != nil {
+				return nil, err
+			}
+			return base64.StdEncoding.EncodeToString(b), nil
+		},
+	}
+
+	i.builtins.values["base64Decode"] = &BuiltinFunction{
+		Name: "base64Decode",
+		Fn: func(args []Value) (Value, error) {
+			s, err := stringArg("base64Decode", args)
+			if err != nil {
+				return nil, err
+			}
+			b, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return &Result{Err: fmt.Sprintf("invalid base64: %v", err)}, nil
+			}
+			return &Result{Ok: true, Value: b}, nil
+		},
+	}
+}
@@ -0,0 +1,250 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
+)
+
+// evaluateCast evaluates expr as Type
+func (i *Interpreter) evaluateCast(expr *ast.CastExpression) (Value, error) {
+	value, err := i.evaluateExpression(expr.Expression)
+	if err != nil {
+		return nil, err
+	}
+	i.setErrorAt(expr)
+	return i.cast(value, expr.TargetType)
+}
+
+// cast converts value to the type typeName, or checks that it already has
+// that type. Numbers convert to and from strings, and an array, map or
+// struct of a value of type any, such as one from parseJSON, is narrowed
+// element by element or field by field.
+func (i *Interpreter) cast(value Value, typeName string) (Value, error) {
+	fail := func() (Value, error) {
+		return nil, errcode.Errorf("BRN0508", "cannot cast %s to %s", typeOf(value), typeName)
+	}
+
+	if baseType, optional := strings.CutSuffix(typeName, "?"); optional {
+		if value == nil {
+			return nil, nil
+		}
+		return i.cast(value, baseType)
+	}
+
+	switch typeName {
+	case "any":
+		return value, nil
+	case "bigint":
+		n, err := toBigInt(value)
+		if err != nil {
+			return fail()
+		}
+		return n, nil
+	case "decimal":
+		d, err := toDecimal(value)
+		if err != nil {
+			return fail()
+		}
+		return d, nil
+	case "int", "float":
+		number, ok := value.(float64)
+		switch n := value.(type) {
+		case *big.Int:
+			number, ok = bigIntFloat(n), true
+		case *Decimal:
+			number, ok = decimalFloat(n), true
+		}
+		if s, isString := value.(string); isString {
+			var err error
+			if number, err = strconv.ParseFloat(strings.TrimSpace(s), 64); errors.Is(err, strconv.ErrRange) {
+				return nil, errcode.Errorf("BRN0508", "cannot cast %q to %s: out of range", s, typeName)
+			} else if err != nil {
+				return nil, errcode.Errorf("BRN0508", "cannot cast %q to %s", s, typeName)
+			}
+			ok = true
+		}
+		if !ok {
+			return fail()
+		}
+		// An int is truncated toward zero, and there is no whole number
+		// for NaN or an infinity, such as a bigint too large for a float
+		if typeName == "int" {
+			if math.IsNaN(number) || math.IsInf(number, 0) {
+				return nil, errcode.Errorf("BRN0508", "cannot cast %s to int: %v is out of range", typeOf(value), number)
+			}
+			return math.Trunc(number), nil
+		}
+		return number, nil
+	case "string":
+		switch value.(type) {
+		case string, float64, *big.Int, *Decimal, bool:
+			return i.toString(value)
+		case *Struct:
+			if str, ok, err := i.structString(value.(*Struct)); ok || err != nil {
+				return str, err
+			}
+		}
+		return fail()
+	case "bool", "bytes":
+		if typeOf(value) != typeName {
+			return fail()
+		}
+		return value, nil
+	}
+
+	if elemType, ok := strings.CutPrefix(typeName, "["); ok {
+		array, isArray := value.(*Array)
+		if !isArray {
+			return fail()
+		}
+		arr := array.Elements
+		elemType = strings.TrimSuffix(elemType, "]")
+		if err := i.allocate(16 * len(arr)); err != nil {
+			return nil, err
+		}
+		result := make([]Value, len(arr))
+		for j, elem := range arr {
+			cast, err := i.cast(elem, elemType)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %w", j, err)
+			}
+			result[j] = cast
+		}
+		return NewArray(result), nil
+	}
+
+	// Maps and structs can be nil
+	if value == nil {
+		return nil, nil
+	}
+
+	if inner, ok := strings.CutPrefix(typeName, "map<"); ok {
+		m, isMap := value.(*Map)
+		if !isMap {
+			return fail()
+		}
+		_, valueType, _ := strings.Cut(strings.TrimSuffix(inner, ">"), ", ")
+		result := NewMap()
+		for _, key := range m.Keys {
+			cast, err := i.cast(m.Entries[key], valueType)
+			if err != nil {
+				return nil, fmt.Errorf("key %v: %w", key, err)
+			}
+			result.Set(key, cast)
+		}
+		return result, nil
+	}
+
+	s, isStruct := value.(*Struct)
+	if !isStruct {
+		return fail()
+	}
+	name, args := genericTypeOf(typeName)
+	if s.TypeName == name {
+		return s, nil
+	}
+	if class, exists := i.classes[s.TypeName]; exists && slices.Contains(class.Interfaces, typeName) {
+		return s, nil
+	}
+
+	// Any other struct, such as an object from parseJSON, is narrowed to the
+	// fields of the type, which it must all have
+	var fields []ast.TypeField
+	if typeDef, exists := i.types[name]; exists {
+		fields = typeDef.Fields
+		if len(args) != len(typeDef.TypeParameters) {
+			return fail()
+		}
+		if len(args) > 0 {
+			fields = make([]ast.TypeField, len(typeDef.Fields))
+			for j, field := range typeDef.Fields {
+				field.Type = substitute(field.Type, typeDef.TypeParameters, args)
+				fields[j] = field
+			}
+		}
+	} else if class, exists := i.classes[typeName]; exists {
+		fields = class.Fields
+	} else {
+		return fail()
+	}
+	if err := i.allocate(16 * len(fields)); err != nil {
+		return nil, err
+	}
+	result := &Struct{TypeName: name, Fields: make(map[string]interface{}, len(fields))}
+	for _, field := range fields {
+		fieldValue, exists := s.Fields[field.Name]
+		if !exists {
+			return nil, errcode.Errorf("BRN0508", "cannot cast %s to %s: missing field %s", typeOf(value), typeName, field.Name)
+		}
+		cast, err := i.cast(fieldValue, field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		result.Fields[field.Name] = cast
+	}
+	return result, nil
+}
+
+// genericTypeOf splits a use of a generic type such as Pair<int, string>
+// into the name of the type and its type arguments, which are none for
+// any other type
+func genericTypeOf(typeName string) (string, []string) {
+	name, inner, ok := strings.Cut(typeName, "<")
+	if !ok {
+		return typeName, nil
+	}
+	inner = strings.TrimSuffix(inner, ">")
+
+	var args []string
+	start, depth := 0, 0
+	for j := 0; j < len(inner); j++ {
+		switch inner[j] {
+		case '(', '<', '[':
+			depth++
+		case ')', '>', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, inner[start:j])
+				start = j + len(", ")
+			}
+		}
+	}
+	return name, append(args, inner[start:])
+}
+
+// substitute replaces the type parameters params in typeName with the
+// type arguments args
+func substitute(typeName string, params, args []string) string {
+	var b strings.Builder
+	start := -1
+	for j, r := range typeName + " " {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = j
+			}
+			continue
+		}
+		if start >= 0 {
+			word := typeName[start:j]
+			if k := slices.Index(params, word); k >= 0 {
+				word = args[k]
+			}
+			b.WriteString(word)
+			start = -1
+		}
+		if j < len(typeName) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,48 @@
+package interpreter_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/burnlang/burn/internal/testutil"
+)
+
+func TestCastLargeNumberToInt(t *testing.T) {
+	got := testutil.Run(t, `
+print(1e30 as int)
+print("1e30" as int)
+print(-2.7 as int)
+print(" 42.9 " as int)
+`)
+	if want := "1e+30\n1e+30\n-2\n42\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCastToIntOutOfRange(t *testing.T) {
+	for _, source := range []string{
+		`print("1e400" as int)`,
+		`print("NaN" as int)`,
+		`print("-Inf" as int)`,
+	} {
+		if _, err := testutil.RunErr(t, source); err == nil {
+			t.Errorf("%s: no error", source)
+		}
+	}
+}
+
+func TestCastBigIntToInt(t *testing.T) {
+	got := testutil.Run(t, `
+var n = toBigInt("100000000000000000000000")
+print(n as int)
+print(toInt(n))
+print(-n as int)
+`)
+	if want := "1e+23\n1e+23\n-1e+23\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	huge := `print(toBigInt("1` + strings.Repeat("0", 400) + `") as int)`
+	if _, err := testutil.RunErr(t, huge); err == nil {
+		t.Error("a bigint too large for a float was cast to int")
+	}
+}
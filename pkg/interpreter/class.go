@@ -1,9 +1,13 @@
 package interpreter
 
 import (
-	"fmt"
+	"maps"
+	"math/big"
+	"strings"
 
 	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
+	"github.com/burnlang/burn/pkg/suggest"
 )
 
 type Class struct {
@@ -12,6 +16,14 @@ type Class struct {
 	Statics    map[string]*ast.FunctionDeclaration
 	Fields     []ast.TypeField
 	Interfaces []string
+
+	// Defaults are the initial values of the fields declared with one
+	Defaults map[string]ast.Expression
+
+	// StaticValues are the values of the static fields of the class, and
+	// StaticConsts the ones declared with static const
+	StaticValues map[string]Value
+	StaticConsts map[string]bool
 }
 
 func NewClass(name string) *Class {
@@ -21,6 +33,10 @@ func NewClass(name string) *Class {
 		Statics:    make(map[string]*ast.FunctionDeclaration),
 		Fields:     []ast.TypeField{},
 		Interfaces: []string{},
+		Defaults:   make(map[string]ast.Expression),
+
+		StaticValues: make(map[string]Value),
+		StaticConsts: make(map[string]bool),
 	}
 }
 
@@ -28,6 +44,30 @@ func (c *Class) AddMethod(name string, fn *ast.FunctionDeclaration) {
 	c.Methods[name] = fn
 }
 
+// className returns the name expr refers to when it names a class rather
+// than a variable, whose struct value is the receiver of the method called
+func (i *Interpreter) className(expr ast.Expression) (string, bool) {
+	name, ok := expr.(*ast.VariableExpression)
+	if !ok {
+		return "", false
+	}
+	if _, isVariable := i.environment.Get(name.Name); isVariable {
+		return "", false
+	}
+	return name.Name, true
+}
+
+// addMethod registers a method declared on a type outside any class, as in
+// fun (p: Point) norm(): float, with the methods of a class of that name
+func (i *Interpreter) addMethod(fn *ast.FunctionDeclaration) {
+	class, exists := i.classes[fn.Receiver]
+	if !exists {
+		class = NewClass(fn.Receiver)
+		i.classes[fn.Receiver] = class
+	}
+	class.AddMethod(fn.Name, fn)
+}
+
 func (c *Class) AddStatic(name string, fn *ast.FunctionDeclaration) {
 	c.Statics[name] = fn
 }
@@ -39,27 +79,165 @@ func (c *Class) AddField(name string, typeName string) {
 	})
 }
 
+// callMethod calls an instance method of class. When its first argument is
+// an instance of the class, the body sees it as this as well.
+func (i *Interpreter) callMethod(class *Class, method *ast.FunctionDeclaration, args []Value) (Value, error) {
+	if len(args) > 0 {
+		if receiver, ok := args[0].(*Struct); ok && receiver.TypeName == class.Name {
+			scope := NewEnvironment(nil)
+			scope.defineSlot(0, "this", receiver, false)
+			return i.executeClosure(method, scope, args)
+		}
+	}
+	return i.executeFunction(method, args)
+}
+
+// construct makes an instance of class for Name(...) or new Name(...). Each
+// field starts with its default value, or the zero value of its type, and
+// the init method, if there is one, is called with the instance and args.
+func (i *Interpreter) construct(class *Class, args []Value) (Value, error) {
+	fields, err := i.initialFields(class)
+	if err != nil {
+		return nil, err
+	}
+	instance := &Struct{TypeName: class.Name, Fields: fields}
+
+	init, exists := class.Methods["init"]
+	if !exists {
+		if len(args) != 0 {
+			return nil, errcode.Errorf("BRN0510", "class %s has no init method and takes no arguments", class.Name)
+		}
+		return instance, nil
+	}
+	if _, err := i.callMethod(class, init, append([]Value{instance}, args...)); err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+// initialFields returns the fields of a new instance of class. Defaults see
+// what a function body sees rather than the variables of the caller.
+func (i *Interpreter) initialFields(class *Class) (map[string]interface{}, error) {
+	if err := i.allocate(16 * len(class.Fields)); err != nil {
+		return nil, err
+	}
+	prevEnv := i.environment
+	i.environment = NewEnvironment(nil)
+	defer func() { i.environment = prevEnv }()
+
+	fields := make(map[string]interface{}, len(class.Fields))
+	for _, field := range class.Fields {
+		value := ZeroValue(field.Type)
+		if def, exists := class.Defaults[field.Name]; exists {
+			var err error
+			value, err = i.evaluateExpression(def)
+			if err != nil {
+				return nil, err
+			}
+		}
+		fields[field.Name] = value
+	}
+	return fields, nil
+}
+
+// initStatics gives the static fields of a class their values. Like
+// defaults, the values see what a function body sees.
+func (i *Interpreter) initStatics(decl *ast.ClassDeclaration) error {
+	class, exists := i.classes[decl.Name]
+	if !exists || len(decl.StaticFields) == 0 {
+		return nil
+	}
+	prevEnv := i.environment
+	i.environment = NewEnvironment(nil)
+	defer func() { i.environment = prevEnv }()
+
+	for _, field := range decl.StaticFields {
+		value, err := i.evaluateExpression(field.Value)
+		if err != nil {
+			return err
+		}
+		class.StaticValues[field.Name] = value
+		if field.IsConst {
+			class.StaticConsts[field.Name] = true
+		}
+	}
+	return nil
+}
+
+// staticField returns the class of a static field named as in
+// Config.VERSION, if expr names one
+func (i *Interpreter) staticField(expr *ast.GetExpression) (*Class, bool) {
+	name, ok := i.className(expr.Object)
+	if !ok {
+		return nil, false
+	}
+	class, exists := i.classes[name]
+	if !exists {
+		return nil, false
+	}
+	_, exists = class.StaticValues[expr.Name]
+	return class, exists
+}
+
+// setStatic assigns to a static field that is not a constant
+func (c *Class) setStatic(name string, value Value) error {
+	if c.StaticConsts[name] {
+		return errcode.Errorf("BRN0516", "cannot assign to constant %s.%s", c.Name, name)
+	}
+	c.StaticValues[name] = value
+	return nil
+}
+
+// ZeroValue returns the value of a field or variable of type typeName that
+// has no default or initial value: nothing for types that can be nil
+func ZeroValue(typeName string) Value {
+	switch {
+	case strings.HasSuffix(typeName, "?"):
+		return nil
+	case typeName == "int" || typeName == "float":
+		return float64(0)
+	case typeName == "bigint":
+		return new(big.Int)
+	case typeName == "decimal":
+		return &Decimal{Digits: new(big.Int)}
+	case typeName == "string":
+		return ""
+	case typeName == "bool":
+		return false
+	case typeName == "bytes":
+		return []byte{}
+	case strings.HasPrefix(typeName, "["):
+		return NewArray([]Value{})
+	case strings.HasPrefix(typeName, "map<"):
+		return NewMap()
+	case strings.HasPrefix(typeName, "Option<"):
+		return &Option{}
+	}
+	return nil
+}
+
 func (c *Class) ImplementsInterface(name string) {
 	c.Interfaces = append(c.Interfaces, name)
 }
 
 func (c *Class) Call(methodName string, interpreter *Interpreter, args []Value) (Value, error) {
 	if method, exists := c.Methods[methodName]; exists {
-		return interpreter.executeFunction(method, args)
+		return interpreter.callMethod(c, method, args)
 	}
 
 	if static, exists := c.Statics[methodName]; exists {
 		return interpreter.executeFunction(static, args)
 	}
 
-	builtinMethodName := fmt.Sprintf("%s.%s", c.Name, methodName)
-	if builtinFunc, exists := interpreter.environment[builtinMethodName]; exists {
+	builtinMethodName := interpreter.qualifiedName(c.Name, methodName)
+	if builtinFunc, exists := interpreter.builtins.Get(builtinMethodName); exists {
 		if bf, ok := builtinFunc.(*BuiltinFunction); ok {
-			return bf.Call(args)
+			return interpreter.callBuiltin(builtinMethodName, bf, args)
 		}
 	}
 
-	return nil, fmt.Errorf("undefined method '%s' in class '%s'", methodName, c.Name)
+	return nil, errcode.Errorf("BRN0509", "undefined method '%s' in class '%s'%s", methodName, c.Name,
+		suggest.Hint(methodName, maps.Keys(c.Methods), maps.Keys(c.Statics)))
 }
 
 func (c *Class) CallStatic(methodName string, interpreter *Interpreter, args []Value) (Value, error) {
@@ -67,14 +245,14 @@ func (c *Class) CallStatic(methodName string, interpreter *Interpreter, args []V
 		return interpreter.executeFunction(static, args)
 	}
 
-	builtinFuncName := fmt.Sprintf("%s.%s", c.Name, methodName)
-	if builtinFunc, exists := interpreter.environment[builtinFuncName]; exists {
+	builtinFuncName := interpreter.qualifiedName(c.Name, methodName)
+	if builtinFunc, exists := interpreter.builtins.Get(builtinFuncName); exists {
 		if bf, ok := builtinFunc.(*BuiltinFunction); ok {
-			return bf.Call(args)
+			return interpreter.callBuiltin(builtinFuncName, bf, args)
 		}
 	}
 
-	return nil, fmt.Errorf("undefined static method '%s' in class '%s'", methodName, c.Name)
+	return nil, errcode.Errorf("BRN0509", "undefined static method '%s' in class '%s'", methodName, c.Name)
 }
 
 func (c *Class) ToTypeDefinition() *ast.TypeDefinition {
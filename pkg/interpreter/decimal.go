@@ -0,0 +1,314 @@
+package interpreter
+
+import (
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/burnlang/burn/pkg/errcode"
+	"github.com/burnlang/burn/pkg/lexer"
+)
+
+// Decimal is a Burn decimal, the exact number Digits / 10^Scale. Like
+// bigints, decimals are never changed once made. Scale is never negative,
+// and keeps the zeros a number was written with, so 1.50d shows as 1.50.
+type Decimal struct {
+	Digits *big.Int
+	Scale  int
+}
+
+// divisionScale is the least number of digits after the decimal point that
+// / keeps when the quotient of two decimals does not end sooner
+const divisionScale = 20
+
+// roundingModes are the ways roundDecimal can round, by the rule applied to
+// the digits it drops
+var roundingModes = []string{"halfEven", "halfUp", "halfDown", "up", "down", "ceiling", "floor"}
+
+func (d *Decimal) String() string {
+	digits := new(big.Int).Abs(d.Digits).String()
+	sign := ""
+	if d.Digits.Sign() < 0 {
+		sign = "-"
+	}
+	if d.Scale == 0 {
+		return sign + digits
+	}
+	if len(digits) <= d.Scale {
+		digits = strings.Repeat("0", d.Scale-len(digits)+1) + digits
+	}
+	point := len(digits) - d.Scale
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// pow10 returns 10^n
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// rescale returns the digits of d with scale digits after the decimal
+// point, which must be at least d.Scale
+func (d *Decimal) rescale(scale int) *big.Int {
+	if scale == d.Scale {
+		return d.Digits
+	}
+	return new(big.Int).Mul(d.Digits, pow10(scale-d.Scale))
+}
+
+// Cmp compares d and other as -1, 0 or +1, whatever their scales
+func (d *Decimal) Cmp(other *Decimal) int {
+	scale := max(d.Scale, other.Scale)
+	return d.rescale(scale).Cmp(other.rescale(scale))
+}
+
+// Round returns d with at most places digits after the decimal point,
+// rounding the dropped digits by mode, one of the roundingModes
+func (d *Decimal) Round(places int, mode string) (*Decimal, error) {
+	if places < 0 {
+		return nil, errcode.Errorf("BRN0510", "cannot round to %d places", places)
+	}
+	if !slices.Contains(roundingModes, mode) {
+		return nil, errcode.Errorf("BRN0510", "unknown rounding mode %q, expected one of %s", mode, strings.Join(roundingModes, ", "))
+	}
+	if d.Scale <= places {
+		return d, nil
+	}
+	quotient, remainder := new(big.Int).QuoRem(d.Digits, pow10(d.Scale-places), new(big.Int))
+	if remainder.Sign() == 0 {
+		return &Decimal{Digits: quotient, Scale: places}, nil
+	}
+
+	// half compares the dropped digits with half of a unit in the last
+	// place kept
+	half := new(big.Int).Abs(remainder)
+	half.Mul(half, big.NewInt(2))
+	half.Sub(half, pow10(d.Scale-places))
+	positive := d.Digits.Sign() > 0
+
+	var away bool
+	switch mode {
+	case "halfEven":
+		away = half.Sign() > 0 || half.Sign() == 0 && quotient.Bit(0) == 1
+	case "halfUp":
+		away = half.Sign() >= 0
+	case "halfDown":
+		away = half.Sign() > 0
+	case "up":
+		away = true
+	case "down":
+		away = false
+	case "ceiling":
+		away = positive
+	case "floor":
+		away = !positive
+	}
+	if away {
+		if positive {
+			quotient.Add(quotient, big.NewInt(1))
+		} else {
+			quotient.Sub(quotient, big.NewInt(1))
+		}
+	}
+	return &Decimal{Digits: quotient, Scale: places}, nil
+}
+
+// Format returns d with exactly places digits after the decimal point,
+// rounded half to even
+func (d *Decimal) Format(places int) (string, error) {
+	rounded, err := d.Round(places, "halfEven")
+	if err != nil {
+		return "", err
+	}
+	return (&Decimal{Digits: rounded.rescale(places), Scale: places}).String(), nil
+}
+
+// decimalOf converts an operand of an operation on decimals, a decimal or
+// a whole number, to a decimal
+func decimalOf(value Value) (*Decimal, bool) {
+	if d, ok := value.(*Decimal); ok {
+		return d, true
+	}
+	n, ok := bigIntOf(value)
+	if !ok {
+		return nil, false
+	}
+	return &Decimal{Digits: n}, true
+}
+
+// decimalOperation evaluates a binary operation on two decimals or a
+// decimal and a whole number. It reports false when the operands are not
+// these, leaving the operation to the other values.
+func (i *Interpreter) decimalOperation(operator string, left, right Value) (Value, bool, error) {
+	_, lDecimal := left.(*Decimal)
+	_, rDecimal := right.(*Decimal)
+	if !lDecimal && !rDecimal {
+		return nil, false, nil
+	}
+	l, lOk := decimalOf(left)
+	r, rOk := decimalOf(right)
+	if !lOk || !rOk {
+		return nil, false, nil
+	}
+
+	scale := max(l.Scale, r.Scale)
+	var result *Decimal
+	switch operator {
+	case "==":
+		return l.Cmp(r) == 0, true, nil
+	case "!=":
+		return l.Cmp(r) != 0, true, nil
+	case "<":
+		return l.Cmp(r) < 0, true, nil
+	case ">":
+		return l.Cmp(r) > 0, true, nil
+	case "<=":
+		return l.Cmp(r) <= 0, true, nil
+	case ">=":
+		return l.Cmp(r) >= 0, true, nil
+	case "+":
+		result = &Decimal{Digits: new(big.Int).Add(l.rescale(scale), r.rescale(scale)), Scale: scale}
+	case "-":
+		result = &Decimal{Digits: new(big.Int).Sub(l.rescale(scale), r.rescale(scale)), Scale: scale}
+	case "*":
+		result = &Decimal{Digits: new(big.Int).Mul(l.Digits, r.Digits), Scale: l.Scale + r.Scale}
+	case "/":
+		if r.Digits.Sign() == 0 {
+			return nil, true, errcode.Errorf("BRN0501", "division by zero")
+		}
+		result = divideDecimals(l, r)
+	case "%":
+		if r.Digits.Sign() == 0 {
+			return nil, true, errcode.Errorf("BRN0501", "modulo by zero")
+		}
+		result = &Decimal{Digits: new(big.Int).Rem(l.rescale(scale), r.rescale(scale)), Scale: scale}
+	default:
+		return nil, false, nil
+	}
+	if err := i.allocate(len(result.Digits.Bits()) * 8); err != nil {
+		return nil, true, err
+	}
+	return result, true, nil
+}
+
+// divideDecimals returns l / r, rounded half to even to divisionScale
+// digits after the decimal point unless it ends sooner. It keeps at least
+// as many digits as either operand has.
+func divideDecimals(l, r *Decimal) *Decimal {
+	keep := max(l.Scale, r.Scale)
+	scale := max(keep, divisionScale)
+	// l / r with scale digits is l.Digits * 10^(scale - l.Scale + r.Scale)
+	// / r.Digits, plus one more digit to round by
+	numerator := new(big.Int).Mul(l.Digits, pow10(scale-l.Scale+r.Scale+1))
+	exact := &Decimal{Digits: new(big.Int).Quo(numerator, r.Digits), Scale: scale + 1}
+	if new(big.Int).Rem(numerator, r.Digits).Sign() != 0 {
+		// A digit beyond the one to round by is not zero, so a 5 there is
+		// more than half
+		exact.Digits.Mul(exact.Digits, big.NewInt(10))
+		if exact.Digits.Sign() < 0 {
+			exact.Digits.Sub(exact.Digits, big.NewInt(1))
+		} else {
+			exact.Digits.Add(exact.Digits, big.NewInt(1))
+		}
+		exact.Scale++
+	}
+	result, _ := exact.Round(scale, "halfEven")
+
+	// Zeros past the digits of the operands only pad the quotient
+	ten := big.NewInt(10)
+	for result.Scale > keep {
+		quotient, remainder := new(big.Int).QuoRem(result.Digits, ten, new(big.Int))
+		if remainder.Sign() != 0 {
+			break
+		}
+		result = &Decimal{Digits: quotient, Scale: result.Scale - 1}
+	}
+	return result
+}
+
+// toDecimal converts a number or the text of a decimal number to a decimal,
+// for toDecimal and as decimal. A float becomes the decimal of the digits
+// it shows, so 0.1 becomes 0.1d and not the binary fraction near it.
+func toDecimal(value Value) (*Decimal, error) {
+	var text string
+	switch v := value.(type) {
+	case *Decimal:
+		return v, nil
+	case *big.Int:
+		return &Decimal{Digits: v}, nil
+	case float64:
+		text = strconv.FormatFloat(v, 'g', -1, 64)
+	case string:
+		text = strings.TrimSpace(v)
+	default:
+		return nil, errcode.Errorf("BRN0512", "cannot convert %s to decimal", typeOf(value))
+	}
+
+	sign := ""
+	if rest, negative := strings.CutPrefix(text, "-"); negative {
+		sign, text = "-", rest
+	}
+	// ParseDecimal takes the digits of a literal the lexer checked, so the
+	// text must look like one without underscores or a suffix
+	if text == "" || strings.Contains(text, "_") || !lexer.IsFloat(text) && strings.Trim(text, "0123456789") != "" {
+		return nil, errcode.Errorf("BRN0512", "cannot convert %v to decimal", value)
+	}
+	digits, scale, err := lexer.ParseDecimal(text)
+	if err != nil {
+		return nil, errcode.Errorf("BRN0512", "cannot convert %v to decimal", value)
+	}
+	if sign == "-" {
+		digits.Neg(digits)
+	}
+	return &Decimal{Digits: digits, Scale: scale}, nil
+}
+
+// decimalFloat returns the float nearest a decimal, for toFloat, toInt and
+// casts to numbers
+func decimalFloat(d *Decimal) float64 {
+	f, _ := new(big.Rat).SetFrac(d.Digits, pow10(d.Scale)).Float64()
+	return f
+}
+
+func (i *Interpreter) addDecimalBuiltins() {
+	i.builtins.values["toDecimal"] = &BuiltinFunction{
+		Name: "toDecimal",
+		Fn: func(args []Value) (Value, error) {
+			if len(args) != 1 {
+				return nil, errcode.Errorf("BRN0510", "toDecimal expects exactly one argument")
+			}
+			return toDecimal(args[0])
+		},
+	}
+
+	i.builtins.values["roundDecimal"] = &BuiltinFunction{
+		Name: "roundDecimal",
+		Fn: func(args []Value) (Value, error) {
+			if len(args) != 3 {
+				return nil, errcode.Errorf("BRN0510", "roundDecimal expects exactly 3 arguments")
+			}
+			d, dOk := args[0].(*Decimal)
+			places, pOk := args[1].(float64)
+			mode, mOk := args[2].(string)
+			if !dOk || !pOk || !mOk {
+				return nil, errcode.Errorf("BRN0510", "roundDecimal expects a decimal, a number of places and a rounding mode")
+			}
+			return d.Round(int(places), mode)
+		},
+	}
+
+	i.builtins.values["formatDecimal"] = &BuiltinFunction{
+		Name: "formatDecimal",
+		Fn: func(args []Value) (Value, error) {
+			if len(args) != 2 {
+				return nil, errcode.Errorf("BRN0510", "formatDecimal expects exactly 2 arguments")
+			}
+			d, dOk := args[0].(*Decimal)
+			places, pOk := args[1].(float64)
+			if !dOk || !pOk {
+				return nil, errcode.Errorf("BRN0510", "formatDecimal expects a decimal and a number of places")
+			}
+			return d.Format(int(places))
+		},
+	}
+}
@@ -1,16 +1,21 @@
 package interpreter
 
 import (
-	"fmt"
-	"strconv"
+	"maps"
+	"math"
+	"math/big"
 	"strings"
 
 	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
+	"github.com/burnlang/burn/pkg/lexer"
+	"github.com/burnlang/burn/pkg/suggest"
 )
 
 func (i *Interpreter) evaluateExpression(expr ast.Expression) (Value, error) {
+	i.host.nodes++
 	if expr != nil {
-		i.setErrorPos(expr.Pos())
+		i.setErrorAt(expr)
 	}
 
 	switch e := expr.(type) {
@@ -18,25 +23,43 @@ func (i *Interpreter) evaluateExpression(expr ast.Expression) (Value, error) {
 		return i.evaluateBinary(e)
 	case *ast.UnaryExpression:
 		return i.evaluateUnary(e)
+	case *ast.ThisExpression:
+		if value, exists := i.environment.Get("this"); exists {
+			return value, nil
+		}
+		return nil, errcode.Errorf("BRN0515", "this used outside of a method")
 	case *ast.VariableExpression:
-		if value, exists := i.environment[e.Name]; exists {
+		if value, exists := i.variable(e.Name, e.Binding); exists {
 			return value, nil
 		}
-		return nil, fmt.Errorf("undefined variable: %s", e.Name)
+		if fn, exists := i.functions[e.Name]; exists {
+			return &Function{Declaration: fn}, nil
+		}
+		return nil, errcode.Errorf("BRN0509", "undefined variable: %s%s", e.Name, i.nameHint(e.Name))
 	case *ast.AssignmentExpression:
 		value, err := i.evaluateExpression(e.Value)
 		if err != nil {
 			return nil, err
 		}
-		i.environment[e.Name] = value
+		if err := i.assign(e.Name, e.Binding, value); err != nil {
+			return nil, err
+		}
 		return value, nil
 	case *ast.CallExpression:
 		return i.evaluateCall(e)
+	case *ast.LambdaExpression:
+		return i.evaluateLambda(e)
 	case *ast.GetExpression:
+		if class, ok := i.staticField(e); ok {
+			return class.StaticValues[e.Name], nil
+		}
 		object, err := i.evaluateExpression(e.Object)
 		if err != nil {
 			return nil, err
 		}
+		if object == nil && e.Optional {
+			return nil, nil
+		}
 
 		if structObj, ok := object.(*Struct); ok {
 			if value, exists := structObj.Fields[e.Name]; exists {
@@ -45,7 +68,7 @@ func (i *Interpreter) evaluateExpression(expr ast.Expression) (Value, error) {
 				}
 				return value, nil
 			}
-			return nil, fmt.Errorf("undefined field '%s' on struct of type '%s'",
+			return nil, errcode.Errorf("BRN0509", "undefined field '%s' on struct of type '%s'",
 				e.Name, structObj.TypeName)
 		}
 
@@ -56,11 +79,24 @@ func (i *Interpreter) evaluateExpression(expr ast.Expression) (Value, error) {
 				}
 				return value, nil
 			}
-			return nil, fmt.Errorf("undefined field: %s", e.Name)
+			return nil, errcode.Errorf("BRN0509", "undefined field: %s", e.Name)
 		}
 
-		return nil, fmt.Errorf("cannot access field on non-struct value")
+		if object == nil {
+			return nil, errcode.Errorf("BRN0503", "cannot access field '%s' of nil", e.Name)
+		}
+		return nil, errcode.Errorf("BRN0511", "cannot access field on non-struct value")
 	case *ast.SetExpression:
+		if class, ok := i.staticField(&ast.GetExpression{Object: e.Object, Name: e.Name}); ok {
+			value, err := i.evaluateExpression(e.Value)
+			if err != nil {
+				return nil, err
+			}
+			if err := class.setStatic(e.Name, value); err != nil {
+				return nil, err
+			}
+			return value, nil
+		}
 		object, err := i.evaluateExpression(e.Object)
 		if err != nil {
 			return nil, err
@@ -77,9 +113,29 @@ func (i *Interpreter) evaluateExpression(expr ast.Expression) (Value, error) {
 			obj[e.Name] = value
 			return value, nil
 		}
-		return nil, fmt.Errorf("cannot set field on non-struct value")
+		if object == nil {
+			return nil, errcode.Errorf("BRN0503", "cannot set field '%s' of nil", e.Name)
+		}
+		return nil, errcode.Errorf("BRN0511", "cannot set field on non-struct value")
 	case *ast.LiteralExpression:
-		return i.evaluateLiteral(e)
+		return i.literal(e)
+	case *ast.CastExpression:
+		return i.evaluateCast(e)
+	case *ast.NilExpression:
+		return nil, nil
+	case *ast.ConditionalExpression:
+		condition, err := i.evaluateExpression(e.Condition)
+		if err != nil {
+			return nil, err
+		}
+		cond, ok := condition.(bool)
+		if !ok {
+			return nil, errcode.Errorf("BRN0511", "condition of '?' must be a bool, got %T", condition)
+		}
+		if cond {
+			return i.evaluateExpression(e.Then)
+		}
+		return i.evaluateExpression(e.Else)
 	case *ast.StructLiteralExpression:
 		fields := make(map[string]interface{})
 		for name, value := range e.Fields {
@@ -89,20 +145,29 @@ func (i *Interpreter) evaluateExpression(expr ast.Expression) (Value, error) {
 			}
 			fields[name] = evaluated
 		}
+		// A value of a generic type such as Box<int> has the type Box
+		typeName, _, _ := strings.Cut(e.Type, "<")
 		return &Struct{
-			TypeName: e.Type,
+			TypeName: typeName,
 			Fields:   fields,
 		}, nil
 	case *ast.ArrayLiteralExpression:
-		elements := make([]Value, 0, len(e.Elements))
-		for _, element := range e.Elements {
-			value, err := i.evaluateExpression(element)
-			if err != nil {
-				return nil, err
-			}
-			elements = append(elements, value)
+		elements, err := i.evaluateArrayElements(e.Elements)
+		if err != nil {
+			return nil, err
+		}
+		if err := i.allocate(16 * len(elements)); err != nil {
+			return nil, err
 		}
-		return elements, nil
+		return NewArray(elements), nil
+	case *ast.RangeExpression:
+		return i.evaluateRange(e)
+	case *ast.MapLiteralExpression:
+		return i.evaluateMapLiteral(e)
+	case *ast.IndexAssignmentExpression:
+		return i.evaluateIndexAssignment(e)
+	case *ast.SliceExpression:
+		return i.evaluateSlice(e)
 	case *ast.IndexExpression:
 		array, err := i.evaluateExpression(e.Array)
 		if err != nil {
@@ -113,26 +178,59 @@ func (i *Interpreter) evaluateExpression(expr ast.Expression) (Value, error) {
 		if err != nil {
 			return nil, err
 		}
+		return i.Index(array, index)
+	default:
+		return nil, errcode.Errorf("BRN0515", "unknown expression type: %T", expr)
+	}
+}
 
-		indexInt, ok := index.(float64)
-		if !ok {
-			return nil, fmt.Errorf("array index must be a number")
+// Index returns the element of an array, character of a string, byte of
+// bytes or value of a map at index
+func (i *Interpreter) Index(array, index Value) (Value, error) {
+	if m, ok := array.(*Map); ok {
+		if err := checkKey(index); err != nil {
+			return nil, err
 		}
+		value, exists := m.Get(index)
+		if !exists {
+			return nil, errcode.Errorf("BRN0502", "map has no key %v", index)
+		}
+		return value, nil
+	}
 
-		arrayValue, ok := array.([]Value)
-		if !ok {
-			return nil, fmt.Errorf("cannot index into non-array value")
+	indexInt, ok := index.(float64)
+	if !ok {
+		return nil, errcode.Errorf("BRN0511", "array index must be a number")
+	}
+
+	if s, ok := array.(string); ok {
+		chars := []rune(s)
+		idx := int(indexInt)
+		if idx < 0 || idx >= len(chars) {
+			return nil, errcode.Errorf("BRN0502", "string index out of bounds: %d", idx)
 		}
+		return character(chars[idx]), nil
+	}
 
+	if b, ok := array.([]byte); ok {
 		idx := int(indexInt)
-		if idx < 0 || idx >= len(arrayValue) {
-			return nil, fmt.Errorf("array index out of bounds: %d", idx)
+		if idx < 0 || idx >= len(b) {
+			return nil, errcode.Errorf("BRN0502", "bytes index out of bounds: %d", idx)
 		}
+		return float64(b[idx]), nil
+	}
 
-		return arrayValue[idx], nil
-	default:
-		return nil, fmt.Errorf("unknown expression type: %T", expr)
+	arrayValue, ok := array.(*Array)
+	if !ok {
+		return nil, errcode.Errorf("BRN0511", "cannot index into non-array value")
+	}
+
+	idx := int(indexInt)
+	if idx < 0 || idx >= len(arrayValue.Elements) {
+		return nil, errcode.Errorf("BRN0502", "array index out of bounds: %d", idx)
 	}
+
+	return arrayValue.Elements[idx], nil
 }
 
 func (i *Interpreter) evaluateBinary(expr *ast.BinaryExpression) (Value, error) {
@@ -141,12 +239,25 @@ func (i *Interpreter) evaluateBinary(expr *ast.BinaryExpression) (Value, error)
 		return nil, err
 	}
 
+	// The right operand of ?? is evaluated only when the left is nil
+	if expr.Operator == "??" {
+		if left != nil {
+			return left, nil
+		}
+		return i.evaluateExpression(expr.Right)
+	}
+
 	right, err := i.evaluateExpression(expr.Right)
 	if err != nil {
 		return nil, err
 	}
+	return i.Binary(expr.Operator, left, right, expr.IntDivision)
+}
 
-	switch expr.Operator {
+// Binary applies a binary operator other than ?? to the values of its
+// operands. intDivision makes / truncate, as it does for ints.
+func (i *Interpreter) Binary(operator string, left, right Value, intDivision bool) (Value, error) {
+	switch operator {
 	case "+", "-", "*", "/", "<", ">", "<=", ">=", "==", "!=":
 		if lInt, lok := left.(int); lok {
 			left = float64(lInt)
@@ -155,123 +266,153 @@ func (i *Interpreter) evaluateBinary(expr *ast.BinaryExpression) (Value, error)
 			right = float64(rInt)
 		}
 	}
+	if result, ok, err := i.bigIntOperation(operator, left, right); ok {
+		return result, err
+	}
+	if result, ok, err := i.decimalOperation(operator, left, right); ok {
+		return result, err
+	}
 
-	switch expr.Operator {
+	switch operator {
 	case "&&":
 		if lBool, lok := left.(bool); lok {
 			if rBool, rok := right.(bool); rok {
 				return lBool && rBool, nil
 			}
 		}
-		return nil, fmt.Errorf("cannot perform logical AND on non-boolean values")
+		return nil, errcode.Errorf("BRN0511", "cannot perform logical AND on non-boolean values")
 	case "||":
 		if lBool, lok := left.(bool); lok {
 			if rBool, rok := right.(bool); rok {
 				return lBool || rBool, nil
 			}
 		}
-		return nil, fmt.Errorf("cannot perform logical OR on non-boolean values")
+		return nil, errcode.Errorf("BRN0511", "cannot perform logical OR on non-boolean values")
 	case "+":
 		if lNum, lOk := left.(float64); lOk {
 			if rNum, rOk := right.(float64); rOk {
-				return lNum + rNum, nil
+				return number(lNum + rNum), nil
 			}
 		}
 		if lStr, lOk := left.(string); lOk {
 			if rStr, rOk := right.(string); rOk {
+				if err := i.allocate(len(lStr) + len(rStr)); err != nil {
+					return nil, err
+				}
 				return lStr + rStr, nil
 			}
 		}
-		return nil, fmt.Errorf("invalid operator %s for types %T and %T", expr.Operator, left, right)
+		if joined, ok, err := i.concatStruct(left, right); ok || err != nil {
+			return joined, err
+		}
+		if lBytes, lOk := left.([]byte); lOk {
+			if rBytes, rOk := right.([]byte); rOk {
+				if err := i.allocate(len(lBytes) + len(rBytes)); err != nil {
+					return nil, err
+				}
+				return append(lBytes[:len(lBytes):len(lBytes)], rBytes...), nil
+			}
+		}
+		return nil, errcode.Errorf("BRN0511", "invalid operator %s for types %T and %T", operator, left, right)
 	case "-":
 		if lNum, lOk := left.(float64); lOk {
 			if rNum, rOk := right.(float64); rOk {
-				return lNum - rNum, nil
+				return number(lNum - rNum), nil
 			}
 		}
-		return nil, fmt.Errorf("invalid operator %s for types %T and %T", expr.Operator, left, right)
+		return nil, errcode.Errorf("BRN0511", "invalid operator %s for types %T and %T", operator, left, right)
 	case "*":
 		if lNum, lOk := left.(float64); lOk {
 			if rNum, rOk := right.(float64); rOk {
-				return lNum * rNum, nil
+				return number(lNum * rNum), nil
 			}
 		}
-		return nil, fmt.Errorf("invalid operator %s for types %T and %T", expr.Operator, left, right)
+		return nil, errcode.Errorf("BRN0511", "invalid operator %s for types %T and %T", operator, left, right)
 	case "/":
 		if lNum, lOk := left.(float64); lOk {
 			if rNum, rOk := right.(float64); rOk {
 				if rNum == 0 {
-					return nil, fmt.Errorf("division by zero")
+					return nil, errcode.Errorf("BRN0501", "division by zero")
+				}
+				if intDivision {
+					return number(math.Trunc(lNum / rNum)), nil
 				}
-				return lNum / rNum, nil
+				return number(lNum / rNum), nil
 			}
 		}
-		return nil, fmt.Errorf("invalid operator %s for types %T and %T", expr.Operator, left, right)
+		return nil, errcode.Errorf("BRN0511", "invalid operator %s for types %T and %T", operator, left, right)
 	case "%":
 		if lNum, lOk := left.(float64); lOk {
 			if rNum, rOk := right.(float64); rOk {
 				if rNum == 0 {
-					return nil, fmt.Errorf("modulo by zero")
+					return nil, errcode.Errorf("BRN0501", "modulo by zero")
 				}
-				return float64(int(lNum) % int(rNum)), nil
+				// The remainder has the sign of the dividend, as the
+				// quotient of / is truncated toward zero
+				return number(math.Mod(lNum, rNum)), nil
 			}
 		}
-		return nil, fmt.Errorf("invalid operator %s for types %T and %T", expr.Operator, left, right)
-	case "==":
-		if lNum, lOk := left.(float64); lOk {
-			if rNum, rOk := right.(float64); rOk {
-				return lNum == rNum, nil
-			}
+		return nil, errcode.Errorf("BRN0511", "invalid operator %s for types %T and %T", operator, left, right)
+	case "==", "!=":
+		equal, ok, err := structsEqual(left, right)
+		if !ok {
+			equal, err = deepEqual(left, right)
 		}
-		if lStr, lOk := left.(string); lOk {
-			if rStr, rOk := right.(string); rOk {
-				return lStr == rStr, nil
-			}
+		if err != nil {
+			return nil, err
 		}
-		return nil, fmt.Errorf("invalid operator %s for types %T and %T", expr.Operator, left, right)
-	case "!=":
+		return equal == (operator == "=="), nil
+	case "<":
 		if lNum, lOk := left.(float64); lOk {
 			if rNum, rOk := right.(float64); rOk {
-				return lNum != rNum, nil
+				return lNum < rNum, nil
 			}
 		}
 		if lStr, lOk := left.(string); lOk {
 			if rStr, rOk := right.(string); rOk {
-				return lStr != rStr, nil
+				return lStr < rStr, nil
 			}
 		}
-		return nil, fmt.Errorf("invalid operator %s for types %T and %T", expr.Operator, left, right)
-	case "<":
-		if lNum, lOk := left.(float64); lOk {
-			if rNum, rOk := right.(float64); rOk {
-				return lNum < rNum, nil
-			}
-		}
-		return nil, fmt.Errorf("invalid operator %s for types %T and %T", expr.Operator, left, right)
+		return nil, errcode.Errorf("BRN0511", "invalid operator %s for types %T and %T", operator, left, right)
 	case ">":
 		if lNum, lOk := left.(float64); lOk {
 			if rNum, rOk := right.(float64); rOk {
 				return lNum > rNum, nil
 			}
 		}
-		return nil, fmt.Errorf("invalid operator %s for types %T and %T", expr.Operator, left, right)
+		if lStr, lOk := left.(string); lOk {
+			if rStr, rOk := right.(string); rOk {
+				return lStr > rStr, nil
+			}
+		}
+		return nil, errcode.Errorf("BRN0511", "invalid operator %s for types %T and %T", operator, left, right)
 	case "<=":
 		if lNum, lOk := left.(float64); lOk {
 			if rNum, rOk := right.(float64); rOk {
 				return lNum <= rNum, nil
 			}
 		}
-		return nil, fmt.Errorf("invalid operator %s for types %T and %T", expr.Operator, left, right)
+		if lStr, lOk := left.(string); lOk {
+			if rStr, rOk := right.(string); rOk {
+				return lStr <= rStr, nil
+			}
+		}
+		return nil, errcode.Errorf("BRN0511", "invalid operator %s for types %T and %T", operator, left, right)
 	case ">=":
 		if lNum, lOk := left.(float64); lOk {
 			if rNum, rOk := right.(float64); rOk {
 				return lNum >= rNum, nil
 			}
 		}
-		return nil, fmt.Errorf("invalid operator %s for types %T and %T", expr.Operator, left, right)
+		if lStr, lOk := left.(string); lOk {
+			if rStr, rOk := right.(string); rOk {
+				return lStr >= rStr, nil
+			}
+		}
+		return nil, errcode.Errorf("BRN0511", "invalid operator %s for types %T and %T", operator, left, right)
 	}
 
-	return nil, fmt.Errorf("invalid operator %s for types %T and %T", expr.Operator, left, right)
+	return nil, errcode.Errorf("BRN0511", "invalid operator %s for types %T and %T", operator, left, right)
 }
 
 func (i *Interpreter) evaluateUnary(expr *ast.UnaryExpression) (Value, error) {
@@ -279,11 +420,21 @@ func (i *Interpreter) evaluateUnary(expr *ast.UnaryExpression) (Value, error) {
 	if err != nil {
 		return nil, err
 	}
+	return i.Unary(expr.Operator, right)
+}
 
-	switch expr.Operator {
+// Unary applies a unary operator to the value of its operand
+func (i *Interpreter) Unary(operator string, right Value) (Value, error) {
+	switch operator {
 	case "-":
 		if num, ok := right.(float64); ok {
-			return -num, nil
+			return number(-num), nil
+		}
+		if n, ok := right.(*big.Int); ok {
+			return new(big.Int).Neg(n), nil
+		}
+		if d, ok := right.(*Decimal); ok {
+			return &Decimal{Digits: new(big.Int).Neg(d.Digits), Scale: d.Scale}, nil
 		}
 	case "!":
 		if b, ok := right.(bool); ok {
@@ -291,18 +442,17 @@ func (i *Interpreter) evaluateUnary(expr *ast.UnaryExpression) (Value, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("invalid unary operator %s for type", expr.Operator)
+	return nil, errcode.Errorf("BRN0511", "invalid unary operator %s for type", operator)
 }
 
 func (i *Interpreter) evaluateCall(expr *ast.CallExpression) (Value, error) {
 	if getExpr, ok := expr.Callee.(*ast.GetExpression); ok {
-		if classNameExpr, ok := getExpr.Object.(*ast.VariableExpression); ok {
-			className := classNameExpr.Name
+		if className, ok := i.className(getExpr.Object); ok {
 			methodName := getExpr.Name
 
 			class, exists := i.classes[className]
 			if !exists {
-				return nil, fmt.Errorf("undefined class: %s", className)
+				return nil, errcode.Errorf("BRN0509", "undefined class: %s", className)
 			}
 
 			args := make([]Value, 0, len(expr.Arguments))
@@ -333,7 +483,7 @@ func (i *Interpreter) evaluateCall(expr *ast.CallExpression) (Value, error) {
 			}
 
 			if instanceMethod, exists := class.Methods[methodName]; exists {
-				result, err := i.executeFunction(instanceMethod, args)
+				result, err := i.callMethod(class, instanceMethod, args)
 				if err != nil {
 					return nil, err
 				}
@@ -350,10 +500,10 @@ func (i *Interpreter) evaluateCall(expr *ast.CallExpression) (Value, error) {
 				return result, nil
 			}
 
-			builtinFuncName := fmt.Sprintf("%s.%s", className, methodName)
-			if builtinFunc, exists := i.environment[builtinFuncName]; exists {
+			builtinFuncName := i.qualifiedName(className, methodName)
+			if builtinFunc, exists := i.builtins.Get(builtinFuncName); exists {
 				if bf, ok := builtinFunc.(*BuiltinFunction); ok {
-					result, err := bf.Call(args)
+					result, err := i.callBuiltin(builtinFuncName, bf, args)
 					if err != nil {
 						return nil, err
 					}
@@ -371,13 +521,18 @@ func (i *Interpreter) evaluateCall(expr *ast.CallExpression) (Value, error) {
 				}
 			}
 
-			return nil, fmt.Errorf("undefined static method '%s' in class '%s'", methodName, className)
+			return nil, errcode.Errorf("BRN0509", "undefined static method '%s' in class '%s'", methodName, className)
 		}
 
 		object, err := i.evaluateExpression(getExpr.Object)
 		if err != nil {
 			return nil, err
 		}
+		// A method called through ?. on nil is not called, and its
+		// arguments are not evaluated
+		if object == nil && getExpr.Optional {
+			return nil, nil
+		}
 
 		if structObj, ok := object.(*Struct); ok {
 			methodName := getExpr.Name
@@ -397,60 +552,111 @@ func (i *Interpreter) evaluateCall(expr *ast.CallExpression) (Value, error) {
 				copy(allArgs[1:], args)
 
 				if method, exists := class.Methods[methodName]; exists {
-					return i.executeFunction(method, allArgs)
+					return i.callMethod(class, method, allArgs)
 				}
 			}
 
-			return nil, fmt.Errorf("undefined method '%s' on type '%s'", methodName, structObj.TypeName)
+			hint := ""
+			if class, exists := i.classes[structObj.TypeName]; exists {
+				hint = suggest.Hint(methodName, maps.Keys(class.Methods))
+			}
+			return nil, errcode.Errorf("BRN0509", "undefined method '%s' on type '%s'%s", methodName, structObj.TypeName, hint)
 		}
 
-		return nil, fmt.Errorf("cannot call method on expression of type %T", object)
+		return nil, errcode.Errorf("BRN0511", "cannot call method on expression of type %T", object)
 	}
 
 	callee, ok := expr.Callee.(*ast.VariableExpression)
 	if !ok {
-		return nil, fmt.Errorf("callee is not a function name")
-	}
-
-	args := make([]Value, 0, len(expr.Arguments))
-	for _, arg := range expr.Arguments {
-		value, err := i.evaluateExpression(arg)
+		value, err := i.evaluateExpression(expr.Callee)
 		if err != nil {
 			return nil, err
 		}
-		args = append(args, value)
+		args, err := i.evaluateArguments(expr.Arguments)
+		if err != nil {
+			return nil, err
+		}
+		return i.callValue(value, args)
 	}
 
-	if builtinFunc, exists := i.environment[callee.Name]; exists {
-		if bf, ok := builtinFunc.(*BuiltinFunction); ok {
-			return bf.Call(args)
+	if arrayCallbacks[callee.Name] {
+		_, shadowed := i.environment.Get(callee.Name)
+		if _, declared := i.functions[callee.Name]; !shadowed && !declared {
+			args, err := i.evaluateArguments(expr.Arguments)
+			if err != nil {
+				return nil, err
+			}
+			return i.callArrayCallback(callee.Name, args)
+		}
+	}
+
+	args, err := i.evaluateArguments(expr.Arguments)
+	if err != nil {
+		return nil, err
+	}
+	// An error of the call itself, rather than of its arguments, is
+	// located at the name of the function
+	i.setErrorAt(callee)
+
+	if value, exists := i.lookup(callee.Name); exists {
+		switch fn := value.(type) {
+		case *BuiltinFunction:
+			result, err := i.callBuiltin(callee.Name, fn, args)
+			if err == nil && expr.NumberType != "" && fn.Name == "typeof" {
+				return expr.NumberType, nil
+			}
+			return result, err
+		case *Function:
+			return i.executeClosure(fn.Declaration, fn.closure, args)
 		}
 	}
 
 	fn, exists := i.functions[callee.Name]
 	if !exists {
-		return nil, fmt.Errorf("undefined function: %s", callee.Name)
+		if class, isClass := i.classes[callee.Name]; isClass {
+			return i.construct(class, args)
+		}
+		return nil, errcode.Errorf("BRN0509", "undefined function: %s%s", callee.Name, i.nameHint(callee.Name))
 	}
 
 	return i.executeFunction(fn, args)
 }
 
-func (i *Interpreter) evaluateLiteral(expr *ast.LiteralExpression) (Value, error) {
+// nameHint suggests a variable, function, class or builtin for a name that
+// is none of them
+func (i *Interpreter) nameHint(name string) string {
+	return suggest.Hint(name, i.environment.names(), maps.Keys(i.functions),
+		maps.Keys(i.classes), i.builtins.names())
+}
+
+func (i *Interpreter) evaluateArguments(arguments []ast.Expression) ([]Value, error) {
+	args := make([]Value, 0, len(arguments))
+	for _, arg := range arguments {
+		value, err := i.evaluateExpression(arg)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value)
+	}
+	return args, nil
+}
+
+// LiteralValue returns the value of a literal
+func LiteralValue(expr *ast.LiteralExpression) (Value, error) {
 	switch expr.Type {
 	case "number":
-		if strings.Contains(expr.Value.(string), ".") {
-			if val, err := strconv.ParseFloat(expr.Value.(string), 64); err == nil {
-				return val, nil
-			} else {
-				return nil, fmt.Errorf("invalid float: %s", expr.Value)
-			}
-		} else {
-			if val, err := strconv.ParseFloat(expr.Value.(string), 64); err == nil {
-				return val, nil
-			} else {
-				return nil, fmt.Errorf("invalid number: %s", expr.Value)
+		text := expr.Value.(string)
+		if lexer.IsDecimal(text) {
+			digits, scale, err := lexer.ParseDecimal(text)
+			if err != nil {
+				return nil, err
 			}
+			return &Decimal{Digits: digits, Scale: scale}, nil
+		}
+		if lexer.IsBigInt(text) {
+			return lexer.ParseBigInt(text)
 		}
+		return lexer.ParseNumber(expr.Value.(string))
 	case "string":
 		return expr.Value, nil
 	case "bool":
@@ -459,9 +665,9 @@ func (i *Interpreter) evaluateLiteral(expr *ast.LiteralExpression) (Value, error
 		} else if expr.Value == "false" {
 			return false, nil
 		}
-		return nil, fmt.Errorf("invalid boolean: %s", expr.Value)
+		return nil, errcode.Errorf("BRN0515", "invalid boolean: %s", expr.Value)
 	default:
-		return nil, fmt.Errorf("unknown literal type: %s", expr.Type)
+		return nil, errcode.Errorf("BRN0515", "unknown literal type: %s", expr.Type)
 	}
 }
 
@@ -471,7 +677,7 @@ func (i *Interpreter) evaluateClassMethodCall(expr *ast.ClassMethodCallExpressio
 
 	class, exists := i.classes[className]
 	if !exists {
-		return nil, fmt.Errorf("undefined class: %s", className)
+		return nil, errcode.Errorf("BRN0509", "undefined class: %s", className)
 	}
 
 	args := make([]Value, len(expr.Arguments))
@@ -484,7 +690,7 @@ func (i *Interpreter) evaluateClassMethodCall(expr *ast.ClassMethodCallExpressio
 	}
 
 	if method, exists := class.Methods[methodName]; exists {
-		return i.executeFunction(method, args)
+		return i.callMethod(class, method, args)
 	}
 
 	if static, exists := class.Statics[methodName]; exists {
@@ -522,8 +728,8 @@ func (i *Interpreter) evalBinaryExpression(expr *ast.BinaryExpression) (interfac
 				return lStr + rStr, nil
 			}
 		}
-		return nil, fmt.Errorf("cannot add values of types %T and %T", left, right)
+		return nil, errcode.Errorf("BRN0511", "cannot add values of types %T and %T", left, right)
 	}
 
-	return nil, fmt.Errorf("unsupported operator: %s", expr.Operator)
+	return nil, errcode.Errorf("BRN0515", "unsupported operator: %s", expr.Operator)
 }
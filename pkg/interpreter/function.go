@@ -0,0 +1,50 @@
+package interpreter
+
+import (
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
+)
+
+// Function is a Burn function used as a value: a lambda, or a declared
+// function named without being called
+type Function struct {
+	Declaration *ast.FunctionDeclaration
+
+	// closure is the scope the function was created in, which its body
+	// can see, or nil for a top-level function
+	closure *Environment
+}
+
+func (f *Function) String() string {
+	return "<fun " + f.Declaration.Name + ">"
+}
+
+// evaluateLambda makes the function value of a lambda. Each lambda gets one
+// declaration, so that hooks see the same function every time it runs.
+func (i *Interpreter) evaluateLambda(expr *ast.LambdaExpression) (Value, error) {
+	fn, exists := i.lambdas[expr]
+	if !exists {
+		fn = &ast.FunctionDeclaration{
+			Name:       "lambda",
+			Parameters: expr.Parameters,
+			ReturnType: expr.ReturnType,
+			Body:       expr.Body,
+			Position:   expr.Position,
+			Location:   expr.Location,
+		}
+		i.lambdas[expr] = fn
+		i.functionFiles[fn] = i.currentFile
+	}
+	return &Function{Declaration: fn, closure: i.environment}, nil
+}
+
+// callValue calls a function value
+func (i *Interpreter) callValue(callee Value, args []Value) (Value, error) {
+	switch fn := callee.(type) {
+	case *Function:
+		return i.executeClosure(fn.Declaration, fn.closure, args)
+	case *BuiltinFunction:
+		return i.callBuiltin(fn.Name, fn, args)
+	}
+	return nil, errcode.Errorf("BRN0511", "cannot call a value of type %T", callee)
+}
@@ -0,0 +1,181 @@
+package interpreter
+
+import (
+	"time"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
+)
+
+// CallHook observes Burn function calls. EnterFunction runs before the
+// function body and ExitFunction after it returns, including on error.
+type CallHook interface {
+	EnterFunction(name string, pos int)
+	ExitFunction(name string)
+}
+
+// AddCallHook registers a hook that is notified of every function call
+func (i *Interpreter) AddCallHook(hook CallHook) {
+	i.callHooks = append(i.callHooks, hook)
+}
+
+func (i *Interpreter) enterFunction(fn *ast.FunctionDeclaration) string {
+	name := i.functionName(fn)
+	for _, hook := range i.callHooks {
+		hook.EnterFunction(name, fn.Position)
+	}
+	return name
+}
+
+func (i *Interpreter) exitFunction(name string) {
+	for j := len(i.callHooks) - 1; j >= 0; j-- {
+		i.callHooks[j].ExitFunction(name)
+	}
+}
+
+// functionName returns the name hooks report for fn, qualifying class
+// methods with their class name
+func (i *Interpreter) functionName(fn *ast.FunctionDeclaration) string {
+	if top, ok := i.functions[fn.Name]; ok && top == fn {
+		return fn.Name
+	}
+	for className, class := range i.classes {
+		if class.Methods[fn.Name] == fn || class.Statics[fn.Name] == fn {
+			return className + "." + fn.Name
+		}
+	}
+	return fn.Name
+}
+
+// CallInfo describes a call of a Burn function or a builtin for an
+// InvocationHook. Duration and Err are only set once the call returns.
+type CallInfo struct {
+	// Name is the function name, qualified with the class name for methods
+	Name string
+
+	// Args are the arguments of the call; hooks must not modify them
+	Args []Value
+
+	// Builtin is set for functions implemented in Go
+	Builtin  bool
+	Duration time.Duration
+	Err      error
+}
+
+// InvocationHook observes calls together with their arguments and outcome.
+// An error from BeforeCall stops the call and becomes its result, so hooks
+// can also enforce quotas.
+type InvocationHook interface {
+	BeforeCall(call *CallInfo) error
+	AfterCall(call *CallInfo)
+}
+
+// AddInvocationHook registers a hook that is notified of every call of a
+// Burn function or builtin
+func (i *Interpreter) AddInvocationHook(hook InvocationHook) {
+	i.invocationHooks = append(i.invocationHooks, hook)
+}
+
+// invoke runs call, notifying the invocation hooks around it
+func (i *Interpreter) invoke(name string, args []Value, builtin bool, call func() (Value, error)) (Value, error) {
+	info := &CallInfo{Name: name, Args: args, Builtin: builtin}
+	for _, hook := range i.invocationHooks {
+		if err := hook.BeforeCall(info); err != nil {
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+	result, err := call()
+	info.Duration = time.Since(start)
+	info.Err = err
+	for j := len(i.invocationHooks) - 1; j >= 0; j-- {
+		i.invocationHooks[j].AfterCall(info)
+	}
+	return result, err
+}
+
+// CallBuiltin calls the builtin or host function called name as Burn code
+// calling it does
+func (i *Interpreter) CallBuiltin(name string, args []Value) (Value, error) {
+	if value, exists := i.builtins.Get(name); exists {
+		if bf, ok := value.(*BuiltinFunction); ok {
+			return i.callBuiltin(name, bf, args)
+		}
+	}
+	return nil, errcode.Errorf("BRN0509", "undefined function: %s%s", name, i.nameHint(name))
+}
+
+// callBuiltin calls a builtin found in the environment under name
+func (i *Interpreter) callBuiltin(name string, bf *BuiltinFunction, args []Value) (Value, error) {
+	// Aliases such as get for HTTP.get share the builtin, whose name is
+	// the one policies refer to
+	if err := i.host.policy.check(bf.Name); err != nil {
+		return nil, err
+	}
+	i.countBuiltin(name)
+	if len(i.invocationHooks) == 0 {
+		return bf.Call(args)
+	}
+	return i.invoke(name, args, true, func() (Value, error) {
+		return bf.Call(args)
+	})
+}
+
+// StatementHook observes statement execution. file is the path of the
+// source file the statement belongs to, or "" for embedded code.
+type StatementHook interface {
+	ExecuteStatement(file string, stmt ast.Declaration)
+	TakeBranch(file string, stmt *ast.IfStatement, taken bool)
+}
+
+// AddStatementHook registers a hook that is notified of every executed
+// statement and every if/else decision
+func (i *Interpreter) AddStatementHook(hook StatementHook) {
+	i.statementHooks = append(i.statementHooks, hook)
+}
+
+func (i *Interpreter) notifyStatement(stmt ast.Declaration) {
+	for _, hook := range i.statementHooks {
+		hook.ExecuteStatement(i.currentFile, stmt)
+	}
+}
+
+func (i *Interpreter) notifyBranch(stmt *ast.IfStatement, taken bool) {
+	for _, hook := range i.statementHooks {
+		hook.TakeBranch(i.currentFile, stmt, taken)
+	}
+}
+
+// SetFile sets the path reported to hooks for the program being run
+func (i *Interpreter) SetFile(path string) {
+	i.file = path
+	i.currentFile = path
+}
+
+// LoadedFiles returns the source files pulled in through imports
+func (i *Interpreter) LoadedFiles() []string {
+	return i.loadedFiles
+}
+
+func (i *Interpreter) fileOf(fn *ast.FunctionDeclaration) string {
+	if file, ok := i.functionFiles[fn]; ok {
+		return file
+	}
+	return i.file
+}
+
+// inheritHooks shares the hooks and host settings of i with an interpreter
+// used for an import
+func (i *Interpreter) inheritHooks(imported *Interpreter, file string) {
+	imported.callHooks = i.callHooks
+	imported.invocationHooks = i.invocationHooks
+	imported.statementHooks = i.statementHooks
+	imported.host = i.host
+	imported.SetFile(file)
+}
+
+// adoptFunction records where a function copied in from an import was defined
+func (i *Interpreter) adoptFunction(from *Interpreter, fn *ast.FunctionDeclaration) {
+	i.functionFiles[fn] = from.fileOf(fn)
+}
@@ -0,0 +1,353 @@
+package interpreter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
+	"github.com/burnlang/burn/pkg/resolver"
+)
+
+// ErrLimitExceeded is wrapped by the error a run stops with when it goes
+// over one of its Limits
+var ErrLimitExceeded = errcode.New("BRN0506", "limit exceeded")
+
+// ErrCapabilityDenied is wrapped by the error a program gets when it uses a
+// capability the host has denied
+var ErrCapabilityDenied = errcode.New("BRN0507", "capability denied")
+
+// DefaultMaxDepth is the depth limit of runs whose Limits leave MaxDepth
+// zero. Much deeper recursion would exhaust the Go stack.
+const DefaultMaxDepth = 10000
+
+// Limits bounds the work a run may do. Zero fields mean no limit, except
+// for MaxDepth, which then is DefaultMaxDepth.
+type Limits struct {
+	// MaxSteps is the number of statements and loop iterations a run may
+	// execute
+	MaxSteps int
+
+	// Timeout is the wall-clock time a run may take
+	Timeout time.Duration
+
+	// MaxMemory is the number of bytes of strings and arrays a run may
+	// create. Memory is counted when it is allocated, not when it is freed.
+	MaxMemory int64
+
+	// MaxDepth is the number of calls of Burn functions that may be in
+	// progress at once
+	MaxDepth int
+}
+
+// Capability is a kind of side effect a host can deny a program
+type Capability int
+
+const (
+	// CapabilityNetwork covers HTTP requests
+	CapabilityNetwork Capability = 1 << iota
+	// CapabilityInput covers reading standard input with input()
+	CapabilityInput
+)
+
+// Policy restricts the builtins programs may call. A rule is the name of a
+// builtin, such as "HTTP.get", or of a class, such as "HTTP", which covers
+// all of its methods. When Allow is not empty, only the builtins it matches
+// may be called; Deny forbids builtins that would otherwise be allowed.
+type Policy struct {
+	Allow []string
+	Deny  []string
+}
+
+// PolicyError is the error of a call of a builtin forbidden by the Policy.
+// It wraps ErrCapabilityDenied.
+type PolicyError struct {
+	Builtin string
+
+	// Rule is the Deny rule matching Builtin, or "" when Builtin is
+	// missing from Allow
+	Rule string
+}
+
+func (e *PolicyError) Error() string {
+	if e.Rule == "" {
+		return fmt.Sprintf("%s: %v: not in the allowed builtins", e.Builtin, ErrCapabilityDenied)
+	}
+	return fmt.Sprintf("%s: %v: denied by rule %q", e.Builtin, ErrCapabilityDenied, e.Rule)
+}
+
+func (e *PolicyError) Unwrap() error {
+	return ErrCapabilityDenied
+}
+
+// RecursionError is the error of a call that goes deeper than
+// Limits.MaxDepth. It wraps ErrLimitExceeded.
+type RecursionError struct {
+	MaxDepth int
+
+	// Chain names the functions in progress, outermost first
+	Chain []string
+}
+
+func (e *RecursionError) Error() string {
+	return fmt.Sprintf("maximum recursion depth exceeded (%d): %s", e.MaxDepth, describeChain(e.Chain))
+}
+
+// ErrorCode returns the code of the error, which is more precise than that
+// of ErrLimitExceeded
+func (e *RecursionError) ErrorCode() string {
+	return "BRN0505"
+}
+
+func (e *RecursionError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// describeChain writes a call chain with each run of calls of the same
+// function once, and leaves out the middle of a long chain
+func describeChain(chain []string) string {
+	var runs []string
+	for j := 0; j < len(chain); {
+		k := j + 1
+		for k < len(chain) && chain[k] == chain[j] {
+			k++
+		}
+		if k-j > 1 {
+			runs = append(runs, fmt.Sprintf("%s (%d times)", chain[j], k-j))
+		} else {
+			runs = append(runs, chain[j])
+		}
+		j = k
+	}
+
+	const shown = 5
+	if len(runs) > 2*shown {
+		omitted := fmt.Sprintf("... (%d more)", len(runs)-2*shown)
+		runs = slices.Concat(runs[:shown], []string{omitted}, runs[len(runs)-shown:])
+	}
+	return strings.Join(runs, " -> ")
+}
+
+// matchRule returns the first rule matching the builtin name
+func matchRule(rules []string, name string) (string, bool) {
+	for _, rule := range rules {
+		if rule == name || strings.HasPrefix(name, rule+".") {
+			return rule, true
+		}
+	}
+	return "", false
+}
+
+// check returns a *PolicyError if the policy forbids the builtin name
+func (p Policy) check(name string) error {
+	if rule, denied := matchRule(p.Deny, name); denied {
+		return &PolicyError{Builtin: name, Rule: rule}
+	}
+	if len(p.Allow) > 0 {
+		if _, allowed := matchRule(p.Allow, name); !allowed {
+			return &PolicyError{Builtin: name}
+		}
+	}
+	return nil
+}
+
+// hostSettings is what the embedding host configures for a run. The
+// interpreters created for imports share it with the importing one.
+type hostSettings struct {
+	stdout   io.Writer
+	stdin    *bufio.Reader
+	denied   Capability
+	policy   Policy
+	resolver resolver.Resolver
+
+	httpHeaders map[string]string
+
+	limits   Limits
+	deadline time.Time
+	steps    int
+	memory   int64
+
+	// calls are the Burn functions in progress, outermost first
+	calls []*ast.FunctionDeclaration
+
+	nodes        int
+	builtinCalls map[string]int
+	httpBytes    int64
+}
+
+// Metrics describes the work done since the last SetLimits
+type Metrics struct {
+	// Nodes is the number of statements and expressions evaluated
+	Nodes int
+
+	// Memory is the number of bytes of strings and arrays created, as
+	// counted for Limits.MaxMemory. Memory that is freed is not
+	// subtracted, so it is the total allocated rather than the peak.
+	Memory int64
+
+	// BuiltinCalls counts the calls of each builtin by name
+	BuiltinCalls map[string]int
+
+	// HTTPBytes is the size of the bodies of HTTP requests and responses
+	HTTPBytes int64
+}
+
+func newHostSettings() *hostSettings {
+	return &hostSettings{
+		stdout:      os.Stdout,
+		stdin:       bufio.NewReader(os.Stdin),
+		httpHeaders: defaultHTTPHeaders,
+	}
+}
+
+// SetOutput sets where print writes
+func (i *Interpreter) SetOutput(w io.Writer) {
+	i.host.stdout = w
+}
+
+// SetInput sets where input reads from
+func (i *Interpreter) SetInput(r io.Reader) {
+	i.host.stdin = bufio.NewReader(r)
+}
+
+// SetResolver replaces the default lookup of imports on disk and in the
+// standard library
+func (i *Interpreter) SetResolver(r resolver.Resolver) {
+	i.host.resolver = r
+}
+
+// resolve looks up an import made by the file importer
+func (h *hostSettings) resolve(path, importer string, importPaths []string) (*resolver.Module, error) {
+	r := h.resolver
+	if r == nil {
+		r = resolver.Default(importPaths...)
+	}
+	dir := ""
+	if importer != "" {
+		dir = filepath.Dir(importer)
+	}
+	return r.Resolve(path, dir)
+}
+
+// Deny forbids programs the given capabilities
+func (i *Interpreter) Deny(caps Capability) {
+	i.host.denied |= caps
+}
+
+// SetPolicy restricts the builtins programs may call, including those
+// defined later with DefineFunction
+func (i *Interpreter) SetPolicy(p Policy) {
+	i.host.policy = p
+}
+
+// SetLimits applies limits to the code run from now on, starting the step,
+// time and memory counts and the Metrics afresh
+func (i *Interpreter) SetLimits(limits Limits) {
+	h := i.host
+	h.limits = limits
+	h.steps = 0
+	h.memory = 0
+	h.nodes = 0
+	h.builtinCalls = make(map[string]int)
+	h.httpBytes = 0
+	h.deadline = time.Time{}
+	if limits.Timeout > 0 {
+		h.deadline = time.Now().Add(limits.Timeout)
+	}
+}
+
+// Metrics returns the work done since the last SetLimits
+func (i *Interpreter) Metrics() Metrics {
+	h := i.host
+	calls := make(map[string]int, len(h.builtinCalls))
+	for name, n := range h.builtinCalls {
+		calls[name] = n
+	}
+	return Metrics{Nodes: h.nodes, Memory: h.memory, BuiltinCalls: calls, HTTPBytes: h.httpBytes}
+}
+
+// countBuiltin records a call of the builtin name for Metrics
+func (i *Interpreter) countBuiltin(name string) {
+	if i.host.builtinCalls == nil {
+		i.host.builtinCalls = make(map[string]int)
+	}
+	i.host.builtinCalls[name]++
+}
+
+// transferred counts the bytes of an HTTP request and response body
+func (i *Interpreter) transferred(sent, received int) {
+	i.host.httpBytes += int64(sent + received)
+}
+
+// require fails when a capability needed by the builtin name is denied
+func (i *Interpreter) require(c Capability, name string) error {
+	if i.host.denied&c != 0 {
+		return fmt.Errorf("%s: %w", name, ErrCapabilityDenied)
+	}
+	return nil
+}
+
+// step counts one unit of work against the step and time limits
+func (i *Interpreter) step() error {
+	h := i.host
+	h.steps++
+	if h.limits.MaxSteps > 0 && h.steps > h.limits.MaxSteps {
+		return fmt.Errorf("%w: more than %d steps", ErrLimitExceeded, h.limits.MaxSteps)
+	}
+	if !h.deadline.IsZero() && time.Now().After(h.deadline) {
+		return fmt.Errorf("%w: timed out after %v", ErrLimitExceeded, h.limits.Timeout)
+	}
+	return nil
+}
+
+// enterCall records a call of fn, failing when it would go deeper than
+// the depth limit. exitCall must follow when the call returns.
+func (i *Interpreter) enterCall(fn *ast.FunctionDeclaration) error {
+	h := i.host
+	maxDepth := h.limits.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+	if len(h.calls) >= maxDepth {
+		chain := make([]string, 0, len(h.calls)+1)
+		for _, call := range h.calls {
+			chain = append(chain, i.functionName(call))
+		}
+		chain = append(chain, i.functionName(fn))
+		return &RecursionError{MaxDepth: maxDepth, Chain: chain}
+	}
+	h.calls = append(h.calls, fn)
+	return nil
+}
+
+func (i *Interpreter) exitCall() {
+	h := i.host
+	h.calls = h.calls[:len(h.calls)-1]
+}
+
+// allocate counts bytes of new strings or arrays against the memory limit
+func (i *Interpreter) allocate(bytes int) error {
+	h := i.host
+	h.memory += int64(bytes)
+	if h.limits.MaxMemory > 0 && h.memory > h.limits.MaxMemory {
+		return fmt.Errorf("%w: more than %d bytes of memory", ErrLimitExceeded, h.limits.MaxMemory)
+	}
+	return nil
+}
+
+// sleep pauses for d, or fails at the deadline if that comes first
+func (i *Interpreter) sleep(d time.Duration) error {
+	h := i.host
+	if !h.deadline.IsZero() && time.Now().Add(d).After(h.deadline) {
+		time.Sleep(time.Until(h.deadline))
+		return fmt.Errorf("%w: timed out after %v", ErrLimitExceeded, h.limits.Timeout)
+	}
+	time.Sleep(d)
+	return nil
+}
@@ -0,0 +1,88 @@
+package interpreter
+
+import (
+	"math"
+
+	"github.com/burnlang/burn/pkg/ast"
+)
+
+// smallNumbers holds the whole numbers from minSmallNumber up to
+// maxSmallNumber already boxed as Values. Converting a float64 to a Value
+// allocates, and loop counters, indexes and small sums are mostly numbers
+// like these.
+var smallNumbers = func() []Value {
+	numbers := make([]Value, maxSmallNumber-minSmallNumber+1)
+	for k := range numbers {
+		numbers[k] = float64(k + minSmallNumber)
+	}
+	return numbers
+}()
+
+const (
+	minSmallNumber = -256
+	maxSmallNumber = 1024
+)
+
+// number returns n as a Value, without allocating when it is small and
+// whole. Negative zero is boxed as it is, since it divides differently.
+func number(n float64) Value {
+	if n >= minSmallNumber && n <= maxSmallNumber {
+		if k := int(n); float64(k) == n && (k != 0 || !math.Signbit(n)) {
+			return smallNumbers[k-minSmallNumber]
+		}
+	}
+	return n
+}
+
+// characters holds the strings of one ASCII character boxed as Values, for
+// indexing and iterating over strings
+var characters = func() []Value {
+	chars := make([]Value, 128)
+	for k := range chars {
+		chars[k] = string(rune(k))
+	}
+	return chars
+}()
+
+// character returns r as a string Value, without allocating for ASCII
+func character(r rune) Value {
+	if r >= 0 && int(r) < len(characters) {
+		return characters[r]
+	}
+	return string(r)
+}
+
+// methodName is a class and one of its methods
+type methodName struct {
+	class, method string
+}
+
+// qualifiedName returns "class.method", the name of a builtin implementing
+// a method, making the string only the first time
+func (i *Interpreter) qualifiedName(class, method string) string {
+	key := methodName{class, method}
+	if name, ok := i.qualifiedNames[key]; ok {
+		return name
+	}
+	name := class + "." + method
+	i.qualifiedNames[key] = name
+	return name
+}
+
+// literal returns the value of a literal. Plain numbers are parsed the
+// first time and kept, boxed; big integers and decimals are made anew each
+// time, as they are pointers the program could share.
+func (i *Interpreter) literal(expr *ast.LiteralExpression) (Value, error) {
+	if value, ok := i.literals[expr]; ok {
+		return value, nil
+	}
+	value, err := LiteralValue(expr)
+	if err != nil {
+		return nil, err
+	}
+	if n, isNumber := value.(float64); isNumber {
+		value = number(n)
+		i.literals[expr] = value
+	}
+	return value, nil
+}
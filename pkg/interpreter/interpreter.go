@@ -1,77 +1,383 @@
 package interpreter
 
 import (
-	"fmt"
-	"os"
-	"path/filepath"
+	"iter"
+	"maps"
 	"strings"
+	"sync"
 
 	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
 	"github.com/burnlang/burn/pkg/lexer"
 	"github.com/burnlang/burn/pkg/parser"
 	"github.com/burnlang/burn/pkg/stdlib"
+	"github.com/burnlang/burn/pkg/suggest"
 )
 
 type Interpreter struct {
-	environment map[string]Value
+	// environment is the innermost scope of the running code, and globals
+	// the scope of the top-level code. The builtins and constants are kept
+	// apart, in a scope that every lookup ends in.
+	environment *Environment
+	globals     *Environment
+	builtins    *Environment
 	functions   map[string]*ast.FunctionDeclaration
 	types       map[string]*ast.TypeDefinition
 	classes     map[string]*Class
 	errorPos    int
+	errorLoc    ast.Location
+	errorFile   string
+	callHooks   []CallHook
+
+	invocationHooks []InvocationHook
+
+	statementHooks []StatementHook
+	file           string
+	currentFile    string
+	functionFiles  map[*ast.FunctionDeclaration]string
+	lambdas        map[*ast.LambdaExpression]*ast.FunctionDeclaration
+	literals       map[*ast.LiteralExpression]Value
+	qualifiedNames map[methodName]string
+	loadedFiles    []string
+	importPaths    []string
+	constants      map[string]Value
+	host           *hostSettings
 
 	importedModules map[string]bool
+	// modules are the modules interpreted for the program so far, by path.
+	// The interpreters of its imports share them with it, so that a module
+	// imported from several places runs only once.
+	modules map[string]*importedModule
+
+	// control is set by a break, continue or return statement until the
+	// loop or function it leaves has seen it
+	control control
+	// label is the label of the loop a break or continue leaves, if it
+	// names one
+	label string
 }
 
+// importedModule is a module once interpreted: the interpreter that ran it
+// holds its functions, types, classes and globals
+type importedModule struct {
+	interp  *Interpreter
+	program *ast.Program
+}
+
+// control tells which statement, if any, is leaving the statements
+// around it
+type control int
+
+const (
+	controlNone control = iota
+	controlBreak
+	controlContinue
+	controlReturn
+)
+
+// Environment is a scope of variables. Names it does not bind are looked
+// up in the scopes enclosing it. The local variables of functions are kept
+// in slots, which code resolved by Resolve reaches by their ast.Binding;
+// other variables, such as globals, are kept by name.
 type Environment struct {
 	enclosing *Environment
 	values    map[string]interface{}
+	// slots hold the variables bound to slots, whose names are at the same
+	// index of slotNames, or "" for slots not bound yet
+	slots     []Value
+	slotNames []string
+	// consts are the names of values bound with DefineConst
+	consts map[string]bool
 }
 
+// NewEnvironment returns an empty scope. It allocates nothing more until a
+// variable is defined in it, as the scopes of blocks and calls are made
+// all the time and most hold only a few slots, if anything.
 func NewEnvironment(enclosing *Environment) *Environment {
+	return &Environment{enclosing: enclosing}
+}
+
+// newFrame returns the scope of a call whose parameters take size slots
+func newFrame(closure *Environment, size int) *Environment {
 	return &Environment{
-		enclosing: enclosing,
-		values:    make(map[string]interface{}),
+		enclosing: closure,
+		slots:     make([]Value, size),
+		slotNames: make([]string, size),
+	}
+}
+
+// Get returns the value name is bound to in e or the scopes enclosing it
+func (e *Environment) Get(name string) (Value, bool) {
+	for env := e; env != nil; env = env.enclosing {
+		if value, ok := env.values[name]; ok {
+			return value, true
+		}
+		if slot := env.slotOf(name); slot >= 0 {
+			return env.slots[slot], true
+		}
+	}
+	return nil, false
+}
+
+// slotOf returns the slot of the latest variable called name bound to a
+// slot of e, or -1
+func (e *Environment) slotOf(name string) int {
+	for slot := len(e.slotNames) - 1; slot >= 0; slot-- {
+		if e.slotNames[slot] == name {
+			return slot
+		}
+	}
+	return -1
+}
+
+// names returns the names bound in e and the scopes enclosing it
+func (e *Environment) names() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for env := e; env != nil; env = env.enclosing {
+			for name := range env.values {
+				if !yield(name) {
+					return
+				}
+			}
+			for _, name := range env.slotNames {
+				if name != "" && !yield(name) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Define binds name in e, shadowing any binding of the scopes enclosing it
+func (e *Environment) Define(name string, value Value) {
+	if e.values == nil {
+		e.values = make(map[string]interface{})
+	}
+	e.values[name] = value
+	delete(e.consts, name)
+}
+
+// DefineConst binds name in e like Define, to a value Assign cannot change
+func (e *Environment) DefineConst(name string, value Value) {
+	e.Define(name, value)
+	if e.consts == nil {
+		e.consts = make(map[string]bool)
+	}
+	e.consts[name] = true
+}
+
+// defineSlot binds the variable called name to a slot of e, as a constant
+// if isConst is set
+func (e *Environment) defineSlot(slot int, name string, value Value, isConst bool) {
+	if slot >= len(e.slots) {
+		e.slots = append(e.slots, make([]Value, slot+1-len(e.slots))...)
+		e.slotNames = append(e.slotNames, make([]string, slot+1-len(e.slotNames))...)
+	}
+	e.slots[slot], e.slotNames[slot] = value, name
+	if isConst {
+		if e.consts == nil {
+			e.consts = make(map[string]bool)
+		}
+		e.consts[name] = true
+	} else {
+		delete(e.consts, name)
+	}
+}
+
+// scopeOf returns the scope holding the variable of a binding, or nil when
+// the variable is not bound yet
+func (e *Environment) scopeOf(b *ast.Binding) *Environment {
+	env := e
+	for depth := b.Depth; depth > 0 && env != nil; depth-- {
+		env = env.enclosing
+	}
+	if env == nil || b.Slot >= len(env.slotNames) || env.slotNames[b.Slot] == "" {
+		return nil
+	}
+	return env
+}
+
+// Assign changes the value of name in the innermost scope that binds it,
+// or defines it in e when no scope does
+func (e *Environment) Assign(name string, value Value) error {
+	for env := e; env != nil; env = env.enclosing {
+		if _, ok := env.values[name]; ok {
+			if env.consts[name] {
+				return errcode.Errorf("BRN0516", "cannot assign to constant %s", name)
+			}
+			env.values[name] = value
+			return nil
+		}
+		if slot := env.slotOf(name); slot >= 0 {
+			if env.consts[name] {
+				return errcode.Errorf("BRN0516", "cannot assign to constant %s", name)
+			}
+			env.slots[slot] = value
+			return nil
+		}
+	}
+	e.Define(name, value)
+	return nil
+}
+
+// variable returns the value of the variable called name with binding b,
+// which is looked up by name when b is nil, as are builtins and constants
+func (i *Interpreter) variable(name string, b *ast.Binding) (Value, bool) {
+	if b != nil {
+		if env := i.environment.scopeOf(b); env != nil {
+			return env.slots[b.Slot], true
+		}
+	}
+	return i.lookup(name)
+}
+
+// assign changes the value of the variable called name with binding b
+func (i *Interpreter) assign(name string, b *ast.Binding, value Value) error {
+	if b != nil {
+		if env := i.environment.scopeOf(b); env != nil {
+			if env.consts[name] {
+				return errcode.Errorf("BRN0516", "cannot assign to constant %s", name)
+			}
+			env.slots[b.Slot] = value
+			return nil
+		}
+	}
+	return i.environment.Assign(name, value)
+}
+
+// define binds a variable declared in the running code, to its slot when
+// it has a binding
+func (i *Interpreter) define(name string, b *ast.Binding, value Value, isConst bool) {
+	switch {
+	case b != nil:
+		i.environment.defineSlot(b.Slot, name, value, isConst)
+	case isConst:
+		i.environment.DefineConst(name, value)
+	default:
+		i.environment.Define(name, value)
 	}
 }
 
 func New() *Interpreter {
+	globals := NewEnvironment(nil)
 	i := &Interpreter{
-		environment:     make(map[string]Value),
+		environment:     globals,
+		globals:         globals,
+		builtins:        &Environment{values: make(map[string]interface{})},
 		functions:       make(map[string]*ast.FunctionDeclaration),
 		types:           make(map[string]*ast.TypeDefinition),
 		classes:         make(map[string]*Class),
-		errorPos:        0,
 		importedModules: make(map[string]bool),
+		modules:         make(map[string]*importedModule),
+		functionFiles:   make(map[*ast.FunctionDeclaration]string),
+		lambdas:         make(map[*ast.LambdaExpression]*ast.FunctionDeclaration),
+		literals:        make(map[*ast.LiteralExpression]Value),
+		qualifiedNames:  make(map[methodName]string),
+		constants:       make(map[string]Value),
+		host:            newHostSettings(),
 	}
 	i.addBuiltins()
 	return i
 }
 
+// RegisterBuiltinStandardLibraries loads the standard libraries written in
+// Burn. The date, http and time libraries are implemented in Go and
+// registered by New already, so that a program's own classes of the same
+// names replace them rather than the other way round.
 func (i *Interpreter) RegisterBuiltinStandardLibraries() {
-
-	i.registerDateLibrary()
-	i.registerHTTPLibrary()
-	i.registerTimeLibrary()
-
 	for name, lib := range stdlib.StdLibFiles {
 		if name == "date" || name == "http" || name == "time" {
 
 			continue
 		}
+		// Marking the library first stops the interpreter that runs it
+		// from loading it again
+		if i.importedModules[name] {
+			continue
+		}
+		i.importedModules[name] = true
 		_ = i.interpretStdLib(name, lib)
 	}
 }
 
 func (i *Interpreter) Interpret(program *ast.Program) (Value, error) {
+	if err := i.Load(program); err != nil {
+		return nil, err
+	}
+
+	if mainFn, exists := i.functions["main"]; exists {
+		return i.executeFunction(mainFn, []Value{})
+	}
+
+	var result Value
+	for _, decl := range program.Declarations {
+		var err error
+		result, err = i.executeDeclaration(decl)
+		if err != nil {
+			return nil, err
+		}
+		i.control = controlNone
+	}
+
+	return result, nil
+}
+
+// ExitCode returns the process exit status a program asks for: the value
+// returned by a main function declared to return int, otherwise 0
+func ExitCode(program *ast.Program, result Value) int {
+	for _, decl := range program.Declarations {
+		if fn, ok := decl.(*ast.FunctionDeclaration); ok && fn.Name == "main" && fn.Receiver == "" {
+			if code, isNumber := result.(float64); isNumber && fn.ReturnType == "int" {
+				return int(code)
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+// Eval loads a program and runs its top-level statements without calling
+// main. Everything defined by earlier calls stays in place, so a sequence of
+// Evals behaves like one growing program, as in an interactive session.
+func (i *Interpreter) Eval(program *ast.Program) (Value, error) {
+	if err := i.Load(program); err != nil {
+		return nil, err
+	}
+
+	var result Value
+	for _, decl := range program.Declarations {
+		var err error
+		result, err = i.executeDeclaration(decl)
+		if err != nil {
+			return nil, err
+		}
+		i.control = controlNone
+	}
 
+	return result, nil
+}
+
+// Load registers the types, classes, functions and imports of a program
+// without running it
+func (i *Interpreter) Load(program *ast.Program) error {
+	Resolve(program)
 	for _, decl := range program.Declarations {
 		if typeDef, ok := decl.(*ast.TypeDefinition); ok {
 			i.types[typeDef.Name] = typeDef
 		} else if classDef, ok := decl.(*ast.ClassDeclaration); ok {
 			class := NewClass(classDef.Name)
+			for _, field := range classDef.Fields {
+				class.AddField(field.Name, field.Type)
+				if field.Value != nil {
+					class.Defaults[field.Name] = field.Value
+				}
+			}
 			for _, method := range classDef.Methods {
 				class.AddMethod(method.Name, method)
 			}
+			for _, name := range classDef.Interfaces {
+				class.ImplementsInterface(name)
+			}
 			for _, method := range classDef.StaticMethods {
 				class.AddStatic(method.Name, method)
 			}
@@ -79,42 +385,59 @@ func (i *Interpreter) Interpret(program *ast.Program) (Value, error) {
 		}
 	}
 
-	i.addBuiltins()
-
 	i.RegisterBuiltinStandardLibraries()
 
 	for _, decl := range program.Declarations {
 		if fn, ok := decl.(*ast.FunctionDeclaration); ok {
-			i.functions[fn.Name] = fn
+			if fn.Receiver != "" {
+				i.addMethod(fn)
+			} else {
+				i.functions[fn.Name] = fn
+			}
+			if _, known := i.functionFiles[fn]; !known && i.file != "" {
+				i.functionFiles[fn] = i.file
+			}
 		}
 		if imp, ok := decl.(*ast.ImportDeclaration); ok {
 			if err := i.handleImport(imp); err != nil {
-				return nil, err
+				return err
 			}
 		}
 		if multiImp, ok := decl.(*ast.MultiImportDeclaration); ok {
 			for _, imp := range multiImp.Imports {
 				if err := i.handleImport(imp); err != nil {
-					return nil, err
+					return err
 				}
 			}
 		}
 	}
 
-	if mainFn, exists := i.functions["main"]; exists {
-		return i.executeFunction(mainFn, []Value{})
-	}
-
-	var result Value
+	// Static fields get their values once everything they could call is
+	// in place
 	for _, decl := range program.Declarations {
-		var err error
-		result, err = i.executeDeclaration(decl)
-		if err != nil {
-			return nil, err
+		if classDef, ok := decl.(*ast.ClassDeclaration); ok {
+			if err := i.initStatics(classDef); err != nil {
+				return err
+			}
 		}
 	}
 
-	return result, nil
+	return nil
+}
+
+// Call runs a top-level Burn function by name
+func (i *Interpreter) Call(name string, args ...Value) (Value, error) {
+	fn, exists := i.functions[name]
+	if !exists {
+		return nil, errcode.Errorf("BRN0509", "undefined function: %s%s", name, suggest.Hint(name, maps.Keys(i.functions)))
+	}
+	return i.executeFunction(fn, args)
+}
+
+// AddImportPath adds a directory in which imports are looked up when they are
+// not found relative to the working directory
+func (i *Interpreter) AddImportPath(dir string) {
+	i.importPaths = append(i.importPaths, dir)
 }
 
 func (i *Interpreter) handleImport(imp *ast.ImportDeclaration) error {
@@ -143,201 +466,299 @@ func (i *Interpreter) handleImport(imp *ast.ImportDeclaration) error {
 		}
 	}
 
-	if strings.HasSuffix(libName, ".bn") || !strings.Contains(libName, ".") {
-		path := libName
-
-		if !strings.HasSuffix(path, ".bn") {
-			path = path + ".bn"
-		}
-
-		workingDir, err := os.Getwd()
-		if err != nil {
-			return fmt.Errorf("error getting current directory: %v", err)
-		}
-
-		searchPaths := []string{
-			path,
-			filepath.Join(workingDir, path),
-			filepath.Join("src", "lib", "std", path),
-			filepath.Join("src", "lib", path),
-			filepath.Join("src", "lib", "std", strings.TrimSuffix(path, ".bn")+".bn"),
-			filepath.Join("src", "lib", strings.TrimSuffix(path, ".bn")+".bn"),
+	module, err := i.host.resolve(libName, i.file, i.importPaths)
+	if err != nil {
+		return errcode.Errorf("BRN0401", "could not find import file %s: %v", libName, err)
+	}
 
-			filepath.Join("test", strings.TrimPrefix(path, "test/")),
+	if module.Std {
+		switch module.Path {
+		case "date":
+			i.registerDateLibrary()
+		case "http":
+			i.registerHTTPLibrary()
+		case "time":
+			i.registerTimeLibrary()
+		default:
+			return i.interpretStdLib(module.Path, module.Source)
 		}
+		return nil
+	}
 
-		var source []byte
-		var foundPath string
-
-		for _, searchPath := range searchPaths {
-			source, err = os.ReadFile(searchPath)
-			if err == nil {
-				foundPath = searchPath
-				break
-			}
-		}
+	foundPath := module.Path
+	if imported, ok := i.modules[foundPath]; ok {
+		i.adoptModule(imported, foundPath)
+		return nil
+	}
 
-		if foundPath == "" {
-			baseName := filepath.Base(strings.TrimSuffix(libName, ".bn"))
-			if lib, exists := stdlib.StdLibFiles[baseName]; exists {
-				switch baseName {
-				case "date":
-					i.registerDateLibrary()
-					return nil
-				case "http":
-					i.registerHTTPLibrary()
-					return nil
-				case "time":
-					i.registerTimeLibrary()
-					return nil
-				default:
-					return i.interpretStdLib(baseName, lib)
-				}
-			}
+	l := lexer.New(module.Source)
+	tokens, err := l.Tokenize()
+	if err != nil {
+		return errcode.Errorf("BRN0402", "lexical error in import %s: %v", foundPath, err)
+	}
 
-			return fmt.Errorf("could not find import file: %s (tried paths: %v)", libName, searchPaths)
-		}
+	p := parser.New(tokens)
+	program, err := p.Parse()
+	if err != nil {
+		return errcode.Errorf("BRN0402", "parse error in import %s: %v", foundPath, err)
+	}
 
-		l := lexer.New(string(source))
-		tokens, err := l.Tokenize()
-		if err != nil {
-			return fmt.Errorf("lexical error in import %s: %v", foundPath, err)
-		}
+	importInterpreter := New()
+	importInterpreter.modules = i.modules
+	importInterpreter.RegisterBuiltinStandardLibraries()
+	i.inheritHooks(importInterpreter, foundPath)
+	importInterpreter.importPaths = i.importPaths
 
-		p := parser.New(tokens)
-		program, err := p.Parse()
-		if err != nil {
-			return fmt.Errorf("parse error in import %s: %v", foundPath, err)
-		}
+	for mod := range i.importedModules {
+		importInterpreter.importedModules[mod] = true
+	}
 
-		importInterpreter := New()
-		importInterpreter.addBuiltins()
-		importInterpreter.RegisterBuiltinStandardLibraries()
+	_, err = importInterpreter.Interpret(program)
+	if err != nil {
+		return errcode.Errorf("BRN0402", "error interpreting import %s: %v", foundPath, err)
+	}
 
-		for mod := range i.importedModules {
-			importInterpreter.importedModules[mod] = true
-		}
+	imported := &importedModule{interp: importInterpreter, program: program}
+	i.modules[foundPath] = imported
+	i.adoptModule(imported, foundPath)
+	return nil
+}
 
-		_, err = importInterpreter.Interpret(program)
-		if err != nil {
-			return fmt.Errorf("error interpreting import %s: %v", foundPath, err)
-		}
+// adoptModule takes in the functions, types, classes and globals of a file
+// imported from path
+func (i *Interpreter) adoptModule(imported *importedModule, path string) {
+	importInterpreter, program := imported.interp, imported.program
+	for name, typeDef := range importInterpreter.types {
+		i.types[name] = typeDef
+	}
 
-		for name, typeDef := range importInterpreter.types {
-			i.types[name] = typeDef
+	for name, fn := range importInterpreter.functions {
+		if name != "main" {
+			i.functions[name] = fn
+			i.adoptFunction(importInterpreter, fn)
 		}
+	}
 
-		for name, fn := range importInterpreter.functions {
-			if name != "main" {
-				i.functions[name] = fn
-			}
+	for name, class := range importInterpreter.classes {
+		if !i.adoptsClass(name, program) {
+			continue
 		}
-
-		for name, class := range importInterpreter.classes {
-			i.classes[name] = class
+		i.classes[name] = class
+		for _, method := range class.Methods {
+			i.adoptFunction(importInterpreter, method)
 		}
-
-		for name, value := range importInterpreter.environment {
-			if _, exists := i.environment[name]; !exists {
-				i.environment[name] = value
-			}
+		for _, method := range class.Statics {
+			i.adoptFunction(importInterpreter, method)
 		}
-
-		return nil
-	}
-
-	basename := filepath.Base(libName)
-	if strings.HasSuffix(basename, ".bn") {
-		basename = strings.TrimSuffix(basename, ".bn")
 	}
 
-	if lib, exists := stdlib.StdLibFiles[basename]; exists {
-		switch basename {
-		case "date":
-			i.registerDateLibrary()
-		case "http":
-			i.registerHTTPLibrary()
-		case "time":
-			i.registerTimeLibrary()
-		default:
-			return i.interpretStdLib(basename, lib)
-		}
-		return nil
-	}
+	i.adoptValues(importInterpreter)
 
-	return fmt.Errorf("could not find import: %s", imp.Path)
+	i.loadedFiles = append(i.loadedFiles, path)
+	i.loadedFiles = append(i.loadedFiles, importInterpreter.loadedFiles...)
 }
 
 func (i *Interpreter) interpretStdLib(name, source string) error {
-	l := lexer.New(source)
-	tokens, err := l.Tokenize()
-	if err != nil {
-		return err
+	for builtinName, fn := range stdlib.NativeBuiltins(name) {
+		i.builtins.Define(builtinName, &BuiltinFunction{Name: builtinName, Fn: native(fn)})
 	}
 
-	p := parser.New(tokens)
-	program, err := p.Parse()
+	if imported, ok := i.modules[name]; ok {
+		i.adoptLibrary(imported)
+		return nil
+	}
+
+	program, err := parseLibrary(source)
 	if err != nil {
 		return err
 	}
 
 	importInterpreter := New()
-	importInterpreter.addBuiltins()
-	importInterpreter.RegisterBuiltinStandardLibraries()
+	importInterpreter.host = i.host
+	importInterpreter.modules = i.modules
+	for mod := range i.importedModules {
+		importInterpreter.importedModules[mod] = true
+	}
 
 	_, err = importInterpreter.Interpret(program)
 	if err != nil {
 		return err
 	}
 
+	imported := &importedModule{interp: importInterpreter, program: program}
+	i.modules[name] = imported
+	i.adoptLibrary(imported)
+	return nil
+}
+
+// native adapts a builtin of package stdlib, which takes and returns arrays
+// as plain slices
+func native(fn stdlib.Builtin) func(args []Value) (Value, error) {
+	return func(args []Value) (Value, error) {
+		plain := make([]Value, len(args))
+		for j, arg := range args {
+			if arr, ok := arg.(*Array); ok {
+				arg = arr.Elements
+			}
+			plain[j] = arg
+		}
+		result, err := fn(plain)
+		if elements, ok := result.([]interface{}); ok {
+			return NewArray(elements), err
+		}
+		return result, err
+	}
+}
+
+// adoptLibrary takes in the functions, classes and globals of a standard
+// library written in Burn
+func (i *Interpreter) adoptLibrary(imported *importedModule) {
+	importInterpreter, program := imported.interp, imported.program
 	for name, fn := range importInterpreter.functions {
 		if name != "main" {
 			i.functions[name] = fn
+			i.functionFiles[fn] = ""
 		}
 	}
 
 	for name, class := range importInterpreter.classes {
-		i.classes[name] = class
+		if i.adoptsClass(name, program) {
+			i.classes[name] = class
+		}
 	}
 
-	for name, value := range importInterpreter.environment {
-		if _, exists := i.environment[name]; !exists {
-			i.environment[name] = value
+	i.adoptValues(importInterpreter)
+}
+
+// adoptsClass reports whether the class name of an import replaces that of
+// i: when i has none yet, or the imported program declares it. Every
+// interpreter has the date, http and time classes, which must not replace
+// classes of the same names that i declares.
+func (i *Interpreter) adoptsClass(name string, program *ast.Program) bool {
+	if _, exists := i.classes[name]; !exists {
+		return true
+	}
+	for _, decl := range program.Declarations {
+		if class, ok := decl.(*ast.ClassDeclaration); ok && class.Name == name {
+			return true
 		}
 	}
+	return false
+}
 
-	return nil
+// adoptValues copies the builtins and globals of an import that i does not
+// have yet
+func (i *Interpreter) adoptValues(from *Interpreter) {
+	for name, value := range from.builtins.values {
+		if _, exists := i.builtins.values[name]; !exists {
+			i.builtins.Define(name, value)
+		}
+	}
+	for name, value := range from.globals.values {
+		if _, exists := i.lookup(name); !exists {
+			i.globals.Define(name, value)
+		}
+	}
+}
+
+// lookup returns the value of a variable of the running code, or of a
+// builtin or constant
+func (i *Interpreter) lookup(name string) (Value, bool) {
+	if value, ok := i.environment.Get(name); ok {
+		return value, true
+	}
+	return i.builtins.Get(name)
+}
+
+// parsedLibraries caches the programs of standard libraries by source.
+// Interpreters never modify a program, so all of them can share one.
+var parsedLibraries sync.Map
+
+func parseLibrary(source string) (*ast.Program, error) {
+	if program, ok := parsedLibraries.Load(source); ok {
+		return program.(*ast.Program), nil
+	}
+
+	tokens, err := lexer.New(source).Tokenize()
+	if err != nil {
+		return nil, err
+	}
+	program, err := parser.New(tokens).Parse()
+	if err != nil {
+		return nil, err
+	}
+	Resolve(program)
+
+	actual, _ := parsedLibraries.LoadOrStore(source, program)
+	return actual.(*ast.Program), nil
 }
 
 func (i *Interpreter) executeDeclaration(decl ast.Declaration) (Value, error) {
 	if decl != nil {
-		i.setErrorPos(decl.Pos())
+		i.setErrorAt(decl)
+	}
+	if len(i.statementHooks) > 0 {
+		i.notifyStatement(decl)
+	}
+	if err := i.step(); err != nil {
+		return nil, err
 	}
+	i.host.nodes++
 
 	switch d := decl.(type) {
-	case *ast.ClassDeclaration:
+	case *ast.ClassDeclaration, *ast.InterfaceDeclaration:
 		return nil, nil
 	case *ast.TypeDefinition:
 		return nil, nil
+	case *ast.ImportDeclaration, *ast.MultiImportDeclaration:
+		// Imports are resolved by Load before any statement runs
+		return nil, nil
 	case *ast.FunctionDeclaration:
+		// Methods are registered on their type by Load
+		if d.Receiver != "" {
+			return nil, nil
+		}
+		// A function declared inside another is a closure over the scope
+		// it is declared in
+		if i.environment != i.globals {
+			if _, known := i.functionFiles[d]; !known {
+				i.functionFiles[d] = i.currentFile
+			}
+			i.define(d.Name, d.Binding, &Function{Declaration: d, closure: i.environment}, false)
+			return nil, nil
+		}
 		i.functions[d.Name] = d
 		return nil, nil
 	case *ast.VariableDeclaration:
+		// A variable declared without a value starts at the zero value of
+		// its type
+		value := ZeroValue(d.Type)
 		if d.Value != nil {
-			value, err := i.evaluateExpression(d.Value)
-			if err != nil {
+			var err error
+			if value, err = i.evaluateExpression(d.Value); err != nil {
 				return nil, err
 			}
-			i.environment[d.Name] = value
 		}
+		i.define(d.Name, d.Binding, value, d.IsConst)
 		return nil, nil
 	case *ast.ExpressionStatement:
 		return i.evaluateExpression(d.Expression)
 	case *ast.ReturnStatement:
-		if d.Value == nil {
-			return nil, nil
+		var value Value
+		if d.Value != nil {
+			var err error
+			if value, err = i.evaluateExpression(d.Value); err != nil {
+				return nil, err
+			}
 		}
-		return i.evaluateExpression(d.Value)
+		i.control = controlReturn
+		return value, nil
+	case *ast.BreakStatement:
+		i.control, i.label = controlBreak, d.Label
+		return nil, nil
+	case *ast.ContinueStatement:
+		i.control, i.label = controlContinue, d.Label
+		return nil, nil
 	case *ast.IfStatement:
 		condition, err := i.evaluateExpression(d.Condition)
 		if err != nil {
@@ -345,52 +766,60 @@ func (i *Interpreter) executeDeclaration(decl ast.Declaration) (Value, error) {
 		}
 
 		if cond, ok := condition.(bool); ok {
+			if len(i.statementHooks) > 0 {
+				i.notifyBranch(d, cond)
+			}
+			branch := d.ElseBranch
 			if cond {
-				for _, stmt := range d.ThenBranch {
-					result, err := i.executeDeclaration(stmt)
-					if err != nil {
-						return nil, err
-					}
-					if _, ok := stmt.(*ast.ReturnStatement); ok {
-						return result, nil
-					}
-				}
-			} else if d.ElseBranch != nil {
-				for _, stmt := range d.ElseBranch {
-					result, err := i.executeDeclaration(stmt)
-					if err != nil {
-						return nil, err
-					}
-					if _, ok := stmt.(*ast.ReturnStatement); ok {
-						return result, nil
-					}
-				}
+				branch = d.ThenBranch
 			}
+			// Only a return gives the if statement a value
+			result, err := i.executeScope(branch)
+			if err != nil || i.control != controlReturn {
+				return nil, err
+			}
+			return result, nil
 		}
 		return nil, nil
+	case *ast.BlockStatement:
+		result, err := i.executeScope(d.Statements)
+		if err != nil || i.control != controlReturn {
+			return nil, err
+		}
+		return result, nil
+	case *ast.MatchStatement:
+		return i.executeMatch(d)
+	case *ast.ForInStatement:
+		return i.executeForIn(d)
 	case *ast.WhileStatement:
 		for {
+			if err := i.step(); err != nil {
+				return nil, err
+			}
 			condition, err := i.evaluateExpression(d.Condition)
 			if err != nil {
 				return nil, err
 			}
 
-			if cond, ok := condition.(bool); ok && cond {
-				for _, stmt := range d.Body {
-					result, err := i.executeDeclaration(stmt)
-					if err != nil {
-						return nil, err
-					}
-					if _, ok := stmt.(*ast.ReturnStatement); ok {
-						return result, nil
-					}
-				}
-			} else {
+			if cond, ok := condition.(bool); !ok || !cond {
 				break
 			}
+
+			result, err := i.executeScope(d.Body)
+			if err != nil {
+				return nil, err
+			}
+			if i.leaveLoop(d.Label) {
+				return result, nil
+			}
 		}
 		return nil, nil
 	case *ast.ForStatement:
+		// The variable the initializer declares is only seen by the loop
+		prevEnv := i.environment
+		i.environment = NewEnvironment(prevEnv)
+		defer func() { i.environment = prevEnv }()
+
 		if d.Initializer != nil {
 			_, err := i.executeDeclaration(d.Initializer)
 			if err != nil {
@@ -399,6 +828,9 @@ func (i *Interpreter) executeDeclaration(decl ast.Declaration) (Value, error) {
 		}
 
 		for {
+			if err := i.step(); err != nil {
+				return nil, err
+			}
 			if d.Condition != nil {
 				condition, err := i.evaluateExpression(d.Condition)
 				if err != nil {
@@ -409,14 +841,12 @@ func (i *Interpreter) executeDeclaration(decl ast.Declaration) (Value, error) {
 				}
 			}
 
-			for _, stmt := range d.Body {
-				result, err := i.executeDeclaration(stmt)
-				if err != nil {
-					return nil, err
-				}
-				if _, ok := stmt.(*ast.ReturnStatement); ok {
-					return result, nil
-				}
+			result, err := i.executeScope(d.Body)
+			if err != nil {
+				return nil, err
+			}
+			if i.leaveLoop(d.Label) {
+				return result, nil
 			}
 
 			if d.Increment != nil {
@@ -428,78 +858,190 @@ func (i *Interpreter) executeDeclaration(decl ast.Declaration) (Value, error) {
 		}
 		return nil, nil
 	default:
-		return nil, fmt.Errorf("unknown declaration type: %T", decl)
+		return nil, errcode.Errorf("BRN0515", "unknown declaration type: %T", decl)
+	}
+}
+
+// executeBlock runs statements until one of them breaks, continues or
+// returns, and returns the value of the last one run
+func (i *Interpreter) executeBlock(stmts []ast.Declaration) (Value, error) {
+	var result Value
+	for _, stmt := range stmts {
+		var err error
+		result, err = i.executeDeclaration(stmt)
+		if err != nil {
+			return nil, err
+		}
+		if i.control != controlNone {
+			break
+		}
 	}
+	return result, nil
+}
+
+// executeScope runs the statements of a block in a scope of their own, so
+// that the variables they declare are not seen after the block
+func (i *Interpreter) executeScope(stmts []ast.Declaration) (Value, error) {
+	prevEnv := i.environment
+	i.environment = NewEnvironment(prevEnv)
+	defer func() { i.environment = prevEnv }()
+	return i.executeBlock(stmts)
+}
+
+// leaveLoop handles the control flow of the body of a loop with a label,
+// or none, that has run. It reports whether the loop ends, by a break or
+// by a return, break or continue that a function or loop around the loop
+// still has to see.
+func (i *Interpreter) leaveLoop(label string) bool {
+	if i.label != "" && i.label != label && (i.control == controlBreak || i.control == controlContinue) {
+		return true
+	}
+	switch i.control {
+	case controlBreak:
+		i.control = controlNone
+		return true
+	case controlContinue:
+		i.control = controlNone
+	case controlReturn:
+		return true
+	}
+	return false
 }
 
 func (i *Interpreter) executeBuiltin(name string, args []Value) (Value, error) {
-	if builtinFunc, ok := i.environment[name]; ok {
+	if err := i.host.policy.check(name); err != nil {
+		return nil, err
+	}
+	i.countBuiltin(name)
+	if builtinFunc, ok := i.builtins.Get(name); ok {
 		if bf, ok := builtinFunc.(*BuiltinFunction); ok {
 			return bf.Call(args)
 		}
 	}
-	return nil, fmt.Errorf("undefined builtin function: %s", name)
+	return nil, errcode.Errorf("BRN0509", "undefined builtin function: %s", name)
 }
 
 func (i *Interpreter) executeFunction(fn *ast.FunctionDeclaration, args []Value) (Value, error) {
-	if fn.Body == nil {
-		return i.executeBuiltin(fn.Name, args)
-	}
+	return i.executeClosure(fn, nil, args)
+}
 
-	prevEnv := make(map[string]Value)
-	for k, v := range i.environment {
-		prevEnv[k] = v
+// executeClosure calls fn with its body in a new scope enclosed by closure.
+// Without a closure, the body only sees its parameters and the builtins.
+func (i *Interpreter) executeClosure(fn *ast.FunctionDeclaration, closure *Environment, args []Value) (Value, error) {
+	if len(i.invocationHooks) == 0 {
+		return i.runFunction(fn, closure, args)
 	}
+	name := i.functionName(fn)
+	return i.invoke(name, args, i.isNative(fn, name), func() (Value, error) {
+		return i.runFunction(fn, closure, args)
+	})
+}
 
-	newEnv := make(map[string]Value)
+// isNative reports whether fn, called name, is the declaration of a
+// function of a native library, implemented by a builtin of the same name
+func (i *Interpreter) isNative(fn *ast.FunctionDeclaration, name string) bool {
+	if len(fn.Body) != 0 || name == fn.Name {
+		return false
+	}
+	_, exists := i.builtins.Get(name)
+	return exists
+}
 
-	for k, v := range i.environment {
-		if _, ok := v.(*BuiltinFunction); ok {
-			newEnv[k] = v
+func (i *Interpreter) runFunction(fn *ast.FunctionDeclaration, closure *Environment, args []Value) (Value, error) {
+	if err := i.enterCall(fn); err != nil {
+		return nil, err
+	}
+	defer i.exitCall()
+	if len(i.callHooks) > 0 {
+		name := i.enterFunction(fn)
+		defer i.exitFunction(name)
+	}
+	prevFile := i.currentFile
+	i.currentFile = i.fileOf(fn)
+	defer func() { i.currentFile = prevFile }()
+
+	// Methods of native libraries are declared with empty bodies and
+	// implemented by a builtin named Class.method
+	if len(fn.Body) == 0 {
+		if name := i.functionName(fn); i.isNative(fn, name) {
+			return i.executeBuiltin(name, args)
 		}
 	}
+	if fn.Body == nil {
+		return i.executeBuiltin(fn.Name, args)
+	}
 
-	i.environment = newEnv
+	// The frame of the call only encloses the closure, if any: the builtins,
+	// which are shared by all calls, are looked up after it
+	prevEnv := i.environment
+	i.environment = newFrame(closure, len(fn.Parameters))
+	defer func() { i.environment = prevEnv }()
 
 	for j, param := range fn.Parameters {
+		// A variadic parameter, the last, gets the rest of the arguments
+		if strings.HasPrefix(param.Type, "...") {
+			rest := []Value{}
+			if j < len(args) {
+				if err := i.allocate(16 * (len(args) - j)); err != nil {
+					return nil, err
+				}
+				rest = append(rest, args[j:]...)
+			}
+			i.environment.defineSlot(j, param.Name, NewArray(rest), false)
+			break
+		}
 		if j < len(args) {
-			i.environment[param.Name] = args[j]
+			i.environment.defineSlot(j, param.Name, args[j], false)
 		}
 	}
 
-	var result Value
-	for _, stmt := range fn.Body {
-		var err error
-		result, err = i.executeDeclaration(stmt)
-		if err != nil {
-			return nil, err
-		}
+	result, err := i.executeBlock(fn.Body)
+	i.control = controlNone
+	if err != nil {
+		return nil, err
 	}
 
-	i.environment = prevEnv
-
 	return result, nil
 }
 
+// GetVariables returns the global variables together with the builtins
+// and constants
 func (i *Interpreter) GetVariables() map[string]interface{} {
-	if i.environment == nil {
-		return make(map[string]interface{})
-	}
 	result := make(map[string]interface{})
-	for k, v := range i.environment {
+	for k, v := range i.builtins.values {
+		result[k] = v
+	}
+	for k, v := range i.globals.values {
 		result[k] = v
 	}
 	return result
 }
 
-func (i *Interpreter) setErrorPos(pos int) {
-	i.errorPos = pos
+// setErrorAt records node as the place of the next error. A nil node
+// stands for the start of the file.
+func (i *Interpreter) setErrorAt(node ast.Node) {
+	i.errorPos, i.errorLoc = 0, ast.Location{}
+	if node != nil {
+		i.errorPos, i.errorLoc = node.Pos(), node.Loc()
+	}
+	i.errorFile = i.currentFile
 }
 
 func (i *Interpreter) Position() int {
 	return i.errorPos
 }
 
+// Location is the line and column of Position
+func (i *Interpreter) Location() ast.Location {
+	return i.errorLoc
+}
+
+// ErrorFile returns the file that Position refers to, which differs from the
+// file passed to SetFile when the error happened inside an imported function
+func (i *Interpreter) ErrorFile() string {
+	return i.errorFile
+}
+
 func (i *Interpreter) AddFunction(name string, fn *ast.FunctionDeclaration) {
 	i.functions[name] = fn
 }
@@ -508,8 +1050,16 @@ func (i *Interpreter) GetFunctions() map[string]*ast.FunctionDeclaration {
 	return i.functions
 }
 
+// DefineConstant makes a value visible under name at the top level and, like
+// the built-ins, inside every function
+func (i *Interpreter) DefineConstant(name string, value Value) {
+	i.constants[name] = value
+	i.builtins.Define(name, value)
+}
+
+// AddVariable defines a global variable, unless name is already in use
 func (i *Interpreter) AddVariable(name string, value interface{}) {
-	if _, exists := i.environment[name]; !exists {
-		i.environment[name] = value
+	if _, exists := i.lookup(name); !exists {
+		i.globals.Define(name, value)
 	}
 }
@@ -0,0 +1,211 @@
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
+)
+
+// Map is a Burn map. Its keys are numbers, strings or bools, and Keys lists
+// them in the order they were added.
+type Map struct {
+	Entries map[Value]Value
+	Keys    []Value
+}
+
+// NewMap returns an empty map
+func NewMap() *Map {
+	return &Map{Entries: make(map[Value]Value)}
+}
+
+// Get returns the value of key
+func (m *Map) Get(key Value) (Value, bool) {
+	value, exists := m.Entries[key]
+	return value, exists
+}
+
+// Set adds key or changes its value
+func (m *Map) Set(key, value Value) {
+	if _, exists := m.Entries[key]; !exists {
+		m.Keys = append(m.Keys, key)
+	}
+	m.Entries[key] = value
+}
+
+// Delete removes key, reporting whether it was there
+func (m *Map) Delete(key Value) bool {
+	if _, exists := m.Entries[key]; !exists {
+		return false
+	}
+	delete(m.Entries, key)
+	for j, k := range m.Keys {
+		if k == key {
+			m.Keys = append(m.Keys[:j], m.Keys[j+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// Values returns the values of m in the order of its keys
+func (m *Map) Values() []Value {
+	values := make([]Value, len(m.Keys))
+	for j, key := range m.Keys {
+		values[j] = m.Entries[key]
+	}
+	return values
+}
+
+func (m *Map) String() string {
+	var sb strings.Builder
+	sb.WriteString("{")
+	for j, key := range m.Keys {
+		if j > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "%v: %v", key, m.Entries[key])
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// checkKey fails for values that cannot be map keys
+func checkKey(key Value) error {
+	switch key.(type) {
+	case float64, string, bool:
+		return nil
+	}
+	return errcode.Errorf("BRN0511", "map keys must be numbers, strings or bools, got %T", key)
+}
+
+func (i *Interpreter) evaluateMapLiteral(expr *ast.MapLiteralExpression) (Value, error) {
+	m := NewMap()
+	for _, entry := range expr.Entries {
+		key, err := i.evaluateExpression(entry.Key)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkKey(key); err != nil {
+			return nil, err
+		}
+		value, err := i.evaluateExpression(entry.Value)
+		if err != nil {
+			return nil, err
+		}
+		m.Set(key, value)
+	}
+	if err := i.allocate(32 * len(m.Keys)); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// evaluateIndexAssignment sets an element of an array or the value of a
+// key of a map
+func (i *Interpreter) evaluateIndexAssignment(expr *ast.IndexAssignmentExpression) (Value, error) {
+	target, err := i.evaluateExpression(expr.Array)
+	if err != nil {
+		return nil, err
+	}
+	index, err := i.evaluateExpression(expr.Index)
+	if err != nil {
+		return nil, err
+	}
+	value, err := i.evaluateExpression(expr.Value)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.SetIndex(target, index, value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// SetIndex changes the element of an array or value of a map at index
+func (i *Interpreter) SetIndex(target, index, value Value) error {
+	switch t := target.(type) {
+	case *Map:
+		if err := checkKey(index); err != nil {
+			return err
+		}
+		if _, exists := t.Entries[index]; !exists {
+			if err := i.allocate(32); err != nil {
+				return err
+			}
+		}
+		t.Set(index, value)
+	case *Array:
+		indexNumber, ok := index.(float64)
+		if !ok {
+			return errcode.Errorf("BRN0511", "array index must be a number")
+		}
+		idx := int(indexNumber)
+		if idx < 0 || idx >= len(t.Elements) {
+			return errcode.Errorf("BRN0502", "array index out of bounds: %d", idx)
+		}
+		t.Elements[idx] = value
+	default:
+		return errcode.Errorf("BRN0511", "cannot index into %T", target)
+	}
+	return nil
+}
+
+func (i *Interpreter) addMapBuiltins() {
+	mapArg := func(name string, args []Value, count int) (*Map, error) {
+		if len(args) != count {
+			return nil, errcode.Errorf("BRN0510", "%s expects exactly %d arguments", name, count)
+		}
+		m, ok := args[0].(*Map)
+		if !ok {
+			return nil, errcode.Errorf("BRN0510", "%s expects a map, got %T", name, args[0])
+		}
+		return m, nil
+	}
+
+	i.builtins.values["keys"] = &BuiltinFunction{
+		Name: "keys",
+		Fn: func(args []Value) (Value, error) {
+			m, err := mapArg("keys", args, 1)
+			if err != nil {
+				return nil, err
+			}
+			return NewArray(append([]Value(nil), m.Keys...)), nil
+		},
+	}
+
+	i.builtins.values["values"] = &BuiltinFunction{
+		Name: "values",
+		Fn: func(args []Value) (Value, error) {
+			m, err := mapArg("values", args, 1)
+			if err != nil {
+				return nil, err
+			}
+			return NewArray(m.Values()), nil
+		},
+	}
+
+	i.builtins.values["has"] = &BuiltinFunction{
+		Name: "has",
+		Fn: func(args []Value) (Value, error) {
+			m, err := mapArg("has", args, 2)
+			if err != nil {
+				return nil, err
+			}
+			_, exists := m.Get(args[1])
+			return exists, nil
+		},
+	}
+
+	i.builtins.values["delete"] = &BuiltinFunction{
+		Name: "delete",
+		Fn: func(args []Value) (Value, error) {
+			m, err := mapArg("delete", args, 2)
+			if err != nil {
+				return nil, err
+			}
+			return m.Delete(args[1]), nil
+		},
+	}
+}
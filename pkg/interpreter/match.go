@@ -0,0 +1,79 @@
+package interpreter
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/burnlang/burn/pkg/ast"
+)
+
+// executeMatch runs the body of the first case of stmt that matches its
+// value. Like an if statement, it only has a value when a case returns.
+func (i *Interpreter) executeMatch(stmt *ast.MatchStatement) (Value, error) {
+	value, err := i.evaluateExpression(stmt.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, matchCase := range stmt.Cases {
+		matched, err := i.matches(matchCase, value)
+		if err != nil {
+			return nil, err
+		}
+		if !matched {
+			continue
+		}
+
+		result, err := i.executeScope(matchCase.Body)
+		if err != nil || i.control != controlReturn {
+			return nil, err
+		}
+		return result, nil
+	}
+	return nil, nil
+}
+
+// matches reports whether value matches a case of a match statement
+func (i *Interpreter) matches(matchCase *ast.MatchCase, value Value) (bool, error) {
+	if matchCase.IsDefault() {
+		return true, nil
+	}
+	for _, pattern := range matchCase.Patterns {
+		patternValue, err := i.evaluateExpression(pattern)
+		if err != nil {
+			return false, err
+		}
+		if equal, err := deepEqual(value, patternValue); err != nil || equal {
+			return equal, err
+		}
+	}
+	for _, typeName := range matchCase.Types {
+		if hasType(value, typeName) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// hasType reports whether value is of the Burn type typeName. Numbers are
+// all float64, so int matches the whole ones and float matches any.
+func hasType(value Value, typeName string) bool {
+	if typeName == "any" {
+		return true
+	}
+	switch v := value.(type) {
+	case float64:
+		return typeName == "float" || typeName == "int" && v == math.Trunc(v)
+	case string:
+		return typeName == "string"
+	case *big.Int:
+		return typeName == "bigint"
+	case *Decimal:
+		return typeName == "decimal"
+	case bool:
+		return typeName == "bool"
+	case *Struct:
+		return v.TypeName == typeName
+	}
+	return false
+}
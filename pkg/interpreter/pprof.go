@@ -0,0 +1,145 @@
+package interpreter
+
+import (
+	"compress/gzip"
+	"io"
+	"sort"
+)
+
+// WritePprof writes the profile in the gzip-compressed protobuf format read
+// by `go tool pprof`. Each sample is a Burn call stack with two values: the
+// number of calls and the self time in nanoseconds.
+func (p *Profiler) WritePprof(w io.Writer) error {
+	strs := newStringTable()
+
+	keys := make([]string, 0, len(p.samples))
+	for key := range p.samples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	functionIDs := make(map[string]uint64)
+	var functionNames []string
+	for _, key := range keys {
+		for _, name := range p.samples[key].stack {
+			if _, ok := functionIDs[name]; !ok {
+				functionIDs[name] = uint64(len(functionNames) + 1)
+				functionNames = append(functionNames, name)
+			}
+		}
+	}
+
+	var profile protoBuffer
+	profile.message(1, valueType(strs.index("calls"), strs.index("count")))
+	profile.message(1, valueType(strs.index("time"), strs.index("nanoseconds")))
+
+	for _, key := range keys {
+		sample := p.samples[key]
+
+		locations := make([]uint64, 0, len(sample.stack))
+		for j := len(sample.stack) - 1; j >= 0; j-- {
+			locations = append(locations, functionIDs[sample.stack[j]])
+		}
+
+		var msg protoBuffer
+		msg.packedUints(1, locations)
+		msg.packedUints(2, []uint64{uint64(sample.calls), uint64(sample.self.Nanoseconds())})
+		profile.message(2, msg)
+	}
+
+	for j, name := range functionNames {
+		id := uint64(j + 1)
+
+		var line protoBuffer
+		line.uint(1, id)
+
+		var location protoBuffer
+		location.uint(1, id)
+		location.message(4, line)
+		profile.message(4, location)
+
+		var function protoBuffer
+		function.uint(1, id)
+		function.uint(2, strs.index(name))
+		function.uint(3, strs.index(name))
+		profile.message(5, function)
+	}
+
+	for _, s := range strs.values {
+		profile.bytes(6, []byte(s))
+	}
+	profile.uint(9, uint64(p.started.UnixNano()))
+	profile.uint(10, uint64(p.now().Sub(p.started).Nanoseconds()))
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(profile.buf); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+type stringTable struct {
+	values  []string
+	indexes map[string]uint64
+}
+
+func newStringTable() *stringTable {
+	return &stringTable{values: []string{""}, indexes: map[string]uint64{"": 0}}
+}
+
+func (t *stringTable) index(s string) uint64 {
+	if idx, ok := t.indexes[s]; ok {
+		return idx
+	}
+	idx := uint64(len(t.values))
+	t.values = append(t.values, s)
+	t.indexes[s] = idx
+	return idx
+}
+
+func valueType(typ, unit uint64) protoBuffer {
+	var msg protoBuffer
+	msg.uint(1, typ)
+	msg.uint(2, unit)
+	return msg
+}
+
+// protoBuffer is a minimal protocol buffer encoder covering the wire types
+// the pprof format needs
+type protoBuffer struct {
+	buf []byte
+}
+
+func (b *protoBuffer) varint(x uint64) {
+	for x >= 0x80 {
+		b.buf = append(b.buf, byte(x)|0x80)
+		x >>= 7
+	}
+	b.buf = append(b.buf, byte(x))
+}
+
+func (b *protoBuffer) uint(field int, x uint64) {
+	if x == 0 {
+		return
+	}
+	b.varint(uint64(field) << 3)
+	b.varint(x)
+}
+
+func (b *protoBuffer) bytes(field int, data []byte) {
+	b.varint(uint64(field)<<3 | 2)
+	b.varint(uint64(len(data)))
+	b.buf = append(b.buf, data...)
+}
+
+func (b *protoBuffer) message(field int, msg protoBuffer) {
+	b.bytes(field, msg.buf)
+}
+
+func (b *protoBuffer) packedUints(field int, xs []uint64) {
+	var packed protoBuffer
+	for _, x := range xs {
+		packed.varint(x)
+	}
+	b.bytes(field, packed.buf)
+}
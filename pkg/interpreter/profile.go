@@ -0,0 +1,153 @@
+package interpreter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FunctionProfile holds the timings collected for one Burn function
+type FunctionProfile struct {
+	Name       string
+	Calls      int
+	Self       time.Duration
+	Cumulative time.Duration
+}
+
+type profileFrame struct {
+	name     string
+	start    time.Time
+	children time.Duration
+}
+
+type stackSample struct {
+	stack []string
+	calls int64
+	self  time.Duration
+}
+
+// Profiler is a CallHook that measures self and cumulative time per function
+type Profiler struct {
+	stack     []profileFrame
+	functions map[string]*FunctionProfile
+	samples   map[string]*stackSample
+	started   time.Time
+	now       func() time.Time
+}
+
+func NewProfiler() *Profiler {
+	return &Profiler{
+		functions: make(map[string]*FunctionProfile),
+		samples:   make(map[string]*stackSample),
+		started:   time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (p *Profiler) EnterFunction(name string, pos int) {
+	p.stack = append(p.stack, profileFrame{name: name, start: p.now()})
+}
+
+func (p *Profiler) ExitFunction(name string) {
+	if len(p.stack) == 0 {
+		return
+	}
+
+	frame := p.stack[len(p.stack)-1]
+	p.stack = p.stack[:len(p.stack)-1]
+
+	elapsed := p.now().Sub(frame.start)
+	self := elapsed - frame.children
+	if len(p.stack) > 0 {
+		p.stack[len(p.stack)-1].children += elapsed
+	}
+
+	fn, ok := p.functions[frame.name]
+	if !ok {
+		fn = &FunctionProfile{Name: frame.name}
+		p.functions[frame.name] = fn
+	}
+	fn.Calls++
+	fn.Self += self
+	if !p.onStack(frame.name) {
+		fn.Cumulative += elapsed
+	}
+
+	stack := make([]string, 0, len(p.stack)+1)
+	for _, f := range p.stack {
+		stack = append(stack, f.name)
+	}
+	stack = append(stack, frame.name)
+
+	key := strings.Join(stack, ";")
+	sample, ok := p.samples[key]
+	if !ok {
+		sample = &stackSample{stack: stack}
+		p.samples[key] = sample
+	}
+	sample.calls++
+	sample.self += self
+}
+
+// onStack reports whether name is still active further up the call stack,
+// in which case its time is already counted by the outer call
+func (p *Profiler) onStack(name string) bool {
+	for _, frame := range p.stack {
+		if frame.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Report returns the collected profiles sorted by self time, then by
+// cumulative time and name
+func (p *Profiler) Report() []FunctionProfile {
+	report := make([]FunctionProfile, 0, len(p.functions))
+	for _, fn := range p.functions {
+		report = append(report, *fn)
+	}
+
+	sort.Slice(report, func(a, b int) bool {
+		if report[a].Self != report[b].Self {
+			return report[a].Self > report[b].Self
+		}
+		if report[a].Cumulative != report[b].Cumulative {
+			return report[a].Cumulative > report[b].Cumulative
+		}
+		return report[a].Name < report[b].Name
+	})
+	return report
+}
+
+// WriteReport prints the profile as a table
+func (p *Profiler) WriteReport(w io.Writer) {
+	report := p.Report()
+
+	nameWidth := len("function")
+	for _, fn := range report {
+		if len(fn.Name) > nameWidth {
+			nameWidth = len(fn.Name)
+		}
+	}
+
+	fmt.Fprintf(w, "%-*s %8s %12s %12s\n", nameWidth, "function", "calls", "self", "cumulative")
+	for _, fn := range report {
+		fmt.Fprintf(w, "%-*s %8d %12s %12s\n", nameWidth, fn.Name, fn.Calls,
+			formatDuration(fn.Self), formatDuration(fn.Cumulative))
+	}
+	fmt.Fprintf(w, "total time: %s\n", formatDuration(p.now().Sub(p.started)))
+}
+
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return fmt.Sprintf("%.2fs", d.Seconds())
+	case d >= time.Millisecond:
+		return fmt.Sprintf("%.2fms", float64(d)/float64(time.Millisecond))
+	default:
+		return fmt.Sprintf("%.2fµs", float64(d)/float64(time.Microsecond))
+	}
+}
@@ -0,0 +1,175 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
+)
+
+// Range is the value of a range expression: the numbers from Start up to
+// but not including End, Step apart. A negative Step counts down.
+type Range struct {
+	Start float64
+	End   float64
+	Step  float64
+}
+
+func (r *Range) String() string {
+	if r.Step == 1 {
+		return fmt.Sprintf("%g..%g", r.Start, r.End)
+	}
+	return fmt.Sprintf("%g..%g step %g", r.Start, r.End, r.Step)
+}
+
+// Len returns how many numbers r has
+func (r *Range) Len() int {
+	n := math.Ceil((r.End - r.Start) / r.Step)
+	if n <= 0 || math.IsNaN(n) {
+		return 0
+	}
+	return int(n)
+}
+
+// At returns the number of r at index k
+func (r *Range) At(k int) float64 {
+	return r.Start + float64(k)*r.Step
+}
+
+func (i *Interpreter) evaluateRange(expr *ast.RangeExpression) (*Range, error) {
+	bounds := make([]Value, 3)
+	for j, bound := range []ast.Expression{expr.Start, expr.End, expr.Step} {
+		if bound == nil {
+			continue
+		}
+		value, err := i.evaluateExpression(bound)
+		if err != nil {
+			return nil, err
+		}
+		bounds[j] = value
+	}
+	r, err := NewRange(bounds[0], bounds[1], bounds[2])
+	// A step of zero is the fault of the whole expression
+	if err != nil && r != nil {
+		i.setErrorAt(expr)
+	}
+	return r, err
+}
+
+// NewRange returns the range of the values of the bounds and step of a
+// range expression, where a missing bound or step is nil. With a step of
+// zero it returns the range as well as the error.
+func NewRange(start, end, step Value) (*Range, error) {
+	r := &Range{Step: 1}
+	for _, bound := range []struct {
+		value  Value
+		number *float64
+	}{{start, &r.Start}, {end, &r.End}, {step, &r.Step}} {
+		if bound.value == nil {
+			continue
+		}
+		number, ok := bound.value.(float64)
+		if !ok {
+			return nil, errcode.Errorf("BRN0511", "range bounds and step must be numbers, got %T", bound.value)
+		}
+		*bound.number = number
+	}
+	if r.Step == 0 {
+		return r, errcode.Errorf("BRN0511", "range step cannot be zero")
+	}
+	return r, nil
+}
+
+// evaluateArrayElements evaluates the elements of an array literal, in
+// which a range stands for its numbers
+func (i *Interpreter) evaluateArrayElements(elements []ast.Expression) ([]Value, error) {
+	values := make([]Value, 0, len(elements))
+	for _, element := range elements {
+		if rangeExpr, ok := element.(*ast.RangeExpression); ok {
+			r, err := i.evaluateRange(rangeExpr)
+			if err != nil {
+				return nil, err
+			}
+			n := r.Len()
+			if err := i.allocate(16 * n); err != nil {
+				return nil, err
+			}
+			for k := 0; k < n; k++ {
+				values = append(values, r.At(k))
+			}
+			continue
+		}
+		value, err := i.evaluateExpression(element)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// executeForIn runs the body of a for-in loop for each element of an
+// array, number of a range, key of a map, character of a string or byte of
+// bytes, each time in a new scope binding the variable
+func (i *Interpreter) executeForIn(stmt *ast.ForInStatement) (Value, error) {
+	iterable, err := i.evaluateExpression(stmt.Iterable)
+	if err != nil {
+		return nil, err
+	}
+	length, at, err := Elements(iterable)
+	if err != nil {
+		return nil, err
+	}
+
+	prevEnv := i.environment
+	defer func() { i.environment = prevEnv }()
+
+	for k := 0; k < length; k++ {
+		if err := i.step(); err != nil {
+			return nil, err
+		}
+		i.environment = NewEnvironment(prevEnv)
+		i.define(stmt.Variable, stmt.Binding, at(k), false)
+
+		result, err := i.executeBlock(stmt.Body)
+		if err != nil {
+			return nil, err
+		}
+		if i.leaveLoop(stmt.Label) {
+			return result, nil
+		}
+	}
+	return nil, nil
+}
+
+// Elements returns the number of elements a for-in loop goes through in
+// iterable and a function returning the element at an index
+func Elements(iterable Value) (int, func(k int) Value, error) {
+	var length int
+	var at func(k int) Value
+	switch it := iterable.(type) {
+	case *Range:
+		length = it.Len()
+		at = func(k int) Value { return number(it.At(k)) }
+	case *Array:
+		elements := slices.Clone(it.Elements)
+		length = len(elements)
+		at = func(k int) Value { return elements[k] }
+	case *Map:
+		keys := append([]Value(nil), it.Keys...)
+		length = len(keys)
+		at = func(k int) Value { return keys[k] }
+	case string:
+		chars := []rune(it)
+		length = len(chars)
+		at = func(k int) Value { return character(chars[k]) }
+	case []byte:
+		length = len(it)
+		at = func(k int) Value { return number(float64(it[k])) }
+	default:
+		return 0, nil, errcode.Errorf("BRN0511", "cannot iterate over a value of type %T", iterable)
+	}
+	return length, at, nil
+}
@@ -0,0 +1,241 @@
+package interpreter
+
+import (
+	"github.com/burnlang/burn/pkg/ast"
+)
+
+// scopeResolver binds the names of the local variables of functions to the
+// slots they are kept in, so that the interpreter finds a variable by
+// indexing rather than by looking its name up in every scope around it.
+// Its scopes are those the interpreter makes at run time: one for the
+// parameters and body of a function, one for each block, one for the
+// initializer of a for loop and one for each iteration of a for-in loop.
+// Names outside of functions, such as those of globals, builtins and
+// functions, are left unbound and looked up by name.
+type scopeResolver struct {
+	// scopes are the scopes around the code being resolved, innermost
+	// last, back to the outermost function; empty outside of functions
+	scopes []*resolverScope
+}
+
+type resolverScope struct {
+	names []string
+}
+
+// Resolve binds the local variables of program to slots. A program is only
+// resolved once, so a resolved program may be run by many interpreters at
+// the same time.
+func Resolve(program *ast.Program) {
+	if program.Resolved() {
+		return
+	}
+	r := &scopeResolver{}
+	for _, decl := range program.Declarations {
+		r.declaration(decl)
+	}
+	program.MarkResolved()
+}
+
+// declare adds name to the innermost scope and returns its binding, or nil
+// outside of functions
+func (r *scopeResolver) declare(name string) *ast.Binding {
+	if len(r.scopes) == 0 {
+		return nil
+	}
+	scope := r.scopes[len(r.scopes)-1]
+	scope.names = append(scope.names, name)
+	return &ast.Binding{Slot: len(scope.names) - 1}
+}
+
+// lookup returns the binding of the innermost variable called name, or nil
+// when no scope of a function declares it
+func (r *scopeResolver) lookup(name string) *ast.Binding {
+	for depth := 0; depth < len(r.scopes); depth++ {
+		scope := r.scopes[len(r.scopes)-1-depth]
+		for slot := len(scope.names) - 1; slot >= 0; slot-- {
+			if scope.names[slot] == name {
+				return &ast.Binding{Depth: depth, Slot: slot}
+			}
+		}
+	}
+	return nil
+}
+
+// inScope resolves code in a new scope, if it is inside a function
+func (r *scopeResolver) inScope(resolve func()) {
+	if len(r.scopes) == 0 {
+		resolve()
+		return
+	}
+	r.scopes = append(r.scopes, &resolverScope{})
+	resolve()
+	r.scopes = r.scopes[:len(r.scopes)-1]
+}
+
+func (r *scopeResolver) block(stmts []ast.Declaration) {
+	r.inScope(func() { r.statements(stmts) })
+}
+
+func (r *scopeResolver) statements(stmts []ast.Declaration) {
+	for _, stmt := range stmts {
+		r.declaration(stmt)
+	}
+}
+
+// function resolves the body of a function, whose parameters take the first
+// slots of its scope. The scopes around it are those its closure sees: none
+// for a top-level function or method.
+func (r *scopeResolver) function(params []ast.Parameter, body []ast.Declaration) {
+	r.scopes = append(r.scopes, &resolverScope{})
+	for _, param := range params {
+		r.declare(param.Name)
+	}
+	r.statements(body)
+	r.scopes = r.scopes[:len(r.scopes)-1]
+}
+
+// topLevel resolves a function outside of the scopes of the code around it
+func (r *scopeResolver) topLevel(fn *ast.FunctionDeclaration) {
+	saved := r.scopes
+	r.scopes = nil
+	r.function(fn.Parameters, fn.Body)
+	r.scopes = saved
+}
+
+func (r *scopeResolver) declaration(decl ast.Declaration) {
+	switch d := decl.(type) {
+	case *ast.FunctionDeclaration:
+		// A function declared inside another is a variable holding a
+		// closure, which its own body can call
+		if d.Receiver == "" && len(r.scopes) > 0 {
+			d.Binding = r.declare(d.Name)
+			r.function(d.Parameters, d.Body)
+			return
+		}
+		r.topLevel(d)
+	case *ast.ClassDeclaration:
+		saved := r.scopes
+		r.scopes = nil
+		for _, fields := range [][]*ast.VariableDeclaration{d.Fields, d.StaticFields} {
+			for _, field := range fields {
+				r.expression(field.Value)
+			}
+		}
+		for _, method := range d.Methods {
+			r.topLevel(method)
+		}
+		for _, method := range d.StaticMethods {
+			r.topLevel(method)
+		}
+		r.scopes = saved
+	case *ast.VariableDeclaration:
+		r.expression(d.Value)
+		d.Binding = r.declare(d.Name)
+	case *ast.ExpressionStatement:
+		r.expression(d.Expression)
+	case *ast.ReturnStatement:
+		r.expression(d.Value)
+	case *ast.IfStatement:
+		r.expression(d.Condition)
+		r.block(d.ThenBranch)
+		r.block(d.ElseBranch)
+	case *ast.BlockStatement:
+		r.block(d.Statements)
+	case *ast.WhileStatement:
+		r.expression(d.Condition)
+		r.block(d.Body)
+	case *ast.ForStatement:
+		r.inScope(func() {
+			if d.Initializer != nil {
+				r.declaration(d.Initializer)
+			}
+			r.expression(d.Condition)
+			r.expression(d.Increment)
+			r.block(d.Body)
+		})
+	case *ast.ForInStatement:
+		r.expression(d.Iterable)
+		r.inScope(func() {
+			d.Binding = r.declare(d.Variable)
+			r.statements(d.Body)
+		})
+	case *ast.MatchStatement:
+		r.expression(d.Value)
+		for _, matchCase := range d.Cases {
+			for _, pattern := range matchCase.Patterns {
+				r.expression(pattern)
+			}
+			r.block(matchCase.Body)
+		}
+	}
+}
+
+func (r *scopeResolver) expression(expr ast.Expression) {
+	switch e := expr.(type) {
+	case *ast.VariableExpression:
+		e.Binding = r.lookup(e.Name)
+	case *ast.AssignmentExpression:
+		r.expression(e.Value)
+		e.Binding = r.lookup(e.Name)
+	case *ast.CompoundAssignmentExpression:
+		r.expression(e.Value)
+		e.Binding = r.lookup(e.Name)
+	case *ast.LambdaExpression:
+		r.function(e.Parameters, e.Body)
+	case *ast.BinaryExpression:
+		r.expression(e.Left)
+		r.expression(e.Right)
+	case *ast.UnaryExpression:
+		r.expression(e.Right)
+	case *ast.GroupingExpression:
+		r.expression(e.Expression)
+	case *ast.CallExpression:
+		r.expression(e.Callee)
+		for _, arg := range e.Arguments {
+			r.expression(arg)
+		}
+	case *ast.ClassMethodCallExpression:
+		for _, arg := range e.Arguments {
+			r.expression(arg)
+		}
+	case *ast.GetExpression:
+		r.expression(e.Object)
+	case *ast.SetExpression:
+		r.expression(e.Object)
+		r.expression(e.Value)
+	case *ast.IndexExpression:
+		r.expression(e.Array)
+		r.expression(e.Index)
+	case *ast.SliceExpression:
+		r.expression(e.Array)
+		r.expression(e.Start)
+		r.expression(e.End)
+	case *ast.IndexAssignmentExpression:
+		r.expression(e.Array)
+		r.expression(e.Index)
+		r.expression(e.Value)
+	case *ast.ArrayLiteralExpression:
+		for _, element := range e.Elements {
+			r.expression(element)
+		}
+	case *ast.MapLiteralExpression:
+		for _, entry := range e.Entries {
+			r.expression(entry.Key)
+			r.expression(entry.Value)
+		}
+	case *ast.StructLiteralExpression:
+		for _, field := range e.Fields {
+			r.expression(field)
+		}
+	case *ast.CastExpression:
+		r.expression(e.Expression)
+	case *ast.RangeExpression:
+		r.expression(e.Start)
+		r.expression(e.End)
+		r.expression(e.Step)
+	case *ast.ConditionalExpression:
+		r.expression(e.Condition)
+		r.expression(e.Then)
+		r.expression(e.Else)
+	}
+}
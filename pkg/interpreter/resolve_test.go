@@ -0,0 +1,125 @@
+package interpreter_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/burnlang/burn/internal/testutil"
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/interpreter"
+)
+
+func TestResolveBindings(t *testing.T) {
+	program := testutil.Program(t, `
+fun f(a: int): int {
+    var b = a
+    if (b > 0) {
+        var c = b
+        return c + 1
+    }
+    return b
+}
+var n = 1
+var m = n
+`)
+	interpreter.Resolve(program)
+
+	body := program.Declarations[0].(*ast.FunctionDeclaration).Body
+	b := body[0].(*ast.VariableDeclaration)
+	inner := body[1].(*ast.IfStatement).ThenBranch
+	c := inner[0].(*ast.VariableDeclaration)
+	sum := inner[1].(*ast.ReturnStatement).Value.(*ast.BinaryExpression)
+	m := program.Declarations[2].(*ast.VariableDeclaration)
+	for _, tt := range []struct {
+		name string
+		got  *ast.Binding
+		want *ast.Binding
+	}{
+		{"a", b.Value.(*ast.VariableExpression).Binding, &ast.Binding{Depth: 0, Slot: 0}},
+		{"declared b", b.Binding, &ast.Binding{Depth: 0, Slot: 1}},
+		{"b in the if", c.Value.(*ast.VariableExpression).Binding, &ast.Binding{Depth: 1, Slot: 1}},
+		{"c", sum.Left.(*ast.VariableExpression).Binding, &ast.Binding{Depth: 0, Slot: 0}},
+		{"global n", m.Value.(*ast.VariableExpression).Binding, nil},
+		{"declared m", m.Binding, nil},
+	} {
+		if (tt.got == nil) != (tt.want == nil) || tt.got != nil && *tt.got != *tt.want {
+			t.Errorf("%s: got binding %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestResolvedScopes(t *testing.T) {
+	got := testutil.Run(t, `
+fun siblings(): string {
+    var x = "outer"
+    if (true) {
+        var y = "first"
+        print(y)
+    }
+    if (true) {
+        var z = x
+        var y = "second"
+        print(y + " " + z)
+    }
+    return x
+}
+
+fun counters(): [fun(): int] {
+    var made: [fun(): int] = []
+    for (i in [1, 2, 3]) {
+        push(made, fun(): int { return i * 10 })
+    }
+    return made
+}
+
+fun fact(n: int): int {
+    fun go(k: int, acc: int): int {
+        if (k <= 1) {
+            return acc
+        }
+        return go(k - 1, acc * k)
+    }
+    return go(n, 1)
+}
+
+print(siblings())
+for (c in counters()) {
+    print(c())
+}
+print(fact(5))
+`)
+	if want := "first\nsecond outer\nouter\n10\n20\n30\n120\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestResolvedProgramRunsConcurrently(t *testing.T) {
+	program := testutil.Program(t, `
+fun sum(n: int): int {
+    var total = 0
+    for (var i = 1; i <= n; i = i + 1) {
+        total = total + i
+    }
+    return total
+}
+sum(1000)
+`)
+	interpreter.Resolve(program)
+
+	var wg sync.WaitGroup
+	results := make([]interpreter.Value, 8)
+	errs := make([]error, len(results))
+	for k := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[k], errs[k] = interpreter.New().Interpret(program)
+		}()
+	}
+	wg.Wait()
+	for k := range results {
+		if errs[k] != nil || results[k] != 500500.0 {
+			t.Errorf("run %d: got %v, %v, want 500500", k, results[k], errs[k])
+		}
+	}
+}
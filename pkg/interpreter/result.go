@@ -0,0 +1,247 @@
+package interpreter
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/burnlang/burn/pkg/errcode"
+)
+
+// Result is a Burn Result: Ok with a value, or Err with a message for a
+// failure the program can recover from
+type Result struct {
+	Ok    bool
+	Value Value
+	Err   string
+}
+
+func (r *Result) String() string {
+	if r.Ok {
+		return fmt.Sprintf("Ok(%v)", r.Value)
+	}
+	return "Err(" + r.Err + ")"
+}
+
+// Option is a Burn Option: Some with a value, or None
+type Option struct {
+	Some  bool
+	Value Value
+}
+
+func (o *Option) String() string {
+	if o.Some {
+		return fmt.Sprintf("Some(%v)", o.Value)
+	}
+	return "None"
+}
+
+func (i *Interpreter) addResultBuiltins() {
+	argCount := func(name string, args []Value, count int) error {
+		if len(args) != count {
+			return errcode.Errorf("BRN0510", "%s expects exactly %d arguments", name, count)
+		}
+		return nil
+	}
+	resultArg := func(name string, args []Value, count int) (*Result, error) {
+		if err := argCount(name, args, count); err != nil {
+			return nil, err
+		}
+		r, ok := args[0].(*Result)
+		if !ok {
+			return nil, errcode.Errorf("BRN0510", "%s expects a Result, got %T", name, args[0])
+		}
+		return r, nil
+	}
+	optionArg := func(name string, args []Value) (*Option, error) {
+		if err := argCount(name, args, 1); err != nil {
+			return nil, err
+		}
+		o, ok := args[0].(*Option)
+		if !ok {
+			return nil, errcode.Errorf("BRN0510", "%s expects an Option, got %T", name, args[0])
+		}
+		return o, nil
+	}
+
+	i.builtins.values["Ok"] = &BuiltinFunction{
+		Name: "Ok",
+		Fn: func(args []Value) (Value, error) {
+			if err := argCount("Ok", args, 1); err != nil {
+				return nil, err
+			}
+			return &Result{Ok: true, Value: args[0]}, nil
+		},
+	}
+
+	i.builtins.values["Err"] = &BuiltinFunction{
+		Name: "Err",
+		Fn: func(args []Value) (Value, error) {
+			if err := argCount("Err", args, 1); err != nil {
+				return nil, err
+			}
+			message, ok := args[0].(string)
+			if !ok {
+				return nil, errcode.Errorf("BRN0510", "Err expects a string, got %T", args[0])
+			}
+			return &Result{Err: message}, nil
+		},
+	}
+
+	i.builtins.values["Some"] = &BuiltinFunction{
+		Name: "Some",
+		Fn: func(args []Value) (Value, error) {
+			if err := argCount("Some", args, 1); err != nil {
+				return nil, err
+			}
+			return &Option{Some: true, Value: args[0]}, nil
+		},
+	}
+
+	i.builtins.values["None"] = &BuiltinFunction{
+		Name: "None",
+		Fn: func(args []Value) (Value, error) {
+			if err := argCount("None", args, 0); err != nil {
+				return nil, err
+			}
+			return &Option{}, nil
+		},
+	}
+
+	i.builtins.values["isOk"] = &BuiltinFunction{
+		Name: "isOk",
+		Fn: func(args []Value) (Value, error) {
+			r, err := resultArg("isOk", args, 1)
+			if err != nil {
+				return nil, err
+			}
+			return r.Ok, nil
+		},
+	}
+
+	i.builtins.values["isErr"] = &BuiltinFunction{
+		Name: "isErr",
+		Fn: func(args []Value) (Value, error) {
+			r, err := resultArg("isErr", args, 1)
+			if err != nil {
+				return nil, err
+			}
+			return !r.Ok, nil
+		},
+	}
+
+	i.builtins.values["isSome"] = &BuiltinFunction{
+		Name: "isSome",
+		Fn: func(args []Value) (Value, error) {
+			o, err := optionArg("isSome", args)
+			if err != nil {
+				return nil, err
+			}
+			return o.Some, nil
+		},
+	}
+
+	i.builtins.values["isNone"] = &BuiltinFunction{
+		Name: "isNone",
+		Fn: func(args []Value) (Value, error) {
+			o, err := optionArg("isNone", args)
+			if err != nil {
+				return nil, err
+			}
+			return !o.Some, nil
+		},
+	}
+
+	i.builtins.values["unwrap"] = &BuiltinFunction{
+		Name: "unwrap",
+		Fn: func(args []Value) (Value, error) {
+			if err := argCount("unwrap", args, 1); err != nil {
+				return nil, err
+			}
+			switch v := args[0].(type) {
+			case *Result:
+				if !v.Ok {
+					return nil, errcode.Errorf("BRN0504", "unwrap of Err: %s", v.Err)
+				}
+				return v.Value, nil
+			case *Option:
+				if !v.Some {
+					return nil, errcode.Errorf("BRN0504", "unwrap of None")
+				}
+				return v.Value, nil
+			}
+			return nil, errcode.Errorf("BRN0510", "unwrap expects a Result or an Option, got %T", args[0])
+		},
+	}
+
+	i.builtins.values["unwrapOr"] = &BuiltinFunction{
+		Name: "unwrapOr",
+		Fn: func(args []Value) (Value, error) {
+			if err := argCount("unwrapOr", args, 2); err != nil {
+				return nil, err
+			}
+			switch v := args[0].(type) {
+			case *Result:
+				if v.Ok {
+					return v.Value, nil
+				}
+				return args[1], nil
+			case *Option:
+				if v.Some {
+					return v.Value, nil
+				}
+				return args[1], nil
+			}
+			return nil, errcode.Errorf("BRN0510", "unwrapOr expects a Result or an Option, got %T", args[0])
+		},
+	}
+
+	i.builtins.values["unwrapErr"] = &BuiltinFunction{
+		Name: "unwrapErr",
+		Fn: func(args []Value) (Value, error) {
+			r, err := resultArg("unwrapErr", args, 1)
+			if err != nil {
+				return nil, err
+			}
+			if r.Ok {
+				return nil, errcode.Errorf("BRN0504", "unwrapErr of Ok(%v)", r.Value)
+			}
+			return r.Err, nil
+		},
+	}
+
+	i.builtins.values["tryToInt"] = &BuiltinFunction{
+		Name: "tryToInt",
+		Fn: func(args []Value) (Value, error) {
+			if err := argCount("tryToInt", args, 1); err != nil {
+				return nil, err
+			}
+			s, ok := args[0].(string)
+			if !ok {
+				return nil, errcode.Errorf("BRN0510", "tryToInt expects a string, got %T", args[0])
+			}
+			n, err := strconv.Atoi(s)
+			if err != nil {
+				return &Result{Err: fmt.Sprintf("cannot convert %q to int", s)}, nil
+			}
+			return &Result{Ok: true, Value: float64(n)}, nil
+		},
+	}
+
+	i.builtins.values["tryToFloat"] = &BuiltinFunction{
+		Name: "tryToFloat",
+		Fn: func(args []Value) (Value, error) {
+			if err := argCount("tryToFloat", args, 1); err != nil {
+				return nil, err
+			}
+			s, ok := args[0].(string)
+			if !ok {
+				return nil, errcode.Errorf("BRN0510", "tryToFloat expects a string, got %T", args[0])
+			}
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return &Result{Err: fmt.Sprintf("cannot convert %q to float", s)}, nil
+			}
+			return &Result{Ok: true, Value: f}, nil
+		},
+	}
+}
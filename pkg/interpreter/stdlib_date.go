@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
 )
 
 func (i *Interpreter) registerDateLibrary() {
@@ -113,11 +114,11 @@ func (i *Interpreter) registerDateLibrary() {
 
 	
 	i.classes["Date"] = dateClass
-	i.environment["Date"] = dateClass
+	i.builtins.values["Date"] = dateClass
 
 	
 
-	i.environment["Date.now"] = &BuiltinFunction{
+	i.builtins.values["Date.now"] = &BuiltinFunction{
 		Name: "Date.now",
 		Fn: func(args []Value) (Value, error) {
 			currentTime := time.Now()
@@ -132,7 +133,7 @@ func (i *Interpreter) registerDateLibrary() {
 		},
 	}
 
-	i.environment["Date.today"] = &BuiltinFunction{
+	i.builtins.values["Date.today"] = &BuiltinFunction{
 		Name: "Date.today",
 		Fn: func(args []Value) (Value, error) {
 			currentTime := time.Now()
@@ -145,15 +146,15 @@ func (i *Interpreter) registerDateLibrary() {
 		},
 	}
 
-	i.environment["Date.formatDate"] = &BuiltinFunction{
+	i.builtins.values["Date.formatDate"] = &BuiltinFunction{
 		Name: "Date.formatDate",
 		Fn: func(args []Value) (Value, error) {
 			if len(args) != 1 {
-				return nil, fmt.Errorf("Date.formatDate expects exactly one Date argument")
+				return nil, errcode.Errorf("BRN0510", "Date.formatDate expects exactly one Date argument")
 			}
 			dateStruct, ok := args[0].(*Struct)
 			if !ok || dateStruct.TypeName != "Date" {
-				return nil, fmt.Errorf("Date.formatDate expects a Date struct")
+				return nil, errcode.Errorf("BRN0510", "Date.formatDate expects a Date struct")
 			}
 			year, _ := dateStruct.Fields["year"].(int)
 			month, _ := dateStruct.Fields["month"].(int)
@@ -164,36 +165,36 @@ func (i *Interpreter) registerDateLibrary() {
 		},
 	}
 
-	i.environment["Date.currentYear"] = &BuiltinFunction{
+	i.builtins.values["Date.currentYear"] = &BuiltinFunction{
 		Name: "Date.currentYear",
 		Fn: func(args []Value) (Value, error) {
 			return float64(time.Now().Year()), nil
 		},
 	}
 
-	i.environment["Date.currentMonth"] = &BuiltinFunction{
+	i.builtins.values["Date.currentMonth"] = &BuiltinFunction{
 		Name: "Date.currentMonth",
 		Fn: func(args []Value) (Value, error) {
 			return float64(int(time.Now().Month())), nil
 		},
 	}
 
-	i.environment["Date.currentDay"] = &BuiltinFunction{
+	i.builtins.values["Date.currentDay"] = &BuiltinFunction{
 		Name: "Date.currentDay",
 		Fn: func(args []Value) (Value, error) {
 			return float64(time.Now().Day()), nil
 		},
 	}
 
-	i.environment["Date.isLeapYear"] = &BuiltinFunction{
+	i.builtins.values["Date.isLeapYear"] = &BuiltinFunction{
 		Name: "Date.isLeapYear",
 		Fn: func(args []Value) (Value, error) {
 			if len(args) != 1 {
-				return nil, fmt.Errorf("Date.isLeapYear expects exactly one integer argument")
+				return nil, errcode.Errorf("BRN0510", "Date.isLeapYear expects exactly one integer argument")
 			}
 			yearFloat, ok := args[0].(float64)
 			if !ok {
-				return nil, fmt.Errorf("Date.isLeapYear expects an integer")
+				return nil, errcode.Errorf("BRN0510", "Date.isLeapYear expects an integer")
 			}
 			year := int(yearFloat)
 			isLeap := false
@@ -208,19 +209,19 @@ func (i *Interpreter) registerDateLibrary() {
 		},
 	}
 
-	i.environment["Date.daysInMonth"] = &BuiltinFunction{
+	i.builtins.values["Date.daysInMonth"] = &BuiltinFunction{
 		Name: "Date.daysInMonth",
 		Fn: func(args []Value) (Value, error) {
 			if len(args) != 2 {
-				return nil, fmt.Errorf("Date.daysInMonth expects exactly two integer arguments")
+				return nil, errcode.Errorf("BRN0510", "Date.daysInMonth expects exactly two integer arguments")
 			}
 			yearFloat, ok := args[0].(float64)
 			if !ok {
-				return nil, fmt.Errorf("Date.daysInMonth expects year as an integer")
+				return nil, errcode.Errorf("BRN0510", "Date.daysInMonth expects year as an integer")
 			}
 			monthFloat, ok := args[1].(float64)
 			if !ok {
-				return nil, fmt.Errorf("Date.daysInMonth expects month as an integer")
+				return nil, errcode.Errorf("BRN0510", "Date.daysInMonth expects month as an integer")
 			}
 			year := int(yearFloat)
 			month := int(monthFloat)
@@ -246,23 +247,23 @@ func (i *Interpreter) registerDateLibrary() {
 		},
 	}
 
-	i.environment["Date.createDate"] = &BuiltinFunction{
+	i.builtins.values["Date.createDate"] = &BuiltinFunction{
 		Name: "Date.createDate",
 		Fn: func(args []Value) (Value, error) {
 			if len(args) != 3 {
-				return nil, fmt.Errorf("Date.createDate expects exactly three integer arguments")
+				return nil, errcode.Errorf("BRN0510", "Date.createDate expects exactly three integer arguments")
 			}
 			yearFloat, ok := args[0].(float64)
 			if !ok {
-				return nil, fmt.Errorf("Date.createDate expects year as an integer")
+				return nil, errcode.Errorf("BRN0510", "Date.createDate expects year as an integer")
 			}
 			monthFloat, ok := args[1].(float64)
 			if !ok {
-				return nil, fmt.Errorf("Date.createDate expects month as an integer")
+				return nil, errcode.Errorf("BRN0510", "Date.createDate expects month as an integer")
 			}
 			dayFloat, ok := args[2].(float64)
 			if !ok {
-				return nil, fmt.Errorf("Date.createDate expects day as an integer")
+				return nil, errcode.Errorf("BRN0510", "Date.createDate expects day as an integer")
 			}
 			dateStruct := &Struct{
 				TypeName: "Date",
@@ -276,15 +277,15 @@ func (i *Interpreter) registerDateLibrary() {
 		},
 	}
 
-	i.environment["Date.dayOfWeek"] = &BuiltinFunction{
+	i.builtins.values["Date.dayOfWeek"] = &BuiltinFunction{
 		Name: "Date.dayOfWeek",
 		Fn: func(args []Value) (Value, error) {
 			if len(args) != 1 {
-				return nil, fmt.Errorf("Date.dayOfWeek expects exactly one Date argument")
+				return nil, errcode.Errorf("BRN0510", "Date.dayOfWeek expects exactly one Date argument")
 			}
 			dateStruct, ok := args[0].(*Struct)
 			if !ok || dateStruct.TypeName != "Date" {
-				return nil, fmt.Errorf("Date.dayOfWeek expects a Date struct")
+				return nil, errcode.Errorf("BRN0510", "Date.dayOfWeek expects a Date struct")
 			}
 			year, _ := dateStruct.Fields["year"].(int)
 			month, _ := dateStruct.Fields["month"].(int)
@@ -303,19 +304,19 @@ func (i *Interpreter) registerDateLibrary() {
 		},
 	}
 
-	i.environment["Date.addDays"] = &BuiltinFunction{
+	i.builtins.values["Date.addDays"] = &BuiltinFunction{
 		Name: "Date.addDays",
 		Fn: func(args []Value) (Value, error) {
 			if len(args) != 2 {
-				return nil, fmt.Errorf("Date.addDays expects exactly two arguments: a Date and an integer")
+				return nil, errcode.Errorf("BRN0510", "Date.addDays expects exactly two arguments: a Date and an integer")
 			}
 			dateStruct, ok := args[0].(*Struct)
 			if !ok || dateStruct.TypeName != "Date" {
-				return nil, fmt.Errorf("Date.addDays expects a Date struct as first argument")
+				return nil, errcode.Errorf("BRN0510", "Date.addDays expects a Date struct as first argument")
 			}
 			daysFloat, ok := args[1].(float64)
 			if !ok {
-				return nil, fmt.Errorf("Date.addDays expects an integer as second argument")
+				return nil, errcode.Errorf("BRN0510", "Date.addDays expects an integer as second argument")
 			}
 			year, _ := dateStruct.Fields["year"].(int)
 			month, _ := dateStruct.Fields["month"].(int)
@@ -334,19 +335,19 @@ func (i *Interpreter) registerDateLibrary() {
 		},
 	}
 
-	i.environment["Date.subtractDays"] = &BuiltinFunction{
+	i.builtins.values["Date.subtractDays"] = &BuiltinFunction{
 		Name: "Date.subtractDays",
 		Fn: func(args []Value) (Value, error) {
 			if len(args) != 2 {
-				return nil, fmt.Errorf("Date.subtractDays expects exactly two arguments: a Date and an integer")
+				return nil, errcode.Errorf("BRN0510", "Date.subtractDays expects exactly two arguments: a Date and an integer")
 			}
 			dateStruct, ok := args[0].(*Struct)
 			if !ok || dateStruct.TypeName != "Date" {
-				return nil, fmt.Errorf("Date.subtractDays expects a Date struct as first argument")
+				return nil, errcode.Errorf("BRN0510", "Date.subtractDays expects a Date struct as first argument")
 			}
 			daysFloat, ok := args[1].(float64)
 			if !ok {
-				return nil, fmt.Errorf("Date.subtractDays expects an integer as second argument")
+				return nil, errcode.Errorf("BRN0510", "Date.subtractDays expects an integer as second argument")
 			}
 			year, _ := dateStruct.Fields["year"].(int)
 			month, _ := dateStruct.Fields["month"].(int)
@@ -382,6 +383,6 @@ func (i *Interpreter) registerDateLibrary() {
 	}
 
 	for oldName, newName := range aliases {
-		i.environment[oldName] = i.environment[newName]
+		i.builtins.values[oldName] = i.builtins.values[newName]
 	}
 }
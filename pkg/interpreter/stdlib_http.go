@@ -9,9 +9,12 @@ import (
 	"time"
 
 	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
 )
 
-var httpHeaders = map[string]string{
+// defaultHTTPHeaders are sent with every request until a program calls
+// HTTP.setHeaders, which changes the headers of its own interpreter only
+var defaultHTTPHeaders = map[string]string{
 	"User-Agent": "BurnLang/1.0",
 	"Accept":     "application/json",
 }
@@ -66,77 +69,80 @@ func (i *Interpreter) registerHTTPLibrary() {
 	})
 
 	i.classes["HTTP"] = httpClass
-	i.environment["HTTP"] = httpClass
+	i.builtins.values["HTTP"] = httpClass
 
 	
-	i.environment["HTTP.get"] = &BuiltinFunction{
+	i.builtins.values["HTTP.get"] = &BuiltinFunction{
 		Name: "HTTP.get",
 		Fn:   i.httpGet,
 	}
-	i.environment["HTTP.post"] = &BuiltinFunction{
+	i.builtins.values["HTTP.post"] = &BuiltinFunction{
 		Name: "HTTP.post",
 		Fn:   i.httpPost,
 	}
-	i.environment["HTTP.put"] = &BuiltinFunction{
+	i.builtins.values["HTTP.put"] = &BuiltinFunction{
 		Name: "HTTP.put",
 		Fn:   i.httpPut,
 	}
-	i.environment["HTTP.delete"] = &BuiltinFunction{
+	i.builtins.values["HTTP.delete"] = &BuiltinFunction{
 		Name: "HTTP.delete",
 		Fn:   i.httpDelete,
 	}
-	i.environment["HTTP.getHeader"] = &BuiltinFunction{
+	i.builtins.values["HTTP.getHeader"] = &BuiltinFunction{
 		Name: "HTTP.getHeader",
 		Fn:   i.httpGetHeader,
 	}
-	i.environment["HTTP.parseJSON"] = &BuiltinFunction{
+	i.builtins.values["HTTP.parseJSON"] = &BuiltinFunction{
 		Name: "HTTP.parseJSON",
 		Fn:   i.httpParseJSON,
 	}
-	i.environment["HTTP.setHeaders"] = &BuiltinFunction{
+	i.builtins.values["HTTP.setHeaders"] = &BuiltinFunction{
 		Name: "HTTP.setHeaders",
 		Fn:   i.httpSetHeaders,
 	}
 
 	
-	i.environment["get"] = i.environment["HTTP.get"]
-	i.environment["post"] = i.environment["HTTP.post"]
-	i.environment["put"] = i.environment["HTTP.put"]
-	i.environment["delete"] = i.environment["HTTP.delete"]
-	i.environment["getHeader"] = i.environment["HTTP.getHeader"]
-	i.environment["parseJSON"] = i.environment["HTTP.parseJSON"]
-	i.environment["setHeaders"] = i.environment["HTTP.setHeaders"]
+	i.builtins.values["get"] = i.builtins.values["HTTP.get"]
+	i.builtins.values["post"] = i.builtins.values["HTTP.post"]
+	i.builtins.values["put"] = i.builtins.values["HTTP.put"]
+	i.builtins.values["getHeader"] = i.builtins.values["HTTP.getHeader"]
+	i.builtins.values["parseJSON"] = i.builtins.values["HTTP.parseJSON"]
+	i.builtins.values["setHeaders"] = i.builtins.values["HTTP.setHeaders"]
 }
 
 func (i *Interpreter) httpGet(args []Value) (Value, error) {
+	if err := i.require(CapabilityNetwork, "HTTP.get"); err != nil {
+		return nil, err
+	}
 	if len(args) != 1 {
-		return nil, fmt.Errorf("HTTP.get expects exactly one string argument")
+		return nil, errcode.Errorf("BRN0510", "HTTP.get expects exactly one string argument")
 	}
 	urlStr, ok := args[0].(string)
 	if !ok {
-		return nil, fmt.Errorf("HTTP.get expects a string URL")
+		return nil, errcode.Errorf("BRN0510", "HTTP.get expects a string URL")
 	}
 
 	client := &http.Client{Timeout: time.Second * 30}
 	req, err := http.NewRequest("GET", urlStr, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error creating request: %v", err)
+		return nil, errcode.Errorf("BRN0513", "error creating request: %v", err)
 	}
 
-	for k, v := range httpHeaders {
+	for k, v := range i.host.httpHeaders {
 		req.Header.Add(k, v)
 	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %v", err)
+		return nil, errcode.Errorf("BRN0513", "error making request: %v", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response: %v", err)
+		return nil, errcode.Errorf("BRN0513", "error reading response: %v", err)
 	}
+	i.transferred(0, len(body))
 
 	headers := []Value{}
 	for name, values := range resp.Header {
@@ -150,44 +156,48 @@ func (i *Interpreter) httpGet(args []Value) (Value, error) {
 		Fields: map[string]interface{}{
 			"statusCode": resp.StatusCode,
 			"body":       string(body),
-			"headers":    headers,
+			"headers":    NewArray(headers),
 		},
 	}, nil
 }
 
 func (i *Interpreter) httpPost(args []Value) (Value, error) {
+	if err := i.require(CapabilityNetwork, "HTTP.post"); err != nil {
+		return nil, err
+	}
 	if len(args) != 2 {
-		return nil, fmt.Errorf("HTTP.post expects exactly two string arguments (url, body)")
+		return nil, errcode.Errorf("BRN0510", "HTTP.post expects exactly two string arguments (url, body)")
 	}
 	urlStr, ok := args[0].(string)
 	if !ok {
-		return nil, fmt.Errorf("HTTP.post expects a string URL as first argument")
+		return nil, errcode.Errorf("BRN0510", "HTTP.post expects a string URL as first argument")
 	}
 	bodyStr, ok := args[1].(string)
 	if !ok {
-		return nil, fmt.Errorf("HTTP.post expects a string body as second argument")
+		return nil, errcode.Errorf("BRN0510", "HTTP.post expects a string body as second argument")
 	}
 
 	client := &http.Client{Timeout: time.Second * 30}
 	req, err := http.NewRequest("POST", urlStr, strings.NewReader(bodyStr))
 	if err != nil {
-		return nil, fmt.Errorf("error creating request: %v", err)
+		return nil, errcode.Errorf("BRN0513", "error creating request: %v", err)
 	}
 
-	for k, v := range httpHeaders {
+	for k, v := range i.host.httpHeaders {
 		req.Header.Add(k, v)
 	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %v", err)
+		return nil, errcode.Errorf("BRN0513", "error making request: %v", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response: %v", err)
+		return nil, errcode.Errorf("BRN0513", "error reading response: %v", err)
 	}
+	i.transferred(len(bodyStr), len(body))
 
 	headers := []Value{}
 	for name, values := range resp.Header {
@@ -201,44 +211,48 @@ func (i *Interpreter) httpPost(args []Value) (Value, error) {
 		Fields: map[string]interface{}{
 			"statusCode": resp.StatusCode,
 			"body":       string(body),
-			"headers":    headers,
+			"headers":    NewArray(headers),
 		},
 	}, nil
 }
 
 func (i *Interpreter) httpPut(args []Value) (Value, error) {
+	if err := i.require(CapabilityNetwork, "HTTP.put"); err != nil {
+		return nil, err
+	}
 	if len(args) != 2 {
-		return nil, fmt.Errorf("HTTP.put expects exactly two string arguments (url, body)")
+		return nil, errcode.Errorf("BRN0510", "HTTP.put expects exactly two string arguments (url, body)")
 	}
 	urlStr, ok := args[0].(string)
 	if !ok {
-		return nil, fmt.Errorf("HTTP.put expects a string URL as first argument")
+		return nil, errcode.Errorf("BRN0510", "HTTP.put expects a string URL as first argument")
 	}
 	bodyStr, ok := args[1].(string)
 	if !ok {
-		return nil, fmt.Errorf("HTTP.put expects a string body as second argument")
+		return nil, errcode.Errorf("BRN0510", "HTTP.put expects a string body as second argument")
 	}
 
 	client := &http.Client{Timeout: time.Second * 30}
 	req, err := http.NewRequest("PUT", urlStr, strings.NewReader(bodyStr))
 	if err != nil {
-		return nil, fmt.Errorf("error creating request: %v", err)
+		return nil, errcode.Errorf("BRN0513", "error creating request: %v", err)
 	}
 
-	for k, v := range httpHeaders {
+	for k, v := range i.host.httpHeaders {
 		req.Header.Add(k, v)
 	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %v", err)
+		return nil, errcode.Errorf("BRN0513", "error making request: %v", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response: %v", err)
+		return nil, errcode.Errorf("BRN0513", "error reading response: %v", err)
 	}
+	i.transferred(len(bodyStr), len(body))
 
 	headers := []Value{}
 	for name, values := range resp.Header {
@@ -252,40 +266,44 @@ func (i *Interpreter) httpPut(args []Value) (Value, error) {
 		Fields: map[string]interface{}{
 			"statusCode": resp.StatusCode,
 			"body":       string(body),
-			"headers":    headers,
+			"headers":    NewArray(headers),
 		},
 	}, nil
 }
 
 func (i *Interpreter) httpDelete(args []Value) (Value, error) {
+	if err := i.require(CapabilityNetwork, "HTTP.delete"); err != nil {
+		return nil, err
+	}
 	if len(args) != 1 {
-		return nil, fmt.Errorf("HTTP.delete expects exactly one string argument")
+		return nil, errcode.Errorf("BRN0510", "HTTP.delete expects exactly one string argument")
 	}
 	urlStr, ok := args[0].(string)
 	if !ok {
-		return nil, fmt.Errorf("HTTP.delete expects a string URL")
+		return nil, errcode.Errorf("BRN0510", "HTTP.delete expects a string URL")
 	}
 
 	client := &http.Client{Timeout: time.Second * 30}
 	req, err := http.NewRequest("DELETE", urlStr, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error creating request: %v", err)
+		return nil, errcode.Errorf("BRN0513", "error creating request: %v", err)
 	}
 
-	for k, v := range httpHeaders {
+	for k, v := range i.host.httpHeaders {
 		req.Header.Add(k, v)
 	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %v", err)
+		return nil, errcode.Errorf("BRN0513", "error making request: %v", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response: %v", err)
+		return nil, errcode.Errorf("BRN0513", "error reading response: %v", err)
 	}
+	i.transferred(0, len(body))
 
 	headers := []Value{}
 	for name, values := range resp.Header {
@@ -299,59 +317,59 @@ func (i *Interpreter) httpDelete(args []Value) (Value, error) {
 		Fields: map[string]interface{}{
 			"statusCode": resp.StatusCode,
 			"body":       string(body),
-			"headers":    headers,
+			"headers":    NewArray(headers),
 		},
 	}, nil
 }
 
 func (i *Interpreter) httpSetHeaders(args []Value) (Value, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("HTTP.setHeaders expects exactly one array argument")
+		return nil, errcode.Errorf("BRN0510", "HTTP.setHeaders expects exactly one array argument")
 	}
-	headerArray, ok := args[0].([]Value)
+	headerArray, ok := args[0].(*Array)
 	if !ok {
-		return nil, fmt.Errorf("HTTP.setHeaders expects an array of header strings")
+		return nil, errcode.Errorf("BRN0510", "HTTP.setHeaders expects an array of header strings")
 	}
 
 	newHeaders := make(map[string]string)
-	for _, hv := range headerArray {
+	for _, hv := range headerArray.Elements {
 		headerStr, ok := hv.(string)
 		if !ok {
-			return nil, fmt.Errorf("each header must be a string")
+			return nil, errcode.Errorf("BRN0510", "each header must be a string")
 		}
 		parts := strings.SplitN(headerStr, ":", 2)
 		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid header format: %s", headerStr)
+			return nil, errcode.Errorf("BRN0511", "invalid header format: %s", headerStr)
 		}
 		name := strings.TrimSpace(parts[0])
 		value := strings.TrimSpace(parts[1])
 		newHeaders[name] = value
 	}
 
-	httpHeaders = newHeaders
+	i.host.httpHeaders = newHeaders
 	return true, nil
 }
 
 func (i *Interpreter) httpGetHeader(args []Value) (Value, error) {
 	if len(args) != 2 {
-		return nil, fmt.Errorf("HTTP.getHeader expects exactly two arguments")
+		return nil, errcode.Errorf("BRN0510", "HTTP.getHeader expects exactly two arguments")
 	}
 	respObj, ok := args[0].(*Struct)
 	if !ok || respObj.TypeName != "HTTPResponse" {
-		return nil, fmt.Errorf("HTTP.getHeader expects an HTTPResponse as first argument")
+		return nil, errcode.Errorf("BRN0510", "HTTP.getHeader expects an HTTPResponse as first argument")
 	}
 	headerName, ok := args[1].(string)
 	if !ok {
-		return nil, fmt.Errorf("HTTP.getHeader expects a string header name")
+		return nil, errcode.Errorf("BRN0510", "HTTP.getHeader expects a string header name")
 	}
 
-	headers, ok := respObj.Fields["headers"].([]Value)
+	headers, ok := respObj.Fields["headers"].(*Array)
 	if !ok {
 		return "", nil
 	}
 
 	headerName = strings.ToLower(headerName)
-	for _, h := range headers {
+	for _, h := range headers.Elements {
 		headerStr, ok := h.(string)
 		if !ok {
 			continue
@@ -371,17 +389,17 @@ func (i *Interpreter) httpGetHeader(args []Value) (Value, error) {
 
 func (i *Interpreter) httpParseJSON(args []Value) (Value, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("HTTP.parseJSON expects exactly one string argument")
+		return nil, errcode.Errorf("BRN0510", "HTTP.parseJSON expects exactly one string argument")
 	}
 	jsonStr, ok := args[0].(string)
 	if !ok {
-		return nil, fmt.Errorf("HTTP.parseJSON expects a string JSON")
+		return nil, errcode.Errorf("BRN0510", "HTTP.parseJSON expects a string JSON")
 	}
 
 	var result interface{}
 	err := json.Unmarshal([]byte(jsonStr), &result)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing JSON: %v", err)
+		return nil, errcode.Errorf("BRN0513", "error parsing JSON: %v", err)
 	}
 
 	return convertJSONToBurn(result), nil
@@ -403,7 +421,7 @@ func convertJSONToBurn(value interface{}) Value {
 		for i, val := range v {
 			array[i] = convertJSONToBurn(val)
 		}
-		return array
+		return NewArray(array)
 	case string:
 		return v
 	case float64:
@@ -1,10 +1,10 @@
 package interpreter
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
 )
 
 
@@ -40,48 +40,48 @@ func (i *Interpreter) registerTimeLibrary() {
 	})
 
 	i.classes["Time"] = timeClass
-	i.environment["Time"] = timeClass
+	i.builtins.values["Time"] = timeClass
 
-	i.environment["Time.now"] = &BuiltinFunction{
+	i.builtins.values["Time.now"] = &BuiltinFunction{
 		Name: "Time.now",
 		Fn: func(args []Value) (Value, error) {
 			return time.Now(), nil
+		},
 	}
 
-	i.environment["Time.sleep"] = &BuiltinFunction{
+	i.builtins.values["Time.sleep"] = &BuiltinFunction{
 		Name: "Time.sleep",
 		Fn: func(args []Value) (Value, error) {
 			if len(args) != 1 {
-				return nil, fmt.Errorf("Time.sleep expects exactly one numeric argument (milliseconds)")
+				return nil, errcode.Errorf("BRN0510", "Time.sleep expects exactly one numeric argument (milliseconds)")
 			}
 
 			ms, ok := args[0].(float64)
 			if !ok {
-				return nil, fmt.Errorf("Time.sleep expects a numeric value")
+				return nil, errcode.Errorf("BRN0510", "Time.sleep expects a numeric value")
 			}
 
-			time.Sleep(time.Duration(ms) * time.Millisecond)
-			return nil, nil
+			return nil, i.sleep(time.Duration(ms) * time.Millisecond)
 		},
 	}
 
-	i.environment["Time.timestamp"] = &BuiltinFunction{
+	i.builtins.values["Time.timestamp"] = &BuiltinFunction{
 		Name: "Time.timestamp",
 		Fn: func(args []Value) (Value, error) {
 			return float64(time.Now().Unix()), nil
 		},
 	}
 
-	i.environment["Time.format"] = &BuiltinFunction{
+	i.builtins.values["Time.format"] = &BuiltinFunction{
 		Name: "Time.format",
 		Fn: func(args []Value) (Value, error) {
 			if len(args) != 1 {
-				return nil, fmt.Errorf("Time.format expects exactly one string argument")
+				return nil, errcode.Errorf("BRN0510", "Time.format expects exactly one string argument")
 			}
 
 			format, ok := args[0].(string)
 			if !ok {
-				return nil, fmt.Errorf("Time.format expects a string argument")
+				return nil, errcode.Errorf("BRN0510", "Time.format expects a string argument")
 			}
 
 			return time.Now().Format(format), nil
@@ -89,8 +89,8 @@ func (i *Interpreter) registerTimeLibrary() {
 	}
 
 	
-	i.environment["now"] = i.environment["Time.now"]
-	i.environment["sleep"] = i.environment["Time.sleep"]
-	i.environment["timestamp"] = i.environment["Time.timestamp"]
-	i.environment["format"] = i.environment["Time.format"]
+	i.builtins.values["now"] = i.builtins.values["Time.now"]
+	i.builtins.values["sleep"] = i.builtins.values["Time.sleep"]
+	i.builtins.values["timestamp"] = i.builtins.values["Time.timestamp"]
+	i.builtins.values["format"] = i.builtins.values["Time.format"]
 }
@@ -1,9 +1,11 @@
 package interpreter
 
 import (
-	"fmt"
+	"bytes"
+	"math/big"
 
 	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
 )
 
 type Struct struct {
@@ -26,7 +28,7 @@ func (s *Struct) HasField(name string) bool {
 }
 
 func (i *Interpreter) evalExpression(expr ast.Expression) (interface{}, error) {
-	return nil, fmt.Errorf("evalExpression not implemented for %T", expr)
+	return nil, errcode.Errorf("BRN0515", "evalExpression not implemented for %T", expr)
 }
 
 func (i *Interpreter) evalStructLiteral(expr *ast.StructLiteralExpression) (interface{}, error) {
@@ -54,14 +56,14 @@ func (i *Interpreter) evalGetExpression(expr *ast.GetExpression) (interface{}, e
 
 	structObj, ok := object.(*Struct)
 	if !ok {
-		i.setErrorPos(0)
-		return nil, fmt.Errorf("cannot access field on non-struct value: %T", object)
+		i.setErrorAt(nil)
+		return nil, errcode.Errorf("BRN0511", "cannot access field on non-struct value: %T", object)
 	}
 
 	value, exists := structObj.Fields[expr.Name]
 	if !exists {
-		i.setErrorPos(0)
-		return nil, fmt.Errorf("undefined field '%s' on struct of type '%s'",
+		i.setErrorAt(nil)
+		return nil, errcode.Errorf("BRN0509", "undefined field '%s' on struct of type '%s'",
 			expr.Name, structObj.TypeName)
 	}
 
@@ -76,8 +78,8 @@ func (i *Interpreter) evalSetExpression(expr *ast.SetExpression) (interface{}, e
 
 	structObj, ok := object.(*Struct)
 	if !ok {
-		i.setErrorPos(0)
-		return nil, fmt.Errorf("cannot set field on non-struct value: %T", object)
+		i.setErrorAt(nil)
+		return nil, errcode.Errorf("BRN0511", "cannot set field on non-struct value: %T", object)
 	}
 
 	value, err := i.evalExpression(expr.Value)
@@ -89,3 +91,282 @@ func (i *Interpreter) evalSetExpression(expr *ast.SetExpression) (interface{}, e
 
 	return value, nil
 }
+
+// toStringMethod returns the toString method of the class or type of s,
+// if it has one taking just the instance and returning a string
+func (i *Interpreter) toStringMethod(s *Struct) (*Class, *ast.FunctionDeclaration, bool) {
+	class, exists := i.classes[s.TypeName]
+	if !exists {
+		return nil, nil, false
+	}
+	method, exists := class.Methods["toString"]
+	if !exists || len(method.Parameters) != 1 || method.ReturnType != "string" {
+		return nil, nil, false
+	}
+	return class, method, true
+}
+
+// structString calls the toString method of the class or type of s
+func (i *Interpreter) structString(s *Struct) (string, bool, error) {
+	class, method, ok := i.toStringMethod(s)
+	if !ok {
+		return "", false, nil
+	}
+	result, err := i.callMethod(class, method, []Value{s})
+	if err != nil {
+		return "", false, err
+	}
+	str, ok := result.(string)
+	if !ok {
+		return "", false, errcode.Errorf("BRN0511", "toString of %s must return a string, got %T", s.TypeName, result)
+	}
+	return str, true, nil
+}
+
+// Display returns what print shows for value: the same value, except that
+// instances with a toString method, also inside arrays, are shown as the
+// string it returns
+func (i *Interpreter) Display(value Value) (Value, error) {
+	switch v := value.(type) {
+	case *Struct:
+		str, ok, err := i.structString(v)
+		if err != nil || !ok {
+			return value, err
+		}
+		return str, nil
+	case *Array:
+		shown := make([]Value, len(v.Elements))
+		for j, elem := range v.Elements {
+			var err error
+			if shown[j], err = i.Display(elem); err != nil {
+				return nil, err
+			}
+		}
+		return NewArray(shown), nil
+	}
+	return value, nil
+}
+
+// concatStruct joins a string and an instance with a toString method, in
+// either order, when left + right is one
+func (i *Interpreter) concatStruct(left, right Value) (Value, bool, error) {
+	lStr, lIsString := left.(string)
+	rStr, rIsString := right.(string)
+	lStruct, lIsStruct := left.(*Struct)
+	rStruct, rIsStruct := right.(*Struct)
+
+	var str string
+	var ok bool
+	var err error
+	switch {
+	case lIsString && rIsStruct:
+		str, ok, err = i.structString(rStruct)
+		str = lStr + str
+	case lIsStruct && rIsString:
+		str, ok, err = i.structString(lStruct)
+		str += rStr
+	}
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	if err := i.allocate(len(str)); err != nil {
+		return nil, false, err
+	}
+	return str, true, nil
+}
+
+// structsEqual reports whether left and right are both structs, and if so
+// whether their fields are equal. Structs of different types cannot be
+// compared.
+func structsEqual(left, right Value) (bool, bool, error) {
+	l, lOk := left.(*Struct)
+	r, rOk := right.(*Struct)
+	if !lOk || !rOk {
+		return false, false, nil
+	}
+	if l.TypeName != r.TypeName {
+		return false, true, errcode.Errorf("BRN0511", "cannot compare a %s with a %s", l.TypeName, r.TypeName)
+	}
+	equal, err := deepEqual(l, r)
+	return equal, true, err
+}
+
+// deepEqual compares the values of two fields, going through the fields of
+// structs and the elements of arrays, maps, Results and Options
+func deepEqual(left, right Value) (bool, error) {
+	return deepEqualIn(left, right, make(map[[2]Value]bool))
+}
+
+// deepEqualIn is deepEqual with the pairs of structs and maps being compared
+// so far, compared, so that a cycle ends: a pair met again within itself is
+// taken to be equal, and whether it is depends on the rest of it.
+func deepEqualIn(left, right Value, compared map[[2]Value]bool) (bool, error) {
+	// A bigint or decimal equals the whole number of the same value, on
+	// either side
+	switch right.(type) {
+	case *big.Int, *Decimal:
+		left, right = right, left
+	}
+	switch l := left.(type) {
+	case *big.Int:
+		if r, ok := bigIntOf(right); ok {
+			return l.Cmp(r) == 0, nil
+		}
+		r, ok := right.(*Decimal)
+		return ok && r.Cmp(&Decimal{Digits: l}) == 0, nil
+	case *Decimal:
+		r, ok := decimalOf(right)
+		return ok && l.Cmp(r) == 0, nil
+	case nil:
+		return right == nil, nil
+	case float64, string, bool:
+		return left == right, nil
+	case []byte:
+		r, ok := right.([]byte)
+		return ok && bytes.Equal(l, r), nil
+	case *Struct:
+		r, ok := right.(*Struct)
+		if !ok || l.TypeName != r.TypeName || len(l.Fields) != len(r.Fields) {
+			return false, nil
+		}
+		if l == r || compared[[2]Value{l, r}] {
+			return true, nil
+		}
+		compared[[2]Value{l, r}] = true
+		for name, value := range l.Fields {
+			other, exists := r.Fields[name]
+			if !exists {
+				return false, nil
+			}
+			if equal, err := deepEqualIn(value, other, compared); err != nil || !equal {
+				return false, err
+			}
+		}
+		return true, nil
+	case *Array:
+		r, ok := right.(*Array)
+		if !ok || len(l.Elements) != len(r.Elements) {
+			return false, nil
+		}
+		if l == r || compared[[2]Value{l, r}] {
+			return true, nil
+		}
+		compared[[2]Value{l, r}] = true
+		for j := range l.Elements {
+			if equal, err := deepEqualIn(l.Elements[j], r.Elements[j], compared); err != nil || !equal {
+				return false, err
+			}
+		}
+		return true, nil
+	case *Map:
+		r, ok := right.(*Map)
+		if !ok || len(l.Keys) != len(r.Keys) {
+			return false, nil
+		}
+		if l == r || compared[[2]Value{l, r}] {
+			return true, nil
+		}
+		compared[[2]Value{l, r}] = true
+		for _, key := range l.Keys {
+			other, exists := r.Entries[key]
+			if !exists {
+				return false, nil
+			}
+			if equal, err := deepEqualIn(l.Entries[key], other, compared); err != nil || !equal {
+				return false, err
+			}
+		}
+		return true, nil
+	case *Result:
+		r, ok := right.(*Result)
+		if !ok || l.Ok != r.Ok || l.Err != r.Err {
+			return false, nil
+		}
+		return deepEqualIn(l.Value, r.Value, compared)
+	case *Option:
+		r, ok := right.(*Option)
+		if !ok || l.Some != r.Some {
+			return false, nil
+		}
+		return deepEqualIn(l.Value, r.Value, compared)
+	}
+	return false, errcode.Errorf("BRN0511", "cannot compare %s with %s", typeOf(left), typeOf(right))
+}
+
+// deepCopy copies a value for clone, going through the fields of structs
+// and the elements of arrays, maps, Results and Options. copies holds the
+// structs and maps copied so far, so that one shared within the value is
+// copied once and a cycle ends.
+func (i *Interpreter) deepCopy(value Value, copies map[Value]Value) (Value, error) {
+	switch v := value.(type) {
+	case *Struct:
+		if copied, exists := copies[v]; exists {
+			return copied, nil
+		}
+		if err := i.allocate(16 * len(v.Fields)); err != nil {
+			return nil, err
+		}
+		s := &Struct{TypeName: v.TypeName, Fields: make(map[string]interface{}, len(v.Fields))}
+		copies[v] = s
+		for name, field := range v.Fields {
+			copied, err := i.deepCopy(field, copies)
+			if err != nil {
+				return nil, err
+			}
+			s.Fields[name] = copied
+		}
+		return s, nil
+	case *Array:
+		if copied, exists := copies[v]; exists {
+			return copied, nil
+		}
+		if err := i.allocate(16 * len(v.Elements)); err != nil {
+			return nil, err
+		}
+		arr := NewArray(make([]Value, len(v.Elements)))
+		copies[v] = arr
+		for j, elem := range v.Elements {
+			copied, err := i.deepCopy(elem, copies)
+			if err != nil {
+				return nil, err
+			}
+			arr.Elements[j] = copied
+		}
+		return arr, nil
+	case *Map:
+		if copied, exists := copies[v]; exists {
+			return copied, nil
+		}
+		if err := i.allocate(32 * len(v.Keys)); err != nil {
+			return nil, err
+		}
+		m := NewMap()
+		copies[v] = m
+		for _, key := range v.Keys {
+			copied, err := i.deepCopy(v.Entries[key], copies)
+			if err != nil {
+				return nil, err
+			}
+			m.Set(key, copied)
+		}
+		return m, nil
+	case []byte:
+		if err := i.allocate(len(v)); err != nil {
+			return nil, err
+		}
+		return append([]byte{}, v...), nil
+	case *Result:
+		copied, err := i.deepCopy(v.Value, copies)
+		if err != nil {
+			return nil, err
+		}
+		return &Result{Ok: v.Ok, Value: copied, Err: v.Err}, nil
+	case *Option:
+		copied, err := i.deepCopy(v.Value, copies)
+		if err != nil {
+			return nil, err
+		}
+		return &Option{Some: v.Some, Value: copied}, nil
+	}
+	return value, nil
+}
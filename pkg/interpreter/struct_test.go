@@ -0,0 +1,41 @@
+package interpreter_test
+
+import (
+	"testing"
+
+	"github.com/burnlang/burn/internal/testutil"
+)
+
+func TestCompareCyclicStructs(t *testing.T) {
+	got := testutil.Run(t, `
+type N { v: int, next: N? }
+var a = N{v: 1, next: nil}
+var b = N{v: 2, next: a}
+a.next = b
+var c = N{v: 1, next: nil}
+var d = N{v: 2, next: c}
+c.next = d
+print(a == c)
+d.v = 3
+print(a == c)
+print(a != c)
+`)
+	if want := "true\nfalse\ntrue\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCompareMaps(t *testing.T) {
+	got := testutil.Run(t, `
+var a = {"x": [1, 2]}
+var b = {"x": [1, 2]}
+print(a == b)
+print(a != b)
+b["y"] = [3]
+print(a == b)
+print(a != b)
+`)
+	if want := "true\nfalse\nfalse\ntrue\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,32 @@
+package interpreter_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/burnlang/burn/pkg/interpreter"
+	"github.com/burnlang/burn/pkg/lexer"
+	"github.com/burnlang/burn/pkg/parser"
+)
+
+// TestTimeNow runs without the type checker, which does not know the Time
+// library yet
+func TestTimeNow(t *testing.T) {
+	tokens, err := lexer.New("print(typeof(now()))\nprint(typeof(Time.now()))").Tokenize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	program, errs := parser.New(tokens).ParseAll()
+	if len(errs) > 0 {
+		t.Fatal(errs[0])
+	}
+	var out bytes.Buffer
+	interp := interpreter.New()
+	interp.SetOutput(&out)
+	if _, err := interp.Interpret(program); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Time\nTime\n"; out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
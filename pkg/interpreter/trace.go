@@ -0,0 +1,215 @@
+package interpreter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/burnlang/burn/pkg/ast"
+)
+
+// Tracer is a StatementHook that logs every executed statement with its
+// location and source line. With ShowValues set it also prints the values
+// the variables read by the statement hold before it runs.
+type Tracer struct {
+	ShowValues bool
+
+	out     io.Writer
+	interp  *Interpreter
+	sources map[string][]string
+}
+
+func NewTracer(w io.Writer, interp *Interpreter) *Tracer {
+	return &Tracer{
+		out:     w,
+		interp:  interp,
+		sources: make(map[string][]string),
+	}
+}
+
+// AddSource registers the source of a file so that it does not have to be
+// read from disk; used for code that has no file, such as -e snippets
+func (t *Tracer) AddSource(file, source string) {
+	t.sources[file] = strings.Split(source, "\n")
+}
+
+func (t *Tracer) ExecuteStatement(file string, stmt ast.Declaration) {
+	switch stmt.(type) {
+	case *ast.FunctionDeclaration, *ast.ClassDeclaration, *ast.InterfaceDeclaration,
+		*ast.TypeDefinition, *ast.ImportDeclaration, *ast.MultiImportDeclaration:
+		return
+	}
+
+	lines := t.lines(file)
+	line := stmt.Loc().Line
+	excerpt := lineText(lines, line)
+
+	name := file
+	if name == "" {
+		name = "<eval>"
+	}
+
+	if !t.ShowValues {
+		fmt.Fprintf(t.out, "[trace] %s:%d: %s\n", name, line, excerpt)
+		return
+	}
+
+	var values []string
+	for _, variable := range referencedVariables(stmt) {
+		if value, ok := t.interp.lookup(variable); ok {
+			if _, isBuiltin := value.(*BuiltinFunction); !isBuiltin {
+				values = append(values, fmt.Sprintf("%s=%s", variable, formatTraceValue(value)))
+			}
+		}
+	}
+
+	if len(values) == 0 {
+		fmt.Fprintf(t.out, "[trace] %s:%d: %s\n", name, line, excerpt)
+		return
+	}
+	fmt.Fprintf(t.out, "[trace] %s:%d: %s  {%s}\n", name, line, excerpt, strings.Join(values, ", "))
+}
+
+func (t *Tracer) TakeBranch(file string, stmt *ast.IfStatement, taken bool) {}
+
+func (t *Tracer) lines(file string) []string {
+	if lines, ok := t.sources[file]; ok {
+		return lines
+	}
+	var lines []string
+	if file != "" {
+		if source, err := os.ReadFile(file); err == nil {
+			lines = strings.Split(string(source), "\n")
+		}
+	}
+	t.sources[file] = lines
+	return lines
+}
+
+// lineText returns the trimmed text of a 1-based line
+func lineText(lines []string, line int) string {
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return strings.TrimSpace(lines[line-1])
+}
+
+func formatTraceValue(value Value) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case float64:
+		if v == float64(int64(v)) {
+			return fmt.Sprintf("%d", int64(v))
+		}
+		return fmt.Sprintf("%g", v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// referencedVariables returns the sorted names of the variables a statement
+// reads or assigns, not descending into nested statement bodies
+func referencedVariables(stmt ast.Declaration) []string {
+	names := make(map[string]bool)
+
+	switch s := stmt.(type) {
+	case *ast.VariableDeclaration:
+		collectVariables(s.Value, names)
+	case *ast.ExpressionStatement:
+		collectVariables(s.Expression, names)
+	case *ast.ReturnStatement:
+		collectVariables(s.Value, names)
+	case *ast.IfStatement:
+		collectVariables(s.Condition, names)
+	case *ast.WhileStatement:
+		collectVariables(s.Condition, names)
+	case *ast.ForStatement:
+		collectVariables(s.Condition, names)
+	case *ast.MatchStatement:
+		collectVariables(s.Value, names)
+	case *ast.ForInStatement:
+		collectVariables(s.Iterable, names)
+	}
+
+	result := make([]string, 0, len(names))
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func collectVariables(expr ast.Expression, names map[string]bool) {
+	switch e := expr.(type) {
+	case *ast.VariableExpression:
+		names[e.Name] = true
+	case *ast.ThisExpression:
+		names["this"] = true
+	case *ast.BinaryExpression:
+		collectVariables(e.Left, names)
+		collectVariables(e.Right, names)
+	case *ast.UnaryExpression:
+		collectVariables(e.Right, names)
+	case *ast.GroupingExpression:
+		collectVariables(e.Expression, names)
+	case *ast.CallExpression:
+		if _, isName := e.Callee.(*ast.VariableExpression); !isName {
+			collectVariables(e.Callee, names)
+		}
+		for _, arg := range e.Arguments {
+			collectVariables(arg, names)
+		}
+	case *ast.ClassMethodCallExpression:
+		for _, arg := range e.Arguments {
+			collectVariables(arg, names)
+		}
+	case *ast.GetExpression:
+		collectVariables(e.Object, names)
+	case *ast.SetExpression:
+		collectVariables(e.Object, names)
+		collectVariables(e.Value, names)
+	case *ast.IndexExpression:
+		collectVariables(e.Array, names)
+		collectVariables(e.Index, names)
+	case *ast.SliceExpression:
+		collectVariables(e.Array, names)
+		collectVariables(e.Start, names)
+		collectVariables(e.End, names)
+	case *ast.ArrayLiteralExpression:
+		for _, element := range e.Elements {
+			collectVariables(element, names)
+		}
+	case *ast.StructLiteralExpression:
+		for _, field := range e.Fields {
+			collectVariables(field, names)
+		}
+	case *ast.AssignmentExpression:
+		names[e.Name] = true
+		collectVariables(e.Value, names)
+	case *ast.CompoundAssignmentExpression:
+		names[e.Name] = true
+		collectVariables(e.Value, names)
+	case *ast.CastExpression:
+		collectVariables(e.Expression, names)
+	case *ast.MapLiteralExpression:
+		for _, entry := range e.Entries {
+			collectVariables(entry.Key, names)
+			collectVariables(entry.Value, names)
+		}
+	case *ast.IndexAssignmentExpression:
+		collectVariables(e.Array, names)
+		collectVariables(e.Index, names)
+		collectVariables(e.Value, names)
+	case *ast.ConditionalExpression:
+		collectVariables(e.Condition, names)
+		collectVariables(e.Then, names)
+		collectVariables(e.Else, names)
+	case *ast.RangeExpression:
+		collectVariables(e.Start, names)
+		collectVariables(e.End, names)
+		collectVariables(e.Step, names)
+	}
+}
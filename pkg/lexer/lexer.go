@@ -1,18 +1,25 @@
 package lexer
 
 import (
-	"fmt"
+	"strings"
 	"unicode"
 	"unicode/utf8"
+
+	"github.com/burnlang/burn/pkg/errcode"
 )
 
 type Lexer struct {
-	source   string
-	pos      int
-	line     int
-	col      int
-	tokens   []Token
-	keywords map[string]TokenType
+	source    string
+	pos       int
+	line      int
+	col       int
+	start     int
+	startLine int
+	startCol  int
+	tokens    []Token
+	keywords  map[string]TokenType
+
+	keepComments bool
 }
 
 func New(source string) *Lexer {
@@ -26,125 +33,201 @@ func New(source string) *Lexer {
 	}
 }
 
+// SetKeepComments makes Tokenize emit a TokenComment for every comment
+// instead of skipping it, for tools such as formatters and documentation
+// generators. The parser accepts token streams with comments.
+func (l *Lexer) SetKeepComments(keep bool) {
+	l.keepComments = keep
+}
+
+// Resume creates a lexer that starts scanning source at the byte offset
+// pos, which must not be inside a token or comment, at the given 1-based
+// line and column. Editors use it to re-lex the part of a file after an
+// edit.
+func Resume(source string, pos, line, col int) *Lexer {
+	l := New(source)
+	l.pos, l.line, l.col = pos, line, col
+	return l
+}
+
 func (l *Lexer) Tokenize() ([]Token, error) {
-	for l.pos < len(l.source) {
-		l.skipWhitespace()
-		if l.pos >= len(l.source) {
-			break
+	for {
+		tok, err := l.Next()
+		if err != nil {
+			return nil, err
 		}
+		if tok.Type == TokenEOF {
+			return l.tokens, nil
+		}
+	}
+}
 
-		r, size := utf8.DecodeRuneInString(l.source[l.pos:])
-		switch {
-		case r == '/':
-			if l.pos+1 < len(l.source) && l.source[l.pos+1] == '/' {
-				l.skipLineComment()
-				continue
-			}
-			l.addToken(TokenDivide, "/")
-			l.advance(size)
-		case r == '%':
-			l.addToken(TokenModulo, "%")
-			l.advance(size)
-		case unicode.IsLetter(r) || r == '_':
-			l.tokenizeIdentifier()
-		case unicode.IsDigit(r):
-			l.tokenizeNumber()
-		case r == '"':
-			if err := l.tokenizeString(); err != nil {
-				return nil, err
-			}
-		case r == '+':
-			l.addToken(TokenPlus, "+")
-			l.advance(size)
-		case r == '-':
-			l.addToken(TokenMinus, "-")
-			l.advance(size)
-		case r == '*':
-			l.addToken(TokenMultiply, "*")
-			l.advance(size)
-		case r == '=':
-			if l.pos+1 < len(l.source) && l.source[l.pos+1] == '=' {
-				l.addToken(TokenEqual, "==")
-				l.advance(2)
-			} else {
-				l.addToken(TokenAssign, "=")
-				l.advance(size)
+// Next scans and returns the next token, which is TokenEOF at the end of
+// the source
+func (l *Lexer) Next() (Token, error) {
+	n := len(l.tokens)
+	for len(l.tokens) == n {
+		if err := l.scan(); err != nil {
+			return Token{}, err
+		}
+	}
+	return l.tokens[n], nil
+}
+
+// scan skips whitespace and scans one token or comment, adding the end of
+// file token when nothing is left
+func (l *Lexer) scan() error {
+	l.skipWhitespace()
+	if l.pos >= len(l.source) {
+		l.markStart()
+		l.addToken(TokenEOF, "")
+		return nil
+	}
+	l.markStart()
+
+	r, size := utf8.DecodeRuneInString(l.source[l.pos:])
+	switch {
+	case r == '/':
+		if l.pos+1 < len(l.source) && l.source[l.pos+1] == '/' {
+			l.skipLineComment()
+			if l.keepComments {
+				l.addToken(TokenComment, strings.TrimRight(l.source[l.start:l.pos], "\r"))
 			}
-		case r == '(':
-			l.addToken(TokenLeftParen, "(")
-			l.advance(size)
-		case r == ')':
-			l.addToken(TokenRightParen, ")")
-			l.advance(size)
-		case r == '{':
-			l.addToken(TokenLeftBrace, "{")
-			l.advance(size)
-		case r == '}':
-			l.addToken(TokenRightBrace, "}")
-			l.advance(size)
-		case r == '[':
-			l.addToken(TokenLeftBracket, "[")
+			return nil
+		}
+		l.addToken(TokenDivide, "/")
+		l.advance(size)
+	case r == '%':
+		l.addToken(TokenModulo, "%")
+		l.advance(size)
+	case unicode.IsLetter(r) || r == '_':
+		l.tokenizeIdentifier()
+	case unicode.IsDigit(r):
+		if err := l.tokenizeNumber(); err != nil {
+			return err
+		}
+	case r == '"':
+		if err := l.tokenizeString(); err != nil {
+			return err
+		}
+	case r == '+':
+		l.addToken(TokenPlus, "+")
+		l.advance(size)
+	case r == '-':
+		l.addToken(TokenMinus, "-")
+		l.advance(size)
+	case r == '*':
+		l.addToken(TokenMultiply, "*")
+		l.advance(size)
+	case r == '=':
+		if l.pos+1 < len(l.source) && l.source[l.pos+1] == '=' {
+			l.addToken(TokenEqual, "==")
+			l.advance(2)
+		} else if l.pos+1 < len(l.source) && l.source[l.pos+1] == '>' {
+			l.addToken(TokenArrow, "=>")
+			l.advance(2)
+		} else {
+			l.addToken(TokenAssign, "=")
 			l.advance(size)
-		case r == ']':
-			l.addToken(TokenRightBracket, "]")
+		}
+	case r == '(':
+		l.addToken(TokenLeftParen, "(")
+		l.advance(size)
+	case r == ')':
+		l.addToken(TokenRightParen, ")")
+		l.advance(size)
+	case r == '{':
+		l.addToken(TokenLeftBrace, "{")
+		l.advance(size)
+	case r == '}':
+		l.addToken(TokenRightBrace, "}")
+		l.advance(size)
+	case r == '[':
+		l.addToken(TokenLeftBracket, "[")
+		l.advance(size)
+	case r == ']':
+		l.addToken(TokenRightBracket, "]")
+		l.advance(size)
+	case r == ',':
+		l.addToken(TokenComma, ",")
+		l.advance(size)
+	case r == ';':
+		l.addToken(TokenSemicolon, ";")
+		l.advance(size)
+	case r == ':':
+		l.addToken(TokenColon, ":")
+		l.advance(size)
+	case r == '?':
+		if l.pos+1 < len(l.source) && l.source[l.pos+1] == '.' {
+			l.addToken(TokenQuestionDot, "?.")
+			l.advance(2)
+		} else if l.pos+1 < len(l.source) && l.source[l.pos+1] == '?' {
+			l.addToken(TokenQuestionQuestion, "??")
+			l.advance(2)
+		} else {
+			l.addToken(TokenQuestion, "?")
 			l.advance(size)
-		case r == ',':
-			l.addToken(TokenComma, ",")
+		}
+	case r == '<':
+		if l.pos+1 < len(l.source) && l.source[l.pos+1] == '=' {
+			l.addToken(TokenLessEqual, "<=")
+			l.advance(2)
+		} else {
+			l.addToken(TokenLess, "<")
 			l.advance(size)
-		case r == ';':
-			l.addToken(TokenSemicolon, ";")
+		}
+	case r == '>':
+		if l.pos+1 < len(l.source) && l.source[l.pos+1] == '=' {
+			l.addToken(TokenGreaterEqual, ">=")
+			l.advance(2)
+		} else {
+			l.addToken(TokenGreater, ">")
 			l.advance(size)
-		case r == ':':
-			l.addToken(TokenColon, ":")
+		}
+	case r == '!':
+		if l.pos+1 < len(l.source) && l.source[l.pos+1] == '=' {
+			l.addToken(TokenNotEqual, "!=")
+			l.advance(2)
+		} else {
+			l.addToken(TokenNot, "!")
 			l.advance(size)
-		case r == '<':
-			if l.pos+1 < len(l.source) && l.source[l.pos+1] == '=' {
-				l.addToken(TokenLessEqual, "<=")
-				l.advance(2)
-			} else {
-				l.addToken(TokenLess, "<")
-				l.advance(size)
-			}
-		case r == '>':
-			if l.pos+1 < len(l.source) && l.source[l.pos+1] == '=' {
-				l.addToken(TokenGreaterEqual, ">=")
-				l.advance(2)
-			} else {
-				l.addToken(TokenGreater, ">")
-				l.advance(size)
-			}
-		case r == '!':
-			if l.pos+1 < len(l.source) && l.source[l.pos+1] == '=' {
-				l.addToken(TokenNotEqual, "!=")
-				l.advance(2)
-			} else {
-				l.addToken(TokenNot, "!")
-				l.advance(size)
-			}
-		case r == '&':
-			if l.pos+1 < len(l.source) && l.source[l.pos+1] == '&' {
-				l.addToken(TokenAnd, "&&")
-				l.advance(2)
-			} else {
-				return nil, fmt.Errorf("unexpected character '&' at line %d, col %d", l.line, l.col)
-			}
-		case r == '|':
-			if l.pos+1 < len(l.source) && l.source[l.pos+1] == '|' {
-				l.addToken(TokenOr, "||")
-				l.advance(2)
-			} else {
-				return nil, fmt.Errorf("unexpected character '|' at line %d, col %d", l.line, l.col)
-			}
-		case r == '.':
+		}
+	case r == '&':
+		if l.pos+1 < len(l.source) && l.source[l.pos+1] == '&' {
+			l.addToken(TokenAnd, "&&")
+			l.advance(2)
+		} else {
+			return errcode.Errorf("BRN0101", "unexpected character '&' at line %d, col %d", l.line, l.col)
+		}
+	case r == '|':
+		if l.pos+1 < len(l.source) && l.source[l.pos+1] == '|' {
+			l.addToken(TokenOr, "||")
+			l.advance(2)
+		} else {
+			return errcode.Errorf("BRN0101", "unexpected character '|' at line %d, col %d", l.line, l.col)
+		}
+	case r == '.':
+		if strings.HasPrefix(l.source[l.pos:], "...") {
+			l.addToken(TokenEllipsis, "...")
+			l.advance(3)
+		} else if l.pos+1 < len(l.source) && l.source[l.pos+1] == '.' {
+			l.addToken(TokenDotDot, "..")
+			l.advance(2)
+		} else {
 			l.addToken(TokenDot, ".")
 			l.advance(size)
-		default:
-			return nil, fmt.Errorf("unexpected character '%c' at line %d, col %d", r, l.line, l.col)
 		}
+	default:
+		return errcode.Errorf("BRN0101", "unexpected character '%c' at line %d, col %d", r, l.line, l.col)
 	}
+	return nil
+}
 
-	l.addToken(TokenEOF, "")
-	return l.tokens, nil
+// markStart records where the token about to be scanned begins
+func (l *Lexer) markStart() {
+	l.start = l.pos
+	l.startLine = l.line
+	l.startCol = l.col
 }
 
 func (l *Lexer) advance(n int) {
@@ -165,12 +248,14 @@ func (l *Lexer) addToken(tokenType TokenType, value string) {
 	l.tokens = append(l.tokens, Token{
 		Type:     tokenType,
 		Value:    value,
-		Line:     l.line,
-		Col:      l.col - len(value),
-		Position: l.pos,
+		Line:     l.startLine,
+		Col:      l.startCol,
+		Position: l.start,
 	})
 }
 
+// Position returns the start of the token being scanned, which is where a
+// lexical error is reported
 func (l *Lexer) Position() int {
-	return l.pos
+	return l.start
 }
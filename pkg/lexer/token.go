@@ -50,6 +50,24 @@ const (
 	TokenModulo
 	TokenClass
 	TokenTypeVoid
+	// TokenComment is a // comment, only emitted when comments are kept
+	TokenComment
+	TokenBreak
+	TokenContinue
+	TokenMatch
+	TokenArrow
+	TokenDotDot
+	TokenIn
+	TokenNil
+	TokenQuestion
+	TokenInterface
+	TokenPrivate
+	// TokenEllipsis is the ... before the type of a variadic parameter
+	TokenEllipsis
+	// TokenQuestionDot is the ?. of optional chaining, as in user?.name
+	TokenQuestionDot
+	// TokenQuestionQuestion is the ?? giving a value for nil, as in name ?? ""
+	TokenQuestionQuestion
 )
 
 type Token struct {
@@ -62,23 +80,30 @@ type Token struct {
 
 func GetKeywords() map[string]TokenType {
 	return map[string]TokenType{
-		"fun":    TokenFun,
-		"var":    TokenVar,
-		"const":  TokenConst,
-		"type":   TokenTypeKeyword,
-		"if":     TokenIf,
-		"else":   TokenElse,
-		"return": TokenReturn,
-		"while":  TokenWhile,
-		"for":    TokenFor,
-		"true":   TokenTrue,
-		"false":  TokenFalse,
-		"int":    TokenTypeInt,
-		"float":  TokenTypeFloat,
-		"string": TokenTypeString,
-		"bool":   TokenTypeBool,
-		"import": TokenImport,
-		"class":  TokenClass,
-		"void":   TokenTypeVoid,
+		"fun":       TokenFun,
+		"var":       TokenVar,
+		"const":     TokenConst,
+		"type":      TokenTypeKeyword,
+		"if":        TokenIf,
+		"else":      TokenElse,
+		"return":    TokenReturn,
+		"while":     TokenWhile,
+		"for":       TokenFor,
+		"true":      TokenTrue,
+		"false":     TokenFalse,
+		"int":       TokenTypeInt,
+		"float":     TokenTypeFloat,
+		"string":    TokenTypeString,
+		"bool":      TokenTypeBool,
+		"import":    TokenImport,
+		"class":     TokenClass,
+		"void":      TokenTypeVoid,
+		"break":     TokenBreak,
+		"continue":  TokenContinue,
+		"match":     TokenMatch,
+		"in":        TokenIn,
+		"nil":       TokenNil,
+		"interface": TokenInterface,
+		"private":   TokenPrivate,
 	}
 }
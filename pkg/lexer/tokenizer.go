@@ -1,10 +1,13 @@
 package lexer
 
 import (
-	"fmt"
+	"math/big"
+	"strconv"
 	"strings"
 	"unicode"
 	"unicode/utf8"
+
+	"github.com/burnlang/burn/pkg/errcode"
 )
 
 func (l *Lexer) tokenizeIdentifier() {
@@ -27,29 +30,230 @@ func (l *Lexer) tokenizeIdentifier() {
 	}
 }
 
-func (l *Lexer) tokenizeNumber() {
+// tokenizeNumber scans a number literal: a decimal integer or float, or an
+// integer in hexadecimal (0x), octal (0o) or binary (0b). Decimal literals
+// may have an exponent, as in 2.5e-3, and underscores may separate digits,
+// as in 1_000_000. An integer followed by n, as in 123n, is a bigint, and
+// a decimal number followed by d, as in 19.99d, is a decimal.
+func (l *Lexer) tokenizeNumber() error {
 	start := l.pos
 
-	for l.pos < len(l.source) && unicode.IsDigit(rune(l.source[l.pos])) {
+	if base, prefixed := numberBase(l.source[l.pos:]); prefixed {
+		l.advance(2)
+		for l.pos < len(l.source) && isWordByte(l.source[l.pos]) {
+			l.advance(1)
+		}
+		text := l.source[start:l.pos]
+		if err := checkDigits(strings.TrimSuffix(text[2:], "n"), base, true); err != nil {
+			return errcode.Errorf("BRN0103", "invalid number %s at line %d: %v", text, l.startLine, err)
+		}
+		l.addToken(TokenNumber, text)
+		return nil
+	}
+
+	l.skipDigits()
+	if l.pos < len(l.source) && l.source[l.pos] == 'n' && (l.pos+1 == len(l.source) || !isWordByte(l.source[l.pos+1])) {
+		l.advance(1)
+		text := l.source[start:l.pos]
+		if err := checkDigits(text[:len(text)-1], 10, false); err != nil {
+			return errcode.Errorf("BRN0103", "invalid number %s at line %d: %v", text, l.startLine, err)
+		}
+		l.addToken(TokenNumber, text)
+		return nil
+	}
+	if l.pos+1 < len(l.source) && l.source[l.pos] == '.' && unicode.IsDigit(rune(l.source[l.pos+1])) {
+		l.advance(1)
+		l.skipDigits()
+	}
+	mantissaEnd := l.pos
+	if l.pos < len(l.source) && (l.source[l.pos] == 'e' || l.source[l.pos] == 'E') {
 		l.advance(1)
+		if l.pos < len(l.source) && (l.source[l.pos] == '+' || l.source[l.pos] == '-') {
+			l.advance(1)
+		}
+		l.skipDigits()
 	}
 
-	if l.pos < len(l.source) && l.source[l.pos] == '.' {
+	text := l.source[start:l.pos]
+	mantissa, exponent := text[:mantissaEnd-start], text[mantissaEnd-start:]
+	whole, fraction, _ := strings.Cut(mantissa, ".")
+	if err := checkDigits(whole, 10, false); err != nil {
+		return errcode.Errorf("BRN0103", "invalid number %s at line %d: %v", text, l.startLine, err)
+	}
+	if fraction != "" {
+		if err := checkDigits(fraction, 10, false); err != nil {
+			return errcode.Errorf("BRN0103", "invalid number %s at line %d: %v", text, l.startLine, err)
+		}
+	}
+	if exponent != "" {
+		if err := checkDigits(strings.TrimLeft(exponent[1:], "+-"), 10, false); err != nil {
+			return errcode.Errorf("BRN0103", "invalid number %s at line %d: %v", text, l.startLine, err)
+		}
+	}
+	if l.pos < len(l.source) && l.source[l.pos] == 'd' && (l.pos+1 == len(l.source) || !isWordByte(l.source[l.pos+1])) {
 		l.advance(1)
+		text = l.source[start:l.pos]
+	}
+	l.addToken(TokenNumber, text)
+	return nil
+}
 
-		if l.pos < len(l.source) && !unicode.IsDigit(rune(l.source[l.pos])) {
+// skipDigits moves past decimal digits and the underscores between them
+func (l *Lexer) skipDigits() {
+	for l.pos < len(l.source) && (unicode.IsDigit(rune(l.source[l.pos])) || l.source[l.pos] == '_') {
+		l.advance(1)
+	}
+}
 
-			l.pos--
-			l.col--
-		} else {
+// numberBase returns the base named by the prefix of a number literal
+func numberBase(text string) (int, bool) {
+	if len(text) < 2 || text[0] != '0' {
+		return 10, false
+	}
+	switch text[1] {
+	case 'x', 'X':
+		return 16, true
+	case 'o', 'O':
+		return 8, true
+	case 'b', 'B':
+		return 2, true
+	}
+	return 10, false
+}
 
-			for l.pos < len(l.source) && unicode.IsDigit(rune(l.source[l.pos])) {
-				l.advance(1)
+func isWordByte(c byte) bool {
+	return c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// checkDigits validates the digits of a number literal in base. Underscores
+// must separate digits; after a base prefix one may also lead.
+func checkDigits(digits string, base int, prefixed bool) error {
+	if strings.Trim(digits, "_") == "" {
+		return errcode.Errorf("BRN0103", "no digits")
+	}
+	for j := 0; j < len(digits); j++ {
+		c := digits[j]
+		if c == '_' {
+			first := j == 0 && !prefixed
+			if first || j == len(digits)-1 || digits[j+1] == '_' {
+				return errcode.Errorf("BRN0103", "'_' must separate successive digits")
 			}
+			continue
 		}
+		if digitValue(c) >= base {
+			return errcode.Errorf("BRN0103", "invalid digit %q for base %d", c, base)
+		}
+	}
+	return nil
+}
+
+func digitValue(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'a' && c <= 'z':
+		return int(c-'a') + 10
+	case c >= 'A' && c <= 'Z':
+		return int(c-'A') + 10
+	}
+	return 36
+}
+
+// ParseNumber returns the value of a number literal accepted by the lexer,
+// which for a bigint or decimal literal is the nearest float
+func ParseNumber(text string) (float64, error) {
+	clean := strings.ReplaceAll(text, "_", "")
+	if base, prefixed := numberBase(clean); prefixed {
+		n, err := strconv.ParseUint(strings.TrimSuffix(clean[2:], "n"), base, 64)
+		if err != nil {
+			return 0, errcode.Errorf("BRN0103", "invalid number: %s", text)
+		}
+		return float64(n), nil
+	}
+	f, err := strconv.ParseFloat(strings.TrimRight(clean, "nd"), 64)
+	if err != nil {
+		return 0, errcode.Errorf("BRN0103", "invalid number: %s", text)
+	}
+	return f, nil
+}
+
+// IsDecimal reports whether a number literal is a decimal, written with
+// the suffix d
+func IsDecimal(text string) bool {
+	_, prefixed := numberBase(text)
+	return !prefixed && strings.HasSuffix(text, "d")
+}
+
+// maxDecimalExponent is the largest exponent a decimal can be written with
+const maxDecimalExponent = 4096
+
+// ParseDecimal returns the value of a decimal literal accepted by the lexer
+// as its digits and the number of them after the decimal point: 19.99d is
+// 1999 and 2, and 1.5e3d is 1500 and 0
+func ParseDecimal(text string) (*big.Int, int, error) {
+	clean := strings.TrimSuffix(strings.ReplaceAll(text, "_", ""), "d")
+	mantissa, exponent, _ := strings.Cut(strings.ToLower(clean), "e")
+	whole, fraction, _ := strings.Cut(mantissa, ".")
+	digits, ok := new(big.Int).SetString(whole+fraction, 10)
+	if !ok {
+		return nil, 0, errcode.Errorf("BRN0103", "invalid number: %s", text)
+	}
+	scale := len(fraction)
+	if exponent != "" {
+		// The digits of a decimal are all kept, so its exponent is limited
+		shift, err := strconv.Atoi(exponent)
+		if err != nil || shift < -maxDecimalExponent || shift > maxDecimalExponent {
+			return nil, 0, errcode.Errorf("BRN0103", "invalid number: %s", text)
+		}
+		scale -= shift
+	}
+	if scale < 0 {
+		digits.Mul(digits, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-scale)), nil))
+		scale = 0
 	}
+	return digits, scale, nil
+}
+
+// maxExactInt is the largest whole number up to which every integer is
+// exactly a float64
+const maxExactInt = 1 << 53
 
-	l.addToken(TokenNumber, l.source[start:l.pos])
+// IsBigInt reports whether a number literal is a bigint: one written with
+// the suffix n, or an integer too large for every integer up to it to be
+// exact as an int
+func IsBigInt(text string) bool {
+	if strings.HasSuffix(text, "n") {
+		return true
+	}
+	if IsFloat(text) || IsDecimal(text) {
+		return false
+	}
+	n, err := ParseBigInt(text)
+	return err == nil && n.CmpAbs(big.NewInt(maxExactInt)) > 0
+}
+
+// ParseBigInt returns the value of an integer literal accepted by the
+// lexer, with or without the suffix n, as a bigint
+func ParseBigInt(text string) (*big.Int, error) {
+	clean := strings.TrimSuffix(strings.ReplaceAll(text, "_", ""), "n")
+	base, prefixed := numberBase(clean)
+	if prefixed {
+		clean = clean[2:]
+	}
+	n, ok := new(big.Int).SetString(clean, base)
+	if !ok {
+		return nil, errcode.Errorf("BRN0103", "invalid number: %s", text)
+	}
+	return n, nil
+}
+
+// IsFloat reports whether a number literal is written with a fraction or
+// an exponent, as 2.5 and 1e3 are, which makes it a float and not an int
+func IsFloat(text string) bool {
+	if _, prefixed := numberBase(text); prefixed || IsDecimal(text) {
+		return false
+	}
+	return strings.ContainsAny(text, ".eE")
 }
 
 func (l *Lexer) tokenizeString() error {
@@ -66,7 +270,7 @@ func (l *Lexer) tokenizeString() error {
 	}
 
 	if l.pos >= len(l.source) {
-		return fmt.Errorf("unterminated string at line %d", l.line)
+		return errcode.Errorf("BRN0102", "unterminated string at line %d", l.startLine)
 	}
 
 	value := processEscapes(l.source[start+1 : l.pos])
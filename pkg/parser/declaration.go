@@ -1,10 +1,11 @@
 package parser
 
 import (
-	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
 	"github.com/burnlang/burn/pkg/lexer"
 )
 
@@ -15,7 +16,19 @@ func (p *Parser) declaration() (ast.Declaration, error) {
 	if p.match(lexer.TokenClass) {
 		return p.classDeclaration()
 	}
-	if p.match(lexer.TokenFun) {
+	if p.match(lexer.TokenInterface) {
+		return p.interfaceDeclaration()
+	}
+	if p.match(lexer.TokenPrivate) {
+		if p.currentFunc != nil {
+			return nil, errcode.Errorf("BRN0203", "only top-level functions and class members can be private at line %d", p.previous().Line)
+		}
+		return p.privateFunction()
+	}
+	// fun followed by '(' starts a lambda rather than a declaration, unless
+	// the parenthesis holds the receiver of a method
+	if p.check(lexer.TokenFun) && (!p.checkNext(lexer.TokenLeftParen) || p.isMethodDeclaration()) {
+		p.advance()
 		return p.functionDeclaration()
 	}
 	if p.match(lexer.TokenVar) {
@@ -32,40 +45,47 @@ func (p *Parser) declaration() (ast.Declaration, error) {
 }
 
 func (p *Parser) importDeclaration() (ast.Declaration, error) {
+	start := p.peek()
 	if p.match(lexer.TokenLeftParen) {
 		imports := []*ast.ImportDeclaration{}
 
 		for !p.check(lexer.TokenRightParen) && !p.isAtEnd() {
 			if !p.match(lexer.TokenString) {
-				return nil, fmt.Errorf("expected string in import block at line %d", p.peek().Line)
+				return nil, errcode.Errorf("BRN0201", "expected string in import block at line %d", p.peek().Line)
 			}
 
 			path := p.previous().Value
 			processedPath := p.processImportPath(path)
 
 			imports = append(imports, &ast.ImportDeclaration{
-				Path: processedPath,
+				Path:     processedPath,
+				Position: p.previous().Position,
+				Location: location(p.previous()),
 			})
 		}
 
 		if !p.match(lexer.TokenRightParen) {
-			return nil, fmt.Errorf("expected ')' after import block at line %d", p.peek().Line)
+			return nil, errcode.Errorf("BRN0201", "expected ')' after import block at line %d", p.peek().Line)
 		}
 
 		return &ast.MultiImportDeclaration{
-			Imports: imports,
+			Imports:  imports,
+			Position: start.Position,
+			Location: location(start),
 		}, nil
 	}
 
 	if !p.match(lexer.TokenString) {
-		return nil, fmt.Errorf("expected string after import at line %d", p.peek().Line)
+		return nil, errcode.Errorf("BRN0201", "expected string after import at line %d", p.peek().Line)
 	}
 
 	path := p.previous().Value
 	processedPath := p.processImportPath(path)
 
 	return &ast.ImportDeclaration{
-		Path: processedPath,
+		Path:     processedPath,
+		Position: start.Position,
+		Location: location(start),
 	}, nil
 }
 
@@ -73,7 +93,7 @@ func (p *Parser) processImportPath(path string) string {
 	trimmedPath := strings.Trim(path, "\"")
 
 	if !strings.Contains(trimmedPath, "/") && !strings.Contains(trimmedPath, "\\") {
-		return "src/lib/std/" + trimmedPath + ".bn"
+		return "src/lib/std/" + strings.TrimSuffix(trimmedPath, ".bn") + ".bn"
 	}
 
 	if strings.HasSuffix(trimmedPath, ".bn") {
@@ -83,42 +103,170 @@ func (p *Parser) processImportPath(path string) string {
 	return trimmedPath + ".bn"
 }
 
+// isMethodDeclaration reports whether the fun at the current token is
+// followed by a parenthesized receiver and a method name, where a lambda
+// has its return type or body
+func (p *Parser) isMethodDeclaration() bool {
+	depth := 0
+	for j := p.current + 1; j < len(p.tokens); j++ {
+		p.furthest = max(p.furthest, j)
+		switch p.tokens[j].Type {
+		case lexer.TokenLeftParen:
+			depth++
+		case lexer.TokenRightParen:
+			depth--
+			if depth == 0 {
+				p.furthest = max(p.furthest, j+1)
+				return j+1 < len(p.tokens) && p.tokens[j+1].Type == lexer.TokenIdentifier
+			}
+		case lexer.TokenEOF:
+			return false
+		}
+	}
+	return false
+}
+
 func (p *Parser) functionDeclaration() (ast.Declaration, error) {
+	doc := p.doc()
+
+	var receiver *ast.Parameter
+	if p.match(lexer.TokenLeftParen) {
+		params, err := p.parameters()
+		if err != nil {
+			return nil, err
+		}
+		if len(params) != 1 || strings.HasPrefix(params[0].Type, "...") {
+			return nil, errcode.Errorf("BRN0201", "expected one receiver before method name at line %d", p.previous().Line)
+		}
+		if p.currentFunc != nil {
+			return nil, errcode.Errorf("BRN0203", "methods must be declared at the top level at line %d", p.previous().Line)
+		}
+		receiver = &params[0]
+	}
+
 	if !p.check(lexer.TokenIdentifier) {
-		return nil, fmt.Errorf("expected function name at line %d", p.peek().Line)
+		return nil, errcode.Errorf("BRN0201", "expected function name at line %d", p.peek().Line)
 	}
 
+	start := p.peek()
 	name := p.advance().Value
 
+	typeParams, err := p.typeParameters()
+	if err != nil {
+		return nil, err
+	}
+	if len(typeParams) > 0 && (receiver != nil || p.currentFunc != nil) {
+		return nil, errcode.Errorf("BRN0203", "only top-level functions can have type parameters at line %d", start.Line)
+	}
+
 	if !p.match(lexer.TokenLeftParen) {
-		return nil, fmt.Errorf("expected '(' after function name at line %d", p.peek().Line)
+		return nil, errcode.Errorf("BRN0201", "expected '(' after function name at line %d", p.peek().Line)
 	}
 
+	parameters, err := p.parameters()
+	if err != nil {
+		return nil, err
+	}
+
+	returnType, err := p.returnType()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.match(lexer.TokenLeftBrace) {
+		return nil, errcode.Errorf("BRN0201", "expected '{' for function body at line %d", p.peek().Line)
+	}
+
+	fn := &ast.FunctionDeclaration{
+		Doc:            doc,
+		Name:           name,
+		TypeParameters: typeParams,
+		Parameters:     parameters,
+		ReturnType:     returnType,
+		Position:       start.Position,
+		Location:       location(start),
+	}
+	if receiver != nil {
+		fn.Receiver = receiver.Type
+		fn.Parameters = append([]ast.Parameter{*receiver}, parameters...)
+	}
+
+	prevFunc := p.currentFunc
+	p.currentFunc = fn
+
+	body, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+
+	fn.Body = body
+	p.currentFunc = prevFunc
+
+	return fn, nil
+}
+
+// typeParameters parses the optional <T, U> after the name of a generic
+// function or type
+func (p *Parser) typeParameters() ([]string, error) {
+	if !p.match(lexer.TokenLess) {
+		return nil, nil
+	}
+	params := []string{}
+	for {
+		if !p.check(lexer.TokenIdentifier) {
+			return nil, errcode.Errorf("BRN0201", "expected type parameter name at line %d", p.peek().Line)
+		}
+		param := p.advance().Value
+		if slices.Contains(params, param) {
+			return nil, errcode.Errorf("BRN0207", "duplicate type parameter %s at line %d", param, p.previous().Line)
+		}
+		params = append(params, param)
+		if !p.match(lexer.TokenComma) {
+			break
+		}
+	}
+	if !p.match(lexer.TokenGreater) {
+		return nil, errcode.Errorf("BRN0201", "expected '>' after type parameters at line %d", p.peek().Line)
+	}
+	return params, nil
+}
+
+// parameters parses a parameter list up to and including its ')'
+func (p *Parser) parameters() ([]ast.Parameter, error) {
 	parameters := []ast.Parameter{}
 
 	if !p.check(lexer.TokenRightParen) {
 		for {
 			if !p.check(lexer.TokenIdentifier) {
-				return nil, fmt.Errorf("expected parameter name at line %d", p.peek().Line)
+				return nil, errcode.Errorf("BRN0201", "expected parameter name at line %d", p.peek().Line)
 			}
 
+			param := p.peek()
 			paramName := p.advance().Value
 
 			if !p.match(lexer.TokenColon) {
-				return nil, fmt.Errorf("expected ':' after parameter name at line %d", p.peek().Line)
+				return nil, errcode.Errorf("BRN0201", "expected ':' after parameter name at line %d", p.peek().Line)
 			}
 
-			if !p.check(lexer.TokenTypeInt) && !p.check(lexer.TokenTypeFloat) &&
-				!p.check(lexer.TokenTypeString) && !p.check(lexer.TokenTypeBool) &&
-				!p.check(lexer.TokenIdentifier) {
-				return nil, fmt.Errorf("expected type after ':' at line %d", p.peek().Line)
+			// A variadic parameter such as values: ...int takes the rest of
+			// the arguments as an array
+			variadic := p.match(lexer.TokenEllipsis)
+			paramType, err := p.typeName("type", false)
+			if err != nil {
+				return nil, err
+			}
+			if variadic {
+				if p.check(lexer.TokenComma) {
+					return nil, errcode.Errorf("BRN0205", "variadic parameter %s must be the last at line %d", paramName, param.Line)
+				}
+				paramType = "..." + paramType
 			}
-
-			paramType := p.advance().Value
 
 			parameters = append(parameters, ast.Parameter{
-				Name: paramName,
-				Type: paramType,
+				Name:     paramName,
+				Type:     paramType,
+				Position: param.Position,
+				Location: location(param),
 			})
 
 			if !p.match(lexer.TokenComma) {
@@ -128,61 +276,173 @@ func (p *Parser) functionDeclaration() (ast.Declaration, error) {
 	}
 
 	if !p.match(lexer.TokenRightParen) {
-		return nil, fmt.Errorf("expected ')' after parameters at line %d", p.peek().Line)
+		return nil, errcode.Errorf("BRN0201", "expected ')' after parameters at line %d", p.peek().Line)
 	}
 
-	returnType := ""
-	if p.match(lexer.TokenColon) {
-		if !p.check(lexer.TokenTypeInt) && !p.check(lexer.TokenTypeFloat) &&
-			!p.check(lexer.TokenTypeString) && !p.check(lexer.TokenTypeBool) &&
-			!p.check(lexer.TokenTypeVoid) &&
-			!p.check(lexer.TokenIdentifier) {
-			return nil, fmt.Errorf("expected return type after ':' at line %d", p.peek().Line)
-		}
-		returnType = p.advance().Value
+	return parameters, nil
+}
+
+// returnType parses the optional ': type' after a parameter list
+func (p *Parser) returnType() (string, error) {
+	if !p.match(lexer.TokenColon) {
+		return "", nil
 	}
+	return p.typeName("return type", true)
+}
 
-	if !p.match(lexer.TokenLeftBrace) {
-		return nil, fmt.Errorf("expected '{' for function body at line %d", p.peek().Line)
+// typeName parses a type after a ':': a builtin type, the name of a type or
+// class, a function type such as fun(int, string): bool, whose return type
+// defaults to void, an array type such as [int], a map type such as
+// map<string, int>, a Result or Option type such as Result<int>, or a
+// generic type with its type arguments, such as Pair<int, string>. Any of
+// these but void can be made optional with a ?, as in string?. what names
+// the expected type in the error.
+func (p *Parser) typeName(what string, allowVoid bool) (string, error) {
+	typeName, err := p.plainTypeName(what, allowVoid)
+	if err != nil || !p.match(lexer.TokenQuestion) {
+		return typeName, err
 	}
+	if typeName == "void" {
+		return "", errcode.Errorf("BRN0207", "void cannot be optional at line %d", p.previous().Line)
+	}
+	return typeName + "?", nil
+}
 
-	fn := &ast.FunctionDeclaration{
-		Name:       name,
-		Parameters: parameters,
-		ReturnType: returnType,
+// plainTypeName parses a type after a ':' without the ? of an optional type
+func (p *Parser) plainTypeName(what string, allowVoid bool) (string, error) {
+	if p.match(lexer.TokenLeftBracket) {
+		elemType, err := p.typeName("element type", false)
+		if err != nil {
+			return "", err
+		}
+		if !p.match(lexer.TokenRightBracket) {
+			return "", errcode.Errorf("BRN0201", "expected ']' after array element type at line %d", p.peek().Line)
+		}
+		return "[" + elemType + "]", nil
 	}
 
-	prevFunc := p.currentFunc
-	p.currentFunc = fn
+	if p.match(lexer.TokenFun) {
+		if !p.match(lexer.TokenLeftParen) {
+			return "", errcode.Errorf("BRN0201", "expected '(' after fun in function type at line %d", p.peek().Line)
+		}
 
-	body, err := p.block()
-	if err != nil {
-		return nil, err
+		parameters := []string{}
+		if !p.check(lexer.TokenRightParen) {
+			for {
+				variadic := p.match(lexer.TokenEllipsis)
+				paramType, err := p.typeName("parameter type", false)
+				if err != nil {
+					return "", err
+				}
+				if variadic {
+					if p.check(lexer.TokenComma) {
+						return "", errcode.Errorf("BRN0205", "variadic parameter type must be the last at line %d", p.peek().Line)
+					}
+					paramType = "..." + paramType
+				}
+				parameters = append(parameters, paramType)
+
+				if !p.match(lexer.TokenComma) {
+					break
+				}
+			}
+		}
+
+		if !p.match(lexer.TokenRightParen) {
+			return "", errcode.Errorf("BRN0201", "expected ')' after parameter types at line %d", p.peek().Line)
+		}
+
+		returnType, err := p.returnType()
+		if err != nil {
+			return "", err
+		}
+		if returnType == "" {
+			returnType = "void"
+		}
+
+		return "fun(" + strings.Join(parameters, ", ") + "): " + returnType, nil
 	}
 
-	fn.Body = body
-	p.currentFunc = prevFunc
+	if p.check(lexer.TokenIdentifier) && p.peek().Value == "map" && p.checkNext(lexer.TokenLess) {
+		p.advance()
+		p.advance()
+		keyType, err := p.typeName("key type", false)
+		if err != nil {
+			return "", err
+		}
+		if !p.match(lexer.TokenComma) {
+			return "", errcode.Errorf("BRN0201", "expected ',' after map key type at line %d", p.peek().Line)
+		}
+		valueType, err := p.typeName("value type", false)
+		if err != nil {
+			return "", err
+		}
+		if !p.match(lexer.TokenGreater) {
+			return "", errcode.Errorf("BRN0201", "expected '>' after map value type at line %d", p.peek().Line)
+		}
+		return "map<" + keyType + ", " + valueType + ">", nil
+	}
 
-	return fn, nil
+	if p.check(lexer.TokenIdentifier) && (p.peek().Value == "Result" || p.peek().Value == "Option") &&
+		p.checkNext(lexer.TokenLess) {
+		kind := p.advance().Value
+		p.advance()
+		valueType, err := p.typeName("value type", false)
+		if err != nil {
+			return "", err
+		}
+		if !p.match(lexer.TokenGreater) {
+			return "", errcode.Errorf("BRN0201", "expected '>' after %s value type at line %d", kind, p.peek().Line)
+		}
+		return kind + "<" + valueType + ">", nil
+	}
+
+	if p.check(lexer.TokenIdentifier) && p.checkNext(lexer.TokenLess) {
+		name := p.advance().Value
+		p.advance()
+		args := []string{}
+		for {
+			arg, err := p.typeName("type argument", false)
+			if err != nil {
+				return "", err
+			}
+			args = append(args, arg)
+			if !p.match(lexer.TokenComma) {
+				break
+			}
+		}
+		if !p.match(lexer.TokenGreater) {
+			return "", errcode.Errorf("BRN0201", "expected '>' after type arguments of %s at line %d", name, p.peek().Line)
+		}
+		return name + "<" + strings.Join(args, ", ") + ">", nil
+	}
+
+	if !p.check(lexer.TokenTypeInt) && !p.check(lexer.TokenTypeFloat) &&
+		!p.check(lexer.TokenTypeString) && !p.check(lexer.TokenTypeBool) &&
+		!(allowVoid && p.check(lexer.TokenTypeVoid)) &&
+		!p.check(lexer.TokenIdentifier) {
+		return "", errcode.Errorf("BRN0201", "expected %s after ':' at line %d", what, p.peek().Line)
+	}
+	return p.advance().Value, nil
 }
 
 func (p *Parser) variableDeclaration(isConst bool) (ast.Declaration, error) {
-	pos := p.peek().Position
+	doc := p.doc()
+	start := p.peek()
 
 	if !p.check(lexer.TokenIdentifier) {
-		return nil, fmt.Errorf("expected variable name at line %d", p.peek().Line)
+		return nil, errcode.Errorf("BRN0201", "expected variable name at line %d", p.peek().Line)
 	}
 
 	name := p.advance().Value
 	typeName := ""
 
 	if p.match(lexer.TokenColon) {
-		if !p.check(lexer.TokenTypeInt) && !p.check(lexer.TokenTypeFloat) &&
-			!p.check(lexer.TokenTypeString) && !p.check(lexer.TokenTypeBool) &&
-			!p.check(lexer.TokenIdentifier) {
-			return nil, fmt.Errorf("expected type after ':' at line %d", p.peek().Line)
+		var err error
+		typeName, err = p.typeName("type", false)
+		if err != nil {
+			return nil, err
 		}
-		typeName = p.advance().Value
 	}
 
 	var value ast.Expression
@@ -193,32 +453,40 @@ func (p *Parser) variableDeclaration(isConst bool) (ast.Declaration, error) {
 			return nil, err
 		}
 	} else if isConst {
-		return nil, fmt.Errorf("const declaration must have initializer at line %d", p.peek().Line)
+		return nil, errcode.Errorf("BRN0204", "const declaration must have initializer at line %d", p.peek().Line)
 	}
 
 	if p.match(lexer.TokenSemicolon) {
 	}
 
 	return &ast.VariableDeclaration{
+		Doc:      doc,
 		Name:     name,
 		Type:     typeName,
 		Value:    value,
 		IsConst:  isConst,
-		Position: pos,
+		Position: start.Position,
+		Location: location(start),
 	}, nil
 }
 
 func (p *Parser) typeDefinition() (ast.Declaration, error) {
-	pos := p.peek().Position
+	doc := p.doc()
+	start := p.peek()
 
 	if !p.check(lexer.TokenIdentifier) {
-		return nil, fmt.Errorf("expected type name at line %d", p.peek().Line)
+		return nil, errcode.Errorf("BRN0201", "expected type name at line %d", p.peek().Line)
 	}
 
 	name := p.advance().Value
 
+	typeParams, err := p.typeParameters()
+	if err != nil {
+		return nil, err
+	}
+
 	if !p.match(lexer.TokenLeftBrace) {
-		return nil, fmt.Errorf("expected '{' after type name at line %d", p.peek().Line)
+		return nil, errcode.Errorf("BRN0201", "expected '{' after type name at line %d", p.peek().Line)
 	}
 
 	fields := []ast.TypeField{}
@@ -226,26 +494,25 @@ func (p *Parser) typeDefinition() (ast.Declaration, error) {
 	if !p.check(lexer.TokenRightBrace) {
 		for {
 			if !p.check(lexer.TokenIdentifier) {
-				return nil, fmt.Errorf("expected field name at line %d", p.peek().Line)
+				return nil, errcode.Errorf("BRN0201", "expected field name at line %d", p.peek().Line)
 			}
 
-			fieldName := p.advance().Value
+			field := p.advance()
 
 			if !p.match(lexer.TokenColon) {
-				return nil, fmt.Errorf("expected ':' after field name at line %d", p.peek().Line)
+				return nil, errcode.Errorf("BRN0201", "expected ':' after field name at line %d", p.peek().Line)
 			}
 
-			if !p.check(lexer.TokenTypeInt) && !p.check(lexer.TokenTypeFloat) &&
-				!p.check(lexer.TokenTypeString) && !p.check(lexer.TokenTypeBool) &&
-				!p.check(lexer.TokenIdentifier) {
-				return nil, fmt.Errorf("expected type after ':' at line %d", p.peek().Line)
+			fieldType, err := p.typeName("type", false)
+			if err != nil {
+				return nil, err
 			}
 
-			fieldType := p.advance().Value
-
 			fields = append(fields, ast.TypeField{
-				Name: fieldName,
-				Type: fieldType,
+				Name:     field.Value,
+				Type:     fieldType,
+				Position: field.Position,
+				Location: location(field),
 			})
 
 			if p.match(lexer.TokenComma) {
@@ -257,34 +524,99 @@ func (p *Parser) typeDefinition() (ast.Declaration, error) {
 	}
 
 	if !p.match(lexer.TokenRightBrace) {
-		return nil, fmt.Errorf("expected '}' after fields at line %d", p.peek().Line)
+		return nil, errcode.Errorf("BRN0201", "expected '}' after fields at line %d", p.peek().Line)
 	}
 
 	return &ast.TypeDefinition{
-		Name:     name,
-		Fields:   fields,
-		Position: pos,
+		Doc:            doc,
+		Name:           name,
+		TypeParameters: typeParams,
+		Fields:         fields,
+		Position:       start.Position,
+		Location:       location(start),
 	}, nil
 }
 
 func (p *Parser) classDeclaration() (ast.Declaration, error) {
-	pos := p.peek().Position
+	doc := p.doc()
+	start := p.peek()
 
 	if !p.check(lexer.TokenIdentifier) {
-		return nil, fmt.Errorf("expected class name at line %d", p.peek().Line)
+		return nil, errcode.Errorf("BRN0201", "expected class name at line %d", p.peek().Line)
 	}
 
 	name := p.advance().Value
 
+	interfaces := []string{}
+	if p.check(lexer.TokenIdentifier) && p.peek().Value == "implements" {
+		p.advance()
+		for {
+			if !p.check(lexer.TokenIdentifier) {
+				return nil, errcode.Errorf("BRN0201", "expected interface name at line %d", p.peek().Line)
+			}
+			interfaces = append(interfaces, p.advance().Value)
+			if !p.match(lexer.TokenComma) {
+				break
+			}
+		}
+	}
+
 	if !p.match(lexer.TokenLeftBrace) {
-		return nil, fmt.Errorf("expected '{' after class name at line %d", p.peek().Line)
+		return nil, errcode.Errorf("BRN0201", "expected '{' after class name at line %d", p.peek().Line)
 	}
 
+	fields := []*ast.VariableDeclaration{}
+	statics := []*ast.VariableDeclaration{}
 	methods := []*ast.FunctionDeclaration{}
 
 	for !p.check(lexer.TokenRightBrace) && !p.isAtEnd() {
+		// The doc comment of a private member goes before private
+		private := p.match(lexer.TokenPrivate)
+		var privateDoc *ast.CommentGroup
+		if private {
+			privateDoc = p.doc()
+		}
+
+		if p.check(lexer.TokenIdentifier) && p.peek().Value == "static" &&
+			(p.checkNext(lexer.TokenVar) || p.checkNext(lexer.TokenConst)) {
+			p.advance()
+			doc := p.doc()
+			isConst := p.advance().Type == lexer.TokenConst
+			field, err := p.variableDeclaration(isConst)
+			if err != nil {
+				return nil, err
+			}
+			fieldDecl := field.(*ast.VariableDeclaration)
+			if fieldDecl.Value == nil {
+				return nil, errcode.Errorf("BRN0204", "static field %s needs a value at line %d", fieldDecl.Name, fieldDecl.Line)
+			}
+			fieldDecl.Doc = doc
+			if private {
+				fieldDecl.Private, fieldDecl.Doc = true, privateDoc
+			}
+			statics = append(statics, fieldDecl)
+			continue
+		}
+		if p.match(lexer.TokenVar) {
+			field, err := p.variableDeclaration(false)
+			if err != nil {
+				return nil, err
+			}
+			fieldDecl := field.(*ast.VariableDeclaration)
+			if fieldDecl.Type == "" {
+				return nil, errcode.Errorf("BRN0201", "expected type for field %s at line %d", fieldDecl.Name, fieldDecl.Line)
+			}
+			if private {
+				fieldDecl.Private, fieldDecl.Doc = true, privateDoc
+			}
+			fields = append(fields, fieldDecl)
+			continue
+		}
 		if !p.match(lexer.TokenFun) {
-			return nil, fmt.Errorf("expected function in class body at line %d", p.peek().Line)
+			return nil, errcode.Errorf("BRN0201", "expected field or function in class body at line %d", p.peek().Line)
+		}
+		if p.check(lexer.TokenLeftParen) {
+			return nil, errcode.Errorf("BRN0201", "expected method name at line %d", p.peek().Line)
 		}
 
 		method, err := p.functionDeclaration()
@@ -293,17 +625,109 @@ func (p *Parser) classDeclaration() (ast.Declaration, error) {
 		}
 
 		if fnDecl, ok := method.(*ast.FunctionDeclaration); ok {
+			if len(fnDecl.TypeParameters) > 0 {
+				return nil, errcode.Errorf("BRN0203", "only top-level functions can have type parameters at line %d", fnDecl.Line)
+			}
+			if private {
+				fnDecl.Private, fnDecl.Doc = true, privateDoc
+			}
 			methods = append(methods, fnDecl)
 		}
 	}
 
 	if !p.match(lexer.TokenRightBrace) {
-		return nil, fmt.Errorf("expected '}' after class body at line %d", p.peek().Line)
+		return nil, errcode.Errorf("BRN0201", "expected '}' after class body at line %d", p.peek().Line)
 	}
 
 	return &ast.ClassDeclaration{
+		Doc:          doc,
+		Name:         name,
+		Fields:       fields,
+		StaticFields: statics,
+		Interfaces:   interfaces,
+		Methods:      methods,
+		Position:     start.Position,
+		Location:     location(start),
+	}, nil
+}
+
+// privateFunction parses a function or method after private, which is
+// where its doc comment goes
+func (p *Parser) privateFunction() (ast.Declaration, error) {
+	doc := p.doc()
+	if !p.match(lexer.TokenFun) {
+		return nil, errcode.Errorf("BRN0201", "expected fun after private at line %d", p.peek().Line)
+	}
+	decl, err := p.functionDeclaration()
+	if err != nil {
+		return nil, err
+	}
+	fn := decl.(*ast.FunctionDeclaration)
+	fn.Private, fn.Doc = true, doc
+	return fn, nil
+}
+
+func (p *Parser) interfaceDeclaration() (ast.Declaration, error) {
+	doc := p.doc()
+	start := p.peek()
+
+	if !p.check(lexer.TokenIdentifier) {
+		return nil, errcode.Errorf("BRN0201", "expected interface name at line %d", p.peek().Line)
+	}
+
+	name := p.advance().Value
+
+	if !p.match(lexer.TokenLeftBrace) {
+		return nil, errcode.Errorf("BRN0201", "expected '{' after interface name at line %d", p.peek().Line)
+	}
+
+	methods := []*ast.FunctionDeclaration{}
+
+	for !p.check(lexer.TokenRightBrace) && !p.isAtEnd() {
+		if !p.match(lexer.TokenFun) {
+			return nil, errcode.Errorf("BRN0201", "expected function in interface body at line %d", p.peek().Line)
+		}
+		methodDoc := p.doc()
+		if !p.check(lexer.TokenIdentifier) {
+			return nil, errcode.Errorf("BRN0201", "expected method name at line %d", p.peek().Line)
+		}
+		methodStart := p.advance()
+
+		if !p.match(lexer.TokenLeftParen) {
+			return nil, errcode.Errorf("BRN0201", "expected '(' after method name at line %d", p.peek().Line)
+		}
+		parameters, err := p.parameters()
+		if err != nil {
+			return nil, err
+		}
+		returnType, err := p.returnType()
+		if err != nil {
+			return nil, err
+		}
+		if p.check(lexer.TokenLeftBrace) {
+			return nil, errcode.Errorf("BRN0207", "interface method %s cannot have a body at line %d", methodStart.Value, p.peek().Line)
+		}
+		p.match(lexer.TokenSemicolon)
+
+		methods = append(methods, &ast.FunctionDeclaration{
+			Doc:        methodDoc,
+			Name:       methodStart.Value,
+			Parameters: parameters,
+			ReturnType: returnType,
+			Position:   methodStart.Position,
+			Location:   location(methodStart),
+		})
+	}
+
+	if !p.match(lexer.TokenRightBrace) {
+		return nil, errcode.Errorf("BRN0201", "expected '}' after interface body at line %d", p.peek().Line)
+	}
+
+	return &ast.InterfaceDeclaration{
+		Doc:      doc,
 		Name:     name,
 		Methods:  methods,
-		Position: pos,
+		Position: start.Position,
+		Location: location(start),
 	}, nil
 }
@@ -0,0 +1,21 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/burnlang/burn/pkg/ast"
+)
+
+func TestImportPaths(t *testing.T) {
+	for source, want := range map[string]string{
+		`import "time"`:          "src/lib/std/time.bn",
+		`import "units.bn"`:      "src/lib/std/units.bn",
+		`import "test/utils"`:    "test/utils.bn",
+		`import "test/utils.bn"`: "test/utils.bn",
+	} {
+		imp, ok := parse(t, source).Declarations[0].(*ast.ImportDeclaration)
+		if !ok || imp.Path != want {
+			t.Errorf("%s: got %#v, want path %s", source, imp, want)
+		}
+	}
+}
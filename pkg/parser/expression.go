@@ -1,10 +1,8 @@
 package parser
 
 import (
-	"fmt"
-	"strconv"
-
 	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
 	"github.com/burnlang/burn/pkg/lexer"
 )
 
@@ -13,7 +11,7 @@ func (p *Parser) expression() (ast.Expression, error) {
 }
 
 func (p *Parser) assignment() (ast.Expression, error) {
-	expr, err := p.logicalOr()
+	expr, err := p.conditional()
 	if err != nil {
 		return nil, err
 	}
@@ -29,22 +27,96 @@ func (p *Parser) assignment() (ast.Expression, error) {
 				Name:     varExpr.Name,
 				Value:    value,
 				Position: varExpr.Position,
+				Location: varExpr.Location,
 			}, nil
 		} else if getExpr, ok := expr.(*ast.GetExpression); ok {
+			if getExpr.Optional {
+				return nil, errcode.Errorf("BRN0202", "cannot assign to a field through '?.' at line %d", getExpr.Line)
+			}
 			return &ast.SetExpression{
 				Object:   getExpr.Object,
 				Name:     getExpr.Name,
 				Value:    value,
 				Position: getExpr.Position,
+				Location: getExpr.Location,
+			}, nil
+		} else if indexExpr, ok := expr.(*ast.IndexExpression); ok {
+			return &ast.IndexAssignmentExpression{
+				Array:    indexExpr.Array,
+				Index:    indexExpr.Index,
+				Value:    value,
+				Position: indexExpr.Position,
+				Location: indexExpr.Location,
 			}, nil
 		}
 
-		return nil, fmt.Errorf("invalid assignment target at line %d", p.previous().Line)
+		return nil, errcode.Errorf("BRN0202", "invalid assignment target at line %d", p.previous().Line)
 	}
 
 	return expr, nil
 }
 
+// conditional parses cond ? a : b, which groups to the right so that
+// a ? b : c ? d : e is a ? b : (c ? d : e)
+func (p *Parser) conditional() (ast.Expression, error) {
+	expr, err := p.coalesce()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.match(lexer.TokenQuestion) {
+		return expr, nil
+	}
+	question := p.previous()
+
+	thenExpr, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if !p.match(lexer.TokenColon) {
+		return nil, errcode.Errorf("BRN0201", "expected ':' after the first branch of '?' at line %d", p.peek().Line)
+	}
+	elseExpr, err := p.conditional()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.ConditionalExpression{
+		Condition: expr,
+		Then:      thenExpr,
+		Else:      elseExpr,
+		Position:  question.Position,
+		Location:  location(question),
+	}, nil
+}
+
+// coalesce parses a ?? b, which groups to the right so that a ?? b ?? c is
+// a ?? (b ?? c)
+func (p *Parser) coalesce() (ast.Expression, error) {
+	expr, err := p.logicalOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.match(lexer.TokenQuestionQuestion) {
+		return expr, nil
+	}
+	operator := p.previous()
+
+	right, err := p.coalesce()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.BinaryExpression{
+		Left:     expr,
+		Operator: operator.Value,
+		Right:    right,
+		Position: operator.Position,
+		Location: location(operator),
+	}, nil
+}
+
 func (p *Parser) logicalOr() (ast.Expression, error) {
 	expr, err := p.logicalAnd()
 	if err != nil {
@@ -52,7 +124,7 @@ func (p *Parser) logicalOr() (ast.Expression, error) {
 	}
 
 	for p.match(lexer.TokenOr) {
-		operator := p.previous().Value
+		operator := p.previous()
 		right, err := p.logicalAnd()
 		if err != nil {
 			return nil, err
@@ -60,9 +132,10 @@ func (p *Parser) logicalOr() (ast.Expression, error) {
 
 		expr = &ast.BinaryExpression{
 			Left:     expr,
-			Operator: operator,
+			Operator: operator.Value,
 			Right:    right,
-			Position: p.previous().Position,
+			Position: operator.Position,
+			Location: location(operator),
 		}
 	}
 
@@ -76,7 +149,7 @@ func (p *Parser) logicalAnd() (ast.Expression, error) {
 	}
 
 	for p.match(lexer.TokenAnd) {
-		operator := p.previous().Value
+		operator := p.previous()
 		right, err := p.equality()
 		if err != nil {
 			return nil, err
@@ -84,9 +157,10 @@ func (p *Parser) logicalAnd() (ast.Expression, error) {
 
 		expr = &ast.BinaryExpression{
 			Left:     expr,
-			Operator: operator,
+			Operator: operator.Value,
 			Right:    right,
-			Position: p.previous().Position,
+			Position: operator.Position,
+			Location: location(operator),
 		}
 	}
 
@@ -100,9 +174,7 @@ func (p *Parser) equality() (ast.Expression, error) {
 	}
 
 	for p.match(lexer.TokenEqual, lexer.TokenNotEqual) {
-		operator := p.previous().Value
-		opPos := p.previous().Position
-
+		operator := p.previous()
 		right, err := p.comparison()
 		if err != nil {
 			return nil, err
@@ -110,9 +182,10 @@ func (p *Parser) equality() (ast.Expression, error) {
 
 		expr = &ast.BinaryExpression{
 			Left:     expr,
-			Operator: operator,
+			Operator: operator.Value,
 			Right:    right,
-			Position: opPos,
+			Position: operator.Position,
+			Location: location(operator),
 		}
 	}
 
@@ -120,29 +193,66 @@ func (p *Parser) equality() (ast.Expression, error) {
 }
 
 func (p *Parser) comparison() (ast.Expression, error) {
-	expr, err := p.term()
+	expr, err := p.rangeExpression()
 	if err != nil {
 		return nil, err
 	}
 
 	for p.match(lexer.TokenLess, lexer.TokenGreater, lexer.TokenLessEqual, lexer.TokenGreaterEqual) {
-		operator := p.previous().Value
-		right, err := p.term()
+		operator := p.previous()
+		right, err := p.rangeExpression()
 		if err != nil {
 			return nil, err
 		}
 
 		expr = &ast.BinaryExpression{
 			Left:     expr,
-			Operator: operator,
+			Operator: operator.Value,
 			Right:    right,
-			Position: p.previous().Position,
+			Position: operator.Position,
+			Location: location(operator),
 		}
 	}
 
 	return expr, nil
 }
 
+// rangeExpression parses start..end, optionally followed by step and the
+// step on the same line as the end
+func (p *Parser) rangeExpression() (ast.Expression, error) {
+	expr, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.match(lexer.TokenDotDot) {
+		return expr, nil
+	}
+	op := p.previous()
+
+	end, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+
+	var step ast.Expression
+	if next := p.peek(); next.Type == lexer.TokenIdentifier && next.Value == "step" && next.Line == p.previous().Line {
+		p.advance()
+		step, err = p.term()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ast.RangeExpression{
+		Start:    expr,
+		End:      end,
+		Step:     step,
+		Position: op.Position,
+		Location: location(op),
+	}, nil
+}
+
 func (p *Parser) term() (ast.Expression, error) {
 	expr, err := p.factor()
 	if err != nil {
@@ -150,7 +260,7 @@ func (p *Parser) term() (ast.Expression, error) {
 	}
 
 	for p.match(lexer.TokenPlus, lexer.TokenMinus) {
-		operator := p.previous().Value
+		operator := p.previous()
 		right, err := p.factor()
 		if err != nil {
 			return nil, err
@@ -158,9 +268,10 @@ func (p *Parser) term() (ast.Expression, error) {
 
 		expr = &ast.BinaryExpression{
 			Left:     expr,
-			Operator: operator,
+			Operator: operator.Value,
 			Right:    right,
-			Position: p.previous().Position,
+			Position: operator.Position,
+			Location: location(operator),
 		}
 	}
 
@@ -168,23 +279,49 @@ func (p *Parser) term() (ast.Expression, error) {
 }
 
 func (p *Parser) factor() (ast.Expression, error) {
-	expr, err := p.unary()
+	expr, err := p.cast()
 	if err != nil {
 		return nil, err
 	}
 
 	for p.match(lexer.TokenMultiply, lexer.TokenDivide, lexer.TokenModulo) {
-		operator := p.previous().Value
-		right, err := p.unary()
+		operator := p.previous()
+		right, err := p.cast()
 		if err != nil {
 			return nil, err
 		}
 
 		expr = &ast.BinaryExpression{
 			Left:     expr,
-			Operator: operator,
+			Operator: operator.Value,
 			Right:    right,
-			Position: p.previous().Position,
+			Position: operator.Position,
+			Location: location(operator),
+		}
+	}
+
+	return expr, nil
+}
+
+// cast parses expr as Type. It binds tighter than the arithmetic operators,
+// so that a * b as int converts only b.
+func (p *Parser) cast() (ast.Expression, error) {
+	expr, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.check(lexer.TokenIdentifier) && p.peek().Value == "as" {
+		as := p.advance()
+		typeName, err := p.typeName("type", false)
+		if err != nil {
+			return nil, err
+		}
+		expr = &ast.CastExpression{
+			Expression: expr,
+			TargetType: typeName,
+			Position:   as.Position,
+			Location:   location(as),
 		}
 	}
 
@@ -193,16 +330,17 @@ func (p *Parser) factor() (ast.Expression, error) {
 
 func (p *Parser) unary() (ast.Expression, error) {
 	if p.match(lexer.TokenMinus, lexer.TokenNot) {
-		operator := p.previous().Value
+		operator := p.previous()
 		right, err := p.unary()
 		if err != nil {
 			return nil, err
 		}
 
 		return &ast.UnaryExpression{
-			Operator: operator,
+			Operator: operator.Value,
 			Right:    right,
-			Position: p.previous().Position,
+			Position: operator.Position,
+			Location: location(operator),
 		}, nil
 	}
 
@@ -221,30 +359,60 @@ func (p *Parser) call() (ast.Expression, error) {
 			if err != nil {
 				return nil, err
 			}
-		} else if p.match(lexer.TokenDot) {
+		} else if p.match(lexer.TokenDot, lexer.TokenQuestionDot) {
+			optional := p.previous().Type == lexer.TokenQuestionDot
 			if !p.check(lexer.TokenIdentifier) {
-				return nil, fmt.Errorf("expected property name after '.' at line %d", p.peek().Line)
+				return nil, errcode.Errorf("BRN0201", "expected property name after '%s' at line %d", p.previous().Value, p.peek().Line)
 			}
 			name := p.advance().Value
 			expr = &ast.GetExpression{
 				Object:   expr,
 				Name:     name,
+				Optional: optional,
 				Position: p.previous().Position,
+				Location: location(p.previous()),
 			}
 		} else if p.match(lexer.TokenLeftBracket) {
-			index, err := p.expression()
-			if err != nil {
-				return nil, err
+			bracket := p.previous()
+			var index ast.Expression
+			if !p.check(lexer.TokenColon) {
+				index, err = p.expression()
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			// a[start:end], where either bound may be left out
+			if p.match(lexer.TokenColon) {
+				var end ast.Expression
+				if !p.check(lexer.TokenRightBracket) {
+					end, err = p.expression()
+					if err != nil {
+						return nil, err
+					}
+				}
+				if !p.match(lexer.TokenRightBracket) {
+					return nil, errcode.Errorf("BRN0201", "expected ']' after slice at line %d", p.peek().Line)
+				}
+				expr = &ast.SliceExpression{
+					Array:    expr,
+					Start:    index,
+					End:      end,
+					Position: bracket.Position,
+					Location: location(bracket),
+				}
+				continue
 			}
 
 			if !p.match(lexer.TokenRightBracket) {
-				return nil, fmt.Errorf("expected ']' after array index at line %d", p.peek().Line)
+				return nil, errcode.Errorf("BRN0201", "expected ']' after array index at line %d", p.peek().Line)
 			}
 
 			expr = &ast.IndexExpression{
 				Array:    expr,
 				Index:    index,
-				Position: p.previous().Position,
+				Position: bracket.Position,
+				Location: location(bracket),
 			}
 		} else {
 			break
@@ -255,6 +423,7 @@ func (p *Parser) call() (ast.Expression, error) {
 }
 
 func (p *Parser) finishCall(callee ast.Expression) (ast.Expression, error) {
+	defer p.leaveClause()()
 	arguments := []ast.Expression{}
 
 	if !p.check(lexer.TokenRightParen) {
@@ -272,24 +441,26 @@ func (p *Parser) finishCall(callee ast.Expression) (ast.Expression, error) {
 	}
 
 	if !p.match(lexer.TokenRightParen) {
-		return nil, fmt.Errorf("expected ')' after arguments at line %d", p.peek().Line)
+		return nil, errcode.Errorf("BRN0201", "expected ')' after arguments at line %d", p.peek().Line)
 	}
 
 	return &ast.CallExpression{
 		Callee:    callee,
 		Arguments: arguments,
-		Position:  p.previous().Position,
+		Position:  callee.Pos(),
+		Location:  callee.Loc(),
 	}, nil
 }
 
 func (p *Parser) primary() (ast.Expression, error) {
-	pos := p.peek().Position
+	start := p.peek()
 
 	if p.match(lexer.TokenTrue) {
 		return &ast.LiteralExpression{
 			Value:    "true",
 			Type:     "bool",
-			Position: pos,
+			Position: start.Position,
+			Location: location(start),
 		}, nil
 	}
 	if p.match(lexer.TokenFalse) {
@@ -297,17 +468,30 @@ func (p *Parser) primary() (ast.Expression, error) {
 			Value:    "false",
 			Type:     "bool",
 			Position: p.previous().Position,
+			Location: location(p.previous()),
+		}, nil
+	}
+	if p.match(lexer.TokenNil) {
+		return &ast.NilExpression{
+			Position: p.previous().Position,
+			Location: location(p.previous()),
 		}, nil
 	}
 	if p.match(lexer.TokenNumber) {
 		value := p.previous().Value
-		if _, err := strconv.ParseFloat(value, 64); err != nil {
-			return nil, fmt.Errorf("invalid number at line %d: %s", p.previous().Line, value)
+		if _, err := lexer.ParseNumber(value); err != nil {
+			return nil, errcode.Errorf("BRN0201", "invalid number at line %d: %s", p.previous().Line, value)
+		}
+		if lexer.IsDecimal(value) {
+			if _, _, err := lexer.ParseDecimal(value); err != nil {
+				return nil, errcode.Errorf("BRN0201", "invalid number at line %d: %s", p.previous().Line, value)
+			}
 		}
 		return &ast.LiteralExpression{
 			Value:    value,
 			Type:     "number",
 			Position: p.previous().Position,
+			Location: location(p.previous()),
 		}, nil
 	}
 	if p.match(lexer.TokenString) {
@@ -315,69 +499,186 @@ func (p *Parser) primary() (ast.Expression, error) {
 			Value:    p.previous().Value,
 			Type:     "string",
 			Position: p.previous().Position,
+			Location: location(p.previous()),
 		}, nil
 	}
 
+	if p.match(lexer.TokenFun) {
+		return p.lambda()
+	}
+	// new Name(...) calls the constructor of a class, as Name(...) does
+	if p.check(lexer.TokenIdentifier) && p.peek().Value == "new" && p.checkNext(lexer.TokenIdentifier) {
+		p.advance()
+		className := p.advance()
+		if !p.match(lexer.TokenLeftParen) {
+			return nil, errcode.Errorf("BRN0201", "expected '(' after class name at line %d", p.peek().Line)
+		}
+		return p.finishCall(&ast.VariableExpression{
+			Name:     className.Value,
+			Position: className.Position,
+			Location: location(className),
+		})
+	}
+	if p.check(lexer.TokenIdentifier) && p.peek().Value == "this" {
+		p.advance()
+		return &ast.ThisExpression{
+			Position: p.previous().Position,
+			Location: location(p.previous()),
+		}, nil
+	}
+	if p.check(lexer.TokenIdentifier) && p.checkNext(lexer.TokenLess) && !p.inClause {
+		if literal, ok, err := p.genericStructLiteral(); ok {
+			return literal, err
+		}
+	}
+	// Name{field: value} is a struct literal of the type Name, except
+	// right before the body of a match or for-in loop, which the brace
+	// starts
+	if p.check(lexer.TokenIdentifier) && p.checkNext(lexer.TokenLeftBrace) && !p.inClause &&
+		(p.checkAt(2, lexer.TokenRightBrace) || p.checkAt(2, lexer.TokenIdentifier) && p.checkAt(3, lexer.TokenColon)) {
+		name := p.advance()
+		p.advance()
+		return p.structLiteral(name, name.Value, true)
+	}
 	if p.match(lexer.TokenIdentifier) {
 		return &ast.VariableExpression{
 			Name:     p.previous().Value,
 			Position: p.previous().Position,
+			Location: location(p.previous()),
 		}, nil
 	}
 	if p.match(lexer.TokenLeftParen) {
+		restore := p.leaveClause()
 		expr, err := p.expression()
+		restore()
 		if err != nil {
 			return nil, err
 		}
 		if !p.match(lexer.TokenRightParen) {
-			return nil, fmt.Errorf("expected ')' after expression at line %d", p.peek().Line)
+			return nil, errcode.Errorf("BRN0201", "expected ')' after expression at line %d", p.peek().Line)
 		}
 		return expr, nil
 	}
+	// A brace starts a struct literal when a field name or the closing
+	// brace follows, and a map literal otherwise
+	if p.check(lexer.TokenLeftBrace) && !p.checkNext(lexer.TokenIdentifier) && !p.checkNext(lexer.TokenRightBrace) {
+		return p.mapLiteral()
+	}
 	if p.match(lexer.TokenLeftBrace) {
 		var typeName string
 		if p.currentFunc != nil && p.currentFunc.ReturnType != "" {
 			typeName = p.currentFunc.ReturnType
 		}
+		return p.structLiteral(p.previous(), typeName, false)
+	}
+	if p.match(lexer.TokenLeftBracket) {
+		return p.arrayLiteral()
+	}
 
-		fields := make(map[string]ast.Expression)
-		if !p.check(lexer.TokenRightBrace) {
-			for {
-				if !p.check(lexer.TokenIdentifier) {
-					return nil, fmt.Errorf("expected field name at line %d", p.peek().Line)
-				}
-				name := p.advance().Value
-				if !p.match(lexer.TokenColon) {
-					return nil, fmt.Errorf("expected ':' after field name at line %d", p.peek().Line)
-				}
-				value, err := p.expression()
-				if err != nil {
-					return nil, err
-				}
-				fields[name] = value
-				if !p.match(lexer.TokenComma) {
-					break
-				}
+	return nil, errcode.Errorf("BRN0201", "expected expression at line %d", p.peek().Line)
+}
+
+// genericStructLiteral parses a struct literal of a generic type with its
+// type arguments, as in Box<int>{value: 3}, and reports whether there was
+// one. When there is not, nothing is consumed, and Name < ... is parsed as
+// a comparison.
+func (p *Parser) genericStructLiteral() (ast.Expression, bool, error) {
+	start := p.current
+	name := p.peek()
+	typeName, err := p.plainTypeName("type", false)
+	if err == nil && p.check(lexer.TokenLeftBrace) &&
+		(p.checkNext(lexer.TokenRightBrace) || p.checkNext(lexer.TokenIdentifier) && p.checkAt(2, lexer.TokenColon)) {
+		p.advance()
+		literal, err := p.structLiteral(name, typeName, true)
+		return literal, true, err
+	}
+	p.current = start
+	return nil, false, nil
+}
+
+// structLiteral parses the fields of a struct literal of the type typeName
+// after its opening brace. named tells whether the type name was written,
+// and start is the first token of the literal.
+func (p *Parser) structLiteral(start lexer.Token, typeName string, named bool) (ast.Expression, error) {
+	fields := make(map[string]ast.Expression)
+	if !p.check(lexer.TokenRightBrace) {
+		for {
+			if !p.check(lexer.TokenIdentifier) {
+				return nil, errcode.Errorf("BRN0201", "expected field name at line %d", p.peek().Line)
+			}
+			name := p.advance().Value
+			if !p.match(lexer.TokenColon) {
+				return nil, errcode.Errorf("BRN0201", "expected ':' after field name at line %d", p.peek().Line)
+			}
+			value, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			fields[name] = value
+			if !p.match(lexer.TokenComma) {
+				break
 			}
 		}
-		if !p.match(lexer.TokenRightBrace) {
-			return nil, fmt.Errorf("expected '}' after struct literal at line %d", p.peek().Line)
-		}
+	}
+	if !p.match(lexer.TokenRightBrace) {
+		return nil, errcode.Errorf("BRN0201", "expected '}' after struct literal at line %d", p.peek().Line)
+	}
 
-		return &ast.StructLiteralExpression{
-			Type:     typeName,
-			Fields:   fields,
-			Position: p.previous().Position,
-		}, nil
+	return &ast.StructLiteralExpression{
+		Type:     typeName,
+		Named:    named,
+		Fields:   fields,
+		Position: start.Position,
+		Location: location(start),
+	}, nil
+}
+
+// lambda parses a function expression such as fun(x: int): int { ... }
+// after its fun keyword
+func (p *Parser) lambda() (ast.Expression, error) {
+	start := p.previous()
+
+	if !p.match(lexer.TokenLeftParen) {
+		return nil, errcode.Errorf("BRN0201", "expected '(' after fun at line %d", p.peek().Line)
 	}
-	if p.match(lexer.TokenLeftBracket) {
-		return p.arrayLiteral()
+
+	parameters, err := p.parameters()
+	if err != nil {
+		return nil, err
+	}
+
+	returnType, err := p.returnType()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.match(lexer.TokenLeftBrace) {
+		return nil, errcode.Errorf("BRN0201", "expected '{' for lambda body at line %d", p.peek().Line)
+	}
+
+	// Struct literals in the body take their type from the return type of
+	// the lambda, as in a function
+	prevFunc := p.currentFunc
+	p.currentFunc = &ast.FunctionDeclaration{ReturnType: returnType}
+
+	body, err := p.block()
+	p.currentFunc = prevFunc
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, fmt.Errorf("expected expression at line %d", p.peek().Line)
+	return &ast.LambdaExpression{
+		Parameters: parameters,
+		ReturnType: returnType,
+		Body:       body,
+		Position:   start.Position,
+		Location:   location(start),
+	}, nil
 }
 
 func (p *Parser) arrayLiteral() (ast.Expression, error) {
+	defer p.leaveClause()()
+	bracket := p.previous()
 	elements := []ast.Expression{}
 
 	if !p.check(lexer.TokenRightBracket) {
@@ -395,11 +696,49 @@ func (p *Parser) arrayLiteral() (ast.Expression, error) {
 	}
 
 	if !p.match(lexer.TokenRightBracket) {
-		return nil, fmt.Errorf("expected ']' after array elements at line %d", p.peek().Line)
+		return nil, errcode.Errorf("BRN0201", "expected ']' after array elements at line %d", p.peek().Line)
 	}
 
 	return &ast.ArrayLiteralExpression{
 		Elements: elements,
-		Position: p.previous().Position,
+		Position: bracket.Position,
+		Location: location(bracket),
 	}, nil
 }
+
+// mapLiteral parses {key: value, ...}, or {:} for an empty map
+func (p *Parser) mapLiteral() (ast.Expression, error) {
+	brace := p.advance()
+	literal := &ast.MapLiteralExpression{Position: brace.Position, Location: location(brace)}
+
+	if p.match(lexer.TokenColon) {
+		if !p.match(lexer.TokenRightBrace) {
+			return nil, errcode.Errorf("BRN0201", "expected '}' after '{:' at line %d", p.peek().Line)
+		}
+		return literal, nil
+	}
+
+	for {
+		key, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		if !p.match(lexer.TokenColon) {
+			return nil, errcode.Errorf("BRN0201", "expected ':' after map key at line %d", p.peek().Line)
+		}
+		value, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		literal.Entries = append(literal.Entries, ast.MapEntry{Key: key, Value: value})
+
+		if !p.match(lexer.TokenComma) || p.check(lexer.TokenRightBrace) {
+			break
+		}
+	}
+
+	if !p.match(lexer.TokenRightBrace) {
+		return nil, errcode.Errorf("BRN0201", "expected '}' after map literal at line %d", p.peek().Line)
+	}
+	return literal, nil
+}
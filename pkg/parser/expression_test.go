@@ -0,0 +1,33 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/burnlang/burn/pkg/ast"
+)
+
+func TestGenericStructLiteral(t *testing.T) {
+	for source, want := range map[string]string{
+		"Box<int>{value: 3}":                "Box<int>",
+		"Pair<string, [int]>{first: \"a\"}": "Pair<string, [int]>",
+		"Box<Box<int>>{value: Box<int>{}}":  "Box<Box<int>>",
+		"Box<map<string, int>>{value: nil}": "Box<map<string, int>>",
+	} {
+		literal, ok := parseExpression(t, source).(*ast.StructLiteralExpression)
+		if !ok {
+			t.Errorf("%s: not a struct literal", source)
+			continue
+		}
+		if literal.Type != want || !literal.Named {
+			t.Errorf("%s: type %q, want %q", source, literal.Type, want)
+		}
+	}
+}
+
+func TestLessThanIsNotGenericStructLiteral(t *testing.T) {
+	for _, source := range []string{"a < b", "a < b > c", "a < b == c"} {
+		if _, ok := parseExpression(t, source).(*ast.BinaryExpression); !ok {
+			t.Errorf("%s: not a binary expression", source)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/lexer"
+)
+
+// Edit replaces the bytes from Start to End of a source with Text
+type Edit struct {
+	Start int
+	End   int
+	Text  string
+}
+
+// Document is a source file kept parsed while it is edited, for editor
+// tooling. An edit re-lexes the source from shortly before it only until
+// the tokens line up with the old ones again, and re-parses only the
+// top-level declarations from shortly before it until one starts at a
+// token that was not re-lexed. The other tokens and declarations are
+// reused, moved to their new positions. Comments are not kept.
+//
+// The declarations reused are changed in place, so a Program returned
+// before an Update must not be used after it.
+type Document struct {
+	source string
+	tokens []lexer.Token
+	items  []item
+	err    error
+}
+
+// item is what ParseAll makes of one top-level declaration: the
+// declaration, or the ErrorNode that took its place, and its syntax
+// errors. Its parse depends on the tokens from start to reach.
+type item struct {
+	start int
+	reach int
+	decl  ast.Declaration
+	errs  []*Error
+}
+
+// parseItem parses the item at the current token of p
+func parseItem(p *Parser) item {
+	start := p.current
+	p.furthest = start
+	decl, errs := p.item()
+	return item{start: start, reach: max(p.furthest, p.current), decl: decl, errs: errs}
+}
+
+// NewDocument lexes and parses source
+func NewDocument(source string) *Document {
+	d := &Document{}
+	d.reset(source)
+	return d
+}
+
+// Source returns the current source of the document
+func (d *Document) Source() string {
+	return d.source
+}
+
+// Tokens returns the tokens of the source, or nil after a lexical error
+func (d *Document) Tokens() []lexer.Token {
+	return d.tokens
+}
+
+// Err returns the lexical error that stopped the source from being lexed,
+// or nil. A document with a lexical error has no declarations.
+func (d *Document) Err() error {
+	return d.err
+}
+
+// Program returns the declarations, like ParseAll
+func (d *Document) Program() *ast.Program {
+	program := &ast.Program{Declarations: []ast.Declaration{}}
+	for _, it := range d.items {
+		program.Declarations = append(program.Declarations, it.decl)
+	}
+	return program
+}
+
+// Errors returns the syntax errors of the source, like ParseAll
+func (d *Document) Errors() []*Error {
+	var errs []*Error
+	for _, it := range d.items {
+		errs = append(errs, it.errs...)
+	}
+	return errs
+}
+
+// reset lexes and parses source from scratch
+func (d *Document) reset(source string) {
+	d.source, d.tokens, d.items, d.err = source, nil, nil, nil
+
+	tokens, err := lexer.New(source).Tokenize()
+	if err != nil {
+		d.err = err
+		return
+	}
+	d.tokens = tokens
+
+	p := &Parser{tokens: tokens}
+	for !p.isAtEnd() {
+		d.items = append(d.items, parseItem(p))
+	}
+}
+
+// Update applies an edit to the source and brings the tokens, declarations
+// and errors up to date. It fails only if the edit is out of range.
+func (d *Document) Update(edit Edit) error {
+	if edit.Start < 0 || edit.End < edit.Start || edit.End > len(d.source) {
+		return fmt.Errorf("edit of bytes %d to %d is out of range for a document of %d bytes",
+			edit.Start, edit.End, len(d.source))
+	}
+	source := d.source[:edit.Start] + edit.Text + d.source[edit.End:]
+	if d.err != nil {
+		d.reset(source)
+		return nil
+	}
+
+	// Parse again from the first declaration whose parse looked at a token
+	// the edit may have changed: the last one starting before the edit, or
+	// any after it
+	changed := max(sort.Search(len(d.tokens), func(j int) bool {
+		return d.tokens[j].Position >= edit.Start
+	})-1, 0)
+	first := len(d.items)
+	for k, it := range d.items {
+		if it.reach >= changed {
+			first = k
+			break
+		}
+	}
+	startToken := len(d.tokens) - 1
+	if first < len(d.items) {
+		startToken = d.items[first].start
+	}
+	if first == 0 {
+		startToken = 0
+	}
+
+	tokens, resumed, sh, err := d.relex(source, edit, startToken)
+	if err != nil {
+		d.source, d.tokens, d.items, d.err = source, nil, nil, err
+		return nil
+	}
+
+	items := append([]item(nil), d.items[:first]...)
+	p := &Parser{tokens: tokens, current: startToken}
+	for !p.isAtEnd() {
+		if resumed >= 0 && p.current >= resumed {
+			oldToken := p.current - resumed + sh.token
+			if k, ok := d.itemAt(oldToken); ok {
+				items = append(items, d.reuse(p, sh, k, resumed-sh.token)...)
+				break
+			}
+		}
+		items = append(items, parseItem(p))
+	}
+
+	d.source, d.tokens, d.items = source, tokens, items
+	return nil
+}
+
+// relex lexes source, the edited document, from the token at index start
+// until a token begins where an old one began after the edit. It returns
+// the new tokens, the index among them from which the old tokens were
+// taken over, or -1 if none were, and how those tokens moved.
+func (d *Document) relex(source string, edit Edit, start int) ([]lexer.Token, int, shift, error) {
+	delta := len(edit.Text) - (edit.End - edit.Start)
+	editEnd := edit.Start + len(edit.Text)
+
+	// Whatever comes before the first token may have been edited too
+	lex := lexer.New(source)
+	if start > 0 {
+		from := d.tokens[start]
+		lex = lexer.Resume(source, from.Position, from.Line, from.Col)
+	}
+	tokens := make([]lexer.Token, start, len(d.tokens)+16)
+	copy(tokens, d.tokens[:start])
+	for {
+		tok, err := lex.Next()
+		if err != nil {
+			return nil, -1, shift{}, err
+		}
+		if tok.Type == lexer.TokenEOF {
+			return append(tokens, tok), -1, shift{}, nil
+		}
+		if tok.Position >= editEnd {
+			if j, ok := d.tokenAt(tok.Position-delta, start); ok {
+				old := d.tokens[j]
+				sh := shift{
+					token: j,
+					bytes: delta,
+					lines: tok.Line - old.Line,
+					line:  old.Line,
+					cols:  tok.Col - old.Col,
+				}
+				resumed := len(tokens)
+				for _, t := range d.tokens[j:] {
+					tokens = append(tokens, sh.apply(t))
+				}
+				return tokens, resumed, sh, nil
+			}
+		}
+		tokens = append(tokens, tok)
+	}
+}
+
+// reuse moves the old items from index k on, whose first tokens move by
+// tokens places. Items with errors are parsed again with p instead, since
+// the messages of syntax errors name their line.
+func (d *Document) reuse(p *Parser, sh shift, k, tokens int) []item {
+	items := make([]item, 0, len(d.items)-k)
+	seen := make(map[uintptr]bool)
+	for _, it := range d.items[k:] {
+		if len(it.errs) > 0 {
+			p.current = it.start + tokens
+			items = append(items, parseItem(p))
+			continue
+		}
+		it.start += tokens
+		it.reach += tokens
+		sh.node(reflect.ValueOf(it.decl), seen)
+		items = append(items, it)
+	}
+	return items
+}
+
+// tokenAt returns the index of the old token at or after from that begins
+// at the byte offset pos
+func (d *Document) tokenAt(pos, from int) (int, bool) {
+	j := from + sort.Search(len(d.tokens)-from, func(k int) bool {
+		return d.tokens[from+k].Position >= pos
+	})
+	return j, j < len(d.tokens) && d.tokens[j].Position == pos
+}
+
+// itemAt returns the index of the old item that begins at the token index
+// start
+func (d *Document) itemAt(start int) (int, bool) {
+	k := sort.Search(len(d.items), func(k int) bool {
+		return d.items[k].start >= start
+	})
+	return k, k < len(d.items) && d.items[k].start == start
+}
+
+// shift is how the tokens and nodes after an edit move: by bytes and
+// lines, and by cols columns for those on the old line where the tokens
+// taken over begin
+type shift struct {
+	token int
+	bytes int
+	lines int
+	line  int
+	cols  int
+}
+
+func (s shift) apply(tok lexer.Token) lexer.Token {
+	tok.Position += s.bytes
+	if tok.Line == s.line {
+		tok.Col += s.cols
+	}
+	tok.Line += s.lines
+	return tok
+}
+
+var locationType = reflect.TypeOf(ast.Location{})
+
+// nodeFields lists, for a struct type of the AST, the indexes of its
+// Position and Location fields and of the fields that may hold nodes
+type nodeFields struct {
+	position int
+	location int
+	children []int
+}
+
+var fieldsByType sync.Map
+
+// fieldsOf returns the nodeFields of the struct type t, working them out
+// on first use
+func fieldsOf(t reflect.Type) *nodeFields {
+	if f, ok := fieldsByType.Load(t); ok {
+		return f.(*nodeFields)
+	}
+	f := &nodeFields{position: -1, location: -1}
+	for k := 0; k < t.NumField(); k++ {
+		field := t.Field(k)
+		switch {
+		case !field.IsExported():
+		case field.Name == "Position" && field.Type.Kind() == reflect.Int:
+			f.position = k
+		case field.Type == locationType:
+			f.location = k
+		default:
+			switch field.Type.Kind() {
+			case reflect.Interface, reflect.Ptr, reflect.Slice, reflect.Map, reflect.Struct:
+				f.children = append(f.children, k)
+			}
+		}
+	}
+	fieldsByType.Store(t, f)
+	return f
+}
+
+// node moves the Position and Location fields of every node reachable
+// from v
+func (s shift) node(v reflect.Value, seen map[uintptr]bool) {
+	switch v.Kind() {
+	case reflect.Interface:
+		if !v.IsNil() {
+			s.node(v.Elem(), seen)
+		}
+	case reflect.Ptr:
+		if v.IsNil() || seen[v.Pointer()] {
+			return
+		}
+		seen[v.Pointer()] = true
+		s.node(v.Elem(), seen)
+	case reflect.Slice:
+		for k := 0; k < v.Len(); k++ {
+			s.node(v.Index(k), seen)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			s.node(iter.Value(), seen)
+		}
+	case reflect.Struct:
+		if !v.CanSet() {
+			return
+		}
+		f := fieldsOf(v.Type())
+		if f.position >= 0 {
+			pos := v.Field(f.position)
+			pos.SetInt(pos.Int() + int64(s.bytes))
+		}
+		if f.location >= 0 {
+			loc := v.Field(f.location).Addr().Interface().(*ast.Location)
+			if loc.IsValid() {
+				if loc.Line == s.line {
+					loc.Column += s.cols
+				}
+				loc.Line += s.lines
+			}
+		}
+		for _, k := range f.children {
+			s.node(v.Field(k), seen)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/lexer"
+	"github.com/burnlang/burn/pkg/parser"
+)
+
+// parseExpression parses source, a single expression statement
+func parseExpression(t *testing.T, source string) ast.Expression {
+	t.Helper()
+	program := parse(t, source)
+	stmt, ok := program.Declarations[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("%q is not an expression statement", source)
+	}
+	return stmt.Expression
+}
+
+func parse(t *testing.T, source string) *ast.Program {
+	t.Helper()
+	tokens, err := lexer.New(source).Tokenize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	program, errs := parser.New(tokens).ParseAll()
+	if len(errs) > 0 {
+		t.Fatal(errs[0])
+	}
+	return program
+}
+
+func TestExpressionLocations(t *testing.T) {
+	tests := []struct {
+		source string
+		column int
+	}{
+		{"a || bb", 3},
+		{"a && bb", 3},
+		{"a == bb", 3},
+		{"a < bb", 3},
+		{"a + bb", 3},
+		{"a * bb", 3},
+		{"a ?? bb", 3},
+		{"a + b * cc", 3},
+		{"-aa", 1},
+		{"!aa", 1},
+		{"ff(1, 2)", 1},
+		{"a.ff(1)", 3},
+		{"aa[1]", 3},
+		{"[1, 2]", 1},
+	}
+	for _, test := range tests {
+		expr := parseExpression(t, test.source)
+		if got := expr.Loc(); got != (ast.Location{Line: 1, Column: test.column}) {
+			t.Errorf("%q (%T) at %d:%d, want 1:%d", test.source, expr, got.Line, got.Column, test.column)
+		}
+	}
+}
+
+func TestStatementLocations(t *testing.T) {
+	program := parse(t, `fun f(): int {
+    var total = 2
+    if (total > 1) { return total }
+    while (total < 9) { total = total + 1 }
+    for (var k = 0; k < 3; k = k + 1) {}
+    return total
+}`)
+	body := program.Declarations[0].(*ast.FunctionDeclaration).Body
+	want := []ast.Location{{Line: 2, Column: 9}, {Line: 3, Column: 5}, {Line: 4, Column: 5}, {Line: 5, Column: 5}, {Line: 6, Column: 5}}
+	for k, stmt := range body {
+		if got := stmt.Loc(); got != want[k] {
+			t.Errorf("%T at %d:%d, want %d:%d", stmt, got.Line, got.Column, want[k].Line, want[k].Column)
+		}
+	}
+}
@@ -9,18 +9,82 @@ type Parser struct {
 	tokens      []lexer.Token
 	current     int
 	currentFunc *ast.FunctionDeclaration
+
+	// inClause is set while parsing the value of a match or the iterable
+	// of a for-in loop without parentheses, which a brace follows: a name
+	// followed by a brace there is not a struct literal
+	inClause bool
+
+	comments []*ast.CommentGroup
+	docs     map[int]*ast.CommentGroup
+
+	// furthest is the index of the furthest token looked at, which tells
+	// Document how far an edit can change the parse of a declaration
+	furthest int
+
+	// recovering is set by ParseAll: a statement of a block that fails to
+	// parse is replaced by an ErrorNode, its error added to errors, and
+	// parsing goes on with the next statement
+	recovering bool
+	errors     []*Error
 }
 
+// New creates a parser for tokens. Comment tokens are taken out of the
+// stream and kept as the Comments of the program and the Doc of the
+// declarations they document.
 func New(tokens []lexer.Token) *Parser {
-	return &Parser{
-		tokens:  tokens,
+	p := &Parser{
 		current: 0,
+		docs:    make(map[int]*ast.CommentGroup),
+	}
+	p.tokens = p.collectComments(tokens)
+	return p
+}
+
+// collectComments groups the comment tokens of tokens and returns the
+// other tokens. A group documents the token that follows it on the next
+// line, unless it started at the end of a line of code.
+func (p *Parser) collectComments(tokens []lexer.Token) []lexer.Token {
+	code := make([]lexer.Token, 0, len(tokens))
+	var group *ast.CommentGroup
+	trailing := false
+	lastLine, codeLine := 0, 0
+
+	for _, tok := range tokens {
+		if tok.Type != lexer.TokenComment {
+			if group != nil && !trailing && lastLine == tok.Line-1 {
+				p.docs[len(code)] = group
+			}
+			group = nil
+			code = append(code, tok)
+			codeLine = tok.Line
+			continue
+		}
+
+		if group == nil || trailing || tok.Line != lastLine+1 {
+			group = &ast.CommentGroup{}
+			p.comments = append(p.comments, group)
+			trailing = tok.Line == codeLine
+		}
+		group.List = append(group.List, &ast.Comment{
+			Text:     tok.Value,
+			Position: tok.Position,
+			Location: location(tok),
+		})
+		lastLine = tok.Line
 	}
+	return code
+}
+
+// doc returns the comment group documenting the keyword just matched
+func (p *Parser) doc() *ast.CommentGroup {
+	return p.docs[p.current-1]
 }
 
 func (p *Parser) Parse() (*ast.Program, error) {
 	program := &ast.Program{
 		Declarations: []ast.Declaration{},
+		Comments:     p.comments,
 	}
 
 	for !p.isAtEnd() {
@@ -34,6 +98,141 @@ func (p *Parser) Parse() (*ast.Program, error) {
 	return program, nil
 }
 
+// Error is a syntax error found by ParseAll, located at a byte offset and
+// at a line and column of the source
+type Error struct {
+	Err      error
+	Position int
+	Location ast.Location
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ParseAll parses like Parse, but after a syntax error it skips to the next
+// statement of the block, or the next top-level declaration, and goes on.
+// It returns the declarations with an ErrorNode in place of each statement
+// or declaration that failed, together with every error found.
+func (p *Parser) ParseAll() (*ast.Program, []*Error) {
+	program := &ast.Program{
+		Declarations: []ast.Declaration{},
+		Comments:     p.comments,
+	}
+
+	var errs []*Error
+	for !p.isAtEnd() {
+		declaration, itemErrs := p.item()
+		errs = append(errs, itemErrs...)
+		program.Declarations = append(program.Declarations, declaration)
+	}
+
+	return program, errs
+}
+
+// item parses one top-level declaration for ParseAll, with the errors of
+// the statements in it that failed. After a syntax error outside any block
+// it moves on to the next declaration, and the declaration is an ErrorNode.
+func (p *Parser) item() (ast.Declaration, []*Error) {
+	start := p.current
+	p.recovering, p.errors = true, nil
+	defer func() { p.recovering, p.errors = false, nil }()
+
+	declaration, err := p.declaration()
+	if err != nil {
+		node := p.fail(start, err)
+		p.currentFunc = nil
+		p.synchronize(start)
+		return node, p.errors
+	}
+	return declaration, p.errors
+}
+
+// fail records the syntax error of the statement or declaration that
+// started at token start, and returns the ErrorNode taking its place. An
+// error already recorded at the same place, such as a missing } reported
+// by every block around it, is recorded once.
+func (p *Parser) fail(start int, err error) *ast.ErrorNode {
+	syntaxErr := &Error{Err: err, Position: p.Position(), Location: p.Location()}
+	if n := len(p.errors); n == 0 || p.errors[n-1].Position != syntaxErr.Position {
+		p.errors = append(p.errors, syntaxErr)
+	}
+	return &ast.ErrorNode{
+		Message:  err.Error(),
+		Position: p.tokens[start].Position,
+		Location: location(p.tokens[start]),
+	}
+}
+
+// synchronize moves past the declaration that started at token start to
+// the next keyword that begins a declaration outside any braces
+func (p *Parser) synchronize(start int) {
+	p.current = start + 1
+	depth := 0
+	for !p.isAtEnd() {
+		switch p.peek().Type {
+		case lexer.TokenLeftBrace:
+			depth++
+		case lexer.TokenRightBrace:
+			depth--
+		case lexer.TokenImport, lexer.TokenClass, lexer.TokenInterface, lexer.TokenFun,
+			lexer.TokenVar, lexer.TokenConst, lexer.TokenTypeKeyword, lexer.TokenPrivate:
+			if depth <= 0 {
+				return
+			}
+		}
+		p.advance()
+	}
+}
+
+// synchronizeStatement moves past the statement that started at token
+// start: to the first token outside any brackets that begins a new line or
+// follows a semicolon, or to the } that ends the block. A keyword that only
+// begins statements, at the start of a line, ends the statement even
+// within brackets, which may be left open by the error.
+func (p *Parser) synchronizeStatement(start int) {
+	p.current = start + 1
+	depth := 0
+	for !p.isAtEnd() {
+		tok := p.peek()
+		if tok.Line > p.previous().Line && (depth == 0 || statementKeywords[tok.Type]) {
+			return
+		}
+		switch tok.Type {
+		case lexer.TokenLeftBrace, lexer.TokenLeftParen, lexer.TokenLeftBracket:
+			depth++
+		case lexer.TokenRightBrace:
+			if depth == 0 {
+				return
+			}
+			depth--
+		case lexer.TokenRightParen, lexer.TokenRightBracket:
+			depth = max(depth-1, 0)
+		case lexer.TokenSemicolon:
+			if depth == 0 {
+				p.advance()
+				return
+			}
+		}
+		p.advance()
+	}
+}
+
+var statementKeywords = map[lexer.TokenType]bool{
+	lexer.TokenVar:      true,
+	lexer.TokenConst:    true,
+	lexer.TokenIf:       true,
+	lexer.TokenReturn:   true,
+	lexer.TokenWhile:    true,
+	lexer.TokenFor:      true,
+	lexer.TokenBreak:    true,
+	lexer.TokenContinue: true,
+}
+
 func (p *Parser) Position() int {
 	if p.current < len(p.tokens) {
 		return p.tokens[p.current].Position
@@ -43,6 +242,16 @@ func (p *Parser) Position() int {
 	return 0
 }
 
+// Location is the line and column of Position
+func (p *Parser) Location() ast.Location {
+	if p.current < len(p.tokens) {
+		return location(p.tokens[p.current])
+	} else if len(p.tokens) > 0 {
+		return location(p.tokens[len(p.tokens)-1])
+	}
+	return ast.Location{}
+}
+
 func (p *Parser) match(types ...lexer.TokenType) bool {
 	for _, tokenType := range types {
 		if p.check(tokenType) {
@@ -60,9 +269,47 @@ func (p *Parser) check(tokenType lexer.TokenType) bool {
 	return p.peek().Type == tokenType
 }
 
+// checkNext reports whether the token after the current one has the type
+// tokenType
+func (p *Parser) checkNext(tokenType lexer.TokenType) bool {
+	if p.isAtEnd() {
+		return false
+	}
+	p.furthest = max(p.furthest, p.current+1)
+	return p.tokens[p.current+1].Type == tokenType
+}
+
+// checkAt reports whether the token n after the current one has the type
+// tokenType
+func (p *Parser) checkAt(n int, tokenType lexer.TokenType) bool {
+	if p.current+n >= len(p.tokens) {
+		return false
+	}
+	p.furthest = max(p.furthest, p.current+n)
+	return p.tokens[p.current+n].Type == tokenType
+}
+
+// clauseExpression parses the value of a match or the iterable of a for-in
+// loop without parentheses
+func (p *Parser) clauseExpression() (ast.Expression, error) {
+	saved := p.inClause
+	p.inClause = true
+	defer func() { p.inClause = saved }()
+	return p.expression()
+}
+
+// leaveClause clears inClause for the part of a clause in brackets of its
+// own, until the function it returns is called
+func (p *Parser) leaveClause() func() {
+	saved := p.inClause
+	p.inClause = false
+	return func() { p.inClause = saved }
+}
+
 func (p *Parser) advance() lexer.Token {
 	if !p.isAtEnd() {
 		p.current++
+		p.furthest = max(p.furthest, p.current)
 	}
 	return p.previous()
 }
@@ -78,3 +325,8 @@ func (p *Parser) peek() lexer.Token {
 func (p *Parser) previous() lexer.Token {
 	return p.tokens[p.current-1]
 }
+
+// location returns where tok starts, for the node built from it
+func location(tok lexer.Token) ast.Location {
+	return ast.Location{Line: tok.Line, Column: tok.Col}
+}
@@ -1,9 +1,8 @@
 package parser
 
 import (
-	"fmt"
-
 	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
 	"github.com/burnlang/burn/pkg/lexer"
 )
 
@@ -20,19 +19,79 @@ func (p *Parser) statement() (ast.Declaration, error) {
 	if p.match(lexer.TokenReturn) {
 		return p.returnStatement()
 	}
+	if p.match(lexer.TokenMatch) {
+		return p.matchStatement()
+	}
+	if p.check(lexer.TokenIdentifier) && p.checkNext(lexer.TokenColon) {
+		return p.labeledLoop()
+	}
+	if p.match(lexer.TokenBreak) {
+		keyword := p.previous()
+		label := p.loopLabel(keyword)
+		if p.match(lexer.TokenSemicolon) {
+		}
+		return &ast.BreakStatement{Label: label, Position: keyword.Position, Location: location(keyword)}, nil
+	}
+	if p.match(lexer.TokenContinue) {
+		keyword := p.previous()
+		label := p.loopLabel(keyword)
+		if p.match(lexer.TokenSemicolon) {
+		}
+		return &ast.ContinueStatement{Label: label, Position: keyword.Position, Location: location(keyword)}, nil
+	}
 	if p.match(lexer.TokenLeftBrace) {
+		brace := p.previous()
 		statements, err := p.block()
 		if err != nil {
 			return nil, err
 		}
-		return &ast.BlockStatement{Statements: statements}, nil
+		return &ast.BlockStatement{Statements: statements, Position: brace.Position, Location: location(brace)}, nil
 	}
 
 	return p.expressionStatement()
 }
 
+// labeledLoop parses a loop named by a label, as in outer: for ...
+func (p *Parser) labeledLoop() (ast.Declaration, error) {
+	label := p.advance().Value
+	p.advance()
+
+	var loop ast.Declaration
+	var err error
+	switch {
+	case p.match(lexer.TokenWhile):
+		loop, err = p.whileStatement()
+	case p.match(lexer.TokenFor):
+		loop, err = p.forStatement()
+	default:
+		return nil, errcode.Errorf("BRN0201", "expected 'for' or 'while' after label %s at line %d", label, p.peek().Line)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch l := loop.(type) {
+	case *ast.WhileStatement:
+		l.Label = label
+	case *ast.ForStatement:
+		l.Label = label
+	case *ast.ForInStatement:
+		l.Label = label
+	}
+	return loop, nil
+}
+
+// loopLabel parses the label of the loop a break or continue leaves, which
+// must be on the same line as its keyword
+func (p *Parser) loopLabel(keyword lexer.Token) string {
+	if p.check(lexer.TokenIdentifier) && p.peek().Line == keyword.Line {
+		return p.advance().Value
+	}
+	return ""
+}
+
 func (p *Parser) ifStatement() (ast.Declaration, error) {
-	pos := p.peek().Position
+	start := p.previous()
 
 	if !p.match(lexer.TokenLeftParen) {
 		p.current--
@@ -47,7 +106,7 @@ func (p *Parser) ifStatement() (ast.Declaration, error) {
 	}
 
 	if !p.match(lexer.TokenLeftBrace) {
-		return nil, fmt.Errorf("expected '{' after if condition at line %d", p.peek().Line)
+		return nil, errcode.Errorf("BRN0201", "expected '{' after if condition at line %d", p.peek().Line)
 	}
 
 	thenBranch, err := p.block()
@@ -69,7 +128,7 @@ func (p *Parser) ifStatement() (ast.Declaration, error) {
 				return nil, err
 			}
 		} else {
-			return nil, fmt.Errorf("expected '{' or 'if' after 'else' at line %d", p.peek().Line)
+			return nil, errcode.Errorf("BRN0201", "expected '{' or 'if' after 'else' at line %d", p.peek().Line)
 		}
 	}
 
@@ -77,12 +136,13 @@ func (p *Parser) ifStatement() (ast.Declaration, error) {
 		Condition:  condition,
 		ThenBranch: thenBranch,
 		ElseBranch: elseBranch,
-		Position:   pos,
+		Position:   start.Position,
+		Location:   location(start),
 	}, nil
 }
 
 func (p *Parser) whileStatement() (ast.Declaration, error) {
-	pos := p.peek().Position
+	start := p.previous()
 
 	if !p.match(lexer.TokenLeftParen) {
 		p.current--
@@ -97,7 +157,7 @@ func (p *Parser) whileStatement() (ast.Declaration, error) {
 	}
 
 	if !p.match(lexer.TokenLeftBrace) {
-		return nil, fmt.Errorf("expected '{' after while condition at line %d", p.peek().Line)
+		return nil, errcode.Errorf("BRN0201", "expected '{' after while condition at line %d", p.peek().Line)
 	}
 
 	body, err := p.block()
@@ -108,14 +168,19 @@ func (p *Parser) whileStatement() (ast.Declaration, error) {
 	return &ast.WhileStatement{
 		Condition: condition,
 		Body:      body,
-		Position:  pos,
+		Position:  start.Position,
+		Location:  location(start),
 	}, nil
 }
 
 func (p *Parser) forStatement() (ast.Declaration, error) {
-	pos := p.peek().Position
+	start := p.previous()
 
-	if !p.match(lexer.TokenLeftParen) {
+	paren := p.match(lexer.TokenLeftParen)
+	if p.check(lexer.TokenIdentifier) && p.checkNext(lexer.TokenIn) {
+		return p.forInStatement(start, paren)
+	}
+	if !paren {
 		p.current--
 	}
 
@@ -158,7 +223,7 @@ func (p *Parser) forStatement() (ast.Declaration, error) {
 	}
 
 	if !p.match(lexer.TokenLeftBrace) {
-		return nil, fmt.Errorf("expected '{' after for clauses at line %d", p.peek().Line)
+		return nil, errcode.Errorf("BRN0201", "expected '{' after for clauses at line %d", p.peek().Line)
 	}
 
 	body, err := p.block()
@@ -171,12 +236,51 @@ func (p *Parser) forStatement() (ast.Declaration, error) {
 		Condition:   condition,
 		Increment:   increment,
 		Body:        body,
-		Position:    pos,
+		Position:    start.Position,
+		Location:    location(start),
+	}, nil
+}
+
+// forInStatement parses the rest of a for statement that starts with a
+// variable and in. paren tells whether the clause is in parentheses.
+func (p *Parser) forInStatement(start lexer.Token, paren bool) (ast.Declaration, error) {
+	variable := p.advance().Value
+	p.advance()
+
+	// Without parentheses the brace after the iterable starts the body
+	iterate := p.clauseExpression
+	if paren {
+		iterate = p.expression
+	}
+	iterable, err := iterate()
+	if err != nil {
+		return nil, err
+	}
+
+	if paren && !p.match(lexer.TokenRightParen) {
+		return nil, errcode.Errorf("BRN0201", "expected ')' after for-in clause at line %d", p.peek().Line)
+	}
+
+	if !p.match(lexer.TokenLeftBrace) {
+		return nil, errcode.Errorf("BRN0201", "expected '{' after for-in clause at line %d", p.peek().Line)
+	}
+
+	body, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.ForInStatement{
+		Variable: variable,
+		Iterable: iterable,
+		Body:     body,
+		Position: start.Position,
+		Location: location(start),
 	}, nil
 }
 
 func (p *Parser) returnStatement() (ast.Declaration, error) {
-	pos := p.peek().Position
+	start := p.previous()
 
 	var value ast.Expression
 	var err error
@@ -193,30 +297,145 @@ func (p *Parser) returnStatement() (ast.Declaration, error) {
 
 	return &ast.ReturnStatement{
 		Value:    value,
-		Position: pos,
+		Position: start.Position,
+		Location: location(start),
 	}, nil
 }
 
+func (p *Parser) matchStatement() (ast.Declaration, error) {
+	keyword := p.previous()
+
+	value, err := p.clauseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.match(lexer.TokenLeftBrace) {
+		return nil, errcode.Errorf("BRN0201", "expected '{' after match value at line %d", p.peek().Line)
+	}
+
+	var cases []*ast.MatchCase
+	for !p.check(lexer.TokenRightBrace) && !p.isAtEnd() {
+		matchCase, err := p.matchCase()
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, matchCase)
+	}
+
+	if !p.match(lexer.TokenRightBrace) {
+		return nil, errcode.Errorf("BRN0201", "expected '}' after match cases at line %d", p.peek().Line)
+	}
+
+	return &ast.MatchStatement{
+		Value:    value,
+		Cases:    cases,
+		Position: keyword.Position,
+		Location: location(keyword),
+	}, nil
+}
+
+// matchCase parses a case of a match statement: patterns separated by
+// commas, then => and a block, or a single statement and a comma unless
+// the case is the last
+func (p *Parser) matchCase() (*ast.MatchCase, error) {
+	start := p.peek()
+	matchCase := &ast.MatchCase{Position: start.Position, Location: location(start)}
+
+	for {
+		tok := p.peek()
+		switch tok.Type {
+		case lexer.TokenTypeInt, lexer.TokenTypeFloat, lexer.TokenTypeString, lexer.TokenTypeBool:
+			p.advance()
+			matchCase.Types = append(matchCase.Types, tok.Value)
+		case lexer.TokenIdentifier:
+			p.advance()
+			if tok.Value == "_" {
+				if len(matchCase.Types) > 0 || len(matchCase.Patterns) > 0 || p.check(lexer.TokenComma) {
+					return nil, errcode.Errorf("BRN0206", "'_' must be the only pattern of its case at line %d", tok.Line)
+				}
+				break
+			}
+			matchCase.Types = append(matchCase.Types, tok.Value)
+		case lexer.TokenNumber, lexer.TokenString, lexer.TokenTrue, lexer.TokenFalse, lexer.TokenNil:
+			pattern, err := p.primary()
+			if err != nil {
+				return nil, err
+			}
+			matchCase.Patterns = append(matchCase.Patterns, pattern)
+		case lexer.TokenMinus:
+			if !p.checkNext(lexer.TokenNumber) {
+				return nil, errcode.Errorf("BRN0206", "expected a number after '-' in match pattern at line %d", tok.Line)
+			}
+			p.advance()
+			number, err := p.primary()
+			if err != nil {
+				return nil, err
+			}
+			matchCase.Patterns = append(matchCase.Patterns, &ast.UnaryExpression{
+				Operator: "-",
+				Right:    number,
+				Position: tok.Position,
+				Location: location(tok),
+			})
+		default:
+			return nil, errcode.Errorf("BRN0206", "expected a literal, a type or '_' as match pattern at line %d", tok.Line)
+		}
+		if !p.match(lexer.TokenComma) {
+			break
+		}
+	}
+
+	if !p.match(lexer.TokenArrow) {
+		return nil, errcode.Errorf("BRN0206", "expected '=>' after match pattern at line %d", p.peek().Line)
+	}
+
+	if p.match(lexer.TokenLeftBrace) {
+		body, err := p.block()
+		if err != nil {
+			return nil, err
+		}
+		matchCase.Body = body
+	} else {
+		stmt, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		matchCase.Body = []ast.Declaration{stmt}
+		if !p.match(lexer.TokenComma) && !p.check(lexer.TokenRightBrace) {
+			return nil, errcode.Errorf("BRN0201", "expected ',' after match case at line %d", p.peek().Line)
+		}
+	}
+
+	return matchCase, nil
+}
+
 func (p *Parser) block() ([]ast.Declaration, error) {
 	statements := []ast.Declaration{}
 
+	defer p.leaveClause()()
 	for !p.check(lexer.TokenRightBrace) && !p.isAtEnd() {
+		start := p.current
 		decl, err := p.declaration()
 		if err != nil {
-			return nil, err
+			if !p.recovering {
+				return nil, err
+			}
+			decl = p.fail(start, err)
+			p.synchronizeStatement(start)
 		}
 		statements = append(statements, decl)
 	}
 
 	if !p.match(lexer.TokenRightBrace) {
-		return nil, fmt.Errorf("expected '}' at line %d", p.peek().Line)
+		return nil, errcode.Errorf("BRN0201", "expected '}' at line %d", p.peek().Line)
 	}
 
 	return statements, nil
 }
 
 func (p *Parser) expressionStatement() (ast.Declaration, error) {
-	pos := p.peek().Position
+	start := p.peek()
 
 	expr, err := p.expression()
 	if err != nil {
@@ -228,6 +447,7 @@ func (p *Parser) expressionStatement() (ast.Declaration, error) {
 
 	return &ast.ExpressionStatement{
 		Expression: expr,
-		Position:   pos,
+		Position:   start.Position,
+		Location:   location(start),
 	}, nil
 }
@@ -0,0 +1,318 @@
+package pkgmgr
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Install fetches every dependency declared in the manifest of dir into the
+// vendor directory, honouring versions pinned in the lockfile, removes the
+// packages no longer declared from it, and rewrites the lockfile to match.
+func Install(dir string, out io.Writer) error {
+	manifest, err := LoadManifest(dir)
+	if err != nil {
+		return err
+	}
+
+	lock, err := LoadLockfile(dir)
+	if err != nil {
+		return err
+	}
+
+	vendorRoot := filepath.Join(dir, VendorDir)
+	if err := os.MkdirAll(vendorRoot, 0755); err != nil {
+		return fmt.Errorf("error creating %s: %v", VendorDir, err)
+	}
+
+	newLock := &Lockfile{Packages: make(map[string]LockedPackage)}
+
+	for _, dep := range manifest.Dependencies {
+		var pinned *LockedPackage
+		if locked, ok := lock.Packages[dep.Name]; ok && locked.Source == dep.Source() {
+			pinned = &locked
+		}
+
+		if err := dep.Validate(); err != nil {
+			return err
+		}
+		dest := filepath.Join(vendorRoot, dep.Name)
+		if !within(vendorRoot, dest) {
+			return fmt.Errorf("invalid dependency name %q: outside %s", dep.Name, VendorDir)
+		}
+		if err := os.RemoveAll(dest); err != nil {
+			return fmt.Errorf("error cleaning %s: %v", dest, err)
+		}
+
+		var pkg LockedPackage
+		if dep.Tarball != "" {
+			pkg, err = fetchTarball(dep, dest)
+		} else {
+			pkg, err = fetchGit(dep, pinned, dest)
+		}
+		if err != nil {
+			return fmt.Errorf("error fetching %s: %v", dep.Name, err)
+		}
+
+		if pinned != nil && pinned.Checksum != "" && pinned.Checksum != pkg.Checksum {
+			return fmt.Errorf("checksum mismatch for %s: lockfile has %s, fetched %s",
+				dep.Name, pinned.Checksum, pkg.Checksum)
+		}
+
+		newLock.Packages[dep.Name] = pkg
+		fmt.Fprintf(out, "Installed %s %s\n", dep.Name, pkg.Version)
+	}
+
+	if err := prune(vendorRoot, newLock, out); err != nil {
+		return err
+	}
+	return newLock.Save(dir)
+}
+
+// prune removes the packages in the vendor directory vendorRoot that are
+// not in lock, such as those dropped from the manifest
+func prune(vendorRoot string, lock *Lockfile, out io.Writer) error {
+	entries, err := os.ReadDir(vendorRoot)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %v", VendorDir, err)
+	}
+	for _, entry := range entries {
+		if _, ok := lock.Packages[entry.Name()]; ok {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(vendorRoot, entry.Name())); err != nil {
+			return fmt.Errorf("error removing %s: %v", entry.Name(), err)
+		}
+		fmt.Fprintf(out, "Removed %s\n", entry.Name())
+	}
+	return nil
+}
+
+// within reports whether path lies strictly inside dir
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+func fetchGit(dep Dependency, pinned *LockedPackage, dest string) (LockedPackage, error) {
+	if strings.HasPrefix(dep.Git, "-") {
+		return LockedPackage{}, fmt.Errorf("invalid git URL %q", dep.Git)
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return LockedPackage{}, fmt.Errorf("git is required to fetch %s", dep.Git)
+	}
+
+	if out, err := exec.Command("git", "clone", "--quiet", "--", dep.Git, dest).CombinedOutput(); err != nil {
+		return LockedPackage{}, fmt.Errorf("git clone failed: %s", strings.TrimSpace(string(out)))
+	}
+
+	target := dep.Rev
+	if pinned != nil && pinned.Commit != "" {
+		target = pinned.Commit
+	}
+	if target != "" {
+		if out, err := exec.Command("git", "-C", dest, "checkout", "--quiet", target).CombinedOutput(); err != nil {
+			return LockedPackage{}, fmt.Errorf("git checkout %s failed: %s", target, strings.TrimSpace(string(out)))
+		}
+	}
+
+	out, err := exec.Command("git", "-C", dest, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return LockedPackage{}, fmt.Errorf("git rev-parse failed: %v", err)
+	}
+	commit := strings.TrimSpace(string(out))
+
+	if err := os.RemoveAll(filepath.Join(dest, ".git")); err != nil {
+		return LockedPackage{}, err
+	}
+
+	checksum, err := hashTree(dest)
+	if err != nil {
+		return LockedPackage{}, err
+	}
+
+	version := dep.Rev
+	if version == "" {
+		version = commit[:min(12, len(commit))]
+	}
+
+	return LockedPackage{
+		Name:     dep.Name,
+		Source:   dep.Source(),
+		Version:  version,
+		Commit:   commit,
+		Checksum: checksum,
+	}, nil
+}
+
+func fetchTarball(dep Dependency, dest string) (LockedPackage, error) {
+	var reader io.Reader
+
+	if strings.HasPrefix(dep.Tarball, "http://") || strings.HasPrefix(dep.Tarball, "https://") {
+		resp, err := http.Get(dep.Tarball)
+		if err != nil {
+			return LockedPackage{}, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return LockedPackage{}, fmt.Errorf("download failed with status %s", resp.Status)
+		}
+		reader = resp.Body
+	} else {
+		file, err := os.Open(strings.TrimPrefix(dep.Tarball, "file://"))
+		if err != nil {
+			return LockedPackage{}, err
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	buffered := bufio.NewReader(reader)
+	if magic, err := buffered.Peek(2); err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return LockedPackage{}, err
+		}
+		defer gz.Close()
+		reader = gz
+	} else {
+		reader = buffered
+	}
+
+	if err := extractTar(tar.NewReader(reader), dest); err != nil {
+		return LockedPackage{}, err
+	}
+
+	checksum, err := hashTree(dest)
+	if err != nil {
+		return LockedPackage{}, err
+	}
+
+	version := ""
+	if manifest, err := LoadManifest(dest); err == nil {
+		version = manifest.Version
+	}
+
+	return LockedPackage{
+		Name:     dep.Name,
+		Source:   dep.Source(),
+		Version:  version,
+		Checksum: checksum,
+	}, nil
+}
+
+func extractTar(tr *tar.Reader, dest string) error {
+	type entry struct {
+		name string
+		mode os.FileMode
+		data []byte
+	}
+
+	var files []entry
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("invalid tarball: %v", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(filepath.Clean(header.Name))
+		if unsafePath(name) {
+			return fmt.Errorf("invalid path in tarball: %s", header.Name)
+		}
+		files = append(files, entry{name: name, mode: os.FileMode(header.Mode).Perm(), data: data})
+	}
+
+	// Archives usually wrap everything in a single top-level directory,
+	// which is stripped so the package root lands directly in dest.
+	prefix := ""
+	if len(files) > 0 {
+		if idx := strings.Index(files[0].name, "/"); idx > 0 {
+			prefix = files[0].name[:idx+1]
+			for _, f := range files {
+				if !strings.HasPrefix(f.name, prefix) {
+					prefix = ""
+					break
+				}
+			}
+		}
+	}
+
+	for _, f := range files {
+		name := strings.TrimPrefix(f.name, prefix)
+		if name == "" || unsafePath(name) {
+			return fmt.Errorf("invalid path in tarball: %s", f.name)
+		}
+
+		target := filepath.Join(dest, filepath.FromSlash(name))
+		if !within(dest, target) {
+			return fmt.Errorf("invalid path in tarball: %s", f.name)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, f.data, f.mode|0600); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unsafePath reports whether a cleaned, slash-separated name from a tarball
+// could escape the directory it is extracted to
+func unsafePath(name string) bool {
+	return name == ".." || strings.HasPrefix(name, "../") || strings.HasPrefix(name, "/") || filepath.IsAbs(filepath.FromSlash(name))
+}
+
+// hashTree returns a sha256 checksum over the relative paths and contents of
+// every file below root.
+func hashTree(root string) (string, error) {
+	var paths []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(paths)
+
+	h := sha256.New()
+	for _, path := range paths {
+		rel, _ := filepath.Rel(root, path)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", filepath.ToSlash(rel), len(data))
+		h.Write(data)
+	}
+
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
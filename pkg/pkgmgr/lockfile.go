@@ -0,0 +1,82 @@
+package pkgmgr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/burnlang/burn/pkg/toml"
+)
+
+type LockedPackage struct {
+	Name     string
+	Source   string
+	Version  string
+	Commit   string
+	Checksum string
+}
+
+type Lockfile struct {
+	Packages map[string]LockedPackage
+}
+
+func LoadLockfile(dir string) (*Lockfile, error) {
+	lock := &Lockfile{Packages: make(map[string]LockedPackage)}
+
+	data, err := os.ReadFile(filepath.Join(dir, LockFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return lock, nil
+		}
+		return nil, err
+	}
+
+	root, err := toml.Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", LockFileName, err)
+	}
+
+	for _, name := range root.Keys() {
+		entry := root.Table(name)
+		if entry == nil {
+			continue
+		}
+		lock.Packages[name] = LockedPackage{
+			Name:     name,
+			Source:   entry.String("source"),
+			Version:  entry.String("version"),
+			Commit:   entry.String("commit"),
+			Checksum: entry.String("checksum"),
+		}
+	}
+
+	return lock, nil
+}
+
+func (l *Lockfile) Save(dir string) error {
+	names := make([]string, 0, len(l.Packages))
+	for name := range l.Packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString("# This file is generated by `burn install`. Do not edit it by hand.\n")
+
+	for _, name := range names {
+		pkg := l.Packages[name]
+		sb.WriteString("\n[" + toml.Quote(name) + "]\n")
+		sb.WriteString("source = " + toml.Quote(pkg.Source) + "\n")
+		if pkg.Version != "" {
+			sb.WriteString("version = " + toml.Quote(pkg.Version) + "\n")
+		}
+		if pkg.Commit != "" {
+			sb.WriteString("commit = " + toml.Quote(pkg.Commit) + "\n")
+		}
+		sb.WriteString("checksum = " + toml.Quote(pkg.Checksum) + "\n")
+	}
+
+	return os.WriteFile(filepath.Join(dir, LockFileName), []byte(sb.String()), 0644)
+}
@@ -0,0 +1,210 @@
+package pkgmgr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/burnlang/burn/pkg/toml"
+)
+
+const (
+	ManifestFile = "burn.toml"
+	LockFileName = "burn.lock"
+	VendorDir    = "burn_modules"
+)
+
+type Dependency struct {
+	Name    string
+	Git     string
+	Rev     string
+	Tarball string
+}
+
+// Source identifies where a dependency comes from. It is recorded in the
+// lockfile so a changed source invalidates the pinned version.
+func (d Dependency) Source() string {
+	if d.Tarball != "" {
+		return "tarball+" + d.Tarball
+	}
+	if d.Rev != "" {
+		return "git+" + d.Git + "#" + d.Rev
+	}
+	return "git+" + d.Git
+}
+
+type Manifest struct {
+	Name         string
+	Version      string
+	Main         string
+	Dependencies []Dependency
+}
+
+func LoadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := toml.Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", ManifestFile, err)
+	}
+
+	manifest := &Manifest{}
+	if pkg := root.Table("package"); pkg != nil {
+		manifest.Name = pkg.String("name")
+		manifest.Version = pkg.String("version")
+		manifest.Main = pkg.String("main")
+	}
+
+	deps := root.Table("dependencies")
+	for _, name := range deps.Keys() {
+		dep := Dependency{Name: name}
+
+		switch spec := deps[name].(type) {
+		case string:
+			dep.Git, dep.Rev = splitRev(spec)
+			if isTarballURL(dep.Git) {
+				dep.Tarball, dep.Git, dep.Rev = dep.Git, "", ""
+			}
+		case toml.Table:
+			dep.Git = spec.String("git")
+			dep.Rev = spec.String("rev")
+			if dep.Rev == "" {
+				dep.Rev = spec.String("tag")
+			}
+			dep.Tarball = spec.String("tarball")
+		default:
+			return nil, fmt.Errorf("invalid source for dependency %s", name)
+		}
+
+		if dep.Git == "" && dep.Tarball == "" {
+			return nil, fmt.Errorf("dependency %s must have a git or tarball source", name)
+		}
+		if dep.Git != "" && dep.Tarball != "" {
+			return nil, fmt.Errorf("dependency %s cannot have both git and tarball sources", name)
+		}
+		if err := dep.Validate(); err != nil {
+			return nil, err
+		}
+
+		manifest.Dependencies = append(manifest.Dependencies, dep)
+	}
+
+	return manifest, nil
+}
+
+// ParseDependency builds a dependency from the command line form
+// url[@rev], treating .tar.gz/.tgz/.tar URLs as tarballs.
+func ParseDependency(name, spec string) Dependency {
+	if isTarballURL(spec) {
+		return Dependency{Name: name, Tarball: spec}
+	}
+	url, rev := splitRev(spec)
+	return Dependency{Name: name, Git: url, Rev: rev}
+}
+
+// Validate rejects a dependency whose name is not a single directory name,
+// since it is vendored under that name, or whose git URL could be read as
+// an option of git
+func (d Dependency) Validate() error {
+	name := d.Name
+	if name == "" || name == "." || name == ".." || filepath.IsAbs(name) ||
+		strings.ContainsAny(name, `/\`) || filepath.Base(name) != name {
+		return fmt.Errorf("invalid dependency name %q: must be a single directory name", name)
+	}
+	if strings.HasPrefix(d.Git, "-") {
+		return fmt.Errorf("invalid git URL %q for dependency %s", d.Git, name)
+	}
+	return nil
+}
+
+// AddDependency appends dep to the [dependencies] table of the manifest in
+// dir, creating the manifest or the table if needed.
+func AddDependency(dir string, dep Dependency) error {
+	if err := dep.Validate(); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, ManifestFile)
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if len(data) > 0 {
+		manifest, err := LoadManifest(dir)
+		if err != nil {
+			return err
+		}
+		for _, existing := range manifest.Dependencies {
+			if existing.Name == dep.Name {
+				return fmt.Errorf("dependency %s is already declared in %s", dep.Name, ManifestFile)
+			}
+		}
+	}
+
+	entry := dep.Name + " = " + formatDependency(dep)
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(data) == 0 {
+		lines = []string{"[package]", "name = " + toml.Quote(filepath.Base(absDir(dir))), "version = \"0.1.0\""}
+	}
+
+	section := -1
+	for idx, line := range lines {
+		if strings.TrimSpace(line) == "[dependencies]" {
+			section = idx
+			break
+		}
+	}
+
+	if section < 0 {
+		lines = append(lines, "", "[dependencies]", entry)
+	} else {
+		insertAt := len(lines)
+		for idx := section + 1; idx < len(lines); idx++ {
+			if strings.HasPrefix(strings.TrimSpace(lines[idx]), "[") {
+				insertAt = idx
+				break
+			}
+		}
+		for insertAt > section+1 && strings.TrimSpace(lines[insertAt-1]) == "" {
+			insertAt--
+		}
+		lines = append(lines[:insertAt], append([]string{entry}, lines[insertAt:]...)...)
+	}
+
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+func formatDependency(dep Dependency) string {
+	if dep.Tarball != "" {
+		return "{ tarball = " + toml.Quote(dep.Tarball) + " }"
+	}
+	if dep.Rev != "" {
+		return "{ git = " + toml.Quote(dep.Git) + ", rev = " + toml.Quote(dep.Rev) + " }"
+	}
+	return "{ git = " + toml.Quote(dep.Git) + " }"
+}
+
+func splitRev(spec string) (string, string) {
+	at := strings.LastIndex(spec, "@")
+	slash := strings.LastIndex(spec, "/")
+	if at > 0 && at > slash {
+		return spec[:at], spec[at+1:]
+	}
+	return spec, ""
+}
+
+func isTarballURL(url string) bool {
+	return strings.HasSuffix(url, ".tar.gz") || strings.HasSuffix(url, ".tgz") || strings.HasSuffix(url, ".tar")
+}
+
+func absDir(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return dir
+}
@@ -0,0 +1,75 @@
+package pkgmgr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProjectRoot returns the root of the project dir is in: the nearest of dir
+// and the directories above it that holds a manifest, or dir itself when
+// none does
+func ProjectRoot(dir string) string {
+	if dir == "" {
+		dir = "."
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+	for d := abs; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, ManifestFile)); err == nil {
+			return d
+		}
+		if filepath.Dir(d) == d {
+			return dir
+		}
+	}
+}
+
+// VendorCandidates returns the files under the vendor directory of the
+// project dir is in that an import path may refer to. A bare package name
+// ("utils") resolves to the package entry file, while "utils/strings"
+// resolves to a file inside it. Files outside the package, such as those
+// of an import path or a main with "..", are left out.
+func VendorCandidates(importPath, dir string) []string {
+	path := filepath.ToSlash(importPath)
+	path = strings.TrimPrefix(path, "src/lib/std/")
+	path = strings.TrimPrefix(path, "./")
+	path = strings.TrimSuffix(path, ".bn")
+	if path == "" || strings.HasPrefix(path, "../") || strings.HasPrefix(path, "/") {
+		return nil
+	}
+
+	parts := strings.SplitN(path, "/", 2)
+	vendorRoot := filepath.Join(ProjectRoot(dir), VendorDir)
+	pkgDir := filepath.Join(vendorRoot, parts[0])
+	if !within(vendorRoot, pkgDir) {
+		return nil
+	}
+
+	var files []string
+	if len(parts) == 1 {
+		if manifest, err := LoadManifest(pkgDir); err == nil && manifest.Main != "" {
+			files = append(files, filepath.Join(pkgDir, manifest.Main))
+		}
+		files = append(files,
+			filepath.Join(pkgDir, parts[0]+".bn"),
+			filepath.Join(pkgDir, "main.bn"),
+			filepath.Join(pkgDir, "src", parts[0]+".bn"),
+		)
+	} else {
+		files = append(files,
+			filepath.Join(pkgDir, parts[1]+".bn"),
+			filepath.Join(pkgDir, "src", parts[1]+".bn"),
+		)
+	}
+
+	candidates := []string{}
+	for _, file := range files {
+		if within(pkgDir, file) {
+			candidates = append(candidates, file)
+		}
+	}
+	return candidates
+}
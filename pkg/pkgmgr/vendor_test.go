@@ -0,0 +1,65 @@
+package pkgmgr
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVendorCandidatesStayInPackage(t *testing.T) {
+	root := t.TempDir()
+	write(t, filepath.Join(root, ManifestFile), "[package]\nname = \"app\"\n")
+	pkgDir := filepath.Join(root, VendorDir, "evil")
+	write(t, filepath.Join(pkgDir, ManifestFile), "[package]\nname = \"evil\"\nmain = \"../../secret.bn\"\n")
+
+	for _, importPath := range []string{"evil", "evil/../../secret", ".."} {
+		for _, c := range VendorCandidates(importPath, root) {
+			if !within(pkgDir, c) {
+				t.Errorf("%s: candidate %s is outside the package", importPath, c)
+			}
+		}
+	}
+}
+
+func TestVendorCandidatesFromSubdirectory(t *testing.T) {
+	root := t.TempDir()
+	write(t, filepath.Join(root, ManifestFile), "[package]\nname = \"app\"\n")
+	sub := filepath.Join(root, "src", "app")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	want := filepath.Join(root, VendorDir, "utils", "utils.bn")
+	if got := VendorCandidates("utils", sub); !slices.Contains(got, want) {
+		t.Errorf("got %v, want it to contain %s", got, want)
+	}
+}
+
+func TestPruneRemovesUndeclaredPackages(t *testing.T) {
+	vendorRoot := filepath.Join(t.TempDir(), VendorDir)
+	write(t, filepath.Join(vendorRoot, "kept", "kept.bn"), "")
+	write(t, filepath.Join(vendorRoot, "dropped", "dropped.bn"), "")
+
+	lock := &Lockfile{Packages: map[string]LockedPackage{"kept": {Name: "kept"}}}
+	if err := prune(vendorRoot, lock, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(vendorRoot, "kept")); err != nil {
+		t.Errorf("kept package was removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(vendorRoot, "dropped")); !os.IsNotExist(err) {
+		t.Errorf("dropped package was not removed: %v", err)
+	}
+}
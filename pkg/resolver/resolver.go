@@ -0,0 +1,158 @@
+// Package resolver finds the source of imported modules. The interpreter,
+// the typechecker and the compiler all look imports up through it, and
+// embedders can supply their own Resolver to serve modules from anywhere.
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/burnlang/burn/pkg/pkgmgr"
+	"github.com/burnlang/burn/pkg/stdlib"
+)
+
+// ErrNotFound is wrapped by the error a Resolver returns when it has no
+// module for an import path
+var ErrNotFound = errors.New("module not found")
+
+// Module is the source of an imported module
+type Module struct {
+	// Path is where the module was found, used in error messages and for
+	// resolving the imports of the module itself. For the standard library
+	// it is the library name.
+	Path string
+
+	Source string
+
+	// Std is set for libraries of the standard library
+	Std bool
+}
+
+// Resolver looks up imports. path is the import path as the parser returns
+// it, and dir the directory of the importing file, or "" when the import
+// comes from code without a file.
+type Resolver interface {
+	Resolve(path, dir string) (*Module, error)
+}
+
+// Default returns the resolver used unless another one is configured: the
+// file system, searching dirs after the usual places, then the standard
+// library
+func Default(dirs ...string) Resolver {
+	return Chain{FileSystem{Dirs: dirs}, Stdlib{}}
+}
+
+// Chain tries resolvers in order and returns the first module found
+type Chain []Resolver
+
+func (c Chain) Resolve(path, dir string) (*Module, error) {
+	var notFound error
+	for _, r := range c {
+		m, err := r.Resolve(path, dir)
+		if err == nil {
+			return m, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		if notFound == nil {
+			notFound = err
+		}
+	}
+	if notFound == nil {
+		notFound = ErrNotFound
+	}
+	return nil, notFound
+}
+
+// FileSystem finds imports on disk: relative to the working directory and
+// the importing file, under src/lib, in Dirs and in the vendor directory of
+// the project the importing file is in
+type FileSystem struct {
+	Dirs []string
+}
+
+func (f FileSystem) Resolve(importPath, dir string) (*Module, error) {
+	candidates := f.Candidates(importPath, dir)
+	for _, candidate := range candidates {
+		source, err := os.ReadFile(candidate)
+		if err == nil {
+			return &Module{Path: candidate, Source: string(source)}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w (tried paths: %s)", ErrNotFound, strings.Join(candidates, ", "))
+}
+
+// Candidates returns the files an import may refer to, in the order they
+// are tried
+func (f FileSystem) Candidates(importPath, dir string) []string {
+	p := withExtension(importPath)
+	bare := strings.TrimPrefix(filepath.ToSlash(p), "src/lib/std/")
+
+	candidates := []string{p}
+	if dir != "" && dir != "." {
+		candidates = append(candidates, filepath.Join(dir, p))
+		if bare != p {
+			candidates = append(candidates, filepath.Join(dir, bare))
+		}
+	}
+	candidates = append(candidates,
+		filepath.Join("src", "lib", "std", p),
+		filepath.Join("src", "lib", p),
+		filepath.Join("test", strings.TrimPrefix(p, "test/")),
+	)
+	for _, d := range f.Dirs {
+		candidates = append(candidates, filepath.Join(d, bare))
+	}
+	return append(candidates, pkgmgr.VendorCandidates(importPath, dir)...)
+}
+
+// FS finds imports in a file system such as an embed.FS. Import paths are
+// taken relative to its root and to the directory of the importing file.
+type FS struct {
+	FS fs.FS
+}
+
+func (f FS) Resolve(importPath, dir string) (*Module, error) {
+	p := filepath.ToSlash(withExtension(importPath))
+	bare := strings.TrimPrefix(p, "src/lib/std/")
+	dir = filepath.ToSlash(dir)
+
+	var tried []string
+	for _, candidate := range []string{path.Join(dir, bare), bare, p} {
+		if !fs.ValidPath(candidate) || slices.Contains(tried, candidate) {
+			continue
+		}
+		tried = append(tried, candidate)
+		source, err := fs.ReadFile(f.FS, candidate)
+		if err == nil {
+			return &Module{Path: candidate, Source: string(source)}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w (tried paths: %s)", ErrNotFound, strings.Join(tried, ", "))
+}
+
+// Stdlib finds the libraries registered in package stdlib by their name,
+// whatever directory the import names
+type Stdlib struct{}
+
+func (Stdlib) Resolve(importPath, dir string) (*Module, error) {
+	name := strings.TrimSuffix(filepath.Base(importPath), ".bn")
+	if source, exists := stdlib.StdLibFiles[name]; exists {
+		return &Module{Path: name, Source: source, Std: true}, nil
+	}
+	return nil, fmt.Errorf("%w: no standard library named %s", ErrNotFound, name)
+}
+
+func withExtension(p string) string {
+	if strings.HasSuffix(p, ".bn") {
+		return p
+	}
+	return p + ".bn"
+}
@@ -0,0 +1,80 @@
+package resolver_test
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/burnlang/burn/pkg/resolver"
+)
+
+var modules = fstest.MapFS{
+	"lib/math.bn":   {Data: []byte("fun sq(n: int): int { return n * n }")},
+	"lib/helper.bn": {Data: []byte("fun one(): int { return 1 }")},
+	"top.bn":        {Data: []byte("fun top(): int { return 0 }")},
+}
+
+func TestFS(t *testing.T) {
+	r := resolver.FS{FS: modules}
+	for _, tt := range []struct {
+		path, dir, want string
+	}{
+		{"lib/math.bn", "", "lib/math.bn"},
+		{"lib/math", ".", "lib/math.bn"},
+		{"helper.bn", "lib", "lib/helper.bn"},
+		{"../top.bn", "lib", "top.bn"},
+		// The parser turns a bare name into a path in the standard library
+		{"src/lib/std/top.bn", "", "top.bn"},
+		{"src/lib/std/helper.bn", "lib", "lib/helper.bn"},
+	} {
+		m, err := r.Resolve(tt.path, tt.dir)
+		if err != nil {
+			t.Errorf("Resolve(%q, %q): %v", tt.path, tt.dir, err)
+			continue
+		}
+		if m.Path != tt.want || m.Source != string(modules[tt.want].Data) || m.Std {
+			t.Errorf("Resolve(%q, %q) = %+v, want %s", tt.path, tt.dir, m, tt.want)
+		}
+	}
+	// Paths outside the file system are not found
+	for _, p := range []string{"missing.bn", "../../top.bn"} {
+		if _, err := r.Resolve(p, "lib"); !errors.Is(err, resolver.ErrNotFound) {
+			t.Errorf("Resolve(%q): got %v, want ErrNotFound", p, err)
+		}
+	}
+}
+
+func TestStdlib(t *testing.T) {
+	m, err := resolver.Stdlib{}.Resolve("src/lib/std/time.bn", "anywhere")
+	if err != nil || !m.Std || m.Path != "time" || m.Source == "" {
+		t.Errorf("Resolve(time) = %+v, %v; want the time library", m, err)
+	}
+	if _, err := (resolver.Stdlib{}).Resolve("nosuchlib", ""); !errors.Is(err, resolver.ErrNotFound) {
+		t.Errorf("got %v, want ErrNotFound", err)
+	}
+}
+
+// failing fails every import with an error other than ErrNotFound
+type failing struct{}
+
+func (failing) Resolve(path, dir string) (*resolver.Module, error) {
+	return nil, errors.New("disk on fire")
+}
+
+func TestChain(t *testing.T) {
+	chain := resolver.Chain{resolver.FS{FS: modules}, resolver.Stdlib{}}
+	if m, err := chain.Resolve("src/lib/std/time.bn", ""); err != nil || !m.Std {
+		t.Errorf("got %+v, %v; want the time library after the FS had none", m, err)
+	}
+	if m, err := chain.Resolve("top", ""); err != nil || m.Path != "top.bn" {
+		t.Errorf("got %+v, %v; want top.bn from the first resolver", m, err)
+	}
+	if _, err := chain.Resolve("missing", ""); !errors.Is(err, resolver.ErrNotFound) {
+		t.Errorf("got %v, want ErrNotFound", err)
+	}
+
+	broken := resolver.Chain{failing{}, resolver.FS{FS: modules}}
+	if _, err := broken.Resolve("top", ""); err == nil || errors.Is(err, resolver.ErrNotFound) {
+		t.Errorf("got %v, want the error of the first resolver", err)
+	}
+}
@@ -6,14 +6,19 @@ import (
 	"strings"
 )
 
+// Builtin is a Go function implementing part of a native library
+type Builtin func(args []interface{}) (interface{}, error)
+
 type Library struct {
-	Name    string
-	Content string
+	Name     string
+	Content  string
+	Builtins map[string]Builtin
 }
 
 var RegisteredLibs = []Library{
 	{Name: "date", Content: DateLib},
 	{Name: "http", Content: HTTPLib},
+	{Name: "strings", Content: StringsLib, Builtins: StringsBuiltins},
 	{Name: "time", Content: TimeLib},
 }
 
@@ -35,6 +40,32 @@ func RegisterLibrary(name string, content string) {
 	StdLibFiles[name] = content
 }
 
+// RegisterNativeLibrary registers a library implemented in Go, typically
+// from the init function of the package providing it. content declares the
+// types, classes and functions of the library in Burn, with empty bodies for
+// the parts implemented in Go; this is what the typechecker sees. builtins
+// holds those implementations, keyed by function name, or by Class.method
+// for class methods.
+func RegisterNativeLibrary(name string, content string, builtins map[string]Builtin) {
+	RegisteredLibs = append(RegisteredLibs, Library{
+		Name:     name,
+		Content:  content,
+		Builtins: builtins,
+	})
+	StdLibFiles[name] = content
+}
+
+// NativeBuiltins returns the Go implementations of a library registered with
+// RegisterNativeLibrary, or nil for libraries written in Burn
+func NativeBuiltins(name string) map[string]Builtin {
+	for j := len(RegisteredLibs) - 1; j >= 0; j-- {
+		if RegisteredLibs[j].Name == name {
+			return RegisteredLibs[j].Builtins
+		}
+	}
+	return nil
+}
+
 func AutoRegisterLibraryFromFile(path string) error {
 	content, err := os.ReadFile(path)
 	if err != nil {
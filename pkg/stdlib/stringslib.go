@@ -0,0 +1,174 @@
+package stdlib
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// StringsLib contains the declarations of the strings standard library,
+// whose methods are implemented in Go by StringsBuiltins
+const StringsLib = `// Burn Standard Library: Strings Module
+// This provides string functions for Burn programs
+
+// Strings class with the string functions
+class Strings {
+    // Split a string into the parts between each separator
+    fun split(s: string, sep: string): [string] {}
+
+    // Join parts into one string with a separator between them
+    fun join(parts: [string], sep: string): string {}
+
+    // Remove white space from the start and end of a string
+    fun trim(s: string): string {}
+
+    // Replace every occurrence of old in a string with new
+    fun replace(s: string, old: string, new: string): string {}
+
+    // Check if a string contains another
+    fun contains(s: string, sub: string): bool {}
+
+    // Check if a string starts with a prefix
+    fun startsWith(s: string, prefix: string): bool {}
+
+    // Check if a string ends with a suffix
+    fun endsWith(s: string, suffix: string): bool {}
+
+    // Convert a string to upper case
+    fun toUpper(s: string): string {}
+
+    // Convert a string to lower case
+    fun toLower(s: string): string {}
+
+    // Get the index of the first occurrence of sub in a string, or -1
+    fun indexOf(s: string, sub: string): int {}
+
+    // Repeat a string count times
+    fun repeat(s: string, count: int): string {}
+}
+`
+
+// StringsBuiltins implements the methods of the strings library
+var StringsBuiltins = map[string]Builtin{
+	"Strings.split": func(args []interface{}) (interface{}, error) {
+		s, sep, err := twoStrings("split", args)
+		if err != nil {
+			return nil, err
+		}
+		parts := strings.Split(s, sep)
+		result := make([]interface{}, len(parts))
+		for j, part := range parts {
+			result[j] = part
+		}
+		return result, nil
+	},
+	"Strings.join": func(args []interface{}) (interface{}, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("join expects exactly 2 arguments")
+		}
+		parts, ok := args[0].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("join expects an array as first argument, got %T", args[0])
+		}
+		sep, ok := args[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("join expects a string as second argument, got %T", args[1])
+		}
+		strs := make([]string, len(parts))
+		for j, part := range parts {
+			str, ok := part.(string)
+			if !ok {
+				return nil, fmt.Errorf("join expects an array of strings, got %T", part)
+			}
+			strs[j] = str
+		}
+		return strings.Join(strs, sep), nil
+	},
+	"Strings.trim": stringFunc("trim", strings.TrimSpace),
+	"Strings.replace": func(args []interface{}) (interface{}, error) {
+		if len(args) != 3 {
+			return nil, fmt.Errorf("replace expects exactly 3 arguments")
+		}
+		s, old, err := twoStrings("replace", args[:2])
+		if err != nil {
+			return nil, err
+		}
+		replacement, ok := args[2].(string)
+		if !ok {
+			return nil, fmt.Errorf("replace expects a string as third argument, got %T", args[2])
+		}
+		return strings.ReplaceAll(s, old, replacement), nil
+	},
+	"Strings.contains":   stringTest("contains", strings.Contains),
+	"Strings.startsWith": stringTest("startsWith", strings.HasPrefix),
+	"Strings.endsWith":   stringTest("endsWith", strings.HasSuffix),
+	"Strings.toUpper":    stringFunc("toUpper", strings.ToUpper),
+	"Strings.toLower":    stringFunc("toLower", strings.ToLower),
+	"Strings.indexOf": func(args []interface{}) (interface{}, error) {
+		s, sub, err := twoStrings("indexOf", args)
+		if err != nil {
+			return nil, err
+		}
+		index := strings.Index(s, sub)
+		if index < 0 {
+			return float64(-1), nil
+		}
+		// Count characters rather than bytes
+		return float64(utf8.RuneCountInString(s[:index])), nil
+	},
+	"Strings.repeat": func(args []interface{}) (interface{}, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("repeat expects exactly 2 arguments")
+		}
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("repeat expects a string as first argument, got %T", args[0])
+		}
+		count, ok := args[1].(float64)
+		if !ok || count < 0 || count != float64(int(count)) {
+			return nil, fmt.Errorf("repeat expects a whole number of at least 0 as count, got %v", args[1])
+		}
+		return strings.Repeat(s, int(count)), nil
+	},
+}
+
+// twoStrings returns the arguments of a builtin taking two strings
+func twoStrings(name string, args []interface{}) (string, string, error) {
+	if len(args) != 2 {
+		return "", "", fmt.Errorf("%s expects exactly 2 arguments", name)
+	}
+	first, ok := args[0].(string)
+	if !ok {
+		return "", "", fmt.Errorf("%s expects a string as first argument, got %T", name, args[0])
+	}
+	second, ok := args[1].(string)
+	if !ok {
+		return "", "", fmt.Errorf("%s expects a string as second argument, got %T", name, args[1])
+	}
+	return first, second, nil
+}
+
+// stringFunc makes a builtin of a Go function from string to string
+func stringFunc(name string, fn func(string) string) Builtin {
+	return func(args []interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s expects exactly 1 argument", name)
+		}
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("%s expects a string, got %T", name, args[0])
+		}
+		return fn(s), nil
+	}
+}
+
+// stringTest makes a builtin of a Go function testing two strings
+func stringTest(name string, fn func(string, string) bool) Builtin {
+	return func(args []interface{}) (interface{}, error) {
+		first, second, err := twoStrings(name, args)
+		if err != nil {
+			return nil, err
+		}
+		return fn(first, second), nil
+	}
+}
@@ -0,0 +1,82 @@
+// Package suggest finds the name a misspelled one was likely meant to be,
+// for the "did you mean" hints of error messages
+package suggest
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+)
+
+// Closest returns the candidate nearest to name by edit distance, when it
+// is near enough to be a likely misspelling of it: about one edit for every
+// three characters of the two names on average, so that no name of one or
+// two characters is taken for another. Ties go to the candidate first in
+// alphabetical order, so that hints do not depend on the order of maps.
+func Closest(name string, candidates ...iter.Seq[string]) (string, bool) {
+	best, bestDistance := "", -1
+	for _, seq := range candidates {
+		for candidate := range seq {
+			if candidate == name || candidate == "" {
+				continue
+			}
+			d := distance(name, candidate)
+			if d > (len(name)+len(candidate)+3)/6 {
+				continue
+			}
+			if bestDistance < 0 || d < bestDistance || (d == bestDistance && candidate < best) {
+				best, bestDistance = candidate, d
+			}
+		}
+	}
+	return best, bestDistance >= 0
+}
+
+// Hint returns " (did you mean X?)" for the closest candidate to name, or
+// "" when none is close, to end an error message about name
+func Hint(name string, candidates ...iter.Seq[string]) string {
+	if closest, ok := Closest(name, candidates...); ok {
+		return fmt.Sprintf(" (did you mean %s?)", closest)
+	}
+	return ""
+}
+
+// CutHint splits an error message ending in a hint made by Hint into the
+// message without it and the suggested name
+func CutHint(msg string) (string, string, bool) {
+	const prefix, suffix = " (did you mean ", "?)"
+	start := strings.LastIndex(msg, prefix)
+	if start < 0 || !strings.HasSuffix(msg, suffix) {
+		return msg, "", false
+	}
+	return msg[:start], msg[start+len(prefix) : len(msg)-len(suffix)], true
+}
+
+// distance returns the number of characters to insert, delete or replace,
+// or pairs of adjacent characters to swap, to turn a into b
+func distance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	// Three rows of the table are enough: the distances between prefixes
+	// of s and those of t one, two and no characters shorter
+	prev2 := make([]int, len(t)+1)
+	prev := make([]int, len(t)+1)
+	row := make([]int, len(t)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		row[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			row[j] = min(prev[j]+1, row[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				row[j] = min(row[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, row = prev, row, prev2
+	}
+	return prev[len(t)]
+}
@@ -0,0 +1,321 @@
+package toml
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Table is a parsed TOML table. Values are string, int64, float64, bool,
+// []interface{} or nested Table.
+type Table map[string]interface{}
+
+// Parse reads the subset of TOML used by Burn project files: [table] and
+// [a.b] headers, key = value pairs with strings, integers, floats, booleans,
+// arrays and inline tables, and # comments.
+func Parse(source string) (Table, error) {
+	root := Table{}
+	current := root
+
+	for n, rawLine := range strings.Split(source, "\n") {
+		lineNum := n + 1
+		line := strings.TrimSpace(stripComment(rawLine))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("invalid table header at line %d", lineNum)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if name == "" {
+				return nil, fmt.Errorf("empty table name at line %d", lineNum)
+			}
+
+			table, err := root.subTable(splitKey(name))
+			if err != nil {
+				return nil, fmt.Errorf("%v at line %d", err, lineNum)
+			}
+			current = table
+			continue
+		}
+
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			return nil, fmt.Errorf("expected '=' at line %d", lineNum)
+		}
+
+		key := unquoteKey(strings.TrimSpace(line[:eq]))
+		if key == "" {
+			return nil, fmt.Errorf("missing key at line %d", lineNum)
+		}
+
+		p := &valueParser{src: strings.TrimSpace(line[eq+1:])}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, fmt.Errorf("%v at line %d", err, lineNum)
+		}
+		p.skipSpace()
+		if p.pos < len(p.src) {
+			return nil, fmt.Errorf("unexpected trailing characters at line %d", lineNum)
+		}
+
+		if _, exists := current[key]; exists {
+			return nil, fmt.Errorf("duplicate key %s at line %d", key, lineNum)
+		}
+		current[key] = value
+	}
+
+	return root, nil
+}
+
+func (t Table) subTable(path []string) (Table, error) {
+	table := t
+	for _, part := range path {
+		next, exists := table[part]
+		if !exists {
+			created := Table{}
+			table[part] = created
+			table = created
+			continue
+		}
+		nested, ok := next.(Table)
+		if !ok {
+			return nil, fmt.Errorf("key %s is not a table", part)
+		}
+		table = nested
+	}
+	return table, nil
+}
+
+// Table returns the nested table stored under key, or nil.
+func (t Table) Table(key string) Table {
+	if value, ok := t[key].(Table); ok {
+		return value
+	}
+	return nil
+}
+
+// String returns the string stored under key, or "".
+func (t Table) String(key string) string {
+	if value, ok := t[key].(string); ok {
+		return value
+	}
+	return ""
+}
+
+// Bool returns the boolean stored under key and whether it was present.
+func (t Table) Bool(key string) (bool, bool) {
+	value, ok := t[key].(bool)
+	return value, ok
+}
+
+// Int returns the integer stored under key and whether it was present.
+func (t Table) Int(key string) (int64, bool) {
+	value, ok := t[key].(int64)
+	return value, ok
+}
+
+// Strings returns the string array stored under key.
+func (t Table) Strings(key string) []string {
+	values, ok := t[key].([]interface{})
+	if !ok {
+		return nil
+	}
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// Keys returns the keys of the table in sorted order.
+func (t Table) Keys() []string {
+	keys := make([]string, 0, len(t))
+	for k := range t {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Quote formats s as a TOML basic string.
+func Quote(s string) string {
+	return strconv.Quote(s)
+}
+
+func stripComment(line string) string {
+	inString := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			if inString {
+				i++
+			}
+		case '"':
+			inString = !inString
+		case '#':
+			if !inString {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+func splitKey(name string) []string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = unquoteKey(strings.TrimSpace(part))
+	}
+	return parts
+}
+
+func unquoteKey(key string) string {
+	if len(key) >= 2 && key[0] == '"' && key[len(key)-1] == '"' {
+		if unquoted, err := strconv.Unquote(key); err == nil {
+			return unquoted
+		}
+	}
+	return key
+}
+
+type valueParser struct {
+	src string
+	pos int
+}
+
+func (p *valueParser) skipSpace() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *valueParser) parseValue() (interface{}, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, fmt.Errorf("missing value")
+	}
+
+	switch c := p.src[p.pos]; {
+	case c == '"':
+		return p.parseString()
+	case c == '[':
+		return p.parseArray()
+	case c == '{':
+		return p.parseInlineTable()
+	case strings.HasPrefix(p.src[p.pos:], "true"):
+		p.pos += 4
+		return true, nil
+	case strings.HasPrefix(p.src[p.pos:], "false"):
+		p.pos += 5
+		return false, nil
+	default:
+		return p.parseNumber()
+	}
+}
+
+func (p *valueParser) parseString() (interface{}, error) {
+	start := p.pos
+	p.pos++
+	for p.pos < len(p.src) && p.src[p.pos] != '"' {
+		if p.src[p.pos] == '\\' {
+			p.pos++
+		}
+		p.pos++
+	}
+	if p.pos >= len(p.src) {
+		return nil, fmt.Errorf("unterminated string")
+	}
+	p.pos++
+
+	value, err := strconv.Unquote(p.src[start:p.pos])
+	if err != nil {
+		return nil, fmt.Errorf("invalid string %s", p.src[start:p.pos])
+	}
+	return value, nil
+}
+
+func (p *valueParser) parseNumber() (interface{}, error) {
+	start := p.pos
+	for p.pos < len(p.src) && strings.IndexByte("+-0123456789._eExob", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+	text := strings.ReplaceAll(p.src[start:p.pos], "_", "")
+	if text == "" {
+		return nil, fmt.Errorf("invalid value %q", p.src[start:])
+	}
+
+	if i, err := strconv.ParseInt(text, 0, 64); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil {
+		return f, nil
+	}
+	return nil, fmt.Errorf("invalid number %s", text)
+}
+
+func (p *valueParser) parseArray() (interface{}, error) {
+	p.pos++
+	values := []interface{}{}
+
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nil, fmt.Errorf("unterminated array")
+		}
+		if p.src[p.pos] == ']' {
+			p.pos++
+			return values, nil
+		}
+
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		p.skipSpace()
+		if p.pos < len(p.src) && p.src[p.pos] == ',' {
+			p.pos++
+		}
+	}
+}
+
+func (p *valueParser) parseInlineTable() (interface{}, error) {
+	p.pos++
+	table := Table{}
+
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nil, fmt.Errorf("unterminated inline table")
+		}
+		if p.src[p.pos] == '}' {
+			p.pos++
+			return table, nil
+		}
+
+		eq := strings.IndexByte(p.src[p.pos:], '=')
+		if eq < 0 {
+			return nil, fmt.Errorf("expected '=' in inline table")
+		}
+		key := unquoteKey(strings.TrimSpace(p.src[p.pos : p.pos+eq]))
+		p.pos += eq + 1
+
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		table[key] = value
+
+		p.skipSpace()
+		if p.pos < len(p.src) && p.src[p.pos] == ',' {
+			p.pos++
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package typechecker
+
+import (
+	"strings"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
+)
+
+// arrayType is the type of an array whose element type is not known, such
+// as the empty array literal or a parameter declared as array. It fits any
+// array type, and any array type fits it.
+const arrayType = "array"
+
+// arrayTypeOf returns the element type of an array type such as [int]
+func arrayTypeOf(typeName string) (string, bool) {
+	if !strings.HasPrefix(typeName, "[") || !strings.HasSuffix(typeName, "]") {
+		return "", false
+	}
+	return typeName[1 : len(typeName)-1], true
+}
+
+// isArray reports whether typeName is an array type, with or without a
+// known element type
+func isArray(typeName string) bool {
+	_, ok := arrayTypeOf(typeName)
+	return ok || typeName == arrayType
+}
+
+// arrayFunctions are the builtins that change the length of an array, the
+// first argument
+var arrayFunctions = map[string]bool{"push": true, "pop": true, "insert": true, "removeAt": true}
+
+// checkArrayCall checks a call of one of the arrayFunctions
+func (t *TypeChecker) checkArrayCall(name string, args []ast.Expression) (string, error) {
+	count := map[string]int{"push": 2, "pop": 1, "insert": 3, "removeAt": 2}[name]
+	if len(args) != count {
+		return "", errcode.Errorf("BRN0308", "function %s expects %d arguments but got %d", name, count, len(args))
+	}
+
+	switch target := args[0].(type) {
+	case *ast.VariableExpression:
+		if t.isConst(target.Name) {
+			return "", errcode.Errorf("BRN0313", "function %s cannot change constant %s", name, target.Name)
+		}
+	case *ast.GetExpression:
+		if err := t.checkConstField(target.Object, target.Name); err != nil {
+			return "", err
+		}
+	}
+	targetType, err := t.checkExpression(args[0])
+	if err != nil {
+		return "", err
+	}
+	if !isArray(targetType) {
+		return "", errcode.Errorf("BRN0307", "argument 1 of function %s expects an array but got %s", name, targetType)
+	}
+	elemType := t.elementType(args[0], targetType)
+
+	rest := args[1:]
+	if name == "insert" || name == "removeAt" {
+		indexType, err := t.checkExpression(rest[0])
+		if err != nil {
+			return "", err
+		}
+		if indexType != "int" {
+			return "", errcode.Errorf("BRN0307", "argument 2 of function %s expects int but got %s", name, indexType)
+		}
+		rest = rest[1:]
+	}
+	if name == "pop" || name == "removeAt" {
+		return elemType, nil
+	}
+
+	valueType, err := t.checkExpression(rest[0])
+	if err != nil {
+		return "", err
+	}
+	if targetType != arrayType && !t.assignable(elemType, valueType) {
+		return "", errcode.Errorf("BRN0306", "cannot store %s in an array of type %s", valueType, targetType)
+	}
+	return "", nil
+}
+
+// arrayCallbacks are the builtins that go through the elements of an
+// array, calling a function for them except for sort
+var arrayCallbacks = map[string]bool{
+	"map": true, "filter": true, "reduce": true,
+	"find": true, "some": true, "every": true,
+	"sort": true, "sortBy": true,
+}
+
+// checkArrayCallback checks a call of one of the arrayCallbacks. The
+// function must take the elements of the array, two at a time for sortBy,
+// and for reduce the value carried from element to element before them.
+func (t *TypeChecker) checkArrayCallback(name string, args []ast.Expression) (string, error) {
+	count := 2
+	switch name {
+	case "reduce":
+		count = 3
+	case "sort":
+		count = 1
+	}
+	if len(args) != count {
+		return "", errcode.Errorf("BRN0308", "function %s expects %d arguments but got %d", name, count, len(args))
+	}
+
+	targetType, err := t.checkExpression(args[0])
+	if err != nil {
+		return "", err
+	}
+	if !isArray(targetType) {
+		return "", errcode.Errorf("BRN0307", "argument 1 of function %s expects an array but got %s", name, targetType)
+	}
+	if name == "sort" {
+		elemType, _ := arrayTypeOf(targetType)
+		switch elemType {
+		case "", "int", "float", "string":
+			return targetType, nil
+		}
+		return "", errcode.Errorf("BRN0307", "function sort expects an array of numbers or strings but got %s; use sortBy", targetType)
+	}
+	fnType, err := t.checkExpression(args[1])
+	if err != nil {
+		return "", err
+	}
+	fn, ok := functionTypeOf(fnType)
+	if !ok {
+		return "", errcode.Errorf("BRN0307", "argument 2 of function %s expects a function but got %s", name, fnType)
+	}
+
+	params := []string{t.elementType(args[0], targetType)}
+	if targetType == arrayType {
+		params[0] = ""
+	}
+	switch name {
+	case "sortBy":
+		params = append(params, params[0])
+	case "reduce":
+		initialType, err := t.checkExpression(args[2])
+		if err != nil {
+			return "", err
+		}
+		params = []string{initialType, params[0]}
+	}
+	if len(fn.Parameters) != len(params) {
+		return "", errcode.Errorf("BRN0307", "function given to %s must take %d arguments but takes %d", name, len(params), len(fn.Parameters))
+	}
+	for j, param := range params {
+		if param != "" && !t.assignable(fn.Parameters[j], param) {
+			return "", errcode.Errorf("BRN0307", "function given to %s must take %s as argument %d but takes %s", name, param, j+1, fn.Parameters[j])
+		}
+	}
+	elemType := fn.Parameters[len(fn.Parameters)-1]
+
+	switch name {
+	case "map":
+		if fn.ReturnType == "" || fn.ReturnType == "void" {
+			return "", errcode.Errorf("BRN0307", "function given to map must return a value")
+		}
+		return "[" + fn.ReturnType + "]", nil
+	case "sortBy":
+		if fn.ReturnType != "int" && fn.ReturnType != "float" {
+			return "", errcode.Errorf("BRN0307", "function given to sortBy must return a number but returns %s", fn.ReturnType)
+		}
+		return targetType, nil
+	case "reduce":
+		if !t.assignable(fn.Parameters[0], fn.ReturnType) {
+			return "", errcode.Errorf("BRN0307", "function given to reduce must return %s but returns %s", fn.Parameters[0], fn.ReturnType)
+		}
+		return fn.Parameters[0], nil
+	}
+	if fn.ReturnType != "bool" {
+		return "", errcode.Errorf("BRN0307", "function given to %s must return bool but returns %s", name, fn.ReturnType)
+	}
+	switch name {
+	case "filter":
+		if targetType == arrayType {
+			return "[" + elemType + "]", nil
+		}
+		return targetType, nil
+	case "find":
+		return optionType + "<" + elemType + ">", nil
+	}
+	return "bool", nil
+}
@@ -0,0 +1,85 @@
+package typechecker
+
+import (
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
+)
+
+// checkCastExpression checks expr as Type, whose type is Type. The
+// interpreter checks that the value fits when the cast narrows it, as from
+// any or from an interface to a class implementing it.
+func (t *TypeChecker) checkCastExpression(expr *ast.CastExpression) (string, error) {
+	fromType, err := t.checkExpression(expr.Expression)
+	if err != nil {
+		return "", err
+	}
+	t.setErrorAt(expr)
+	if !t.castTarget(expr.TargetType) {
+		return "", errcode.Errorf("BRN0322", "cannot cast to %s", expr.TargetType)
+	}
+	if !t.castable(fromType, expr.TargetType) {
+		return "", errcode.Errorf("BRN0322", "cannot cast %s to %s", fromType, expr.TargetType)
+	}
+	return expr.TargetType, nil
+}
+
+// castTarget reports whether a value can be cast to typeName: a number,
+// string, bool, bytes or any, a type, class or interface, or an array or
+// map of one of these
+func (t *TypeChecker) castTarget(typeName string) bool {
+	if baseType, ok := optionalTypeOf(typeName); ok {
+		return t.castTarget(baseType)
+	}
+	if elemType, ok := arrayTypeOf(typeName); ok {
+		return t.castTarget(elemType)
+	}
+	if keyType, valueType, ok := mapTypeOf(typeName); ok {
+		return isMapKeyType(keyType) && t.castTarget(valueType)
+	}
+	if _, exists := t.fieldsOf(typeName); exists {
+		return true
+	}
+	return isBuiltinType(typeName) && typeName != "void"
+}
+
+// castable reports whether a value of type from can be cast to type to
+func (t *TypeChecker) castable(from, to string) bool {
+	numeric := func(typeName string) bool {
+		switch typeName {
+		case "int", "float", "bigint", "decimal":
+			return true
+		}
+		return false
+	}
+
+	switch {
+	case from == "any" || to == "any" || t.assignable(to, from):
+		return true
+	case numeric(to):
+		return numeric(from) || from == "string"
+	case to == "string":
+		return numeric(from) || from == "bool" || t.hasToString(from)
+	case t.implementations[to][from]:
+		return true
+	}
+
+	// nil fits an optional type, and a value of one is cast as the type
+	// without nil when it is not nil
+	toBase, toOptional := optionalTypeOf(to)
+	fromBase, fromOptional := optionalTypeOf(from)
+	switch {
+	case toOptional:
+		return from == nilType || t.castable(fromBase, toBase)
+	case fromOptional:
+		return t.castable(fromBase, to)
+	}
+
+	fromElem, fromArray := arrayTypeOf(from)
+	toElem, toArray := arrayTypeOf(to)
+	if fromArray && toArray {
+		return t.castable(fromElem, toElem)
+	}
+	fromKey, fromValue, fromMap := mapTypeOf(from)
+	toKey, toValue, toMap := mapTypeOf(to)
+	return fromMap && toMap && fromKey == toKey && t.castable(fromValue, toValue)
+}
@@ -0,0 +1,31 @@
+package typechecker_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompareIncomparableTypes(t *testing.T) {
+	for _, source := range []string{
+		"var r = 1..3\nvar same = r == r",
+		"var f = fun(): int { return 1 }\nvar same = f != f",
+		"fun half(n: int): Result<int> { return Ok(n / 2) }\nvar same = half(2) == half(2)",
+		"var fs = [fun(): int { return 1 }]\nvar same = fs == fs",
+	} {
+		_, errs := check(t, source)
+		if len(errs) == 0 || !strings.Contains(errs[0].Error(), "cannot be applied") {
+			t.Errorf("%q: got %v, want an error", source, errs)
+		}
+	}
+}
+
+func TestCompareMaps(t *testing.T) {
+	_, errs := check(t, `
+var a = {"x": [1]}
+var b = {"x": [2]}
+var same = a == b
+`)
+	if len(errs) > 0 {
+		t.Errorf("unexpected error: %v", errs[0])
+	}
+}
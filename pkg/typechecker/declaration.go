@@ -2,9 +2,13 @@ package typechecker
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
+	"github.com/burnlang/burn/pkg/suggest"
 )
 
 func (t *TypeChecker) checkDeclaration(decl ast.Declaration) error {
@@ -15,6 +19,9 @@ func (t *TypeChecker) checkDeclaration(decl ast.Declaration) error {
 		}
 		return t.checkVarDeclaration(d)
 	case *ast.FunctionDeclaration:
+		if t.currentFn != "" {
+			return t.checkNestedFunction(d)
+		}
 		return t.checkFunctionDeclaration(d)
 	case *ast.ExpressionStatement:
 		_, err := t.checkExpression(d.Expression)
@@ -29,6 +36,9 @@ func (t *TypeChecker) checkDeclaration(decl ast.Declaration) error {
 		return nil
 	case *ast.ClassDeclaration:
 		return t.checkClassDeclaration(d)
+	case *ast.InterfaceDeclaration:
+		// Interfaces are registered with the types
+		return nil
 	case *ast.ReturnStatement:
 		return t.checkReturnStatement(d)
 	case *ast.IfStatement:
@@ -37,32 +47,56 @@ func (t *TypeChecker) checkDeclaration(decl ast.Declaration) error {
 		return t.checkWhileStatement(d)
 	case *ast.ForStatement:
 		return t.checkForStatement(d)
+	case *ast.ForInStatement:
+		return t.checkForInStatement(d)
 	case *ast.BlockStatement:
 		return t.checkBlockStatement(d)
+	case *ast.MatchStatement:
+		return t.checkMatchStatement(d)
+	case *ast.BreakStatement:
+		t.setErrorAt(d)
+		return t.checkLoopControl("break", d.Label)
+	case *ast.ContinueStatement:
+		t.setErrorAt(d)
+		return t.checkLoopControl("continue", d.Label)
 	default:
-		return fmt.Errorf("unknown declaration type: %T", decl)
+		return errcode.Errorf("BRN0332", "unknown declaration type: %T", decl)
 	}
 }
 
 func (t *TypeChecker) checkVarDeclaration(decl *ast.VariableDeclaration) error {
-	t.setErrorPos(decl.Pos())
+	t.setErrorAt(decl)
+	annotated := decl.Type
 
 	if decl.Value != nil {
+		t.expectType(decl.Value, decl.Type)
 		valueType, err := t.checkExpression(decl.Value)
 		if err != nil {
 			return err
 		}
+		t.setErrorAt(decl)
 
-		if decl.Type != "" && valueType != decl.Type {
-			return fmt.Errorf("variable type %s does not match initializer type %s", decl.Type, valueType)
+		if decl.Type != "" && !t.assignable(decl.Type, valueType) {
+			return errcode.Errorf("BRN0306", "variable type %s does not match initializer type %s", decl.Type, valueType)
 		}
 
 		if decl.Type == "" {
+			if valueType == emptyMapType {
+				return errcode.Errorf("BRN0316", "variable %s needs a map type for the empty map, as in %s: map<string, int>", decl.Name, decl.Name)
+			}
+			if valueType == nilType {
+				return errcode.Errorf("BRN0316", "variable %s needs a type to be nil, as in %s: Point = nil", decl.Name, decl.Name)
+			}
+			if valueType == resultType || valueType == optionType {
+				return errcode.Errorf("BRN0316", "variable %s needs a type for the value of its %s, as in %s: %s<int>", decl.Name, valueType, decl.Name, valueType)
+			}
 			decl.Type = valueType
 		}
 
-		if arrayLiteral, ok := decl.Value.(*ast.ArrayLiteralExpression); ok && len(arrayLiteral.Elements) > 0 {
-			elemType, err := t.checkExpression(arrayLiteral.Elements[0])
+		if arrayLiteral, ok := decl.Value.(*ast.ArrayLiteralExpression); ok && arrayLiteral.ElementType != "" {
+			t.arrayTypes[decl.Name] = arrayLiteral.ElementType
+		} else if ok && len(arrayLiteral.Elements) > 0 {
+			elemType, err := t.checkArrayElement(arrayLiteral.Elements[0])
 			if err != nil {
 				return err
 			}
@@ -71,31 +105,50 @@ func (t *TypeChecker) checkVarDeclaration(decl *ast.VariableDeclaration) error {
 	}
 
 	if decl.Type == "" {
-		return fmt.Errorf("variable %s must have a type or an initializer", decl.Name)
+		return errcode.Errorf("BRN0316", "variable %s must have a type or an initializer", decl.Name)
+	}
+	if err := checkMapType(decl.Type); err != nil {
+		return err
 	}
 
 	if _, exists := t.variables[decl.Name]; exists {
-		return fmt.Errorf("variable %s is already defined", decl.Name)
+		return errcode.Errorf("BRN0314", "variable %s is already defined", decl.Name)
+	}
+	if err := t.declareLocal(decl, annotated); err != nil {
+		return err
 	}
 
 	t.variables[decl.Name] = decl.Type
+	delete(t.consts, decl.Name)
 	return nil
 }
 
 func (t *TypeChecker) checkConstDeclaration(decl *ast.VariableDeclaration) error {
-	t.setErrorPos(decl.Pos())
+	t.setErrorAt(decl)
+	annotated := decl.Type
 
 	if decl.Value == nil {
-		return fmt.Errorf("constant %s must have an initializer", decl.Name)
+		return errcode.Errorf("BRN0316", "constant %s must have an initializer", decl.Name)
 	}
 
+	t.expectType(decl.Value, decl.Type)
 	valueType, err := t.checkExpression(decl.Value)
 	if err != nil {
 		return err
 	}
+	t.setErrorAt(decl)
 
-	if decl.Type != "" && valueType != decl.Type {
-		return fmt.Errorf("constant type %s does not match initializer type %s", decl.Type, valueType)
+	if decl.Type != "" && !t.assignable(decl.Type, valueType) {
+		return errcode.Errorf("BRN0306", "constant type %s does not match initializer type %s", decl.Type, valueType)
+	}
+	if decl.Type == "" && valueType == emptyMapType {
+		return errcode.Errorf("BRN0316", "constant %s needs a map type for the empty map, as in %s: map<string, int>", decl.Name, decl.Name)
+	}
+	if decl.Type == "" && valueType == nilType {
+		return errcode.Errorf("BRN0316", "constant %s needs a type to be nil, as in %s: Point = nil", decl.Name, decl.Name)
+	}
+	if decl.Type == "" && (valueType == resultType || valueType == optionType) {
+		return errcode.Errorf("BRN0316", "constant %s needs a type for the value of its %s, as in %s: %s<int>", decl.Name, valueType, decl.Name, valueType)
 	}
 
 	if decl.Type == "" {
@@ -103,15 +156,25 @@ func (t *TypeChecker) checkConstDeclaration(decl *ast.VariableDeclaration) error
 	}
 
 	if _, exists := t.variables[decl.Name]; exists {
-		return fmt.Errorf("constant %s is already defined", decl.Name)
+		return errcode.Errorf("BRN0314", "constant %s is already defined", decl.Name)
+	}
+	if err := t.declareLocal(decl, annotated); err != nil {
+		return err
 	}
 
 	t.variables[decl.Name] = decl.Type
+	t.consts[decl.Name] = true
 	return nil
 }
 
+// isConst reports whether name is a constant of the current scope
+func (t *TypeChecker) isConst(name string) bool {
+	_, exists := t.variables[name]
+	return exists && t.consts[name]
+}
+
 func (t *TypeChecker) checkFunctionDeclaration(decl *ast.FunctionDeclaration) error {
-	t.setErrorPos(decl.Pos())
+	t.setErrorAt(decl)
 
 	prevVars := make(map[string]string)
 	for k, v := range t.variables {
@@ -119,22 +182,51 @@ func (t *TypeChecker) checkFunctionDeclaration(decl *ast.FunctionDeclaration) er
 	}
 	prevFn := t.currentFn
 
-	t.currentFn = decl.Name
-	t.variables = make(map[string]string)
+	// Methods are known by their type and name, as class methods are
+	name := decl.Name
+	if decl.Receiver != "" {
+		name = decl.Receiver + "." + decl.Name
+	}
+	t.currentFn = name
+	t.variables = t.functionScope()
+	prevClass, prevNarrowed, prevConsts := t.currentClass, t.narrowed, t.consts
+	if decl.Receiver != "" {
+		t.variables["this"] = decl.Receiver
+		t.currentClass = decl.Receiver
+	}
+	t.narrowed = make(map[string]string)
+	t.consts = t.constScope()
+	defer func() { t.currentClass, t.narrowed, t.consts = prevClass, prevNarrowed, prevConsts }()
 
 	for _, param := range decl.Parameters {
-		t.variables[param.Name] = param.Type
+		t.variables[param.Name] = parameterType(param.Type)
+		delete(t.consts, param.Name)
+	}
+	if err := t.beginFunction(decl, prevVars); err != nil {
+		return fmt.Errorf("in function %s: %w", name, err)
 	}
 
+	failed := false
 	for _, stmt := range decl.Body {
 		if err := t.checkDeclaration(stmt); err != nil {
-			return fmt.Errorf("in function %s: %w", decl.Name, err)
+			err = fmt.Errorf("in function %s: %w", name, err)
+			if t.report == nil {
+				return err
+			}
+			t.report(err)
+			t.markUsedIn(stmt)
+			t.declareFailed(stmt)
+			failed = true
 		}
 	}
+	if err := t.endFunction(); err != nil {
+		return fmt.Errorf("in function %s: %w", name, err)
+	}
 
-	if decl.ReturnType != "" && decl.ReturnType != "void" {
+	// A return statement that failed to check may be what is missing
+	if !failed && decl.ReturnType != "" && decl.ReturnType != "void" {
 		if !t.functionHasValidReturn(decl.Body, decl.ReturnType) {
-			return fmt.Errorf("function %s must return a value of type %s", decl.Name, decl.ReturnType)
+			return errcode.Errorf("BRN0311", "function %s must return a value of type %s", name, decl.ReturnType)
 		}
 	}
 
@@ -144,6 +236,103 @@ func (t *TypeChecker) checkFunctionDeclaration(decl *ast.FunctionDeclaration) er
 	return nil
 }
 
+// declareFailed declares the variable of a declaration that failed to
+// check with the type it declares, or any, so that its uses do not cause
+// further errors
+func (t *TypeChecker) declareFailed(decl ast.Declaration) {
+	if v, ok := decl.(*ast.VariableDeclaration); ok {
+		if _, exists := t.variables[v.Name]; !exists {
+			t.variables[v.Name] = "any"
+			if v.Type != "" {
+				t.variables[v.Name] = v.Type
+			}
+		}
+	}
+}
+
+// checkNestedFunction checks a function declared inside another, which is
+// a variable of its function type in the scope it is declared in
+func (t *TypeChecker) checkNestedFunction(decl *ast.FunctionDeclaration) error {
+	t.setErrorAt(decl)
+
+	if _, exists := t.variables[decl.Name]; exists {
+		return errcode.Errorf("BRN0314", "variable %s is already defined", decl.Name)
+	}
+	t.variables[decl.Name] = closureType(decl).String()
+	delete(t.consts, decl.Name)
+
+	_, err := t.checkClosure(decl, "function "+decl.Name)
+	return err
+}
+
+// closureType returns the function type of a lambda or nested function,
+// whose return type defaults to void
+func closureType(decl *ast.FunctionDeclaration) FunctionType {
+	fn := FunctionType{Parameters: make([]string, len(decl.Parameters)), ReturnType: decl.ReturnType}
+	for j, param := range decl.Parameters {
+		fn.Parameters[j] = param.Type
+	}
+	if fn.ReturnType == "" {
+		fn.ReturnType = "void"
+	}
+	return fn
+}
+
+// checkClosure checks the body of a lambda or nested function, described
+// by what in errors. Unlike a top-level function, the body sees the
+// variables of the scope it is declared in.
+func (t *TypeChecker) checkClosure(decl *ast.FunctionDeclaration, what string) (FunctionType, error) {
+	fn := closureType(decl)
+
+	prevVars, prevFn, prevReturn := t.variables, t.currentFn, t.closureReturn
+	prevLocals, prevLocalsByName, prevOuter := t.locals, t.localsByName, t.outerVars
+	prevEnclosing, prevLoops, prevNarrowed := t.enclosingLocals, t.loops, t.narrowed
+	prevConsts := t.consts
+	defer func() {
+		t.variables, t.currentFn, t.closureReturn = prevVars, prevFn, prevReturn
+		t.locals, t.localsByName, t.outerVars = prevLocals, prevLocalsByName, prevOuter
+		t.enclosingLocals, t.loops, t.narrowed = prevEnclosing, prevLoops, prevNarrowed
+		t.consts = prevConsts
+	}()
+
+	// A closure can run after the variables it sees are set to nil, so
+	// the nil checks around it do not apply in it
+	t.narrowed = make(map[string]string)
+
+	if t.localsByName != nil {
+		t.enclosingLocals = append(t.enclosingLocals, t.localsByName)
+	}
+	t.currentFn, t.closureReturn = closureFn, fn.ReturnType
+	t.loops = nil
+	t.variables = make(map[string]string, len(prevVars)+len(decl.Parameters))
+	for name, typeName := range prevVars {
+		t.variables[name] = typeName
+	}
+	t.consts = maps.Clone(prevConsts)
+	for _, param := range decl.Parameters {
+		t.variables[param.Name] = parameterType(param.Type)
+		delete(t.consts, param.Name)
+	}
+	if err := t.beginFunction(decl, prevVars); err != nil {
+		return fn, fmt.Errorf("in %s: %w", what, err)
+	}
+
+	for _, stmt := range decl.Body {
+		if err := t.checkDeclaration(stmt); err != nil {
+			return fn, fmt.Errorf("in %s: %w", what, err)
+		}
+	}
+	if err := t.endFunction(); err != nil {
+		return fn, fmt.Errorf("in %s: %w", what, err)
+	}
+
+	if fn.ReturnType != "void" && !t.functionHasValidReturn(decl.Body, fn.ReturnType) {
+		return fn, errcode.Errorf("BRN0311", "%s must return a value of type %s", what, fn.ReturnType)
+	}
+
+	return fn, nil
+}
+
 func (t *TypeChecker) functionHasValidReturn(body []ast.Declaration, expectedType string) bool {
 	for _, stmt := range body {
 		if ret, ok := stmt.(*ast.ReturnStatement); ok {
@@ -151,8 +340,16 @@ func (t *TypeChecker) functionHasValidReturn(body []ast.Declaration, expectedTyp
 				return false
 			}
 
-			valueType, err := t.checkExpression(ret.Value)
-			if err != nil || valueType != expectedType {
+			// The type found when the body was checked holds the nil
+			// checks around the return
+			valueType, checked := t.exprTypes[ret.Value]
+			if !checked {
+				var err error
+				if valueType, err = t.checkExpression(ret.Value); err != nil {
+					return false
+				}
+			}
+			if !t.assignable(expectedType, valueType) {
 				return false
 			}
 
@@ -172,31 +369,66 @@ func (t *TypeChecker) functionHasValidReturn(body []ast.Declaration, expectedTyp
 				}
 			}
 		}
+
+		// A match returns when it has a default case and every case
+		// returns
+		if matchStmt, ok := stmt.(*ast.MatchStatement); ok {
+			returns := false
+			for _, matchCase := range matchStmt.Cases {
+				if !t.functionHasValidReturn(matchCase.Body, expectedType) {
+					returns = false
+					break
+				}
+				returns = returns || matchCase.IsDefault()
+			}
+			if returns {
+				return true
+			}
+		}
 	}
 
 	return false
 }
 
 func (t *TypeChecker) checkTypeDefinition(decl *ast.TypeDefinition) error {
-	t.setErrorPos(decl.Pos())
+	t.setErrorAt(decl)
 
 	fields := make(map[string]string)
 	for _, field := range decl.Fields {
-		if !isBuiltinType(field.Type) && field.Type != decl.Name {
-			if _, exists := t.types[field.Type]; !exists {
-				return fmt.Errorf("unknown type %s for field %s", field.Type, field.Name)
+		// A field of an optional or generic type needs the type it is
+		// built on, as Node for Node? or Box for Box<int>
+		fieldType, _ := optionalTypeOf(field.Type)
+		_, isFunction := functionTypeOf(fieldType)
+		_, _, isMap := mapTypeOf(fieldType)
+		_, _, isWrapper := wrapperTypeOf(fieldType)
+		if name, _, isGeneric := genericTypeOf(fieldType); isGeneric {
+			fieldType = name
+		}
+		if !isBuiltinType(fieldType) && !isFunction && !isMap && !isWrapper && !isArray(fieldType) &&
+			fieldType != decl.Name && !slices.Contains(decl.TypeParameters, fieldType) {
+			if _, exists := t.types[fieldType]; !exists {
+				return errcode.Errorf("BRN0303", "unknown type %s for field %s%s", field.Type, field.Name,
+					suggest.Hint(fieldType, maps.Keys(t.types)))
 			}
 		}
+		t.checkAnyType(field.Type, fmt.Sprintf("field %s of %s", field.Name, decl.Name), decl)
 		fields[field.Name] = field.Type
 	}
 	t.types[decl.Name] = fields
+	if len(decl.TypeParameters) > 0 {
+		t.typeParameters[decl.Name] = decl.TypeParameters
+	}
 
 	return nil
 }
 
+// closureFn is the currentFn of the body of a lambda or nested function,
+// which cannot clash with the name of a function since fun is a keyword
+const closureFn = "fun"
+
 func isBuiltinType(typeName string) bool {
 	switch typeName {
-	case "int", "float", "string", "bool", "void", "any":
+	case "int", "float", "bigint", "decimal", "string", "bool", "bytes", "void", "any":
 		return true
 	default:
 		return false
@@ -204,26 +436,49 @@ func isBuiltinType(typeName string) bool {
 }
 
 func (t *TypeChecker) checkClassDeclaration(decl *ast.ClassDeclaration) error {
-	t.setErrorPos(decl.Pos())
+	t.setErrorAt(decl)
+
+	prevClass := t.currentClass
+	t.currentClass = decl.Name
+	defer func() { t.currentClass = prevClass }()
 
 	if _, exists := t.types[decl.Name]; !exists {
 		t.types[decl.Name] = make(map[string]string)
 	}
+	if err := t.checkFields(decl); err != nil {
+		return err
+	}
+	if err := t.checkStatics(decl); err != nil {
+		return err
+	}
+	if err := t.checkImplements(decl); err != nil {
+		return err
+	}
 
 	for _, method := range decl.Methods {
 		prevVars := make(map[string]string)
 		for k, v := range t.variables {
 			prevVars[k] = v
 		}
-		prevFn := t.currentFn
+		prevFn, prevNarrowed, prevConsts := t.currentFn, t.narrowed, t.consts
 
 		t.currentFn = decl.Name + "." + method.Name
-		t.variables = make(map[string]string)
-
-		t.variables["this"] = decl.Name
+		t.variables = t.functionScope()
+		t.narrowed = make(map[string]string)
+		t.consts = t.constScope()
+
+		// this is the instance an instance method is called on, which it
+		// takes as its first parameter
+		if len(method.Parameters) > 0 && method.Parameters[0].Type == decl.Name {
+			t.variables["this"] = decl.Name
+		}
 
 		for _, param := range method.Parameters {
-			t.variables[param.Name] = param.Type
+			t.variables[param.Name] = parameterType(param.Type)
+			delete(t.consts, param.Name)
+		}
+		if err := t.beginFunction(method, prevVars); err != nil {
+			return fmt.Errorf("in method %s.%s: %w", decl.Name, method.Name, err)
 		}
 
 		for _, stmt := range method.Body {
@@ -231,16 +486,21 @@ func (t *TypeChecker) checkClassDeclaration(decl *ast.ClassDeclaration) error {
 				return fmt.Errorf("in method %s.%s: %w", decl.Name, method.Name, err)
 			}
 		}
+		if err := t.endFunction(); err != nil {
+			return fmt.Errorf("in method %s.%s: %w", decl.Name, method.Name, err)
+		}
 
 		if method.ReturnType != "" && method.ReturnType != "void" {
 			if !t.functionHasValidReturn(method.Body, method.ReturnType) {
-				return fmt.Errorf("method %s.%s must return a value of type %s",
+				return errcode.Errorf("BRN0311", "method %s.%s must return a value of type %s",
 					decl.Name, method.Name, method.ReturnType)
 			}
 		}
 
 		t.variables = prevVars
 		t.currentFn = prevFn
+		t.narrowed = prevNarrowed
+		t.consts = prevConsts
 	}
 
 	for _, method := range decl.StaticMethods {
@@ -248,13 +508,19 @@ func (t *TypeChecker) checkClassDeclaration(decl *ast.ClassDeclaration) error {
 		for k, v := range t.variables {
 			prevVars[k] = v
 		}
-		prevFn := t.currentFn
+		prevFn, prevNarrowed, prevConsts := t.currentFn, t.narrowed, t.consts
 
 		t.currentFn = decl.Name + ".static." + method.Name
-		t.variables = make(map[string]string)
+		t.variables = t.functionScope()
+		t.narrowed = make(map[string]string)
+		t.consts = t.constScope()
 
 		for _, param := range method.Parameters {
-			t.variables[param.Name] = param.Type
+			t.variables[param.Name] = parameterType(param.Type)
+			delete(t.consts, param.Name)
+		}
+		if err := t.beginFunction(method, prevVars); err != nil {
+			return fmt.Errorf("in static method %s.%s: %w", decl.Name, method.Name, err)
 		}
 
 		for _, stmt := range method.Body {
@@ -262,30 +528,135 @@ func (t *TypeChecker) checkClassDeclaration(decl *ast.ClassDeclaration) error {
 				return fmt.Errorf("in static method %s.%s: %w", decl.Name, method.Name, err)
 			}
 		}
+		if err := t.endFunction(); err != nil {
+			return fmt.Errorf("in static method %s.%s: %w", decl.Name, method.Name, err)
+		}
 
 		if method.ReturnType != "" && method.ReturnType != "void" {
 			if !t.functionHasValidReturn(method.Body, method.ReturnType) {
-				return fmt.Errorf("static method %s.%s must return a value of type %s",
+				return errcode.Errorf("BRN0311", "static method %s.%s must return a value of type %s",
 					decl.Name, method.Name, method.ReturnType)
 			}
 		}
 
 		t.variables = prevVars
 		t.currentFn = prevFn
+		t.narrowed = prevNarrowed
+		t.consts = prevConsts
+	}
+
+	return nil
+}
+
+// checkFields checks the fields and the init method of a class. Defaults
+// are checked like the body of a function, and a field without one starts
+// at the zero value of its type, or nil.
+func (t *TypeChecker) checkFields(decl *ast.ClassDeclaration) error {
+	prevVars := t.variables
+	t.variables = t.functionScope()
+	defer func() { t.variables = prevVars }()
+
+	for _, field := range decl.Fields {
+		t.setErrorAt(field)
+		if field.Value == nil {
+			if !hasZeroValue(field.Type) {
+				return errcode.Errorf("BRN0330", "field %s of class %s needs a default value of type %s", field.Name, decl.Name, field.Type)
+			}
+			continue
+		}
+		valueType, err := t.checkExpression(field.Value)
+		if err != nil {
+			return err
+		}
+		if field.Type != "any" && !t.assignable(field.Type, valueType) {
+			return errcode.Errorf("BRN0306", "field %s of class %s has type %s but its default has type %s",
+				field.Name, decl.Name, field.Type, valueType)
+		}
 	}
 
+	init, exists := t.classes[decl.Name]["init"]
+	if !exists {
+		return nil
+	}
+	t.setErrorAt(decl)
+	if len(init.Parameters) == 0 || init.Parameters[0] != decl.Name {
+		return errcode.Errorf("BRN0330", "method init of class %s must take the new %s as its first parameter", decl.Name, decl.Name)
+	}
+	if init.ReturnType != "" && init.ReturnType != "void" {
+		return errcode.Errorf("BRN0312", "method init of class %s cannot return a value", decl.Name)
+	}
 	return nil
 }
 
+// checkStatics checks the static fields of a class, whose values are
+// checked like the body of a function, and records their types. Static
+// fields are known from the declaration of the class on, as constants are.
+func (t *TypeChecker) checkStatics(decl *ast.ClassDeclaration) error {
+	prevVars := t.variables
+	t.variables = t.functionScope()
+	defer func() { t.variables = prevVars }()
+
+	statics := make(map[string]string, len(decl.StaticFields))
+	for _, field := range decl.StaticFields {
+		t.setErrorAt(field)
+		if _, exists := statics[field.Name]; exists {
+			return errcode.Errorf("BRN0314", "static field %s is already defined in class %s", field.Name, decl.Name)
+		}
+		if _, exists := t.types[decl.Name][field.Name]; exists {
+			return errcode.Errorf("BRN0330", "static field %s of class %s has the name of a field", field.Name, decl.Name)
+		}
+
+		valueType, err := t.checkExpression(field.Value)
+		if err != nil {
+			return err
+		}
+		typeName := field.Type
+		if typeName == "" {
+			if valueType == emptyMapType || valueType == nilType || valueType == resultType || valueType == optionType {
+				return errcode.Errorf("BRN0316", "static field %s of class %s needs a type for its value", field.Name, decl.Name)
+			}
+			typeName = valueType
+		} else if typeName != "any" && !t.assignable(typeName, valueType) {
+			return errcode.Errorf("BRN0306", "static field %s of class %s has type %s but its value has type %s",
+				field.Name, decl.Name, typeName, valueType)
+		}
+		if err := checkMapType(typeName); err != nil {
+			return err
+		}
+
+		statics[field.Name] = typeName
+		if field.IsConst {
+			t.staticConsts[decl.Name+"."+field.Name] = true
+		}
+	}
+	t.statics[decl.Name] = statics
+	return nil
+}
+
+// hasZeroValue reports whether a field of type typeName can do without a
+// default value
+func hasZeroValue(typeName string) bool {
+	switch typeName {
+	case "int", "float", "bigint", "decimal", "string", "bool", "bytes":
+		return true
+	}
+	if kind, _, ok := wrapperTypeOf(typeName); ok {
+		return kind == optionType
+	}
+	return isArray(typeName) || nullable(typeName)
+}
+
 func (t *TypeChecker) checkReturnStatement(stmt *ast.ReturnStatement) error {
-	t.setErrorPos(stmt.Pos())
+	t.setErrorAt(stmt)
 
 	if t.currentFn == "" {
-		return fmt.Errorf("return statement outside of function")
+		return errcode.Errorf("BRN0312", "return statement outside of function")
 	}
 
 	var expectedType string
-	if strings.Contains(t.currentFn, ".") {
+	if t.currentFn == closureFn {
+		expectedType = t.closureReturn
+	} else if strings.Contains(t.currentFn, ".") {
 		parts := strings.Split(t.currentFn, ".")
 
 		if len(parts) == 3 && parts[1] == "static" {
@@ -312,27 +683,28 @@ func (t *TypeChecker) checkReturnStatement(stmt *ast.ReturnStatement) error {
 	}
 
 	if expectedType == "" {
-		return fmt.Errorf("could not determine return type for function %s", t.currentFn)
+		return errcode.Errorf("BRN0311", "could not determine return type for function %s", t.currentFn)
 	}
 
 	if expectedType == "void" {
 		if stmt.Value != nil {
-			return fmt.Errorf("void function cannot return a value")
+			return errcode.Errorf("BRN0312", "void function cannot return a value")
 		}
 		return nil
 	}
 
 	if stmt.Value == nil {
-		return fmt.Errorf("non-void function must return a value")
+		return errcode.Errorf("BRN0311", "non-void function must return a value")
 	}
 
 	actualType, err := t.checkExpression(stmt.Value)
 	if err != nil {
 		return err
 	}
+	t.setErrorAt(stmt)
 
-	if actualType != expectedType {
-		return fmt.Errorf("return type %s does not match expected type %s",
+	if !t.assignable(expectedType, actualType) {
+		return errcode.Errorf("BRN0312", "return type %s does not match expected type %s",
 			actualType, expectedType)
 	}
 
@@ -347,26 +719,151 @@ func (t *TypeChecker) checkIfStatement(stmt *ast.IfStatement) error {
 	}
 
 	if condType != "bool" {
-		return fmt.Errorf("if condition must be a boolean expression, got %s", condType)
+		return errcode.Errorf("BRN0310", "if condition must be a boolean expression, got %s", condType)
 	}
+	t.warnConstantCondition(stmt)
+
+	whenTrue, whenFalse := t.nilChecks(stmt.Condition)
+	thenNotNil, err := t.checkBranch(stmt.ThenBranch, whenTrue)
+	if err != nil {
+		return err
+	}
+	elseNotNil, err := t.checkBranch(stmt.ElseBranch, whenFalse)
+	if err != nil {
+		return err
+	}
+
+	// When one branch always leaves, the code after the if runs only when
+	// the other would, and otherwise after either of them
+	switch thenExits, elseExits := exits(stmt.ThenBranch), exits(stmt.ElseBranch); {
+	case thenExits && !elseExits:
+		t.narrow(elseNotNil)
+	case elseExits && !thenExits:
+		t.narrow(thenNotNil)
+	case !thenExits && !elseExits:
+		t.narrow(intersect(thenNotNil, elseNotNil))
+	}
+
+	return nil
+}
 
-	for _, thenStmt := range stmt.ThenBranch {
-		if err := t.checkDeclaration(thenStmt); err != nil {
+func (t *TypeChecker) checkForInStatement(stmt *ast.ForInStatement) error {
+	t.setErrorAt(stmt)
+
+	var elemType string
+	if r, ok := stmt.Iterable.(*ast.RangeExpression); ok {
+		var err error
+		if elemType, err = t.checkRangeExpression(r); err != nil {
 			return err
 		}
+	} else {
+		iterableType, err := t.checkExpression(stmt.Iterable)
+		if err != nil {
+			return err
+		}
+		keyType, _, isMap := mapTypeOf(iterableType)
+		switch {
+		case isArray(iterableType):
+			elemType = t.elementType(stmt.Iterable, iterableType)
+		case iterableType == "range":
+			elemType = "int"
+		case iterableType == "string":
+			elemType = "string"
+		case iterableType == "bytes":
+			elemType = "int"
+		case isMap:
+			elemType = keyType
+		default:
+			return errcode.Errorf("BRN0324", "cannot iterate over a value of type %s", iterableType)
+		}
+	}
+
+	if _, exists := t.variables[stmt.Variable]; exists {
+		return errcode.Errorf("BRN0314", "variable %s is already defined", stmt.Variable)
+	}
+
+	prevVars := make(map[string]string)
+	for k, v := range t.variables {
+		prevVars[k] = v
+	}
+	t.variables[stmt.Variable] = elemType
+	delete(t.consts, stmt.Variable)
+
+	if err := t.checkLoopBody(stmt.Label, stmt.Body); err != nil {
+		return err
+	}
+
+	t.variables = prevVars
+
+	return nil
+}
+
+func (t *TypeChecker) checkMatchStatement(stmt *ast.MatchStatement) error {
+	t.setErrorAt(stmt)
+
+	valueType, err := t.checkExpression(stmt.Value)
+	if err != nil {
+		return err
 	}
 
-	if len(stmt.ElseBranch) > 0 {
-		for _, elseStmt := range stmt.ElseBranch {
-			if err := t.checkDeclaration(elseStmt); err != nil {
+	for k, matchCase := range stmt.Cases {
+		t.setErrorAt(matchCase)
+		if matchCase.IsDefault() && k < len(stmt.Cases)-1 {
+			return errcode.Errorf("BRN0325", "the default case of a match must be its last case")
+		}
+
+		for _, pattern := range matchCase.Patterns {
+			patternType, err := t.checkExpression(pattern)
+			if err != nil {
 				return err
 			}
+			if valueType == "any" {
+				continue
+			}
+			if _, err := t.checkComparisonOperation("==", valueType, patternType, nil, pattern); err != nil {
+				return errcode.Errorf("BRN0325", "pattern of type %s cannot match a value of type %s", patternType, valueType)
+			}
+		}
+
+		for _, typeName := range matchCase.Types {
+			if !isBuiltinType(typeName) && t.types[typeName] == nil && t.classes[typeName] == nil {
+				return errcode.Errorf("BRN0325", "unknown type in match pattern: %s%s", typeName,
+					suggest.Hint(typeName, maps.Keys(t.types), maps.Keys(t.classes)))
+			}
+			if valueType != "any" && typeName != valueType {
+				return errcode.Errorf("BRN0325", "type pattern %s cannot match a value of type %s", typeName, valueType)
+			}
+		}
+
+		if err := t.checkCaseBody(matchCase.Body); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
+// checkCaseBody checks the body of a case of a match statement, which is a
+// block of its own
+func (t *TypeChecker) checkCaseBody(body []ast.Declaration) error {
+	restore := t.narrowScope()
+	defer restore()
+	return t.checkBlock(body)
+}
+
+// checkBlock checks statements in a block of their own
+func (t *TypeChecker) checkBlock(body []ast.Declaration) error {
+	endScope := t.blockScope()
+	defer endScope()
+
+	for _, bodyStmt := range body {
+		if err := t.checkDeclaration(bodyStmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (t *TypeChecker) checkWhileStatement(stmt *ast.WhileStatement) error {
 
 	condType, err := t.checkExpression(stmt.Condition)
@@ -375,10 +872,35 @@ func (t *TypeChecker) checkWhileStatement(stmt *ast.WhileStatement) error {
 	}
 
 	if condType != "bool" {
-		return fmt.Errorf("while condition must be a boolean expression, got %s", condType)
+		return errcode.Errorf("BRN0310", "while condition must be a boolean expression, got %s", condType)
+	}
+	// while (true) is how a loop that ends with break is written
+	if value, constant := constantCondition(stmt.Condition); constant && !value {
+		t.warn(stmt, "BRN0327", "condition is always false, so the loop never runs")
 	}
 
-	for _, bodyStmt := range stmt.Body {
+	restore := t.narrowScope()
+	defer restore()
+	whenTrue, _ := t.nilChecks(stmt.Condition)
+	t.narrow(whenTrue)
+	return t.checkLoopBody(stmt.Label, stmt.Body)
+}
+
+// checkLoopBody checks the body of a loop with a label, or none, in which
+// break and continue are allowed
+func (t *TypeChecker) checkLoopBody(label string, body []ast.Declaration) error {
+	if label != "" && slices.Contains(t.loops, label) {
+		return errcode.Errorf("BRN0314", "loop label %s is already used by an enclosing loop", label)
+	}
+	t.loops = append(t.loops, label)
+	defer func() { t.loops = t.loops[:len(t.loops)-1] }()
+	// The body may not run, so what it shows is not nil is not known after
+	restore := t.narrowScope()
+	defer restore()
+	endScope := t.blockScope()
+	defer endScope()
+
+	for _, bodyStmt := range body {
 		if err := t.checkDeclaration(bodyStmt); err != nil {
 			return err
 		}
@@ -387,6 +909,18 @@ func (t *TypeChecker) checkWhileStatement(stmt *ast.WhileStatement) error {
 	return nil
 }
 
+// checkLoopControl checks a break or continue, given by keyword, that
+// leaves the loop it is in or the enclosing loop with the label
+func (t *TypeChecker) checkLoopControl(keyword, label string) error {
+	if len(t.loops) == 0 {
+		return errcode.Errorf("BRN0319", "%s statement outside of loop", keyword)
+	}
+	if label != "" && !slices.Contains(t.loops, label) {
+		return errcode.Errorf("BRN0319", "%s %s: no enclosing loop has the label %s", keyword, label, label)
+	}
+	return nil
+}
+
 func (t *TypeChecker) checkForStatement(stmt *ast.ForStatement) error {
 
 	prevVars := make(map[string]string)
@@ -407,7 +941,7 @@ func (t *TypeChecker) checkForStatement(stmt *ast.ForStatement) error {
 		}
 
 		if condType != "bool" {
-			return fmt.Errorf("for condition must be a boolean expression, got %s", condType)
+			return errcode.Errorf("BRN0310", "for condition must be a boolean expression, got %s", condType)
 		}
 	}
 
@@ -418,10 +952,8 @@ func (t *TypeChecker) checkForStatement(stmt *ast.ForStatement) error {
 		}
 	}
 
-	for _, bodyStmt := range stmt.Body {
-		if err := t.checkDeclaration(bodyStmt); err != nil {
-			return err
-		}
+	if err := t.checkLoopBody(stmt.Label, stmt.Body); err != nil {
+		return err
 	}
 
 	t.variables = prevVars
@@ -429,20 +961,21 @@ func (t *TypeChecker) checkForStatement(stmt *ast.ForStatement) error {
 	return nil
 }
 
-func (t *TypeChecker) checkBlockStatement(stmt *ast.BlockStatement) error {
-
-	prevVars := make(map[string]string)
-	for k, v := range t.variables {
-		prevVars[k] = v
-	}
-
-	for _, blockStmt := range stmt.Statements {
-		if err := t.checkDeclaration(blockStmt); err != nil {
-			return err
+// blockScope starts the scope of a block, the body of an if, loop or case
+// or a { } statement, and returns a function that ends it. The variables
+// declared in the block are not seen after it, as at run time.
+func (t *TypeChecker) blockScope() func() {
+	prevVars := maps.Clone(t.variables)
+	return func() {
+		for name := range t.narrowed {
+			if _, exists := prevVars[name]; !exists {
+				delete(t.narrowed, name)
+			}
 		}
+		t.variables = prevVars
 	}
+}
 
-	t.variables = prevVars
-
-	return nil
+func (t *TypeChecker) checkBlockStatement(stmt *ast.BlockStatement) error {
+	return t.checkBlock(stmt.Statements)
 }
@@ -2,13 +2,102 @@ package typechecker
 
 import (
 	"fmt"
+	"iter"
+	"maps"
+	"strings"
 
 	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
+	"github.com/burnlang/burn/pkg/lexer"
+	"github.com/burnlang/burn/pkg/suggest"
 )
 
+// nilType is the type of nil, which fits any nullable type
+const nilType = "nil"
+
+// nullable reports whether nil can stand for a value of type typeName: a
+// struct, a class instance, a map, a function or any value, but not a
+// number, string, bool, bytes, array, Result or Option
+func nullable(typeName string) bool {
+	switch typeName {
+	case "int", "float", "bigint", "decimal", "string", "bool", "bytes", "void", "range", nilType, resultType, optionType:
+		return false
+	}
+	_, _, isWrapper := wrapperTypeOf(typeName)
+	return !isWrapper && !isArray(typeName)
+}
+
+// assignable reports whether a value of type from can be stored where a
+// value of type to is expected
+func assignable(to, from string) bool {
+	if to == from {
+		return true
+	}
+	if from == nilType {
+		return nullable(to)
+	}
+	if toBase, ok := optionalTypeOf(to); ok {
+		fromBase, _ := optionalTypeOf(from)
+		return assignable(toBase, fromBase)
+	}
+	if kind, _, ok := wrapperTypeOf(to); ok {
+		return from == kind
+	}
+	if toElem, ok := arrayTypeOf(to); ok {
+		fromElem, isTyped := arrayTypeOf(from)
+		return from == arrayType || isTyped && assignable(toElem, fromElem)
+	}
+	if to == arrayType {
+		return isArray(from)
+	}
+	_, _, isMap := mapTypeOf(to)
+	return from == emptyMapType && isMap
+}
+
+// unify returns the type of a value that may be of type a or of type b: the
+// one of them the other fits, preferring known array element types
+func unify(a, b string) (string, bool) {
+	fitsA, fitsB := assignable(a, b), assignable(b, a)
+	switch {
+	case fitsA && fitsB:
+		if vagueArray(a) {
+			return b, true
+		}
+		return a, true
+	case fitsA:
+		return a, true
+	case fitsB:
+		return b, true
+
+	// nil and a value that cannot be nil make an optional value
+	case a == nilType && b != "void":
+		return b + "?", true
+	case b == nilType && a != "void":
+		return a + "?", true
+	}
+	return "", false
+}
+
+// vagueArray reports whether typeName is an array type whose element type,
+// or that of its innermost arrays, is not known
+func vagueArray(typeName string) bool {
+	if elemType, ok := arrayTypeOf(typeName); ok {
+		return vagueArray(elemType)
+	}
+	return typeName == arrayType
+}
+
 func (t *TypeChecker) checkExpression(expr ast.Expression) (string, error) {
+	exprType, err := t.inferExpression(expr)
+	if err == nil && expr != nil {
+		t.exprTypes[expr] = exprType
+	}
+	return exprType, err
+}
+
+func (t *TypeChecker) inferExpression(expr ast.Expression) (string, error) {
 	if expr != nil {
-		t.setErrorPos(expr.Pos())
+		t.setErrorAt(expr)
 	}
 
 	switch e := expr.(type) {
@@ -18,6 +107,12 @@ func (t *TypeChecker) checkExpression(expr ast.Expression) (string, error) {
 		return t.checkUnaryExpression(e)
 	case *ast.VariableExpression:
 		return t.checkVariableExpression(e)
+	case *ast.ThisExpression:
+		thisType, exists := t.variables["this"]
+		if !exists {
+			return "", errcode.Errorf("BRN0331", "this can only be used in a method that takes an instance as its first parameter")
+		}
+		return thisType, nil
 	case *ast.AssignmentExpression:
 		return t.checkAssignmentExpression(e)
 	case *ast.CallExpression:
@@ -30,14 +125,33 @@ func (t *TypeChecker) checkExpression(expr ast.Expression) (string, error) {
 		return t.checkSetExpression(e)
 	case *ast.LiteralExpression:
 		return t.checkLiteralExpression(e)
+	case *ast.CastExpression:
+		return t.checkCastExpression(e)
+	case *ast.NilExpression:
+		return nilType, nil
+	case *ast.ConditionalExpression:
+		return t.checkConditionalExpression(e)
 	case *ast.ArrayLiteralExpression:
 		return t.checkArrayLiteralExpression(e)
 	case *ast.IndexExpression:
 		return t.checkIndexExpression(e)
+	case *ast.SliceExpression:
+		return t.checkSliceExpression(e)
 	case *ast.ClassMethodCallExpression:
 		return t.checkClassMethodCallExpression(e)
+	case *ast.LambdaExpression:
+		return t.checkLambdaExpression(e)
+	case *ast.MapLiteralExpression:
+		return t.checkMapLiteralExpression(e)
+	case *ast.IndexAssignmentExpression:
+		return t.checkIndexAssignmentExpression(e)
+	case *ast.RangeExpression:
+		if _, err := t.checkRangeExpression(e); err != nil {
+			return "", err
+		}
+		return "range", nil
 	default:
-		return "", fmt.Errorf("unknown expression type: %T", expr)
+		return "", errcode.Errorf("BRN0332", "unknown expression type: %T", expr)
 	}
 }
 
@@ -47,24 +161,45 @@ func (t *TypeChecker) checkBinaryExpression(expr *ast.BinaryExpression) (string,
 		return "", err
 	}
 
+	// The right operand of && runs only when the left is true, and that
+	// of || only when it is false
+	restore := t.narrowScope()
+	switch whenTrue, whenFalse := t.nilChecks(expr.Left); expr.Operator {
+	case "&&":
+		t.narrow(whenTrue)
+	case "||":
+		t.narrow(whenFalse)
+	}
 	rightType, err := t.checkExpression(expr.Right)
+	restore()
 	if err != nil {
 		return "", err
 	}
+	t.setErrorAt(expr)
 
 	switch expr.Operator {
 	case "+", "-", "*", "/", "%":
-		return t.checkArithmeticOperation(expr.Operator, leftType, rightType)
+		exprType, err := t.checkArithmeticOperation(expr.Operator, leftType, rightType)
+		expr.IntDivision = expr.Operator == "/" && exprType == "int"
+		return exprType, err
 	case "&&", "||":
 		return t.checkLogicalOperation(expr.Operator, leftType, rightType)
+	case "??":
+		return checkCoalesceOperation(leftType, rightType)
 	case "==", "!=", "<", ">", "<=", ">=":
-		return t.checkComparisonOperation(expr.Operator, leftType, rightType)
+		return t.checkComparisonOperation(expr.Operator, leftType, rightType, expr.Left, expr.Right)
 	default:
-		return "", fmt.Errorf("unknown operator: %s", expr.Operator)
+		return "", errcode.Errorf("BRN0332", "unknown operator: %s", expr.Operator)
 	}
 }
 
 func (t *TypeChecker) checkArithmeticOperation(operator string, leftType, rightType string) (string, error) {
+	if err := checkNotNil(leftType); err != nil {
+		return "", err
+	}
+	if err := checkNotNil(rightType); err != nil {
+		return "", err
+	}
 
 	if leftType == "number" {
 		leftType = "int"
@@ -73,7 +208,13 @@ func (t *TypeChecker) checkArithmeticOperation(operator string, leftType, rightT
 		rightType = "int"
 	}
 
+	if exactType, ok := exactOperands(leftType, rightType); ok {
+		return exactType, nil
+	}
 	if (leftType == "int" || leftType == "float") && (rightType == "int" || rightType == "float") {
+		if err := t.checkNumericConversion(operator, leftType, rightType); err != nil {
+			return "", err
+		}
 		if leftType == "float" || rightType == "float" {
 			return "float", nil
 		}
@@ -83,74 +224,280 @@ func (t *TypeChecker) checkArithmeticOperation(operator string, leftType, rightT
 	if operator == "+" && leftType == "string" && rightType == "string" {
 		return "string", nil
 	}
+	if operator == "+" && ((leftType == "string" && t.hasToString(rightType)) || (t.hasToString(leftType) && rightType == "string")) {
+		return "string", nil
+	}
+	if operator == "+" && leftType == "bytes" && rightType == "bytes" {
+		return "bytes", nil
+	}
 
-	return "", fmt.Errorf("incompatible types for operator %s: %s and %s",
+	return "", errcode.Errorf("BRN0309", "incompatible types for operator %s: %s and %s",
 		operator, leftType, rightType)
 }
 
+// hasToString reports whether values of a class or type have a toString
+// method, taking just the value and returning a string, which print and +
+// use to turn them into text
+func (t *TypeChecker) hasToString(typeName string) bool {
+	method, exists := t.classes[typeName]["toString"]
+	return exists && len(method.Parameters) == 1 && method.Parameters[0] == typeName && method.ReturnType == "string"
+}
+
+// exactOperands returns the type of an arithmetic operation or comparison
+// on bigints or on decimals: two of them, or one and an int, which is made
+// one. A float, or a bigint with a decimal, must be cast first.
+func exactOperands(leftType, rightType string) (string, bool) {
+	for _, exactType := range []string{"bigint", "decimal"} {
+		fits := func(typeName string) bool {
+			return typeName == exactType || typeName == "int"
+		}
+		if fits(leftType) && fits(rightType) && (leftType == exactType || rightType == exactType) {
+			return exactType, true
+		}
+	}
+	return "", false
+}
+
 func (t *TypeChecker) checkLogicalOperation(operator string, leftType, rightType string) (string, error) {
 	if leftType != "bool" || rightType != "bool" {
-		return "", fmt.Errorf("operator %s requires boolean operands, got %s and %s",
+		return "", errcode.Errorf("BRN0309", "operator %s requires boolean operands, got %s and %s",
 			operator, leftType, rightType)
 	}
 	return "bool", nil
 }
 
-func (t *TypeChecker) checkComparisonOperation(operator string, leftType, rightType string) (string, error) {
+// checkComparisonOperation checks a comparison of operands left and right,
+// which may be nil when the values compared are not written as operands
+func (t *TypeChecker) checkComparisonOperation(operator string, leftType, rightType string, left, right ast.Expression) (string, error) {
 
+	if _, ok := exactOperands(leftType, rightType); ok {
+		return "bool", nil
+	}
 	if (leftType == "int" || leftType == "float") && (rightType == "int" || rightType == "float") {
+		if err := t.checkNumericConversion(operator, leftType, rightType); err != nil {
+			return "", err
+		}
+		return "bool", nil
+	}
+
+	if leftType == nilType || rightType == nilType {
+		if operator != "==" && operator != "!=" {
+			return "", errcode.Errorf("BRN0315", "operator %s cannot be applied to nil", operator)
+		}
+		other := leftType
+		if other == nilType {
+			other = rightType
+		}
+		if other != nilType && !nullable(other) {
+			return "", errcode.Errorf("BRN0315", "cannot compare %s with nil", other)
+		}
 		return "bool", nil
 	}
 
+	// An optional value can equal a value of its type without nil, and
+	// has no order
+	if operator == "==" || operator == "!=" {
+		for _, operand := range []struct {
+			typeName string
+			expr     ast.Expression
+		}{{leftType, left}, {rightType, right}} {
+			if !equatable(operand.typeName) {
+				return "", errcode.Errorf("BRN0309", "operator %s cannot be applied to %s", operator,
+					describeOperand(operand.typeName, operand.expr))
+			}
+		}
+		leftBase, _ := optionalTypeOf(leftType)
+		rightBase, _ := optionalTypeOf(rightType)
+		if leftBase == rightBase {
+			return "bool", nil
+		}
+		// Arrays are equal when their elements are, and one whose element
+		// type is not known, such as [], can equal any array
+		if isArray(leftType) && isArray(rightType) {
+			if _, ok := unify(leftType, rightType); ok {
+				return "bool", nil
+			}
+		}
+	} else {
+		if err := checkNotNil(leftType); err != nil {
+			return "", err
+		}
+		if err := checkNotNil(rightType); err != nil {
+			return "", err
+		}
+	}
+
 	if leftType != rightType {
-		return "", fmt.Errorf("incompatible types for comparison: %s and %s",
-			leftType, rightType)
+		return "", errcode.Errorf("BRN0309", "incompatible types for comparison: %s and %s",
+			describeOperand(leftType, left), describeOperand(rightType, right))
+	}
+
+	// Besides numbers, strings are ordered, by their characters. Structs
+	// and arrays are equal when their fields or elements are, and like other
+	// values have no order.
+	if operator != "==" && operator != "!=" && !t.ordered(leftType) {
+		return "", errcode.Errorf("BRN0309", "operator %s cannot be applied to %s", operator, leftType)
 	}
 	return "bool", nil
 }
 
+// describeOperand names the type of an operand and, when it is known, the
+// place it starts
+func describeOperand(typeName string, operand ast.Expression) string {
+	if operand == nil || !operand.Loc().IsValid() {
+		return typeName
+	}
+	loc := operand.Loc()
+	return fmt.Sprintf("%s at line %d, column %d", typeName, loc.Line, loc.Column)
+}
+
+// ordered reports whether <, >, <= and >= can compare values of a type
+// other than int and float: bigints, decimals, strings, and values of type
+// any or of a type parameter, whose order is checked at run time
+func (t *TypeChecker) ordered(typeName string) bool {
+	switch typeName {
+	case "bigint", "decimal", "string", "any":
+		return true
+	case "bool", "bytes", "range", nilType, resultType, optionType, emptyMapType:
+		return false
+	}
+	if _, isStruct := t.fieldsOf(typeName); isStruct || isArray(typeName) {
+		return false
+	}
+	if _, isClass := t.classes[typeName]; isClass {
+		return false
+	}
+	_, _, isMap := mapTypeOf(typeName)
+	_, isFunction := functionTypeOf(typeName)
+	_, _, isWrapper := wrapperTypeOf(typeName)
+	_, _, isGeneric := genericTypeOf(typeName)
+	return !isMap && !isFunction && !isWrapper && !isGeneric
+}
+
+// equatable reports whether == and != can compare values of a type, or
+// arrays or maps of them. Ranges and functions cannot be compared, and
+// Results and Options are compared through isOk, isSome and unwrap.
+func equatable(typeName string) bool {
+	typeName, _ = optionalTypeOf(typeName)
+	switch typeName {
+	case "range", resultType, optionType:
+		return false
+	}
+	if elemType, ok := arrayTypeOf(typeName); ok {
+		return equatable(elemType)
+	}
+	if _, valueType, ok := mapTypeOf(typeName); ok {
+		return equatable(valueType)
+	}
+	_, isFunction := functionTypeOf(typeName)
+	_, _, isWrapper := wrapperTypeOf(typeName)
+	return !isFunction && !isWrapper
+}
+
+// checkConditionalExpression checks cond ? a : b, whose type is the one of
+// the types of its branches that the other fits, or float for an int and a
+// float
+func (t *TypeChecker) checkConditionalExpression(expr *ast.ConditionalExpression) (string, error) {
+	condType, err := t.checkExpression(expr.Condition)
+	if err != nil {
+		return "", err
+	}
+	if condType != "bool" {
+		return "", errcode.Errorf("BRN0310", "condition of '?' must be bool, got %s", condType)
+	}
+
+	whenTrue, whenFalse := t.nilChecks(expr.Condition)
+	restore := t.narrowScope()
+	t.narrow(whenTrue)
+	thenType, err := t.checkExpression(expr.Then)
+	restore()
+	if err != nil {
+		return "", err
+	}
+	t.narrow(whenFalse)
+	elseType, err := t.checkExpression(expr.Else)
+	restore()
+	if err != nil {
+		return "", err
+	}
+
+	if exprType, ok := unify(thenType, elseType); ok {
+		return exprType, nil
+	}
+	if (thenType == "int" || thenType == "float") && (elseType == "int" || elseType == "float") {
+		return "float", nil
+	}
+	return "", errcode.Errorf("BRN0329", "branches of '?' have different types: %s and %s", thenType, elseType)
+}
+
 func (t *TypeChecker) checkUnaryExpression(expr *ast.UnaryExpression) (string, error) {
 	rightType, err := t.checkExpression(expr.Right)
 	if err != nil {
 		return "", err
 	}
+	t.setErrorAt(expr)
+	if err := checkNotNil(rightType); err != nil {
+		return "", err
+	}
 
 	switch expr.Operator {
 	case "-":
-		if rightType == "int" || rightType == "float" {
+		switch rightType {
+		case "int", "float", "bigint", "decimal":
 			return rightType, nil
 		}
-		return "", fmt.Errorf("cannot apply unary - to type %s", rightType)
+		return "", errcode.Errorf("BRN0309", "cannot apply unary - to type %s", rightType)
 	case "!":
 		if rightType == "bool" {
 			return "bool", nil
 		}
-		return "", fmt.Errorf("cannot apply unary ! to type %s", rightType)
+		return "", errcode.Errorf("BRN0309", "cannot apply unary ! to type %s", rightType)
 	default:
-		return "", fmt.Errorf("unknown unary operator: %s", expr.Operator)
+		return "", errcode.Errorf("BRN0332", "unknown unary operator: %s", expr.Operator)
 	}
 }
 
 func (t *TypeChecker) checkVariableExpression(expr *ast.VariableExpression) (string, error) {
-	t.setErrorPos(expr.Pos())
+	t.setErrorAt(expr)
 
 	if varType, exists := t.variables[expr.Name]; exists {
+		t.markUsed(expr.Name)
+		if notNil, narrowed := t.narrowed[expr.Name]; narrowed {
+			return notNil, nil
+		}
 		return varType, nil
 	}
-	return "", fmt.Errorf("undefined variable: %s", expr.Name)
+	if fn, exists := t.functions[expr.Name]; exists {
+		return fn.String(), nil
+	}
+	return "", errcode.Errorf("BRN0301", "undefined variable: %s%s", expr.Name,
+		suggest.Hint(expr.Name, maps.Keys(t.variables), maps.Keys(t.functions)))
 }
 
 func (t *TypeChecker) checkAssignmentExpression(expr *ast.AssignmentExpression) (string, error) {
+	t.expectType(expr.Value, t.variables[expr.Name])
 	valueType, err := t.checkExpression(expr.Value)
 	if err != nil {
 		return "", err
 	}
 
 	if varType, exists := t.variables[expr.Name]; exists {
-		if varType != valueType {
-			return "", fmt.Errorf("cannot assign %s to variable %s of type %s",
+		if t.isConst(expr.Name) {
+			return "", errcode.Errorf("BRN0313", "cannot assign to constant %s", expr.Name)
+		}
+		if !t.assignable(varType, valueType) {
+			return "", errcode.Errorf("BRN0306", "cannot assign %s to variable %s of type %s",
 				valueType, expr.Name, varType)
 		}
+		// A value that may be nil undoes the nil checks of the variable,
+		// and one that cannot be nil is a nil check of it
+		baseType, optional := optionalTypeOf(varType)
+		switch notNil, narrowed := t.narrowed[expr.Name]; {
+		case optional && !t.closureAssigned[expr.Name] && valueType != nilType && t.assignable(baseType, valueType):
+			t.narrowed[expr.Name] = baseType
+		case narrowed && !t.assignable(notNil, valueType):
+			delete(t.narrowed, expr.Name)
+		}
 		return varType, nil
 	}
 
@@ -160,6 +507,12 @@ func (t *TypeChecker) checkAssignmentExpression(expr *ast.AssignmentExpression)
 
 func (t *TypeChecker) checkCallExpression(expr *ast.CallExpression) (string, error) {
 
+	// A method called on a value rather than on a class name gets the
+	// value as its first argument
+	if getExpr, ok := expr.Callee.(*ast.GetExpression); ok && !t.namesClass(getExpr.Object) {
+		return t.checkMethodCall(getExpr, expr.Arguments)
+	}
+
 	if getExpr, ok := expr.Callee.(*ast.GetExpression); ok {
 		if classNameExpr, ok := getExpr.Object.(*ast.VariableExpression); ok {
 			className := classNameExpr.Name
@@ -171,6 +524,7 @@ func (t *TypeChecker) checkCallExpression(expr *ast.CallExpression) (string, err
 				Arguments:  expr.Arguments,
 				IsStatic:   false,
 				Position:   expr.Position,
+				Location:   expr.Location,
 			}
 
 			return t.checkClassMethodCallExpression(classMethodCall)
@@ -191,6 +545,7 @@ func (t *TypeChecker) checkCallExpression(expr *ast.CallExpression) (string, err
 					Arguments:  expr.Arguments,
 					IsStatic:   true,
 					Position:   expr.Position,
+					Location:   expr.Location,
 				}
 
 				return t.checkClassMethodCallExpression(classMethodCall)
@@ -198,19 +553,55 @@ func (t *TypeChecker) checkCallExpression(expr *ast.CallExpression) (string, err
 		}
 	}
 
-	callee, ok := expr.Callee.(*ast.VariableExpression)
-	if !ok {
-		return "", fmt.Errorf("callee is not a function name")
+	if callee, ok := expr.Callee.(*ast.VariableExpression); ok && mapFunctions[callee.Name] {
+		if _, isVariable := t.variables[callee.Name]; !isVariable {
+			return t.checkMapCall(callee.Name, expr.Arguments)
+		}
+	}
+	if callee, ok := expr.Callee.(*ast.VariableExpression); ok && resultFunctions[callee.Name] {
+		if _, isVariable := t.variables[callee.Name]; !isVariable {
+			return t.checkResultCall(callee.Name, expr.Arguments)
+		}
+	}
+	if callee, ok := expr.Callee.(*ast.VariableExpression); ok && (arrayFunctions[callee.Name] || arrayCallbacks[callee.Name]) {
+		_, isVariable := t.variables[callee.Name]
+		if _, declared := t.functions[callee.Name]; !isVariable && !declared {
+			if arrayCallbacks[callee.Name] {
+				return t.checkArrayCallback(callee.Name, expr.Arguments)
+			}
+			return t.checkArrayCall(callee.Name, expr.Arguments)
+		}
 	}
 
-	fn, exists := t.functions[callee.Name]
-	if !exists {
-		return "", fmt.Errorf("undefined function: %s", callee.Name)
+	if callee, ok := expr.Callee.(*ast.VariableExpression); ok && callee.Name == "clone" && len(expr.Arguments) == 1 {
+		if _, isVariable := t.variables[callee.Name]; !isVariable {
+			return t.checkExpression(expr.Arguments[0])
+		}
+	}
+
+	if callee, ok := expr.Callee.(*ast.VariableExpression); ok && t.declaredClasses[callee.Name] {
+		_, isVariable := t.variables[callee.Name]
+		if _, declared := t.functions[callee.Name]; !isVariable && !declared {
+			return t.checkConstruction(callee.Name, expr.Arguments)
+		}
+	}
+
+	// An error of the call itself is located at the function called, and
+	// one of an argument at the argument
+	t.setErrorAt(expr.Callee)
+	fn, name, err := t.calledFunction(expr.Callee)
+	if err != nil {
+		return "", err
 	}
 
-	if len(expr.Arguments) != len(fn.Parameters) {
-		return "", fmt.Errorf("function %s expects %d arguments but got %d",
-			callee.Name, len(fn.Parameters), len(expr.Arguments))
+	params, ok := argumentTypes(fn.Parameters, len(expr.Arguments))
+	if !ok {
+		return "", errcode.Errorf("BRN0308", "function %s expects %s arguments but got %d",
+			name, arity(fn.Parameters), len(expr.Arguments))
+	}
+	if len(fn.TypeParameters) > 0 {
+		fn.Parameters = params
+		return t.checkGenericCall(fn, name, expr.Arguments)
 	}
 
 	for i, arg := range expr.Arguments {
@@ -219,35 +610,96 @@ func (t *TypeChecker) checkCallExpression(expr *ast.CallExpression) (string, err
 			return "", err
 		}
 
-		expectedType := fn.Parameters[i]
-		if expectedType != "any" && argType != expectedType {
-			return "", fmt.Errorf("argument %d of function %s expects %s but got %s",
-				i+1, callee.Name, expectedType, argType)
+		expectedType := params[i]
+		if expectedType != "any" && !t.assignable(expectedType, argType) {
+			t.setErrorAt(arg)
+			return "", errcode.Errorf("BRN0307", "argument %d of function %s expects %s but got %s",
+				i+1, name, expectedType, argType)
+		}
+		if name == "typeof" && (argType == "int" || argType == "float") {
+			expr.NumberType = argType
 		}
 	}
 
 	return fn.ReturnType, nil
 }
 
+// calledFunction returns the type of the function a call calls, and the
+// name to give it in errors. A name calls the variable of a function type
+// of that name if there is one, otherwise the declared function; any other
+// callee must be an expression of a function type.
+func (t *TypeChecker) calledFunction(callee ast.Expression) (FunctionType, string, error) {
+	if name, ok := callee.(*ast.VariableExpression); ok {
+		if varType, exists := t.variables[name.Name]; exists {
+			if fn, ok := functionTypeOf(varType); ok {
+				t.markUsed(name.Name)
+				return fn, name.Name, nil
+			}
+		}
+
+		fn, exists := t.functions[name.Name]
+		if !exists {
+			if varType, isVariable := t.variables[name.Name]; isVariable {
+				return FunctionType{}, "", errcode.Errorf("BRN0324", "cannot call %s of type %s", name.Name, varType)
+			}
+			if path, hidden := t.hiddenFunctions[name.Name]; hidden {
+				return FunctionType{}, "", errcode.Errorf("BRN0318", "function %s is private to %s", name.Name, path)
+			}
+			return FunctionType{}, "", errcode.Errorf("BRN0302", "undefined function: %s%s", name.Name,
+				suggest.Hint(name.Name, maps.Keys(t.functions), maps.Keys(t.declaredClasses)))
+		}
+		return fn, name.Name, nil
+	}
+
+	calleeType, err := t.checkExpression(callee)
+	if err != nil {
+		return FunctionType{}, "", err
+	}
+	fn, ok := functionTypeOf(calleeType)
+	if !ok {
+		return FunctionType{}, "", errcode.Errorf("BRN0324", "cannot call a value of type %s", calleeType)
+	}
+	return fn, calleeType, nil
+}
+
+// checkLambdaExpression checks a lambda like a function declared where it
+// stands and returns its function type
+func (t *TypeChecker) checkLambdaExpression(expr *ast.LambdaExpression) (string, error) {
+	fn, err := t.checkClosure(&ast.FunctionDeclaration{
+		Name:       "lambda",
+		Parameters: expr.Parameters,
+		ReturnType: expr.ReturnType,
+		Body:       expr.Body,
+		Position:   expr.Position,
+		Location:   expr.Location,
+	}, "lambda")
+	if err != nil {
+		return "", err
+	}
+	return fn.String(), nil
+}
+
 func (t *TypeChecker) checkStructLiteralExpression(expr *ast.StructLiteralExpression) (string, error) {
-	typeDef, exists := t.types[expr.Type]
+	typeDef, exists := t.fieldsOf(expr.Type)
 	if !exists {
-		return "", fmt.Errorf("unknown type: %s", expr.Type)
+		return "", errcode.Errorf("BRN0303", "unknown type: %s%s", expr.Type, suggest.Hint(expr.Type, maps.Keys(t.types)))
 	}
 
 	for fieldName, fieldExpr := range expr.Fields {
 		fieldType, exists := typeDef[fieldName]
 		if !exists {
-			return "", fmt.Errorf("unknown field %s in type %s", fieldName, expr.Type)
+			return "", errcode.Errorf("BRN0304", "unknown field %s in type %s%s", fieldName, expr.Type,
+				suggest.Hint(fieldName, maps.Keys(typeDef)))
 		}
+		t.expectType(fieldExpr, fieldType)
 
 		valueType, err := t.checkExpression(fieldExpr)
 		if err != nil {
 			return "", err
 		}
 
-		if valueType != fieldType {
-			return "", fmt.Errorf("type mismatch for field %s: expected %s but got %s",
+		if !t.assignable(fieldType, valueType) {
+			return "", errcode.Errorf("BRN0306", "type mismatch for field %s: expected %s but got %s",
 				fieldName, fieldType, valueType)
 		}
 	}
@@ -255,48 +707,121 @@ func (t *TypeChecker) checkStructLiteralExpression(expr *ast.StructLiteralExpres
 	return expr.Type, nil
 }
 
+// expectType gives a literal the type of the variable or field it is the
+// value of, typeName: a struct literal written without its type name takes
+// it rather than the return type of the function it is in, and an array
+// literal takes its element type, so that its elements are checked against
+// it rather than against each other
+func (t *TypeChecker) expectType(value ast.Expression, typeName string) {
+	baseType, _ := optionalTypeOf(typeName)
+	switch literal := value.(type) {
+	case *ast.StructLiteralExpression:
+		if _, isStruct := t.fieldsOf(baseType); isStruct && !literal.Named {
+			literal.Type = baseType
+		}
+	case *ast.ArrayLiteralExpression:
+		if elemType, ok := arrayTypeOf(baseType); ok {
+			literal.ElementType = elemType
+			for _, elem := range literal.Elements {
+				t.expectType(elem, elemType)
+			}
+		}
+	}
+}
+
 func (t *TypeChecker) checkGetExpression(expr *ast.GetExpression) (string, error) {
+	if className, fieldType, ok := t.staticField(expr.Object, expr.Name); ok {
+		if err := t.checkAccess(className, expr.Name, "static field"); err != nil {
+			return "", err
+		}
+		return fieldType, nil
+	}
+
 	objectType, err := t.checkExpression(expr.Object)
 	if err != nil {
 		return "", err
 	}
+	// object?.name is nil when the object is
+	if expr.Optional {
+		objectType, _ = optionalTypeOf(objectType)
+	}
+	if err := checkNotNil(objectType); err != nil {
+		return "", err
+	}
 
-	typeDef, exists := t.types[objectType]
+	typeDef, exists := t.fieldsOf(objectType)
 	if !exists {
-		return "", fmt.Errorf("cannot access field on non-struct type: %s", objectType)
+		return "", errcode.Errorf("BRN0304", "cannot access field on non-struct type: %s", objectType)
 	}
 
 	fieldType, exists := typeDef[expr.Name]
 	if !exists {
-		return "", fmt.Errorf("unknown field %s in type %s", expr.Name, objectType)
+		return "", errcode.Errorf("BRN0304", "unknown field %s in type %s%s", expr.Name, objectType,
+			suggest.Hint(expr.Name, maps.Keys(typeDef)))
+	}
+	if err := t.checkAccess(objectType, expr.Name, "field"); err != nil {
+		return "", err
 	}
 
+	if expr.Optional {
+		return optionalOf(fieldType), nil
+	}
 	return fieldType, nil
 }
 
 func (t *TypeChecker) checkSetExpression(expr *ast.SetExpression) (string, error) {
+	if className, fieldType, ok := t.staticField(expr.Object, expr.Name); ok {
+		if err := t.checkAccess(className, expr.Name, "static field"); err != nil {
+			return "", err
+		}
+		if t.staticConsts[className+"."+expr.Name] {
+			return "", errcode.Errorf("BRN0313", "cannot assign to constant %s.%s", className, expr.Name)
+		}
+		t.expectType(expr.Value, fieldType)
+		valueType, err := t.checkExpression(expr.Value)
+		if err != nil {
+			return "", err
+		}
+		if fieldType != "any" && !t.assignable(fieldType, valueType) {
+			return "", errcode.Errorf("BRN0306", "cannot assign %s to static field %s.%s of type %s",
+				valueType, className, expr.Name, fieldType)
+		}
+		return fieldType, nil
+	}
+
 	objectType, err := t.checkExpression(expr.Object)
 	if err != nil {
 		return "", err
 	}
+	if err := checkNotNil(objectType); err != nil {
+		return "", err
+	}
 
-	typeDef, exists := t.types[objectType]
+	typeDef, exists := t.fieldsOf(objectType)
 	if !exists {
-		return "", fmt.Errorf("cannot set field on non-struct type: %s", objectType)
+		return "", errcode.Errorf("BRN0304", "cannot set field on non-struct type: %s", objectType)
 	}
 
 	fieldType, exists := typeDef[expr.Name]
 	if !exists {
-		return "", fmt.Errorf("unknown field %s in type %s", expr.Name, objectType)
+		return "", errcode.Errorf("BRN0304", "unknown field %s in type %s%s", expr.Name, objectType,
+			suggest.Hint(expr.Name, maps.Keys(typeDef)))
+	}
+	if err := t.checkAccess(objectType, expr.Name, "field"); err != nil {
+		return "", err
+	}
+	if err := t.checkConstField(expr.Object, expr.Name); err != nil {
+		return "", err
 	}
 
+	t.expectType(expr.Value, fieldType)
 	valueType, err := t.checkExpression(expr.Value)
 	if err != nil {
 		return "", err
 	}
 
-	if valueType != fieldType {
-		return "", fmt.Errorf("cannot assign %s to field %s of type %s",
+	if fieldType != "any" && !t.assignable(fieldType, valueType) {
+		return "", errcode.Errorf("BRN0306", "cannot assign %s to field %s of type %s",
 			valueType, expr.Name, fieldType)
 	}
 
@@ -306,44 +831,110 @@ func (t *TypeChecker) checkSetExpression(expr *ast.SetExpression) (string, error
 func (t *TypeChecker) checkLiteralExpression(expr *ast.LiteralExpression) (string, error) {
 
 	if expr.Type == "number" {
+		text, _ := expr.Value.(string)
+		switch {
+		case lexer.IsDecimal(text):
+			return "decimal", nil
+		case lexer.IsBigInt(text):
+			return "bigint", nil
+		case lexer.IsFloat(text):
+			return "float", nil
+		}
 		return "int", nil
 	}
 	return expr.Type, nil
 }
 
+// checkRangeExpression checks a range and returns the type of its numbers:
+// float if any of its bounds or its step is a float, otherwise int
+func (t *TypeChecker) checkRangeExpression(expr *ast.RangeExpression) (string, error) {
+	elemType := "int"
+	for _, part := range []ast.Expression{expr.Start, expr.End, expr.Step} {
+		if part == nil {
+			continue
+		}
+		partType, err := t.checkExpression(part)
+		if err != nil {
+			return "", err
+		}
+		if partType != "int" && partType != "float" {
+			return "", errcode.Errorf("BRN0324", "range bounds and step must be numbers, got %s", partType)
+		}
+		if partType == "float" {
+			elemType = "float"
+		}
+	}
+	return elemType, nil
+}
+
+// checkArrayElement checks an element of an array literal and returns its
+// type. A range in an array literal stands for its numbers.
+func (t *TypeChecker) checkArrayElement(elem ast.Expression) (string, error) {
+	if r, ok := elem.(*ast.RangeExpression); ok {
+		return t.checkRangeExpression(r)
+	}
+	return t.checkExpression(elem)
+}
+
 func (t *TypeChecker) checkArrayLiteralExpression(expr *ast.ArrayLiteralExpression) (string, error) {
 	if len(expr.Elements) == 0 {
-		return "array", nil
+		return arrayType, nil
 	}
 
-	firstType, err := t.checkExpression(expr.Elements[0])
+	if expr.ElementType != "" {
+		for _, elem := range expr.Elements {
+			elemType, err := t.checkArrayElement(elem)
+			if err != nil {
+				return "", err
+			}
+			if expr.ElementType != "any" && !t.assignable(expr.ElementType, elemType) {
+				return "", errcode.Errorf("BRN0306", "cannot store %s in an array of %s", elemType, expr.ElementType)
+			}
+		}
+		return "[" + expr.ElementType + "]", nil
+	}
+
+	firstType, err := t.checkArrayElement(expr.Elements[0])
 	if err != nil {
 		return "", err
 	}
 
+	elemType := firstType
 	for i := 1; i < len(expr.Elements); i++ {
-		elemType, err := t.checkExpression(expr.Elements[i])
+		nextType, err := t.checkArrayElement(expr.Elements[i])
 		if err != nil {
 			return "", err
 		}
 
-		if elemType != firstType {
-			return "", fmt.Errorf("array elements must be of the same type, got %s and %s",
-				firstType, elemType)
+		unified, ok := unify(elemType, nextType)
+		if !ok {
+			return "", errcode.Errorf("BRN0306", "array elements must be of the same type, got %s and %s",
+				firstType, nextType)
 		}
+		elemType = unified
+	}
+	if elemType == nilType || elemType == emptyMapType || elemType == resultType || elemType == optionType {
+		return "", errcode.Errorf("BRN0316", "array needs elements of a known type, got only %s", elemType)
 	}
 
-	return "array", nil
+	return "[" + elemType + "]", nil
 }
 
 func (t *TypeChecker) checkIndexExpression(expr *ast.IndexExpression) (string, error) {
-	arrayType, err := t.checkExpression(expr.Array)
+	targetType, err := t.checkExpression(expr.Array)
 	if err != nil {
 		return "", err
 	}
+	if err := checkNotNil(targetType); err != nil {
+		return "", err
+	}
 
-	if arrayType != "array" {
-		return "", fmt.Errorf("cannot index into non-array type: %s", arrayType)
+	if _, _, isMap := mapTypeOf(targetType); isMap {
+		return t.checkMapKey(targetType, expr.Index)
+	}
+
+	if !isArray(targetType) && targetType != "string" && targetType != "bytes" {
+		return "", errcode.Errorf("BRN0323", "cannot index into non-array type: %s", targetType)
 	}
 
 	indexType, err := t.checkExpression(expr.Index)
@@ -352,16 +943,169 @@ func (t *TypeChecker) checkIndexExpression(expr *ast.IndexExpression) (string, e
 	}
 
 	if indexType != "int" {
-		return "", fmt.Errorf("array index must be an integer, got %s", indexType)
+		return "", errcode.Errorf("BRN0323", "array index must be an integer, got %s", indexType)
+	}
+
+	switch targetType {
+	case "string":
+		return "string", nil
+	case "bytes":
+		return "int", nil
+	}
+	return t.elementType(expr.Array, targetType), nil
+}
+
+// checkSliceExpression checks a[start:end], which has the type of a
+func (t *TypeChecker) checkSliceExpression(expr *ast.SliceExpression) (string, error) {
+	targetType, err := t.checkExpression(expr.Array)
+	if err != nil {
+		return "", err
+	}
+	if !isArray(targetType) && targetType != "string" && targetType != "bytes" {
+		return "", errcode.Errorf("BRN0323", "cannot slice %s, only arrays, strings and bytes", targetType)
+	}
+
+	for _, bound := range []ast.Expression{expr.Start, expr.End} {
+		if bound == nil {
+			continue
+		}
+		boundType, err := t.checkExpression(bound)
+		if err != nil {
+			return "", err
+		}
+		if boundType != "int" {
+			return "", errcode.Errorf("BRN0323", "slice bounds must be integers, got %s", boundType)
+		}
 	}
+	return targetType, nil
+}
 
-	if varExpr, ok := expr.Array.(*ast.VariableExpression); ok {
-		if elemType, exists := t.arrayTypes[varExpr.Name]; exists {
-			return elemType, nil
+// elementType returns the type of the elements of an array of type
+// typeName, as far as it is known, and otherwise int
+func (t *TypeChecker) elementType(array ast.Expression, typeName string) string {
+	if elemType, ok := arrayTypeOf(typeName); ok {
+		return elemType
+	}
+	if a, ok := array.(*ast.VariableExpression); ok {
+		if elemType, exists := t.arrayTypes[a.Name]; exists {
+			return elemType
 		}
 	}
+	return "int"
+}
 
-	return "int", nil
+// checkConstruction checks Name(...) or new Name(...) for a class, whose
+// arguments are those of its init method after the new instance
+func (t *TypeChecker) checkConstruction(className string, args []ast.Expression) (string, error) {
+	var params []string
+	if init, exists := t.classes[className]["init"]; exists && len(init.Parameters) > 0 {
+		params = init.Parameters[1:]
+	}
+	if err := t.checkAccess(className, "init", "method"); err != nil {
+		return "", err
+	}
+	argTypes, ok := argumentTypes(params, len(args))
+	if !ok {
+		return "", errcode.Errorf("BRN0308", "constructor of class %s expects %s arguments but got %d",
+			className, arity(params), len(args))
+	}
+
+	for i, arg := range args {
+		argType, err := t.checkExpression(arg)
+		if err != nil {
+			return "", err
+		}
+
+		expectedType := argTypes[i]
+		if expectedType != "any" && !t.assignable(expectedType, argType) {
+			return "", errcode.Errorf("BRN0307", "argument %d of constructor of class %s expects %s but got %s",
+				i+1, className, expectedType, argType)
+		}
+	}
+
+	return className, nil
+}
+
+// namesClass reports whether expr is a name that is not a variable, which
+// a call such as Name.method() takes as a class
+func (t *TypeChecker) namesClass(expr ast.Expression) bool {
+	name, ok := expr.(*ast.VariableExpression)
+	if !ok {
+		return false
+	}
+	_, isVariable := t.variables[name.Name]
+	return !isVariable
+}
+
+// staticField returns the class and type of a static field named as in
+// Config.VERSION, if object and name are one
+func (t *TypeChecker) staticField(object ast.Expression, name string) (string, string, bool) {
+	if !t.namesClass(object) {
+		return "", "", false
+	}
+	className := object.(*ast.VariableExpression).Name
+	fieldType, exists := t.statics[className][name]
+	return className, fieldType, exists
+}
+
+// checkMethodCall checks a call of a method on a value, such as p.norm()
+// for a method declared as fun (p: Point) norm(): float
+func (t *TypeChecker) checkMethodCall(getExpr *ast.GetExpression, args []ast.Expression) (string, error) {
+	objectType, err := t.checkExpression(getExpr.Object)
+	if err != nil {
+		return "", err
+	}
+	if getExpr.Optional {
+		objectType, _ = optionalTypeOf(objectType)
+	}
+	if err := checkNotNil(objectType); err != nil {
+		return "", err
+	}
+	method, exists := t.classes[objectType][getExpr.Name]
+	if !exists {
+		return "", errcode.Errorf("BRN0305", "undefined method %s on type %s%s", getExpr.Name, objectType,
+			suggest.Hint(getExpr.Name, methodNames(t.classes[objectType], false)))
+	}
+	if err := t.checkAccess(objectType, getExpr.Name, "method"); err != nil {
+		return "", err
+	}
+	name := objectType + "." + getExpr.Name
+
+	params, ok := argumentTypes(method.Parameters[1:], len(args))
+	if !ok {
+		return "", errcode.Errorf("BRN0308", "method %s expects %s arguments but got %d",
+			name, arity(method.Parameters[1:]), len(args))
+	}
+	for i, arg := range args {
+		argType, err := t.checkExpression(arg)
+		if err != nil {
+			return "", err
+		}
+
+		expectedType := params[i]
+		if expectedType != "any" && !t.assignable(expectedType, argType) {
+			return "", errcode.Errorf("BRN0307", "argument %d of method %s expects %s but got %s",
+				i+1, name, expectedType, argType)
+		}
+	}
+
+	if getExpr.Optional && method.ReturnType != "" {
+		return optionalOf(method.ReturnType), nil
+	}
+	return method.ReturnType, nil
+}
+
+// methodNames returns the names of the static methods of a class, whose
+// keys start with static., or those of the other methods
+func methodNames(methods map[string]FunctionType, static bool) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for key := range methods {
+			name, isStatic := strings.CutPrefix(key, "static.")
+			if isStatic == static && !yield(name) {
+				return
+			}
+		}
+	}
 }
 
 func (t *TypeChecker) checkClassMethodCallExpression(expr *ast.ClassMethodCallExpression) (string, error) {
@@ -371,7 +1115,10 @@ func (t *TypeChecker) checkClassMethodCallExpression(expr *ast.ClassMethodCallEx
 
 	classMethods, exists := t.classes[className]
 	if !exists {
-		return "", fmt.Errorf("undefined class: %s", className)
+		return "", errcode.Errorf("BRN0302", "undefined class: %s", className)
+	}
+	if _, isInterface := t.interfaces[className]; isInterface {
+		return "", errcode.Errorf("BRN0305", "cannot call method %s on interface %s; call it on a value of type %s", methodName, className, className)
 	}
 
 	methodKey := methodName
@@ -382,22 +1129,28 @@ func (t *TypeChecker) checkClassMethodCallExpression(expr *ast.ClassMethodCallEx
 	method, exists := classMethods[methodKey]
 	if !exists {
 		if isStatic {
-			return "", fmt.Errorf("undefined static method %s.%s", className, methodName)
+			return "", errcode.Errorf("BRN0305", "undefined static method %s.%s%s", className, methodName,
+				suggest.Hint(methodName, methodNames(classMethods, true)))
 		} else {
 
 			methodKey = "static." + methodName
 			method, exists = classMethods[methodKey]
 			if !exists {
-				return "", fmt.Errorf("undefined method %s.%s", className, methodName)
+				return "", errcode.Errorf("BRN0305", "undefined method %s.%s%s", className, methodName,
+					suggest.Hint(methodName, methodNames(classMethods, false), methodNames(classMethods, true)))
 			}
 
-			return "", fmt.Errorf("static method %s.%s cannot be called on instance", className, methodName)
+			return "", errcode.Errorf("BRN0305", "static method %s.%s cannot be called on instance", className, methodName)
 		}
 	}
 
-	if len(expr.Arguments) != len(method.Parameters) {
-		return "", fmt.Errorf("method %s.%s expects %d arguments but got %d",
-			className, methodName, len(method.Parameters), len(expr.Arguments))
+	if err := t.checkAccess(className, methodName, "method"); err != nil {
+		return "", err
+	}
+	params, ok := argumentTypes(method.Parameters, len(expr.Arguments))
+	if !ok {
+		return "", errcode.Errorf("BRN0308", "method %s.%s expects %s arguments but got %d",
+			className, methodName, arity(method.Parameters), len(expr.Arguments))
 	}
 
 	for i, arg := range expr.Arguments {
@@ -406,9 +1159,9 @@ func (t *TypeChecker) checkClassMethodCallExpression(expr *ast.ClassMethodCallEx
 			return "", err
 		}
 
-		expectedType := method.Parameters[i]
-		if expectedType != "any" && argType != expectedType {
-			return "", fmt.Errorf("argument %d of method %s.%s expects %s but got %s",
+		expectedType := params[i]
+		if expectedType != "any" && !t.assignable(expectedType, argType) {
+			return "", errcode.Errorf("BRN0307", "argument %d of method %s.%s expects %s but got %s",
 				i+1, className, methodName, expectedType, argType)
 		}
 	}
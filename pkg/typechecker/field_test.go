@@ -0,0 +1,41 @@
+package typechecker_test
+
+import "testing"
+
+func TestAssignToOptionalField(t *testing.T) {
+	for _, source := range []string{`
+type N { v: int, next: N? }
+var a = N{v: 1, next: nil}
+a.next = a
+`, `
+type N { name: string? }
+var n = N{name: nil}
+n.name = "b"
+`} {
+		if _, errs := check(t, source); len(errs) > 0 {
+			t.Errorf("%s: unexpected error: %v", source, errs[0])
+		}
+	}
+}
+
+func TestAssignNilToStructField(t *testing.T) {
+	_, errs := check(t, `
+type Node { v: int, next: Node }
+var n = Node{v: 1, next: nil}
+n.next = nil
+`)
+	if len(errs) > 0 {
+		t.Errorf("unexpected error: %v", errs[0])
+	}
+}
+
+func TestAssignWrongTypeToField(t *testing.T) {
+	_, errs := check(t, `
+type N { name: string? }
+var n = N{name: nil}
+n.name = 1
+`)
+	if len(errs) == 0 {
+		t.Error("assigning int to a string? field was accepted")
+	}
+}
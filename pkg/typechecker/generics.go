@@ -0,0 +1,168 @@
+package typechecker
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
+)
+
+// genericTypeOf splits a use of a generic type such as Pair<int, string>
+// into the name of the type and its type arguments
+func genericTypeOf(typeName string) (string, []string, bool) {
+	open := strings.IndexByte(typeName, '<')
+	if open <= 0 || !strings.HasSuffix(typeName, ">") || strings.ContainsAny(typeName[:open], "[(") {
+		return "", nil, false
+	}
+
+	inner := typeName[open+1 : len(typeName)-1]
+	args := []string{}
+	start, depth := 0, 0
+	for j := 0; j < len(inner); j++ {
+		switch inner[j] {
+		case '(', '<', '[':
+			depth++
+		case ')', '>', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, inner[start:j])
+				start = j + len(", ")
+			}
+		}
+	}
+	return typeName[:open], append(args, inner[start:]), true
+}
+
+// substitute replaces the type parameters in typeName, such as the T of
+// [T] or fun(T): Box<T>, with the types bound to them
+func substitute(typeName string, bindings map[string]string) string {
+	var b strings.Builder
+	start := -1
+	for j, r := range typeName + " " {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = j
+			}
+			continue
+		}
+		if start >= 0 {
+			word := typeName[start:j]
+			if bound, ok := bindings[word]; ok {
+				word = bound
+			}
+			b.WriteString(word)
+			start = -1
+		}
+		if j < len(typeName) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// fieldsOf returns the fields of a type, or those of a use of a generic
+// type such as Box<int>, with its type arguments for its type parameters
+func (t *TypeChecker) fieldsOf(typeName string) (map[string]string, bool) {
+	if fields, exists := t.types[typeName]; exists {
+		return fields, true
+	}
+	name, args, ok := genericTypeOf(typeName)
+	params := t.typeParameters[name]
+	if !ok || len(params) == 0 || len(args) != len(params) {
+		return nil, false
+	}
+
+	bindings := make(map[string]string, len(params))
+	for j, param := range params {
+		bindings[param] = args[j]
+	}
+	fields := make(map[string]string, len(t.types[name]))
+	for field, fieldType := range t.types[name] {
+		fields[field] = substitute(fieldType, bindings)
+	}
+	return fields, true
+}
+
+// bindTypes matches the type of a parameter of a generic function against
+// the type of its argument, binding the type parameters the parameter type
+// uses that are not bound yet. Types such as nil and the empty array fit
+// many types and bind nothing.
+func bindTypes(paramType, argType string, bindings map[string]string) {
+	if bound, isParam := bindings[paramType]; isParam {
+		switch argType {
+		case nilType, arrayType, emptyMapType, resultType, optionType, "void":
+		default:
+			if bound == "" {
+				bindings[paramType] = argType
+			}
+		}
+		return
+	}
+
+	if paramBase, ok := optionalTypeOf(paramType); ok {
+		argBase, _ := optionalTypeOf(argType)
+		bindTypes(paramBase, argBase, bindings)
+		return
+	}
+	if paramElem, ok := arrayTypeOf(paramType); ok {
+		if argElem, ok := arrayTypeOf(argType); ok {
+			bindTypes(paramElem, argElem, bindings)
+		}
+		return
+	}
+	if paramFn, ok := functionTypeOf(paramType); ok {
+		argFn, ok := functionTypeOf(argType)
+		if ok && len(argFn.Parameters) == len(paramFn.Parameters) {
+			for j := range paramFn.Parameters {
+				bindTypes(paramFn.Parameters[j], argFn.Parameters[j], bindings)
+			}
+			bindTypes(paramFn.ReturnType, argFn.ReturnType, bindings)
+		}
+		return
+	}
+	paramName, paramArgs, ok := genericTypeOf(paramType)
+	argName, argArgs, argOk := genericTypeOf(argType)
+	if ok && argOk && paramName == argName && len(paramArgs) == len(argArgs) {
+		for j := range paramArgs {
+			bindTypes(paramArgs[j], argArgs[j], bindings)
+		}
+	}
+}
+
+// checkGenericCall checks a call of a generic function, inferring its type
+// arguments from the types of the arguments, and returns the return type
+// with the inferred types in place of the type parameters
+func (t *TypeChecker) checkGenericCall(fn FunctionType, name string, args []ast.Expression) (string, error) {
+	argTypes := make([]string, len(args))
+	for j, arg := range args {
+		argType, err := t.checkExpression(arg)
+		if err != nil {
+			return "", err
+		}
+		argTypes[j] = argType
+	}
+
+	bindings := make(map[string]string, len(fn.TypeParameters))
+	for _, param := range fn.TypeParameters {
+		bindings[param] = ""
+	}
+	for j, paramType := range fn.Parameters {
+		bindTypes(paramType, argTypes[j], bindings)
+	}
+	for _, param := range fn.TypeParameters {
+		if bindings[param] == "" {
+			return "", errcode.Errorf("BRN0316", "cannot infer type %s of function %s from its arguments", param, name)
+		}
+	}
+
+	for j, paramType := range fn.Parameters {
+		expectedType := substitute(paramType, bindings)
+		if expectedType != "any" && !t.assignable(expectedType, argTypes[j]) {
+			return "", errcode.Errorf("BRN0307", "argument %d of function %s expects %s but got %s",
+				j+1, name, expectedType, argTypes[j])
+		}
+	}
+	return substitute(fn.ReturnType, bindings), nil
+}
@@ -0,0 +1,39 @@
+package typechecker
+
+import "reflect"
+
+// inspect calls visit for node and, for as long as visit returns true for
+// them, for the nodes inside it in the order of their fields
+func inspect(node any, visit func(node any) bool) {
+	walkNodes(reflect.ValueOf(node), visit)
+}
+
+func walkNodes(v reflect.Value, visit func(node any) bool) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		if v.Kind() == reflect.Pointer && v.Elem().Kind() == reflect.Struct {
+			if !visit(v.Interface()) {
+				return
+			}
+		}
+		walkNodes(v.Elem(), visit)
+	case reflect.Slice:
+		for k := range v.Len() {
+			walkNodes(v.Index(k), visit)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			walkNodes(iter.Value(), visit)
+		}
+	case reflect.Struct:
+		for k := range v.NumField() {
+			if v.Type().Field(k).IsExported() {
+				walkNodes(v.Field(k), visit)
+			}
+		}
+	}
+}
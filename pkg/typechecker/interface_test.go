@@ -0,0 +1,61 @@
+package typechecker_test
+
+import "testing"
+
+const shapes = `
+interface Shape {
+    fun area(): int
+}
+
+class Square implements Shape {
+    var side: int
+    fun init(square: Square, side: int) { this.side = side }
+    fun area(square: Square): int { return this.side * this.side }
+}
+
+class Rect implements Shape {
+    var w: int
+    var h: int
+    fun init(rect: Rect, w: int, h: int) { this.w = w; this.h = h }
+    fun area(rect: Rect): int { return this.w * this.h }
+}
+`
+
+func TestAssignImplementationToInterfaceField(t *testing.T) {
+	_, errs := check(t, shapes+`
+type Holder { s: Shape }
+var h = Holder{s: Square(1)}
+h.s = Rect(5, 5)
+`)
+	if len(errs) > 0 {
+		t.Errorf("unexpected error: %v", errs[0])
+	}
+}
+
+func TestArrayLiteralOfInterface(t *testing.T) {
+	_, errs := check(t, shapes+`
+var shapes: [Shape] = [Square(2), Rect(2, 3)]
+var area: int = shapes[1].area()
+`)
+	if len(errs) > 0 {
+		t.Errorf("unexpected error: %v", errs[0])
+	}
+}
+
+func TestArrayLiteralElementNotOfDeclaredType(t *testing.T) {
+	_, errs := check(t, shapes+`
+var shapes: [Shape] = [Square(2), 3]
+`)
+	if len(errs) == 0 {
+		t.Error("an int was accepted in an array of Shape")
+	}
+}
+
+func TestArrayLiteralOfAny(t *testing.T) {
+	_, errs := check(t, `
+var values: [any] = [1, "two", [3]]
+`)
+	if len(errs) > 0 {
+		t.Errorf("unexpected error: %v", errs[0])
+	}
+}
@@ -0,0 +1,100 @@
+package typechecker
+
+import (
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
+)
+
+// assignable is like the assignable function, and in addition lets an
+// instance of a class, or an array of them, stand for an interface the
+// class implements
+func (t *TypeChecker) assignable(to, from string) bool {
+	if assignable(to, from) || t.implementations[from][to] {
+		return true
+	}
+	if toBase, ok := optionalTypeOf(to); ok && from != nilType {
+		fromBase, _ := optionalTypeOf(from)
+		return t.assignable(toBase, fromBase)
+	}
+	toElem, toArray := arrayTypeOf(to)
+	fromElem, fromArray := arrayTypeOf(from)
+	return toArray && fromArray && t.assignable(toElem, fromElem)
+}
+
+// registerInterface registers an interface as a type whose methods take a
+// value of the interface first, as the methods of a class take an instance,
+// so that calls on a value of the interface check like other method calls
+func (t *TypeChecker) registerInterface(decl *ast.InterfaceDeclaration) error {
+	t.setErrorAt(decl)
+	if _, exists := t.types[decl.Name]; exists {
+		return errcode.Errorf("BRN0314", "type %s is already defined", decl.Name)
+	}
+
+	methods := make(map[string]FunctionType, len(decl.Methods))
+	for _, method := range decl.Methods {
+		if _, exists := methods[method.Name]; exists {
+			t.setErrorAt(method)
+			return errcode.Errorf("BRN0314", "method %s is already defined in interface %s", method.Name, decl.Name)
+		}
+		paramTypes := []string{decl.Name}
+		for _, param := range method.Parameters {
+			paramTypes = append(paramTypes, param.Type)
+		}
+		methods[method.Name] = FunctionType{
+			Parameters: paramTypes,
+			ReturnType: method.ReturnType,
+		}
+	}
+
+	t.types[decl.Name] = make(map[string]string)
+	t.classes[decl.Name] = methods
+	t.interfaces[decl.Name] = decl
+	return nil
+}
+
+// registerImplements records the interfaces a class implements, so that
+// its instances fit them before checkImplements has looked at the class
+func (t *TypeChecker) registerImplements(class *ast.ClassDeclaration) error {
+	for _, name := range class.Interfaces {
+		if _, exists := t.interfaces[name]; !exists {
+			t.setErrorAt(class)
+			return errcode.Errorf("BRN0317", "class %s implements %s, which is not an interface", class.Name, name)
+		}
+		if t.implementations[class.Name] == nil {
+			t.implementations[class.Name] = make(map[string]bool)
+		}
+		t.implementations[class.Name][name] = true
+	}
+	return nil
+}
+
+// checkImplements checks that a class has each method of the interfaces it
+// implements, taking an instance of the class and then the parameters of
+// the interface method
+func (t *TypeChecker) checkImplements(class *ast.ClassDeclaration) error {
+	t.setErrorAt(class)
+	for _, name := range class.Interfaces {
+		for _, method := range t.interfaces[name].Methods {
+			want := t.classes[name][method.Name]
+			want.Parameters = want.Parameters[1:]
+
+			have, exists := t.classes[class.Name][method.Name]
+			if !exists {
+				return errcode.Errorf("BRN0317", "class %s does not implement %s: missing method %s", class.Name, name, method.Name)
+			}
+			if t.private[class.Name+"."+method.Name] {
+				return errcode.Errorf("BRN0317", "class %s does not implement %s: method %s is private", class.Name, name, method.Name)
+			}
+			if len(have.Parameters) == 0 || have.Parameters[0] != class.Name {
+				return errcode.Errorf("BRN0317", "class %s does not implement %s: method %s must take a %s as its first parameter",
+					class.Name, name, method.Name, class.Name)
+			}
+			have.Parameters = have.Parameters[1:]
+			if have.String() != want.String() {
+				return errcode.Errorf("BRN0317", "class %s does not implement %s: method %s has type %s after the instance, not %s",
+					class.Name, name, method.Name, have, want)
+			}
+		}
+	}
+	return nil
+}
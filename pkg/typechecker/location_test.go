@@ -0,0 +1,36 @@
+package typechecker_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/burnlang/burn/pkg/ast"
+)
+
+func TestCallErrorLocations(t *testing.T) {
+	tests := []struct {
+		source  string
+		message string
+		at      ast.Location
+	}{
+		{"print(helper(2))", "undefined function: helper", ast.Location{Line: 1, Column: 7}},
+		{"fun main() {\n    var f = fun() {}\n    var g: any = f\n    g()\n}", "cannot call g of type any", ast.Location{Line: 4, Column: 5}},
+		{"print(len(1, 2))", "function len expects 1 arguments but got 2", ast.Location{Line: 1, Column: 7}},
+		{"fun twice(n: int): int { return n * 2 }\nprint(twice(true))", "argument 1 of function twice", ast.Location{Line: 2, Column: 13}},
+	}
+	for _, test := range tests {
+		_, errs := check(t, test.source)
+		if len(errs) == 0 {
+			t.Errorf("no error for %q", test.source)
+			continue
+		}
+		err := errs[len(errs)-1]
+		if !strings.Contains(err.Error(), test.message) {
+			t.Errorf("%q: got error %q, want %q", test.source, err, test.message)
+		}
+		if err.Location != test.at {
+			t.Errorf("%q: error %q at %d:%d, want %d:%d", test.source, err,
+				err.Location.Line, err.Location.Column, test.at.Line, test.at.Column)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package typechecker
+
+import (
+	"strings"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
+)
+
+// emptyMapType is the type of the empty map literal {:}, which fits any map
+// type
+const emptyMapType = "map"
+
+// mapTypeOf splits a map type such as map<string, int> into its key and
+// value types
+func mapTypeOf(typeName string) (string, string, bool) {
+	inner, ok := strings.CutPrefix(typeName, "map<")
+	if !ok || !strings.HasSuffix(inner, ">") {
+		return "", "", false
+	}
+	inner = inner[:len(inner)-1]
+
+	depth := 0
+	for j := 0; j < len(inner); j++ {
+		switch inner[j] {
+		case '(', '<', '[':
+			depth++
+		case ')', '>', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				return inner[:j], inner[j+len(", "):], true
+			}
+		}
+	}
+	return "", "", false
+}
+
+func isMapKeyType(typeName string) bool {
+	switch typeName {
+	case "int", "float", "string", "bool":
+		return true
+	}
+	return false
+}
+
+// checkMapType fails for a map type whose key type cannot be a map key
+func checkMapType(typeName string) error {
+	keyType, _, ok := mapTypeOf(typeName)
+	if ok && !isMapKeyType(keyType) {
+		return errcode.Errorf("BRN0323", "map keys must be int, float, string or bool, got %s", keyType)
+	}
+	return nil
+}
+
+func (t *TypeChecker) checkMapLiteralExpression(expr *ast.MapLiteralExpression) (string, error) {
+	if len(expr.Entries) == 0 {
+		return emptyMapType, nil
+	}
+
+	var keyType, valueType string
+	for j, entry := range expr.Entries {
+		k, err := t.checkExpression(entry.Key)
+		if err != nil {
+			return "", err
+		}
+		v, err := t.checkExpression(entry.Value)
+		if err != nil {
+			return "", err
+		}
+
+		if j == 0 {
+			if !isMapKeyType(k) {
+				return "", errcode.Errorf("BRN0323", "map keys must be int, float, string or bool, got %s", k)
+			}
+			keyType, valueType = k, v
+			continue
+		}
+		if k != keyType {
+			return "", errcode.Errorf("BRN0306", "map keys must be of the same type, got %s and %s", keyType, k)
+		}
+		if !t.assignable(valueType, v) {
+			return "", errcode.Errorf("BRN0306", "map values must be of the same type, got %s and %s", valueType, v)
+		}
+	}
+
+	return "map<" + keyType + ", " + valueType + ">", nil
+}
+
+// checkMapKey checks a key used with a map of type mapType and returns the
+// type of the values of the map
+func (t *TypeChecker) checkMapKey(mapType string, key ast.Expression) (string, error) {
+	keyType, valueType, _ := mapTypeOf(mapType)
+	indexType, err := t.checkExpression(key)
+	if err != nil {
+		return "", err
+	}
+	if indexType != keyType && !(keyType == "float" && indexType == "int") {
+		return "", errcode.Errorf("BRN0323", "key of type %s cannot be used with a map of type %s", indexType, mapType)
+	}
+	return valueType, nil
+}
+
+func (t *TypeChecker) checkIndexAssignmentExpression(expr *ast.IndexAssignmentExpression) (string, error) {
+	targetType, err := t.checkExpression(expr.Array)
+	if err != nil {
+		return "", err
+	}
+	valueType, err := t.checkExpression(expr.Value)
+	if err != nil {
+		return "", err
+	}
+
+	if _, _, isMap := mapTypeOf(targetType); isMap {
+		elemType, err := t.checkMapKey(targetType, expr.Index)
+		if err != nil {
+			return "", err
+		}
+		if !t.assignable(elemType, valueType) {
+			return "", errcode.Errorf("BRN0306", "cannot store %s in a map of type %s", valueType, targetType)
+		}
+		return valueType, nil
+	}
+
+	if !isArray(targetType) {
+		return "", errcode.Errorf("BRN0323", "cannot index into non-array type: %s", targetType)
+	}
+	indexType, err := t.checkExpression(expr.Index)
+	if err != nil {
+		return "", err
+	}
+	if indexType != "int" {
+		return "", errcode.Errorf("BRN0323", "array index must be an integer, got %s", indexType)
+	}
+	if elemType, ok := arrayTypeOf(targetType); ok && !t.assignable(elemType, valueType) {
+		return "", errcode.Errorf("BRN0306", "cannot store %s in an array of type %s", valueType, targetType)
+	}
+	return valueType, nil
+}
+
+// mapFunctions are the builtins taking a map, whose types depend on the
+// type of the map
+var mapFunctions = map[string]bool{"keys": true, "values": true, "has": true, "delete": true}
+
+// checkMapCall checks a call of one of the mapFunctions
+func (t *TypeChecker) checkMapCall(name string, args []ast.Expression) (string, error) {
+	count := 2
+	if name == "keys" || name == "values" {
+		count = 1
+	}
+	if len(args) != count {
+		return "", errcode.Errorf("BRN0308", "function %s expects %d arguments but got %d", name, count, len(args))
+	}
+
+	mapType, err := t.checkExpression(args[0])
+	if err != nil {
+		return "", err
+	}
+	if _, _, isMap := mapTypeOf(mapType); !isMap {
+		return "", errcode.Errorf("BRN0307", "argument 1 of function %s expects a map but got %s", name, mapType)
+	}
+
+	switch name {
+	case "has", "delete":
+		if _, err := t.checkMapKey(mapType, args[1]); err != nil {
+			return "", err
+		}
+		return "bool", nil
+	}
+	keyType, valueType, _ := mapTypeOf(mapType)
+	if name == "keys" {
+		return "[" + keyType + "]", nil
+	}
+	return "[" + valueType + "]", nil
+}
@@ -0,0 +1,220 @@
+package typechecker
+
+import (
+	"maps"
+	"strings"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
+)
+
+// optionalTypeOf returns the type without nil of an optional type such as
+// string?, which is that type or nil
+func optionalTypeOf(typeName string) (string, bool) {
+	return strings.CutSuffix(typeName, "?")
+}
+
+// optionalOf returns the optional type of values of type typeName or nil,
+// which is typeName itself when it is already optional or any
+func optionalOf(typeName string) string {
+	if _, ok := optionalTypeOf(typeName); ok || typeName == "any" || typeName == "void" {
+		return typeName
+	}
+	return typeName + "?"
+}
+
+// checkNotNil fails for a value of an optional type, which must be checked
+// against nil before it is used as a value of the type without nil
+func checkNotNil(typeName string) error {
+	if _, ok := optionalTypeOf(typeName); ok {
+		return errcode.Errorf("BRN0315", "value of type %s may be nil: check it against nil first", typeName)
+	}
+	return nil
+}
+
+// checkCoalesceOperation checks value ?? fallback, whose type is the type
+// without nil of the value unified with that of the fallback
+func checkCoalesceOperation(leftType, rightType string) (string, error) {
+	if leftType == nilType {
+		return rightType, nil
+	}
+	baseType, optional := optionalTypeOf(leftType)
+	if !optional && !nullable(leftType) {
+		return "", errcode.Errorf("BRN0315", "left operand of ?? cannot be nil: %s", leftType)
+	}
+	if exprType, ok := unify(baseType, rightType); ok {
+		return exprType, nil
+	}
+	return "", errcode.Errorf("BRN0329", "operands of ?? have different types: %s and %s", leftType, rightType)
+}
+
+// nilChecks returns the variables of optional types that a condition
+// shows are not nil, with their types without nil, when it is true and
+// when it is false, as x != nil does for x when it is true
+func (t *TypeChecker) nilChecks(cond ast.Expression) (map[string]string, map[string]string) {
+	switch e := cond.(type) {
+	case *ast.BinaryExpression:
+		switch e.Operator {
+		case "==", "!=":
+			variable, ok := e.Left.(*ast.VariableExpression)
+			other := e.Right
+			if !ok {
+				variable, ok = e.Right.(*ast.VariableExpression)
+				other = e.Left
+			}
+			if _, isNil := other.(*ast.NilExpression); !ok || !isNil {
+				return nil, nil
+			}
+			baseType, optional := optionalTypeOf(t.variables[variable.Name])
+			if !optional || t.closureAssigned[variable.Name] {
+				return nil, nil
+			}
+			notNil := map[string]string{variable.Name: baseType}
+			if e.Operator == "!=" {
+				return notNil, nil
+			}
+			return nil, notNil
+		case "&&":
+			leftTrue, _ := t.nilChecks(e.Left)
+			rightTrue, _ := t.nilChecks(e.Right)
+			return merge(leftTrue, rightTrue), nil
+		case "||":
+			_, leftFalse := t.nilChecks(e.Left)
+			_, rightFalse := t.nilChecks(e.Right)
+			return nil, merge(leftFalse, rightFalse)
+		}
+	case *ast.UnaryExpression:
+		if e.Operator == "!" {
+			whenTrue, whenFalse := t.nilChecks(e.Right)
+			return whenFalse, whenTrue
+		}
+	}
+	return nil, nil
+}
+
+// assignedInClosures returns the variables that the lambdas and nested
+// functions of program assign, other than their own parameters and
+// variables. A closure may be called between a nil check of such a
+// variable and its use, so the check does not show it is not nil.
+func assignedInClosures(program []ast.Declaration) map[string]bool {
+	assigned := map[string]bool{}
+
+	// closure walks the body of a closure, whose own parameters and
+	// variables it may assign freely
+	var closure func(params []ast.Parameter, body []ast.Declaration)
+	closure = func(params []ast.Parameter, body []ast.Declaration) {
+		own := map[string]bool{}
+		for _, param := range params {
+			own[param.Name] = true
+		}
+		inspect(body, func(node any) bool {
+			switch node := node.(type) {
+			case *ast.LambdaExpression:
+				closure(node.Parameters, node.Body)
+				return false
+			case *ast.FunctionDeclaration:
+				closure(node.Parameters, node.Body)
+				return false
+			case *ast.VariableDeclaration:
+				own[node.Name] = true
+			case *ast.AssignmentExpression:
+				if !own[node.Name] {
+					assigned[node.Name] = true
+				}
+			}
+			return true
+		})
+	}
+
+	// Top-level functions and methods are not closures, but functions
+	// declared inside them are
+	inFunction := false
+	var visit func(node any) bool
+	visit = func(node any) bool {
+		switch node := node.(type) {
+		case *ast.LambdaExpression:
+			closure(node.Parameters, node.Body)
+			return false
+		case *ast.FunctionDeclaration:
+			if inFunction {
+				closure(node.Parameters, node.Body)
+			} else {
+				inFunction = true
+				inspect(node.Body, visit)
+				inFunction = false
+			}
+			return false
+		}
+		return true
+	}
+	inspect(program, visit)
+	return assigned
+}
+
+func merge(a, b map[string]string) map[string]string {
+	if a == nil {
+		return b
+	}
+	merged := maps.Clone(a)
+	maps.Copy(merged, b)
+	return merged
+}
+
+// narrow gives variables the types without nil that a nil check showed
+// they have, until the function returned by narrowScope is called
+func (t *TypeChecker) narrow(notNil map[string]string) {
+	maps.Copy(t.narrowed, notNil)
+}
+
+// narrowScope returns a function that undoes the narrowing done after
+// narrowScope was called, when the code it applies to ends
+func (t *TypeChecker) narrowScope() func() {
+	saved := maps.Clone(t.narrowed)
+	return func() {
+		for name := range t.narrowed {
+			if _, kept := saved[name]; !kept {
+				delete(t.narrowed, name)
+			}
+		}
+	}
+}
+
+// checkBranch checks statements run when a condition shows the variables
+// of notNil are not nil, and returns the variables known not to be nil
+// when they end, by nil checks or by assignments of values that are not
+// nil
+func (t *TypeChecker) checkBranch(body []ast.Declaration, notNil map[string]string) (map[string]string, error) {
+	restore := t.narrowScope()
+	defer restore()
+	t.narrow(notNil)
+	err := t.checkBlock(body)
+	return maps.Clone(t.narrowed), err
+}
+
+// intersect returns the variables narrowed to the same type in both a and b
+func intersect(a, b map[string]string) map[string]string {
+	both := map[string]string{}
+	for name, typeName := range a {
+		if b[name] == typeName {
+			both[name] = typeName
+		}
+	}
+	return both
+}
+
+// exits reports whether statements always leave the code around them by
+// return, break or continue
+func exits(body []ast.Declaration) bool {
+	if len(body) == 0 {
+		return false
+	}
+	switch last := body[len(body)-1].(type) {
+	case *ast.ReturnStatement, *ast.BreakStatement, *ast.ContinueStatement:
+		return true
+	case *ast.BlockStatement:
+		return exits(last.Statements)
+	case *ast.IfStatement:
+		return exits(last.ThenBranch) && exits(last.ElseBranch)
+	}
+	return false
+}
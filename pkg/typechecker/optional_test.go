@@ -0,0 +1,95 @@
+package typechecker_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNilCheckNarrows(t *testing.T) {
+	_, errs := check(t, `
+fun greet(s: string?): string {
+    if (s == nil) { return "nobody" }
+    return s + "!"
+}
+`)
+	if len(errs) > 0 {
+		t.Errorf("unexpected error: %v", errs[0])
+	}
+}
+
+func TestNilCheckOfVariableAssignedByClosure(t *testing.T) {
+	for _, source := range []string{`
+fun greet(s: string?): int {
+    if (s == nil) { return 1 }
+    var f = fun(): int { s = nil; return 1 }
+    f()
+    print(s + "!")
+    return 0
+}
+`, `
+fun greet(s: string?): int {
+    var f = fun(): int { s = nil; return 1 }
+    if (s != nil) {
+        f()
+        print(s + "!")
+    }
+    return 0
+}
+`} {
+		_, errs := check(t, source)
+		if len(errs) == 0 {
+			t.Errorf("no error for\n%s", source)
+			continue
+		}
+		if msg := errs[0].Error(); !strings.Contains(msg, "may be nil") {
+			t.Errorf("got error %q, want one saying s may be nil", msg)
+		}
+	}
+}
+
+func TestAssignmentNarrows(t *testing.T) {
+	for _, source := range []string{`
+fun greet(s: string?): string {
+    if (s == nil) { s = "d" }
+    return s + "!"
+}
+`, `
+fun greet(s: string?): string {
+    if (s != nil) { print(s) } else { s = "d" }
+    return s + "!"
+}
+`, `
+fun greet(s: string?): string {
+    s = "d"
+    return s + "!"
+}
+`} {
+		if _, errs := check(t, source); len(errs) > 0 {
+			t.Errorf("%s: unexpected error: %v", source, errs[0])
+		}
+	}
+}
+
+func TestAssignmentInOneBranchDoesNotNarrow(t *testing.T) {
+	for _, source := range []string{`
+fun greet(s: string?, b: bool): string {
+    if (b) { s = "d" }
+    return s + "!"
+}
+`, `
+fun greet(s: string?, b: bool): string {
+    while (b) { s = "d" }
+    return s + "!"
+}
+`, `
+fun greet(s: string?): string {
+    if (s == nil) { s = "d" } else { s = nil }
+    return s + "!"
+}
+`} {
+		_, errs := check(t, source)
+		if len(errs) == 0 || !strings.Contains(errs[0].Error(), "may be nil") {
+			t.Errorf("%s: got %v, want a may be nil error", source, errs)
+		}
+	}
+}
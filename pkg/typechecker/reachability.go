@@ -0,0 +1,141 @@
+package typechecker
+
+import (
+	"cmp"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/lexer"
+)
+
+// warnUnreachable warns about the first statement after a return, break or
+// continue in body and in the blocks nested in it. Lambdas and nested
+// functions are left to the check of their own bodies.
+func (t *TypeChecker) warnUnreachable(body []ast.Declaration) {
+	for j, stmt := range body {
+		if j > 0 && exits(body[j-1:j]) {
+			t.warn(stmt, "BRN0326", "unreachable code")
+			return
+		}
+		switch s := stmt.(type) {
+		case *ast.BlockStatement:
+			t.warnUnreachable(s.Statements)
+		case *ast.IfStatement:
+			t.warnUnreachable(s.ThenBranch)
+			t.warnUnreachable(s.ElseBranch)
+		case *ast.WhileStatement:
+			t.warnUnreachable(s.Body)
+		case *ast.ForStatement:
+			t.warnUnreachable(s.Body)
+		case *ast.ForInStatement:
+			t.warnUnreachable(s.Body)
+		case *ast.MatchStatement:
+			for _, matchCase := range s.Cases {
+				t.warnUnreachable(matchCase.Body)
+			}
+		}
+	}
+}
+
+// warnConstantCondition warns about the condition of an if statement that
+// is always true or always false, so that one of its branches never runs
+func (t *TypeChecker) warnConstantCondition(stmt *ast.IfStatement) {
+	value, constant := constantCondition(stmt.Condition)
+	switch {
+	case !constant:
+	case value && len(stmt.ElseBranch) > 0:
+		t.warn(stmt, "BRN0327", "condition is always true, so the else branch never runs")
+	case value:
+		t.warn(stmt, "BRN0327", "condition is always true")
+	default:
+		t.warn(stmt, "BRN0327", "condition is always false, so the if branch never runs")
+	}
+}
+
+// constantCondition returns the value of a condition that is the same every
+// time it is evaluated, because it is made of literals, such as 1 > 2, or
+// because a literal decides it, such as x || true
+func constantCondition(expr ast.Expression) (bool, bool) {
+	switch e := expr.(type) {
+	case *ast.LiteralExpression:
+		if e.Type == "bool" {
+			return e.Value == "true", true
+		}
+	case *ast.GroupingExpression:
+		return constantCondition(e.Expression)
+	case *ast.UnaryExpression:
+		if value, ok := constantCondition(e.Right); ok && e.Operator == "!" {
+			return !value, true
+		}
+	case *ast.BinaryExpression:
+		left, leftOk := constantCondition(e.Left)
+		right, rightOk := constantCondition(e.Right)
+		switch e.Operator {
+		case "&&":
+			if (leftOk && !left) || (rightOk && !right) {
+				return false, true
+			}
+			return true, leftOk && rightOk
+		case "||":
+			if (leftOk && left) || (rightOk && right) {
+				return true, true
+			}
+			return false, leftOk && rightOk
+		}
+		return constantComparison(e)
+	}
+	return false, false
+}
+
+// constantComparison returns the value of a comparison of two number, two
+// string or two bool literals
+func constantComparison(e *ast.BinaryExpression) (bool, bool) {
+	left, leftOk := e.Left.(*ast.LiteralExpression)
+	right, rightOk := e.Right.(*ast.LiteralExpression)
+	if !leftOk || !rightOk || left.Type != right.Type {
+		return false, false
+	}
+
+	l, lOk := left.Value.(string)
+	r, rOk := right.Value.(string)
+	if !lOk || !rOk {
+		return false, false
+	}
+	var order int
+	switch left.Type {
+	case "number":
+		if lexer.IsBigInt(l) || lexer.IsDecimal(l) || lexer.IsBigInt(r) || lexer.IsDecimal(r) {
+			return false, false
+		}
+		lNumber, lErr := lexer.ParseNumber(l)
+		rNumber, rErr := lexer.ParseNumber(r)
+		if lErr != nil || rErr != nil {
+			return false, false
+		}
+		order = cmp.Compare(lNumber, rNumber)
+	case "string":
+		order = cmp.Compare(l, r)
+	case "bool":
+		if e.Operator != "==" && e.Operator != "!=" {
+			return false, false
+		}
+		order = cmp.Compare(l, r)
+	default:
+		return false, false
+	}
+
+	switch e.Operator {
+	case "==":
+		return order == 0, true
+	case "!=":
+		return order != 0, true
+	case "<":
+		return order < 0, true
+	case "<=":
+		return order <= 0, true
+	case ">":
+		return order > 0, true
+	case ">=":
+		return order >= 0, true
+	}
+	return false, false
+}
@@ -0,0 +1,111 @@
+package typechecker
+
+import (
+	"strings"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
+)
+
+// resultType and optionType are the types of Err(...) and None(), which
+// fit any Result or Option type
+const (
+	resultType = "Result"
+	optionType = "Option"
+)
+
+// wrapperTypeOf splits a type such as Result<int> or Option<string> into
+// Result or Option and the type of the value it wraps
+func wrapperTypeOf(typeName string) (string, string, bool) {
+	for _, kind := range []string{resultType, optionType} {
+		inner, ok := strings.CutPrefix(typeName, kind+"<")
+		if ok && strings.HasSuffix(inner, ">") {
+			return kind, inner[:len(inner)-1], true
+		}
+	}
+	return "", "", false
+}
+
+var resultFunctions = map[string]bool{
+	"Ok": true, "Err": true, "Some": true, "None": true,
+	"isOk": true, "isErr": true, "isSome": true, "isNone": true,
+	"unwrap": true, "unwrapOr": true, "unwrapErr": true,
+}
+
+// checkResultCall checks a call of one of the resultFunctions
+func (t *TypeChecker) checkResultCall(name string, args []ast.Expression) (string, error) {
+	count := 1
+	switch name {
+	case "None":
+		count = 0
+	case "unwrapOr":
+		count = 2
+	}
+	if len(args) != count {
+		return "", errcode.Errorf("BRN0308", "function %s expects %d arguments but got %d", name, count, len(args))
+	}
+
+	switch name {
+	case "None":
+		return optionType, nil
+	case "Ok", "Some", "Err":
+		argType, err := t.checkExpression(args[0])
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case name == "Err":
+			if argType != "string" {
+				return "", errcode.Errorf("BRN0307", "argument 1 of function Err expects string but got %s", argType)
+			}
+			return resultType, nil
+		case argType == nilType || argType == "void":
+			return "", errcode.Errorf("BRN0316", "function %s needs a value, got %s", name, argType)
+		case name == "Ok":
+			return resultType + "<" + argType + ">", nil
+		}
+		return optionType + "<" + argType + ">", nil
+	}
+
+	argType, err := t.checkExpression(args[0])
+	if err != nil {
+		return "", err
+	}
+	kind, inner, ok := wrapperTypeOf(argType)
+	if !ok && (argType == resultType || argType == optionType) {
+		kind, ok = argType, true
+	}
+
+	switch name {
+	case "isOk", "isErr", "unwrapErr":
+		if !ok || kind != resultType {
+			return "", errcode.Errorf("BRN0307", "argument 1 of function %s expects a Result but got %s", name, argType)
+		}
+		if name == "unwrapErr" {
+			return "string", nil
+		}
+		return "bool", nil
+	case "isSome", "isNone":
+		if !ok || kind != optionType {
+			return "", errcode.Errorf("BRN0307", "argument 1 of function %s expects an Option but got %s", name, argType)
+		}
+		return "bool", nil
+	}
+
+	if !ok {
+		return "", errcode.Errorf("BRN0307", "argument 1 of function %s expects a Result or an Option but got %s", name, argType)
+	}
+	if inner == "" {
+		return "", errcode.Errorf("BRN0307", "argument 1 of function %s needs a value type, as in %s<int>, but got %s", name, argType, argType)
+	}
+	if name == "unwrapOr" {
+		defaultType, err := t.checkExpression(args[1])
+		if err != nil {
+			return "", err
+		}
+		if !t.assignable(inner, defaultType) && !(inner == "float" && defaultType == "int") {
+			return "", errcode.Errorf("BRN0307", "argument 2 of function unwrapOr expects %s but got %s", inner, defaultType)
+		}
+	}
+	return inner, nil
+}
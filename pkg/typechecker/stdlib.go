@@ -3,7 +3,7 @@ package typechecker
 func initStandardLibrary(tc *TypeChecker) {
 
 	tc.functions["print"] = FunctionType{
-		Parameters: []string{"any"},
+		Parameters: []string{"...any"},
 		ReturnType: "",
 	}
 
@@ -12,6 +12,143 @@ func initStandardLibrary(tc *TypeChecker) {
 		ReturnType: "string",
 	}
 
+	tc.functions["toInt"] = FunctionType{
+		Parameters: []string{"any"},
+		ReturnType: "int",
+	}
+
+	tc.functions["toFloat"] = FunctionType{
+		Parameters: []string{"any"},
+		ReturnType: "float",
+	}
+
+	tc.functions["toBigInt"] = FunctionType{
+		Parameters: []string{"any"},
+		ReturnType: "bigint",
+	}
+
+	tc.functions["toDecimal"] = FunctionType{
+		Parameters: []string{"any"},
+		ReturnType: "decimal",
+	}
+
+	tc.functions["roundDecimal"] = FunctionType{
+		Parameters: []string{"decimal", "int", "string"},
+		ReturnType: "decimal",
+	}
+
+	tc.functions["formatDecimal"] = FunctionType{
+		Parameters: []string{"decimal", "int"},
+		ReturnType: "string",
+	}
+
+	tc.functions["len"] = FunctionType{
+		Parameters: []string{"any"},
+		ReturnType: "int",
+	}
+
+	tc.functions["typeof"] = FunctionType{
+		Parameters: []string{"any"},
+		ReturnType: "string",
+	}
+
+	// clone is checked by checkCallExpression, as its result has the type
+	// of its argument
+	tc.functions["clone"] = FunctionType{
+		Parameters: []string{"any"},
+		ReturnType: "any",
+	}
+
+	tc.functions["contains"] = FunctionType{
+		TypeParameters: []string{"T"},
+		Parameters:     []string{"[T]", "T"},
+		ReturnType:     "bool",
+	}
+
+	tc.functions["lenBytes"] = FunctionType{
+		Parameters: []string{"string"},
+		ReturnType: "int",
+	}
+
+	// The map functions are checked by checkMapCall
+	tc.functions["keys"] = FunctionType{
+		Parameters: []string{"any"},
+		ReturnType: "array",
+	}
+
+	tc.functions["values"] = FunctionType{
+		Parameters: []string{"any"},
+		ReturnType: "array",
+	}
+
+	tc.functions["has"] = FunctionType{
+		Parameters: []string{"any", "any"},
+		ReturnType: "bool",
+	}
+
+	tc.functions["delete"] = FunctionType{
+		Parameters: []string{"any", "any"},
+		ReturnType: "bool",
+	}
+
+	// The Result and Option functions are checked by checkResultCall
+	for name := range resultFunctions {
+		tc.functions[name] = FunctionType{
+			Parameters: []string{"any"},
+			ReturnType: "any",
+		}
+	}
+
+	tc.functions["tryToInt"] = FunctionType{
+		Parameters: []string{"string"},
+		ReturnType: "Result<int>",
+	}
+
+	tc.functions["tryToFloat"] = FunctionType{
+		Parameters: []string{"string"},
+		ReturnType: "Result<float>",
+	}
+
+	tc.functions["toBytes"] = FunctionType{
+		Parameters: []string{"string"},
+		ReturnType: "bytes",
+	}
+
+	tc.functions["bytesOf"] = FunctionType{
+		Parameters: []string{"[int]"},
+		ReturnType: "bytes",
+	}
+
+	tc.functions["decodeUTF8"] = FunctionType{
+		Parameters: []string{"bytes"},
+		ReturnType: "Result<string>",
+	}
+
+	tc.functions["hexEncode"] = FunctionType{
+		Parameters: []string{"bytes"},
+		ReturnType: "string",
+	}
+
+	tc.functions["hexDecode"] = FunctionType{
+		Parameters: []string{"string"},
+		ReturnType: "Result<bytes>",
+	}
+
+	tc.functions["base64Encode"] = FunctionType{
+		Parameters: []string{"bytes"},
+		ReturnType: "string",
+	}
+
+	tc.functions["base64Decode"] = FunctionType{
+		Parameters: []string{"string"},
+		ReturnType: "Result<bytes>",
+	}
+
+	tc.functions["assert"] = FunctionType{
+		Parameters: []string{"bool", "string"},
+		ReturnType: "",
+	}
+
 	tc.functions["input"] = FunctionType{
 		Parameters: []string{"string"},
 		ReturnType: "string",
@@ -0,0 +1,218 @@
+package typechecker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
+)
+
+// Warning is a diagnostic that does not stop the program from running
+type Warning struct {
+	// Code is the code of the warning, such as BRN0320
+	Code     string
+	Message  string
+	Position int
+	Location ast.Location
+}
+
+// localVar tracks a variable or parameter of a function so that it can be
+// reported when it is never read
+type localVar struct {
+	name      string
+	node      ast.Node
+	used      bool
+	parameter bool
+}
+
+// SetStrict enables the extra diagnostics of strict mode: warnings for the
+// any type, and errors for implicit int/float conversions, unused local
+// variables, locals or parameters that shadow globals and changes to the
+// fields of structs held by constants.
+func (t *TypeChecker) SetStrict(strict bool) {
+	t.strict = strict
+}
+
+// Warnings returns the warnings collected by the last Check, in the order
+// of their positions: unused variables and parameters, unreachable code and
+// conditions that are always true or false, and uses of any in strict mode
+func (t *TypeChecker) Warnings() []Warning {
+	sort.SliceStable(t.warnings, func(a, b int) bool {
+		return t.warnings[a].Position < t.warnings[b].Position
+	})
+	return t.warnings
+}
+
+func (t *TypeChecker) warn(node ast.Node, code, format string, args ...interface{}) {
+	t.warnings = append(t.warnings, Warning{Code: code, Message: fmt.Sprintf(format, args...), Position: node.Pos(), Location: node.Loc()})
+}
+
+// checkAnyType warns about an explicit use of the any type in strict mode
+func (t *TypeChecker) checkAnyType(typeName, what string, node ast.Node) {
+	if t.strict && typeName == "any" {
+		t.warn(node, "BRN0328", "%s has type any, which disables type checking", what)
+	}
+}
+
+// checkConstField reports, in strict mode, a change of a field of a struct
+// held by a constant, directly or through other fields, as p.x = 1 is for
+// const p
+func (t *TypeChecker) checkConstField(object ast.Expression, field string) error {
+	if !t.strict {
+		return nil
+	}
+	for {
+		get, ok := object.(*ast.GetExpression)
+		if !ok {
+			break
+		}
+		object = get.Object
+	}
+	if v, ok := object.(*ast.VariableExpression); ok && t.isConst(v.Name) {
+		return errcode.Errorf("BRN0313", "cannot change field %s of constant %s", field, v.Name)
+	}
+	return nil
+}
+
+// beginFunction starts tracking the locals of a function whose enclosing
+// scope was outer, and checks its signature
+func (t *TypeChecker) beginFunction(decl *ast.FunctionDeclaration, outer map[string]string) error {
+	t.outerVars = outer
+	t.locals = nil
+	t.localsByName = make(map[string]*localVar)
+	t.warnUnreachable(decl.Body)
+
+	// Functions of native libraries have no body to use their parameters
+	// in, and methods may use their receiver as this
+	for j, param := range decl.Parameters {
+		if len(decl.Body) == 0 || (j == 0 && param.Type == t.variables["this"]) {
+			continue
+		}
+		local := &localVar{name: param.Name, node: &param, parameter: true}
+		t.locals = append(t.locals, local)
+		t.localsByName[param.Name] = local
+	}
+
+	if !t.strict {
+		return nil
+	}
+
+	t.checkAnyType(decl.ReturnType, fmt.Sprintf("return value of %s", decl.Name), decl)
+	for _, param := range decl.Parameters {
+		t.checkAnyType(param.Type, fmt.Sprintf("parameter %s of %s", param.Name, decl.Name), &param)
+		if err := t.checkShadowing(param.Name, "parameter"); err != nil {
+			t.setErrorAt(&param)
+			return err
+		}
+	}
+	return nil
+}
+
+// endFunction reports the locals and parameters of the current function
+// that were never read. Names starting with _ are meant to be unused. An
+// unused variable is an error in strict mode.
+func (t *TypeChecker) endFunction() error {
+	locals := t.locals
+	t.locals = nil
+	t.localsByName = nil
+	t.outerVars = nil
+
+	var unused []*localVar
+	for _, local := range locals {
+		if !local.used && !strings.HasPrefix(local.name, "_") {
+			unused = append(unused, local)
+		}
+	}
+	sort.Slice(unused, func(a, b int) bool { return unused[a].node.Pos() < unused[b].node.Pos() })
+
+	for _, local := range unused {
+		switch {
+		case local.parameter:
+			t.warn(local.node, "BRN0320", "parameter %s is never used", local.name)
+		case t.strict:
+			t.setErrorAt(local.node)
+			return errcode.Errorf("BRN0320", "variable %s is declared but never used", local.name)
+		default:
+			t.warn(local.node, "BRN0320", "variable %s is declared but never used", local.name)
+		}
+	}
+	return nil
+}
+
+// declareLocal records a variable declared inside a function
+func (t *TypeChecker) declareLocal(decl *ast.VariableDeclaration, annotated string) error {
+	if t.strict {
+		t.checkAnyType(annotated, fmt.Sprintf("variable %s", decl.Name), decl)
+	}
+
+	if t.localsByName == nil {
+		return nil
+	}
+	if t.strict {
+		if err := t.checkShadowing(decl.Name, "variable"); err != nil {
+			return err
+		}
+	}
+
+	local := &localVar{name: decl.Name, node: decl}
+	t.locals = append(t.locals, local)
+	t.localsByName[decl.Name] = local
+	return nil
+}
+
+// markUsed records a read of a local, which may belong to a function
+// enclosing the current one
+func (t *TypeChecker) markUsed(name string) {
+	if local, ok := t.localsByName[name]; ok {
+		local.used = true
+		return
+	}
+	for j := len(t.enclosingLocals) - 1; j >= 0; j-- {
+		if local, ok := t.enclosingLocals[j][name]; ok {
+			local.used = true
+			return
+		}
+	}
+}
+
+// markUsedIn marks every variable read in a statement that failed to check
+// as used. Checking stops at the first error, which may come before some
+// of the reads, and they should not be reported as unused on top of it.
+func (t *TypeChecker) markUsedIn(stmt ast.Declaration) {
+	inspect(stmt, func(node any) bool {
+		if v, ok := node.(*ast.VariableExpression); ok {
+			t.markUsed(v.Name)
+		}
+		return true
+	})
+}
+
+func (t *TypeChecker) checkShadowing(name, kind string) error {
+	if _, exists := t.outerVars[name]; exists {
+		if t.currentFn == closureFn {
+			return errcode.Errorf("BRN0321", "%s %s shadows a variable of an enclosing scope", kind, name)
+		}
+		return errcode.Errorf("BRN0321", "%s %s shadows a global variable", kind, name)
+	}
+	if _, exists := t.functions[name]; exists {
+		return errcode.Errorf("BRN0321", "%s %s shadows the function %s", kind, name, name)
+	}
+	if _, exists := t.types[name]; exists {
+		return errcode.Errorf("BRN0321", "%s %s shadows the type %s", kind, name, name)
+	}
+	return nil
+}
+
+// checkNumericConversion rejects mixing int and float operands in strict
+// mode, where the int operand would otherwise be converted silently
+func (t *TypeChecker) checkNumericConversion(operator, leftType, rightType string) error {
+	if !t.strict {
+		return nil
+	}
+	if (leftType == "int" && rightType == "float") || (leftType == "float" && rightType == "int") {
+		return errcode.Errorf("BRN0309", "implicit conversion between int and float in operator %s, use toFloat or toInt", operator)
+	}
+	return nil
+}
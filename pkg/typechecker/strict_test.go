@@ -0,0 +1,48 @@
+package typechecker_test
+
+import (
+	"testing"
+
+	"github.com/burnlang/burn/pkg/errcode"
+)
+
+func TestNoUnusedWarningsAfterErrors(t *testing.T) {
+	tc, errs := check(t, `
+fun helper(n: int): int { return n }
+fun main(p: int) {
+    var f: any = helper
+    f()
+    print(helperr(p))
+}
+`)
+	if len(errs) == 0 {
+		t.Fatal("no errors")
+	}
+	for _, warning := range tc.Warnings() {
+		t.Errorf("unexpected warning: %s", warning.Message)
+	}
+}
+
+func TestReachabilityWarningCodes(t *testing.T) {
+	tc, errs := check(t, `
+fun main(): int {
+    if (true) { print(1) }
+    if (false) { print(2) }
+    return 1
+    print(3)
+}
+fun unused(n: int) { print(4) }
+`)
+	if len(errs) > 0 {
+		t.Fatal(errs[0])
+	}
+	warnings := tc.Warnings()
+	if len(warnings) != 4 {
+		t.Fatalf("got %d warnings, want 4: %v", len(warnings), warnings)
+	}
+	for _, warning := range warnings {
+		if _, ok := errcode.Lookup(warning.Code); !ok {
+			t.Errorf("warning %q has no code", warning.Message)
+		}
+	}
+}
@@ -1,18 +1,74 @@
 package typechecker
 
 import (
-	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"strings"
 
 	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
 	"github.com/burnlang/burn/pkg/lexer"
 	"github.com/burnlang/burn/pkg/parser"
+	"github.com/burnlang/burn/pkg/resolver"
+	"github.com/burnlang/burn/pkg/stdlib"
 )
 
 type FunctionType struct {
 	Parameters []string
 	ReturnType string
+
+	// TypeParameters are those of a generic function, which each call
+	// infers from its arguments
+	TypeParameters []string
+}
+
+// String returns the type of the function as a value, such as
+// fun(int, int): bool, in the form the parser gives function types
+func (f FunctionType) String() string {
+	returnType := f.ReturnType
+	if returnType == "" {
+		returnType = "void"
+	}
+	return "fun(" + strings.Join(f.Parameters, ", ") + "): " + returnType
+}
+
+// functionTypeOf parses a function type such as fun(int, int): bool back
+// into its parameter and return types
+func functionTypeOf(typeName string) (FunctionType, bool) {
+	if !strings.HasPrefix(typeName, "fun(") {
+		return FunctionType{}, false
+	}
+
+	fn := FunctionType{Parameters: []string{}}
+	start, depth := len("fun("), 0
+	for j := start; j < len(typeName); j++ {
+		switch typeName[j] {
+		case '(', '<', '[':
+			depth++
+		case '>', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				fn.Parameters = append(fn.Parameters, typeName[start:j])
+				start = j + len(", ")
+			}
+		case ')':
+			if depth > 0 {
+				depth--
+				continue
+			}
+			if j > start {
+				fn.Parameters = append(fn.Parameters, typeName[start:j])
+			}
+			returnType, ok := strings.CutPrefix(typeName[j+1:], ": ")
+			if !ok {
+				return FunctionType{}, false
+			}
+			fn.ReturnType = returnType
+			return fn, true
+		}
+	}
+	return FunctionType{}, false
 }
 
 type TypeChecker struct {
@@ -21,8 +77,74 @@ type TypeChecker struct {
 	variables  map[string]string
 	classes    map[string]map[string]FunctionType
 	arrayTypes map[string]string
+	exprTypes  map[ast.Expression]string
 	currentFn  string
 	errorPos   int
+	errorLoc   ast.Location
+
+	// closureReturn is the return type of the lambda or nested function
+	// being checked, when currentFn is closureFn
+	closureReturn string
+
+	// loops are the labels of the loops around the statement being
+	// checked, within the function being checked, innermost last and empty
+	// for a loop without a label
+	loops []string
+
+	importPaths []string
+	resolver    resolver.Resolver
+	constants   map[string]string
+
+	strict       bool
+	warnings     []Warning
+	outerVars    map[string]string
+	locals       []*localVar
+	localsByName map[string]*localVar
+
+	// enclosingLocals are the locals of the functions enclosing the
+	// lambda or nested function being checked, innermost last
+	enclosingLocals []map[string]*localVar
+
+	// declaredClasses are the classes declared with class, which Name(...)
+	// constructs, as opposed to types with methods and library classes
+	declaredClasses map[string]bool
+
+	// interfaces are the declared interfaces, and implementations the
+	// interfaces each class implements
+	interfaces      map[string]*ast.InterfaceDeclaration
+	implementations map[string]map[string]bool
+
+	// private holds the private members of classes and types, as
+	// Type.member, and hiddenFunctions the private functions of imported
+	// files with the path they were imported from. currentClass is the
+	// class or type whose method is being checked.
+	private         map[string]bool
+	hiddenFunctions map[string]string
+	currentClass    string
+
+	// statics are the types of the static fields of each class, and
+	// staticConsts the ones declared const, as Class.name
+	statics      map[string]map[string]string
+	staticConsts map[string]bool
+
+	// typeParameters are those of each generic type
+	typeParameters map[string][]string
+
+	// narrowed holds the variables of optional types that a nil check
+	// showed are not nil where they are used, with their types without nil
+	narrowed map[string]string
+
+	// closureAssigned holds the variables that a lambda or nested function
+	// of the program being checked assigns, which are never narrowed
+	closureAssigned map[string]bool
+
+	// consts are the variables in scope that are constants, declared with
+	// const or by the host, which cannot be assigned
+	consts map[string]bool
+
+	// report, set while CheckAll runs, records an error of a statement of
+	// a function body so that checking goes on with the next statement
+	report func(error)
 }
 
 func New() *TypeChecker {
@@ -32,8 +154,20 @@ func New() *TypeChecker {
 		variables:  make(map[string]string),
 		classes:    make(map[string]map[string]FunctionType),
 		arrayTypes: make(map[string]string),
+		exprTypes:  make(map[ast.Expression]string),
 		currentFn:  "",
-		errorPos:   0,
+		constants:  make(map[string]string),
+
+		declaredClasses: make(map[string]bool),
+		interfaces:      make(map[string]*ast.InterfaceDeclaration),
+		implementations: make(map[string]map[string]bool),
+		private:         make(map[string]bool),
+		hiddenFunctions: make(map[string]string),
+		statics:         make(map[string]map[string]string),
+		staticConsts:    make(map[string]bool),
+		typeParameters:  make(map[string][]string),
+		narrowed:        make(map[string]string),
+		consts:          make(map[string]bool),
 	}
 
 	initStandardLibrary(tc)
@@ -41,6 +175,8 @@ func New() *TypeChecker {
 }
 
 func (t *TypeChecker) Check(program []ast.Declaration) error {
+	t.warnings = nil
+	t.closureAssigned = assignedInClosures(program)
 
 	if err := t.registerTypes(program); err != nil {
 		return err
@@ -59,12 +195,89 @@ func (t *TypeChecker) Check(program []ast.Declaration) error {
 	return nil
 }
 
+// Error is a type error found by CheckAll, located at a byte offset and at
+// a line and column of the source
+type Error struct {
+	Err      error
+	Position int
+	Location ast.Location
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// CheckAll checks a program like Check, but reports every top-level
+// declaration and every statement of a function body that fails instead of
+// stopping at the first. A variable whose declaration fails is still
+// declared, with the type it declares or any, so that its uses do not cause
+// further errors.
+func (t *TypeChecker) CheckAll(program []ast.Declaration) []*Error {
+	t.warnings = nil
+	t.closureAssigned = assignedInClosures(program)
+
+	var errs []*Error
+	report := func(err error) {
+		errs = append(errs, &Error{Err: err, Position: t.errorPos, Location: t.errorLoc})
+	}
+	t.report = report
+	defer func() { t.report = nil }()
+
+	for _, decl := range program {
+		if typeDef, ok := decl.(*ast.TypeDefinition); ok {
+			if err := t.checkTypeDefinition(typeDef); err != nil {
+				report(err)
+			}
+		} else if iface, ok := decl.(*ast.InterfaceDeclaration); ok {
+			if err := t.registerInterface(iface); err != nil {
+				report(err)
+			}
+		}
+	}
+
+	for _, decl := range program {
+		var err error
+		if fn, ok := decl.(*ast.FunctionDeclaration); ok {
+			err = t.registerFunction(fn)
+		} else if class, ok := decl.(*ast.ClassDeclaration); ok {
+			err = t.registerClass(class)
+		}
+		if err != nil {
+			t.setErrorAt(decl)
+			report(err)
+		}
+	}
+
+	for _, decl := range program {
+		variables, consts, currentFn := t.variables, t.consts, t.currentFn
+		if err := t.checkDeclaration(decl); err != nil {
+			report(err)
+			t.variables, t.consts, t.currentFn = variables, consts, currentFn
+			t.locals, t.localsByName, t.outerVars = nil, nil, nil
+			t.enclosingLocals = nil
+			t.loops = nil
+			t.narrowed = make(map[string]string)
+			t.declareFailed(decl)
+		}
+	}
+
+	return errs
+}
+
 func (t *TypeChecker) registerTypes(program []ast.Declaration) error {
 	for _, decl := range program {
 		if typeDef, ok := decl.(*ast.TypeDefinition); ok {
 			if err := t.checkTypeDefinition(typeDef); err != nil {
 				return err
 			}
+		} else if iface, ok := decl.(*ast.InterfaceDeclaration); ok {
+			if err := t.registerInterface(iface); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
@@ -86,8 +299,11 @@ func (t *TypeChecker) registerFunctions(program []ast.Declaration) error {
 }
 
 func (t *TypeChecker) registerFunction(fn *ast.FunctionDeclaration) error {
+	if fn.Receiver != "" {
+		return t.registerMethod(fn)
+	}
 	if _, exists := t.functions[fn.Name]; exists {
-		return fmt.Errorf("function %s is already defined", fn.Name)
+		return errcode.Errorf("BRN0314", "function %s is already defined", fn.Name)
 	}
 
 	paramTypes := make([]string, len(fn.Parameters))
@@ -96,26 +312,98 @@ func (t *TypeChecker) registerFunction(fn *ast.FunctionDeclaration) error {
 	}
 
 	t.functions[fn.Name] = FunctionType{
+		Parameters:     paramTypes,
+		ReturnType:     fn.ReturnType,
+		TypeParameters: fn.TypeParameters,
+	}
+
+	return nil
+}
+
+// registerMethod registers a method declared on a type, as in
+// fun (p: Point) norm(): float, with the methods of any class of that name
+func (t *TypeChecker) registerMethod(fn *ast.FunctionDeclaration) error {
+	t.setErrorAt(fn)
+	if _, exists := t.types[fn.Receiver]; !exists {
+		return errcode.Errorf("BRN0330", "cannot declare method %s on %s, which is not a type", fn.Name, fn.Receiver)
+	}
+	if _, exists := t.classes[fn.Receiver][fn.Name]; exists {
+		return errcode.Errorf("BRN0314", "method %s is already defined on type %s", fn.Name, fn.Receiver)
+	}
+	t.addMethod(fn)
+	return nil
+}
+
+// addMethod adds a method declared on a type to the methods of the type
+func (t *TypeChecker) addMethod(fn *ast.FunctionDeclaration) {
+	paramTypes := make([]string, len(fn.Parameters))
+	for i, param := range fn.Parameters {
+		paramTypes[i] = param.Type
+	}
+	method := FunctionType{
 		Parameters: paramTypes,
 		ReturnType: fn.ReturnType,
 	}
 
+	if _, exists := t.classes[fn.Receiver]; !exists {
+		t.classes[fn.Receiver] = make(map[string]FunctionType)
+	}
+	t.classes[fn.Receiver][fn.Name] = method
+	t.functions[fn.Receiver+"."+fn.Name] = method
+	if fn.Private {
+		t.private[fn.Receiver+"."+fn.Name] = true
+	}
+}
+
+// registerFields makes the fields a class declares the fields of its type.
+// A class without fields may share its name with a type declared with type.
+func (t *TypeChecker) registerFields(class *ast.ClassDeclaration) error {
+	if len(class.Fields) == 0 {
+		if _, exists := t.types[class.Name]; !exists {
+			t.types[class.Name] = make(map[string]string)
+		}
+		return nil
+	}
+	if _, exists := t.types[class.Name]; exists {
+		t.setErrorAt(class)
+		return errcode.Errorf("BRN0314", "class %s declares fields, but type %s is already defined", class.Name, class.Name)
+	}
+
+	fields := make(map[string]string, len(class.Fields))
+	for _, field := range class.Fields {
+		t.setErrorAt(field)
+		if _, exists := fields[field.Name]; exists {
+			return errcode.Errorf("BRN0314", "field %s is already defined in class %s", field.Name, class.Name)
+		}
+		if err := checkMapType(field.Type); err != nil {
+			return err
+		}
+		fields[field.Name] = field.Type
+	}
+	t.types[class.Name] = fields
 	return nil
 }
 
 func (t *TypeChecker) registerClass(class *ast.ClassDeclaration) error {
 	if _, exists := t.classes[class.Name]; exists {
-		return fmt.Errorf("class %s is already defined", class.Name)
+		return errcode.Errorf("BRN0314", "class %s is already defined", class.Name)
 	}
 
 	classMethods := make(map[string]FunctionType)
 	t.classes[class.Name] = classMethods
+	t.declaredClasses[class.Name] = true
 
-	t.types[class.Name] = make(map[string]string)
+	if err := t.registerFields(class); err != nil {
+		return err
+	}
+	if err := t.registerImplements(class); err != nil {
+		return err
+	}
+	t.markPrivate(class)
 
 	for _, method := range class.Methods {
 		if _, exists := classMethods[method.Name]; exists {
-			return fmt.Errorf("method %s is already defined in class %s", method.Name, class.Name)
+			return errcode.Errorf("BRN0314", "method %s is already defined in class %s", method.Name, class.Name)
 		}
 
 		paramTypes := make([]string, len(method.Parameters))
@@ -137,7 +425,7 @@ func (t *TypeChecker) registerClass(class *ast.ClassDeclaration) error {
 	for _, method := range class.StaticMethods {
 		methodKey := "static." + method.Name
 		if _, exists := classMethods[methodKey]; exists {
-			return fmt.Errorf("static method %s is already defined in class %s", method.Name, class.Name)
+			return errcode.Errorf("BRN0314", "static method %s is already defined in class %s", method.Name, class.Name)
 		}
 
 		paramTypes := make([]string, len(method.Parameters))
@@ -184,6 +472,64 @@ func (t *TypeChecker) CheckFile(filename string) error {
 	return t.Check(program.Declarations)
 }
 
+// DefineConstant declares a global constant supplied by the host rather than
+// the program, such as a -D flag on the command line
+func (t *TypeChecker) DefineConstant(name, typeName string) {
+	t.constants[name] = typeName
+	t.variables[name] = typeName
+	t.consts[name] = true
+}
+
+// DefineFunction declares the signature of a built-in function supplied by
+// the host
+func (t *TypeChecker) DefineFunction(name string, parameters []string, returnType string) error {
+	if _, exists := t.functions[name]; exists {
+		return errcode.Errorf("BRN0314", "function %s is already defined", name)
+	}
+	t.functions[name] = FunctionType{
+		Parameters: parameters,
+		ReturnType: returnType,
+	}
+	return nil
+}
+
+// functionScope returns the variables visible when a function body starts:
+// only the host-defined constants, since functions do not see globals
+func (t *TypeChecker) functionScope() map[string]string {
+	scope := make(map[string]string, len(t.constants))
+	for name, typeName := range t.constants {
+		scope[name] = typeName
+	}
+	return scope
+}
+
+// constScope returns the constants of the scope functionScope returns
+func (t *TypeChecker) constScope() map[string]bool {
+	consts := make(map[string]bool, len(t.constants))
+	for name := range t.constants {
+		consts[name] = true
+	}
+	return consts
+}
+
+// AddImportPath adds a directory in which imports are looked up when they are
+// not found relative to the importing file
+func (t *TypeChecker) AddImportPath(dir string) {
+	t.importPaths = append(t.importPaths, dir)
+}
+
+// SetResolver replaces the default lookup of imports on disk and in the
+// standard library
+func (t *TypeChecker) SetResolver(r resolver.Resolver) {
+	t.resolver = r
+}
+
+// ProcessImports registers the declarations of every module imported by
+// program, resolving relative paths against baseDir.
+func (t *TypeChecker) ProcessImports(program []ast.Declaration, baseDir string) error {
+	return t.processImports(program, baseDir)
+}
+
 func (t *TypeChecker) processImports(program []ast.Declaration, baseDir string) error {
 	for _, decl := range program {
 		if imp, ok := decl.(*ast.ImportDeclaration); ok {
@@ -202,22 +548,30 @@ func (t *TypeChecker) processImports(program []ast.Declaration, baseDir string)
 }
 
 func (t *TypeChecker) processImport(imp *ast.ImportDeclaration, baseDir string) error {
-	importPath := filepath.Join(baseDir, imp.Path)
-	data, err := ioutil.ReadFile(importPath)
+	r := t.resolver
+	if r == nil {
+		r = resolver.Default(t.importPaths...)
+	}
+	module, err := r.Resolve(imp.Path, baseDir)
 	if err != nil {
-		return fmt.Errorf("could not import %s: %v", imp.Path, err)
+		return errcode.Errorf("BRN0401", "could not import %s: %v", imp.Path, err)
+	}
+	// Standard libraries written in Go are known to the typechecker already;
+	// only native libraries registered by other packages need declaring
+	if module.Std && stdlib.NativeBuiltins(module.Path) == nil {
+		return nil
 	}
 
-	l := lexer.New(string(data))
+	l := lexer.New(module.Source)
 	tokens, err := l.Tokenize()
 	if err != nil {
-		return fmt.Errorf("lexical error in import %s: %v", imp.Path, err)
+		return errcode.Errorf("BRN0402", "lexical error in import %s: %v", imp.Path, err)
 	}
 
 	p := parser.New(tokens)
 	importProgram, err := p.Parse()
 	if err != nil {
-		return fmt.Errorf("parse error in import %s: %v", imp.Path, err)
+		return errcode.Errorf("BRN0402", "parse error in import %s: %v", imp.Path, err)
 	}
 
 	return t.registerImportedDeclarations(importProgram.Declarations, imp)
@@ -237,6 +591,9 @@ func (t *TypeChecker) registerImportedDeclarations(declarations []ast.Declaratio
 				fields[field.Name] = field.Type
 			}
 			t.types[typeDef.Name] = fields
+			if len(typeDef.TypeParameters) > 0 {
+				t.typeParameters[typeDef.Name] = typeDef.TypeParameters
+			}
 
 		} else if class, ok := decl.(*ast.ClassDeclaration); ok {
 
@@ -245,15 +602,36 @@ func (t *TypeChecker) registerImportedDeclarations(declarations []ast.Declaratio
 			}
 
 			if _, exists := t.types[class.Name]; !exists {
-				t.types[class.Name] = make(map[string]string)
+				fields := make(map[string]string, len(class.Fields))
+				for _, field := range class.Fields {
+					fields[field.Name] = field.Type
+				}
+				t.types[class.Name] = fields
+			}
+			t.declaredClasses[class.Name] = true
+		} else if iface, ok := decl.(*ast.InterfaceDeclaration); ok {
+			if _, exists := t.types[iface.Name]; !exists {
+				if err := t.registerInterface(iface); err != nil {
+					return err
+				}
 			}
 		}
 	}
 
 	for _, decl := range declarations {
 		if fn, ok := decl.(*ast.FunctionDeclaration); ok {
+			if fn.Receiver != "" {
+				if _, exists := t.classes[fn.Receiver][fn.Name]; !exists {
+					t.addMethod(fn)
+				}
+				continue
+			}
 
-			if _, exists := t.functions[fn.Name]; exists {
+			if _, exists := t.functions[fn.Name]; exists || fn.Name == "main" {
+				continue
+			}
+			if fn.Private {
+				t.hiddenFunctions[fn.Name] = imp.Path
 				continue
 			}
 
@@ -263,14 +641,22 @@ func (t *TypeChecker) registerImportedDeclarations(declarations []ast.Declaratio
 			}
 
 			t.functions[fn.Name] = FunctionType{
-				Parameters: paramTypes,
-				ReturnType: fn.ReturnType,
+				Parameters:     paramTypes,
+				ReturnType:     fn.ReturnType,
+				TypeParameters: fn.TypeParameters,
 			}
 		} else if class, ok := decl.(*ast.ClassDeclaration); ok {
 
 			if _, exists := t.classes[class.Name]; !exists {
 				classMethods := make(map[string]FunctionType)
 				t.classes[class.Name] = classMethods
+				if err := t.registerImplements(class); err != nil {
+					return err
+				}
+				t.markPrivate(class)
+				if err := t.checkStatics(class); err != nil {
+					return err
+				}
 
 				for _, method := range class.Methods {
 					paramTypes := make([]string, len(method.Parameters))
@@ -313,10 +699,23 @@ func (t *TypeChecker) registerImportedDeclarations(declarations []ast.Declaratio
 	return nil
 }
 
-func (t *TypeChecker) setErrorPos(pos int) {
-	t.errorPos = pos
+// setErrorAt records node as the place of the next error
+func (t *TypeChecker) setErrorAt(node ast.Node) {
+	t.errorPos = node.Pos()
+	t.errorLoc = node.Loc()
 }
 
 func (t *TypeChecker) Position() int {
 	return t.errorPos
 }
+
+// Location is the line and column of Position
+func (t *TypeChecker) Location() ast.Location {
+	return t.errorLoc
+}
+
+// TypeOf returns the type resolved for expr during the last Check.
+func (t *TypeChecker) TypeOf(expr ast.Expression) (string, bool) {
+	exprType, exists := t.exprTypes[expr]
+	return exprType, exists
+}
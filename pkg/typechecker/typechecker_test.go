@@ -0,0 +1,25 @@
+package typechecker_test
+
+import (
+	"testing"
+
+	"github.com/burnlang/burn/pkg/lexer"
+	"github.com/burnlang/burn/pkg/parser"
+	"github.com/burnlang/burn/pkg/typechecker"
+)
+
+// check parses source and returns the type checker after checking it,
+// with the errors it found
+func check(t *testing.T, source string) (*typechecker.TypeChecker, []*typechecker.Error) {
+	t.Helper()
+	tokens, err := lexer.New(source).Tokenize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	program, errs := parser.New(tokens).ParseAll()
+	if len(errs) > 0 {
+		t.Fatal(errs[0])
+	}
+	tc := typechecker.New()
+	return tc, tc.CheckAll(program.Declarations)
+}
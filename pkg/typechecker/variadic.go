@@ -0,0 +1,53 @@
+package typechecker
+
+import (
+	"strconv"
+	"strings"
+)
+
+// variadicTypeOf returns the type of each argument a variadic parameter
+// type such as ...int takes
+func variadicTypeOf(typeName string) (string, bool) {
+	return strings.CutPrefix(typeName, "...")
+}
+
+// parameterType returns the type of a parameter in the body of its
+// function, which is an array for a variadic parameter
+func parameterType(typeName string) string {
+	if elemType, ok := variadicTypeOf(typeName); ok {
+		return "[" + elemType + "]"
+	}
+	return typeName
+}
+
+// argumentTypes returns the types of the count arguments of a call of a
+// function with the parameter types params, and whether it takes that many
+func argumentTypes(params []string, count int) ([]string, bool) {
+	last := len(params) - 1
+	if last < 0 {
+		return params, count == 0
+	}
+	elemType, variadic := variadicTypeOf(params[last])
+	if !variadic {
+		return params, count == len(params)
+	}
+	if count < last {
+		return nil, false
+	}
+
+	types := make([]string, count)
+	copy(types, params[:last])
+	for j := last; j < count; j++ {
+		types[j] = elemType
+	}
+	return types, true
+}
+
+// arity describes how many arguments a function with the parameter types
+// params takes, as in "expects at least 1 arguments"
+func arity(params []string) string {
+	if last := len(params) - 1; last >= 0 && strings.HasPrefix(params[last], "...") {
+		return "at least " + strconv.Itoa(last)
+	}
+	return strconv.Itoa(len(params))
+}
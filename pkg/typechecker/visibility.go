@@ -0,0 +1,31 @@
+package typechecker
+
+import (
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
+)
+
+// markPrivate records the private fields and methods of a class
+func (t *TypeChecker) markPrivate(class *ast.ClassDeclaration) {
+	for _, fields := range [][]*ast.VariableDeclaration{class.Fields, class.StaticFields} {
+		for _, field := range fields {
+			if field.Private {
+				t.private[class.Name+"."+field.Name] = true
+			}
+		}
+	}
+	for _, method := range class.Methods {
+		if method.Private {
+			t.private[class.Name+"."+method.Name] = true
+		}
+	}
+}
+
+// checkAccess checks that a field or method of a type, described by kind
+// in errors, is public or used within the methods of that type
+func (t *TypeChecker) checkAccess(typeName, member, kind string) error {
+	if t.private[typeName+"."+member] && t.currentClass != typeName {
+		return errcode.Errorf("BRN0318", "%s %s of %s is private", kind, member, typeName)
+	}
+	return nil
+}
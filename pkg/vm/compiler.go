@@ -0,0 +1,533 @@
+// Package vm compiles Burn programs to bytecode and runs them on a stack
+// machine, which is faster than walking the syntax tree as the interpreter
+// does. It runs programs made of functions working on numbers, strings,
+// booleans and arrays; Compile rejects programs using the rest of the
+// language, which run on the interpreter instead.
+package vm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
+	"github.com/burnlang/burn/pkg/interpreter"
+)
+
+// Program is a compiled program
+type Program struct {
+	Functions []*Function
+
+	// Entry is the function the program starts with: main, or the
+	// top-level statements when there is no main
+	Entry int
+}
+
+// Function is a compiled function. Its parameters are its first locals.
+type Function struct {
+	Name     string
+	Arity    int
+	Variadic bool
+	Locals   int
+
+	Code      []Instruction
+	Constants []interpreter.Value
+
+	// script is set for the function of the top-level statements
+	script bool
+	// constants are the Constants as the VM keeps them
+	constants []value
+	// nodes are the syntax the instructions were compiled from, where an
+	// error in them is reported
+	nodes []ast.Node
+}
+
+// UnsupportedError reports a part of Burn the VM cannot run yet
+type UnsupportedError struct {
+	What string
+	Node ast.Node
+}
+
+func (e *UnsupportedError) Error() string {
+	if e.Node != nil && e.Node.Loc().IsValid() {
+		return fmt.Sprintf("the VM does not support %s yet (line %d)", e.What, e.Node.Loc().Line)
+	}
+	return fmt.Sprintf("the VM does not support %s yet", e.What)
+}
+
+type compiler struct {
+	program *Program
+	// functions are the indexes of the functions of the program by name
+	functions map[string]int
+
+	fn *Function
+	// scopes are the locals of the blocks around the code being compiled,
+	// innermost last, and next the first local none of them uses
+	scopes [][]local
+	next   int
+	loops  []*loop
+	// node is the syntax being compiled, which the instructions emitted
+	// are attributed to
+	node ast.Node
+
+	err error
+}
+
+type local struct {
+	name string
+	slot int
+}
+
+// loop is a loop being compiled, whose break and continue statements jump
+// to places not known yet
+type loop struct {
+	label     string
+	breaks    []int
+	continues []int
+}
+
+// Compile compiles a typechecked program. It fails with an
+// *UnsupportedError when the program uses a part of Burn the VM does not
+// support.
+func Compile(program *ast.Program) (*Program, error) {
+	c := &compiler{program: &Program{}, functions: make(map[string]int)}
+
+	var bodies []*ast.FunctionDeclaration
+	var statements []ast.Declaration
+	for _, decl := range program.Declarations {
+		switch d := decl.(type) {
+		case *ast.FunctionDeclaration:
+			if d.Receiver != "" {
+				c.unsupported(d, "methods")
+				continue
+			}
+			c.functions[d.Name] = len(c.program.Functions)
+			c.program.Functions = append(c.program.Functions, &Function{Name: d.Name})
+			bodies = append(bodies, d)
+		case *ast.TypeDefinition:
+			c.unsupported(d, "type definitions")
+		case *ast.ClassDeclaration:
+			c.unsupported(d, "classes")
+		case *ast.InterfaceDeclaration:
+			c.unsupported(d, "interfaces")
+		case *ast.ImportDeclaration, *ast.MultiImportDeclaration:
+			c.unsupported(d, "imports")
+		default:
+			statements = append(statements, d)
+		}
+	}
+
+	for _, decl := range bodies {
+		c.function(c.program.Functions[c.functions[decl.Name]], decl.Parameters, decl.Body)
+	}
+
+	// As in the interpreter, the top-level statements only run when there
+	// is no main function
+	if main, exists := c.functions["main"]; exists {
+		c.program.Entry = main
+	} else {
+		c.program.Entry = len(c.program.Functions)
+		script := &Function{Name: "<script>", script: true}
+		c.program.Functions = append(c.program.Functions, script)
+		c.function(script, nil, statements)
+	}
+
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.program, nil
+}
+
+// unsupported records that the program uses what, which the VM does not
+// support, unless an error was found before
+func (c *compiler) unsupported(node ast.Node, what string) {
+	if c.err == nil {
+		c.err = &UnsupportedError{What: what, Node: node}
+	}
+}
+
+func (c *compiler) function(fn *Function, params []ast.Parameter, body []ast.Declaration) {
+	c.fn, c.scopes, c.next, c.loops = fn, [][]local{nil}, 0, nil
+	fn.Arity = len(params)
+	for _, param := range params {
+		// A variadic parameter, the last, gets the rest of the arguments
+		fn.Variadic = strings.HasPrefix(param.Type, "...")
+		c.declare(param.Name)
+	}
+	c.statements(body)
+	c.emit(OpNil, 0, 0)
+	c.emit(OpReturn, 0, 0)
+}
+
+func (c *compiler) emit(op Op, a, b int) int {
+	c.fn.Code = append(c.fn.Code, Instruction{Op: op, A: int32(a), B: int32(b)})
+	c.fn.nodes = append(c.fn.nodes, c.node)
+	return len(c.fn.Code) - 1
+}
+
+// patch makes the jump at index go to the next instruction
+func (c *compiler) patch(index int) {
+	c.fn.Code[index].A = int32(len(c.fn.Code))
+}
+
+func (c *compiler) constant(v interpreter.Value) int {
+	c.fn.Constants = append(c.fn.Constants, v)
+	c.fn.constants = append(c.fn.constants, unbox(v))
+	return len(c.fn.Constants) - 1
+}
+
+// declare gives name the next free local of the innermost scope
+func (c *compiler) declare(name string) int {
+	slot := c.next
+	c.next++
+	c.fn.Locals = max(c.fn.Locals, c.next)
+	scope := len(c.scopes) - 1
+	c.scopes[scope] = append(c.scopes[scope], local{name: name, slot: slot})
+	return slot
+}
+
+// resolve returns the local name refers to, or -1
+func (c *compiler) resolve(name string) int {
+	for scope := len(c.scopes) - 1; scope >= 0; scope-- {
+		locals := c.scopes[scope]
+		for j := len(locals) - 1; j >= 0; j-- {
+			if locals[j].name == name {
+				return locals[j].slot
+			}
+		}
+	}
+	return -1
+}
+
+// block compiles statements in a scope of their own, whose locals later
+// code may use again
+func (c *compiler) block(stmts []ast.Declaration) {
+	c.scopes = append(c.scopes, nil)
+	c.statements(stmts)
+	c.endScope()
+}
+
+func (c *compiler) endScope() {
+	c.next -= len(c.scopes[len(c.scopes)-1])
+	c.scopes = c.scopes[:len(c.scopes)-1]
+}
+
+func (c *compiler) statements(stmts []ast.Declaration) {
+	for _, stmt := range stmts {
+		c.statement(stmt)
+	}
+}
+
+func (c *compiler) statement(decl ast.Declaration) {
+	c.node = decl
+	switch d := decl.(type) {
+	case *ast.VariableDeclaration:
+		if d.Value != nil {
+			c.expression(d.Value)
+		} else {
+			c.emit(OpZero, c.constant(d.Type), 0)
+		}
+		c.emit(OpSetLocal, c.declare(d.Name), 0)
+		c.emit(OpPop, 0, 0)
+	case *ast.ExpressionStatement:
+		c.expression(d.Expression)
+		c.emit(OpPop, 0, 0)
+	case *ast.ReturnStatement:
+		if d.Value != nil {
+			c.expression(d.Value)
+		} else {
+			c.emit(OpNil, 0, 0)
+		}
+		c.emit(OpReturn, 0, 0)
+	case *ast.IfStatement:
+		c.expression(d.Condition)
+		skipThen := c.emit(OpJumpIfFalse, 0, 0)
+		c.block(d.ThenBranch)
+		if len(d.ElseBranch) == 0 {
+			c.patch(skipThen)
+			return
+		}
+		skipElse := c.emit(OpJump, 0, 0)
+		c.patch(skipThen)
+		c.block(d.ElseBranch)
+		c.patch(skipElse)
+	case *ast.BlockStatement:
+		c.block(d.Statements)
+	case *ast.WhileStatement:
+		start := len(c.fn.Code)
+		c.expression(d.Condition)
+		exit := c.emit(OpJumpIfFalse, 0, 0)
+		l := c.beginLoop(d.Label)
+		c.block(d.Body)
+		c.emit(OpJump, start, 0)
+		c.patch(exit)
+		c.endLoop(l, start)
+	case *ast.ForStatement:
+		c.scopes = append(c.scopes, nil)
+		if d.Initializer != nil {
+			c.statement(d.Initializer)
+		}
+		start := len(c.fn.Code)
+		exit := -1
+		if d.Condition != nil {
+			c.expression(d.Condition)
+			exit = c.emit(OpJumpIfFalse, 0, 0)
+		}
+		l := c.beginLoop(d.Label)
+		c.block(d.Body)
+		increment := len(c.fn.Code)
+		if d.Increment != nil {
+			c.expression(d.Increment)
+			c.emit(OpPop, 0, 0)
+		}
+		c.emit(OpJump, start, 0)
+		if exit >= 0 {
+			c.patch(exit)
+		}
+		c.endLoop(l, increment)
+		c.endScope()
+	case *ast.ForInStatement:
+		c.expression(d.Iterable)
+		c.scopes = append(c.scopes, nil)
+		// The iterator is kept in a local no name refers to
+		iterator := c.declare("")
+		c.emit(OpIterate, iterator, 0)
+		start := len(c.fn.Code)
+		next := c.emit(OpNext, 0, iterator)
+		c.emit(OpSetLocal, c.declare(d.Variable), 0)
+		c.emit(OpPop, 0, 0)
+		l := c.beginLoop(d.Label)
+		c.block(d.Body)
+		c.emit(OpJump, start, 0)
+		c.patch(next)
+		c.endLoop(l, start)
+		c.endScope()
+	case *ast.BreakStatement:
+		if l := c.loop(d, d.Label); l != nil {
+			l.breaks = append(l.breaks, c.emit(OpJump, 0, 0))
+		}
+	case *ast.ContinueStatement:
+		if l := c.loop(d, d.Label); l != nil {
+			l.continues = append(l.continues, c.emit(OpJump, 0, 0))
+		}
+	case *ast.FunctionDeclaration:
+		c.unsupported(d, "nested functions")
+	case *ast.MatchStatement:
+		c.unsupported(d, "match statements")
+	default:
+		c.unsupported(d, fmt.Sprintf("%T statements", d))
+	}
+}
+
+func (c *compiler) beginLoop(label string) *loop {
+	l := &loop{label: label}
+	c.loops = append(c.loops, l)
+	return l
+}
+
+// endLoop makes the break statements of l jump to the next instruction
+// and its continue statements to next
+func (c *compiler) endLoop(l *loop, next int) {
+	for _, jump := range l.breaks {
+		c.patch(jump)
+	}
+	for _, jump := range l.continues {
+		c.fn.Code[jump].A = int32(next)
+	}
+	c.loops = c.loops[:len(c.loops)-1]
+}
+
+// loop returns the loop a break or continue statement with label leaves
+func (c *compiler) loop(node ast.Node, label string) *loop {
+	for j := len(c.loops) - 1; j >= 0; j-- {
+		if label == "" || c.loops[j].label == label {
+			return c.loops[j]
+		}
+	}
+	if c.err == nil {
+		c.err = errcode.Errorf("BRN0319", "break or continue outside of a loop at line %d", node.Loc().Line)
+	}
+	return nil
+}
+
+func (c *compiler) expression(expr ast.Expression) {
+	c.node = expr
+	switch e := expr.(type) {
+	case *ast.LiteralExpression:
+		v, err := interpreter.LiteralValue(e)
+		if err != nil {
+			if c.err == nil {
+				c.err = err
+			}
+			return
+		}
+		switch v {
+		case true:
+			c.emit(OpTrue, 0, 0)
+		case false:
+			c.emit(OpFalse, 0, 0)
+		default:
+			c.emit(OpConstant, c.constant(v), 0)
+		}
+	case *ast.NilExpression:
+		c.emit(OpNil, 0, 0)
+	case *ast.GroupingExpression:
+		c.expression(e.Expression)
+	case *ast.VariableExpression:
+		if slot := c.resolve(e.Name); slot >= 0 {
+			c.emit(OpGetLocal, slot, 0)
+		} else if _, exists := c.functions[e.Name]; exists {
+			c.unsupported(e, "functions as values")
+		} else {
+			c.emit(OpGetGlobal, c.constant(e.Name), 0)
+		}
+	case *ast.AssignmentExpression:
+		slot := c.resolve(e.Name)
+		if slot < 0 {
+			c.unsupported(e, "assignments to globals")
+			return
+		}
+		c.expression(e.Value)
+		c.emit(OpSetLocal, slot, 0)
+	case *ast.BinaryExpression:
+		c.expression(e.Left)
+		if e.Operator == "??" {
+			skip := c.emit(OpJumpIfNotNil, 0, 0)
+			c.expression(e.Right)
+			c.patch(skip)
+			return
+		}
+		op, ok := operators[e.Operator]
+		if !ok {
+			c.unsupported(e, "the operator "+e.Operator)
+			return
+		}
+		c.expression(e.Right)
+		intDivision := 0
+		if e.IntDivision {
+			intDivision = 1
+		}
+		c.emit(op, intDivision, 0)
+	case *ast.UnaryExpression:
+		c.expression(e.Right)
+		switch e.Operator {
+		case "-":
+			c.emit(OpNegate, 0, 0)
+		case "!":
+			c.emit(OpNot, 0, 0)
+		default:
+			c.unsupported(e, "the operator "+e.Operator)
+		}
+	case *ast.ConditionalExpression:
+		c.expression(e.Condition)
+		c.emit(OpCheckCondition, 0, 0)
+		skipThen := c.emit(OpJumpIfFalse, 0, 0)
+		c.expression(e.Then)
+		skipElse := c.emit(OpJump, 0, 0)
+		c.patch(skipThen)
+		c.expression(e.Else)
+		c.patch(skipElse)
+	case *ast.CallExpression:
+		c.call(e)
+	case *ast.IndexExpression:
+		c.expression(e.Array)
+		c.expression(e.Index)
+		c.emit(OpIndex, 0, 0)
+	case *ast.IndexAssignmentExpression:
+		c.expression(e.Array)
+		c.expression(e.Index)
+		c.expression(e.Value)
+		c.emit(OpSetIndex, 0, 0)
+	case *ast.ArrayLiteralExpression:
+		c.array(e)
+	case *ast.RangeExpression:
+		for _, bound := range []ast.Expression{e.Start, e.End, e.Step} {
+			if bound != nil {
+				c.expression(bound)
+			} else {
+				c.emit(OpNil, 0, 0)
+			}
+		}
+		c.node = e
+		c.emit(OpRange, 0, 0)
+	case *ast.LambdaExpression:
+		c.unsupported(e, "lambdas")
+	case *ast.GetExpression, *ast.SetExpression, *ast.ThisExpression:
+		c.unsupported(e, "fields")
+	case *ast.StructLiteralExpression:
+		c.unsupported(e, "structs")
+	case *ast.MapLiteralExpression:
+		c.unsupported(e, "maps")
+	case *ast.ClassMethodCallExpression:
+		c.unsupported(e, "methods")
+	default:
+		c.unsupported(e, fmt.Sprintf("%T expressions", e))
+	}
+}
+
+func (c *compiler) call(e *ast.CallExpression) {
+	callee, ok := e.Callee.(*ast.VariableExpression)
+	if !ok {
+		if _, isGet := e.Callee.(*ast.GetExpression); isGet {
+			c.unsupported(e, "methods")
+		} else {
+			c.unsupported(e, "calls of function values")
+		}
+		return
+	}
+	if c.resolve(callee.Name) >= 0 {
+		c.unsupported(e, "calls of function values")
+		return
+	}
+
+	fn, declared := c.functions[callee.Name]
+	switch {
+	case declared:
+		c.arguments(e.Arguments)
+		c.node = callee
+		c.emit(OpCall, fn, len(e.Arguments))
+	case interpreter.CallsBack(callee.Name):
+		c.unsupported(e, callee.Name)
+	default:
+		c.arguments(e.Arguments)
+		c.node = callee
+		c.emit(OpCallBuiltin, c.constant(callee.Name), len(e.Arguments))
+		if callee.Name == "typeof" && e.NumberType != "" {
+			c.emit(OpPop, 0, 0)
+			c.emit(OpConstant, c.constant(e.NumberType), 0)
+		}
+	}
+}
+
+func (c *compiler) arguments(args []ast.Expression) {
+	for _, arg := range args {
+		c.expression(arg)
+	}
+}
+
+// array compiles an array literal, in which a range expression stands for
+// its numbers
+func (c *compiler) array(e *ast.ArrayLiteralExpression) {
+	spread := false
+	for _, element := range e.Elements {
+		if _, isRange := element.(*ast.RangeExpression); isRange {
+			spread = true
+		}
+	}
+	if !spread {
+		c.arguments(e.Elements)
+		c.node = e
+		c.emit(OpArray, len(e.Elements), 0)
+		return
+	}
+	c.emit(OpArray, 0, 0)
+	for _, element := range e.Elements {
+		c.expression(element)
+		_, isRange := element.(*ast.RangeExpression)
+		if isRange {
+			c.emit(OpAppend, 1, 0)
+		} else {
+			c.emit(OpAppend, 0, 0)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package vm
+
+import (
+	"fmt"
+	"io"
+)
+
+// Op is the operation of an instruction
+type Op uint8
+
+const (
+	// OpConstant pushes constant A of the function
+	OpConstant Op = iota
+	OpNil
+	OpTrue
+	OpFalse
+	// OpZero pushes the zero value of the type named by constant A
+	OpZero
+	OpPop
+
+	// OpGetLocal pushes local A, and OpSetLocal sets local A to the value
+	// on top of the stack, which stays there
+	OpGetLocal
+	OpSetLocal
+	// OpGetGlobal pushes the builtin or constant named by constant A
+	OpGetGlobal
+
+	// The binary operators pop the right operand, then the left one, and
+	// push the result. OpDivide truncates when A is 1, as / does for ints.
+	OpAdd
+	OpSubtract
+	OpMultiply
+	OpDivide
+	OpModulo
+	OpEqual
+	OpNotEqual
+	OpLess
+	OpLessEqual
+	OpGreater
+	OpGreaterEqual
+	OpAnd
+	OpOr
+	OpNegate
+	OpNot
+
+	// OpJump continues at instruction A. OpJumpIfFalse pops a value and
+	// jumps unless it is true. OpJumpIfNotNil jumps if the value on top of
+	// the stack is not nil and pops it otherwise.
+	OpJump
+	OpJumpIfFalse
+	OpJumpIfNotNil
+	// OpCheckCondition fails unless the value on top of the stack is a bool,
+	// as the condition of ?: must be
+	OpCheckCondition
+
+	// OpCall calls function A of the program with the B arguments on top
+	// of the stack, and OpCallBuiltin the builtin named by constant A
+	OpCall
+	OpCallBuiltin
+	OpReturn
+
+	// OpArray pushes an array of the A values on top of the stack.
+	// OpAppend pops a value and appends it to the array under it, or its
+	// numbers when A is 1 and the value is a range.
+	OpArray
+	OpAppend
+	// OpRange pops a step, an end and a start, nil when missing, and pushes
+	// the range
+	OpRange
+	OpIndex
+	// OpSetIndex pops a value, an index and an array or map, sets the
+	// element and pushes the value
+	OpSetIndex
+
+	// OpIterate pops a value and keeps an iterator over its elements in
+	// local A. OpNext pushes the next element of the iterator in local B,
+	// or jumps to A when there is none.
+	OpIterate
+	OpNext
+)
+
+var opNames = [...]string{
+	OpConstant:       "CONSTANT",
+	OpNil:            "NIL",
+	OpTrue:           "TRUE",
+	OpFalse:          "FALSE",
+	OpZero:           "ZERO",
+	OpPop:            "POP",
+	OpGetLocal:       "GET_LOCAL",
+	OpSetLocal:       "SET_LOCAL",
+	OpGetGlobal:      "GET_GLOBAL",
+	OpAdd:            "ADD",
+	OpSubtract:       "SUBTRACT",
+	OpMultiply:       "MULTIPLY",
+	OpDivide:         "DIVIDE",
+	OpModulo:         "MODULO",
+	OpEqual:          "EQUAL",
+	OpNotEqual:       "NOT_EQUAL",
+	OpLess:           "LESS",
+	OpLessEqual:      "LESS_EQUAL",
+	OpGreater:        "GREATER",
+	OpGreaterEqual:   "GREATER_EQUAL",
+	OpAnd:            "AND",
+	OpOr:             "OR",
+	OpNegate:         "NEGATE",
+	OpNot:            "NOT",
+	OpJump:           "JUMP",
+	OpJumpIfFalse:    "JUMP_IF_FALSE",
+	OpJumpIfNotNil:   "JUMP_IF_NOT_NIL",
+	OpCheckCondition: "CHECK_CONDITION",
+	OpCall:           "CALL",
+	OpCallBuiltin:    "CALL_BUILTIN",
+	OpReturn:         "RETURN",
+	OpArray:          "ARRAY",
+	OpAppend:         "APPEND",
+	OpRange:          "RANGE",
+	OpIndex:          "INDEX",
+	OpSetIndex:       "SET_INDEX",
+	OpIterate:        "ITERATE",
+	OpNext:           "NEXT",
+}
+
+func (op Op) String() string {
+	if int(op) < len(opNames) && opNames[op] != "" {
+		return opNames[op]
+	}
+	return fmt.Sprintf("OP(%d)", op)
+}
+
+// operators are the binary operators of Burn and their operations
+var operators = map[string]Op{
+	"+":  OpAdd,
+	"-":  OpSubtract,
+	"*":  OpMultiply,
+	"/":  OpDivide,
+	"%":  OpModulo,
+	"==": OpEqual,
+	"!=": OpNotEqual,
+	"<":  OpLess,
+	"<=": OpLessEqual,
+	">":  OpGreater,
+	">=": OpGreaterEqual,
+	"&&": OpAnd,
+	"||": OpOr,
+}
+
+// operatorOf returns the Burn operator of a binary operation
+func operatorOf(op Op) string {
+	for operator, o := range operators {
+		if o == op {
+			return operator
+		}
+	}
+	return ""
+}
+
+// Instruction is an operation and its operands, whose meaning depends on
+// the operation
+type Instruction struct {
+	Op Op
+	A  int32
+	B  int32
+}
+
+// Disassemble writes the instructions of every function of p
+func (p *Program) Disassemble(w io.Writer) {
+	for index, fn := range p.Functions {
+		if index > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s (%d parameters, %d locals):\n", fn.Name, fn.Arity, fn.Locals)
+		for ip, in := range fn.Code {
+			fmt.Fprintf(w, "%04d  %-16s", ip, in.Op)
+			switch in.Op {
+			case OpConstant, OpZero, OpGetGlobal:
+				fmt.Fprintf(w, " %v", fn.Constants[in.A])
+			case OpCallBuiltin:
+				fmt.Fprintf(w, " %v %d", fn.Constants[in.A], in.B)
+			case OpCall:
+				fmt.Fprintf(w, " %s %d", p.Functions[in.A].Name, in.B)
+			case OpGetLocal, OpSetLocal, OpJump, OpJumpIfFalse, OpJumpIfNotNil, OpArray, OpAppend, OpIterate, OpDivide:
+				fmt.Fprintf(w, " %d", in.A)
+			case OpNext:
+				fmt.Fprintf(w, " %d %d", in.A, in.B)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package vm
+
+import (
+	"slices"
+
+	"github.com/burnlang/burn/pkg/interpreter"
+)
+
+type kind uint8
+
+const (
+	kindNil kind = iota
+	kindNumber
+	kindBool
+	kindObject
+)
+
+// value is a value on the stack of the VM. Numbers and booleans are kept
+// in num, 1 standing for true, so that working on them allocates nothing;
+// the other values of the interpreter are kept in obj.
+type value struct {
+	kind kind
+	num  float64
+	obj  interpreter.Value
+}
+
+func number(n float64) value {
+	return value{kind: kindNumber, num: n}
+}
+
+func boolean(b bool) value {
+	if b {
+		return value{kind: kindBool, num: 1}
+	}
+	return value{kind: kindBool}
+}
+
+func object(v interpreter.Value) value {
+	return value{kind: kindObject, obj: v}
+}
+
+func (v value) isTrue() bool {
+	return v.kind == kindBool && v.num != 0
+}
+
+// unbox returns the VM value of a value of the interpreter
+func unbox(v interpreter.Value) value {
+	switch x := v.(type) {
+	case nil:
+		return value{}
+	case float64:
+		return number(x)
+	case bool:
+		return boolean(x)
+	}
+	return object(v)
+}
+
+// box returns the value of the interpreter v stands for
+func (v value) box() interpreter.Value {
+	switch v.kind {
+	case kindNumber:
+		return v.num
+	case kindBool:
+		return v.num != 0
+	case kindObject:
+		return v.obj
+	}
+	return nil
+}
+
+// iterator goes through the elements of a value for a for-in loop, without
+// boxing them for ranges and arrays
+type iterator struct {
+	next   int
+	length int
+	r      *interpreter.Range
+	array  []interpreter.Value
+	at     func(k int) interpreter.Value
+}
+
+func newIterator(v value) (*iterator, error) {
+	switch it := v.obj.(type) {
+	case *interpreter.Range:
+		return &iterator{length: it.Len(), r: it}, nil
+	case *interpreter.Array:
+		return &iterator{length: len(it.Elements), array: slices.Clone(it.Elements)}, nil
+	}
+	length, at, err := interpreter.Elements(v.box())
+	if err != nil {
+		return nil, err
+	}
+	return &iterator{length: length, at: at}, nil
+}
+
+func (it *iterator) element(k int) value {
+	switch {
+	case it.r != nil:
+		return number(it.r.At(k))
+	case it.array != nil:
+		return unbox(it.array[k])
+	}
+	return unbox(it.at(k))
+}
@@ -0,0 +1,384 @@
+package vm
+
+import (
+	"math"
+
+	"github.com/burnlang/burn/pkg/ast"
+	"github.com/burnlang/burn/pkg/errcode"
+	"github.com/burnlang/burn/pkg/interpreter"
+)
+
+// VM runs compiled programs. The builtins and constants a program sees, and
+// the operations on values other than numbers and booleans, are those of
+// the interpreter it is made with, its host.
+type VM struct {
+	host     *interpreter.Interpreter
+	globals  map[string]interpreter.Value
+	maxDepth int
+
+	stack  []value
+	frames []frame
+
+	// errorAt is the syntax of the instruction that failed
+	errorAt ast.Node
+}
+
+// frame is a call in progress: its locals start at base on the stack, and
+// ip is its next instruction
+type frame struct {
+	fn   *Function
+	ip   int
+	base int
+}
+
+func New(host *interpreter.Interpreter) *VM {
+	return &VM{host: host, maxDepth: interpreter.DefaultMaxDepth}
+}
+
+// SetMaxDepth sets the number of calls that may be in progress at once, as
+// Limits.MaxDepth does for the interpreter
+func (m *VM) SetMaxDepth(depth int) {
+	if depth > 0 {
+		m.maxDepth = depth
+	}
+}
+
+// Position returns the position in the source of the code that caused the
+// error returned by Run, or 0
+func (m *VM) Position() int {
+	if m.errorAt == nil {
+		return 0
+	}
+	return m.errorAt.Pos()
+}
+
+// Location is the line and column of Position
+func (m *VM) Location() ast.Location {
+	if m.errorAt == nil {
+		return ast.Location{}
+	}
+	return m.errorAt.Loc()
+}
+
+// Run runs a program and returns the value its main function returns
+func (m *VM) Run(p *Program) (interpreter.Value, error) {
+	m.globals = m.host.GetVariables()
+	m.stack, m.frames, m.errorAt = m.stack[:0], m.frames[:0], nil
+
+	if err := m.call(p.Functions[p.Entry], 0); err != nil {
+		return nil, err
+	}
+	result, err := m.run(p)
+	if err != nil {
+		return nil, err
+	}
+	return result.box(), nil
+}
+
+func (m *VM) push(v value) {
+	m.stack = append(m.stack, v)
+}
+
+func (m *VM) pop() value {
+	v := m.stack[len(m.stack)-1]
+	m.stack = m.stack[:len(m.stack)-1]
+	return v
+}
+
+// popArguments pops n values and returns them as values of the interpreter
+func (m *VM) popArguments(n int) []interpreter.Value {
+	args := make([]interpreter.Value, n)
+	for j, v := range m.stack[len(m.stack)-n:] {
+		args[j] = v.box()
+	}
+	m.stack = m.stack[:len(m.stack)-n]
+	return args
+}
+
+// call starts a call of fn with the argc arguments on top of the stack. As
+// in the interpreter, missing arguments are nil, extra ones are dropped and
+// a variadic parameter gets an array of the rest.
+func (m *VM) call(fn *Function, argc int) error {
+	depth := len(m.frames)
+	if len(m.frames) > 0 && m.frames[0].fn.script {
+		depth--
+	}
+	if depth >= m.maxDepth {
+		chain := make([]string, 0, depth+1)
+		for _, f := range m.frames {
+			if !f.fn.script {
+				chain = append(chain, f.fn.Name)
+			}
+		}
+		return &interpreter.RecursionError{MaxDepth: m.maxDepth, Chain: append(chain, fn.Name)}
+	}
+
+	base := len(m.stack) - argc
+	fixed := fn.Arity
+	if fn.Variadic {
+		fixed--
+	}
+	var rest []interpreter.Value
+	if fn.Variadic {
+		rest = []interpreter.Value{}
+		if argc > fixed {
+			rest = m.popArguments(argc - fixed)
+		}
+	}
+	if argc > fixed {
+		m.stack = m.stack[:base+fixed]
+	}
+	for len(m.stack) < base+fixed {
+		m.push(value{})
+	}
+	if fn.Variadic {
+		m.push(object(interpreter.NewArray(rest)))
+	}
+	for len(m.stack) < base+fn.Locals {
+		m.push(value{})
+	}
+	m.frames = append(m.frames, frame{fn: fn, base: base})
+	return nil
+}
+
+// run executes instructions until the first call returns
+func (m *VM) run(p *Program) (value, error) {
+	f := &m.frames[len(m.frames)-1]
+	code, constants := f.fn.Code, f.fn.constants
+
+	for {
+		in := code[f.ip]
+		f.ip++
+
+		switch in.Op {
+		case OpConstant:
+			m.push(constants[in.A])
+		case OpNil:
+			m.push(value{})
+		case OpTrue:
+			m.push(boolean(true))
+		case OpFalse:
+			m.push(boolean(false))
+		case OpZero:
+			m.push(unbox(interpreter.ZeroValue(f.fn.Constants[in.A].(string))))
+		case OpPop:
+			m.stack = m.stack[:len(m.stack)-1]
+
+		case OpGetLocal:
+			m.push(m.stack[f.base+int(in.A)])
+		case OpSetLocal:
+			m.stack[f.base+int(in.A)] = m.stack[len(m.stack)-1]
+		case OpGetGlobal:
+			name := f.fn.Constants[in.A].(string)
+			global, exists := m.globals[name]
+			if !exists {
+				return m.fail(f, errcode.Errorf("BRN0509", "undefined variable: %s", name))
+			}
+			m.push(unbox(global))
+
+		case OpAdd, OpSubtract, OpMultiply, OpDivide, OpModulo,
+			OpLess, OpLessEqual, OpGreater, OpGreaterEqual:
+			right := m.pop()
+			left := &m.stack[len(m.stack)-1]
+			if left.kind == kindNumber && right.kind == kindNumber {
+				if result, ok := arithmetic(in, left.num, right.num); ok {
+					*left = result
+					continue
+				}
+			}
+			result, err := m.host.Binary(operatorOf(in.Op), left.box(), right.box(), in.A == 1)
+			if err != nil {
+				return m.fail(f, err)
+			}
+			*left = unbox(result)
+		case OpEqual, OpNotEqual:
+			right := m.pop()
+			left := &m.stack[len(m.stack)-1]
+			if left.kind == right.kind && left.kind != kindObject {
+				*left = boolean((left.num == right.num) == (in.Op == OpEqual))
+				continue
+			}
+			result, err := m.host.Binary(operatorOf(in.Op), left.box(), right.box(), false)
+			if err != nil {
+				return m.fail(f, err)
+			}
+			*left = unbox(result)
+		case OpAnd, OpOr:
+			right := m.pop()
+			left := &m.stack[len(m.stack)-1]
+			if left.kind == kindBool && right.kind == kindBool {
+				if in.Op == OpAnd {
+					*left = boolean(left.isTrue() && right.isTrue())
+				} else {
+					*left = boolean(left.isTrue() || right.isTrue())
+				}
+				continue
+			}
+			result, err := m.host.Binary(operatorOf(in.Op), left.box(), right.box(), false)
+			if err != nil {
+				return m.fail(f, err)
+			}
+			*left = unbox(result)
+		case OpNegate, OpNot:
+			operand := &m.stack[len(m.stack)-1]
+			switch {
+			case in.Op == OpNegate && operand.kind == kindNumber:
+				operand.num = -operand.num
+			case in.Op == OpNot && operand.kind == kindBool:
+				*operand = boolean(!operand.isTrue())
+			default:
+				operator := "-"
+				if in.Op == OpNot {
+					operator = "!"
+				}
+				result, err := m.host.Unary(operator, operand.box())
+				if err != nil {
+					return m.fail(f, err)
+				}
+				*operand = unbox(result)
+			}
+
+		case OpJump:
+			f.ip = int(in.A)
+		case OpJumpIfFalse:
+			if !m.pop().isTrue() {
+				f.ip = int(in.A)
+			}
+		case OpJumpIfNotNil:
+			if m.stack[len(m.stack)-1].kind != kindNil {
+				f.ip = int(in.A)
+			} else {
+				m.pop()
+			}
+		case OpCheckCondition:
+			if condition := m.stack[len(m.stack)-1]; condition.kind != kindBool {
+				return m.fail(f, errcode.Errorf("BRN0511", "condition of '?' must be a bool, got %T", condition.box()))
+			}
+
+		case OpCall:
+			if err := m.call(p.Functions[in.A], int(in.B)); err != nil {
+				return m.fail(f, err)
+			}
+			f = &m.frames[len(m.frames)-1]
+			code, constants = f.fn.Code, f.fn.constants
+		case OpReturn:
+			result := m.pop()
+			m.stack = m.stack[:f.base]
+			m.frames = m.frames[:len(m.frames)-1]
+			if len(m.frames) == 0 {
+				return result, nil
+			}
+			m.push(result)
+			f = &m.frames[len(m.frames)-1]
+			code, constants = f.fn.Code, f.fn.constants
+		case OpCallBuiltin:
+			args := m.popArguments(int(in.B))
+			result, err := m.host.CallBuiltin(f.fn.Constants[in.A].(string), args)
+			if err != nil {
+				return m.fail(f, err)
+			}
+			m.push(unbox(result))
+		case OpArray:
+			m.push(object(interpreter.NewArray(m.popArguments(int(in.A)))))
+		case OpAppend:
+			element := m.pop()
+			array := m.stack[len(m.stack)-1].obj.(*interpreter.Array)
+			if r, isRange := element.obj.(*interpreter.Range); isRange && in.A == 1 {
+				for k := range r.Len() {
+					array.Elements = append(array.Elements, r.At(k))
+				}
+			} else {
+				array.Elements = append(array.Elements, element.box())
+			}
+		case OpRange:
+			step, end := m.pop(), m.pop()
+			start := &m.stack[len(m.stack)-1]
+			r, err := interpreter.NewRange(start.box(), end.box(), step.box())
+			if err != nil {
+				return m.fail(f, err)
+			}
+			*start = object(r)
+		case OpIndex:
+			index := m.pop()
+			target := &m.stack[len(m.stack)-1]
+			if array, isArray := target.obj.(*interpreter.Array); isArray && index.kind == kindNumber {
+				if k := int(index.num); k >= 0 && k < len(array.Elements) {
+					*target = unbox(array.Elements[k])
+					continue
+				}
+			}
+			result, err := m.host.Index(target.box(), index.box())
+			if err != nil {
+				return m.fail(f, err)
+			}
+			*target = unbox(result)
+		case OpSetIndex:
+			v, index := m.pop(), m.pop()
+			target := &m.stack[len(m.stack)-1]
+			if err := m.host.SetIndex(target.box(), index.box(), v.box()); err != nil {
+				return m.fail(f, err)
+			}
+			*target = v
+
+		case OpIterate:
+			it, err := newIterator(m.pop())
+			if err != nil {
+				return m.fail(f, err)
+			}
+			m.stack[f.base+int(in.A)] = object(it)
+		case OpNext:
+			it := m.stack[f.base+int(in.B)].obj.(*iterator)
+			if it.next >= it.length {
+				f.ip = int(in.A)
+				continue
+			}
+			m.push(it.element(it.next))
+			it.next++
+
+		default:
+			return m.fail(f, errcode.Errorf("BRN0515", "unknown instruction %s", in.Op))
+		}
+	}
+}
+
+// arithmetic applies an arithmetic or comparison operation to numbers, as
+// the interpreter does. It reports false for a division by zero, whose
+// error the host gives.
+func arithmetic(in Instruction, left, right float64) (value, bool) {
+	switch in.Op {
+	case OpAdd:
+		return number(left + right), true
+	case OpSubtract:
+		return number(left - right), true
+	case OpMultiply:
+		return number(left * right), true
+	case OpDivide:
+		if right == 0 {
+			return value{}, false
+		}
+		if in.A == 1 {
+			return number(math.Trunc(left / right)), true
+		}
+		return number(left / right), true
+	case OpModulo:
+		if right == 0 {
+			return value{}, false
+		}
+		return number(math.Mod(left, right)), true
+	case OpLess:
+		return boolean(left < right), true
+	case OpLessEqual:
+		return boolean(left <= right), true
+	case OpGreater:
+		return boolean(left > right), true
+	case OpGreaterEqual:
+		return boolean(left >= right), true
+	}
+	return value{}, false
+}
+
+// fail records the instruction of f that just ran as the place of err
+func (m *VM) fail(f *frame, err error) (value, error) {
+	m.errorAt = f.fn.nodes[f.ip-1]
+	return value{}, err
+}
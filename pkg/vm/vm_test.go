@@ -0,0 +1,176 @@
+package vm_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/burnlang/burn/internal/testutil"
+	"github.com/burnlang/burn/pkg/interpreter"
+	"github.com/burnlang/burn/pkg/vm"
+)
+
+func TestPushToSharedArray(t *testing.T) {
+	got := testutil.RunVM(t, `
+var e = [1, 2]
+push(e, 3)
+var f = e
+push(e, 4)
+push(f, 5)
+print(e)
+print(f)
+`)
+	if want := "[1 2 3 4 5]\n[1 2 3 4 5]\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPushToSharedArrayInFunction(t *testing.T) {
+	got := testutil.RunVM(t, `
+fun grow(a: [int]): [int] {
+    push(a, 4)
+    return a
+}
+
+var e = [1, 2]
+push(e, 3)
+var f = grow(e)
+push(f, 5)
+print(e)
+print(f)
+`)
+	if want := "[1 2 3 4 5]\n[1 2 3 4 5]\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestFunctionChangesArgumentArray(t *testing.T) {
+	got := testutil.RunVM(t, `
+fun add(xs: [int]) {
+    push(xs, 1)
+    xs[0] = 9
+}
+
+var a = [0]
+add(a)
+var grid = [[0], [0]]
+add(grid[1])
+print(a)
+print(grid)
+`)
+	if want := "[9 1]\n[[0] [9 1]]\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestMatchesInterpreter(t *testing.T) {
+	for _, source := range []string{`
+var sum = 0
+for (var i = 0; i < 100; i = i + 1) {
+    if (i % 3 == 0) {
+        continue
+    }
+    sum = sum + i
+}
+print(sum)
+`, `
+fun fib(n: int): int {
+    if (n < 2) {
+        return n
+    }
+    return fib(n - 1) + fib(n - 2)
+}
+print(fib(20))
+`, `
+var found = 0
+outer: for (var i = 1; i < 10; i = i + 1) {
+    var j = 1
+    while (j < 10) {
+        if (i * j == 42) {
+            found = i * 10 + j
+            break outer
+        }
+        j = j + 1
+    }
+}
+print(found)
+`, `
+fun sum(values: ...int): int {
+    var total = 0
+    for (v in values) {
+        total = total + v
+    }
+    return total
+}
+print(sum())
+print(sum(1, 2, 3))
+`, `
+var chars = ""
+for (c in "héllo") {
+    chars = c + chars
+}
+print(chars)
+print([0, 1..4, 9])
+print(7 / 2)
+print(7.0 / 2)
+print(len([1, 2, 3]) * 2)
+`} {
+		if got, want := testutil.RunVM(t, source), testutil.Run(t, source); got != want {
+			t.Errorf("%s: VM printed %q, interpreter %q", source, got, want)
+		}
+	}
+}
+
+func TestRuntimeErrorLocation(t *testing.T) {
+	compiled, err := vm.Compile(testutil.Program(t, `
+fun at(xs: [int], i: int): int {
+    return xs[i]
+}
+print(at([1, 2], 1))
+print(at([1, 2], 5))
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := vm.New(interpreter.New())
+	_, err = m.Run(compiled)
+	if err == nil || !strings.Contains(err.Error(), "out of bounds") {
+		t.Fatalf("got %v, want an index out of bounds error", err)
+	}
+	if line := m.Location().Line; line != 3 {
+		t.Errorf("error reported at line %d, want 3", line)
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	compiled, err := vm.Compile(testutil.Program(t, `
+fun down(n: int): int {
+    return down(n + 1)
+}
+down(0)
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := vm.New(interpreter.New())
+	m.SetMaxDepth(50)
+	_, err = m.Run(compiled)
+	var recursion *interpreter.RecursionError
+	if !errors.As(err, &recursion) || recursion.MaxDepth != 50 {
+		t.Errorf("got %v, want a recursion error at depth 50", err)
+	}
+}
+
+func TestCompileUnsupported(t *testing.T) {
+	_, err := vm.Compile(testutil.Program(t, `
+type Point { x: int, y: int }
+print(Point{x: 1, y: 2})
+`))
+	var unsupported *vm.UnsupportedError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("got %v, want an UnsupportedError", err)
+	}
+	if unsupported.Node == nil || unsupported.Node.Loc().Line != 2 {
+		t.Errorf("unsupported %s reported without the line of the type", unsupported.What)
+	}
+}